@@ -13,21 +13,36 @@ import (
 	httpDelivery "github.com/secusense/backend/internal/delivery/http"
 	"github.com/secusense/backend/internal/delivery/http/handler"
 	"github.com/secusense/backend/internal/delivery/http/middleware"
+	"github.com/secusense/backend/internal/domain"
 	"github.com/secusense/backend/internal/repository/postgres"
 	"github.com/secusense/backend/internal/usecase/ai"
+	"github.com/secusense/backend/internal/usecase/audit"
 	"github.com/secusense/backend/internal/usecase/auth"
 	"github.com/secusense/backend/internal/usecase/certificate"
 	"github.com/secusense/backend/internal/usecase/course"
 	"github.com/secusense/backend/internal/usecase/enrollment"
+	"github.com/secusense/backend/internal/usecase/export"
+	"github.com/secusense/backend/internal/usecase/group"
+	"github.com/secusense/backend/internal/usecase/lti"
+	"github.com/secusense/backend/internal/usecase/organization"
+	"github.com/secusense/backend/internal/usecase/role"
 	"github.com/secusense/backend/internal/usecase/test"
 	"github.com/secusense/backend/internal/usecase/workflow"
+	"github.com/secusense/backend/infrastructure/assets"
 	"github.com/secusense/backend/infrastructure/database"
-	"github.com/secusense/backend/infrastructure/ollama"
+	"github.com/secusense/backend/infrastructure/eventbus"
+	"github.com/secusense/backend/infrastructure/llm"
+	"github.com/secusense/backend/infrastructure/ltiplatform"
+	"github.com/secusense/backend/infrastructure/queue"
+	"github.com/secusense/backend/infrastructure/storage"
 	"github.com/secusense/backend/infrastructure/synthesia"
 	"github.com/secusense/backend/infrastructure/tts"
 	"github.com/secusense/backend/infrastructure/unsplash"
+	"github.com/secusense/backend/infrastructure/xapi"
+	"github.com/secusense/backend/pkg/gift"
 	"github.com/secusense/backend/pkg/jwt"
 	"github.com/secusense/backend/pkg/pdf"
+	"github.com/secusense/backend/pkg/qti"
 )
 
 func main() {
@@ -54,10 +69,22 @@ func main() {
 	questionRepo := postgres.NewQuestionRepository(db)
 	attemptRepo := postgres.NewTestAttemptRepository(db)
 	answerRepo := postgres.NewUserAnswerRepository(db)
+	grantRepo := postgres.NewTestAttemptGrantRepository(db)
+	reviewRepo := postgres.NewAnswerReviewRepository(db)
 	certRepo := postgres.NewCertificateRepository(db)
+	certKeyRepo := postgres.NewCertificateKeyRepository(db)
+	certTemplateRepo := postgres.NewCertificateTemplateRepository(db)
 	aiJobRepo := postgres.NewAIGenerationJobRepository(db)
 	workflowRepo := postgres.NewWorkflowRepository(db)
 	presentationRepo := postgres.NewPresentationRepository(db)
+	xapiOutboxRepo := postgres.NewXAPIOutboxRepository(db)
+	ltiPlatformRepo := postgres.NewLTIPlatformRepository(db)
+	ltiRepo := postgres.NewLTIRepository(db)
+	orgRepo := postgres.NewOrganizationRepository(db)
+	roleRepo := postgres.NewRoleRepository(db)
+	groupRepo := postgres.NewGroupRepository(db)
+	assignmentRepo := postgres.NewCourseAssignmentRepository(db)
+	auditRepo := postgres.NewAuditRepository(db)
 
 	// Initialize JWT manager
 	jwtManager := jwt.NewManager(
@@ -68,22 +95,59 @@ func main() {
 	)
 
 	// Initialize external clients
-	ollamaClient := ollama.NewClient(cfg.Ollama)
+	llmProvider, err := llm.NewProvider(cfg.LLM, cfg.Ollama)
+	if err != nil {
+		log.Fatalf("Failed to initialize LLM provider: %v", err)
+	}
+	// Initialize storage of certificate PDFs, narration audio and slide images
+	store, err := storage.New(cfg.Storage)
+	if err != nil {
+		log.Fatalf("Failed to initialize storage: %v", err)
+	}
+
 	synthesiaClient := synthesia.NewClient(cfg.Synthesia)
-	ttsClient := tts.NewClient(cfg.TTS)
+	ttsClient := tts.NewClient(cfg.TTS, store)
 	unsplashClient := unsplash.NewClient(cfg.Unsplash)
+	assetFetcher := assets.NewFetcher(store)
+
+	// Initialize background job queue (handlers are registered by the use cases)
+	jobQueue := queue.New(aiJobRepo, cfg.Queue)
+
+	// Initialize workflow progress event bus
+	eventBus, err := eventbus.New(cfg.Events, db, cfg.Database.DSN())
+	if err != nil {
+		log.Fatalf("Failed to initialize event bus: %v", err)
+	}
+	defer eventBus.Close()
+
+	// Initialize xAPI statement recording; the relay sends the outbox to the LRS
+	xapiRelay := xapi.NewRelay(cfg.XAPI, xapiOutboxRepo)
+	xapiRecorder := xapi.NewRecorder(cfg.XAPI, xapiOutboxRepo, xapiRelay)
 
 	// Initialize PDF generator
 	pdfGen := pdf.NewCertificateGenerator("https://secusense.example.com")
 
 	// Initialize use cases
-	authUC := auth.NewUseCase(userRepo, refreshTokenRepo, jwtManager)
+	authUC := auth.NewUseCase(userRepo, refreshTokenRepo, orgRepo, roleRepo, jwtManager)
 	courseUC := course.NewUseCase(courseRepo, courseContentRepo)
-	enrollmentUC := enrollment.NewUseCase(enrollmentRepo, courseRepo)
-	testUC := test.NewUseCase(testRepo, questionRepo, attemptRepo, answerRepo, enrollmentRepo, courseRepo)
-	certUC := certificate.NewUseCase(certRepo, attemptRepo, pdfGen)
-	aiUC := ai.NewUseCase(aiJobRepo, courseRepo, courseContentRepo, testRepo, questionRepo, ollamaClient, synthesiaClient)
-	workflowUC := workflow.NewUseCase(workflowRepo, presentationRepo, courseRepo, testRepo, questionRepo, ollamaClient, synthesiaClient, ttsClient, unsplashClient)
+	enrollmentUC := enrollment.NewUseCase(enrollmentRepo, courseRepo, workflowRepo, presentationRepo, xapiRecorder)
+	testUC := test.NewUseCase(testRepo, questionRepo, attemptRepo, answerRepo, grantRepo, reviewRepo, enrollmentRepo, courseRepo, certRepo, xapiRecorder, jobQueue, cfg.Tests.GracePeriod)
+	testUC.RegisterScorer(domain.QuestionTypeOpenEnded, test.NewLLMScorer(llmProvider, cfg.Tests.GradingTimeout, cfg.Tests.ReviewThreshold))
+	testUC.RegisterCodec(domain.FormatQTI, qti.NewCodec())
+	testUC.RegisterCodec(domain.FormatGIFT, gift.NewCodec())
+	certUC := certificate.NewUseCase(certRepo, certKeyRepo, attemptRepo, testRepo, enrollmentRepo, courseRepo, certTemplateRepo, orgRepo, pdfGen, store, cfg.Storage.URLTTL, cfg.Certificates.APIURL+"/api/v1/certificates/revocation-list", cfg.Certificates.KeySecret)
+	if err := certUC.PrepareSigningKeys(); err != nil {
+		log.Fatalf("Failed to prepare certificate signing keys: %v", err)
+	}
+	aiUC := ai.NewUseCase(aiJobRepo, courseRepo, courseContentRepo, testRepo, questionRepo, llmProvider, synthesiaClient, jobQueue)
+	workflowUC := workflow.NewUseCase(workflowRepo, presentationRepo, courseRepo, testRepo, questionRepo, llmProvider, synthesiaClient, ttsClient, unsplashClient, assetFetcher, jobQueue, eventBus)
+	exportUC := export.NewUseCase(courseRepo, workflowRepo, presentationRepo, testRepo, questionRepo, assetFetcher)
+	ltiUC := lti.NewUseCase(ltiPlatformRepo, ltiRepo, userRepo, courseRepo, enrollmentRepo, testRepo, attemptRepo, reviewRepo, authUC, ltiplatform.NewKeySet(), ltiplatform.NewGradeClient(), jobQueue, cfg.LTI)
+	testUC.RegisterListener(ltiUC)
+	orgUC := organization.NewUseCase(orgRepo, userRepo, roleRepo)
+	roleUC := role.NewUseCase(roleRepo, userRepo)
+	groupUC := group.NewUseCase(groupRepo, assignmentRepo, userRepo, courseRepo, enrollmentRepo)
+	auditUC := audit.NewUseCase(auditRepo)
 
 	// Initialize middleware
 	authMiddleware := middleware.NewAuthMiddleware(jwtManager)
@@ -96,6 +160,13 @@ func main() {
 	certHandler := handler.NewCertificateHandler(certUC)
 	aiHandler := handler.NewAIHandler(aiUC)
 	workflowHandler := handler.NewWorkflowHandler(workflowUC)
+	exportHandler := handler.NewExportHandler(exportUC)
+	ltiHandler := handler.NewLTIHandler(ltiUC)
+	orgHandler := handler.NewOrganizationHandler(orgUC)
+	roleHandler := handler.NewRoleHandler(roleUC)
+	groupHandler := handler.NewGroupHandler(groupUC)
+	auditHandler := handler.NewAuditHandler(auditUC)
+	mediaHandler := handler.NewMediaHandler(store, cfg.Storage.URLTTL)
 
 	// Initialize router
 	router := httpDelivery.NewRouter(
@@ -110,6 +181,13 @@ func main() {
 		certHandler,
 		aiHandler,
 		workflowHandler,
+		exportHandler,
+		ltiHandler,
+		orgHandler,
+		roleHandler,
+		groupHandler,
+		auditHandler,
+		mediaHandler,
 	)
 
 	// Create server
@@ -129,6 +207,12 @@ func main() {
 		}
 	}()
 
+	// Start job workers; this also picks up jobs orphaned by a previous run
+	jobQueue.Start()
+
+	// Start sending recorded statements, including any left from a previous run
+	xapiRelay.Start()
+
 	// Start background video status polling (every 5 minutes)
 	stopPolling := make(chan struct{})
 	go func() {
@@ -153,6 +237,56 @@ func main() {
 		}
 	}()
 
+	// Start background sweeper for test attempts that ran out of time
+	sweepInterval := cfg.Tests.SweepInterval
+	if sweepInterval <= 0 {
+		sweepInterval = time.Minute
+	}
+	go func() {
+		ticker := time.NewTicker(sweepInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				n, err := testUC.SweepExpiredAttempts()
+				if err != nil {
+					log.Printf("Attempt sweep error: %v", err)
+				}
+				if n > 0 {
+					log.Printf("Auto-submitted %d expired test attempt(s)", n)
+				}
+			case <-stopPolling:
+				return
+			}
+		}
+	}()
+
+	// Start background renewal of certificates that are about to expire
+	renewalInterval := cfg.Certificates.RenewalInterval
+	if renewalInterval <= 0 {
+		renewalInterval = time.Hour
+	}
+	go func() {
+		ticker := time.NewTicker(renewalInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				n, err := certUC.OpenRenewals(time.Now())
+				if err != nil {
+					log.Printf("Certificate renewal error: %v", err)
+				}
+				if n > 0 {
+					log.Printf("Enrolled %d learner(s) again to renew their certificate", n)
+				}
+			case <-stopPolling:
+				return
+			}
+		}
+	}()
+
 	// Graceful shutdown
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -160,7 +294,7 @@ func main() {
 
 	log.Println("Shutting down server...")
 
-	// Stop background polling
+	// Stop background polling, sweeping and renewals
 	close(stopPolling)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
@@ -170,5 +304,14 @@ func main() {
 		log.Fatalf("Server forced to shutdown: %v", err)
 	}
 
+	// Let running jobs finish; whatever is still running at the deadline is
+	// handed back to the queue for the next start
+	if err := jobQueue.Stop(ctx); err != nil {
+		log.Printf("Job queue stopped before all jobs finished: %v", err)
+	}
+	if err := xapiRelay.Stop(ctx); err != nil {
+		log.Printf("xAPI relay stopped before its batch was sent: %v", err)
+	}
+
 	log.Println("Server stopped")
 }
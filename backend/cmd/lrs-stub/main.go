@@ -0,0 +1,169 @@
+// Command lrs-stub is an in-memory Learning Record Store for trying out the
+// xAPI integration locally. It accepts statements on POST /xapi/statements
+// and lists them on GET /xapi/statements; everything is lost on exit.
+//
+//	go run ./cmd/lrs-stub -addr :8090 -username lrs -password secret
+//
+// and point the API at it with SECUSENSE_XAPI_ENDPOINT=http://localhost:8090/xapi.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"log"
+	"net/http"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+type store struct {
+	mu         sync.Mutex
+	statements []json.RawMessage
+	byID       map[string]json.RawMessage
+}
+
+func main() {
+	addr := flag.String("addr", ":8090", "listen address")
+	username := flag.String("username", "", "Basic auth username (empty disables auth)")
+	password := flag.String("password", "", "Basic auth password")
+	flag.Parse()
+
+	s := &store{byID: make(map[string]json.RawMessage)}
+
+	http.HandleFunc("/xapi/statements", func(w http.ResponseWriter, r *http.Request) {
+		if *username != "" {
+			user, pass, ok := r.BasicAuth()
+			if !ok || user != *username || pass != *password {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+		}
+		w.Header().Set("X-Experience-API-Version", "1.0.3")
+
+		switch r.Method {
+		case http.MethodPost:
+			s.post(w, r)
+		case http.MethodGet:
+			s.list(w)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	log.Printf("[LRS] Listening on %s", *addr)
+	log.Fatal(http.ListenAndServe(*addr, nil))
+}
+
+// post stores a statement or an array of statements and returns their IDs.
+// A statement ID that is already stored with other content is a conflict;
+// the same statement sent again is ignored.
+func (s *store) post(w http.ResponseWriter, r *http.Request) {
+	var raw json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&raw); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	batch := []json.RawMessage{raw}
+	if bytes.HasPrefix(bytes.TrimSpace(raw), []byte("[")) {
+		if err := json.Unmarshal(raw, &batch); err != nil {
+			http.Error(w, "invalid JSON", http.StatusBadRequest)
+			return
+		}
+	}
+
+	type statement struct {
+		ID     string          `json:"id"`
+		Actor  json.RawMessage `json:"actor"`
+		Verb   json.RawMessage `json:"verb"`
+		Object json.RawMessage `json:"object"`
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ids := make([]string, 0, len(batch))
+	accepted := make(map[string]json.RawMessage, len(batch))
+	for _, data := range batch {
+		var st statement
+		if err := json.Unmarshal(data, &st); err != nil || st.Actor == nil || st.Verb == nil || st.Object == nil {
+			http.Error(w, "statement needs an actor, verb and object", http.StatusBadRequest)
+			return
+		}
+		if st.ID == "" {
+			st.ID = uuid.NewString()
+		} else if _, err := uuid.Parse(st.ID); err != nil {
+			http.Error(w, "invalid statement ID "+st.ID, http.StatusBadRequest)
+			return
+		}
+		existing, ok := s.byID[st.ID]
+		if !ok {
+			existing, ok = accepted[st.ID]
+		}
+		if ok && !jsonEqual(existing, data) {
+			http.Error(w, "statement "+st.ID+" already exists", http.StatusConflict)
+			return
+		}
+		if !ok {
+			accepted[st.ID] = data
+		}
+		ids = append(ids, st.ID)
+	}
+
+	for _, id := range ids {
+		if data, ok := accepted[id]; ok {
+			delete(accepted, id)
+			s.byID[id] = data
+			s.statements = append(s.statements, data)
+			log.Printf("[LRS] Stored %s", summary(data))
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ids)
+}
+
+func (s *store) list(w http.ResponseWriter) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	statements := s.statements
+	if statements == nil {
+		statements = []json.RawMessage{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"statements": statements,
+		"more":       "",
+	})
+}
+
+func jsonEqual(a, b json.RawMessage) bool {
+	var va, vb interface{}
+	if json.Unmarshal(a, &va) != nil || json.Unmarshal(b, &vb) != nil {
+		return false
+	}
+	ea, _ := json.Marshal(va)
+	eb, _ := json.Marshal(vb)
+	return bytes.Equal(ea, eb)
+}
+
+// summary describes a statement as "<account> <verb> <object>" for the log
+func summary(data json.RawMessage) string {
+	var st struct {
+		Actor struct {
+			Account struct {
+				Name string `json:"name"`
+			} `json:"account"`
+		} `json:"actor"`
+		Verb struct {
+			Display map[string]string `json:"display"`
+		} `json:"verb"`
+		Object struct {
+			ID string `json:"id"`
+		} `json:"object"`
+	}
+	json.Unmarshal(data, &st)
+	return st.Actor.Account.Name + " " + st.Verb.Display["en-US"] + " " + st.Object.ID
+}
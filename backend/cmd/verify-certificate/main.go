@@ -0,0 +1,101 @@
+// Command verify-certificate checks a signed SecuSense certificate without
+// reaching the API. It reads the credential from a certificate PDF, from the
+// payload of its QR code or from the credential itself, and verifies it
+// against the published public keys, fetched once beforehand:
+//
+//	curl -o keys.json https://secusense.example.com/.well-known/certificate-keys.json
+//	go run ./cmd/verify-certificate -keys keys.json certificate.pdf
+//	go run ./cmd/verify-certificate -keys keys.json -payload 'https://secusense.example.com/verify/...'
+//
+// It exits with 0 for a valid certificate, 1 for an invalid or expired one
+// and 2 when the input can't be read.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/secusense/backend/pkg/certsig"
+)
+
+func main() {
+	keysPath := flag.String("keys", "certificate-keys.json", "JWKS with the certificate signing keys")
+	payload := flag.String("payload", "", "scanned QR code payload or credential, instead of a file")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: verify-certificate -keys keys.json (certificate.pdf | -payload text)\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	var data []byte
+	switch {
+	case *payload != "":
+		data = []byte(*payload)
+	case flag.NArg() == 1:
+		var err error
+		if data, err = os.ReadFile(flag.Arg(0)); err != nil {
+			fail(2, "read certificate: %v", err)
+		}
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	keys, err := readKeys(*keysPath)
+	if err != nil {
+		fail(2, "read keys: %v", err)
+	}
+
+	credential, err := certsig.Extract(data)
+	if err != nil {
+		fail(2, "%v", err)
+	}
+
+	claims, err := certsig.Verify(credential, keys)
+	if errors.Is(err, certsig.ErrUnknownKey) {
+		fail(1, "INVALID: %v (are the keys up to date?)", err)
+	}
+	if err != nil {
+		fail(1, "INVALID: %v", err)
+	}
+
+	fmt.Printf("Certificate: %s\n", claims.ID)
+	fmt.Printf("Holder:      %s\n", claims.Holder)
+	fmt.Printf("Course:      %s\n", claims.Course)
+	if claims.MaxScore > 0 {
+		fmt.Printf("Score:       %d/%d\n", claims.Score, claims.MaxScore)
+	}
+	fmt.Printf("Issuer:      %s\n", claims.Issuer)
+	if claims.IssuedAt != nil {
+		fmt.Printf("Issued:      %s\n", claims.IssuedAt.Format("2006-01-02"))
+	}
+	if claims.ExpiresAt != nil {
+		fmt.Printf("Expires:     %s\n", claims.ExpiresAt.Format("2006-01-02"))
+	}
+
+	if claims.Expired(time.Now()) {
+		fail(1, "EXPIRED: the signature is valid but the certificate has expired")
+	}
+	fmt.Println("VALID: signed by the issuer and not tampered with")
+}
+
+func readKeys(path string) (*certsig.JWKS, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var keys certsig.JWKS
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return nil, err
+	}
+	return &keys, nil
+}
+
+func fail(code int, format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+	os.Exit(code)
+}
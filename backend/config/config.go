@@ -9,13 +9,21 @@ import (
 )
 
 type Config struct {
-	Server    ServerConfig
-	Database  DatabaseConfig
-	JWT       JWTConfig
-	Ollama    OllamaConfig
-	Synthesia SynthesiaConfig
-	TTS       TTSConfig
-	Unsplash  UnsplashConfig
+	Server       ServerConfig
+	Database     DatabaseConfig
+	JWT          JWTConfig
+	LLM          LLMConfig
+	Ollama       OllamaConfig
+	Synthesia    SynthesiaConfig
+	TTS          TTSConfig
+	Unsplash     UnsplashConfig
+	Queue        QueueConfig
+	Events       EventsConfig
+	Tests        TestsConfig
+	Certificates CertificatesConfig
+	Storage      StorageConfig
+	XAPI         XAPIConfig
+	LTI          LTIConfig
 }
 
 type ServerConfig struct {
@@ -41,11 +49,26 @@ type JWTConfig struct {
 }
 
 type OllamaConfig struct {
-	BaseURL     string
-	Model       string
-	Timeout     time.Duration
-	CloudMode   bool
-	APIKey      string // API key for Ollama Cloud authentication
+	BaseURL   string
+	Model     string
+	Timeout   time.Duration
+	CloudMode bool
+	APIKey    string // API key for Ollama Cloud authentication
+}
+
+// LLMConfig selects the language model backend: "ollama" (configured by
+// OllamaConfig) or "openai" for any OpenAI-compatible chat completions server
+type LLMConfig struct {
+	Provider string
+	OpenAI   OpenAIConfig
+}
+
+type OpenAIConfig struct {
+	BaseURL  string
+	Model    string
+	APIKey   string
+	Timeout  time.Duration
+	JSONMode bool // Request response_format=json_object; disable for servers without support
 }
 
 type SynthesiaConfig struct {
@@ -56,8 +79,7 @@ type SynthesiaConfig struct {
 }
 
 type TTSConfig struct {
-	Voice     string
-	OutputDir string
+	Voice string
 }
 
 type UnsplashConfig struct {
@@ -65,6 +87,86 @@ type UnsplashConfig struct {
 	BaseURL   string
 }
 
+type QueueConfig struct {
+	Workers         int
+	PollInterval    time.Duration
+	LeaseDuration   time.Duration
+	MaxAttempts     int
+	RetryBackoff    time.Duration
+	MaxRetryBackoff time.Duration
+}
+
+// EventsConfig selects how workflow progress events are distributed: "memory"
+// for a single instance, "postgres" (LISTEN/NOTIFY) for several instances
+type EventsConfig struct {
+	Backend string
+}
+
+// TestsConfig controls server-side enforcement of test time limits and the
+// LLM grading of open-ended answers
+type TestsConfig struct {
+	GracePeriod     time.Duration // How late a submission may arrive and still be graded as submitted
+	SweepInterval   time.Duration // How often expired attempts are auto-submitted
+	GradingTimeout  time.Duration // How long the LLM may take to grade one answer
+	ReviewThreshold float64       // LLM grades less confident than this go to the review queue
+}
+
+// CertificatesConfig controls the renewal of certificates that expire and
+// where their revocation list is published
+type CertificatesConfig struct {
+	RenewalInterval time.Duration // How often learners with certificates about to expire are enrolled again
+	APIURL          string        // Public URL of the API; Open Badges credentials point at its revocation list
+	KeySecret       string        // Encrypts the signing keys stored in the database (the JWT secret by default)
+}
+
+// StorageConfig selects where generated files (certificate PDFs, narration
+// audio, slide images) are kept: "local" for a directory served by the API,
+// or "s3" for a bucket of an S3-compatible service such as MinIO. Files are
+// handed out through signed URLs that expire after URLTTL.
+type StorageConfig struct {
+	Backend    string
+	LocalDir   string
+	APIURL     string // Public URL of the API, which serves local files to signed URLs
+	SigningKey string // Signs local URLs; the JWT secret when empty
+	URLTTL     time.Duration
+	S3         S3Config
+}
+
+type S3Config struct {
+	Endpoint  string // e.g. "http://localhost:9000" for MinIO; AWS when empty
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	PathStyle bool // Address buckets as <endpoint>/<bucket>, which MinIO needs
+}
+
+// XAPIConfig sends learning activity as xAPI statements to a Learning Record
+// Store. Statements are only recorded when an endpoint is set; they wait in
+// an outbox table until the LRS has accepted them.
+type XAPIConfig struct {
+	Endpoint        string // Statements are posted to <endpoint>/statements
+	Username        string // Basic auth key of the LRS client
+	Password        string
+	PlatformURL     string // Home page of learner accounts and base of activity IDs
+	Timeout         time.Duration
+	BatchSize       int
+	PollInterval    time.Duration
+	RetryBackoff    time.Duration
+	MaxRetryBackoff time.Duration
+	MaxAttempts     int           // Statements still unsent after this many tries are marked failed
+	Retention       time.Duration // How long sent statements are kept in the outbox
+}
+
+// LTIConfig describes SecuSense as an LTI 1.3 tool. The platforms (LMSs)
+// allowed to launch it are registered by administrators.
+type LTIConfig struct {
+	ToolURL     string        // Public base URL of the API, given to platforms for login, launch and keys
+	FrontendURL string        // Where launches continue in the browser
+	StateTTL    time.Duration // How long a login may take to come back as a launch
+	LaunchTTL   time.Duration // How long a launch can be exchanged for a session or answered with a deep link
+}
+
 func Load() (*Config, error) {
 	viper.SetConfigName("config")
 	viper.SetConfigType("yaml")
@@ -87,6 +189,12 @@ func Load() (*Config, error) {
 	viper.SetDefault("jwt.refreshExpiresIn", "7d")
 	viper.SetDefault("jwt.issuer", "secusense")
 
+	viper.SetDefault("llm.provider", "ollama")
+	viper.SetDefault("llm.openai.baseUrl", "https://api.openai.com/v1")
+	viper.SetDefault("llm.openai.model", "gpt-4o-mini")
+	viper.SetDefault("llm.openai.timeout", "5m")
+	viper.SetDefault("llm.openai.jsonMode", true)
+
 	viper.SetDefault("ollama.baseUrl", "http://localhost:11434")
 	viper.SetDefault("ollama.model", "")
 	viper.SetDefault("ollama.timeout", "5m")
@@ -96,10 +204,44 @@ func Load() (*Config, error) {
 	viper.SetDefault("synthesia.avatarId", "anna_costume1_cameraA")
 
 	viper.SetDefault("tts.voice", "de-DE-KatjaNeural")
-	viper.SetDefault("tts.outputDir", "./generated/audio")
 
 	viper.SetDefault("unsplash.baseUrl", "https://api.unsplash.com")
 
+	viper.SetDefault("events.backend", "memory")
+
+	viper.SetDefault("tests.gracePeriod", "30s")
+	viper.SetDefault("tests.sweepInterval", "1m")
+	viper.SetDefault("tests.gradingTimeout", "60s")
+	viper.SetDefault("tests.reviewThreshold", 0.7)
+
+	viper.SetDefault("xapi.platformUrl", "https://secusense.example.com")
+	viper.SetDefault("xapi.timeout", "10s")
+	viper.SetDefault("xapi.batchSize", 50)
+	viper.SetDefault("xapi.pollInterval", "5s")
+	viper.SetDefault("xapi.retryBackoff", "30s")
+	viper.SetDefault("xapi.maxRetryBackoff", "1h")
+	viper.SetDefault("xapi.maxAttempts", 48)
+	viper.SetDefault("xapi.retention", "168h")
+
+	viper.SetDefault("certificates.apiUrl", "http://localhost:8080")
+	viper.SetDefault("storage.backend", "local")
+	viper.SetDefault("storage.localDir", "./generated")
+	viper.SetDefault("storage.apiUrl", "http://localhost:8080")
+	viper.SetDefault("storage.urlTtl", "15m")
+	viper.SetDefault("storage.s3.region", "us-east-1")
+	viper.SetDefault("storage.s3.pathStyle", true)
+	viper.SetDefault("lti.toolUrl", "http://localhost:8080")
+	viper.SetDefault("lti.frontendUrl", "http://localhost:4200")
+	viper.SetDefault("lti.stateTtl", "10m")
+	viper.SetDefault("lti.launchTtl", "1h")
+
+	viper.SetDefault("queue.workers", 4)
+	viper.SetDefault("queue.pollInterval", "2s")
+	viper.SetDefault("queue.leaseDuration", "2m")
+	viper.SetDefault("queue.maxAttempts", 3)
+	viper.SetDefault("queue.retryBackoff", "30s")
+	viper.SetDefault("queue.maxRetryBackoff", "10m")
+
 	// Environment variable bindings
 	viper.AutomaticEnv()
 	viper.SetEnvPrefix("SECUSENSE")
@@ -112,14 +254,39 @@ func Load() (*Config, error) {
 	viper.BindEnv("database.password", "SECUSENSE_DATABASE_PASSWORD")
 	viper.BindEnv("database.dbname", "SECUSENSE_DATABASE_DBNAME")
 	viper.BindEnv("jwt.secret", "SECUSENSE_JWT_SECRET")
+	viper.BindEnv("llm.provider", "SECUSENSE_LLM_PROVIDER")
+	viper.BindEnv("llm.openai.baseUrl", "SECUSENSE_LLM_OPENAI_BASEURL")
+	viper.BindEnv("llm.openai.model", "SECUSENSE_LLM_OPENAI_MODEL")
+	viper.BindEnv("llm.openai.apiKey", "SECUSENSE_LLM_OPENAI_APIKEY")
 	viper.BindEnv("ollama.baseUrl", "SECUSENSE_OLLAMA_BASEURL")
 	viper.BindEnv("ollama.cloudMode", "SECUSENSE_OLLAMA_CLOUDMODE")
 	viper.BindEnv("ollama.apiKey", "SECUSENSE_OLLAMA_APIKEY")
 	viper.BindEnv("synthesia.apiKey", "SECUSENSE_SYNTHESIA_APIKEY")
 	viper.BindEnv("server.allowOrigins", "SECUSENSE_SERVER_ALLOWORIGINS")
 	viper.BindEnv("tts.voice", "SECUSENSE_TTS_VOICE")
-	viper.BindEnv("tts.outputDir", "SECUSENSE_TTS_OUTPUTDIR")
 	viper.BindEnv("unsplash.accessKey", "SECUSENSE_UNSPLASH_ACCESSKEY")
+	viper.BindEnv("queue.workers", "SECUSENSE_QUEUE_WORKERS")
+	viper.BindEnv("events.backend", "SECUSENSE_EVENTS_BACKEND")
+	viper.BindEnv("tests.gracePeriod", "SECUSENSE_TESTS_GRACEPERIOD")
+	viper.BindEnv("tests.reviewThreshold", "SECUSENSE_TESTS_REVIEWTHRESHOLD")
+	viper.BindEnv("xapi.endpoint", "SECUSENSE_XAPI_ENDPOINT")
+	viper.BindEnv("xapi.username", "SECUSENSE_XAPI_USERNAME")
+	viper.BindEnv("xapi.password", "SECUSENSE_XAPI_PASSWORD")
+	viper.BindEnv("xapi.platformUrl", "SECUSENSE_XAPI_PLATFORMURL")
+	viper.BindEnv("certificates.apiUrl", "SECUSENSE_CERTIFICATES_APIURL")
+	viper.BindEnv("certificates.keySecret", "SECUSENSE_CERTIFICATES_KEYSECRET")
+	viper.BindEnv("storage.backend", "SECUSENSE_STORAGE_BACKEND")
+	viper.BindEnv("storage.localDir", "SECUSENSE_STORAGE_LOCALDIR")
+	viper.BindEnv("storage.apiUrl", "SECUSENSE_STORAGE_APIURL")
+	viper.BindEnv("storage.signingKey", "SECUSENSE_STORAGE_SIGNINGKEY")
+	viper.BindEnv("storage.s3.endpoint", "SECUSENSE_STORAGE_S3_ENDPOINT")
+	viper.BindEnv("storage.s3.region", "SECUSENSE_STORAGE_S3_REGION")
+	viper.BindEnv("storage.s3.bucket", "SECUSENSE_STORAGE_S3_BUCKET")
+	viper.BindEnv("storage.s3.accessKey", "SECUSENSE_STORAGE_S3_ACCESSKEY")
+	viper.BindEnv("storage.s3.secretKey", "SECUSENSE_STORAGE_S3_SECRETKEY")
+	viper.BindEnv("storage.s3.pathStyle", "SECUSENSE_STORAGE_S3_PATHSTYLE")
+	viper.BindEnv("lti.toolUrl", "SECUSENSE_LTI_TOOLURL")
+	viper.BindEnv("lti.frontendUrl", "SECUSENSE_LTI_FRONTENDURL")
 
 	// Read config file if exists
 	if err := viper.ReadInConfig(); err != nil {
@@ -131,6 +298,31 @@ func Load() (*Config, error) {
 	accessExpiry, _ := time.ParseDuration(viper.GetString("jwt.accessExpiresIn"))
 	refreshExpiry, _ := time.ParseDuration(viper.GetString("jwt.refreshExpiresIn"))
 	ollamaTimeout, _ := time.ParseDuration(viper.GetString("ollama.timeout"))
+	openAITimeout, _ := time.ParseDuration(viper.GetString("llm.openai.timeout"))
+	queuePollInterval, _ := time.ParseDuration(viper.GetString("queue.pollInterval"))
+	queueLeaseDuration, _ := time.ParseDuration(viper.GetString("queue.leaseDuration"))
+	queueRetryBackoff, _ := time.ParseDuration(viper.GetString("queue.retryBackoff"))
+	queueMaxRetryBackoff, _ := time.ParseDuration(viper.GetString("queue.maxRetryBackoff"))
+	testsGracePeriod, _ := time.ParseDuration(viper.GetString("tests.gracePeriod"))
+	testsSweepInterval, _ := time.ParseDuration(viper.GetString("tests.sweepInterval"))
+	testsGradingTimeout, _ := time.ParseDuration(viper.GetString("tests.gradingTimeout"))
+	certsRenewalInterval, _ := time.ParseDuration(viper.GetString("certificates.renewalInterval"))
+	xapiTimeout, _ := time.ParseDuration(viper.GetString("xapi.timeout"))
+	xapiPollInterval, _ := time.ParseDuration(viper.GetString("xapi.pollInterval"))
+	xapiRetryBackoff, _ := time.ParseDuration(viper.GetString("xapi.retryBackoff"))
+	xapiMaxRetryBackoff, _ := time.ParseDuration(viper.GetString("xapi.maxRetryBackoff"))
+	xapiRetention, _ := time.ParseDuration(viper.GetString("xapi.retention"))
+	storageURLTTL, _ := time.ParseDuration(viper.GetString("storage.urlTtl"))
+	certsKeySecret := viper.GetString("certificates.keySecret")
+	if certsKeySecret == "" {
+		certsKeySecret = viper.GetString("jwt.secret")
+	}
+	storageSigningKey := viper.GetString("storage.signingKey")
+	if storageSigningKey == "" {
+		storageSigningKey = viper.GetString("jwt.secret")
+	}
+	ltiStateTTL, _ := time.ParseDuration(viper.GetString("lti.stateTtl"))
+	ltiLaunchTTL, _ := time.ParseDuration(viper.GetString("lti.launchTtl"))
 
 	return &Config{
 		Server: ServerConfig{
@@ -152,6 +344,16 @@ func Load() (*Config, error) {
 			RefreshExpiresIn: refreshExpiry,
 			Issuer:           viper.GetString("jwt.issuer"),
 		},
+		LLM: LLMConfig{
+			Provider: viper.GetString("llm.provider"),
+			OpenAI: OpenAIConfig{
+				BaseURL:  viper.GetString("llm.openai.baseUrl"),
+				Model:    viper.GetString("llm.openai.model"),
+				APIKey:   viper.GetString("llm.openai.apiKey"),
+				Timeout:  openAITimeout,
+				JSONMode: viper.GetBool("llm.openai.jsonMode"),
+			},
+		},
 		Ollama: OllamaConfig{
 			BaseURL:   viper.GetString("ollama.baseUrl"),
 			Model:     viper.GetString("ollama.model"),
@@ -166,13 +368,68 @@ func Load() (*Config, error) {
 			AvatarID:   viper.GetString("synthesia.avatarId"),
 		},
 		TTS: TTSConfig{
-			Voice:     viper.GetString("tts.voice"),
-			OutputDir: viper.GetString("tts.outputDir"),
+			Voice: viper.GetString("tts.voice"),
 		},
 		Unsplash: UnsplashConfig{
 			AccessKey: viper.GetString("unsplash.accessKey"),
 			BaseURL:   viper.GetString("unsplash.baseUrl"),
 		},
+		Queue: QueueConfig{
+			Workers:         viper.GetInt("queue.workers"),
+			PollInterval:    queuePollInterval,
+			LeaseDuration:   queueLeaseDuration,
+			MaxAttempts:     viper.GetInt("queue.maxAttempts"),
+			RetryBackoff:    queueRetryBackoff,
+			MaxRetryBackoff: queueMaxRetryBackoff,
+		},
+		Events: EventsConfig{
+			Backend: viper.GetString("events.backend"),
+		},
+		Tests: TestsConfig{
+			GracePeriod:     testsGracePeriod,
+			SweepInterval:   testsSweepInterval,
+			GradingTimeout:  testsGradingTimeout,
+			ReviewThreshold: viper.GetFloat64("tests.reviewThreshold"),
+		},
+		Certificates: CertificatesConfig{
+			RenewalInterval: certsRenewalInterval,
+			APIURL:          strings.TrimSuffix(viper.GetString("certificates.apiUrl"), "/"),
+			KeySecret:       certsKeySecret,
+		},
+		Storage: StorageConfig{
+			Backend:    viper.GetString("storage.backend"),
+			LocalDir:   viper.GetString("storage.localDir"),
+			APIURL:     strings.TrimSuffix(viper.GetString("storage.apiUrl"), "/"),
+			SigningKey: storageSigningKey,
+			URLTTL:     storageURLTTL,
+			S3: S3Config{
+				Endpoint:  viper.GetString("storage.s3.endpoint"),
+				Region:    viper.GetString("storage.s3.region"),
+				Bucket:    viper.GetString("storage.s3.bucket"),
+				AccessKey: viper.GetString("storage.s3.accessKey"),
+				SecretKey: viper.GetString("storage.s3.secretKey"),
+				PathStyle: viper.GetBool("storage.s3.pathStyle"),
+			},
+		},
+		XAPI: XAPIConfig{
+			Endpoint:        viper.GetString("xapi.endpoint"),
+			Username:        viper.GetString("xapi.username"),
+			Password:        viper.GetString("xapi.password"),
+			PlatformURL:     viper.GetString("xapi.platformUrl"),
+			Timeout:         xapiTimeout,
+			BatchSize:       viper.GetInt("xapi.batchSize"),
+			PollInterval:    xapiPollInterval,
+			RetryBackoff:    xapiRetryBackoff,
+			MaxRetryBackoff: xapiMaxRetryBackoff,
+			MaxAttempts:     viper.GetInt("xapi.maxAttempts"),
+			Retention:       xapiRetention,
+		},
+		LTI: LTIConfig{
+			ToolURL:     viper.GetString("lti.toolUrl"),
+			FrontendURL: viper.GetString("lti.frontendUrl"),
+			StateTTL:    ltiStateTTL,
+			LaunchTTL:   ltiLaunchTTL,
+		},
 	}, nil
 }
 
@@ -0,0 +1,120 @@
+package assets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/secusense/backend/infrastructure/storage"
+)
+
+// audioURLPrefix is where the API served narration before it was kept in
+// the storage; presentations generated then still point there
+const audioURLPrefix = "/api/v1/audio/"
+
+const maxImageSize = 10 << 20
+
+// imageExtensions are the image types copied into packages
+var imageExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+// Fetcher loads the media of generated lessons: narration audio and slide
+// images from the storage, and slide images from their hosts
+type Fetcher struct {
+	store      storage.Storage
+	httpClient *http.Client
+}
+
+func NewFetcher(store storage.Storage) *Fetcher {
+	return &Fetcher{
+		store: store,
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// Fetch returns the content of a media URL and the file extension to store
+// it under
+func (f *Fetcher) Fetch(ctx context.Context, rawURL string) ([]byte, string, error) {
+	if key, ok := storage.MediaKey(rawURL); ok {
+		return f.read(ctx, key)
+	}
+	if name, ok := strings.CutPrefix(rawURL, audioURLPrefix); ok {
+		return f.read(ctx, storage.AudioPrefix+name)
+	}
+	return f.download(ctx, rawURL)
+}
+
+// SaveImage downloads a slide image into the storage, so presentations
+// don't depend on the image host, and returns its media URL
+func (f *Fetcher) SaveImage(ctx context.Context, rawURL string) (string, error) {
+	data, ext, err := f.download(ctx, rawURL)
+	if err != nil {
+		return "", err
+	}
+	key := storage.ImagePrefix + uuid.New().String() + ext
+	if err := f.store.Put(ctx, key, mime.TypeByExtension(ext), data); err != nil {
+		return "", err
+	}
+	return storage.MediaURL(key), nil
+}
+
+func (f *Fetcher) read(ctx context.Context, key string) ([]byte, string, error) {
+	// Only public media, so a crafted URL can't reach certificates
+	if !storage.IsPublic(key) {
+		return nil, "", fmt.Errorf("invalid media key %q", key)
+	}
+	data, err := f.store.Get(ctx, key)
+	if err != nil {
+		return nil, "", err
+	}
+	return data, path.Ext(key), nil
+}
+
+func (f *Fetcher) download(ctx context.Context, rawURL string) ([]byte, string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil, "", fmt.Errorf("unsupported media URL %q", rawURL)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, "", err
+	}
+	resp, err := f.httpClient.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("media download returned status %d", resp.StatusCode)
+	}
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	ext, ok := imageExtensions[mediaType]
+	if !ok {
+		return nil, "", fmt.Errorf("unsupported media type %q", mediaType)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxImageSize+1))
+	if err != nil {
+		return nil, "", err
+	}
+	if len(data) > maxImageSize {
+		return nil, "", errors.New("image is larger than 10MB")
+	}
+	return data, ext, nil
+}
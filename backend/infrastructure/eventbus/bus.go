@@ -0,0 +1,93 @@
+package eventbus
+
+import (
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/secusense/backend/config"
+	"github.com/secusense/backend/internal/domain"
+)
+
+const (
+	BackendMemory   = "memory"
+	BackendPostgres = "postgres"
+
+	// Events buffered per subscriber before new ones are dropped for it
+	subscriberBuffer = 64
+)
+
+// Bus delivers workflow events to everyone watching a session
+type Bus interface {
+	Publish(event domain.WorkflowEvent)
+	// Subscribe returns the events of one session until cancel is called
+	Subscribe(sessionID uuid.UUID) (events <-chan domain.WorkflowEvent, cancel func())
+	Close() error
+}
+
+// New builds the bus selected by cfg.Backend. The Postgres bus relays events
+// through LISTEN/NOTIFY so every instance sees every event.
+func New(cfg config.EventsConfig, db *sqlx.DB, dsn string) (Bus, error) {
+	if strings.ToLower(cfg.Backend) == BackendPostgres {
+		return NewPostgresBus(db, dsn)
+	}
+	return NewMemoryBus(), nil
+}
+
+// MemoryBus fans events out to subscribers within this process
+type MemoryBus struct {
+	mu   sync.RWMutex
+	subs map[uuid.UUID]map[chan domain.WorkflowEvent]struct{}
+}
+
+func NewMemoryBus() *MemoryBus {
+	return &MemoryBus{
+		subs: make(map[uuid.UUID]map[chan domain.WorkflowEvent]struct{}),
+	}
+}
+
+func (b *MemoryBus) Publish(event domain.WorkflowEvent) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for ch := range b.subs[event.SessionID] {
+		// A slow client must not hold up the workflow
+		select {
+		case ch <- event:
+		default:
+			log.Printf("[EventBus] WARNING: Dropping %s event for slow subscriber of session %s", event.Type, event.SessionID)
+		}
+	}
+}
+
+func (b *MemoryBus) Subscribe(sessionID uuid.UUID) (<-chan domain.WorkflowEvent, func()) {
+	ch := make(chan domain.WorkflowEvent, subscriberBuffer)
+
+	b.mu.Lock()
+	if b.subs[sessionID] == nil {
+		b.subs[sessionID] = make(map[chan domain.WorkflowEvent]struct{})
+	}
+	b.subs[sessionID][ch] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subs[sessionID], ch)
+			if len(b.subs[sessionID]) == 0 {
+				delete(b.subs, sessionID)
+			}
+			b.mu.Unlock()
+			close(ch)
+		})
+	}
+
+	return ch, cancel
+}
+
+func (b *MemoryBus) Close() error {
+	return nil
+}
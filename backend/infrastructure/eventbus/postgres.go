@@ -0,0 +1,94 @@
+package eventbus
+
+import (
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/secusense/backend/internal/domain"
+)
+
+const notifyChannel = "workflow_events"
+
+// PostgresBus publishes events with NOTIFY and delivers whatever arrives on
+// the LISTEN connection to local subscribers, including this instance's own
+// events. Postgres limits payloads to 8000 bytes, which workflow events stay
+// well below.
+type PostgresBus struct {
+	db       *sqlx.DB
+	local    *MemoryBus
+	listener *pq.Listener
+	done     chan struct{}
+}
+
+func NewPostgresBus(db *sqlx.DB, dsn string) (*PostgresBus, error) {
+	listener := pq.NewListener(dsn, time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Printf("[EventBus] Listener connection event %d: %v", ev, err)
+		}
+	})
+	if err := listener.Listen(notifyChannel); err != nil {
+		listener.Close()
+		return nil, err
+	}
+
+	b := &PostgresBus{
+		db:       db,
+		local:    NewMemoryBus(),
+		listener: listener,
+		done:     make(chan struct{}),
+	}
+	go b.relay()
+
+	return b, nil
+}
+
+func (b *PostgresBus) Publish(event domain.WorkflowEvent) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("[EventBus] ERROR: Failed to encode %s event: %v", event.Type, err)
+		return
+	}
+
+	if _, err := b.db.Exec(`SELECT pg_notify($1, $2)`, notifyChannel, string(payload)); err != nil {
+		// At least local subscribers still get the event
+		log.Printf("[EventBus] WARNING: NOTIFY failed, delivering locally only: %v", err)
+		b.local.Publish(event)
+	}
+}
+
+func (b *PostgresBus) Subscribe(sessionID uuid.UUID) (<-chan domain.WorkflowEvent, func()) {
+	return b.local.Subscribe(sessionID)
+}
+
+func (b *PostgresBus) Close() error {
+	close(b.done)
+	return b.listener.Close()
+}
+
+func (b *PostgresBus) relay() {
+	for {
+		select {
+		case <-b.done:
+			return
+		case n := <-b.listener.Notify:
+			// nil is sent after the listener reconnected; events sent while
+			// it was down are lost, which is acceptable for progress updates
+			if n == nil {
+				continue
+			}
+			var event domain.WorkflowEvent
+			if err := json.Unmarshal([]byte(n.Extra), &event); err != nil {
+				log.Printf("[EventBus] WARNING: Ignoring malformed notification: %v", err)
+				continue
+			}
+			b.local.Publish(event)
+		case <-time.After(90 * time.Second):
+			// Detect dead connections the driver hasn't noticed yet
+			go b.listener.Ping()
+		}
+	}
+}
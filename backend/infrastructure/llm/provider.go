@@ -0,0 +1,46 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/secusense/backend/config"
+	"github.com/secusense/backend/infrastructure/ollama"
+	"github.com/secusense/backend/infrastructure/openai"
+	"github.com/secusense/backend/internal/domain"
+)
+
+const (
+	ProviderOllama = "ollama"
+	ProviderOpenAI = "openai"
+)
+
+// LLMProvider is everything the course workflow and test grading ask of a
+// language model
+type LLMProvider interface {
+	GenerateCourseContent(ctx context.Context, req *domain.GenerateCourseRequest) (*domain.GeneratedCourseContent, error)
+	ResearchTopicSuggestions(ctx context.Context, mainTopic, targetAudience, difficulty, language string, count int) ([]TopicSuggestionResult, error)
+	RefineTopics(ctx context.Context, mainTopic string, suggestions []TopicSuggestionResult, targetAudience, difficulty, language string) ([]RefinedTopicResult, error)
+	GenerateLessonScripts(ctx context.Context, mainTopic string, topics []RefinedTopicResult, targetAudience, difficulty, language string) ([]LessonScriptResult, error)
+	GenerateQuizQuestions(ctx context.Context, courseTitle string, lessonScripts []string, language string, count int) ([]domain.GeneratedQuestion, error)
+	GeneratePresentationSlides(ctx context.Context, lessonTitle string, script string, language string) ([]PresentationSlideResult, error)
+	GradeOpenAnswer(ctx context.Context, req *domain.OpenAnswerGradingRequest) (*domain.OpenAnswerGrade, error)
+}
+
+// Completer is the transport a Generator needs: send a prompt, get back the
+// model's answer as a JSON document
+type Completer interface {
+	CompleteJSON(ctx context.Context, prompt string) (string, error)
+}
+
+// NewProvider builds the provider selected by cfg.Provider
+func NewProvider(cfg config.LLMConfig, ollamaCfg config.OllamaConfig) (LLMProvider, error) {
+	switch strings.ToLower(cfg.Provider) {
+	case "", ProviderOllama:
+		return NewGenerator(ollama.NewClient(ollamaCfg)), nil
+	case ProviderOpenAI:
+		return NewGenerator(openai.NewClient(cfg.OpenAI)), nil
+	}
+	return nil, fmt.Errorf("unknown LLM provider %q", cfg.Provider)
+}
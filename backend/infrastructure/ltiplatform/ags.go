@@ -0,0 +1,187 @@
+package ltiplatform
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/secusense/backend/internal/domain"
+)
+
+const (
+	ActivityProgressCompleted = "Completed"
+
+	GradingProgressFullyGraded   = "FullyGraded"
+	GradingProgressPending       = "Pending"
+	GradingProgressPendingManual = "PendingManual"
+
+	scoreContentType = "application/vnd.ims.lis.v1.score+json"
+	clientAssertion  = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+	// tokenRefreshMargin renews access tokens shortly before they expire
+	tokenRefreshMargin = 30 * time.Second
+)
+
+// Score is a learner's result posted to a platform line item
+type Score struct {
+	UserID           string  `json:"userId"`
+	ScoreGiven       float64 `json:"scoreGiven"`
+	ScoreMaximum     float64 `json:"scoreMaximum"`
+	Comment          string  `json:"comment,omitempty"`
+	Timestamp        string  `json:"timestamp"`
+	ActivityProgress string  `json:"activityProgress"`
+	GradingProgress  string  `json:"gradingProgress"`
+}
+
+type accessToken struct {
+	value     string
+	expiresAt time.Time
+}
+
+// GradeClient posts scores through LTI Assignment and Grade Services
+type GradeClient struct {
+	httpClient *http.Client
+
+	mu     sync.Mutex
+	tokens map[uuid.UUID]accessToken
+}
+
+func NewGradeClient() *GradeClient {
+	return &GradeClient{
+		httpClient: &http.Client{
+			Timeout: 15 * time.Second,
+		},
+		tokens: make(map[uuid.UUID]accessToken),
+	}
+}
+
+// PostScore sends a score to the line item's scores endpoint
+func (c *GradeClient) PostScore(ctx context.Context, platform *domain.LTIPlatform, key *ToolKey, lineItemURL string, score *Score) error {
+	token, err := c.token(ctx, platform, key)
+	if err != nil {
+		return err
+	}
+
+	scoresURL, err := scoresEndpoint(lineItemURL)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(score)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, scoresURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", scoreContentType)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post score: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		// The platform may have revoked the token early
+		c.mu.Lock()
+		delete(c.tokens, platform.ID)
+		c.mu.Unlock()
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("platform rejected score with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// scoresEndpoint appends /scores to the line item's path, keeping any query
+func scoresEndpoint(lineItemURL string) (string, error) {
+	u, err := url.Parse(lineItemURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid line item URL: %w", err)
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/scores"
+	return u.String(), nil
+}
+
+// token returns a cached access token for the platform or requests one with
+// a client assertion signed by the tool key
+func (c *GradeClient) token(ctx context.Context, platform *domain.LTIPlatform, key *ToolKey) (string, error) {
+	c.mu.Lock()
+	cached, ok := c.tokens[platform.ID]
+	c.mu.Unlock()
+	if ok && time.Until(cached.expiresAt) > tokenRefreshMargin {
+		return cached.value, nil
+	}
+
+	now := time.Now()
+	assertion, err := key.Sign(&jwt.RegisteredClaims{
+		Issuer:    platform.ClientID,
+		Subject:   platform.ClientID,
+		Audience:  jwt.ClaimStrings{platform.AuthTokenURL},
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(messageLifetime)),
+		ID:        uuid.NewString(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{
+		"grant_type":            {"client_credentials"},
+		"client_assertion_type": {clientAssertion},
+		"client_assertion":      {assertion},
+		"scope":                 {ScopeScore},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, platform.AuthTokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to request grade service token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("grade service token request returned status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<16)).Decode(&result); err != nil {
+		return "", fmt.Errorf("invalid grade service token response: %w", err)
+	}
+	if result.AccessToken == "" {
+		return "", fmt.Errorf("grade service token response has no access token")
+	}
+	if result.ExpiresIn <= 0 {
+		result.ExpiresIn = 3600
+	}
+
+	c.mu.Lock()
+	c.tokens[platform.ID] = accessToken{
+		value:     result.AccessToken,
+		expiresAt: now.Add(time.Duration(result.ExpiresIn) * time.Second),
+	}
+	c.mu.Unlock()
+
+	return result.AccessToken, nil
+}
@@ -0,0 +1,186 @@
+package ltiplatform
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// keySetTTL is how long a platform's keys are trusted without refetching
+	keySetTTL = time.Hour
+	// keySetRefetchInterval limits refetches for unknown key IDs, so forged
+	// tokens can't make the tool hammer a platform
+	keySetRefetchInterval = time.Minute
+	maxKeySetSize         = 1 << 20
+)
+
+type cachedKeySet struct {
+	keys      map[string]*rsa.PublicKey
+	fetchedAt time.Time
+}
+
+// KeySet fetches and caches the public keys platforms sign id_tokens with
+type KeySet struct {
+	httpClient *http.Client
+
+	mu       sync.Mutex
+	sets     map[string]*cachedKeySet
+	fetching map[string]*sync.Mutex // One fetch per JWKS URL at a time
+}
+
+func NewKeySet() *KeySet {
+	return &KeySet{
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+		sets:     make(map[string]*cachedKeySet),
+		fetching: make(map[string]*sync.Mutex),
+	}
+}
+
+// PublicKey returns the key with the given ID from the JWKS at jwksURL. An
+// empty ID matches the only key of a set with one key.
+func (k *KeySet) PublicKey(ctx context.Context, jwksURL, kid string) (*rsa.PublicKey, error) {
+	if key, err := k.cached(jwksURL).lookup(kid); key != nil || err != nil {
+		return key, err
+	}
+
+	// The fetch runs outside k.mu so a slow platform only holds up its own
+	// launches; launches waiting on the same platform share one fetch
+	k.mu.Lock()
+	fetching := k.fetching[jwksURL]
+	if fetching == nil {
+		fetching = &sync.Mutex{}
+		k.fetching[jwksURL] = fetching
+	}
+	k.mu.Unlock()
+
+	fetching.Lock()
+	defer fetching.Unlock()
+
+	set := k.cached(jwksURL)
+	if key, err := set.lookup(kid); key != nil || err != nil {
+		return key, err
+	}
+
+	fetched, err := k.fetch(ctx, jwksURL)
+	if err != nil {
+		// A platform that is briefly unreachable shouldn't block launches
+		// signed with a key we already know
+		if set != nil {
+			if key := set.find(kid); key != nil {
+				return key, nil
+			}
+		}
+		return nil, err
+	}
+	k.mu.Lock()
+	k.sets[jwksURL] = fetched
+	k.mu.Unlock()
+
+	if key := fetched.find(kid); key != nil {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+func (k *KeySet) cached(jwksURL string) *cachedKeySet {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.sets[jwksURL]
+}
+
+// lookup returns the key from the cache while it can be trusted. It returns
+// neither key nor error when the set has to be fetched.
+func (s *cachedKeySet) lookup(kid string) (*rsa.PublicKey, error) {
+	if s == nil {
+		return nil, nil
+	}
+	if key := s.find(kid); key != nil && time.Since(s.fetchedAt) < keySetTTL {
+		return key, nil
+	}
+	// Keys rotate, so an unknown ID is worth one refetch
+	if time.Since(s.fetchedAt) < keySetRefetchInterval {
+		if key := s.find(kid); key != nil {
+			return key, nil
+		}
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	return nil, nil
+}
+
+func (s *cachedKeySet) find(kid string) *rsa.PublicKey {
+	if kid == "" {
+		if len(s.keys) == 1 {
+			for _, key := range s.keys {
+				return key
+			}
+		}
+		return nil
+	}
+	return s.keys[kid]
+}
+
+func (k *KeySet) fetch(ctx context.Context, jwksURL string) (*cachedKeySet, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, jwksURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := k.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch platform keys: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("platform keys returned status %d", resp.StatusCode)
+	}
+
+	var jwks JWKS
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxKeySetSize)).Decode(&jwks); err != nil {
+		return nil, fmt.Errorf("invalid platform key set: %w", err)
+	}
+
+	set := &cachedKeySet{keys: make(map[string]*rsa.PublicKey), fetchedAt: time.Now()}
+	for _, jwk := range jwks.Keys {
+		if jwk.Kty != "RSA" || (jwk.Use != "" && jwk.Use != "sig") {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			continue
+		}
+		set.keys[jwk.Kid] = key
+	}
+	if len(set.keys) == 0 {
+		return nil, errors.New("platform key set has no RSA signing keys")
+	}
+	return set, nil
+}
+
+func (j JWK) publicKey() (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(j.N)
+	if err != nil {
+		return nil, err
+	}
+	e, err := base64.RawURLEncoding.DecodeString(j.E)
+	if err != nil {
+		return nil, err
+	}
+	exponent := new(big.Int).SetBytes(e)
+	if !exponent.IsInt64() || exponent.Int64() < 3 || exponent.Int64() > 1<<31-1 {
+		return nil, errors.New("invalid RSA exponent")
+	}
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}, nil
+}
@@ -0,0 +1,94 @@
+// Package ltiplatform implements the tool side of the LTI 1.3 security
+// framework: validating the id_tokens platforms launch SecuSense with,
+// signing the tool's own messages and posting grades through Assignment and
+// Grade Services.
+package ltiplatform
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"math/big"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/secusense/backend/internal/domain"
+)
+
+const toolKeyBits = 2048
+
+// JWK is an RSA public key in JSON Web Key form
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// ToolKey is a key the tool signs deep linking responses and grade service
+// token requests with
+type ToolKey struct {
+	KID     string
+	Private *rsa.PrivateKey
+}
+
+// GenerateToolKey creates a new signing key. Its ID is the RFC 7638
+// thumbprint of the public key.
+func GenerateToolKey() (*domain.LTIToolKey, error) {
+	private, err := rsa.GenerateKey(rand.Reader, toolKeyBits)
+	if err != nil {
+		return nil, err
+	}
+	block := &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(private)}
+	return &domain.LTIToolKey{
+		KID:        thumbprint(&private.PublicKey),
+		PrivateKey: string(pem.EncodeToMemory(block)),
+	}, nil
+}
+
+func ParseToolKey(key *domain.LTIToolKey) (*ToolKey, error) {
+	block, _ := pem.Decode([]byte(key.PrivateKey))
+	if block == nil {
+		return nil, errors.New("tool key is not PEM encoded")
+	}
+	private, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	return &ToolKey{KID: key.KID, Private: private}, nil
+}
+
+// Sign returns the claims as an RS256 JWT carrying the key's ID
+func (k *ToolKey) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = k.KID
+	return token.SignedString(k.Private)
+}
+
+func (k *ToolKey) JWK() JWK {
+	return JWK{
+		Kty: "RSA",
+		Kid: k.KID,
+		Use: "sig",
+		Alg: "RS256",
+		N:   base64.RawURLEncoding.EncodeToString(k.Private.PublicKey.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(k.Private.PublicKey.E)).Bytes()),
+	}
+}
+
+func thumbprint(public *rsa.PublicKey) string {
+	n := base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+	e := base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+	// Members in lexicographic order, no whitespace
+	sum := sha256.Sum256([]byte(`{"e":"` + e + `","kty":"RSA","n":"` + n + `"}`))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,207 @@
+package ltiplatform
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/secusense/backend/internal/domain"
+)
+
+const (
+	LTIVersion = "1.3.0"
+
+	// ScopeScore lets the tool post scores to a line item
+	ScopeScore = "https://purl.imsglobal.org/spec/lti-ags/scope/score"
+
+	// Context roles that may pick content in a deep linking launch
+	RoleInstructor       = "http://purl.imsglobal.org/vocab/lis/v2/membership#Instructor"
+	RoleContentDeveloper = "http://purl.imsglobal.org/vocab/lis/v2/membership#ContentDeveloper"
+	RoleAdministrator    = "http://purl.imsglobal.org/vocab/lis/v2/institution/person#Administrator"
+
+	// clockSkew tolerates platform clocks running slightly ahead or behind
+	clockSkew = time.Minute
+	// messageLifetime is how long the tool's own messages are valid
+	messageLifetime = 5 * time.Minute
+)
+
+var ErrInvalidIDToken = errors.New("invalid LTI id_token")
+
+// LaunchClaims are the claims of a launch's id_token
+type LaunchClaims struct {
+	jwt.RegisteredClaims
+	AuthorizedParty string `json:"azp,omitempty"`
+	Nonce           string `json:"nonce"`
+	Email           string `json:"email,omitempty"`
+	GivenName       string `json:"given_name,omitempty"`
+	FamilyName      string `json:"family_name,omitempty"`
+	Name            string `json:"name,omitempty"`
+
+	MessageType   domain.LTIMessageType  `json:"https://purl.imsglobal.org/spec/lti/claim/message_type"`
+	Version       string                 `json:"https://purl.imsglobal.org/spec/lti/claim/version"`
+	DeploymentID  string                 `json:"https://purl.imsglobal.org/spec/lti/claim/deployment_id"`
+	TargetLinkURI string                 `json:"https://purl.imsglobal.org/spec/lti/claim/target_link_uri,omitempty"`
+	Roles         []string               `json:"https://purl.imsglobal.org/spec/lti/claim/roles"`
+	Custom        map[string]interface{} `json:"https://purl.imsglobal.org/spec/lti/claim/custom,omitempty"`
+	ResourceLink  *ResourceLink          `json:"https://purl.imsglobal.org/spec/lti/claim/resource_link,omitempty"`
+	DeepLinking   *DeepLinkingSettings   `json:"https://purl.imsglobal.org/spec/lti-dl/claim/deep_linking_settings,omitempty"`
+	AGS           *AGSEndpoint           `json:"https://purl.imsglobal.org/spec/lti-ags/claim/endpoint,omitempty"`
+}
+
+type ResourceLink struct {
+	ID    string `json:"id"`
+	Title string `json:"title,omitempty"`
+}
+
+type DeepLinkingSettings struct {
+	ReturnURL   string   `json:"deep_link_return_url"`
+	AcceptTypes []string `json:"accept_types"`
+	Data        string   `json:"data,omitempty"`
+}
+
+// Accepts reports whether the platform takes content items of a type
+func (s *DeepLinkingSettings) Accepts(itemType string) bool {
+	for _, t := range s.AcceptTypes {
+		if t == itemType {
+			return true
+		}
+	}
+	return false
+}
+
+// AGSEndpoint is where the platform accepts grades for the launch
+type AGSEndpoint struct {
+	Scope     []string `json:"scope"`
+	LineItems string   `json:"lineitems,omitempty"`
+	LineItem  string   `json:"lineitem,omitempty"`
+}
+
+func (e *AGSEndpoint) HasScope(scope string) bool {
+	for _, s := range e.Scope {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// HasRole reports whether the launching user has one of the given roles
+func (c *LaunchClaims) HasRole(roles ...string) bool {
+	for _, have := range c.Roles {
+		for _, want := range roles {
+			if have == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// CustomValue returns a custom parameter as a string
+func (c *LaunchClaims) CustomValue(key string) string {
+	v, ok := c.Custom[key]
+	if !ok || v == nil {
+		return ""
+	}
+	if s, ok := v.(string); ok {
+		return s
+	}
+	return fmt.Sprint(v)
+}
+
+// ParseIDToken verifies an id_token's signature against the platform's keys
+// and checks it was issued by the platform for this tool. Nonce, deployment
+// and message type are left to the caller.
+func (k *KeySet) ParseIDToken(ctx context.Context, platform *domain.LTIPlatform, idToken string) (*LaunchClaims, error) {
+	claims := &LaunchClaims{}
+	_, err := jwt.ParseWithClaims(idToken, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return k.PublicKey(ctx, platform.JWKSURL, kid)
+	},
+		jwt.WithValidMethods([]string{"RS256"}),
+		jwt.WithIssuer(platform.Issuer),
+		jwt.WithAudience(platform.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(clockSkew),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+
+	// With several audiences the token must name this tool as the party it
+	// was issued to
+	if len(claims.Audience) > 1 && claims.AuthorizedParty != platform.ClientID {
+		return nil, fmt.Errorf("%w: azp does not match the client ID", ErrInvalidIDToken)
+	}
+	if claims.AuthorizedParty != "" && claims.AuthorizedParty != platform.ClientID {
+		return nil, fmt.Errorf("%w: azp does not match the client ID", ErrInvalidIDToken)
+	}
+	if claims.IssuedAt == nil {
+		return nil, fmt.Errorf("%w: missing iat", ErrInvalidIDToken)
+	}
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: anonymous launches are not supported", ErrInvalidIDToken)
+	}
+	if claims.Version != LTIVersion {
+		return nil, fmt.Errorf("%w: unsupported LTI version %q", ErrInvalidIDToken, claims.Version)
+	}
+	if claims.DeploymentID == "" {
+		return nil, fmt.Errorf("%w: missing deployment_id", ErrInvalidIDToken)
+	}
+	return claims, nil
+}
+
+// ContentItem is a link the tool offers in a deep linking response
+type ContentItem struct {
+	Type     string            `json:"type"`
+	Title    string            `json:"title,omitempty"`
+	Text     string            `json:"text,omitempty"`
+	URL      string            `json:"url,omitempty"`
+	Custom   map[string]string `json:"custom,omitempty"`
+	LineItem *LineItem         `json:"lineItem,omitempty"`
+}
+
+// ContentItemTypeResourceLink launches the tool when followed
+const ContentItemTypeResourceLink = "ltiResourceLink"
+
+// LineItem asks the platform to create a gradebook column for a link
+type LineItem struct {
+	ScoreMaximum float64 `json:"scoreMaximum"`
+	Label        string  `json:"label"`
+	ResourceID   string  `json:"resourceId,omitempty"`
+}
+
+type deepLinkingResponseClaims struct {
+	jwt.RegisteredClaims
+	Nonce        string        `json:"nonce"`
+	MessageType  string        `json:"https://purl.imsglobal.org/spec/lti/claim/message_type"`
+	Version      string        `json:"https://purl.imsglobal.org/spec/lti/claim/version"`
+	DeploymentID string        `json:"https://purl.imsglobal.org/spec/lti/claim/deployment_id"`
+	ContentItems []ContentItem `json:"https://purl.imsglobal.org/spec/lti-dl/claim/content_items"`
+	Data         string        `json:"https://purl.imsglobal.org/spec/lti-dl/claim/data,omitempty"`
+}
+
+// SignDeepLinkingResponse returns the JWT that hands the chosen content items
+// back to the platform. data echoes the request's settings.
+func (k *ToolKey) SignDeepLinkingResponse(platform *domain.LTIPlatform, deploymentID, data string, items []ContentItem) (string, error) {
+	now := time.Now()
+	return k.Sign(&deepLinkingResponseClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    platform.ClientID,
+			Audience:  jwt.ClaimStrings{platform.Issuer},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(messageLifetime)),
+			ID:        uuid.NewString(),
+		},
+		Nonce:        uuid.NewString(),
+		MessageType:  "LtiDeepLinkingResponse",
+		Version:      LTIVersion,
+		DeploymentID: deploymentID,
+		ContentItems: items,
+		Data:         data,
+	})
+}
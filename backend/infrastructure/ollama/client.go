@@ -8,12 +8,10 @@ import (
 	"io"
 	"log"
 	"net/http"
-	"regexp"
 	"strings"
 	"time"
 
 	"github.com/secusense/backend/config"
-	"github.com/secusense/backend/internal/domain"
 )
 
 const (
@@ -190,747 +188,9 @@ type generateResponse struct {
 	Done     bool   `json:"done"`
 }
 
-// rawCourseContent is used for initial parsing with flexible question handling
-type rawCourseContent struct {
-	Title              string                   `json:"title"`
-	Description        string                   `json:"description"`
-	LearningObjectives []string                 `json:"learningObjectives"`
-	Outline            []domain.CourseOutlineChapter `json:"outline"`
-	VideoScript        string                   `json:"videoScript"`
-	Questions          []json.RawMessage        `json:"questions"`
-}
-
-// rawQuestion for flexible question parsing
-type rawQuestion struct {
-	QuestionType string          `json:"questionType"`
-	QuestionText string          `json:"questionText"`
-	QuestionData json.RawMessage `json:"questionData"`
-	Points       interface{}     `json:"points"` // Can be int or string
-}
-
-func (c *Client) GenerateCourseContent(ctx context.Context, req *domain.GenerateCourseRequest) (*domain.GeneratedCourseContent, error) {
-	prompt := c.buildCoursePrompt(req)
-
-	ollamaReq := generateRequest{
-		Model:  c.model,
-		Prompt: prompt,
-		Stream: false,
-		Format: "json",
-	}
-
-	body, err := json.Marshal(ollamaReq)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
-	}
-
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/api/generate", bytes.NewReader(body))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-	httpReq.Header.Set("Content-Type", "application/json")
-	c.setAuthHeader(httpReq)
-
-	resp, err := c.httpClient.Do(httpReq)
-	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("ollama returned status %d: %s", resp.StatusCode, string(bodyBytes))
-	}
-
-	var ollamaResp generateResponse
-	if err := json.NewDecoder(resp.Body).Decode(&ollamaResp); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
-	}
-
-	// Log raw response for debugging
-	log.Printf("Ollama raw response length: %d bytes", len(ollamaResp.Response))
-
-	// Clean and parse the response
-	content, err := c.parseAndValidateContent(ollamaResp.Response)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse generated content: %w", err)
-	}
-
-	return content, nil
-}
-
-func (c *Client) parseAndValidateContent(rawJSON string) (*domain.GeneratedCourseContent, error) {
-	// Try to extract JSON if wrapped in markdown code blocks
-	rawJSON = extractJSON(rawJSON)
-
-	// First parse into raw structure
-	var raw rawCourseContent
-	if err := json.Unmarshal([]byte(rawJSON), &raw); err != nil {
-		return nil, fmt.Errorf("JSON unmarshal failed: %w, raw: %s", err, truncate(rawJSON, 500))
-	}
-
-	// Build the final content
-	content := &domain.GeneratedCourseContent{
-		Title:              raw.Title,
-		Description:        raw.Description,
-		LearningObjectives: raw.LearningObjectives,
-		Outline:            raw.Outline,
-		VideoScript:        raw.VideoScript,
-		Questions:          make([]domain.GeneratedQuestion, 0),
-	}
-
-	// Parse questions with flexible handling
-	for i, qRaw := range raw.Questions {
-		q, err := c.parseQuestion(qRaw)
-		if err != nil {
-			log.Printf("Warning: Failed to parse question %d: %v", i, err)
-			continue
-		}
-		if q != nil {
-			content.Questions = append(content.Questions, *q)
-		}
-	}
-
-	log.Printf("Parsed content: title=%q, questions=%d", content.Title, len(content.Questions))
-
-	// If no questions were parsed, generate default questions
-	if len(content.Questions) == 0 {
-		log.Printf("No questions parsed, generating defaults for topic: %s", content.Title)
-		content.Questions = c.generateDefaultQuestions(content.Title)
-	}
-
-	return content, nil
-}
-
-func (c *Client) parseQuestion(rawQ json.RawMessage) (*domain.GeneratedQuestion, error) {
-	var rq rawQuestion
-	if err := json.Unmarshal(rawQ, &rq); err != nil {
-		return nil, fmt.Errorf("unmarshal question: %w", err)
-	}
-
-	// Validate question type
-	qType := normalizeQuestionType(rq.QuestionType)
-	if qType == "" {
-		return nil, fmt.Errorf("invalid question type: %s", rq.QuestionType)
-	}
-
-	// Parse points (handle string or int)
-	points := 10
-	switch v := rq.Points.(type) {
-	case float64:
-		points = int(v)
-	case int:
-		points = v
-	case string:
-		fmt.Sscanf(v, "%d", &points)
-	}
-
-	// Validate and fix questionData based on type
-	questionData, err := c.validateQuestionData(qType, rq.QuestionData)
-	if err != nil {
-		return nil, fmt.Errorf("invalid question data for type %s: %w", qType, err)
-	}
-
-	return &domain.GeneratedQuestion{
-		QuestionType: qType,
-		QuestionText: rq.QuestionText,
-		QuestionData: questionData,
-		Points:       points,
-	}, nil
-}
-
-func (c *Client) validateQuestionData(qType domain.QuestionType, data json.RawMessage) (json.RawMessage, error) {
-	if len(data) == 0 {
-		return nil, fmt.Errorf("empty question data")
-	}
-
-	switch qType {
-	case domain.QuestionTypeMultipleChoice:
-		var mc domain.MultipleChoiceData
-		if err := json.Unmarshal(data, &mc); err != nil {
-			return nil, err
-		}
-		if len(mc.Options) < 2 {
-			return nil, fmt.Errorf("multiple choice needs at least 2 options")
-		}
-		if len(mc.CorrectIndices) == 0 {
-			mc.CorrectIndices = []int{0} // Default to first option
-		}
-		return json.Marshal(mc)
-
-	case domain.QuestionTypeDragDrop:
-		var dd domain.DragDropData
-		if err := json.Unmarshal(data, &dd); err != nil {
-			return nil, err
-		}
-		if len(dd.Items) == 0 || len(dd.DropZones) == 0 {
-			return nil, fmt.Errorf("drag drop needs items and drop zones")
-		}
-		if dd.CorrectMapping == nil {
-			dd.CorrectMapping = make(map[string]string)
-		}
-		return json.Marshal(dd)
-
-	case domain.QuestionTypeFillBlank:
-		var fb domain.FillBlankData
-		if err := json.Unmarshal(data, &fb); err != nil {
-			return nil, err
-		}
-		if fb.Template == "" || len(fb.Blanks) == 0 {
-			return nil, fmt.Errorf("fill blank needs template and blanks")
-		}
-		return json.Marshal(fb)
-
-	case domain.QuestionTypeMatching:
-		var m domain.MatchingData
-		if err := json.Unmarshal(data, &m); err != nil {
-			return nil, err
-		}
-		if len(m.LeftItems) == 0 || len(m.RightItems) == 0 {
-			return nil, fmt.Errorf("matching needs left and right items")
-		}
-		if m.CorrectPairs == nil {
-			m.CorrectPairs = make(map[string]string)
-		}
-		return json.Marshal(m)
-
-	case domain.QuestionTypeOrdering:
-		var o domain.OrderingData
-		if err := json.Unmarshal(data, &o); err != nil {
-			return nil, err
-		}
-		if len(o.Items) == 0 {
-			return nil, fmt.Errorf("ordering needs items")
-		}
-		if len(o.CorrectOrder) == 0 {
-			// Generate sequential order
-			o.CorrectOrder = make([]int, len(o.Items))
-			for i := range o.Items {
-				o.CorrectOrder[i] = i
-			}
-		}
-		return json.Marshal(o)
-	}
-
-	return data, nil
-}
-
-func normalizeQuestionType(t string) domain.QuestionType {
-	t = strings.ToLower(strings.TrimSpace(t))
-	t = strings.ReplaceAll(t, "-", "_")
-	t = strings.ReplaceAll(t, " ", "_")
-
-	switch t {
-	case "multiple_choice", "multiplechoice", "mc", "mcq":
-		return domain.QuestionTypeMultipleChoice
-	case "drag_drop", "dragdrop", "drag_and_drop", "draganddrop":
-		return domain.QuestionTypeDragDrop
-	case "fill_blank", "fillblank", "fill_in_blank", "fill_in_the_blank", "fillintheblank":
-		return domain.QuestionTypeFillBlank
-	case "matching", "match":
-		return domain.QuestionTypeMatching
-	case "ordering", "order", "sequence":
-		return domain.QuestionTypeOrdering
-	}
-	return ""
-}
-
-func (c *Client) generateDefaultQuestions(topic string) []domain.GeneratedQuestion {
-	questions := []domain.GeneratedQuestion{
-		{
-			QuestionType: domain.QuestionTypeMultipleChoice,
-			QuestionText: fmt.Sprintf("What is the main purpose of %s?", topic),
-			QuestionData: mustMarshal(domain.MultipleChoiceData{
-				Options:        []string{"To improve security", "To reduce costs", "To increase speed", "To simplify processes"},
-				CorrectIndices: []int{0},
-				Explanation:    "Security improvement is the primary goal.",
-			}),
-			Points: 10,
-		},
-		{
-			QuestionType: domain.QuestionTypeMultipleChoice,
-			QuestionText: fmt.Sprintf("Which of the following is a best practice for %s?", topic),
-			QuestionData: mustMarshal(domain.MultipleChoiceData{
-				Options:        []string{"Follow industry standards", "Ignore guidelines", "Use shortcuts", "Skip verification"},
-				CorrectIndices: []int{0},
-				Explanation:    "Following industry standards ensures proper implementation.",
-			}),
-			Points: 10,
-		},
-		{
-			QuestionType: domain.QuestionTypeMultipleChoice,
-			QuestionText: "What should you do first when implementing security measures?",
-			QuestionData: mustMarshal(domain.MultipleChoiceData{
-				Options:        []string{"Assess current risks", "Buy new software", "Change all passwords", "Disable all access"},
-				CorrectIndices: []int{0},
-				Explanation:    "Risk assessment helps identify what needs protection.",
-			}),
-			Points: 10,
-		},
-		{
-			QuestionType: domain.QuestionTypeMultipleChoice,
-			QuestionText: "How often should security practices be reviewed?",
-			QuestionData: mustMarshal(domain.MultipleChoiceData{
-				Options:        []string{"Regularly and after incidents", "Only once a year", "Never", "Only when problems occur"},
-				CorrectIndices: []int{0},
-				Explanation:    "Regular reviews and post-incident analysis ensure continued protection.",
-			}),
-			Points: 10,
-		},
-		{
-			QuestionType: domain.QuestionTypeFillBlank,
-			QuestionText: "Complete the security statement:",
-			QuestionData: mustMarshal(domain.FillBlankData{
-				Template:    "A strong {{blank}} policy is essential for {{blank}} protection.",
-				Blanks:      []string{"security", "data"},
-				Explanation: "Security policies protect organizational data.",
-			}),
-			Points: 10,
-		},
-		{
-			QuestionType: domain.QuestionTypeFillBlank,
-			QuestionText: "Fill in the security terms:",
-			QuestionData: mustMarshal(domain.FillBlankData{
-				Template:    "{{blank}} testing helps identify {{blank}} before attackers do.",
-				Blanks:      []string{"Penetration", "vulnerabilities"},
-				Explanation: "Penetration testing proactively finds security weaknesses.",
-			}),
-			Points: 10,
-		},
-		{
-			QuestionType: domain.QuestionTypeOrdering,
-			QuestionText: "Arrange the incident response steps in the correct order:",
-			QuestionData: mustMarshal(domain.OrderingData{
-				Items:        []string{"Identify the threat", "Contain the damage", "Eradicate the cause", "Recover systems", "Document lessons"},
-				CorrectOrder: []int{0, 1, 2, 3, 4},
-				Explanation:  "Proper incident response follows: Identify, Contain, Eradicate, Recover, Document.",
-			}),
-			Points: 10,
-		},
-		{
-			QuestionType: domain.QuestionTypeMatching,
-			QuestionText: "Match the security terms with their descriptions:",
-			QuestionData: mustMarshal(domain.MatchingData{
-				LeftItems:    []string{"Encryption", "Authentication", "Authorization"},
-				RightItems:   []string{"Verifying identity", "Scrambling data", "Granting permissions"},
-				CorrectPairs: map[string]string{"Encryption": "Scrambling data", "Authentication": "Verifying identity", "Authorization": "Granting permissions"},
-				Explanation:  "Understanding security terminology is fundamental.",
-			}),
-			Points: 10,
-		},
-		{
-			QuestionType: domain.QuestionTypeDragDrop,
-			QuestionText: "Categorize these items as either 'Good Practice' or 'Bad Practice':",
-			QuestionData: mustMarshal(domain.DragDropData{
-				Items:          []string{"Regular updates", "Sharing passwords", "Using MFA", "Ignoring alerts"},
-				DropZones:      []string{"Good Practice", "Bad Practice"},
-				CorrectMapping: map[string]string{"Regular updates": "Good Practice", "Sharing passwords": "Bad Practice", "Using MFA": "Good Practice", "Ignoring alerts": "Bad Practice"},
-				Explanation:    "Security awareness includes knowing good from bad practices.",
-			}),
-			Points: 10,
-		},
-		{
-			QuestionType: domain.QuestionTypeDragDrop,
-			QuestionText: "Sort these actions by priority level:",
-			QuestionData: mustMarshal(domain.DragDropData{
-				Items:          []string{"Patch critical vulnerability", "Update documentation", "Review logs", "Plan training"},
-				DropZones:      []string{"High Priority", "Medium Priority"},
-				CorrectMapping: map[string]string{"Patch critical vulnerability": "High Priority", "Review logs": "High Priority", "Update documentation": "Medium Priority", "Plan training": "Medium Priority"},
-				Explanation:    "Critical security issues take priority over administrative tasks.",
-			}),
-			Points: 10,
-		},
-	}
-	return questions
-}
-
-func mustMarshal(v interface{}) json.RawMessage {
-	data, _ := json.Marshal(v)
-	return data
-}
-
-func extractJSON(s string) string {
-	// Remove markdown code blocks
-	re := regexp.MustCompile("```(?:json)?\\s*([\\s\\S]*?)```")
-	matches := re.FindStringSubmatch(s)
-	if len(matches) > 1 {
-		return strings.TrimSpace(matches[1])
-	}
-
-	// Try to find JSON object boundaries
-	start := strings.Index(s, "{")
-	end := strings.LastIndex(s, "}")
-	if start >= 0 && end > start {
-		return s[start : end+1]
-	}
-
-	return strings.TrimSpace(s)
-}
-
-func truncate(s string, maxLen int) string {
-	if len(s) <= maxLen {
-		return s
-	}
-	return s[:maxLen] + "..."
-}
-
-func (c *Client) buildCoursePrompt(req *domain.GenerateCourseRequest) string {
-	duration := req.VideoDurationMin
-	if duration == 0 {
-		duration = 5
-	}
-	wordCount := duration * 150
-
-	difficulty := req.DifficultyLevel
-	if difficulty == "" {
-		difficulty = "intermediate"
-	}
-
-	audience := req.TargetAudience
-	if audience == "" {
-		audience = "professionals"
-	}
-
-	return fmt.Sprintf(`Generate a complete training course about: %s
-
-Target audience: %s
-Difficulty level: %s
-Video script should be approximately %d words (for a %d minute video).
-
-You must respond with a valid JSON object in this exact format:
-{
-  "title": "Course title",
-  "description": "2-3 sentence course description",
-  "learningObjectives": ["objective 1", "objective 2", "objective 3"],
-  "outline": [
-    {
-      "title": "Chapter 1 title",
-      "description": "Brief description",
-      "topics": ["topic 1", "topic 2"]
-    }
-  ],
-  "videoScript": "Full video narration script...",
-  "questions": [
-    {
-      "questionType": "multiple_choice",
-      "questionText": "Question text?",
-      "questionData": {
-        "options": ["Option A", "Option B", "Option C", "Option D"],
-        "correctIndices": [0],
-        "explanation": "Explanation of correct answer"
-      },
-      "points": 10
-    },
-    {
-      "questionType": "drag_drop",
-      "questionText": "Match items to categories",
-      "questionData": {
-        "items": ["Item 1", "Item 2"],
-        "dropZones": ["Zone A", "Zone B"],
-        "correctMapping": {"Item 1": "Zone A", "Item 2": "Zone B"},
-        "explanation": "Explanation"
-      },
-      "points": 10
-    },
-    {
-      "questionType": "fill_blank",
-      "questionText": "Fill in the blanks",
-      "questionData": {
-        "template": "The {{blank}} is used for {{blank}}.",
-        "blanks": ["answer1", "answer2"],
-        "explanation": "Explanation"
-      },
-      "points": 10
-    },
-    {
-      "questionType": "matching",
-      "questionText": "Match the terms",
-      "questionData": {
-        "leftItems": ["Term 1", "Term 2"],
-        "rightItems": ["Definition 1", "Definition 2"],
-        "correctPairs": {"Term 1": "Definition 1", "Term 2": "Definition 2"},
-        "explanation": "Explanation"
-      },
-      "points": 10
-    },
-    {
-      "questionType": "ordering",
-      "questionText": "Put in correct order",
-      "questionData": {
-        "items": ["Step 1", "Step 2", "Step 3"],
-        "correctOrder": [0, 1, 2],
-        "explanation": "Explanation"
-      },
-      "points": 10
-    }
-  ]
-}
-
-Generate exactly 10 questions with this distribution:
-- 4 multiple_choice questions
-- 2 drag_drop questions
-- 2 fill_blank questions
-- 1 matching question
-- 1 ordering question
-
-Each question should be worth 10 points for a total of 100 points.
-Ensure all JSON is properly formatted and valid.`, req.Topic, audience, difficulty, wordCount, duration)
-}
-
-// ====== Multi-Agency Workflow Methods ======
-
-// ResearchTopicSuggestions generates topic suggestions for a main topic (Research Agency)
-func (c *Client) ResearchTopicSuggestions(ctx context.Context, mainTopic, targetAudience, difficulty, language string, count int) ([]TopicSuggestionResult, error) {
-	if count <= 0 {
-		count = 6
-	}
-
-	prompt := c.buildResearchPrompt(mainTopic, targetAudience, difficulty, language, count)
-
-	result, err := c.generateJSON(ctx, prompt)
-	if err != nil {
-		return nil, err
-	}
-
-	var suggestions struct {
-		Suggestions []TopicSuggestionResult `json:"suggestions"`
-	}
-	if err := json.Unmarshal([]byte(result), &suggestions); err != nil {
-		return nil, fmt.Errorf("failed to parse suggestions: %w", err)
-	}
-
-	return suggestions.Suggestions, nil
-}
-
-// TopicSuggestionResult from the Research Agency
-type TopicSuggestionResult struct {
-	Title       string `json:"title"`
-	Description string `json:"description"`
-}
-
-// getLanguageInstruction returns the instruction for generating content in the specified language
-func getLanguageInstruction(language string) string {
-	languageNames := map[string]string{
-		"en": "English",
-		"de": "German",
-		"fr": "French",
-		"es": "Spanish",
-		"it": "Italian",
-		"pt": "Portuguese",
-	}
-	langName, ok := languageNames[language]
-	if !ok {
-		langName = "English"
-	}
-	return fmt.Sprintf("IMPORTANT: Generate ALL content (titles, descriptions, text) in %s language.", langName)
-}
-
-func (c *Client) buildResearchPrompt(mainTopic, targetAudience, difficulty, language string, count int) string {
-	audience := targetAudience
-	if audience == "" {
-		audience = "professionals"
-	}
-	if difficulty == "" {
-		difficulty = "intermediate"
-	}
-	if language == "" {
-		language = "en"
-	}
-
-	languageInstruction := getLanguageInstruction(language)
-
-	return fmt.Sprintf(`You are a Research Agency specializing in cybersecurity training course design.
-
-Your task is to generate %d subtopic suggestions for a comprehensive training course on the following main topic:
-
-Main Topic: %s
-Target Audience: %s
-Difficulty Level: %s
-
-%s
-
-Generate specific, actionable subtopics that would make excellent individual training modules. Each subtopic should:
-1. Be focused and specific enough for a single lesson (5-15 minutes)
-2. Build practical skills the audience can apply immediately
-3. Cover different aspects of the main topic
-4. Progress logically from foundational to advanced concepts
-
-Respond with ONLY valid JSON in this exact format:
-{
-  "suggestions": [
-    {
-      "title": "Short, descriptive title (max 100 chars)",
-      "description": "2-3 sentence description of what this module will cover and why it's important"
-    }
-  ]
-}`, count, mainTopic, audience, difficulty, languageInstruction)
-}
-
-// RefineTopics takes approved suggestions and creates detailed topic outlines (Refinement Agency)
-func (c *Client) RefineTopics(ctx context.Context, mainTopic string, suggestions []TopicSuggestionResult, targetAudience, difficulty, language string) ([]RefinedTopicResult, error) {
-	prompt := c.buildRefinementPrompt(mainTopic, suggestions, targetAudience, difficulty, language)
-
-	result, err := c.generateJSON(ctx, prompt)
-	if err != nil {
-		return nil, err
-	}
-
-	var refined struct {
-		Topics []RefinedTopicResult `json:"topics"`
-	}
-	if err := json.Unmarshal([]byte(result), &refined); err != nil {
-		return nil, fmt.Errorf("failed to parse refined topics: %w", err)
-	}
-
-	return refined.Topics, nil
-}
-
-// RefinedTopicResult from the Refinement Agency
-type RefinedTopicResult struct {
-	OriginalTitle    string   `json:"originalTitle"`
-	Title            string   `json:"title"`
-	Description      string   `json:"description"`
-	LearningGoals    []string `json:"learningGoals"`
-	KeyPoints        []string `json:"keyPoints"`
-	EstimatedTimeMin int      `json:"estimatedTimeMin"`
-}
-
-func (c *Client) buildRefinementPrompt(mainTopic string, suggestions []TopicSuggestionResult, targetAudience, difficulty, language string) string {
-	audience := targetAudience
-	if audience == "" {
-		audience = "professionals"
-	}
-	if difficulty == "" {
-		difficulty = "intermediate"
-	}
-	if language == "" {
-		language = "en"
-	}
-
-	topicsJSON, _ := json.Marshal(suggestions)
-	languageInstruction := getLanguageInstruction(language)
-
-	return fmt.Sprintf(`You are a Refinement Agency specializing in instructional design for cybersecurity training.
-
-Your task is to refine and expand the following approved topics into detailed lesson outlines:
-
-Main Course Topic: %s
-Target Audience: %s
-Difficulty Level: %s
-
-%s
-
-Approved Topics to Refine:
-%s
-
-For each topic, create a detailed outline including:
-1. A refined, compelling title
-2. An expanded description
-3. 3-5 specific learning goals (what the learner will be able to do)
-4. 4-6 key points to cover
-5. Estimated time in minutes (typically 5-15 min per lesson)
-
-Respond with ONLY valid JSON in this exact format:
-{
-  "topics": [
-    {
-      "originalTitle": "Original title from input",
-      "title": "Refined, compelling title",
-      "description": "Detailed 2-3 sentence description",
-      "learningGoals": ["Goal 1", "Goal 2", "Goal 3"],
-      "keyPoints": ["Point 1", "Point 2", "Point 3", "Point 4"],
-      "estimatedTimeMin": 10
-    }
-  ]
-}`, mainTopic, audience, difficulty, languageInstruction, string(topicsJSON))
-}
-
-// GenerateLessonScripts creates detailed video scripts for refined topics (Script Agency)
-func (c *Client) GenerateLessonScripts(ctx context.Context, mainTopic string, topics []RefinedTopicResult, targetAudience, difficulty, language string) ([]LessonScriptResult, error) {
-	var scripts []LessonScriptResult
-
-	for _, topic := range topics {
-		script, err := c.generateSingleScript(ctx, mainTopic, topic, targetAudience, difficulty, language)
-		if err != nil {
-			return nil, fmt.Errorf("failed to generate script for %s: %w", topic.Title, err)
-		}
-		scripts = append(scripts, *script)
-	}
-
-	return scripts, nil
-}
-
-// LessonScriptResult from the Script Agency
-type LessonScriptResult struct {
-	TopicTitle  string `json:"topicTitle"`
-	Title       string `json:"title"`
-	Script      string `json:"script"`
-	DurationMin int    `json:"durationMin"`
-}
-
-func (c *Client) generateSingleScript(ctx context.Context, mainTopic string, topic RefinedTopicResult, targetAudience, difficulty, language string) (*LessonScriptResult, error) {
-	audience := targetAudience
-	if audience == "" {
-		audience = "professionals"
-	}
-	if difficulty == "" {
-		difficulty = "intermediate"
-	}
-	if language == "" {
-		language = "en"
-	}
-
-	learningGoalsJSON, _ := json.Marshal(topic.LearningGoals)
-	keyPointsJSON, _ := json.Marshal(topic.KeyPoints)
-	languageInstruction := getLanguageInstruction(language)
-
-	prompt := fmt.Sprintf(`You are a Script Agency specializing in creating engaging video scripts for cybersecurity training.
-
-Your task is to create a complete, ready-to-record video script for the following lesson:
-
-Course: %s
-Lesson Title: %s
-Description: %s
-Learning Goals: %s
-Key Points to Cover: %s
-Target Duration: %d minutes
-Target Audience: %s
-Difficulty Level: %s
-
-%s
-
-Create an engaging, professional video script that:
-1. Opens with a hook that captures attention
-2. Clearly states what the viewer will learn
-3. Covers all key points with examples and practical advice
-4. Uses clear, conversational language suitable for video
-5. Includes natural transitions between sections
-6. Ends with a summary and call-to-action
-7. Is appropriately paced for the target duration (roughly 150 words per minute)
-
-Respond with ONLY valid JSON in this exact format:
-{
-  "topicTitle": "%s",
-  "title": "Video title",
-  "script": "Full video script text here...",
-  "durationMin": %d
-}`, mainTopic, topic.Title, topic.Description, string(learningGoalsJSON), string(keyPointsJSON),
-		topic.EstimatedTimeMin, audience, difficulty, languageInstruction, topic.Title, topic.EstimatedTimeMin)
-
-	result, err := c.generateJSON(ctx, prompt)
-	if err != nil {
-		return nil, err
-	}
-
-	var script LessonScriptResult
-	if err := json.Unmarshal([]byte(result), &script); err != nil {
-		return nil, fmt.Errorf("failed to parse script: %w", err)
-	}
-
-	return &script, nil
-}
-
-// generateJSON is a helper for making JSON-formatted requests to Ollama
-func (c *Client) generateJSON(ctx context.Context, prompt string) (string, error) {
+// CompleteJSON sends a prompt to /api/generate in JSON mode and returns the
+// model's answer with any markdown code fences stripped
+func (c *Client) CompleteJSON(ctx context.Context, prompt string) (string, error) {
 	ollamaReq := generateRequest{
 		Model:  c.model,
 		Prompt: prompt,
@@ -1008,222 +268,3 @@ func (c *Client) HealthCheck(ctx context.Context) error {
 
 	return nil
 }
-
-// QuizQuestionResult represents a generated quiz question
-type QuizQuestionResult struct {
-	QuestionType string          `json:"questionType"`
-	QuestionText string          `json:"questionText"`
-	QuestionData json.RawMessage `json:"questionData"`
-	Points       int             `json:"points"`
-}
-
-// GenerateQuizQuestions generates quiz questions based on the course content
-func (c *Client) GenerateQuizQuestions(ctx context.Context, courseTitle string, lessonScripts []string, language string, count int) ([]domain.GeneratedQuestion, error) {
-	if count <= 0 {
-		count = 10
-	}
-	if language == "" {
-		language = "en"
-	}
-
-	// Combine lesson scripts into context
-	contentSummary := ""
-	for i, script := range lessonScripts {
-		contentSummary += fmt.Sprintf("\n--- Lesson %d ---\n%s\n", i+1, script)
-	}
-
-	languageInstruction := getLanguageInstruction(language)
-
-	prompt := fmt.Sprintf(`You are a Quiz Designer specializing in creating comprehensive assessments for cybersecurity training.
-
-Your task is to create %d quiz questions based on the following course content:
-
-Course Title: %s
-Content:
-%s
-
-%s
-
-Create exactly %d questions with this distribution:
-- 4 multiple_choice questions
-- 2 drag_drop questions
-- 2 fill_blank questions
-- 1 matching question
-- 1 ordering question
-
-Each question should:
-1. Be directly related to the course content
-2. Test understanding, not just memorization
-3. Have clear, unambiguous answers
-4. Include helpful explanations
-
-Respond with ONLY valid JSON in this exact format:
-{
-  "questions": [
-    {
-      "questionType": "multiple_choice",
-      "questionText": "Question text?",
-      "questionData": {
-        "options": ["Option A", "Option B", "Option C", "Option D"],
-        "correctIndices": [0],
-        "explanation": "Explanation of correct answer"
-      },
-      "points": 10
-    },
-    {
-      "questionType": "drag_drop",
-      "questionText": "Match items to categories",
-      "questionData": {
-        "items": ["Item 1", "Item 2"],
-        "dropZones": ["Zone A", "Zone B"],
-        "correctMapping": {"Item 1": "Zone A", "Item 2": "Zone B"},
-        "explanation": "Explanation"
-      },
-      "points": 10
-    },
-    {
-      "questionType": "fill_blank",
-      "questionText": "Fill in the blanks",
-      "questionData": {
-        "template": "The {{blank}} is used for {{blank}}.",
-        "blanks": ["answer1", "answer2"],
-        "explanation": "Explanation"
-      },
-      "points": 10
-    },
-    {
-      "questionType": "matching",
-      "questionText": "Match the terms",
-      "questionData": {
-        "leftItems": ["Term 1", "Term 2"],
-        "rightItems": ["Definition 1", "Definition 2"],
-        "correctPairs": {"Term 1": "Definition 1", "Term 2": "Definition 2"},
-        "explanation": "Explanation"
-      },
-      "points": 10
-    },
-    {
-      "questionType": "ordering",
-      "questionText": "Put in correct order",
-      "questionData": {
-        "items": ["Step 1", "Step 2", "Step 3"],
-        "correctOrder": [0, 1, 2],
-        "explanation": "Explanation"
-      },
-      "points": 10
-    }
-  ]
-}
-
-Ensure all questions are worth 10 points each for a total of 100 points.`, count, courseTitle, contentSummary, languageInstruction, count)
-
-	result, err := c.generateJSON(ctx, prompt)
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate questions: %w", err)
-	}
-
-	var questionsResult struct {
-		Questions []QuizQuestionResult `json:"questions"`
-	}
-	if err := json.Unmarshal([]byte(result), &questionsResult); err != nil {
-		return nil, fmt.Errorf("failed to parse questions: %w", err)
-	}
-
-	// Convert to domain questions
-	var domainQuestions []domain.GeneratedQuestion
-	for _, q := range questionsResult.Questions {
-		qType := normalizeQuestionType(q.QuestionType)
-		if qType == "" {
-			log.Printf("Warning: Skipping question with invalid type: %s", q.QuestionType)
-			continue
-		}
-
-		// Validate question data
-		validatedData, err := c.validateQuestionData(qType, q.QuestionData)
-		if err != nil {
-			log.Printf("Warning: Skipping question with invalid data: %v", err)
-			continue
-		}
-
-		points := q.Points
-		if points <= 0 {
-			points = 10
-		}
-
-		domainQuestions = append(domainQuestions, domain.GeneratedQuestion{
-			QuestionType: qType,
-			QuestionText: q.QuestionText,
-			QuestionData: validatedData,
-			Points:       points,
-		})
-	}
-
-	// If no questions generated, use defaults
-	if len(domainQuestions) == 0 {
-		log.Printf("No valid questions generated, using defaults for: %s", courseTitle)
-		domainQuestions = c.generateDefaultQuestions(courseTitle)
-	}
-
-	return domainQuestions, nil
-}
-
-// PresentationSlideResult represents a single slide generated by the AI
-type PresentationSlideResult struct {
-	Title         string `json:"title"`
-	Content       string `json:"content"`       // HTML/Markdown for the slide
-	Script        string `json:"script"`        // Narration text for TTS
-	ImageKeywords string `json:"imageKeywords"` // Keywords for stock image search
-}
-
-// GeneratePresentationSlides creates presentation slides from a lesson script
-func (c *Client) GeneratePresentationSlides(ctx context.Context, lessonTitle string, script string, language string) ([]PresentationSlideResult, error) {
-	if language == "" {
-		language = "en"
-	}
-
-	languageInstruction := getLanguageInstruction(language)
-
-	prompt := fmt.Sprintf(`You are a Presentation Designer specializing in creating engaging educational slide presentations.
-
-Your task is to convert the following lesson script into a series of presentation slides:
-
-Lesson Title: %s
-Script:
-%s
-
-%s
-
-Create 5-8 presentation slides that:
-1. Break the content into logical sections
-2. Each slide should have a clear title
-3. Content should be concise bullet points or short paragraphs (suitable for slides)
-4. Include the narration script (what the presenter says) separately from the visual content
-5. Use HTML formatting for the content (bullet lists with <ul><li>, bold with <strong>, etc.)
-6. For each slide, provide 2-4 keywords for finding a relevant stock photo (e.g., "cybersecurity network protection", "data encryption lock")
-
-Respond with ONLY valid JSON in this exact format:
-{
-  "slides": [
-    {
-      "title": "Slide title",
-      "content": "<ul><li>Bullet point 1</li><li>Bullet point 2</li></ul>",
-      "script": "Full narration text for this slide that will be converted to audio...",
-      "imageKeywords": "keyword1 keyword2 keyword3"
-    }
-  ]
-}`, lessonTitle, script, languageInstruction)
-
-	result, err := c.generateJSON(ctx, prompt)
-	if err != nil {
-		return nil, err
-	}
-
-	var slides struct {
-		Slides []PresentationSlideResult `json:"slides"`
-	}
-	if err := json.Unmarshal([]byte(result), &slides); err != nil {
-		return nil, fmt.Errorf("failed to parse slides: %w", err)
-	}
-
-	return slides.Slides, nil
-}
@@ -0,0 +1,128 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/secusense/backend/config"
+)
+
+// Client talks to any server implementing the OpenAI chat completions API
+// (OpenAI itself, vLLM, llama.cpp server, LM Studio, ...)
+type Client struct {
+	baseURL    string
+	model      string
+	apiKey     string
+	jsonMode   bool
+	httpClient *http.Client
+}
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type responseFormat struct {
+	Type string `json:"type"`
+}
+
+type chatRequest struct {
+	Model          string          `json:"model"`
+	Messages       []chatMessage   `json:"messages"`
+	Stream         bool            `json:"stream"`
+	ResponseFormat *responseFormat `json:"response_format,omitempty"`
+}
+
+type chatResponse struct {
+	Choices []struct {
+		Message chatMessage `json:"message"`
+	} `json:"choices"`
+}
+
+const systemPrompt = "You are a helpful assistant that always responds with a single valid JSON document and nothing else."
+
+func NewClient(cfg config.OpenAIConfig) *Client {
+	c := &Client{
+		baseURL:  strings.TrimRight(cfg.BaseURL, "/"),
+		model:    cfg.Model,
+		apiKey:   cfg.APIKey,
+		jsonMode: cfg.JSONMode,
+		httpClient: &http.Client{
+			Timeout: cfg.Timeout,
+		},
+	}
+
+	log.Printf("OpenAI-compatible client initialized: baseURL=%s, model=%s", c.baseURL, c.model)
+
+	return c
+}
+
+// CompleteJSON sends the prompt as a chat completion and returns the
+// assistant's answer with any markdown code fences stripped
+func (c *Client) CompleteJSON(ctx context.Context, prompt string) (string, error) {
+	chatReq := chatRequest{
+		Model: c.model,
+		Messages: []chatMessage{
+			{Role: "system", Content: systemPrompt},
+			{Role: "user", Content: prompt},
+		},
+		Stream: false,
+	}
+	// Not every compatible server supports JSON mode, so it can be switched off
+	if c.jsonMode {
+		chatReq.ResponseFormat = &responseFormat{Type: "json_object"}
+	}
+
+	body, err := json.Marshal(chatReq)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if c.apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return "", fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("chat completions returned status %d: %s", resp.StatusCode, string(bodyBytes))
+	}
+
+	var chatResp chatResponse
+	if err := json.NewDecoder(resp.Body).Decode(&chatResp); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+	if len(chatResp.Choices) == 0 {
+		return "", fmt.Errorf("chat completions returned no choices")
+	}
+
+	result := strings.TrimSpace(chatResp.Choices[0].Message.Content)
+
+	// Strip markdown code fences some models add despite the instructions
+	if strings.HasPrefix(result, "```json") {
+		result = strings.TrimPrefix(result, "```json")
+	} else if strings.HasPrefix(result, "```") {
+		result = strings.TrimPrefix(result, "```")
+	}
+	if idx := strings.LastIndex(result, "```"); idx != -1 {
+		result = result[:idx]
+	}
+
+	return strings.TrimSpace(result), nil
+}
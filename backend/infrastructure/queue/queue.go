@@ -0,0 +1,347 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/secusense/backend/config"
+	"github.com/secusense/backend/internal/domain"
+)
+
+// HandlerFunc processes a leased job. Returning an error schedules a retry
+// until the job runs out of attempts. Handlers may set job.CourseID and
+// job.OutputData; both are persisted when the job completes.
+type HandlerFunc func(ctx context.Context, job *domain.AIGenerationJob) error
+
+// FailureFunc is called once a job has failed for the last time.
+type FailureFunc func(job *domain.AIGenerationJob, err error)
+
+type registration struct {
+	handle HandlerFunc
+	onFail FailureFunc
+}
+
+// Queue is a durable job queue on top of the ai_generation_jobs table.
+// Jobs survive restarts: a worker holds a lease on the job it runs, and
+// jobs whose lease expires (crashed worker, killed process) are picked up
+// again by any instance.
+type Queue struct {
+	repo     domain.AIGenerationJobRepository
+	cfg      config.QueueConfig
+	workerID string
+
+	mu       sync.RWMutex
+	handlers map[domain.JobType]registration
+
+	wake    chan struct{}
+	stop    chan struct{}
+	running context.Context
+	abort   context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+func New(repo domain.AIGenerationJobRepository, cfg config.QueueConfig) *Queue {
+	if cfg.Workers <= 0 {
+		cfg.Workers = 1
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = 2 * time.Second
+	}
+	if cfg.LeaseDuration <= 0 {
+		cfg.LeaseDuration = 2 * time.Minute
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 1
+	}
+	if cfg.RetryBackoff <= 0 {
+		cfg.RetryBackoff = 30 * time.Second
+	}
+	if cfg.MaxRetryBackoff < cfg.RetryBackoff {
+		cfg.MaxRetryBackoff = cfg.RetryBackoff
+	}
+
+	hostname, _ := os.Hostname()
+	running, abort := context.WithCancel(context.Background())
+
+	return &Queue{
+		repo:     repo,
+		cfg:      cfg,
+		workerID: fmt.Sprintf("%s-%s", hostname, uuid.NewString()[:8]),
+		handlers: make(map[domain.JobType]registration),
+		wake:     make(chan struct{}, 1),
+		stop:     make(chan struct{}),
+		running:  running,
+		abort:    abort,
+	}
+}
+
+// Register installs the handler for a job type. onFail may be nil.
+// Handlers must be registered before Start.
+func (q *Queue) Register(jobType domain.JobType, handle HandlerFunc, onFail FailureFunc) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.handlers[jobType] = registration{handle: handle, onFail: onFail}
+}
+
+// Enqueue stores a new job with the JSON-encoded payload as input data.
+func (q *Queue) Enqueue(jobType domain.JobType, payload interface{}) (*domain.AIGenerationJob, error) {
+	inputData, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode job payload: %w", err)
+	}
+
+	job := &domain.AIGenerationJob{
+		ID:          uuid.New(),
+		JobType:     jobType,
+		Status:      domain.JobStatusPending,
+		InputData:   inputData,
+		MaxAttempts: q.cfg.MaxAttempts,
+	}
+	if err := q.repo.Create(job); err != nil {
+		return nil, err
+	}
+
+	// Nudge an idle worker so the job doesn't wait for the next poll
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+
+	return job, nil
+}
+
+// Start recovers jobs orphaned by a previous run and launches the workers.
+func (q *Queue) Start() {
+	if n, err := q.repo.RecoverExpired(); err != nil {
+		log.Printf("[Queue] ERROR: Failed to recover orphaned jobs: %v", err)
+	} else if n > 0 {
+		log.Printf("[Queue] Recovered %d orphaned job(s)", n)
+	}
+
+	jobTypes := q.jobTypes()
+	log.Printf("[Queue] Starting %d worker(s) as %s for %d job type(s)", q.cfg.Workers, q.workerID, len(jobTypes))
+
+	for i := 0; i < q.cfg.Workers; i++ {
+		q.wg.Add(1)
+		go q.work(jobTypes)
+	}
+
+	q.wg.Add(1)
+	go q.reap()
+}
+
+// Stop stops leasing new jobs and waits for running ones to finish. When ctx
+// expires first, running jobs are cancelled and handed back to the queue.
+func (q *Queue) Stop(ctx context.Context) error {
+	close(q.stop)
+
+	done := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		q.abort()
+		<-done
+		return ctx.Err()
+	}
+}
+
+func (q *Queue) jobTypes() []domain.JobType {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	types := make([]domain.JobType, 0, len(q.handlers))
+	for t := range q.handlers {
+		types = append(types, t)
+	}
+	return types
+}
+
+func (q *Queue) work(jobTypes []domain.JobType) {
+	defer q.wg.Done()
+
+	for {
+		select {
+		case <-q.stop:
+			return
+		default:
+		}
+
+		job, err := q.repo.Lease(q.workerID, jobTypes, q.cfg.LeaseDuration)
+		if err != nil {
+			log.Printf("[Queue] ERROR: Failed to lease job: %v", err)
+		}
+		if job != nil {
+			q.run(job)
+			continue
+		}
+
+		select {
+		case <-q.stop:
+			return
+		case <-q.wake:
+		case <-time.After(q.cfg.PollInterval):
+		}
+	}
+}
+
+// reap periodically returns jobs with expired leases to the queue, so work
+// held by a crashed instance is picked up without waiting for a restart.
+func (q *Queue) reap() {
+	defer q.wg.Done()
+
+	ticker := time.NewTicker(q.cfg.LeaseDuration)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-q.stop:
+			return
+		case <-ticker.C:
+			if n, err := q.repo.RecoverExpired(); err != nil {
+				log.Printf("[Queue] ERROR: Failed to recover expired jobs: %v", err)
+			} else if n > 0 {
+				log.Printf("[Queue] Recovered %d job(s) with expired leases", n)
+			}
+		}
+	}
+}
+
+func (q *Queue) run(job *domain.AIGenerationJob) {
+	q.mu.RLock()
+	reg, ok := q.handlers[job.JobType]
+	q.mu.RUnlock()
+
+	if !ok {
+		q.fail(job, reg, fmt.Errorf("no handler registered for job type %s", job.JobType))
+		return
+	}
+
+	// A job that keeps killing its worker would otherwise be recovered forever
+	if job.Attempts > job.MaxAttempts {
+		q.fail(job, reg, errors.New("job exceeded its maximum attempts"))
+		return
+	}
+
+	log.Printf("[Queue] Running job %s (%s), attempt %d/%d", job.ID, job.JobType, job.Attempts, job.MaxAttempts)
+
+	ctx, cancel := context.WithCancelCause(q.running)
+	heartbeatDone := make(chan struct{})
+	go q.heartbeat(ctx, cancel, job.ID, heartbeatDone)
+
+	err := q.invoke(ctx, reg.handle, job)
+	cancel(nil)
+	<-heartbeatDone
+
+	switch {
+	case errors.Is(context.Cause(ctx), domain.ErrLeaseLost):
+		// Another worker runs the job now and records its outcome
+		log.Printf("[Queue] Job %s (%s) lost its lease, dropping this run", job.ID, job.JobType)
+
+	case err == nil:
+		if err := q.repo.Complete(job, q.workerID); err != nil {
+			log.Printf("[Queue] ERROR: Failed to mark job %s completed: %v", job.ID, err)
+			return
+		}
+		log.Printf("[Queue] Job %s (%s) completed", job.ID, job.JobType)
+
+	case q.running.Err() != nil:
+		// Shutdown interrupted the job; it didn't fail on its own
+		log.Printf("[Queue] Job %s interrupted by shutdown, releasing", job.ID)
+		if err := q.repo.Release(job.ID, q.workerID); err != nil {
+			log.Printf("[Queue] ERROR: Failed to release job %s: %v", job.ID, err)
+		}
+
+	case job.Attempts >= job.MaxAttempts:
+		q.fail(job, reg, err)
+
+	default:
+		runAt := time.Now().Add(q.backoff(job.Attempts))
+		log.Printf("[Queue] Job %s (%s) failed: %v - retrying at %s", job.ID, job.JobType, err, runAt.Format(time.RFC3339))
+		if err := q.repo.Retry(job, q.workerID, runAt, err.Error()); err != nil {
+			log.Printf("[Queue] ERROR: Failed to reschedule job %s: %v", job.ID, err)
+		}
+	}
+}
+
+// invoke runs the handler, turning a panic into an ordinary job failure.
+func (q *Queue) invoke(ctx context.Context, handle HandlerFunc, job *domain.AIGenerationJob) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+	return handle(ctx, job)
+}
+
+// heartbeat extends the job's lease while it runs. If the lease was lost
+// meanwhile, the job is cancelled so two workers don't run it at once.
+func (q *Queue) heartbeat(ctx context.Context, cancel context.CancelCauseFunc, jobID uuid.UUID, done chan<- struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(q.cfg.LeaseDuration / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := q.repo.ExtendLease(jobID, q.workerID, q.cfg.LeaseDuration); err != nil {
+				if errors.Is(err, domain.ErrLeaseLost) {
+					log.Printf("[Queue] WARNING: Job %s lost its lease, cancelling it", jobID)
+					cancel(err)
+					return
+				}
+				log.Printf("[Queue] WARNING: Failed to extend lease for job %s: %v", jobID, err)
+			}
+		}
+	}
+}
+
+func (q *Queue) fail(job *domain.AIGenerationJob, reg registration, err error) {
+	log.Printf("[Queue] ERROR: Job %s (%s) failed permanently: %v", job.ID, job.JobType, err)
+	if err := q.repo.Fail(job, q.workerID, err.Error()); err != nil {
+		if errors.Is(err, domain.ErrLeaseLost) {
+			// The worker that took the job over decides its fate
+			log.Printf("[Queue] Job %s lost its lease, leaving it to its new worker", job.ID)
+			return
+		}
+		log.Printf("[Queue] ERROR: Failed to mark job %s failed: %v", job.ID, err)
+	}
+	if reg.onFail != nil {
+		reg.onFail(job, err)
+	}
+}
+
+// backoff doubles the retry delay with every attempt, up to the configured cap.
+func (q *Queue) backoff(attempt int) time.Duration {
+	delay := q.cfg.RetryBackoff
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= q.cfg.MaxRetryBackoff {
+			return q.cfg.MaxRetryBackoff
+		}
+	}
+	return delay
+}
+
+// DecodePayload unmarshals a job's input data into v.
+func DecodePayload(job *domain.AIGenerationJob, v interface{}) error {
+	if err := json.Unmarshal(job.InputData, v); err != nil {
+		return fmt.Errorf("invalid payload for job %s: %w", job.ID, err)
+	}
+	return nil
+}
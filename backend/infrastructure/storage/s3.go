@@ -0,0 +1,276 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/secusense/backend/config"
+)
+
+const (
+	sigV4Algorithm  = "AWS4-HMAC-SHA256"
+	amzDateFormat   = "20060102T150405Z"
+	unsignedPayload = "UNSIGNED-PAYLOAD"
+	// maxPresignTTL is the longest S3 accepts a presigned URL for
+	maxPresignTTL = 7 * 24 * time.Hour
+)
+
+// S3 stores objects in a bucket of an S3-compatible service, authenticating
+// with AWS Signature Version 4. MinIO and most other services need
+// path-style addressing; AWS also takes virtual-hosted style.
+type S3 struct {
+	endpoint   *url.URL
+	region     string
+	bucket     string
+	accessKey  string
+	secretKey  string
+	pathStyle  bool
+	httpClient *http.Client
+}
+
+func NewS3(cfg config.S3Config) (*S3, error) {
+	if cfg.Bucket == "" || cfg.AccessKey == "" || cfg.SecretKey == "" {
+		return nil, errors.New("s3 storage needs a bucket, an access key and a secret key")
+	}
+	region := cfg.Region
+	if region == "" {
+		region = "us-east-1"
+	}
+	rawEndpoint := cfg.Endpoint
+	if rawEndpoint == "" {
+		rawEndpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", region)
+	}
+	endpoint, err := url.Parse(strings.TrimSuffix(rawEndpoint, "/"))
+	if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid s3 endpoint %q", rawEndpoint)
+	}
+
+	return &S3{
+		endpoint:  endpoint,
+		region:    region,
+		bucket:    cfg.Bucket,
+		accessKey: cfg.AccessKey,
+		secretKey: cfg.SecretKey,
+		pathStyle: cfg.PathStyle,
+		httpClient: &http.Client{
+			Timeout: 60 * time.Second,
+		},
+	}, nil
+}
+
+func (s *S3) Put(ctx context.Context, key, contentType string, data []byte) error {
+	resp, err := s.do(ctx, http.MethodPut, key, contentType, data)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return responseError("put", key, resp)
+	}
+	return nil
+}
+
+func (s *S3) Get(ctx context.Context, key string) ([]byte, error) {
+	resp, err := s.do(ctx, http.MethodGet, key, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return io.ReadAll(resp.Body)
+	case http.StatusNotFound:
+		return nil, ErrNotFound
+	default:
+		return nil, responseError("get", key, resp)
+	}
+}
+
+func (s *S3) Delete(ctx context.Context, key string) error {
+	resp, err := s.do(ctx, http.MethodDelete, key, "", nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		return responseError("delete", key, resp)
+	}
+	return nil
+}
+
+// SignedURL presigns a GET of the object. The bucket stays private.
+func (s *S3) SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	if !ValidKey(key) {
+		return "", ErrInvalidKey
+	}
+	return s.presign(key, ttl, time.Now()), nil
+}
+
+func (s *S3) presign(key string, ttl time.Duration, now time.Time) string {
+	if ttl > maxPresignTTL {
+		ttl = maxPresignTTL
+	}
+	now = now.UTC()
+	u := s.objectURL(key)
+
+	query := map[string]string{
+		"X-Amz-Algorithm":     sigV4Algorithm,
+		"X-Amz-Credential":    s.accessKey + "/" + s.scope(now),
+		"X-Amz-Date":          now.Format(amzDateFormat),
+		"X-Amz-Expires":       fmt.Sprint(int(ttl.Seconds())),
+		"X-Amz-SignedHeaders": "host",
+	}
+	canonicalQuery := canonicalQueryString(query)
+	canonicalRequest := strings.Join([]string{
+		http.MethodGet,
+		u.EscapedPath(),
+		canonicalQuery,
+		"host:" + u.Host + "\n",
+		"host",
+		unsignedPayload,
+	}, "\n")
+
+	u.RawQuery = canonicalQuery + "&X-Amz-Signature=" + s.signature(canonicalRequest, now)
+	return u.String()
+}
+
+// do sends a request for an object signed in its Authorization header
+func (s *S3) do(ctx context.Context, method, key, contentType string, body []byte) (*http.Response, error) {
+	if !ValidKey(key) {
+		return nil, ErrInvalidKey
+	}
+	now := time.Now().UTC()
+	u := s.objectURL(key)
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	payloadHash := sha256.Sum256(body)
+	headers := map[string]string{
+		"host":                 u.Host,
+		"x-amz-content-sha256": hex.EncodeToString(payloadHash[:]),
+		"x-amz-date":           now.Format(amzDateFormat),
+	}
+	if contentType != "" {
+		headers["content-type"] = contentType
+	}
+
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+		if name != "host" {
+			req.Header.Set(name, headers[name])
+		}
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		method,
+		u.EscapedPath(),
+		"",
+		canonicalHeaders.String(),
+		signedHeaders,
+		headers["x-amz-content-sha256"],
+	}, "\n")
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		sigV4Algorithm, s.accessKey, s.scope(now), signedHeaders, s.signature(canonicalRequest, now)))
+
+	return s.httpClient.Do(req)
+}
+
+// objectURL addresses an object path-style (endpoint/bucket/key) or
+// virtual-hosted style (bucket.endpoint/key). Valid keys need no escaping.
+func (s *S3) objectURL(key string) *url.URL {
+	u := *s.endpoint
+	if s.pathStyle {
+		u.Path = "/" + s.bucket + "/" + key
+	} else {
+		u.Host = s.bucket + "." + u.Host
+		u.Path = "/" + key
+	}
+	return &u
+}
+
+func (s *S3) scope(now time.Time) string {
+	return now.Format("20060102") + "/" + s.region + "/s3/aws4_request"
+}
+
+// signature signs a canonical request with the key derived for the day,
+// region and service
+func (s *S3) signature(canonicalRequest string, now time.Time) string {
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		sigV4Algorithm,
+		now.Format(amzDateFormat),
+		s.scope(now),
+		hex.EncodeToString(requestHash[:]),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.secretKey), now.Format("20060102"))
+	key = hmacSHA256(key, s.region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// canonicalQueryString sorts the parameters by name and escapes them as
+// Signature Version 4 expects
+func canonicalQueryString(params map[string]string) string {
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		pairs[i] = uriEncode(name) + "=" + uriEncode(params[name])
+	}
+	return strings.Join(pairs, "&")
+}
+
+// uriEncode percent-encodes everything but unreserved characters
+func uriEncode(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func responseError(op, key string, resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return fmt.Errorf("s3 %s %s returned status %d: %s", op, key, resp.StatusCode, strings.TrimSpace(string(body)))
+}
@@ -0,0 +1,100 @@
+// Package storage keeps the files the portal generates: certificate PDFs,
+// narration audio and slide images. Objects live in a local directory or an
+// S3-compatible bucket (MinIO works as a local stand-in) and are handed out
+// through signed, time-limited URLs.
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/secusense/backend/config"
+)
+
+var (
+	ErrNotFound   = errors.New("object not found")
+	ErrInvalidKey = errors.New("invalid object key")
+)
+
+// Key prefixes of the objects the portal stores. Audio and images are
+// public media; certificates are only handed out to their holders.
+const (
+	AudioPrefix       = "audio/"
+	ImagePrefix       = "images/"
+	CertificatePrefix = "certificates/"
+)
+
+// mediaURLPrefix is the stable URL of public media, which redirects to a
+// signed URL
+const mediaURLPrefix = "/api/v1/media/"
+
+// Storage stores objects by key. Keys are slash-separated paths such as
+// "audio/<uuid>.mp3".
+type Storage interface {
+	Put(ctx context.Context, key, contentType string, data []byte) error
+	// Get returns ErrNotFound if there is no object under the key
+	Get(ctx context.Context, key string) ([]byte, error)
+	Delete(ctx context.Context, key string) error
+	// SignedURL returns a URL the object can be downloaded from without
+	// credentials until the TTL has passed
+	SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error)
+}
+
+// New creates the storage backend the configuration selects
+func New(cfg config.StorageConfig) (Storage, error) {
+	switch cfg.Backend {
+	case "", "local":
+		return NewLocal(cfg.LocalDir, cfg.APIURL, cfg.SigningKey)
+	case "s3":
+		return NewS3(cfg.S3)
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", cfg.Backend)
+	}
+}
+
+// MediaURL is the stable URL of a public media object, to store in
+// presentations instead of a signed URL that expires
+func MediaURL(key string) string {
+	return mediaURLPrefix + key
+}
+
+// MediaKey returns the key of a media URL
+func MediaKey(url string) (string, bool) {
+	key, ok := strings.CutPrefix(url, mediaURLPrefix)
+	return key, ok && IsPublic(key)
+}
+
+// IsPublic reports whether an object is public media anyone may download
+func IsPublic(key string) bool {
+	return ValidKey(key) && (strings.HasPrefix(key, AudioPrefix) || strings.HasPrefix(key, ImagePrefix))
+}
+
+// CertificateKey is the key of a certificate's PDF
+func CertificateKey(certificateID string) string {
+	return CertificatePrefix + certificateID + ".pdf"
+}
+
+// ValidKey reports whether a key is a relative path of letters, digits,
+// dots, dashes and underscores that stays inside the storage
+func ValidKey(key string) bool {
+	if key == "" || len(key) > 512 || strings.HasPrefix(key, "/") || strings.HasSuffix(key, "/") {
+		return false
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return false
+		}
+	}
+	for _, c := range key {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '/', c == '.', c == '-', c == '_':
+		default:
+			return false
+		}
+	}
+	return true
+}
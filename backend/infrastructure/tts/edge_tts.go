@@ -10,6 +10,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/secusense/backend/config"
+	"github.com/secusense/backend/infrastructure/storage"
 )
 
 // Voice represents an available TTS voice
@@ -21,40 +22,39 @@ type Voice struct {
 
 // Client handles text-to-speech generation using Edge TTS
 type Client struct {
-	voice     string
-	outputDir string
+	voice string
+	store storage.Storage
 }
 
-// NewClient creates a new Edge TTS client
-func NewClient(cfg config.TTSConfig) *Client {
+// NewClient creates a new Edge TTS client that keeps the audio in the
+// storage
+func NewClient(cfg config.TTSConfig, store storage.Storage) *Client {
 	voice := cfg.Voice
 	if voice == "" {
 		voice = "de-DE-KatjaNeural" // Default German female voice
 	}
 
-	outputDir := cfg.OutputDir
-	if outputDir == "" {
-		outputDir = "./generated/audio"
-	}
-
-	// Ensure output directory exists
-	os.MkdirAll(outputDir, 0755)
-
 	return &Client{
-		voice:     voice,
-		outputDir: outputDir,
+		voice: voice,
+		store: store,
 	}
 }
 
-// GenerateAudio generates an audio file from text using Edge TTS
-// Returns the relative URL path to the generated audio file
+// GenerateAudio generates an audio file from text using Edge TTS and stores
+// it. Returns the media URL of the audio file.
 func (c *Client) GenerateAudio(ctx context.Context, text string, language string) (string, error) {
 	// Select appropriate voice based on language
 	voice := c.getVoiceForLanguage(language)
 
-	// Generate unique filename
+	// edge-tts writes to a scratch file, which is uploaded
+	scratchDir, err := os.MkdirTemp("", "secusense-tts-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(scratchDir)
+
 	filename := fmt.Sprintf("%s.mp3", uuid.New().String())
-	outputPath := filepath.Join(c.outputDir, filename)
+	outputPath := filepath.Join(scratchDir, filename)
 
 	// Build edge-tts command
 	cmd := exec.CommandContext(ctx, "edge-tts",
@@ -69,8 +69,16 @@ func (c *Client) GenerateAudio(ctx context.Context, text string, language string
 		return "", fmt.Errorf("edge-tts failed: %w, output: %s", err, string(output))
 	}
 
-	// Return the relative URL path
-	return fmt.Sprintf("/api/v1/audio/%s", filename), nil
+	data, err := os.ReadFile(outputPath)
+	if err != nil {
+		return "", err
+	}
+	key := storage.AudioPrefix + filename
+	if err := c.store.Put(ctx, key, "audio/mpeg", data); err != nil {
+		return "", fmt.Errorf("failed to store audio: %w", err)
+	}
+
+	return storage.MediaURL(key), nil
 }
 
 // GenerateAudioBatch generates multiple audio files for a list of texts
@@ -128,8 +136,3 @@ func (c *Client) IsAvailable() bool {
 	_, err := exec.LookPath("edge-tts")
 	return err == nil
 }
-
-// GetOutputDir returns the configured output directory
-func (c *Client) GetOutputDir() string {
-	return c.outputDir
-}
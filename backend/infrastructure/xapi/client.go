@@ -0,0 +1,88 @@
+package xapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/secusense/backend/config"
+)
+
+// StatusError is an LRS response other than 2xx
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("LRS returned status %d: %s", e.StatusCode, e.Body)
+}
+
+// Rejected reports whether the LRS refused the statements themselves, so
+// sending them again can't succeed. Auth errors, a wrong endpoint, rate
+// limits and outages are retried.
+func (e *StatusError) Rejected() bool {
+	if e.StatusCode < 400 || e.StatusCode >= 500 {
+		return false
+	}
+	switch e.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound,
+		http.StatusRequestTimeout, http.StatusConflict, http.StatusTooManyRequests:
+		return false
+	}
+	return true
+}
+
+// Client posts statements to the statements resource of an LRS
+type Client struct {
+	endpoint   string
+	username   string
+	password   string
+	httpClient *http.Client
+}
+
+func NewClient(cfg config.XAPIConfig) *Client {
+	return &Client{
+		endpoint: strings.TrimSuffix(cfg.Endpoint, "/"),
+		username: cfg.Username,
+		password: cfg.Password,
+		httpClient: &http.Client{
+			Timeout: cfg.Timeout,
+		},
+	}
+}
+
+// Send posts the statements in one request
+func (c *Client) Send(ctx context.Context, statements []json.RawMessage) error {
+	body, err := json.Marshal(statements)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint+"/statements", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Experience-API-Version", Version)
+	if c.username != "" || c.password != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return &StatusError{StatusCode: resp.StatusCode, Body: strings.TrimSpace(string(msg))}
+}
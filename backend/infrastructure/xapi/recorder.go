@@ -0,0 +1,220 @@
+package xapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/secusense/backend/config"
+	"github.com/secusense/backend/internal/domain"
+)
+
+const platform = "SecuSense"
+
+// Recorder turns learning activity into statements and puts them in the
+// outbox for the relay. Recording never fails the learner's request: errors
+// are logged and the statement is lost.
+type Recorder struct {
+	repo     domain.XAPIOutboxRepository
+	relay    *Relay
+	enabled  bool
+	homePage string
+}
+
+func NewRecorder(cfg config.XAPIConfig, repo domain.XAPIOutboxRepository, relay *Relay) *Recorder {
+	return &Recorder{
+		repo:     repo,
+		relay:    relay,
+		enabled:  cfg.Endpoint != "",
+		homePage: strings.TrimSuffix(cfg.PlatformURL, "/"),
+	}
+}
+
+// Enabled reports whether statements are recorded. Callers can skip the
+// lookups a statement needs when it isn't.
+func (r *Recorder) Enabled() bool {
+	return r.enabled
+}
+
+// CourseLaunched records that the learner opened an enrolled course
+func (r *Recorder) CourseLaunched(enrollment *domain.Enrollment, course *domain.Course) {
+	r.record(&Statement{
+		Actor:   r.agent(enrollment.UserID),
+		Verb:    VerbLaunched,
+		Object:  r.courseActivity(course),
+		Context: r.context(&enrollment.ID, nil, nil),
+	}, time.Now())
+}
+
+// VideoCompleted records that the learner watched the course video to the end
+func (r *Recorder) VideoCompleted(enrollment *domain.Enrollment, course *domain.Course) {
+	completion := true
+	r.record(&Statement{
+		Actor: r.agent(enrollment.UserID),
+		Verb:  VerbCompleted,
+		Object: r.activity(r.courseID(course.ID)+"/video", &ActivityDefinition{
+			Name: name(course.Title),
+			Type: ActivityTypeVideo,
+		}),
+		Result:  &Result{Completion: &completion},
+		Context: r.context(&enrollment.ID, []Activity{r.courseActivity(course)}, nil),
+	}, time.Now())
+}
+
+// CourseCompleted records that the learner finished the course
+func (r *Recorder) CourseCompleted(enrollment *domain.Enrollment, course *domain.Course) {
+	completion := true
+	at := time.Now()
+	if enrollment.CompletedAt != nil {
+		at = *enrollment.CompletedAt
+	}
+	r.record(&Statement{
+		Actor:   r.agent(enrollment.UserID),
+		Verb:    VerbCompleted,
+		Object:  r.courseActivity(course),
+		Result:  &Result{Completion: &completion, Duration: duration(at.Sub(enrollment.EnrolledAt))},
+		Context: r.context(&enrollment.ID, nil, nil),
+	}, at)
+}
+
+// SlideExperienced records that the learner viewed a slide (0-based index)
+// of a presentation lesson
+func (r *Recorder) SlideExperienced(enrollment *domain.Enrollment, course *domain.Course, lesson *domain.LessonScript, index int, slide *domain.PresentationSlide) {
+	lessonActivity := r.activity(r.lessonID(lesson.ID), &ActivityDefinition{
+		Name: name(lesson.Title),
+		Type: ActivityTypeLesson,
+	})
+	r.record(&Statement{
+		Actor: r.agent(enrollment.UserID),
+		Verb:  VerbExperienced,
+		Object: r.activity(fmt.Sprintf("%s/slides/%d", r.lessonID(lesson.ID), index+1), &ActivityDefinition{
+			Name: name(slide.Title),
+			Type: ActivityTypeSlide,
+		}),
+		Context: r.context(&enrollment.ID, []Activity{lessonActivity}, []Activity{r.courseActivity(course)}),
+	}, time.Now())
+}
+
+// QuestionAnswered records a graded answer of a finished attempt.
+// registration is the learner's enrollment in the test's course, if any.
+// Answers the learner wrote in their own words are left out.
+func (r *Recorder) QuestionAnswered(attempt *domain.TestAttempt, registration *uuid.UUID, test *domain.Test, question *domain.Question, answer *domain.UserAnswer) {
+	success := answer.IsCorrect
+	result := &Result{
+		Score:   newScore(answer.PointsAwarded, question.Points),
+		Success: &success,
+	}
+	if !freeText(question.QuestionType) {
+		result.Response = string(answer.AnswerData)
+	}
+	if answer.TimeSpentSeconds != nil {
+		result.Duration = duration(time.Duration(*answer.TimeSpentSeconds) * time.Second)
+	}
+
+	r.record(&Statement{
+		Actor: r.agent(attempt.UserID),
+		Verb:  VerbAnswered,
+		Object: r.activity(r.homePage+"/questions/"+question.ID.String(), &ActivityDefinition{
+			Name:            name(question.QuestionText),
+			Type:            ActivityTypeInteraction,
+			InteractionType: interactionType(question.QuestionType),
+		}),
+		Result:  result,
+		Context: r.context(registration, []Activity{r.testActivity(test)}, []Activity{r.activity(r.courseID(test.CourseID), nil)}),
+	}, attemptTime(attempt))
+}
+
+// TestFinished records whether a finished attempt passed the test
+func (r *Recorder) TestFinished(attempt *domain.TestAttempt, registration *uuid.UUID, test *domain.Test) {
+	verb := VerbFailed
+	success := attempt.Passed != nil && *attempt.Passed
+	if success {
+		verb = VerbPassed
+	}
+	completion := true
+	result := &Result{Success: &success, Completion: &completion}
+	if attempt.Score != nil && attempt.MaxScore != nil {
+		result.Score = newScore(*attempt.Score, *attempt.MaxScore)
+	}
+	if attempt.CompletedAt != nil {
+		result.Duration = duration(attempt.CompletedAt.Sub(attempt.StartedAt))
+	}
+
+	r.record(&Statement{
+		Actor:   r.agent(attempt.UserID),
+		Verb:    verb,
+		Object:  r.testActivity(test),
+		Result:  result,
+		Context: r.context(registration, nil, []Activity{r.activity(r.courseID(test.CourseID), nil)}),
+	}, attemptTime(attempt))
+}
+
+func (r *Recorder) record(stmt *Statement, at time.Time) {
+	if !r.enabled {
+		return
+	}
+	stmt.ID = uuid.New()
+	stmt.Timestamp = at.UTC().Format(time.RFC3339Nano)
+
+	data, err := json.Marshal(stmt)
+	if err != nil {
+		log.Printf("[xAPI] ERROR: Failed to encode %s statement: %v", stmt.Verb.Display["en-US"], err)
+		return
+	}
+	if err := r.repo.Create(&domain.XAPIOutboxEntry{ID: stmt.ID, Statement: data}); err != nil {
+		log.Printf("[xAPI] ERROR: Failed to store %s statement: %v", stmt.Verb.Display["en-US"], err)
+		return
+	}
+	r.relay.Notify()
+}
+
+func (r *Recorder) agent(userID uuid.UUID) Agent {
+	return Agent{
+		ObjectType: "Agent",
+		Account:    Account{HomePage: r.homePage, Name: userID.String()},
+	}
+}
+
+func (r *Recorder) context(registration *uuid.UUID, parent, grouping []Activity) *Context {
+	ctx := &Context{Registration: registration, Platform: platform}
+	if len(parent) > 0 || len(grouping) > 0 {
+		ctx.ContextActivities = &ContextActivities{Parent: parent, Grouping: grouping}
+	}
+	return ctx
+}
+
+func (r *Recorder) activity(id string, def *ActivityDefinition) Activity {
+	return Activity{ObjectType: "Activity", ID: id, Definition: def}
+}
+
+func (r *Recorder) courseActivity(course *domain.Course) Activity {
+	return r.activity(r.courseID(course.ID), &ActivityDefinition{
+		Name: name(course.Title),
+		Type: ActivityTypeCourse,
+	})
+}
+
+func (r *Recorder) testActivity(test *domain.Test) Activity {
+	return r.activity(r.homePage+"/tests/"+test.ID.String(), &ActivityDefinition{
+		Name: name(test.Title),
+		Type: ActivityTypeAssessment,
+	})
+}
+
+func (r *Recorder) courseID(id uuid.UUID) string {
+	return r.homePage + "/courses/" + id.String()
+}
+
+func (r *Recorder) lessonID(id uuid.UUID) string {
+	return r.homePage + "/lessons/" + id.String()
+}
+
+func attemptTime(attempt *domain.TestAttempt) time.Time {
+	if attempt.CompletedAt != nil {
+		return *attempt.CompletedAt
+	}
+	return time.Now()
+}
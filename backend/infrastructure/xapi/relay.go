@@ -0,0 +1,256 @@
+package xapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/secusense/backend/config"
+	"github.com/secusense/backend/internal/domain"
+)
+
+// cleanupInterval is how often sent statements past their retention are purged
+const cleanupInterval = time.Hour
+
+// Relay sends the statements in the outbox to the LRS. Statements the LRS
+// can't take right now are retried with a growing delay, up to a limit;
+// statements it rejects as invalid or that run out of tries are marked
+// failed and kept for inspection.
+type Relay struct {
+	repo   domain.XAPIOutboxRepository
+	client *Client
+	cfg    config.XAPIConfig
+
+	wake    chan struct{}
+	stop    chan struct{}
+	running context.Context
+	abort   context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+func NewRelay(cfg config.XAPIConfig, repo domain.XAPIOutboxRepository) *Relay {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 50
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = 5 * time.Second
+	}
+	if cfg.RetryBackoff <= 0 {
+		cfg.RetryBackoff = 30 * time.Second
+	}
+	if cfg.MaxRetryBackoff < cfg.RetryBackoff {
+		cfg.MaxRetryBackoff = cfg.RetryBackoff
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 1
+	}
+
+	running, abort := context.WithCancel(context.Background())
+
+	return &Relay{
+		repo:    repo,
+		client:  NewClient(cfg),
+		cfg:     cfg,
+		wake:    make(chan struct{}, 1),
+		stop:    make(chan struct{}),
+		running: running,
+		abort:   abort,
+	}
+}
+
+// Notify nudges the relay so a new statement doesn't wait for the next poll
+func (r *Relay) Notify() {
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Start launches the relay. Without an LRS endpoint there is nothing to do.
+func (r *Relay) Start() {
+	if r.cfg.Endpoint == "" {
+		log.Printf("[xAPI] No LRS endpoint configured - statements will not be recorded")
+		return
+	}
+	log.Printf("[xAPI] Relaying statements to %s", r.cfg.Endpoint)
+
+	r.wg.Add(1)
+	go r.run()
+}
+
+// Stop waits for the batch being sent. When ctx expires first the request is
+// cancelled; its statements stay in the outbox and are sent after a restart.
+func (r *Relay) Stop(ctx context.Context) error {
+	close(r.stop)
+
+	done := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		r.abort()
+		<-done
+		return ctx.Err()
+	}
+}
+
+func (r *Relay) run() {
+	defer r.wg.Done()
+
+	r.cleanup()
+	lastCleanup := time.Now()
+
+	for {
+		select {
+		case <-r.stop:
+			return
+		default:
+		}
+
+		if time.Since(lastCleanup) >= cleanupInterval {
+			r.cleanup()
+			lastCleanup = time.Now()
+		}
+
+		// A full batch means there is probably more waiting
+		if r.relayBatch() == r.cfg.BatchSize {
+			continue
+		}
+
+		select {
+		case <-r.stop:
+			return
+		case <-r.wake:
+		case <-time.After(r.cfg.PollInterval):
+		}
+	}
+}
+
+// relayBatch sends the statements that are due and returns how many it claimed
+func (r *Relay) relayBatch() int {
+	// The claim only has to outlast the requests; if it runs out, another
+	// instance sends the statements again and the LRS recognises their IDs
+	leaseFor := r.cfg.Timeout*time.Duration(r.cfg.BatchSize+1) + time.Minute
+
+	entries, err := r.repo.ClaimDue(r.cfg.BatchSize, leaseFor)
+	if err != nil {
+		log.Printf("[xAPI] ERROR: Failed to claim statements: %v", err)
+		return 0
+	}
+	if len(entries) > 0 {
+		r.send(entries)
+	}
+	return len(entries)
+}
+
+func (r *Relay) send(entries []*domain.XAPIOutboxEntry) {
+	statements := make([]json.RawMessage, len(entries))
+	for i, e := range entries {
+		statements[i] = e.Statement
+	}
+
+	err := r.client.Send(r.running, statements)
+	if err == nil {
+		r.markSent(entries)
+		return
+	}
+
+	var statusErr *StatusError
+	isStatus := errors.As(err, &statusErr)
+
+	// One bad statement, or one the LRS already has, fails the whole batch;
+	// sending them one by one finds it
+	if isStatus && (statusErr.Rejected() || statusErr.StatusCode == http.StatusConflict) && len(entries) > 1 {
+		for _, e := range entries {
+			r.send([]*domain.XAPIOutboxEntry{e})
+		}
+		return
+	}
+
+	switch {
+	case isStatus && statusErr.StatusCode == http.StatusConflict:
+		// The statement was stored by an earlier attempt whose response got lost
+		r.markSent(entries)
+
+	case isStatus && statusErr.Rejected():
+		log.Printf("[xAPI] ERROR: LRS rejected statement %s: %v", entries[0].ID, err)
+		if err := r.repo.Fail(entries[0].ID, err.Error()); err != nil {
+			log.Printf("[xAPI] ERROR: Failed to mark statement %s failed: %v", entries[0].ID, err)
+		}
+
+	case r.running.Err() != nil:
+		// Shutdown cancelled the request; the claim runs out and the
+		// statements are sent by the next run
+
+	default:
+		var gaveUp int
+		for _, e := range entries {
+			if e.Attempts >= r.cfg.MaxAttempts {
+				gaveUp++
+				if err := r.repo.Fail(e.ID, err.Error()); err != nil {
+					log.Printf("[xAPI] ERROR: Failed to mark statement %s failed: %v", e.ID, err)
+				}
+				continue
+			}
+			next := time.Now().Add(r.backoff(e.Attempts))
+			if err := r.repo.Retry(e.ID, next, err.Error()); err != nil {
+				log.Printf("[xAPI] ERROR: Failed to reschedule statement %s: %v", e.ID, err)
+			}
+		}
+		if gaveUp > 0 {
+			log.Printf("[xAPI] ERROR: Gave up on %d statement(s) after %d attempts: %v", gaveUp, r.cfg.MaxAttempts, err)
+		}
+		if retried := len(entries) - gaveUp; retried > 0 {
+			log.Printf("[xAPI] Failed to send %d statement(s): %v - retrying", retried, err)
+		}
+	}
+}
+
+func (r *Relay) markSent(entries []*domain.XAPIOutboxEntry) {
+	ids := make([]uuid.UUID, len(entries))
+	for i, e := range entries {
+		ids[i] = e.ID
+	}
+	if err := r.repo.MarkSent(ids); err != nil {
+		log.Printf("[xAPI] ERROR: Failed to mark %d statement(s) sent: %v", len(ids), err)
+	}
+}
+
+func (r *Relay) cleanup() {
+	if r.cfg.Retention <= 0 {
+		return
+	}
+	n, err := r.repo.DeleteSentBefore(time.Now().Add(-r.cfg.Retention))
+	if err != nil {
+		log.Printf("[xAPI] ERROR: Failed to purge sent statements: %v", err)
+		return
+	}
+	if n > 0 {
+		log.Printf("[xAPI] Purged %d sent statement(s)", n)
+	}
+}
+
+// backoff doubles the retry delay with every attempt, up to the configured cap
+func (r *Relay) backoff(attempt int) time.Duration {
+	delay := r.cfg.RetryBackoff
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= r.cfg.MaxRetryBackoff {
+			return r.cfg.MaxRetryBackoff
+		}
+	}
+	return delay
+}
@@ -0,0 +1,152 @@
+// Package xapi records learning activity as xAPI (Experience API 1.0.3)
+// statements and relays them to a Learning Record Store. Statements are
+// written to an outbox table first, so nothing is lost while the LRS is down.
+package xapi
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/secusense/backend/internal/domain"
+)
+
+// Version is the xAPI version statements are sent as
+const Version = "1.0.3"
+
+// Verbs from the ADL vocabulary
+var (
+	VerbLaunched    = Verb{ID: "http://adlnet.gov/expapi/verbs/launched", Display: map[string]string{"en-US": "launched"}}
+	VerbExperienced = Verb{ID: "http://adlnet.gov/expapi/verbs/experienced", Display: map[string]string{"en-US": "experienced"}}
+	VerbAnswered    = Verb{ID: "http://adlnet.gov/expapi/verbs/answered", Display: map[string]string{"en-US": "answered"}}
+	VerbPassed      = Verb{ID: "http://adlnet.gov/expapi/verbs/passed", Display: map[string]string{"en-US": "passed"}}
+	VerbFailed      = Verb{ID: "http://adlnet.gov/expapi/verbs/failed", Display: map[string]string{"en-US": "failed"}}
+	VerbCompleted   = Verb{ID: "http://adlnet.gov/expapi/verbs/completed", Display: map[string]string{"en-US": "completed"}}
+)
+
+// Activity types
+const (
+	ActivityTypeCourse      = "http://adlnet.gov/expapi/activities/course"
+	ActivityTypeLesson      = "http://adlnet.gov/expapi/activities/lesson"
+	ActivityTypeAssessment  = "http://adlnet.gov/expapi/activities/assessment"
+	ActivityTypeInteraction = "http://adlnet.gov/expapi/activities/cmi.interaction"
+	ActivityTypeSlide       = "http://id.tincanapi.com/activitytype/slide"
+	ActivityTypeVideo       = "https://w3id.org/xapi/video/activity-type/video"
+)
+
+type Statement struct {
+	ID        uuid.UUID `json:"id"`
+	Actor     Agent     `json:"actor"`
+	Verb      Verb      `json:"verb"`
+	Object    Activity  `json:"object"`
+	Result    *Result   `json:"result,omitempty"`
+	Context   *Context  `json:"context,omitempty"`
+	Timestamp string    `json:"timestamp"`
+}
+
+// Agent identifies learners by their account on the platform, so no personal
+// data leaves it
+type Agent struct {
+	ObjectType string  `json:"objectType"`
+	Account    Account `json:"account"`
+}
+
+type Account struct {
+	HomePage string `json:"homePage"`
+	Name     string `json:"name"`
+}
+
+type Verb struct {
+	ID      string            `json:"id"`
+	Display map[string]string `json:"display"`
+}
+
+type Activity struct {
+	ObjectType string              `json:"objectType"`
+	ID         string              `json:"id"`
+	Definition *ActivityDefinition `json:"definition,omitempty"`
+}
+
+type ActivityDefinition struct {
+	Name            map[string]string `json:"name,omitempty"`
+	Type            string            `json:"type,omitempty"`
+	InteractionType string            `json:"interactionType,omitempty"`
+}
+
+type Result struct {
+	Score      *Score `json:"score,omitempty"`
+	Success    *bool  `json:"success,omitempty"`
+	Completion *bool  `json:"completion,omitempty"`
+	Response   string `json:"response,omitempty"`
+	Duration   string `json:"duration,omitempty"` // ISO 8601, e.g. "PT42S"
+}
+
+type Score struct {
+	Scaled float64 `json:"scaled"`
+	Raw    float64 `json:"raw"`
+	Min    float64 `json:"min"`
+	Max    float64 `json:"max"`
+}
+
+type Context struct {
+	Registration      *uuid.UUID         `json:"registration,omitempty"` // The learner's enrollment
+	ContextActivities *ContextActivities `json:"contextActivities,omitempty"`
+	Platform          string             `json:"platform,omitempty"`
+}
+
+type ContextActivities struct {
+	Parent   []Activity `json:"parent,omitempty"`
+	Grouping []Activity `json:"grouping,omitempty"`
+}
+
+// interactionTypes maps question types to the closest cmi.interaction type
+var interactionTypes = map[domain.QuestionType]string{
+	domain.QuestionTypeMultipleChoice: "choice",
+	domain.QuestionTypeTrueFalse:      "true-false",
+	domain.QuestionTypeFillBlank:      "fill-in",
+	domain.QuestionTypeNumeric:        "numeric",
+	domain.QuestionTypeOrdering:       "sequencing",
+	domain.QuestionTypeMatching:       "matching",
+	domain.QuestionTypeDragDrop:       "matching",
+	domain.QuestionTypeShortText:      "long-fill-in",
+	domain.QuestionTypeOpenEnded:      "long-fill-in",
+}
+
+func interactionType(t domain.QuestionType) string {
+	if it, ok := interactionTypes[t]; ok {
+		return it
+	}
+	return "other"
+}
+
+// freeText reports whether answers to questions of type t are written by the
+// learner, so they may hold personal data
+func freeText(t domain.QuestionType) bool {
+	return t == domain.QuestionTypeShortText || t == domain.QuestionTypeOpenEnded
+}
+
+// newScore reports points out of maxPoints. Negative marking can push raw
+// below zero, so min follows it down.
+func newScore(raw, maxPoints int) *Score {
+	if maxPoints <= 0 {
+		return nil
+	}
+	scaled := float64(raw) / float64(maxPoints)
+	return &Score{
+		Scaled: min(max(scaled, -1), 1),
+		Raw:    float64(raw),
+		Min:    float64(min(raw, 0)),
+		Max:    float64(maxPoints),
+	}
+}
+
+func duration(d time.Duration) string {
+	return fmt.Sprintf("PT%dS", int(d.Round(time.Second).Seconds()))
+}
+
+func name(s string) map[string]string {
+	if s == "" {
+		return nil
+	}
+	return map[string]string{"en-US": s}
+}
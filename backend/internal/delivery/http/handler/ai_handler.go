@@ -7,6 +7,7 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
+	"github.com/secusense/backend/internal/delivery/http/middleware"
 	"github.com/secusense/backend/internal/domain"
 	"github.com/secusense/backend/internal/usecase/ai"
 )
@@ -35,7 +36,7 @@ func (h *AIHandler) GenerateCourse(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	job, err := h.aiUC.GenerateCourse(r.Context(), &req)
+	job, err := h.aiUC.GenerateCourse(r.Context(), middleware.GetTenant(r.Context()), &req)
 	if err != nil {
 		respondError(w, http.StatusInternalServerError, "failed to start course generation: "+err.Error())
 		return
@@ -52,7 +53,7 @@ func (h *AIHandler) GetJob(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	job, err := h.aiUC.GetJob(id)
+	job, err := h.aiUC.GetJob(middleware.GetTenant(r.Context()), id)
 	if err != nil {
 		switch err {
 		case ai.ErrJobNotFound:
@@ -89,9 +90,14 @@ func (h *AIHandler) RefreshVideoStatus(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	course, err := h.aiUC.RefreshVideoStatus(r.Context(), id)
+	course, err := h.aiUC.RefreshVideoStatus(r.Context(), middleware.GetTenant(r.Context()), id)
 	if err != nil {
-		respondError(w, http.StatusInternalServerError, err.Error())
+		switch err {
+		case ai.ErrCourseNotFound:
+			respondError(w, http.StatusNotFound, err.Error())
+		default:
+			respondError(w, http.StatusInternalServerError, err.Error())
+		}
 		return
 	}
 
@@ -115,9 +121,14 @@ func (h *AIHandler) GetVideoURL(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	videoURL, err := h.aiUC.GetFreshVideoURL(r.Context(), id)
+	videoURL, err := h.aiUC.GetFreshVideoURL(r.Context(), middleware.GetTenant(r.Context()), id)
 	if err != nil {
-		respondError(w, http.StatusInternalServerError, err.Error())
+		switch err {
+		case ai.ErrCourseNotFound:
+			respondError(w, http.StatusNotFound, err.Error())
+		default:
+			respondError(w, http.StatusInternalServerError, err.Error())
+		}
 		return
 	}
 
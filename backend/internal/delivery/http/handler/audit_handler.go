@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/secusense/backend/internal/delivery/http/middleware"
+	"github.com/secusense/backend/internal/usecase/audit"
+)
+
+type AuditHandler struct {
+	auditUC *audit.UseCase
+}
+
+func NewAuditHandler(auditUC *audit.UseCase) *AuditHandler {
+	return &AuditHandler{
+		auditUC: auditUC,
+	}
+}
+
+// List returns the audit log of the admin's organization (paginated)
+func (h *AuditHandler) List(w http.ResponseWriter, r *http.Request) {
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	pageSize, _ := strconv.Atoi(r.URL.Query().Get("pageSize"))
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	entries, total, err := h.auditUC.List(middleware.GetTenant(r.Context()), page, pageSize)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to list audit log")
+		return
+	}
+
+	respondPaginated(w, entries, total, page, pageSize)
+}
@@ -39,6 +39,8 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 		switch err {
 		case auth.ErrUserAlreadyExists:
 			respondError(w, http.StatusConflict, err.Error())
+		case auth.ErrOrganizationNotFound:
+			respondError(w, http.StatusBadRequest, err.Error())
 		default:
 			respondError(w, http.StatusInternalServerError, "registration failed")
 		}
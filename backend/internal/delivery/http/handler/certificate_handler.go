@@ -2,21 +2,32 @@ package handler
 
 import (
 	"encoding/json"
+	"io"
 	"net/http"
+	"strconv"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
 	"github.com/secusense/backend/internal/delivery/http/middleware"
+	"github.com/secusense/backend/internal/domain"
 	"github.com/secusense/backend/internal/usecase/certificate"
+	"github.com/secusense/backend/pkg/openbadge"
 )
 
+// maxCredentialSize bounds the credential documents accepted for
+// verification
+const maxCredentialSize = 1 << 20
+
 type CertificateHandler struct {
-	certUC *certificate.UseCase
+	certUC   *certificate.UseCase
+	validate *validator.Validate
 }
 
 func NewCertificateHandler(certUC *certificate.UseCase) *CertificateHandler {
 	return &CertificateHandler{
-		certUC: certUC,
+		certUC:   certUC,
+		validate: validator.New(),
 	}
 }
 
@@ -32,6 +43,27 @@ func (h *CertificateHandler) List(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, http.StatusOK, certs)
 }
 
+// ListByOrganization lists the certificates issued in the admin's
+// organization (admin)
+func (h *CertificateHandler) ListByOrganization(w http.ResponseWriter, r *http.Request) {
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	pageSize, _ := strconv.Atoi(r.URL.Query().Get("pageSize"))
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	certs, total, err := h.certUC.List(middleware.GetTenant(r.Context()), page, pageSize)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to list certificates")
+		return
+	}
+
+	respondPaginated(w, certs, total, page, pageSize)
+}
+
 func (h *CertificateHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
 	id, err := uuid.Parse(idStr)
@@ -40,7 +72,7 @@ func (h *CertificateHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	cert, err := h.certUC.GetByID(id)
+	cert, err := h.certUC.GetByID(middleware.GetTenant(r.Context()), id)
 	if err != nil {
 		switch err {
 		case certificate.ErrCertificateNotFound:
@@ -54,6 +86,28 @@ func (h *CertificateHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, http.StatusOK, cert)
 }
 
+// History returns a certificate with the certificates it renewed
+func (h *CertificateHandler) History(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid certificate ID")
+		return
+	}
+
+	certs, err := h.certUC.GetHistory(middleware.GetTenant(r.Context()), id)
+	if err != nil {
+		switch err {
+		case certificate.ErrCertificateNotFound:
+			respondError(w, http.StatusNotFound, err.Error())
+		default:
+			respondError(w, http.StatusInternalServerError, "failed to get certificate history")
+		}
+		return
+	}
+
+	respondJSON(w, http.StatusOK, certs)
+}
+
 func (h *CertificateHandler) Generate(w http.ResponseWriter, r *http.Request) {
 	userID := middleware.GetUserID(r.Context())
 
@@ -66,14 +120,14 @@ func (h *CertificateHandler) Generate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	cert, err := h.certUC.Generate(userID, req.CourseID, req.AttemptID)
+	cert, err := h.certUC.Generate(r.Context(), userID, req.CourseID, req.AttemptID)
 	if err != nil {
 		switch err {
 		case certificate.ErrTestNotPassed:
 			respondError(w, http.StatusBadRequest, err.Error())
 		case certificate.ErrCertificateExists:
 			respondError(w, http.StatusConflict, err.Error())
-		case certificate.ErrAttemptNotFound:
+		case certificate.ErrAttemptNotFound, certificate.ErrCourseNotFound:
 			respondError(w, http.StatusNotFound, err.Error())
 		default:
 			respondError(w, http.StatusInternalServerError, "failed to generate certificate")
@@ -94,11 +148,13 @@ func (h *CertificateHandler) Download(w http.ResponseWriter, r *http.Request) {
 
 	userID := middleware.GetUserID(r.Context())
 
-	pdfBytes, err := h.certUC.GeneratePDF(id, userID)
+	pdfBytes, err := h.certUC.GeneratePDF(r.Context(), id, userID)
 	if err != nil {
 		switch err {
 		case certificate.ErrCertificateNotFound:
 			respondError(w, http.StatusNotFound, err.Error())
+		case certificate.ErrCertificateRevoked:
+			respondError(w, http.StatusGone, err.Error())
 		default:
 			respondError(w, http.StatusInternalServerError, "failed to generate PDF")
 		}
@@ -111,6 +167,120 @@ func (h *CertificateHandler) Download(w http.ResponseWriter, r *http.Request) {
 	w.Write(pdfBytes)
 }
 
+// Link returns a signed, time-limited URL of the certificate's PDF
+func (h *CertificateHandler) Link(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid certificate ID")
+		return
+	}
+
+	link, err := h.certUC.PDFLink(r.Context(), id, middleware.GetUserID(r.Context()))
+	if err != nil {
+		switch err {
+		case certificate.ErrCertificateNotFound:
+			respondError(w, http.StatusNotFound, err.Error())
+		case certificate.ErrCertificateRevoked:
+			respondError(w, http.StatusGone, err.Error())
+		default:
+			respondError(w, http.StatusInternalServerError, "failed to create download link")
+		}
+		return
+	}
+
+	respondJSON(w, http.StatusOK, link)
+}
+
+// Revoke revokes a certificate with a reason (admin)
+func (h *CertificateHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid certificate ID")
+		return
+	}
+
+	var req domain.RevokeCertificateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	cert, err := h.certUC.Revoke(middleware.GetTenant(ctx), middleware.GetUserID(ctx), id, &req)
+	if err != nil {
+		switch err {
+		case certificate.ErrCertificateNotFound:
+			respondError(w, http.StatusNotFound, err.Error())
+		case certificate.ErrCertificateRevoked:
+			respondError(w, http.StatusConflict, err.Error())
+		default:
+			respondError(w, http.StatusInternalServerError, "failed to revoke certificate")
+		}
+		return
+	}
+
+	respondJSON(w, http.StatusOK, cert)
+}
+
+// RevocationList publishes the signed revocation list the Open Badges
+// credentials point at
+func (h *CertificateHandler) RevocationList(w http.ResponseWriter, r *http.Request) {
+	list, err := h.certUC.RevocationList()
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to build revocation list")
+		return
+	}
+
+	w.Header().Set("Content-Type", openbadge.MediaType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(list)
+}
+
+// DownloadBadge returns the certificate as a signed Open Badges 3.0
+// credential
+func (h *CertificateHandler) DownloadBadge(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid certificate ID")
+		return
+	}
+
+	badge, err := h.certUC.Badge(id, middleware.GetUserID(r.Context()))
+	if err != nil {
+		switch err {
+		case certificate.ErrCertificateNotFound:
+			respondError(w, http.StatusNotFound, err.Error())
+		case certificate.ErrCertificateRevoked:
+			respondError(w, http.StatusGone, err.Error())
+		default:
+			respondError(w, http.StatusInternalServerError, "failed to issue badge")
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", openbadge.MediaType)
+	w.Header().Set("Content-Disposition", "attachment; filename=certificate.json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(badge)
+}
+
+// Keys publishes the public keys certificate credentials are signed with,
+// as a JWKS, for offline verification
+func (h *CertificateHandler) Keys(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.certUC.Keys()
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to get signing keys")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, keys)
+}
+
 func (h *CertificateHandler) Verify(w http.ResponseWriter, r *http.Request) {
 	hash := chi.URLParam(r, "hash")
 	if hash == "" {
@@ -126,3 +296,26 @@ func (h *CertificateHandler) Verify(w http.ResponseWriter, r *http.Request) {
 
 	respondJSON(w, http.StatusOK, verification)
 }
+
+// VerifyCredential verifies an Open Badges credential document posted as
+// the request body
+func (h *CertificateHandler) VerifyCredential(w http.ResponseWriter, r *http.Request) {
+	document, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxCredentialSize))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	verification, err := h.certUC.VerifyCredential(document)
+	if err != nil {
+		switch err {
+		case certificate.ErrInvalidCredential:
+			respondError(w, http.StatusBadRequest, err.Error())
+		default:
+			respondError(w, http.StatusInternalServerError, "verification failed")
+		}
+		return
+	}
+
+	respondJSON(w, http.StatusOK, verification)
+}
@@ -0,0 +1,191 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/secusense/backend/internal/delivery/http/middleware"
+	"github.com/secusense/backend/internal/domain"
+	"github.com/secusense/backend/internal/usecase/certificate"
+)
+
+// maxTemplateSize bounds certificate template bodies, which carry their
+// images inline
+const maxTemplateSize = 4 << 20
+
+func (h *CertificateHandler) ListTemplates(w http.ResponseWriter, r *http.Request) {
+	templates, err := h.certUC.ListTemplates(middleware.GetTenant(r.Context()))
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to list certificate templates")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, templates)
+}
+
+func (h *CertificateHandler) GetTemplate(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid template ID")
+		return
+	}
+
+	tmpl, err := h.certUC.GetTemplate(middleware.GetTenant(r.Context()), id)
+	if err != nil {
+		switch err {
+		case certificate.ErrTemplateNotFound:
+			respondError(w, http.StatusNotFound, err.Error())
+		default:
+			respondError(w, http.StatusInternalServerError, "failed to get certificate template")
+		}
+		return
+	}
+
+	respondJSON(w, http.StatusOK, tmpl)
+}
+
+func (h *CertificateHandler) CreateTemplate(w http.ResponseWriter, r *http.Request) {
+	var req domain.CreateCertificateTemplateRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxTemplateSize)).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	tmpl, err := h.certUC.CreateTemplate(middleware.GetTenant(r.Context()), &req)
+	if err != nil {
+		switch err {
+		case certificate.ErrCourseNotFound:
+			respondError(w, http.StatusNotFound, err.Error())
+		case certificate.ErrTemplateExists:
+			respondError(w, http.StatusConflict, err.Error())
+		case certificate.ErrInvalidTemplateTexts, domain.ErrInvalidImage:
+			respondError(w, http.StatusBadRequest, err.Error())
+		default:
+			respondError(w, http.StatusInternalServerError, "failed to create certificate template")
+		}
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, tmpl)
+}
+
+func (h *CertificateHandler) UpdateTemplate(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid template ID")
+		return
+	}
+
+	var req domain.UpdateCertificateTemplateRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxTemplateSize)).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	tmpl, err := h.certUC.UpdateTemplate(middleware.GetTenant(r.Context()), id, &req)
+	if err != nil {
+		switch err {
+		case certificate.ErrTemplateNotFound:
+			respondError(w, http.StatusNotFound, err.Error())
+		case certificate.ErrInvalidTemplateTexts, domain.ErrInvalidImage:
+			respondError(w, http.StatusBadRequest, err.Error())
+		default:
+			respondError(w, http.StatusInternalServerError, "failed to update certificate template")
+		}
+		return
+	}
+
+	respondJSON(w, http.StatusOK, tmpl)
+}
+
+func (h *CertificateHandler) DeleteTemplate(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid template ID")
+		return
+	}
+
+	if err := h.certUC.DeleteTemplate(middleware.GetTenant(r.Context()), id); err != nil {
+		switch err {
+		case certificate.ErrTemplateNotFound:
+			respondError(w, http.StatusNotFound, err.Error())
+		default:
+			respondError(w, http.StatusInternalServerError, "failed to delete certificate template")
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// PreviewTemplate renders a sample certificate with a saved template, in
+// the ?language= given
+func (h *CertificateHandler) PreviewTemplate(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid template ID")
+		return
+	}
+
+	pdfBytes, err := h.certUC.PreviewTemplate(middleware.GetTenant(r.Context()), id, r.URL.Query().Get("language"))
+	if err != nil {
+		switch err {
+		case certificate.ErrTemplateNotFound:
+			respondError(w, http.StatusNotFound, err.Error())
+		default:
+			respondError(w, http.StatusInternalServerError, "failed to generate PDF")
+		}
+		return
+	}
+
+	respondPreview(w, pdfBytes)
+}
+
+// PreviewDraft renders a sample certificate with a template that isn't
+// saved yet, so admins can try changes out
+func (h *CertificateHandler) PreviewDraft(w http.ResponseWriter, r *http.Request) {
+	var req domain.CreateCertificateTemplateRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxTemplateSize)).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	pdfBytes, err := h.certUC.PreviewDraft(middleware.GetTenant(r.Context()), &req, r.URL.Query().Get("language"))
+	if err != nil {
+		switch err {
+		case certificate.ErrCourseNotFound:
+			respondError(w, http.StatusNotFound, err.Error())
+		case certificate.ErrInvalidTemplateTexts, domain.ErrInvalidImage:
+			respondError(w, http.StatusBadRequest, err.Error())
+		default:
+			respondError(w, http.StatusInternalServerError, "failed to generate PDF")
+		}
+		return
+	}
+
+	respondPreview(w, pdfBytes)
+}
+
+func respondPreview(w http.ResponseWriter, pdfBytes []byte) {
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", "inline; filename=certificate-preview.pdf")
+	w.WriteHeader(http.StatusOK)
+	w.Write(pdfBytes)
+}
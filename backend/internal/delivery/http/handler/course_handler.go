@@ -8,6 +8,7 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
+	"github.com/secusense/backend/internal/delivery/http/middleware"
 	"github.com/secusense/backend/internal/domain"
 	"github.com/secusense/backend/internal/usecase/course"
 )
@@ -34,7 +35,7 @@ func (h *CourseHandler) List(w http.ResponseWriter, r *http.Request) {
 		pageSize = 20
 	}
 
-	courses, total, err := h.courseUC.List(page, pageSize, true) // Only published for public
+	courses, total, err := h.courseUC.List(middleware.GetTenant(r.Context()), page, pageSize, true) // Only published for public
 	if err != nil {
 		respondError(w, http.StatusInternalServerError, "failed to list courses")
 		return
@@ -51,7 +52,7 @@ func (h *CourseHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	courseObj, err := h.courseUC.GetByID(id)
+	courseObj, err := h.courseUC.GetByID(middleware.GetTenant(r.Context()), id)
 	if err != nil {
 		switch err {
 		case course.ErrCourseNotFound:
@@ -77,7 +78,7 @@ func (h *CourseHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	courseObj, err := h.courseUC.Create(&req)
+	courseObj, err := h.courseUC.Create(middleware.GetTenant(r.Context()), &req)
 	if err != nil {
 		respondError(w, http.StatusInternalServerError, "failed to create course")
 		return
@@ -105,7 +106,7 @@ func (h *CourseHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	courseObj, err := h.courseUC.Update(id, &req)
+	courseObj, err := h.courseUC.Update(middleware.GetTenant(r.Context()), id, &req)
 	if err != nil {
 		switch err {
 		case course.ErrCourseNotFound:
@@ -127,7 +128,7 @@ func (h *CourseHandler) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.courseUC.Delete(id); err != nil {
+	if err := h.courseUC.Delete(middleware.GetTenant(r.Context()), id); err != nil {
 		switch err {
 		case course.ErrCourseNotFound:
 			respondError(w, http.StatusNotFound, err.Error())
@@ -150,7 +151,7 @@ func (h *CourseHandler) ListAll(w http.ResponseWriter, r *http.Request) {
 		pageSize = 20
 	}
 
-	courses, total, err := h.courseUC.List(page, pageSize, false) // All courses for admin
+	courses, total, err := h.courseUC.List(middleware.GetTenant(r.Context()), page, pageSize, false) // All courses for admin
 	if err != nil {
 		respondError(w, http.StatusInternalServerError, "failed to list courses")
 		return
@@ -167,7 +168,7 @@ func (h *CourseHandler) Publish(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.courseUC.Publish(id); err != nil {
+	if err := h.courseUC.Publish(middleware.GetTenant(r.Context()), id); err != nil {
 		switch err {
 		case course.ErrCourseNotFound:
 			respondError(w, http.StatusNotFound, err.Error())
@@ -188,7 +189,7 @@ func (h *CourseHandler) Unpublish(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.courseUC.Unpublish(id); err != nil {
+	if err := h.courseUC.Unpublish(middleware.GetTenant(r.Context()), id); err != nil {
 		switch err {
 		case course.ErrCourseNotFound:
 			respondError(w, http.StatusNotFound, err.Error())
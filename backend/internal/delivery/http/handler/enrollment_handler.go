@@ -3,6 +3,7 @@ package handler
 import (
 	"encoding/json"
 	"net/http"
+	"strconv"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
@@ -31,7 +32,7 @@ func (h *EnrollmentHandler) Enroll(w http.ResponseWriter, r *http.Request) {
 
 	userID := middleware.GetUserID(r.Context())
 
-	enrollmentObj, err := h.enrollmentUC.Enroll(userID, courseID)
+	enrollmentObj, err := h.enrollmentUC.Enroll(middleware.GetTenant(r.Context()), userID, courseID)
 	if err != nil {
 		switch err {
 		case enrollment.ErrCourseNotFound:
@@ -61,6 +62,27 @@ func (h *EnrollmentHandler) List(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, http.StatusOK, enrollments)
 }
 
+// ListByOrganization lists the enrollments of the admin's organization
+// (admin)
+func (h *EnrollmentHandler) ListByOrganization(w http.ResponseWriter, r *http.Request) {
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	pageSize, _ := strconv.Atoi(r.URL.Query().Get("pageSize"))
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	enrollments, total, err := h.enrollmentUC.ListByOrganization(middleware.GetTenant(r.Context()), page, pageSize)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to list enrollments")
+		return
+	}
+
+	respondPaginated(w, enrollments, total, page, pageSize)
+}
+
 func (h *EnrollmentHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
 	id, err := uuid.Parse(idStr)
@@ -147,3 +169,62 @@ func (h *EnrollmentHandler) CompleteVideo(w http.ResponseWriter, r *http.Request
 
 	respondJSON(w, http.StatusOK, enrollmentObj)
 }
+
+// Launch records that the learner opened the enrolled course
+func (h *EnrollmentHandler) Launch(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid enrollment ID")
+		return
+	}
+
+	userID := middleware.GetUserID(r.Context())
+
+	enrollmentObj, err := h.enrollmentUC.Launch(id, userID)
+	if err != nil {
+		switch err {
+		case enrollment.ErrEnrollmentNotFound:
+			respondError(w, http.StatusNotFound, err.Error())
+		case enrollment.ErrNotOwner:
+			respondError(w, http.StatusForbidden, err.Error())
+		default:
+			respondError(w, http.StatusInternalServerError, "failed to launch course")
+		}
+		return
+	}
+
+	respondJSON(w, http.StatusOK, enrollmentObj)
+}
+
+// RecordSlideView records that the learner viewed a presentation slide
+func (h *EnrollmentHandler) RecordSlideView(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid enrollment ID")
+		return
+	}
+
+	userID := middleware.GetUserID(r.Context())
+
+	var req domain.SlideViewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.enrollmentUC.ExperienceSlide(id, userID, &req); err != nil {
+		switch err {
+		case enrollment.ErrEnrollmentNotFound, enrollment.ErrLessonNotFound, enrollment.ErrSlideNotFound:
+			respondError(w, http.StatusNotFound, err.Error())
+		case enrollment.ErrNotOwner:
+			respondError(w, http.StatusForbidden, err.Error())
+		default:
+			respondError(w, http.StatusInternalServerError, "failed to record slide view")
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/secusense/backend/internal/delivery/http/middleware"
+	"github.com/secusense/backend/internal/usecase/export"
+	"github.com/secusense/backend/pkg/scorm"
+)
+
+// scormExportTimeout covers fetching every slide's media
+const scormExportTimeout = 5 * time.Minute
+
+type ExportHandler struct {
+	exportUC *export.UseCase
+}
+
+func NewExportHandler(exportUC *export.UseCase) *ExportHandler {
+	return &ExportHandler{
+		exportUC: exportUC,
+	}
+}
+
+// ExportSCORM downloads a published course as a SCORM 1.2 (default) or
+// SCORM 2004 package (admin). Content left out of the package is counted in
+// the X-Skipped-Items header.
+func (h *ExportHandler) ExportSCORM(w http.ResponseWriter, r *http.Request) {
+	courseID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid course ID")
+		return
+	}
+
+	version := scorm.Version(r.URL.Query().Get("version"))
+	if version == "" {
+		version = scorm.Version12
+	}
+
+	// Packaging outlives the server's write timeout
+	http.NewResponseController(w).SetWriteDeadline(time.Now().Add(scormExportTimeout))
+
+	pkg, err := h.exportUC.ExportSCORM(r.Context(), middleware.GetTenant(r.Context()), courseID, version)
+	if err != nil {
+		switch err {
+		case export.ErrCourseNotFound:
+			respondError(w, http.StatusNotFound, err.Error())
+		case export.ErrUnsupportedVersion, export.ErrCourseNotPublished, export.ErrNothingToExport:
+			respondError(w, http.StatusBadRequest, err.Error())
+		default:
+			respondError(w, http.StatusInternalServerError, "failed to export course")
+		}
+		return
+	}
+
+	skipped := 0
+	for _, issue := range pkg.Issues {
+		if issue.Skipped {
+			skipped++
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", "attachment; filename="+pkg.Filename)
+	w.Header().Set("X-Skipped-Items", strconv.Itoa(skipped))
+	w.WriteHeader(http.StatusOK)
+	w.Write(pkg.Data)
+}
@@ -0,0 +1,327 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/secusense/backend/internal/delivery/http/middleware"
+	"github.com/secusense/backend/internal/domain"
+	"github.com/secusense/backend/internal/usecase/group"
+)
+
+type GroupHandler struct {
+	groupUC  *group.UseCase
+	validate *validator.Validate
+}
+
+func NewGroupHandler(groupUC *group.UseCase) *GroupHandler {
+	return &GroupHandler{
+		groupUC:  groupUC,
+		validate: validator.New(),
+	}
+}
+
+func (h *GroupHandler) List(w http.ResponseWriter, r *http.Request) {
+	groups, err := h.groupUC.List(middleware.GetTenant(r.Context()))
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to list groups")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, groups)
+}
+
+func (h *GroupHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid group ID")
+		return
+	}
+
+	g, err := h.groupUC.GetByID(middleware.GetTenant(r.Context()), id)
+	if err != nil {
+		switch err {
+		case group.ErrGroupNotFound:
+			respondError(w, http.StatusNotFound, err.Error())
+		default:
+			respondError(w, http.StatusInternalServerError, "failed to get group")
+		}
+		return
+	}
+
+	respondJSON(w, http.StatusOK, g)
+}
+
+func (h *GroupHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req domain.CreateGroupRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	g, err := h.groupUC.Create(middleware.GetTenant(r.Context()), &req)
+	if err != nil {
+		switch err {
+		case group.ErrGroupExists:
+			respondError(w, http.StatusConflict, err.Error())
+		default:
+			respondError(w, http.StatusInternalServerError, "failed to create group")
+		}
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, g)
+}
+
+func (h *GroupHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid group ID")
+		return
+	}
+
+	var req domain.UpdateGroupRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	g, err := h.groupUC.Update(middleware.GetTenant(r.Context()), id, &req)
+	if err != nil {
+		switch err {
+		case group.ErrGroupNotFound:
+			respondError(w, http.StatusNotFound, err.Error())
+		case group.ErrGroupExists:
+			respondError(w, http.StatusConflict, err.Error())
+		default:
+			respondError(w, http.StatusInternalServerError, "failed to update group")
+		}
+		return
+	}
+
+	respondJSON(w, http.StatusOK, g)
+}
+
+func (h *GroupHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid group ID")
+		return
+	}
+
+	if err := h.groupUC.Delete(middleware.GetTenant(r.Context()), id); err != nil {
+		switch err {
+		case group.ErrGroupNotFound:
+			respondError(w, http.StatusNotFound, err.Error())
+		default:
+			respondError(w, http.StatusInternalServerError, "failed to delete group")
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *GroupHandler) ListMembers(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid group ID")
+		return
+	}
+
+	members, err := h.groupUC.ListMembers(middleware.GetTenant(r.Context()), id)
+	if err != nil {
+		switch err {
+		case group.ErrGroupNotFound:
+			respondError(w, http.StatusNotFound, err.Error())
+		default:
+			respondError(w, http.StatusInternalServerError, "failed to list members")
+		}
+		return
+	}
+
+	respondJSON(w, http.StatusOK, members)
+}
+
+// AddMembers adds users to a group in bulk and enrolls them in the
+// group's assigned courses
+func (h *GroupHandler) AddMembers(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid group ID")
+		return
+	}
+
+	var req domain.AddGroupMembersRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	members, err := h.groupUC.AddMembers(middleware.GetTenant(r.Context()), id, &req)
+	if err != nil {
+		switch err {
+		case group.ErrGroupNotFound:
+			respondError(w, http.StatusNotFound, err.Error())
+		case group.ErrUserNotFound:
+			respondError(w, http.StatusBadRequest, err.Error())
+		default:
+			respondError(w, http.StatusInternalServerError, "failed to add members")
+		}
+		return
+	}
+
+	respondJSON(w, http.StatusOK, members)
+}
+
+func (h *GroupHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid group ID")
+		return
+	}
+
+	userID, err := uuid.Parse(chi.URLParam(r, "userId"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid user ID")
+		return
+	}
+
+	if err := h.groupUC.RemoveMember(middleware.GetTenant(r.Context()), id, userID); err != nil {
+		switch err {
+		case group.ErrGroupNotFound:
+			respondError(w, http.StatusNotFound, err.Error())
+		default:
+			respondError(w, http.StatusInternalServerError, "failed to remove member")
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *GroupHandler) ListAssignments(w http.ResponseWriter, r *http.Request) {
+	assignments, err := h.groupUC.ListAssignments(middleware.GetTenant(r.Context()))
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to list assignments")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, assignments)
+}
+
+// Assign assigns a course to several groups at once
+func (h *GroupHandler) Assign(w http.ResponseWriter, r *http.Request) {
+	var req domain.AssignCourseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	assignments, err := h.groupUC.Assign(middleware.GetTenant(ctx), middleware.GetUserID(ctx), &req)
+	if err != nil {
+		switch err {
+		case group.ErrCourseNotFound, group.ErrGroupNotFound:
+			respondError(w, http.StatusNotFound, err.Error())
+		case group.ErrCourseNotPublished:
+			respondError(w, http.StatusBadRequest, err.Error())
+		default:
+			respondError(w, http.StatusInternalServerError, "failed to assign course")
+		}
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, assignments)
+}
+
+func (h *GroupHandler) UpdateAssignment(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid assignment ID")
+		return
+	}
+
+	var req domain.UpdateAssignmentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	assignment, err := h.groupUC.UpdateAssignment(middleware.GetTenant(r.Context()), id, &req)
+	if err != nil {
+		switch err {
+		case group.ErrAssignmentNotFound:
+			respondError(w, http.StatusNotFound, err.Error())
+		default:
+			respondError(w, http.StatusInternalServerError, "failed to update assignment")
+		}
+		return
+	}
+
+	respondJSON(w, http.StatusOK, assignment)
+}
+
+func (h *GroupHandler) DeleteAssignment(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid assignment ID")
+		return
+	}
+
+	if err := h.groupUC.DeleteAssignment(middleware.GetTenant(r.Context()), id); err != nil {
+		switch err {
+		case group.ErrAssignmentNotFound:
+			respondError(w, http.StatusNotFound, err.Error())
+		default:
+			respondError(w, http.StatusInternalServerError, "failed to delete assignment")
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListOverdue lists learners past the due date of an assigned course
+func (h *GroupHandler) ListOverdue(w http.ResponseWriter, r *http.Request) {
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	pageSize, _ := strconv.Atoi(r.URL.Query().Get("pageSize"))
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	overdue, total, err := h.groupUC.ListOverdue(middleware.GetTenant(r.Context()), page, pageSize)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to list overdue learners")
+		return
+	}
+
+	respondPaginated(w, overdue, total, page, pageSize)
+}
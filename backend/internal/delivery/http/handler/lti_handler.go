@@ -0,0 +1,240 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/secusense/backend/internal/delivery/http/middleware"
+	"github.com/secusense/backend/internal/domain"
+	"github.com/secusense/backend/internal/usecase/lti"
+)
+
+type LTIHandler struct {
+	ltiUC    *lti.UseCase
+	validate *validator.Validate
+}
+
+func NewLTIHandler(ltiUC *lti.UseCase) *LTIHandler {
+	return &LTIHandler{
+		ltiUC:    ltiUC,
+		validate: validator.New(),
+	}
+}
+
+// Login handles a platform's OIDC login initiation, sent as a GET or a form
+// POST, by redirecting to the platform's authorization endpoint
+func (h *LTIHandler) Login(w http.ResponseWriter, r *http.Request) {
+	req := &domain.LTILoginRequest{
+		Issuer:         r.FormValue("iss"),
+		LoginHint:      r.FormValue("login_hint"),
+		TargetLinkURI:  r.FormValue("target_link_uri"),
+		LTIMessageHint: r.FormValue("lti_message_hint"),
+		ClientID:       r.FormValue("client_id"),
+		DeploymentID:   r.FormValue("lti_deployment_id"),
+	}
+
+	redirect, err := h.ltiUC.Login(req)
+	if err != nil {
+		switch err {
+		case lti.ErrInvalidLogin:
+			respondError(w, http.StatusBadRequest, err.Error())
+		case lti.ErrPlatformNotFound:
+			respondError(w, http.StatusNotFound, err.Error())
+		case lti.ErrDeploymentNotAllowed:
+			respondError(w, http.StatusForbidden, err.Error())
+		default:
+			respondError(w, http.StatusInternalServerError, "failed to start LTI login")
+		}
+		return
+	}
+
+	http.Redirect(w, r, redirect, http.StatusFound)
+}
+
+// Launch receives the id_token the platform posts after login and hands the
+// browser over to the frontend
+func (h *LTIHandler) Launch(w http.ResponseWriter, r *http.Request) {
+	redirect, err := h.ltiUC.Launch(r.Context(), r.PostFormValue("id_token"), r.PostFormValue("state"))
+	if err != nil {
+		switch err {
+		case lti.ErrInvalidLaunch, lti.ErrInvalidState, lti.ErrUnsupportedMessage, lti.ErrCourseNotPublished:
+			respondError(w, http.StatusBadRequest, err.Error())
+		case lti.ErrDeploymentNotAllowed, lti.ErrNotInstructor:
+			respondError(w, http.StatusForbidden, err.Error())
+		case lti.ErrPlatformNotFound, lti.ErrCourseNotFound:
+			respondError(w, http.StatusNotFound, err.Error())
+		default:
+			respondError(w, http.StatusInternalServerError, "failed to launch")
+		}
+		return
+	}
+
+	http.Redirect(w, r, redirect, http.StatusSeeOther)
+}
+
+// JWKS publishes the tool's public keys
+func (h *LTIHandler) JWKS(w http.ResponseWriter, r *http.Request) {
+	jwks, err := h.ltiUC.JWKS()
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to load keys")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, jwks)
+}
+
+// Session signs the browser in as the user of a launch
+func (h *LTIHandler) Session(w http.ResponseWriter, r *http.Request) {
+	var req domain.LTISessionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	session, err := h.ltiUC.Session(req.Code)
+	if err != nil {
+		switch err {
+		case lti.ErrInvalidCode:
+			respondError(w, http.StatusUnauthorized, err.Error())
+		default:
+			respondError(w, http.StatusInternalServerError, "failed to start session")
+		}
+		return
+	}
+
+	respondJSON(w, http.StatusOK, session)
+}
+
+// DeepLink returns the signed response that adds a course to the platform
+func (h *LTIHandler) DeepLink(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+
+	var req domain.LTIDeepLinkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response, err := h.ltiUC.DeepLink(userID, &req)
+	if err != nil {
+		switch err {
+		case lti.ErrLaunchNotFound, lti.ErrCourseNotFound, lti.ErrPlatformNotFound:
+			respondError(w, http.StatusNotFound, err.Error())
+		case lti.ErrNotDeepLinking, lti.ErrCourseNotPublished:
+			respondError(w, http.StatusBadRequest, err.Error())
+		default:
+			respondError(w, http.StatusInternalServerError, "failed to create deep link")
+		}
+		return
+	}
+
+	respondJSON(w, http.StatusOK, response)
+}
+
+// GetToolConfiguration returns the URLs to register the tool with (admin)
+func (h *LTIHandler) GetToolConfiguration(w http.ResponseWriter, r *http.Request) {
+	respondJSON(w, http.StatusOK, h.ltiUC.ToolConfiguration())
+}
+
+func (h *LTIHandler) ListPlatforms(w http.ResponseWriter, r *http.Request) {
+	platforms, err := h.ltiUC.ListPlatforms(middleware.GetTenant(r.Context()))
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to list platforms")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, platforms)
+}
+
+func (h *LTIHandler) CreatePlatform(w http.ResponseWriter, r *http.Request) {
+	var req domain.CreateLTIPlatformRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	platform, err := h.ltiUC.CreatePlatform(middleware.GetTenant(r.Context()), &req)
+	if err != nil {
+		switch err {
+		case lti.ErrPlatformExists:
+			respondError(w, http.StatusConflict, err.Error())
+		default:
+			respondError(w, http.StatusInternalServerError, "failed to create platform")
+		}
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, platform)
+}
+
+func (h *LTIHandler) UpdatePlatform(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid platform ID")
+		return
+	}
+
+	var req domain.CreateLTIPlatformRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	platform, err := h.ltiUC.UpdatePlatform(middleware.GetTenant(r.Context()), id, &req)
+	if err != nil {
+		switch err {
+		case lti.ErrPlatformNotFound:
+			respondError(w, http.StatusNotFound, err.Error())
+		case lti.ErrPlatformExists:
+			respondError(w, http.StatusConflict, err.Error())
+		default:
+			respondError(w, http.StatusInternalServerError, "failed to update platform")
+		}
+		return
+	}
+
+	respondJSON(w, http.StatusOK, platform)
+}
+
+func (h *LTIHandler) DeletePlatform(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid platform ID")
+		return
+	}
+
+	if err := h.ltiUC.DeletePlatform(middleware.GetTenant(r.Context()), id); err != nil {
+		switch err {
+		case lti.ErrPlatformNotFound:
+			respondError(w, http.StatusNotFound, err.Error())
+		default:
+			respondError(w, http.StatusInternalServerError, "failed to delete platform")
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/secusense/backend/infrastructure/storage"
+)
+
+type MediaHandler struct {
+	store  storage.Storage
+	urlTTL time.Duration
+}
+
+func NewMediaHandler(store storage.Storage, urlTTL time.Duration) *MediaHandler {
+	return &MediaHandler{
+		store:  store,
+		urlTTL: urlTTL,
+	}
+}
+
+// Media redirects the stable URL of narration audio or a slide image to a
+// signed URL of the object
+func (h *MediaHandler) Media(w http.ResponseWriter, r *http.Request) {
+	key := chi.URLParam(r, "*")
+	if !storage.IsPublic(key) {
+		respondError(w, http.StatusNotFound, "media not found")
+		return
+	}
+
+	h.redirect(w, r, key)
+}
+
+// LegacyAudio redirects the audio URLs of presentations generated before
+// audio was kept in the storage
+func (h *MediaHandler) LegacyAudio(w http.ResponseWriter, r *http.Request) {
+	key := storage.AudioPrefix + chi.URLParam(r, "*")
+	if !storage.IsPublic(key) {
+		respondError(w, http.StatusNotFound, "media not found")
+		return
+	}
+
+	h.redirect(w, r, key)
+}
+
+// File serves an object of the local storage to a signed URL
+func (h *MediaHandler) File(w http.ResponseWriter, r *http.Request) {
+	local, ok := h.store.(*storage.Local)
+	if !ok {
+		respondError(w, http.StatusNotFound, "file not found")
+		return
+	}
+
+	local.ServeSigned(w, r, chi.URLParam(r, "*"))
+}
+
+func (h *MediaHandler) redirect(w http.ResponseWriter, r *http.Request, key string) {
+	url, err := h.store.SignedURL(r.Context(), key, h.urlTTL)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to sign media URL")
+		return
+	}
+
+	// Browsers may reuse the redirect while the signed URL is valid
+	w.Header().Set("Cache-Control", "private, max-age=60")
+	http.Redirect(w, r, url, http.StatusFound)
+}
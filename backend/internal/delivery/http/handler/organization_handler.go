@@ -0,0 +1,154 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/secusense/backend/internal/delivery/http/middleware"
+	"github.com/secusense/backend/internal/domain"
+	"github.com/secusense/backend/internal/usecase/organization"
+)
+
+type OrganizationHandler struct {
+	orgUC    *organization.UseCase
+	validate *validator.Validate
+}
+
+func NewOrganizationHandler(orgUC *organization.UseCase) *OrganizationHandler {
+	return &OrganizationHandler{
+		orgUC:    orgUC,
+		validate: validator.New(),
+	}
+}
+
+// GetCurrent returns the organization of the signed-in user
+func (h *OrganizationHandler) GetCurrent(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	org, err := h.orgUC.GetByID(middleware.GetTenant(ctx), middleware.GetOrganizationID(ctx))
+	if err != nil {
+		switch err {
+		case organization.ErrOrganizationNotFound:
+			respondError(w, http.StatusNotFound, err.Error())
+		default:
+			respondError(w, http.StatusInternalServerError, "failed to get organization")
+		}
+		return
+	}
+
+	respondJSON(w, http.StatusOK, org)
+}
+
+// List returns every organization (super admin)
+func (h *OrganizationHandler) List(w http.ResponseWriter, r *http.Request) {
+	orgs, err := h.orgUC.List()
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to list organizations")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, orgs)
+}
+
+func (h *OrganizationHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req domain.CreateOrganizationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	org, err := h.orgUC.Create(&req)
+	if err != nil {
+		switch err {
+		case organization.ErrInvalidSlug:
+			respondError(w, http.StatusBadRequest, err.Error())
+		case organization.ErrSlugTaken:
+			respondError(w, http.StatusConflict, err.Error())
+		default:
+			respondError(w, http.StatusInternalServerError, "failed to create organization")
+		}
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, org)
+}
+
+func (h *OrganizationHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid organization ID")
+		return
+	}
+
+	var req domain.UpdateOrganizationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	org, err := h.orgUC.Update(id, &req)
+	if err != nil {
+		switch err {
+		case organization.ErrOrganizationNotFound:
+			respondError(w, http.StatusNotFound, err.Error())
+		default:
+			respondError(w, http.StatusInternalServerError, "failed to update organization")
+		}
+		return
+	}
+
+	respondJSON(w, http.StatusOK, org)
+}
+
+// CreateMember adds an account to an organization (admin of that
+// organization or super admin)
+func (h *OrganizationHandler) CreateMember(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid organization ID")
+		return
+	}
+
+	var req domain.CreateMemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	user, err := h.orgUC.CreateMember(middleware.GetTenant(ctx), middleware.GetPermissions(ctx), id, &req)
+	if err != nil {
+		switch err {
+		case organization.ErrOrganizationNotFound:
+			respondError(w, http.StatusNotFound, err.Error())
+		case organization.ErrRoleNotFound, organization.ErrRoleNotAssignable:
+			respondError(w, http.StatusBadRequest, err.Error())
+		case organization.ErrRoleExceedsCaller:
+			respondError(w, http.StatusForbidden, err.Error())
+		case organization.ErrUserAlreadyExists:
+			respondError(w, http.StatusConflict, err.Error())
+		default:
+			respondError(w, http.StatusInternalServerError, "failed to create member")
+		}
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, user)
+}
@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/secusense/backend/internal/delivery/http/middleware"
+	"github.com/secusense/backend/internal/domain"
+	"github.com/secusense/backend/internal/usecase/role"
+)
+
+type RoleHandler struct {
+	roleUC   *role.UseCase
+	validate *validator.Validate
+}
+
+func NewRoleHandler(roleUC *role.UseCase) *RoleHandler {
+	return &RoleHandler{
+		roleUC:   roleUC,
+		validate: validator.New(),
+	}
+}
+
+// List returns the roles with their permissions
+func (h *RoleHandler) List(w http.ResponseWriter, r *http.Request) {
+	roles, err := h.roleUC.List()
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to list roles")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, roles)
+}
+
+func (h *RoleHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req domain.CreateRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	roleObj, err := h.roleUC.Create(&req)
+	if err != nil {
+		switch err {
+		case role.ErrInvalidRoleName, role.ErrInvalidPermission:
+			respondError(w, http.StatusBadRequest, err.Error())
+		case role.ErrRoleExists:
+			respondError(w, http.StatusConflict, err.Error())
+		default:
+			respondError(w, http.StatusInternalServerError, "failed to create role")
+		}
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, roleObj)
+}
+
+func (h *RoleHandler) Update(w http.ResponseWriter, r *http.Request) {
+	name := domain.UserRole(chi.URLParam(r, "name"))
+
+	var req domain.UpdateRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	roleObj, err := h.roleUC.Update(name, &req)
+	if err != nil {
+		switch err {
+		case role.ErrRoleNotFound:
+			respondError(w, http.StatusNotFound, err.Error())
+		case role.ErrInvalidPermission:
+			respondError(w, http.StatusBadRequest, err.Error())
+		case role.ErrBuiltInRole:
+			respondError(w, http.StatusForbidden, err.Error())
+		default:
+			respondError(w, http.StatusInternalServerError, "failed to update role")
+		}
+		return
+	}
+
+	respondJSON(w, http.StatusOK, roleObj)
+}
+
+// AssignRole changes the role of a user in the caller's organization
+func (h *RoleHandler) AssignRole(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid user ID")
+		return
+	}
+
+	var req domain.AssignRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	user, err := h.roleUC.AssignRole(middleware.GetTenant(ctx), middleware.GetUserID(ctx), middleware.GetPermissions(ctx), userID, &req)
+	if err != nil {
+		switch err {
+		case role.ErrUserNotFound:
+			respondError(w, http.StatusNotFound, err.Error())
+		case role.ErrRoleNotFound:
+			respondError(w, http.StatusBadRequest, err.Error())
+		case role.ErrRoleNotAssignable, role.ErrOwnRole, role.ErrRoleExceedsCaller:
+			respondError(w, http.StatusForbidden, err.Error())
+		default:
+			respondError(w, http.StatusInternalServerError, "failed to assign role")
+		}
+		return
+	}
+
+	respondJSON(w, http.StatusOK, user)
+}
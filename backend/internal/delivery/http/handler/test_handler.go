@@ -2,7 +2,10 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
+	"strconv"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-playground/validator/v10"
@@ -32,7 +35,7 @@ func (h *TestHandler) GetByCourseID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	testObj, err := h.testUC.GetByCourseID(courseID)
+	testObj, err := h.testUC.GetByCourseID(middleware.GetTenant(r.Context()), courseID)
 	if err != nil {
 		switch err {
 		case test.ErrTestNotFound:
@@ -63,6 +66,13 @@ func (h *TestHandler) StartAttempt(w http.ResponseWriter, r *http.Request) {
 
 	attempt, err := h.testUC.StartAttempt(userID, testID)
 	if err != nil {
+		var cooldown *test.CooldownError
+		if errors.As(err, &cooldown) {
+			w.Header().Set("Retry-After", strconv.Itoa(cooldown.RetryAfterSeconds()))
+			respondError(w, http.StatusTooManyRequests, err.Error())
+			return
+		}
+
 		switch err {
 		case test.ErrTestNotFound:
 			respondError(w, http.StatusNotFound, err.Error())
@@ -70,15 +80,92 @@ func (h *TestHandler) StartAttempt(w http.ResponseWriter, r *http.Request) {
 			respondError(w, http.StatusForbidden, err.Error())
 		case test.ErrVideoNotWatched:
 			respondError(w, http.StatusForbidden, err.Error())
+		case test.ErrMaxAttemptsReached, test.ErrLockedOut:
+			respondError(w, http.StatusForbidden, err.Error())
 		default:
 			respondError(w, http.StatusInternalServerError, "failed to start attempt")
 		}
 		return
 	}
 
+	for _, q := range attempt.Questions {
+		q.QuestionData = stripCorrectAnswers(q.QuestionType, q.QuestionData)
+	}
+
 	respondJSON(w, http.StatusCreated, attempt)
 }
 
+// ResumeAttempt returns the user's open attempt at a test, with the saved answers
+func (h *TestHandler) ResumeAttempt(w http.ResponseWriter, r *http.Request) {
+	testIDStr := chi.URLParam(r, "testId")
+	testID, err := uuid.Parse(testIDStr)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid test ID")
+		return
+	}
+
+	userID := middleware.GetUserID(r.Context())
+
+	attempt, err := h.testUC.ResumeAttempt(userID, testID)
+	if err != nil {
+		switch err {
+		case test.ErrNoOpenAttempt:
+			respondError(w, http.StatusNotFound, err.Error())
+		case test.ErrTimeLimitExceeded:
+			respondError(w, http.StatusConflict, err.Error())
+		default:
+			respondError(w, http.StatusInternalServerError, "failed to resume attempt")
+		}
+		return
+	}
+
+	for _, q := range attempt.Questions {
+		q.QuestionData = stripCorrectAnswers(q.QuestionType, q.QuestionData)
+	}
+
+	respondJSON(w, http.StatusOK, attempt)
+}
+
+// SaveAnswer stores a single answer while the attempt is in progress
+func (h *TestHandler) SaveAnswer(w http.ResponseWriter, r *http.Request) {
+	attemptIDStr := chi.URLParam(r, "attemptId")
+	attemptID, err := uuid.Parse(attemptIDStr)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid attempt ID")
+		return
+	}
+
+	userID := middleware.GetUserID(r.Context())
+
+	var req domain.SubmitAnswerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	answer, err := h.testUC.SaveAnswer(attemptID, userID, &req)
+	if err != nil {
+		switch err {
+		case test.ErrAttemptNotFound:
+			respondError(w, http.StatusNotFound, err.Error())
+		case test.ErrAttemptCompleted, test.ErrQuestionNotInAttempt:
+			respondError(w, http.StatusBadRequest, err.Error())
+		case test.ErrTimeLimitExceeded:
+			respondError(w, http.StatusConflict, err.Error())
+		default:
+			respondError(w, http.StatusInternalServerError, "failed to save answer")
+		}
+		return
+	}
+
+	respondJSON(w, http.StatusOK, answer)
+}
+
 func (h *TestHandler) SubmitAttempt(w http.ResponseWriter, r *http.Request) {
 	attemptIDStr := chi.URLParam(r, "attemptId")
 	attemptID, err := uuid.Parse(attemptIDStr)
@@ -107,6 +194,8 @@ func (h *TestHandler) SubmitAttempt(w http.ResponseWriter, r *http.Request) {
 			respondError(w, http.StatusNotFound, err.Error())
 		case test.ErrAttemptCompleted:
 			respondError(w, http.StatusBadRequest, err.Error())
+		case test.ErrTimeLimitExceeded:
+			respondError(w, http.StatusConflict, err.Error())
 		default:
 			respondError(w, http.StatusInternalServerError, "failed to submit attempt")
 		}
@@ -152,15 +241,263 @@ func (h *TestHandler) CreateTest(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	testObj, err := h.testUC.CreateTest(&req)
+	testObj, err := h.testUC.CreateTest(middleware.GetTenant(r.Context()), &req)
 	if err != nil {
-		respondError(w, http.StatusInternalServerError, "failed to create test")
+		switch err {
+		case test.ErrCourseNotFound:
+			respondError(w, http.StatusNotFound, err.Error())
+		default:
+			respondError(w, http.StatusInternalServerError, "failed to create test")
+		}
 		return
 	}
 
 	respondJSON(w, http.StatusCreated, testObj)
 }
 
+func (h *TestHandler) UpdateTest(w http.ResponseWriter, r *http.Request) {
+	testIDStr := chi.URLParam(r, "testId")
+	testID, err := uuid.Parse(testIDStr)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid test ID")
+		return
+	}
+
+	var req domain.UpdateTestRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	testObj, err := h.testUC.UpdateTest(middleware.GetTenant(r.Context()), testID, &req)
+	if err != nil {
+		switch err {
+		case test.ErrTestNotFound:
+			respondError(w, http.StatusNotFound, err.Error())
+		default:
+			respondError(w, http.StatusInternalServerError, "failed to update test")
+		}
+		return
+	}
+
+	respondJSON(w, http.StatusOK, testObj)
+}
+
+// ListReviews returns the LLM-graded answers with the given status, by
+// default the ones waiting for a reviewer (admin)
+func (h *TestHandler) ListReviews(w http.ResponseWriter, r *http.Request) {
+	status := domain.ReviewStatus(r.URL.Query().Get("status"))
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	pageSize, _ := strconv.Atoi(r.URL.Query().Get("pageSize"))
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	reviews, err := h.testUC.ListReviews(middleware.GetTenant(r.Context()), status, pageSize, (page-1)*pageSize)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to list reviews")
+		return
+	}
+	if reviews == nil {
+		reviews = []*domain.AnswerReview{}
+	}
+
+	respondJSON(w, http.StatusOK, reviews)
+}
+
+// ResolveReview sets the final grade of a reviewed answer (admin)
+func (h *TestHandler) ResolveReview(w http.ResponseWriter, r *http.Request) {
+	reviewID, err := uuid.Parse(chi.URLParam(r, "reviewId"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid review ID")
+		return
+	}
+
+	var req domain.ResolveReviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	adminID := middleware.GetUserID(r.Context())
+
+	review, err := h.testUC.ResolveReview(middleware.GetTenant(r.Context()), reviewID, adminID, &req)
+	if err != nil {
+		switch err {
+		case test.ErrReviewNotFound:
+			respondError(w, http.StatusNotFound, err.Error())
+		case test.ErrReviewNotPending:
+			respondError(w, http.StatusConflict, err.Error())
+		case test.ErrInvalidPoints:
+			respondError(w, http.StatusBadRequest, err.Error())
+		default:
+			respondError(w, http.StatusInternalServerError, "failed to resolve review")
+		}
+		return
+	}
+
+	respondJSON(w, http.StatusOK, review)
+}
+
+// GetItemAnalysis returns per-question statistics over a test's completed
+// attempts (admin)
+func (h *TestHandler) GetItemAnalysis(w http.ResponseWriter, r *http.Request) {
+	testID, err := uuid.Parse(chi.URLParam(r, "testId"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid test ID")
+		return
+	}
+
+	analysis, err := h.testUC.GetItemAnalysis(middleware.GetTenant(r.Context()), testID)
+	if err != nil {
+		switch err {
+		case test.ErrTestNotFound:
+			respondError(w, http.StatusNotFound, err.Error())
+		default:
+			respondError(w, http.StatusInternalServerError, "failed to analyze test")
+		}
+		return
+	}
+
+	respondJSON(w, http.StatusOK, analysis)
+}
+
+// Largest question bank file accepted for import
+const maxImportSize = 32 << 20
+
+// ExportQuestions downloads a test's questions as a QTI 2.1 package or GIFT
+// file (admin). Questions the format can't hold are listed in the file and
+// counted in the X-Skipped-Questions header.
+func (h *TestHandler) ExportQuestions(w http.ResponseWriter, r *http.Request) {
+	testID, err := uuid.Parse(chi.URLParam(r, "testId"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid test ID")
+		return
+	}
+
+	format := domain.InterchangeFormat(r.URL.Query().Get("format"))
+	export, err := h.testUC.ExportQuestions(middleware.GetTenant(r.Context()), testID, format)
+	if err != nil {
+		switch err {
+		case test.ErrTestNotFound:
+			respondError(w, http.StatusNotFound, err.Error())
+		case test.ErrUnsupportedFormat:
+			respondError(w, http.StatusBadRequest, err.Error())
+		default:
+			respondError(w, http.StatusInternalServerError, "failed to export questions")
+		}
+		return
+	}
+
+	skipped := 0
+	for _, issue := range export.Issues {
+		if issue.Skipped {
+			skipped++
+		}
+	}
+
+	w.Header().Set("Content-Type", export.ContentType)
+	w.Header().Set("Content-Disposition", "attachment; filename="+export.Filename)
+	w.Header().Set("X-Skipped-Questions", strconv.Itoa(skipped))
+	w.WriteHeader(http.StatusOK)
+	w.Write(export.Data)
+}
+
+// ImportQuestions adds the questions of an uploaded QTI 2.1 package or GIFT
+// file, sent as the request body, to a test (admin). With dryRun=true the
+// file is only checked. The report lists what could not be mapped.
+func (h *TestHandler) ImportQuestions(w http.ResponseWriter, r *http.Request) {
+	testID, err := uuid.Parse(chi.URLParam(r, "testId"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid test ID")
+		return
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxImportSize))
+	if err != nil {
+		respondError(w, http.StatusRequestEntityTooLarge, "import file too large")
+		return
+	}
+	if len(data) == 0 {
+		respondError(w, http.StatusBadRequest, "import file required")
+		return
+	}
+
+	format := domain.InterchangeFormat(r.URL.Query().Get("format"))
+	dryRun := r.URL.Query().Get("dryRun") == "true"
+	report, err := h.testUC.ImportQuestions(middleware.GetTenant(r.Context()), testID, format, data, dryRun)
+	if err != nil {
+		if errors.Is(err, test.ErrInvalidImportFile) {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		switch err {
+		case test.ErrTestNotFound:
+			respondError(w, http.StatusNotFound, err.Error())
+		case test.ErrUnsupportedFormat:
+			respondError(w, http.StatusBadRequest, err.Error())
+		default:
+			respondError(w, http.StatusInternalServerError, "failed to import questions")
+		}
+		return
+	}
+
+	status := http.StatusCreated
+	if dryRun {
+		status = http.StatusOK
+	}
+	respondJSON(w, status, report)
+}
+
+// GrantAttempts gives a learner extra attempts at a test (admin)
+func (h *TestHandler) GrantAttempts(w http.ResponseWriter, r *http.Request) {
+	testIDStr := chi.URLParam(r, "testId")
+	testID, err := uuid.Parse(testIDStr)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid test ID")
+		return
+	}
+
+	var req domain.GrantAttemptsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	adminID := middleware.GetUserID(r.Context())
+
+	grant, err := h.testUC.GrantAttempts(middleware.GetTenant(r.Context()), testID, adminID, &req)
+	if err != nil {
+		switch err {
+		case test.ErrTestNotFound:
+			respondError(w, http.StatusNotFound, err.Error())
+		default:
+			respondError(w, http.StatusInternalServerError, "failed to grant attempts")
+		}
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, grant)
+}
+
 func (h *TestHandler) CreateQuestion(w http.ResponseWriter, r *http.Request) {
 	var req domain.CreateQuestionRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -173,9 +510,16 @@ func (h *TestHandler) CreateQuestion(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	question, err := h.testUC.CreateQuestion(&req)
+	question, err := h.testUC.CreateQuestion(middleware.GetTenant(r.Context()), &req)
 	if err != nil {
-		respondError(w, http.StatusInternalServerError, "failed to create question")
+		switch err {
+		case test.ErrTestNotFound:
+			respondError(w, http.StatusNotFound, err.Error())
+		case test.ErrInvalidQuestionData:
+			respondError(w, http.StatusBadRequest, err.Error())
+		default:
+			respondError(w, http.StatusInternalServerError, "failed to create question")
+		}
 		return
 	}
 
@@ -196,6 +540,8 @@ func stripCorrectAnswers(qType domain.QuestionType, data json.RawMessage) json.R
 		delete(result, "explanation")
 	case domain.QuestionTypeFillBlank:
 		delete(result, "blanks")
+		delete(result, "alternatives")
+		delete(result, "patterns")
 		delete(result, "explanation")
 	case domain.QuestionTypeMatching:
 		delete(result, "correctPairs")
@@ -203,6 +549,25 @@ func stripCorrectAnswers(qType domain.QuestionType, data json.RawMessage) json.R
 	case domain.QuestionTypeOrdering:
 		delete(result, "correctOrder")
 		delete(result, "explanation")
+	case domain.QuestionTypeTrueFalse:
+		delete(result, "correctAnswer")
+		delete(result, "explanation")
+	case domain.QuestionTypeNumeric:
+		delete(result, "correctValue")
+		delete(result, "tolerance")
+		delete(result, "explanation")
+	case domain.QuestionTypeHotspot:
+		// The learner sees only the image; the regions are what to find
+		delete(result, "regions")
+		delete(result, "explanation")
+	case domain.QuestionTypeShortText:
+		delete(result, "rubric")
+		delete(result, "sampleAnswer")
+		delete(result, "explanation")
+	case domain.QuestionTypeOpenEnded:
+		delete(result, "rubric")
+		delete(result, "modelAnswer")
+		delete(result, "explanation")
 	}
 
 	stripped, _ := json.Marshal(result)
@@ -218,7 +583,7 @@ func (h *TestHandler) GetQuestionsByCourseID(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	testObj, err := h.testUC.GetTestByCourseIDWithQuestions(courseID)
+	testObj, err := h.testUC.GetTestByCourseIDWithQuestions(middleware.GetTenant(r.Context()), courseID)
 	if err != nil {
 		switch err {
 		case test.ErrTestNotFound:
@@ -252,9 +617,16 @@ func (h *TestHandler) UpdateQuestion(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	question, err := h.testUC.UpdateQuestion(questionID, &req)
+	question, err := h.testUC.UpdateQuestion(middleware.GetTenant(r.Context()), questionID, &req)
 	if err != nil {
-		respondError(w, http.StatusInternalServerError, "failed to update question: "+err.Error())
+		switch err {
+		case test.ErrQuestionNotFound:
+			respondError(w, http.StatusNotFound, err.Error())
+		case test.ErrInvalidQuestionData:
+			respondError(w, http.StatusBadRequest, err.Error())
+		default:
+			respondError(w, http.StatusInternalServerError, "failed to update question: "+err.Error())
+		}
 		return
 	}
 
@@ -270,8 +642,13 @@ func (h *TestHandler) DeleteQuestion(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.testUC.DeleteQuestion(questionID); err != nil {
-		respondError(w, http.StatusInternalServerError, "failed to delete question: "+err.Error())
+	if err := h.testUC.DeleteQuestion(middleware.GetTenant(r.Context()), questionID); err != nil {
+		switch err {
+		case test.ErrQuestionNotFound:
+			respondError(w, http.StatusNotFound, err.Error())
+		default:
+			respondError(w, http.StatusInternalServerError, "failed to delete question: "+err.Error())
+		}
 		return
 	}
 
@@ -2,11 +2,14 @@ package handler
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
+	"github.com/secusense/backend/internal/delivery/http/middleware"
 	"github.com/secusense/backend/internal/domain"
 	"github.com/secusense/backend/internal/usecase/workflow"
 )
@@ -36,7 +39,7 @@ func (h *WorkflowHandler) StartResearch(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	session, err := h.workflowUC.StartResearch(r.Context(), &req)
+	session, err := h.workflowUC.StartResearch(r.Context(), middleware.GetTenant(r.Context()), &req)
 	if err != nil {
 		respondError(w, http.StatusInternalServerError, "failed to start research: "+err.Error())
 		return
@@ -54,7 +57,7 @@ func (h *WorkflowHandler) GetSession(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	session, err := h.workflowUC.GetSession(id)
+	session, err := h.workflowUC.GetSession(middleware.GetTenant(r.Context()), id)
 	if err != nil {
 		switch err {
 		case workflow.ErrSessionNotFound:
@@ -93,7 +96,7 @@ func (h *WorkflowHandler) UpdateSuggestionStatus(w http.ResponseWriter, r *http.
 		return
 	}
 
-	if err := h.workflowUC.UpdateSuggestionStatus(sessionID, suggestionID, req.Status); err != nil {
+	if err := h.workflowUC.UpdateSuggestionStatus(middleware.GetTenant(r.Context()), sessionID, suggestionID, req.Status); err != nil {
 		switch err {
 		case workflow.ErrSessionNotFound, workflow.ErrSuggestionNotFound:
 			respondError(w, http.StatusNotFound, err.Error())
@@ -127,7 +130,7 @@ func (h *WorkflowHandler) AddCustomTopic(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	suggestion, err := h.workflowUC.AddCustomTopic(sessionID, &req)
+	suggestion, err := h.workflowUC.AddCustomTopic(middleware.GetTenant(r.Context()), sessionID, &req)
 	if err != nil {
 		switch err {
 		case workflow.ErrSessionNotFound:
@@ -151,7 +154,7 @@ func (h *WorkflowHandler) GenerateMoreSuggestions(w http.ResponseWriter, r *http
 		return
 	}
 
-	if err := h.workflowUC.GenerateMoreSuggestions(r.Context(), sessionID); err != nil {
+	if err := h.workflowUC.GenerateMoreSuggestions(r.Context(), middleware.GetTenant(r.Context()), sessionID); err != nil {
 		switch err {
 		case workflow.ErrSessionNotFound:
 			respondError(w, http.StatusNotFound, err.Error())
@@ -164,7 +167,7 @@ func (h *WorkflowHandler) GenerateMoreSuggestions(w http.ResponseWriter, r *http
 	}
 
 	// Return updated session
-	session, _ := h.workflowUC.GetSession(sessionID)
+	session, _ := h.workflowUC.GetSession(middleware.GetTenant(r.Context()), sessionID)
 	respondJSON(w, http.StatusOK, session)
 }
 
@@ -176,7 +179,7 @@ func (h *WorkflowHandler) ProceedToRefinement(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	session, err := h.workflowUC.ProceedToRefinement(r.Context(), sessionID)
+	session, err := h.workflowUC.ProceedToRefinement(r.Context(), middleware.GetTenant(r.Context()), sessionID)
 	if err != nil {
 		switch err {
 		case workflow.ErrSessionNotFound:
@@ -200,7 +203,7 @@ func (h *WorkflowHandler) ProceedToScriptGeneration(w http.ResponseWriter, r *ht
 		return
 	}
 
-	session, err := h.workflowUC.ProceedToScriptGeneration(r.Context(), sessionID)
+	session, err := h.workflowUC.ProceedToScriptGeneration(r.Context(), middleware.GetTenant(r.Context()), sessionID)
 	if err != nil {
 		switch err {
 		case workflow.ErrSessionNotFound:
@@ -224,7 +227,7 @@ func (h *WorkflowHandler) ProceedToVideoGeneration(w http.ResponseWriter, r *htt
 		return
 	}
 
-	session, err := h.workflowUC.ProceedToVideoGeneration(r.Context(), sessionID)
+	session, err := h.workflowUC.ProceedToVideoGeneration(r.Context(), middleware.GetTenant(r.Context()), sessionID)
 	if err != nil {
 		switch err {
 		case workflow.ErrSessionNotFound:
@@ -265,7 +268,7 @@ func (h *WorkflowHandler) UpdateRefinedTopic(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	topic, err := h.workflowUC.UpdateRefinedTopic(sessionID, topicID, &req)
+	topic, err := h.workflowUC.UpdateRefinedTopic(middleware.GetTenant(r.Context()), sessionID, topicID, &req)
 	if err != nil {
 		switch err {
 		case workflow.ErrSessionNotFound, workflow.ErrTopicNotFound:
@@ -295,7 +298,7 @@ func (h *WorkflowHandler) RegenerateTopic(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	topic, err := h.workflowUC.RegenerateSingleTopic(r.Context(), sessionID, topicID)
+	topic, err := h.workflowUC.RegenerateSingleTopic(r.Context(), middleware.GetTenant(r.Context()), sessionID, topicID)
 	if err != nil {
 		switch err {
 		case workflow.ErrSessionNotFound, workflow.ErrTopicNotFound, workflow.ErrSuggestionNotFound:
@@ -330,7 +333,7 @@ func (h *WorkflowHandler) ReorderRefinedTopics(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	if err := h.workflowUC.ReorderRefinedTopics(sessionID, req.TopicOrders); err != nil {
+	if err := h.workflowUC.ReorderRefinedTopics(middleware.GetTenant(r.Context()), sessionID, req.TopicOrders); err != nil {
 		switch err {
 		case workflow.ErrSessionNotFound:
 			respondError(w, http.StatusNotFound, err.Error())
@@ -343,7 +346,7 @@ func (h *WorkflowHandler) ReorderRefinedTopics(w http.ResponseWriter, r *http.Re
 	}
 
 	// Return updated session
-	session, _ := h.workflowUC.GetSession(sessionID)
+	session, _ := h.workflowUC.GetSession(middleware.GetTenant(r.Context()), sessionID)
 	respondJSON(w, http.StatusOK, session)
 }
 
@@ -367,7 +370,7 @@ func (h *WorkflowHandler) SetOutputType(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	if err := h.workflowUC.SetLessonOutputType(sessionID, lessonID, req.OutputType); err != nil {
+	if err := h.workflowUC.SetLessonOutputType(middleware.GetTenant(r.Context()), sessionID, lessonID, req.OutputType); err != nil {
 		switch err {
 		case workflow.ErrSessionNotFound:
 			respondError(w, http.StatusNotFound, "session not found")
@@ -382,7 +385,7 @@ func (h *WorkflowHandler) SetOutputType(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Return updated session
-	session, _ := h.workflowUC.GetSession(sessionID)
+	session, _ := h.workflowUC.GetSession(middleware.GetTenant(r.Context()), sessionID)
 	respondJSON(w, http.StatusOK, session)
 }
 
@@ -400,7 +403,7 @@ func (h *WorkflowHandler) GeneratePresentation(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	presentation, err := h.workflowUC.GeneratePresentation(r.Context(), sessionID, lessonID)
+	presentation, err := h.workflowUC.GeneratePresentation(r.Context(), middleware.GetTenant(r.Context()), sessionID, lessonID)
 	if err != nil {
 		switch err {
 		case workflow.ErrSessionNotFound:
@@ -430,7 +433,7 @@ func (h *WorkflowHandler) GetPresentation(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	presentation, err := h.workflowUC.GetPresentation(sessionID, lessonID)
+	presentation, err := h.workflowUC.GetPresentation(middleware.GetTenant(r.Context()), sessionID, lessonID)
 	if err != nil {
 		switch err {
 		case workflow.ErrSessionNotFound:
@@ -467,7 +470,7 @@ func (h *WorkflowHandler) RegenerateAudio(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	presentation, err := h.workflowUC.RegenerateAudio(r.Context(), sessionID, lessonID)
+	presentation, err := h.workflowUC.RegenerateAudio(r.Context(), middleware.GetTenant(r.Context()), sessionID, lessonID)
 	if err != nil {
 		switch err {
 		case workflow.ErrSessionNotFound:
@@ -508,7 +511,7 @@ func (h *WorkflowHandler) UpdateLessonScript(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	script, err := h.workflowUC.UpdateLessonScript(sessionID, lessonID, &req)
+	script, err := h.workflowUC.UpdateLessonScript(middleware.GetTenant(r.Context()), sessionID, lessonID, &req)
 	if err != nil {
 		switch err {
 		case workflow.ErrSessionNotFound:
@@ -538,7 +541,7 @@ func (h *WorkflowHandler) RegenerateScript(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	script, err := h.workflowUC.RegenerateScript(r.Context(), sessionID, lessonID)
+	script, err := h.workflowUC.RegenerateScript(r.Context(), middleware.GetTenant(r.Context()), sessionID, lessonID)
 	if err != nil {
 		switch err {
 		case workflow.ErrSessionNotFound:
@@ -565,7 +568,7 @@ func (h *WorkflowHandler) GetCourseLessons(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	lessons, err := h.workflowUC.GetCourseLessons(courseID)
+	lessons, err := h.workflowUC.GetCourseLessons(middleware.GetTenant(r.Context()), courseID)
 	if err != nil {
 		respondError(w, http.StatusNotFound, "no lessons found for this course")
 		return
@@ -582,7 +585,7 @@ func (h *WorkflowHandler) ProceedToQuestionGeneration(w http.ResponseWriter, r *
 		return
 	}
 
-	session, err := h.workflowUC.ProceedToQuestionGeneration(r.Context(), sessionID)
+	session, err := h.workflowUC.ProceedToQuestionGeneration(r.Context(), middleware.GetTenant(r.Context()), sessionID)
 	if err != nil {
 		switch err {
 		case workflow.ErrSessionNotFound:
@@ -606,7 +609,7 @@ func (h *WorkflowHandler) PreviewQuestions(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	preview, err := h.workflowUC.PreviewQuestions(r.Context(), sessionID)
+	preview, err := h.workflowUC.PreviewQuestions(r.Context(), middleware.GetTenant(r.Context()), sessionID)
 	if err != nil {
 		switch err {
 		case workflow.ErrSessionNotFound:
@@ -647,9 +650,14 @@ func (h *WorkflowHandler) UpdateLessonScriptForCourse(w http.ResponseWriter, r *
 		return
 	}
 
-	lesson, err := h.workflowUC.UpdateLessonScriptForCourse(r.Context(), courseID, lessonID, &req)
+	lesson, err := h.workflowUC.UpdateLessonScriptForCourse(r.Context(), middleware.GetTenant(r.Context()), courseID, lessonID, &req)
 	if err != nil {
-		respondError(w, http.StatusInternalServerError, "failed to update lesson: "+err.Error())
+		switch err {
+		case workflow.ErrSessionNotFound:
+			respondError(w, http.StatusNotFound, err.Error())
+		default:
+			respondError(w, http.StatusInternalServerError, "failed to update lesson: "+err.Error())
+		}
 		return
 	}
 
@@ -670,9 +678,14 @@ func (h *WorkflowHandler) RegenerateLessonScriptForCourse(w http.ResponseWriter,
 		return
 	}
 
-	lesson, err := h.workflowUC.RegenerateLessonScriptForCourse(r.Context(), courseID, lessonID)
+	lesson, err := h.workflowUC.RegenerateLessonScriptForCourse(r.Context(), middleware.GetTenant(r.Context()), courseID, lessonID)
 	if err != nil {
-		respondError(w, http.StatusInternalServerError, "failed to regenerate lesson: "+err.Error())
+		switch err {
+		case workflow.ErrSessionNotFound:
+			respondError(w, http.StatusNotFound, err.Error())
+		default:
+			respondError(w, http.StatusInternalServerError, "failed to regenerate lesson: "+err.Error())
+		}
 		return
 	}
 
@@ -693,11 +706,96 @@ func (h *WorkflowHandler) RegeneratePresentationForCourse(w http.ResponseWriter,
 		return
 	}
 
-	presentation, err := h.workflowUC.RegeneratePresentationForCourse(r.Context(), courseID, lessonID)
+	presentation, err := h.workflowUC.RegeneratePresentationForCourse(r.Context(), middleware.GetTenant(r.Context()), courseID, lessonID)
 	if err != nil {
-		respondError(w, http.StatusInternalServerError, "failed to regenerate presentation: "+err.Error())
+		switch err {
+		case workflow.ErrSessionNotFound:
+			respondError(w, http.StatusNotFound, err.Error())
+		default:
+			respondError(w, http.StatusInternalServerError, "failed to regenerate presentation: "+err.Error())
+		}
 		return
 	}
 
 	respondJSON(w, http.StatusAccepted, presentation)
 }
+
+// StreamEvents streams workflow progress as server-sent events. The first
+// event is a snapshot of the session's current step so clients don't miss
+// transitions that happened before they connected.
+func (h *WorkflowHandler) StreamEvents(w http.ResponseWriter, r *http.Request) {
+	sessionID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid session ID")
+		return
+	}
+
+	session, events, cancel, err := h.workflowUC.SubscribeEvents(middleware.GetTenant(r.Context()), sessionID)
+	if err != nil {
+		switch err {
+		case workflow.ErrSessionNotFound:
+			respondError(w, http.StatusNotFound, err.Error())
+		default:
+			respondError(w, http.StatusInternalServerError, "failed to subscribe to session events")
+		}
+		return
+	}
+	defer cancel()
+
+	// The stream outlives the server's write timeout
+	rc := http.NewResponseController(w)
+	rc.SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	writeEvent := func(event domain.WorkflowEvent) error {
+		data, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data); err != nil {
+			return err
+		}
+		return rc.Flush()
+	}
+
+	snapshot := domain.WorkflowEvent{
+		Type:      domain.WorkflowEventStepChanged,
+		SessionID: session.ID,
+		Step:      session.CurrentStep,
+		Status:    session.Status,
+		At:        session.UpdatedAt,
+	}
+	if err := writeEvent(snapshot); err != nil {
+		return
+	}
+
+	keepAlive := time.NewTicker(15 * time.Second)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			if err := writeEvent(event); err != nil {
+				return
+			}
+		case <-keepAlive.C:
+			// Comment line keeps proxies from closing an idle connection
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -13,9 +13,11 @@ import (
 type contextKey string
 
 const (
-	UserIDKey   contextKey = "userID"
-	UserRoleKey contextKey = "userRole"
-	UserEmailKey contextKey = "userEmail"
+	UserIDKey         contextKey = "userID"
+	UserRoleKey       contextKey = "userRole"
+	UserEmailKey      contextKey = "userEmail"
+	OrganizationIDKey contextKey = "organizationID"
+	PermissionsKey    contextKey = "permissions"
 )
 
 type AuthMiddleware struct {
@@ -50,25 +52,55 @@ func (m *AuthMiddleware) Authenticate(next http.Handler) http.Handler {
 			return
 		}
 
-		ctx := context.WithValue(r.Context(), UserIDKey, claims.UserID)
-		ctx = context.WithValue(ctx, UserRoleKey, claims.Role)
-		ctx = context.WithValue(ctx, UserEmailKey, claims.Email)
-
-		next.ServeHTTP(w, r.WithContext(ctx))
+		next.ServeHTTP(w, r.WithContext(withClaims(r.Context(), claims)))
 	})
 }
 
-func (m *AuthMiddleware) RequireAdmin(next http.Handler) http.Handler {
+// OptionalAuthenticate identifies the user when a valid token is sent and
+// lets the request through anonymously otherwise, for public routes whose
+// results depend on the tenant
+func (m *AuthMiddleware) OptionalAuthenticate(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		role, ok := r.Context().Value(UserRoleKey).(domain.UserRole)
-		if !ok || role != domain.RoleAdmin {
-			http.Error(w, `{"error": "admin access required"}`, http.StatusForbidden)
-			return
+		parts := strings.Split(r.Header.Get("Authorization"), " ")
+		if len(parts) == 2 && parts[0] == "Bearer" {
+			if claims, err := m.jwtManager.ValidateAccessToken(parts[1]); err == nil {
+				r = r.WithContext(withClaims(r.Context(), claims))
+			}
 		}
 		next.ServeHTTP(w, r)
 	})
 }
 
+func withClaims(ctx context.Context, claims *jwt.Claims) context.Context {
+	// Tokens issued before organizations existed carry no tenant; all of
+	// their users belong to the default organization
+	orgID := claims.OrganizationID
+	if orgID == uuid.Nil {
+		orgID = domain.DefaultOrganizationID
+	}
+
+	ctx = context.WithValue(ctx, UserIDKey, claims.UserID)
+	ctx = context.WithValue(ctx, UserRoleKey, claims.Role)
+	ctx = context.WithValue(ctx, UserEmailKey, claims.Email)
+	ctx = context.WithValue(ctx, OrganizationIDKey, orgID)
+	ctx = context.WithValue(ctx, PermissionsKey, claims.Permissions)
+	return ctx
+}
+
+// RequirePermission lets only users whose role grants the permission
+// through
+func (m *AuthMiddleware) RequirePermission(permission domain.Permission) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !HasPermission(r.Context(), permission) {
+				http.Error(w, `{"error": "permission `+string(permission)+` required"}`, http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
 func GetUserID(ctx context.Context) uuid.UUID {
 	userID, _ := ctx.Value(UserIDKey).(uuid.UUID)
 	return userID
@@ -83,3 +115,35 @@ func GetUserEmail(ctx context.Context) string {
 	email, _ := ctx.Value(UserEmailKey).(string)
 	return email
 }
+
+func GetOrganizationID(ctx context.Context) uuid.UUID {
+	orgID, _ := ctx.Value(OrganizationIDKey).(uuid.UUID)
+	return orgID
+}
+
+// GetTenant returns the data scope of the request. Super admins see every
+// organization; anonymous requests see the default one.
+func GetTenant(ctx context.Context) domain.Tenant {
+	orgID, ok := ctx.Value(OrganizationIDKey).(uuid.UUID)
+	if !ok {
+		return domain.TenantOf(domain.DefaultOrganizationID)
+	}
+	return domain.Tenant{
+		OrganizationID: orgID,
+		CrossTenant:    GetUserRole(ctx) == domain.RoleSuperAdmin,
+	}
+}
+
+func GetPermissions(ctx context.Context) []domain.Permission {
+	permissions, _ := ctx.Value(PermissionsKey).([]domain.Permission)
+	return permissions
+}
+
+func HasPermission(ctx context.Context, permission domain.Permission) bool {
+	for _, p := range GetPermissions(ctx) {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/go-chi/cors"
 	"github.com/secusense/backend/internal/delivery/http/handler"
 	authMiddleware "github.com/secusense/backend/internal/delivery/http/middleware"
+	"github.com/secusense/backend/internal/domain"
 )
 
 type Router struct {
@@ -20,6 +21,13 @@ type Router struct {
 	certHandler     *handler.CertificateHandler
 	aiHandler       *handler.AIHandler
 	workflowHandler *handler.WorkflowHandler
+	exportHandler   *handler.ExportHandler
+	ltiHandler      *handler.LTIHandler
+	orgHandler      *handler.OrganizationHandler
+	roleHandler     *handler.RoleHandler
+	groupHandler    *handler.GroupHandler
+	auditHandler    *handler.AuditHandler
+	mediaHandler    *handler.MediaHandler
 }
 
 type RouterConfig struct {
@@ -36,6 +44,13 @@ func NewRouter(
 	certH *handler.CertificateHandler,
 	aiH *handler.AIHandler,
 	workflowH *handler.WorkflowHandler,
+	exportH *handler.ExportHandler,
+	ltiH *handler.LTIHandler,
+	orgH *handler.OrganizationHandler,
+	roleH *handler.RoleHandler,
+	groupH *handler.GroupHandler,
+	auditH *handler.AuditHandler,
+	mediaH *handler.MediaHandler,
 ) *Router {
 	r := &Router{
 		chi:             chi.NewRouter(),
@@ -47,6 +62,13 @@ func NewRouter(
 		certHandler:     certH,
 		aiHandler:       aiH,
 		workflowHandler: workflowH,
+		exportHandler:   exportH,
+		ltiHandler:      ltiH,
+		orgHandler:      orgH,
+		roleHandler:     roleH,
+		groupHandler:    groupH,
+		auditHandler:    auditH,
+		mediaHandler:    mediaH,
 	}
 
 	// Global middleware
@@ -77,6 +99,9 @@ func (r *Router) setupRoutes() {
 		w.Write([]byte(`{"status": "ok"}`))
 	})
 
+	// Public keys of signed certificates, for verifying them offline
+	r.chi.Get("/.well-known/certificate-keys.json", r.certHandler.Keys)
+
 	r.chi.Route("/api/v1", func(api chi.Router) {
 		// Public routes
 		api.Route("/auth", func(auth chi.Router) {
@@ -87,21 +112,36 @@ func (r *Router) setupRoutes() {
 
 		// Public certificate verification
 		api.Get("/certificates/verify/{hash}", r.certHandler.Verify)
+		api.Post("/certificates/verify", r.certHandler.VerifyCredential)
+		api.Get("/certificates/revocation-list", r.certHandler.RevocationList)
+
+		// Public course listing, scoped to the caller's organization when
+		// signed in
+		api.Group(func(public chi.Router) {
+			public.Use(r.authMiddleware.OptionalAuthenticate)
 
-		// Public course listing
-		api.Get("/courses", r.courseHandler.List)
-		api.Get("/courses/{id}", r.courseHandler.GetByID)
-		api.Get("/courses/{courseId}/lessons", r.workflowHandler.GetCourseLessons)
+			public.Get("/courses", r.courseHandler.List)
+			public.Get("/courses/{id}", r.courseHandler.GetByID)
+			public.Get("/courses/{courseId}/lessons", r.workflowHandler.GetCourseLessons)
+		})
+
+		// LTI 1.3 launches from external LMSs
+		api.Route("/lti", func(lti chi.Router) {
+			lti.Get("/login", r.ltiHandler.Login)
+			lti.Post("/login", r.ltiHandler.Login)
+			lti.Post("/launch", r.ltiHandler.Launch)
+			lti.Get("/jwks", r.ltiHandler.JWKS)
+			lti.Post("/session", r.ltiHandler.Session)
+		})
 
 		// Synthesia webhook (public but should be secured in production)
 		api.Post("/webhooks/synthesia", r.aiHandler.SynthesiaWebhook)
 
-		// Serve generated audio files for presentations (public)
-		api.Get("/audio/*", func(w http.ResponseWriter, req *http.Request) {
-			// Serve files from the generated/audio directory
-			fs := http.StripPrefix("/api/v1/audio/", http.FileServer(http.Dir("./generated/audio")))
-			fs.ServeHTTP(w, req)
-		})
+		// Narration audio and slide images of presentations (public), and
+		// the files of local storage to signed URLs
+		api.Get("/media/*", r.mediaHandler.Media)
+		api.Get("/audio/*", r.mediaHandler.LegacyAudio)
+		api.Get("/files/*", r.mediaHandler.File)
 
 		// Protected routes
 		api.Group(func(protected chi.Router) {
@@ -109,6 +149,7 @@ func (r *Router) setupRoutes() {
 
 			// Auth
 			protected.Get("/auth/me", r.authHandler.Me)
+			protected.Get("/organization", r.orgHandler.GetCurrent)
 
 			// Course enrollment
 			protected.Post("/courses/{id}/enroll", r.enrollHandler.Enroll)
@@ -120,80 +161,191 @@ func (r *Router) setupRoutes() {
 			protected.Get("/enrollments/{id}", r.enrollHandler.GetByID)
 			protected.Put("/enrollments/{id}/progress", r.enrollHandler.UpdateProgress)
 			protected.Post("/enrollments/{id}/complete-video", r.enrollHandler.CompleteVideo)
+			protected.Post("/enrollments/{id}/launch", r.enrollHandler.Launch)
+			protected.Post("/enrollments/{id}/slide-views", r.enrollHandler.RecordSlideView)
 
 			// Tests
 			protected.Post("/tests/{testId}/attempts", r.testHandler.StartAttempt)
+			protected.Get("/tests/{testId}/attempts/current", r.testHandler.ResumeAttempt)
+			protected.Put("/attempts/{attemptId}/answers", r.testHandler.SaveAnswer)
 			protected.Post("/attempts/{attemptId}/submit", r.testHandler.SubmitAttempt)
 			protected.Get("/attempts/{attemptId}/results", r.testHandler.GetAttemptResults)
 
 			// Certificates
 			protected.Get("/certificates", r.certHandler.List)
 			protected.Get("/certificates/{id}", r.certHandler.GetByID)
+			protected.Get("/certificates/{id}/history", r.certHandler.History)
 			protected.Post("/certificates", r.certHandler.Generate)
 			protected.Get("/certificates/{id}/download", r.certHandler.Download)
+			protected.Get("/certificates/{id}/link", r.certHandler.Link)
+			protected.Get("/certificates/{id}/badge", r.certHandler.DownloadBadge)
+
+			// LTI deep linking
+			protected.Post("/lti/deep-link", r.ltiHandler.DeepLink)
 
-			// Admin routes
-			protected.Group(func(admin chi.Router) {
-				admin.Use(r.authMiddleware.RequireAdmin)
+			// Admin routes, each group behind the permission it needs
+			protected.Group(func(courses chi.Router) {
+				courses.Use(r.authMiddleware.RequirePermission(domain.PermCourseWrite))
 
 				// Course management
-				admin.Get("/admin/courses", r.courseHandler.ListAll)
-				admin.Post("/admin/courses", r.courseHandler.Create)
-				admin.Put("/admin/courses/{id}", r.courseHandler.Update)
-				admin.Delete("/admin/courses/{id}", r.courseHandler.Delete)
-				admin.Post("/admin/courses/{id}/publish", r.courseHandler.Publish)
-				admin.Post("/admin/courses/{id}/unpublish", r.courseHandler.Unpublish)
+				courses.Get("/admin/courses", r.courseHandler.ListAll)
+				courses.Post("/admin/courses", r.courseHandler.Create)
+				courses.Put("/admin/courses/{id}", r.courseHandler.Update)
+				courses.Delete("/admin/courses/{id}", r.courseHandler.Delete)
+				courses.Post("/admin/courses/{id}/publish", r.courseHandler.Publish)
+				courses.Post("/admin/courses/{id}/unpublish", r.courseHandler.Unpublish)
+				courses.Get("/admin/courses/{id}/scorm", r.exportHandler.ExportSCORM)
+
+				// Lesson management for completed courses
+				courses.Put("/admin/courses/{courseId}/lessons/{lessonId}", r.workflowHandler.UpdateLessonScriptForCourse)
+				courses.Post("/admin/courses/{courseId}/lessons/{lessonId}/regenerate", r.workflowHandler.RegenerateLessonScriptForCourse)
+				courses.Post("/admin/courses/{courseId}/lessons/{lessonId}/regenerate-presentation", r.workflowHandler.RegeneratePresentationForCourse)
+
+				// Video status
+				courses.Post("/admin/courses/{id}/refresh-video", r.aiHandler.RefreshVideoStatus)
+				courses.Post("/admin/videos/poll", r.aiHandler.PollPendingVideos)
+
+				// Certificate templates
+				courses.Get("/admin/certificate-templates", r.certHandler.ListTemplates)
+				courses.Post("/admin/certificate-templates", r.certHandler.CreateTemplate)
+				courses.Post("/admin/certificate-templates/preview", r.certHandler.PreviewDraft)
+				courses.Get("/admin/certificate-templates/{id}", r.certHandler.GetTemplate)
+				courses.Put("/admin/certificate-templates/{id}", r.certHandler.UpdateTemplate)
+				courses.Delete("/admin/certificate-templates/{id}", r.certHandler.DeleteTemplate)
+				courses.Get("/admin/certificate-templates/{id}/preview", r.certHandler.PreviewTemplate)
+			})
+
+			protected.Group(func(tests chi.Router) {
+				tests.Use(r.authMiddleware.RequirePermission(domain.PermTestWrite))
 
 				// Test management
-				admin.Post("/admin/tests", r.testHandler.CreateTest)
-				admin.Post("/admin/questions", r.testHandler.CreateQuestion)
+				tests.Post("/admin/tests", r.testHandler.CreateTest)
+				tests.Put("/admin/tests/{testId}", r.testHandler.UpdateTest)
+				tests.Post("/admin/tests/{testId}/grants", r.testHandler.GrantAttempts)
+				tests.Get("/admin/tests/{testId}/questions/export", r.testHandler.ExportQuestions)
+				tests.Post("/admin/tests/{testId}/questions/import", r.testHandler.ImportQuestions)
+				tests.Post("/admin/questions", r.testHandler.CreateQuestion)
 
 				// Question management for existing courses
-				admin.Get("/admin/courses/{courseId}/questions", r.testHandler.GetQuestionsByCourseID)
-				admin.Put("/admin/questions/{questionId}", r.testHandler.UpdateQuestion)
-				admin.Delete("/admin/questions/{questionId}", r.testHandler.DeleteQuestion)
+				tests.Get("/admin/courses/{courseId}/questions", r.testHandler.GetQuestionsByCourseID)
+				tests.Put("/admin/questions/{questionId}", r.testHandler.UpdateQuestion)
+				tests.Delete("/admin/questions/{questionId}", r.testHandler.DeleteQuestion)
+			})
 
-				// AI generation
-				admin.Post("/admin/generate/course", r.aiHandler.GenerateCourse)
-				admin.Get("/admin/generate/jobs/{id}", r.aiHandler.GetJob)
+			protected.Group(func(reviews chi.Router) {
+				reviews.Use(r.authMiddleware.RequirePermission(domain.PermReviewGrade))
+
+				reviews.Get("/admin/reviews", r.testHandler.ListReviews)
+				reviews.Post("/admin/reviews/{reviewId}/resolve", r.testHandler.ResolveReview)
+			})
 
-				// Video generation
-				admin.Post("/admin/courses/{id}/refresh-video", r.aiHandler.RefreshVideoStatus)
-				admin.Post("/admin/videos/poll", r.aiHandler.PollPendingVideos)
+			protected.Group(func(reports chi.Router) {
+				reports.Use(r.authMiddleware.RequirePermission(domain.PermReportsRead))
+
+				reports.Get("/admin/tests/{testId}/item-analysis", r.testHandler.GetItemAnalysis)
+				reports.Get("/admin/enrollments", r.enrollHandler.ListByOrganization)
+				reports.Get("/admin/certificates", r.certHandler.ListByOrganization)
+				reports.Get("/admin/assignments/overdue", r.groupHandler.ListOverdue)
+				reports.Get("/admin/audit-log", r.auditHandler.List)
+			})
+
+			protected.Group(func(certs chi.Router) {
+				certs.Use(r.authMiddleware.RequirePermission(domain.PermCertificatesRevoke))
+
+				certs.Post("/admin/certificates/{id}/revoke", r.certHandler.Revoke)
+			})
+
+			protected.Group(func(users chi.Router) {
+				users.Use(r.authMiddleware.RequirePermission(domain.PermUsersManage))
+
+				users.Get("/admin/roles", r.roleHandler.List)
+				users.Put("/admin/users/{id}/role", r.roleHandler.AssignRole)
+				users.Post("/admin/organizations/{id}/members", r.orgHandler.CreateMember)
+
+				// Groups and departments
+				users.Get("/admin/groups", r.groupHandler.List)
+				users.Post("/admin/groups", r.groupHandler.Create)
+				users.Get("/admin/groups/{id}", r.groupHandler.Get)
+				users.Put("/admin/groups/{id}", r.groupHandler.Update)
+				users.Delete("/admin/groups/{id}", r.groupHandler.Delete)
+				users.Get("/admin/groups/{id}/members", r.groupHandler.ListMembers)
+				users.Post("/admin/groups/{id}/members", r.groupHandler.AddMembers)
+				users.Delete("/admin/groups/{id}/members/{userId}", r.groupHandler.RemoveMember)
+			})
+
+			protected.Group(func(assignments chi.Router) {
+				assignments.Use(r.authMiddleware.RequirePermission(domain.PermAssignmentsManage))
+
+				// Course assignments
+				assignments.Get("/admin/assignments", r.groupHandler.ListAssignments)
+				assignments.Post("/admin/assignments", r.groupHandler.Assign)
+				assignments.Put("/admin/assignments/{id}", r.groupHandler.UpdateAssignment)
+				assignments.Delete("/admin/assignments/{id}", r.groupHandler.DeleteAssignment)
+			})
+
+			protected.Group(func(lti chi.Router) {
+				lti.Use(r.authMiddleware.RequirePermission(domain.PermLTIManage))
+
+				// LTI platforms
+				lti.Get("/admin/lti/tool", r.ltiHandler.GetToolConfiguration)
+				lti.Get("/admin/lti/platforms", r.ltiHandler.ListPlatforms)
+				lti.Post("/admin/lti/platforms", r.ltiHandler.CreatePlatform)
+				lti.Put("/admin/lti/platforms/{id}", r.ltiHandler.UpdatePlatform)
+				lti.Delete("/admin/lti/platforms/{id}", r.ltiHandler.DeletePlatform)
+			})
+
+			protected.Group(func(workflow chi.Router) {
+				workflow.Use(r.authMiddleware.RequirePermission(domain.PermWorkflowRun))
+
+				// AI generation
+				workflow.Post("/admin/generate/course", r.aiHandler.GenerateCourse)
+				workflow.Get("/admin/generate/jobs/{id}", r.aiHandler.GetJob)
 
 				// Course Workflow (multi-agency)
-				admin.Post("/admin/workflow/start", r.workflowHandler.StartResearch)
-				admin.Get("/admin/workflow/{id}", r.workflowHandler.GetSession)
-				admin.Put("/admin/workflow/{sessionId}/suggestions/{suggestionId}", r.workflowHandler.UpdateSuggestionStatus)
-				admin.Post("/admin/workflow/{sessionId}/suggestions", r.workflowHandler.AddCustomTopic)
-				admin.Post("/admin/workflow/{sessionId}/generate-more", r.workflowHandler.GenerateMoreSuggestions)
-				admin.Post("/admin/workflow/{sessionId}/refine", r.workflowHandler.ProceedToRefinement)
-				admin.Post("/admin/workflow/{sessionId}/scripts", r.workflowHandler.ProceedToScriptGeneration)
-				admin.Post("/admin/workflow/{sessionId}/videos", r.workflowHandler.ProceedToVideoGeneration)
+				workflow.Post("/admin/workflow/start", r.workflowHandler.StartResearch)
+				workflow.Get("/admin/workflow/{id}", r.workflowHandler.GetSession)
+				workflow.Get("/admin/workflow/{id}/events", r.workflowHandler.StreamEvents)
+				workflow.Put("/admin/workflow/{sessionId}/suggestions/{suggestionId}", r.workflowHandler.UpdateSuggestionStatus)
+				workflow.Post("/admin/workflow/{sessionId}/suggestions", r.workflowHandler.AddCustomTopic)
+				workflow.Post("/admin/workflow/{sessionId}/generate-more", r.workflowHandler.GenerateMoreSuggestions)
+				workflow.Post("/admin/workflow/{sessionId}/refine", r.workflowHandler.ProceedToRefinement)
+				workflow.Post("/admin/workflow/{sessionId}/scripts", r.workflowHandler.ProceedToScriptGeneration)
+				workflow.Post("/admin/workflow/{sessionId}/videos", r.workflowHandler.ProceedToVideoGeneration)
 
 				// Refined topic management
-				admin.Put("/admin/workflow/{sessionId}/topics/{topicId}", r.workflowHandler.UpdateRefinedTopic)
-				admin.Post("/admin/workflow/{sessionId}/topics/{topicId}/regenerate", r.workflowHandler.RegenerateTopic)
-				admin.Put("/admin/workflow/{sessionId}/topics/reorder", r.workflowHandler.ReorderRefinedTopics)
+				workflow.Put("/admin/workflow/{sessionId}/topics/{topicId}", r.workflowHandler.UpdateRefinedTopic)
+				workflow.Post("/admin/workflow/{sessionId}/topics/{topicId}/regenerate", r.workflowHandler.RegenerateTopic)
+				workflow.Put("/admin/workflow/{sessionId}/topics/reorder", r.workflowHandler.ReorderRefinedTopics)
 
 				// Lesson script management
-				admin.Put("/admin/workflow/{sessionId}/lessons/{lessonId}", r.workflowHandler.UpdateLessonScript)
-				admin.Post("/admin/workflow/{sessionId}/lessons/{lessonId}/regenerate", r.workflowHandler.RegenerateScript)
+				workflow.Put("/admin/workflow/{sessionId}/lessons/{lessonId}", r.workflowHandler.UpdateLessonScript)
+				workflow.Post("/admin/workflow/{sessionId}/lessons/{lessonId}/regenerate", r.workflowHandler.RegenerateScript)
 
 				// Presentation/Output type management
-				admin.Put("/admin/workflow/{sessionId}/lessons/{lessonId}/output-type", r.workflowHandler.SetOutputType)
-				admin.Post("/admin/workflow/{sessionId}/lessons/{lessonId}/presentation", r.workflowHandler.GeneratePresentation)
-				admin.Get("/admin/workflow/{sessionId}/lessons/{lessonId}/presentation", r.workflowHandler.GetPresentation)
-				admin.Post("/admin/workflow/{sessionId}/lessons/{lessonId}/regenerate-audio", r.workflowHandler.RegenerateAudio)
+				workflow.Put("/admin/workflow/{sessionId}/lessons/{lessonId}/output-type", r.workflowHandler.SetOutputType)
+				workflow.Post("/admin/workflow/{sessionId}/lessons/{lessonId}/presentation", r.workflowHandler.GeneratePresentation)
+				workflow.Get("/admin/workflow/{sessionId}/lessons/{lessonId}/presentation", r.workflowHandler.GetPresentation)
+				workflow.Post("/admin/workflow/{sessionId}/lessons/{lessonId}/regenerate-audio", r.workflowHandler.RegenerateAudio)
 
 				// Question generation
-				admin.Post("/admin/workflow/{sessionId}/questions", r.workflowHandler.ProceedToQuestionGeneration)
-				admin.Get("/admin/workflow/{sessionId}/questions/preview", r.workflowHandler.PreviewQuestions)
+				workflow.Post("/admin/workflow/{sessionId}/questions", r.workflowHandler.ProceedToQuestionGeneration)
+				workflow.Get("/admin/workflow/{sessionId}/questions/preview", r.workflowHandler.PreviewQuestions)
+			})
 
-				// Lesson management for completed courses
-				admin.Put("/admin/courses/{courseId}/lessons/{lessonId}", r.workflowHandler.UpdateLessonScriptForCourse)
-				admin.Post("/admin/courses/{courseId}/lessons/{lessonId}/regenerate", r.workflowHandler.RegenerateLessonScriptForCourse)
-				admin.Post("/admin/courses/{courseId}/lessons/{lessonId}/regenerate-presentation", r.workflowHandler.RegeneratePresentationForCourse)
+			protected.Group(func(orgs chi.Router) {
+				orgs.Use(r.authMiddleware.RequirePermission(domain.PermOrganizationsManage))
+
+				// Organization management
+				orgs.Get("/admin/organizations", r.orgHandler.List)
+				orgs.Post("/admin/organizations", r.orgHandler.Create)
+				orgs.Put("/admin/organizations/{id}", r.orgHandler.Update)
+			})
+
+			protected.Group(func(roles chi.Router) {
+				roles.Use(r.authMiddleware.RequirePermission(domain.PermRolesManage))
+
+				roles.Post("/admin/roles", r.roleHandler.Create)
+				roles.Put("/admin/roles/{name}", r.roleHandler.Update)
 			})
 		})
 	})
@@ -2,6 +2,7 @@ package domain
 
 import (
 	"encoding/json"
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
@@ -16,6 +17,20 @@ const (
 	JobTypeVideo    JobType = "video_generation"
 	JobTypeTest     JobType = "test_generation"
 
+	// Workflow steps processed by the background job queue
+	JobTypeWorkflowResearch     JobType = "workflow_research"
+	JobTypeWorkflowRefinement   JobType = "workflow_refinement"
+	JobTypeWorkflowScripts      JobType = "workflow_scripts"
+	JobTypeWorkflowVideos       JobType = "workflow_videos"
+	JobTypeWorkflowPresentation JobType = "workflow_presentation"
+	JobTypeWorkflowQuestions    JobType = "workflow_questions"
+
+	// Grade passback to LTI platforms
+	JobTypeLTIScore JobType = "lti_score"
+
+	// LLM grading of open-ended answers after an attempt is submitted
+	JobTypeGradeAnswers JobType = "grade_answers"
+
 	JobStatusPending    JobStatus = "pending"
 	JobStatusProcessing JobStatus = "processing"
 	JobStatusCompleted  JobStatus = "completed"
@@ -49,6 +64,32 @@ const (
 	StepCompleted    WorkflowStep = "completed"    // Workflow complete
 )
 
+type WorkflowEventType string
+
+const (
+	WorkflowEventStepChanged           WorkflowEventType = "step_changed"
+	WorkflowEventScriptGenerated       WorkflowEventType = "script_generated"
+	WorkflowEventPresentationStarted   WorkflowEventType = "presentation_started"
+	WorkflowEventSlideGenerated        WorkflowEventType = "slide_generated"
+	WorkflowEventPresentationCompleted WorkflowEventType = "presentation_completed"
+	WorkflowEventFailed                WorkflowEventType = "failed"
+)
+
+// WorkflowEvent reports progress of a workflow session as it happens
+type WorkflowEvent struct {
+	Type      WorkflowEventType `json:"type"`
+	SessionID uuid.UUID         `json:"sessionId"`
+	Step      WorkflowStep      `json:"step,omitempty"`
+	Status    JobStatus         `json:"status,omitempty"`
+	LessonID  *uuid.UUID        `json:"lessonId,omitempty"`
+	Title     string            `json:"title,omitempty"`
+	Current   int               `json:"current,omitempty"` // 1-based progress within the step
+	Total     int               `json:"total,omitempty"`
+	HasAudio  bool              `json:"hasAudio,omitempty"`
+	Message   string            `json:"message,omitempty"`
+	At        time.Time         `json:"at"`
+}
+
 // CourseWorkflowSession tracks the multi-agency course creation workflow
 type CourseWorkflowSession struct {
 	ID               uuid.UUID          `db:"id" json:"id"`
@@ -60,6 +101,7 @@ type CourseWorkflowSession struct {
 	CurrentStep      WorkflowStep       `db:"current_step" json:"currentStep"`
 	Status           JobStatus          `db:"status" json:"status"`
 	CourseID         *uuid.UUID         `db:"course_id" json:"courseId,omitempty"`
+	OrganizationID   uuid.UUID          `db:"organization_id" json:"organizationId"`
 	CreatedAt        time.Time          `db:"created_at" json:"createdAt"`
 	UpdatedAt        time.Time          `db:"updated_at" json:"updatedAt"`
 	Suggestions      []TopicSuggestion  `db:"-" json:"suggestions"`
@@ -142,6 +184,8 @@ type StartResearchRequest struct {
 	DifficultyLevel  string `json:"difficultyLevel,omitempty" validate:"omitempty,oneof=beginner intermediate advanced"`
 	Language         string `json:"language" validate:"required,oneof=en de fr es it pt"`
 	VideoDurationMin int    `json:"videoDurationMin,omitempty" validate:"omitempty,min=1,max=60"`
+	// OrganizationID is honoured for super admins only
+	OrganizationID *uuid.UUID `json:"organizationId,omitempty"`
 }
 
 // UpdateSuggestionRequest updates a suggestion's status
@@ -199,6 +243,13 @@ type AIGenerationJob struct {
 	CreatedAt  time.Time       `db:"created_at" json:"createdAt"`
 	UpdatedAt  time.Time       `db:"updated_at" json:"updatedAt"`
 	CompletedAt *time.Time     `db:"completed_at" json:"completedAt,omitempty"`
+
+	// Queue bookkeeping
+	Attempts    int        `db:"attempts" json:"attempts"`
+	MaxAttempts int        `db:"max_attempts" json:"maxAttempts"`
+	RunAt       time.Time  `db:"run_at" json:"runAt"`
+	LockedBy    *string    `db:"locked_by" json:"-"`
+	LockedUntil *time.Time `db:"locked_until" json:"-"`
 }
 
 type GenerateCourseRequest struct {
@@ -206,6 +257,9 @@ type GenerateCourseRequest struct {
 	TargetAudience   string `json:"targetAudience,omitempty"`
 	DifficultyLevel  string `json:"difficultyLevel,omitempty" validate:"omitempty,oneof=beginner intermediate advanced"`
 	VideoDurationMin int    `json:"videoDurationMin,omitempty" validate:"omitempty,min=1,max=60"`
+	// OrganizationID owns the generated course; honoured for super admins
+	// only, set to the caller's organization otherwise
+	OrganizationID *uuid.UUID `json:"organizationId,omitempty"`
 }
 
 type GeneratedCourseContent struct {
@@ -230,10 +284,25 @@ type SynthesiaWebhookPayload struct {
 	URL     string `json:"download,omitempty"`
 }
 
+// ErrLeaseLost means a job's lease expired and the job was handed to
+// another worker
+var ErrLeaseLost = errors.New("job lease lost")
+
 type AIGenerationJobRepository interface {
 	Create(job *AIGenerationJob) error
 	GetByID(id uuid.UUID) (*AIGenerationJob, error)
 	GetByCourseID(courseID uuid.UUID) ([]*AIGenerationJob, error)
 	Update(job *AIGenerationJob) error
 	ListPending(limit int) ([]*AIGenerationJob, error)
+
+	// Queue operations
+	Lease(workerID string, jobTypes []JobType, leaseFor time.Duration) (*AIGenerationJob, error)
+	// ExtendLease, Complete, Retry and Fail return ErrLeaseLost when the
+	// worker no longer holds the job
+	ExtendLease(id uuid.UUID, workerID string, leaseFor time.Duration) error
+	Complete(job *AIGenerationJob, workerID string) error
+	Retry(job *AIGenerationJob, workerID string, runAt time.Time, errMsg string) error
+	Fail(job *AIGenerationJob, workerID string, errMsg string) error
+	Release(id uuid.UUID, workerID string) error
+	RecoverExpired() (int64, error)
 }
@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditAction names an administrative action recorded in the audit log
+type AuditAction string
+
+const (
+	AuditCertificateRevoked AuditAction = "certificate.revoked"
+)
+
+// AuditEntry records who did what to which entity
+type AuditEntry struct {
+	ID             uuid.UUID       `db:"id" json:"id"`
+	OrganizationID uuid.UUID       `db:"organization_id" json:"organizationId"`
+	ActorID        *uuid.UUID      `db:"actor_id" json:"actorId,omitempty"` // nil for actions of the system
+	Action         AuditAction     `db:"action" json:"action"`
+	EntityType     string          `db:"entity_type" json:"entityType"`
+	EntityID       uuid.UUID       `db:"entity_id" json:"entityId"`
+	Details        json.RawMessage `db:"details" json:"details"`
+	CreatedAt      time.Time       `db:"created_at" json:"createdAt"`
+
+	// Joined fields
+	ActorEmail *string `db:"actor_email" json:"actorEmail,omitempty"`
+}
+
+// NewCertificateRevokedEntry records the revocation of a certificate
+func NewCertificateRevokedEntry(cert *Certificate) *AuditEntry {
+	details, _ := json.Marshal(map[string]interface{}{
+		"certificateNumber": cert.CertificateNumber,
+		"userId":            cert.UserID,
+		"courseId":          cert.CourseID,
+		"reason":            cert.RevocationReason,
+	})
+	return &AuditEntry{
+		ID:             uuid.New(),
+		OrganizationID: cert.OrganizationID,
+		ActorID:        cert.RevokedBy,
+		Action:         AuditCertificateRevoked,
+		EntityType:     "certificate",
+		EntityID:       cert.ID,
+		Details:        details,
+	}
+}
+
+type AuditRepository interface {
+	Create(entry *AuditEntry) error
+	List(tenant Tenant, limit, offset int) ([]*AuditEntry, error)
+	Count(tenant Tenant) (int, error)
+}
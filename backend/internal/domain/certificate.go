@@ -12,28 +12,96 @@ type Certificate struct {
 	UserID            uuid.UUID  `db:"user_id" json:"userId"`
 	CourseID          uuid.UUID  `db:"course_id" json:"courseId"`
 	TestAttemptID     uuid.UUID  `db:"test_attempt_id" json:"testAttemptId"`
-	PDFURL            *string    `db:"pdf_url" json:"pdfUrl,omitempty"`
+	PDFURL            *string    `db:"pdf_url" json:"pdfUrl,omitempty"` // Set once the PDF is kept in the storage
 	VerificationHash  string     `db:"verification_hash" json:"verificationHash"`
 	IssuedAt          time.Time  `db:"issued_at" json:"issuedAt"`
 	ExpiresAt         *time.Time `db:"expires_at" json:"expiresAt,omitempty"`
+	OrganizationID    uuid.UUID  `db:"organization_id" json:"organizationId"`
+	// A renewal points at the certificate it replaces
+	PreviousCertificateID *uuid.UUID `db:"previous_certificate_id" json:"previousCertificateId,omitempty"`
+	RenewalOpenedAt       *time.Time `db:"renewal_opened_at" json:"renewalOpenedAt,omitempty"`
+	// Signature is the signed credential (compact JWS) of the certificate
+	Signature *string `db:"signature" json:"signature,omitempty"`
+	// StatusListIndex is the certificate's bit in the revocation list
+	StatusListIndex  int        `db:"status_list_index" json:"-"`
+	RevokedAt        *time.Time `db:"revoked_at" json:"revokedAt,omitempty"`
+	RevokedBy        *uuid.UUID `db:"revoked_by" json:"revokedBy,omitempty"`
+	RevocationReason *string    `db:"revocation_reason" json:"revocationReason,omitempty"`
 
 	// Joined fields
 	UserFirstName string `db:"user_first_name" json:"userFirstName,omitempty"`
 	UserLastName  string `db:"user_last_name" json:"userLastName,omitempty"`
 	UserEmail     string `db:"user_email" json:"userEmail,omitempty"`
 	CourseTitle   string `db:"course_title" json:"courseTitle,omitempty"`
-	Score         int    `db:"score" json:"score,omitempty"`
-	MaxScore      int    `db:"max_score" json:"maxScore,omitempty"`
+	// CourseLanguage is the language the course was generated in
+	CourseLanguage string `db:"course_language" json:"-"`
+	Score          int    `db:"score" json:"score,omitempty"`
+	MaxScore       int    `db:"max_score" json:"maxScore,omitempty"`
+
+	// Branding of the issuing organization
+	OrganizationName  string  `db:"organization_name" json:"organizationName,omitempty"`
+	CertificateIssuer *string `db:"certificate_issuer" json:"-"`
+	BrandColor        *string `db:"brand_color" json:"-"`
+	CertificateFooter *string `db:"certificate_footer" json:"-"`
+}
+
+// DefaultCertificateIssuer names the issuer of certificates whose
+// organization has no branding of its own
+const DefaultCertificateIssuer = "SecuSense Training Portal"
+
+// IssuerName returns who the certificate is issued by
+func (c *Certificate) IssuerName() string {
+	if c.CertificateIssuer != nil && *c.CertificateIssuer != "" {
+		return *c.CertificateIssuer
+	}
+	return DefaultCertificateIssuer
+}
+
+// Revoked reports whether the certificate has been revoked
+func (c *Certificate) Revoked() bool {
+	return c.RevokedAt != nil
+}
+
+type RevokeCertificateRequest struct {
+	Reason string `json:"reason" validate:"required,min=3,max=500"`
+}
+
+// VerificationStatus tells why a certificate is or isn't valid
+type VerificationStatus string
+
+const (
+	VerificationValid    VerificationStatus = "valid"
+	VerificationExpired  VerificationStatus = "expired"
+	VerificationRevoked  VerificationStatus = "revoked"
+	VerificationNotFound VerificationStatus = "not_found"
+)
+
+// CertificateLink is a signed URL the certificate's PDF can be downloaded
+// from without logging in, until it expires
+type CertificateLink struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expiresAt"`
 }
 
 type CertificateVerification struct {
-	Valid            bool      `json:"valid"`
-	CertificateNumber string   `json:"certificateNumber,omitempty"`
-	HolderName       string    `json:"holderName,omitempty"`
-	CourseTitle      string    `json:"courseTitle,omitempty"`
-	IssuedAt         time.Time `json:"issuedAt,omitempty"`
-	Score            int       `json:"score,omitempty"`
-	MaxScore         int       `json:"maxScore,omitempty"`
+	Valid             bool               `json:"valid"`
+	Status            VerificationStatus `json:"status"`
+	CertificateNumber string             `json:"certificateNumber,omitempty"`
+	HolderName        string             `json:"holderName,omitempty"`
+	CourseTitle       string             `json:"courseTitle,omitempty"`
+	Issuer            string             `json:"issuer,omitempty"`
+	IssuedAt          time.Time          `json:"issuedAt,omitempty"`
+	ExpiresAt         *time.Time         `json:"expiresAt,omitempty"`
+	RevokedAt         *time.Time         `json:"revokedAt,omitempty"`
+	Score             int                `json:"score,omitempty"`
+	MaxScore          int                `json:"maxScore,omitempty"`
+}
+
+// CertificateSigningKey is an Ed25519 key certificates are signed with
+type CertificateSigningKey struct {
+	KID        string    `db:"kid"`
+	PrivateKey string    `db:"private_key"` // PEM encoded PKCS#8 key, sealed with the configured secret
+	CreatedAt  time.Time `db:"created_at"`
 }
 
 type CertificateRepository interface {
@@ -42,6 +110,34 @@ type CertificateRepository interface {
 	GetByVerificationHash(hash string) (*Certificate, error)
 	GetByUserID(userID uuid.UUID) ([]*Certificate, error)
 	GetByUserAndCourse(userID, courseID uuid.UUID) (*Certificate, error)
+	List(tenant Tenant, limit, offset int) ([]*Certificate, error)
+	Count(tenant Tenant) (int, error)
 	Update(cert *Certificate) error
+	// ListDueForRenewal returns the latest certificates of their holders
+	// that enter their course's renewal notice period by now and haven't had
+	// a renewal opened yet
+	ListDueForRenewal(now time.Time, limit int) ([]*Certificate, error)
+	MarkRenewalOpened(id uuid.UUID, at time.Time) error
+	// GetHistory returns a certificate and the ones it renewed, newest first
+	GetHistory(id uuid.UUID) ([]*Certificate, error)
+	GetByAttemptID(attemptID uuid.UUID) (*Certificate, error)
+	// Revoke stores the revocation of a certificate and its audit log
+	// entry in one transaction; it returns false when the certificate was
+	// revoked already
+	Revoke(cert *Certificate) (bool, error)
+	// ListRevokedStatusIndexes returns the revocation list bits of the
+	// revoked certificates
+	ListRevokedStatusIndexes() ([]int, error)
+	// MaxStatusListIndex returns the highest revocation list bit in use
+	MaxStatusListIndex() (int, error)
 	GenerateCertificateNumber() (string, error)
 }
+
+type CertificateKeyRepository interface {
+	GetLatest() (*CertificateSigningKey, error)
+	List() ([]*CertificateSigningKey, error)
+	// CreateFirst stores the key unless there is a key already, and reports
+	// whether it did
+	CreateFirst(key *CertificateSigningKey) (bool, error)
+	UpdatePrivateKey(kid, privateKey string) error
+}
@@ -0,0 +1,178 @@
+package domain
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CertificateLanguages are the languages certificates are printed in, those
+// courses are generated in
+var CertificateLanguages = []string{"en", "de", "fr", "es", "it", "pt"}
+
+// CertificateTextKey names a text printed on a certificate. Templates
+// override the built-in text per language; {placeholders} are filled in.
+type CertificateTextKey string
+
+const (
+	CertificateTextTitle      CertificateTextKey = "title"      // CERTIFICATE OF COMPLETION
+	CertificateTextCertify    CertificateTextKey = "certify"    // This is to certify that
+	CertificateTextCompleted  CertificateTextKey = "completed"  // has successfully completed the course
+	CertificateTextScore      CertificateTextKey = "score"      // with a score of {score} out of {maxScore}
+	CertificateTextIssued     CertificateTextKey = "issued"     // Issued on {date}
+	CertificateTextValidUntil CertificateTextKey = "validUntil" // , valid until {date}
+	CertificateTextNumber     CertificateTextKey = "number"     // Certificate Number: {number}
+	CertificateTextVerify     CertificateTextKey = "verify"     // Scan to verify or visit:
+	CertificateTextFooter     CertificateTextKey = "footer"     // Replaces the organization's footer
+)
+
+var CertificateTextKeys = []CertificateTextKey{
+	CertificateTextTitle,
+	CertificateTextCertify,
+	CertificateTextCompleted,
+	CertificateTextScore,
+	CertificateTextIssued,
+	CertificateTextValidUntil,
+	CertificateTextNumber,
+	CertificateTextVerify,
+	CertificateTextFooter,
+}
+
+const (
+	// MaxCertificateSignatures fit beside the QR code
+	MaxCertificateSignatures = 2
+	// MaxCertificateExtraFields fit on the page with the rest
+	MaxCertificateExtraFields = 3
+	// MaxCertificateImageSize bounds a logo or signature image, decoded
+	MaxCertificateImageSize = 512 << 10
+)
+
+var ErrInvalidImage = errors.New("images must be PNG or JPEG data URIs of at most 512 KB")
+
+// CertificateTemplate customizes the certificates of an organization, or of
+// one of its courses. A course's template takes precedence over its
+// organization's; without either, certificates use the built-in layout with
+// the organization's branding.
+type CertificateTemplate struct {
+	ID             uuid.UUID  `db:"id" json:"id"`
+	OrganizationID uuid.UUID  `db:"organization_id" json:"organizationId"`
+	CourseID       *uuid.UUID `db:"course_id" json:"courseId,omitempty"` // nil for the organization's template
+	Name           string     `db:"name" json:"name"`
+	PrimaryColor   *string    `db:"primary_color" json:"primaryColor,omitempty"` // #rrggbb, for the title and the learner's name
+	TextColor      *string    `db:"text_color" json:"textColor,omitempty"`       // #rrggbb
+	Logo           *string    `db:"logo" json:"logo,omitempty"`                  // Data URI of a PNG or JPEG image
+
+	Signatures     []CertificateSignature                   `db:"-" json:"signatures"`
+	SignaturesRaw  json.RawMessage                          `db:"signatures" json:"-"`
+	Texts          map[string]map[CertificateTextKey]string `db:"-" json:"texts"` // Language → text
+	TextsRaw       json.RawMessage                          `db:"texts" json:"-"`
+	ExtraFields    []CertificateExtraField                  `db:"-" json:"extraFields"`
+	ExtraFieldsRaw json.RawMessage                          `db:"extra_fields" json:"-"`
+
+	CreatedAt time.Time `db:"created_at" json:"createdAt"`
+	UpdatedAt time.Time `db:"updated_at" json:"updatedAt"`
+}
+
+// CertificateSignature is a signature printed at the bottom of certificates
+type CertificateSignature struct {
+	Name  string `json:"name" validate:"required,max=100"`
+	Title string `json:"title,omitempty" validate:"max=100"`
+	Image string `json:"image" validate:"required"` // Data URI of a PNG or JPEG image
+}
+
+// CertificateExtraField prints an extra line such as "CPE credit hours: 2"
+type CertificateExtraField struct {
+	Label map[string]string `json:"label" validate:"required"` // Language → label
+	Value string            `json:"value" validate:"required,max=100"`
+}
+
+// LabelIn returns the field's label in a language, falling back to English
+// and then to any label
+func (f CertificateExtraField) LabelIn(language string) string {
+	if label := f.Label[language]; label != "" {
+		return label
+	}
+	if label := f.Label["en"]; label != "" {
+		return label
+	}
+	for _, label := range f.Label {
+		return label
+	}
+	return ""
+}
+
+// Text returns the template's text for a language, or "" to use the
+// built-in one
+func (t *CertificateTemplate) Text(language string, key CertificateTextKey) string {
+	if t == nil {
+		return ""
+	}
+	return t.Texts[language][key]
+}
+
+type CreateCertificateTemplateRequest struct {
+	OrganizationID *uuid.UUID                               `json:"organizationId,omitempty"` // Super admins only
+	CourseID       *uuid.UUID                               `json:"courseId,omitempty"`
+	Name           string                                   `json:"name" validate:"required,min=2,max=255"`
+	PrimaryColor   *string                                  `json:"primaryColor,omitempty" validate:"omitempty,hexcolor,len=7"`
+	TextColor      *string                                  `json:"textColor,omitempty" validate:"omitempty,hexcolor,len=7"`
+	Logo           *string                                  `json:"logo,omitempty"`
+	Signatures     []CertificateSignature                   `json:"signatures,omitempty" validate:"max=2,dive"`
+	Texts          map[string]map[CertificateTextKey]string `json:"texts,omitempty"`
+	ExtraFields    []CertificateExtraField                  `json:"extraFields,omitempty" validate:"max=3,dive"`
+}
+
+// UpdateCertificateTemplateRequest changes the fields that are set; an
+// empty color or logo resets it
+type UpdateCertificateTemplateRequest struct {
+	Name         *string                                  `json:"name,omitempty" validate:"omitempty,min=2,max=255"`
+	PrimaryColor *string                                  `json:"primaryColor,omitempty" validate:"omitempty,hexcolor,len=7"`
+	TextColor    *string                                  `json:"textColor,omitempty" validate:"omitempty,hexcolor,len=7"`
+	Logo         *string                                  `json:"logo,omitempty"`
+	Signatures   []CertificateSignature                   `json:"signatures,omitempty" validate:"max=2,dive"`
+	Texts        map[string]map[CertificateTextKey]string `json:"texts,omitempty"`
+	ExtraFields  []CertificateExtraField                  `json:"extraFields,omitempty" validate:"max=3,dive"`
+}
+
+// DecodeImage decodes a base64 data URI of a PNG or JPEG image and returns
+// the image with its format, "png" or "jpg"
+func DecodeImage(dataURI string) ([]byte, string, error) {
+	header, payload, ok := strings.Cut(dataURI, ",")
+	if !ok {
+		return nil, "", ErrInvalidImage
+	}
+
+	var format string
+	switch header {
+	case "data:image/png;base64":
+		format = "png"
+	case "data:image/jpeg;base64", "data:image/jpg;base64":
+		format = "jpg"
+	default:
+		return nil, "", ErrInvalidImage
+	}
+
+	data, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil || len(data) == 0 || len(data) > MaxCertificateImageSize {
+		return nil, "", ErrInvalidImage
+	}
+	return data, format, nil
+}
+
+type CertificateTemplateRepository interface {
+	Create(tmpl *CertificateTemplate) error
+	GetByID(id uuid.UUID) (*CertificateTemplate, error)
+	// GetByScope returns the template of an organization (courseID nil) or
+	// of one of its courses
+	GetByScope(organizationID uuid.UUID, courseID *uuid.UUID) (*CertificateTemplate, error)
+	// GetForCourse returns the template a course's certificates use: its
+	// own, or its organization's
+	GetForCourse(organizationID, courseID uuid.UUID) (*CertificateTemplate, error)
+	List(tenant Tenant) ([]*CertificateTemplate, error)
+	Update(tmpl *CertificateTemplate) error
+	Delete(id uuid.UUID) error
+}
@@ -27,8 +27,27 @@ type Course struct {
 	ThumbnailURL     *string      `db:"thumbnail_url" json:"thumbnailUrl,omitempty"`
 	PassPercentage   int          `db:"pass_percentage" json:"passPercentage"`
 	IsPublished      bool         `db:"is_published" json:"isPublished"`
-	CreatedAt        time.Time    `db:"created_at" json:"createdAt"`
-	UpdatedAt        time.Time    `db:"updated_at" json:"updatedAt"`
+	OrganizationID   uuid.UUID    `db:"organization_id" json:"organizationId"`
+	// Certificates expire after CertificateValidityDays (never when nil);
+	// learners are enrolled again RenewalNoticeDays before
+	CertificateValidityDays *int      `db:"certificate_validity_days" json:"certificateValidityDays,omitempty"`
+	RenewalNoticeDays       int       `db:"renewal_notice_days" json:"renewalNoticeDays"`
+	CreatedAt               time.Time `db:"created_at" json:"createdAt"`
+	UpdatedAt               time.Time `db:"updated_at" json:"updatedAt"`
+}
+
+// DefaultRenewalNoticeDays is how long before expiry learners are enrolled
+// again to renew a certificate, unless the course says otherwise
+const DefaultRenewalNoticeDays = 30
+
+// CertificateExpiry returns when a certificate of the course issued at
+// issuedAt expires, or nil if it doesn't
+func (c *Course) CertificateExpiry(issuedAt time.Time) *time.Time {
+	if c.CertificateValidityDays == nil || *c.CertificateValidityDays <= 0 {
+		return nil
+	}
+	expiresAt := issuedAt.AddDate(0, 0, *c.CertificateValidityDays)
+	return &expiresAt
 }
 
 type CourseOutlineChapter struct {
@@ -48,9 +67,13 @@ type CourseContent struct {
 }
 
 type CreateCourseRequest struct {
-	Title          string `json:"title" validate:"required,min=1,max=255"`
-	Description    string `json:"description" validate:"required,min=1"`
-	PassPercentage int    `json:"passPercentage" validate:"required,min=0,max=100"`
+	Title                   string `json:"title" validate:"required,min=1,max=255"`
+	Description             string `json:"description" validate:"required,min=1"`
+	PassPercentage          int    `json:"passPercentage" validate:"required,min=0,max=100"`
+	CertificateValidityDays *int   `json:"certificateValidityDays,omitempty" validate:"omitempty,min=1,max=3650"`
+	RenewalNoticeDays       *int   `json:"renewalNoticeDays,omitempty" validate:"omitempty,min=1,max=365"`
+	// OrganizationID is honoured for super admins only
+	OrganizationID *uuid.UUID `json:"organizationId,omitempty"`
 }
 
 type UpdateCourseRequest struct {
@@ -60,6 +83,17 @@ type UpdateCourseRequest struct {
 	ThumbnailURL   *string `json:"thumbnailUrl,omitempty"`
 	PassPercentage *int    `json:"passPercentage,omitempty" validate:"omitempty,min=0,max=100"`
 	IsPublished    *bool   `json:"isPublished,omitempty"`
+	// CertificateValidityDays of 0 makes certificates never expire
+	CertificateValidityDays *int `json:"certificateValidityDays,omitempty" validate:"omitempty,min=0,max=3650"`
+	RenewalNoticeDays       *int `json:"renewalNoticeDays,omitempty" validate:"omitempty,min=1,max=365"`
+}
+
+// CoursePackage is a course exported as a SCORM package. Issues lists the
+// content that had to be left out.
+type CoursePackage struct {
+	Filename string
+	Data     []byte
+	Issues   []InterchangeIssue
 }
 
 type CourseRepository interface {
@@ -67,8 +101,8 @@ type CourseRepository interface {
 	GetByID(id uuid.UUID) (*Course, error)
 	Update(course *Course) error
 	Delete(id uuid.UUID) error
-	List(limit, offset int, publishedOnly bool) ([]*Course, error)
-	Count(publishedOnly bool) (int, error)
+	List(tenant Tenant, limit, offset int, publishedOnly bool) ([]*Course, error)
+	Count(tenant Tenant, publishedOnly bool) (int, error)
 	GetByVideoStatus(status VideoStatus) ([]*Course, error)
 	GetBySynthesiaVideoID(videoID string) (*Course, error)
 }
@@ -24,6 +24,10 @@ type Enrollment struct {
 	EnrolledAt         time.Time        `db:"enrolled_at" json:"enrolledAt"`
 	CompletedAt        *time.Time       `db:"completed_at" json:"completedAt,omitempty"`
 	UpdatedAt          time.Time        `db:"updated_at" json:"updatedAt"`
+	OrganizationID     uuid.UUID        `db:"organization_id" json:"organizationId"`
+	AssignmentID       *uuid.UUID       `db:"assignment_id" json:"assignmentId,omitempty"` // Set when assigned to the learner's group
+	DueAt              *time.Time       `db:"due_at" json:"dueAt,omitempty"`
+	RenewalStartedAt   *time.Time       `db:"renewal_started_at" json:"renewalStartedAt,omitempty"` // Set when enrolled again to renew a certificate
 
 	// Joined fields
 	Course *Course `db:"-" json:"course,omitempty"`
@@ -36,6 +40,14 @@ type EnrollmentWithCourse struct {
 	CourseThumbnail   *string `db:"course_thumbnail_url" json:"courseThumbnailUrl,omitempty"`
 }
 
+// EnrollmentWithLearner is an enrollment as administrators list it
+type EnrollmentWithLearner struct {
+	EnrollmentWithCourse
+	UserEmail     string `db:"user_email" json:"userEmail"`
+	UserFirstName string `db:"user_first_name" json:"userFirstName"`
+	UserLastName  string `db:"user_last_name" json:"userLastName"`
+}
+
 type UpdateProgressRequest struct {
 	ProgressPercentage int `json:"progressPercentage" validate:"required,min=0,max=100"`
 }
@@ -47,5 +59,7 @@ type EnrollmentRepository interface {
 	Update(enrollment *Enrollment) error
 	ListByUser(userID uuid.UUID) ([]*EnrollmentWithCourse, error)
 	ListByCourse(courseID uuid.UUID) ([]*Enrollment, error)
+	ListByOrganization(tenant Tenant, limit, offset int) ([]*EnrollmentWithLearner, error)
+	CountByOrganization(tenant Tenant) (int, error)
 	Delete(id uuid.UUID) error
 }
@@ -0,0 +1,135 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type GroupKind string
+
+const (
+	GroupKindGroup      GroupKind = "group"
+	GroupKindDepartment GroupKind = "department"
+)
+
+// Group collects learners of an organization, such as a team or a
+// department, so courses can be assigned to all of them
+type Group struct {
+	ID             uuid.UUID `db:"id" json:"id"`
+	OrganizationID uuid.UUID `db:"organization_id" json:"organizationId"`
+	Name           string    `db:"name" json:"name"`
+	Description    string    `db:"description" json:"description"`
+	Kind           GroupKind `db:"kind" json:"kind"`
+	MemberCount    int       `db:"member_count" json:"memberCount"`
+	CreatedAt      time.Time `db:"created_at" json:"createdAt"`
+	UpdatedAt      time.Time `db:"updated_at" json:"updatedAt"`
+}
+
+// GroupMember is a learner in a group
+type GroupMember struct {
+	UserID    uuid.UUID `db:"user_id" json:"userId"`
+	Email     string    `db:"email" json:"email"`
+	FirstName string    `db:"first_name" json:"firstName"`
+	LastName  string    `db:"last_name" json:"lastName"`
+	AddedAt   time.Time `db:"added_at" json:"addedAt"`
+}
+
+// CourseAssignment enrolls every member of a group in a course, including
+// members who join the group later
+type CourseAssignment struct {
+	ID             uuid.UUID  `db:"id" json:"id"`
+	OrganizationID uuid.UUID  `db:"organization_id" json:"organizationId"`
+	CourseID       uuid.UUID  `db:"course_id" json:"courseId"`
+	GroupID        uuid.UUID  `db:"group_id" json:"groupId"`
+	DueAt          *time.Time `db:"due_at" json:"dueAt,omitempty"`
+	AssignedBy     *uuid.UUID `db:"assigned_by" json:"assignedBy,omitempty"`
+	CreatedAt      time.Time  `db:"created_at" json:"createdAt"`
+	UpdatedAt      time.Time  `db:"updated_at" json:"updatedAt"`
+}
+
+// AssignmentProgress is an assignment with how far its learners are
+type AssignmentProgress struct {
+	CourseAssignment
+	CourseTitle string `db:"course_title" json:"courseTitle"`
+	GroupName   string `db:"group_name" json:"groupName"`
+	Enrolled    int    `db:"enrolled" json:"enrolled"`
+	Completed   int    `db:"completed" json:"completed"`
+	Overdue     int    `db:"overdue" json:"overdue"`
+}
+
+// OverdueEnrollment is an assigned course a learner hasn't completed by its
+// due date
+type OverdueEnrollment struct {
+	EnrollmentID       uuid.UUID `db:"enrollment_id" json:"enrollmentId"`
+	UserID             uuid.UUID `db:"user_id" json:"userId"`
+	UserEmail          string    `db:"user_email" json:"userEmail"`
+	UserFirstName      string    `db:"user_first_name" json:"userFirstName"`
+	UserLastName       string    `db:"user_last_name" json:"userLastName"`
+	CourseID           uuid.UUID `db:"course_id" json:"courseId"`
+	CourseTitle        string    `db:"course_title" json:"courseTitle"`
+	GroupName          string    `db:"group_name" json:"groupName"`
+	ProgressPercentage int       `db:"progress_percentage" json:"progressPercentage"`
+	DueAt              time.Time `db:"due_at" json:"dueAt"`
+}
+
+type CreateGroupRequest struct {
+	Name        string    `json:"name" validate:"required,min=1,max=255"`
+	Description string    `json:"description,omitempty"`
+	Kind        GroupKind `json:"kind,omitempty" validate:"omitempty,oneof=group department"`
+	// OrganizationID is honoured for super admins only
+	OrganizationID *uuid.UUID `json:"organizationId,omitempty"`
+}
+
+type UpdateGroupRequest struct {
+	Name        *string    `json:"name,omitempty" validate:"omitempty,min=1,max=255"`
+	Description *string    `json:"description,omitempty"`
+	Kind        *GroupKind `json:"kind,omitempty" validate:"omitempty,oneof=group department"`
+}
+
+type AddGroupMembersRequest struct {
+	UserIDs []uuid.UUID `json:"userIds" validate:"required,min=1,max=1000"`
+}
+
+// AssignCourseRequest assigns a course to one or more groups at once
+type AssignCourseRequest struct {
+	CourseID uuid.UUID   `json:"courseId" validate:"required"`
+	GroupIDs []uuid.UUID `json:"groupIds" validate:"required,min=1,max=100"`
+	DueAt    *time.Time  `json:"dueAt,omitempty"`
+}
+
+type UpdateAssignmentRequest struct {
+	// DueAt replaces the due date; null removes it
+	DueAt *time.Time `json:"dueAt"`
+}
+
+type GroupRepository interface {
+	Create(group *Group) error
+	GetByID(id uuid.UUID) (*Group, error)
+	GetByName(organizationID uuid.UUID, name string) (*Group, error)
+	List(tenant Tenant) ([]*Group, error)
+	Update(group *Group) error
+	Delete(id uuid.UUID) error
+	// AddMember reports whether the user wasn't a member yet
+	AddMember(groupID, userID uuid.UUID) (bool, error)
+	RemoveMember(groupID, userID uuid.UUID) error
+	ListMembers(groupID uuid.UUID) ([]*GroupMember, error)
+	ListMemberIDs(groupID uuid.UUID) ([]uuid.UUID, error)
+}
+
+type CourseAssignmentRepository interface {
+	Create(assignment *CourseAssignment) error
+	GetByID(id uuid.UUID) (*CourseAssignment, error)
+	GetByCourseAndGroup(courseID, groupID uuid.UUID) (*CourseAssignment, error)
+	ListByGroup(groupID uuid.UUID) ([]*CourseAssignment, error)
+	List(tenant Tenant) ([]*AssignmentProgress, error)
+	// UpdateDueAt moves the due date of the assignment and of the
+	// enrollments still due for it
+	UpdateDueAt(assignment *CourseAssignment) error
+	// Delete removes the assignment. Its enrollments move to the learner's
+	// other assignment of the course due soonest, and lose their due date
+	// when they have none.
+	Delete(id uuid.UUID) error
+	ListOverdue(tenant Tenant, limit, offset int) ([]*OverdueEnrollment, error)
+	CountOverdue(tenant Tenant) (int, error)
+}
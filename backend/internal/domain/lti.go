@@ -0,0 +1,188 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type LTIMessageType string
+
+const (
+	LTIResourceLinkRequest LTIMessageType = "LtiResourceLinkRequest"
+	LTIDeepLinkingRequest  LTIMessageType = "LtiDeepLinkingRequest"
+)
+
+// LTIPlatform is an LMS allowed to launch SecuSense as an LTI 1.3 tool
+type LTIPlatform struct {
+	ID       uuid.UUID `db:"id" json:"id"`
+	Name     string    `db:"name" json:"name"`
+	Issuer   string    `db:"issuer" json:"issuer"`
+	ClientID string    `db:"client_id" json:"clientId"`
+	// OrganizationID owns the platform's users and the courses it may launch
+	OrganizationID uuid.UUID `db:"organization_id" json:"organizationId"`
+	// DeploymentIDs limits launches to these deployments; empty allows any
+	DeploymentIDs []string  `db:"-" json:"deploymentIds"`
+	AuthLoginURL  string    `db:"auth_login_url" json:"authLoginUrl"` // OIDC authorization endpoint
+	AuthTokenURL  string    `db:"auth_token_url" json:"authTokenUrl"` // OAuth2 token endpoint for grade services
+	JWKSURL       string    `db:"jwks_url" json:"jwksUrl"`
+	CreatedAt     time.Time `db:"created_at" json:"createdAt"`
+	UpdatedAt     time.Time `db:"updated_at" json:"updatedAt"`
+}
+
+// AllowsDeployment reports whether launches from a deployment are accepted
+func (p *LTIPlatform) AllowsDeployment(deploymentID string) bool {
+	if len(p.DeploymentIDs) == 0 {
+		return true
+	}
+	for _, id := range p.DeploymentIDs {
+		if id == deploymentID {
+			return true
+		}
+	}
+	return false
+}
+
+// LTIToolKey is a key the tool signs its messages with
+type LTIToolKey struct {
+	KID        string    `db:"kid"`
+	PrivateKey string    `db:"private_key"` // PEM encoded PKCS#1 RSA key
+	CreatedAt  time.Time `db:"created_at"`
+}
+
+// LTIState ties an OIDC login to the launch that answers it
+type LTIState struct {
+	State      string    `db:"state"`
+	Nonce      string    `db:"nonce"`
+	PlatformID uuid.UUID `db:"platform_id"`
+	ExpiresAt  time.Time `db:"expires_at"`
+}
+
+type LTIUserLink struct {
+	PlatformID uuid.UUID `db:"platform_id"`
+	Subject    string    `db:"subject"`
+	UserID     uuid.UUID `db:"user_id"`
+	CreatedAt  time.Time `db:"created_at"`
+}
+
+// LTILaunch is a validated launch waiting for the browser to pick it up
+type LTILaunch struct {
+	ID                uuid.UUID      `db:"id"`
+	CodeHash          string         `db:"code_hash"`
+	CodeUsed          bool           `db:"code_used"`
+	PlatformID        uuid.UUID      `db:"platform_id"`
+	UserID            uuid.UUID      `db:"user_id"`
+	DeploymentID      string         `db:"deployment_id"`
+	MessageType       LTIMessageType `db:"message_type"`
+	CourseID          *uuid.UUID     `db:"course_id"`
+	DeepLinkReturnURL *string        `db:"deep_link_return_url"`
+	DeepLinkData      *string        `db:"deep_link_data"`
+	ExpiresAt         time.Time      `db:"expires_at"`
+	CreatedAt         time.Time      `db:"created_at"`
+}
+
+// LTIGradeLink is a platform line item that receives a learner's grade for
+// a course
+type LTIGradeLink struct {
+	ID          uuid.UUID `db:"id"`
+	PlatformID  uuid.UUID `db:"platform_id"`
+	UserID      uuid.UUID `db:"user_id"`
+	CourseID    uuid.UUID `db:"course_id"`
+	Subject     string    `db:"subject"` // The learner's user ID on the platform
+	LineItemURL string    `db:"lineitem_url"`
+	CreatedAt   time.Time `db:"created_at"`
+	UpdatedAt   time.Time `db:"updated_at"`
+}
+
+// LTILoginRequest is a platform's OIDC third-party login initiation
+type LTILoginRequest struct {
+	Issuer         string
+	LoginHint      string
+	TargetLinkURI  string
+	LTIMessageHint string
+	ClientID       string
+	DeploymentID   string
+}
+
+type CreateLTIPlatformRequest struct {
+	Name          string   `json:"name" validate:"required,min=1,max=255"`
+	Issuer        string   `json:"issuer" validate:"required,url"`
+	ClientID      string   `json:"clientId" validate:"required"`
+	DeploymentIDs []string `json:"deploymentIds"`
+	AuthLoginURL  string   `json:"authLoginUrl" validate:"required,url"`
+	AuthTokenURL  string   `json:"authTokenUrl" validate:"required,url"`
+	JWKSURL       string   `json:"jwksUrl" validate:"required,url"`
+	// OrganizationID is honoured for super admins only
+	OrganizationID *uuid.UUID `json:"organizationId,omitempty"`
+}
+
+// LTIToolConfiguration is what an administrator enters in the LMS to
+// register SecuSense
+type LTIToolConfiguration struct {
+	LoginURL       string `json:"loginUrl"`
+	LaunchURL      string `json:"launchUrl"`
+	DeepLinkingURL string `json:"deepLinkingUrl"`
+	JWKSURL        string `json:"jwksUrl"`
+}
+
+type LTISessionRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+// LTISession signs the browser in as the launching user and tells it where
+// to go next
+type LTISession struct {
+	AuthResponse
+	LaunchID    uuid.UUID      `json:"launchId"`
+	MessageType LTIMessageType `json:"messageType"`
+	CourseID    *uuid.UUID     `json:"courseId,omitempty"`
+}
+
+type LTIDeepLinkRequest struct {
+	LaunchID uuid.UUID `json:"launchId" validate:"required"`
+	CourseID uuid.UUID `json:"courseId" validate:"required"`
+}
+
+// LTIDeepLinkResponse is posted by the browser to the platform's return URL
+// as the JWT form field
+type LTIDeepLinkResponse struct {
+	ReturnURL string `json:"returnUrl"`
+	JWT       string `json:"jwt"`
+}
+
+type LTIPlatformRepository interface {
+	Create(platform *LTIPlatform) error
+	GetByID(id uuid.UUID) (*LTIPlatform, error)
+	// GetByIssuer finds a platform by issuer, and by client ID when the
+	// issuer has several registrations
+	GetByIssuer(issuer, clientID string) (*LTIPlatform, error)
+	List(tenant Tenant) ([]*LTIPlatform, error)
+	Update(platform *LTIPlatform) error
+	Delete(id uuid.UUID) error
+}
+
+type LTIRepository interface {
+	GetLatestKey() (*LTIToolKey, error)
+	ListKeys() ([]*LTIToolKey, error)
+	CreateKey(key *LTIToolKey) error
+
+	CreateState(state *LTIState) error
+	// ConsumeState removes the state and returns it if it hasn't expired
+	ConsumeState(state string) (*LTIState, error)
+
+	GetUserLink(platformID uuid.UUID, subject string) (*LTIUserLink, error)
+	CreateUserLink(link *LTIUserLink) error
+
+	CreateLaunch(launch *LTILaunch) error
+	GetLaunch(id uuid.UUID) (*LTILaunch, error)
+	// ConsumeLaunchCode marks the code used and returns its launch if it
+	// hasn't been used or expired
+	ConsumeLaunchCode(codeHash string) (*LTILaunch, error)
+
+	// UpsertGradeLink stores the link, keeping one per learner and line item
+	UpsertGradeLink(link *LTIGradeLink) error
+	ListGradeLinks(userID, courseID uuid.UUID) ([]*LTIGradeLink, error)
+
+	// DeleteExpired removes states and launches past their expiry
+	DeleteExpired() error
+}
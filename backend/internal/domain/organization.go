@@ -0,0 +1,84 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultOrganizationID is the organization that owned everything before
+// multi-tenancy, and the one anonymous visitors browse
+var DefaultOrganizationID = uuid.MustParse("00000000-0000-0000-0000-000000000001")
+
+// Organization is a client company; its users only see its own courses,
+// enrollments and certificates
+type Organization struct {
+	ID   uuid.UUID `db:"id" json:"id"`
+	Name string    `db:"name" json:"name"`
+	Slug string    `db:"slug" json:"slug"`
+	// Certificate branding; nil uses the SecuSense defaults
+	CertificateIssuer *string   `db:"certificate_issuer" json:"certificateIssuer,omitempty"`
+	BrandColor        *string   `db:"brand_color" json:"brandColor,omitempty"` // #rrggbb
+	CertificateFooter *string   `db:"certificate_footer" json:"certificateFooter,omitempty"`
+	CreatedAt         time.Time `db:"created_at" json:"createdAt"`
+	UpdatedAt         time.Time `db:"updated_at" json:"updatedAt"`
+}
+
+// Tenant scopes queries to the data of one organization. Super admins get a
+// cross-tenant scope that sees every organization. The zero Tenant sees
+// nothing.
+type Tenant struct {
+	OrganizationID uuid.UUID
+	CrossTenant    bool
+}
+
+func TenantOf(organizationID uuid.UUID) Tenant {
+	return Tenant{OrganizationID: organizationID}
+}
+
+// Allows reports whether the tenant may see data of an organization
+func (t Tenant) Allows(organizationID uuid.UUID) bool {
+	return t.CrossTenant || (t.OrganizationID != uuid.Nil && t.OrganizationID == organizationID)
+}
+
+// Owner picks the organization new data belongs to: super admins may name
+// one, everyone else creates data in their own
+func (t Tenant) Owner(requested *uuid.UUID) uuid.UUID {
+	if t.CrossTenant && requested != nil && *requested != uuid.Nil {
+		return *requested
+	}
+	return t.OrganizationID
+}
+
+type CreateOrganizationRequest struct {
+	Name              string  `json:"name" validate:"required,min=1,max=255"`
+	Slug              string  `json:"slug" validate:"required,min=2,max=100"`
+	CertificateIssuer *string `json:"certificateIssuer,omitempty" validate:"omitempty,max=255"`
+	BrandColor        *string `json:"brandColor,omitempty" validate:"omitempty,hexcolor,len=7"`
+	CertificateFooter *string `json:"certificateFooter,omitempty" validate:"omitempty,max=500"`
+}
+
+type UpdateOrganizationRequest struct {
+	Name              *string `json:"name,omitempty" validate:"omitempty,min=1,max=255"`
+	CertificateIssuer *string `json:"certificateIssuer,omitempty" validate:"omitempty,max=255"`
+	BrandColor        *string `json:"brandColor,omitempty" validate:"omitempty,hexcolor,len=7"`
+	CertificateFooter *string `json:"certificateFooter,omitempty" validate:"omitempty,max=500"`
+}
+
+// CreateMemberRequest adds a user to an organization. Any role but
+// super_admin can be given this way.
+type CreateMemberRequest struct {
+	Email     string   `json:"email" validate:"required,email"`
+	Password  string   `json:"password" validate:"required,min=8"`
+	FirstName string   `json:"firstName" validate:"required,min=1,max=100"`
+	LastName  string   `json:"lastName" validate:"required,min=1,max=100"`
+	Role      UserRole `json:"role" validate:"required,max=50"`
+}
+
+type OrganizationRepository interface {
+	Create(org *Organization) error
+	GetByID(id uuid.UUID) (*Organization, error)
+	GetBySlug(slug string) (*Organization, error)
+	List() ([]*Organization, error)
+	Update(org *Organization) error
+}
@@ -0,0 +1,104 @@
+package domain
+
+import (
+	"slices"
+	"time"
+)
+
+// Permission allows a part of the admin API
+type Permission string
+
+const (
+	// PermCourseWrite creates, edits, publishes, deletes and exports courses
+	PermCourseWrite Permission = "course:write"
+	// PermTestWrite edits tests and questions and grants extra attempts
+	PermTestWrite Permission = "test:write"
+	// PermReviewGrade grades answers in the review queue
+	PermReviewGrade Permission = "review:grade"
+	// PermWorkflowRun runs AI course generation and the course workflow
+	PermWorkflowRun Permission = "workflow:run"
+	// PermReportsRead reads enrollments, certificates and item analysis
+	PermReportsRead Permission = "reports:read"
+	// PermUsersManage adds members to an organization, assigns roles and
+	// manages groups
+	PermUsersManage Permission = "users:manage"
+	// PermAssignmentsManage assigns courses to groups with due dates
+	PermAssignmentsManage Permission = "assignments:manage"
+	// PermCertificatesRevoke revokes certificates
+	PermCertificatesRevoke Permission = "certificates:revoke"
+	// PermLTIManage registers the LMSs that may launch courses
+	PermLTIManage Permission = "lti:manage"
+	// PermOrganizationsManage creates and edits organizations
+	PermOrganizationsManage Permission = "organizations:manage"
+	// PermRolesManage defines roles and their permissions
+	PermRolesManage Permission = "roles:manage"
+)
+
+// Permissions lists every permission a role can be granted
+var Permissions = []Permission{
+	PermCourseWrite,
+	PermTestWrite,
+	PermReviewGrade,
+	PermWorkflowRun,
+	PermReportsRead,
+	PermUsersManage,
+	PermAssignmentsManage,
+	PermCertificatesRevoke,
+	PermLTIManage,
+	PermOrganizationsManage,
+	PermRolesManage,
+}
+
+func (p Permission) Valid() bool {
+	for _, known := range Permissions {
+		if p == known {
+			return true
+		}
+	}
+	return false
+}
+
+// HoldsAll reports whether every wanted permission is among the held ones
+func HoldsAll(held, wanted []Permission) bool {
+	for _, w := range wanted {
+		if !slices.Contains(held, w) {
+			return false
+		}
+	}
+	return true
+}
+
+// Role is a named set of permissions users are assigned
+type Role struct {
+	Name        UserRole     `db:"name" json:"name"`
+	Description string       `db:"description" json:"description"`
+	BuiltIn     bool         `db:"built_in" json:"builtIn"`
+	Permissions []Permission `db:"-" json:"permissions"`
+	CreatedAt   time.Time    `db:"created_at" json:"createdAt"`
+	UpdatedAt   time.Time    `db:"updated_at" json:"updatedAt"`
+}
+
+type CreateRoleRequest struct {
+	Name        UserRole     `json:"name" validate:"required,min=2,max=50"`
+	Description string       `json:"description,omitempty" validate:"max=255"`
+	Permissions []Permission `json:"permissions" validate:"required"`
+}
+
+type UpdateRoleRequest struct {
+	Description *string      `json:"description,omitempty" validate:"omitempty,max=255"`
+	Permissions []Permission `json:"permissions,omitempty"`
+}
+
+type AssignRoleRequest struct {
+	Role UserRole `json:"role" validate:"required,max=50"`
+}
+
+type RoleRepository interface {
+	Create(role *Role) error
+	GetByName(name UserRole) (*Role, error)
+	List() ([]*Role, error)
+	Update(role *Role) error
+	// GetPermissions returns the permissions of a role, none for an
+	// unknown role
+	GetPermissions(name UserRole) ([]Permission, error)
+}
@@ -15,6 +15,33 @@ const (
 	QuestionTypeFillBlank      QuestionType = "fill_blank"
 	QuestionTypeMatching       QuestionType = "matching"
 	QuestionTypeOrdering       QuestionType = "ordering"
+	QuestionTypeTrueFalse      QuestionType = "true_false"
+	QuestionTypeNumeric        QuestionType = "numeric"
+	QuestionTypeHotspot        QuestionType = "hotspot"
+	QuestionTypeShortText      QuestionType = "short_text"
+	QuestionTypeOpenEnded      QuestionType = "open_ended"
+)
+
+// StratifyBy controls how a question draw is spread across the pool
+type StratifyBy string
+
+const (
+	StratifyNone         StratifyBy = "none"
+	StratifyQuestionType StratifyBy = "question_type"
+	// StratifyTag groups questions by their first tag; untagged questions
+	// form a group of their own
+	StratifyTag StratifyBy = "tag"
+)
+
+// ScoringPolicy turns a partly right answer into points
+type ScoringPolicy string
+
+const (
+	ScoringAllOrNothing ScoringPolicy = "all_or_nothing"
+	ScoringProportional ScoringPolicy = "proportional"
+	// ScoringPenalty deducts for wrong parts, e.g. wrong selections in a
+	// multi-select multiple choice question
+	ScoringPenalty ScoringPolicy = "penalty"
 )
 
 type Test struct {
@@ -24,13 +51,32 @@ type Test struct {
 	Description      string    `db:"description" json:"description"`
 	TimeLimitMinutes *int      `db:"time_limit_minutes" json:"timeLimitMinutes,omitempty"`
 	PassingScore     int       `db:"passing_score" json:"passingScore"`
-	CreatedAt        time.Time `db:"created_at" json:"createdAt"`
-	UpdatedAt        time.Time `db:"updated_at" json:"updatedAt"`
+	// QuestionCount is how many questions each attempt draws from the pool;
+	// nil means every attempt gets all questions
+	QuestionCount    *int       `db:"question_count" json:"questionCount,omitempty"`
+	StratifyBy       StratifyBy `db:"stratify_by" json:"stratifyBy"`
+	ShuffleQuestions bool       `db:"shuffle_questions" json:"shuffleQuestions"`
+	ShuffleOptions   bool       `db:"shuffle_options" json:"shuffleOptions"`
+	// Retake policy: nil limits and a zero cooldown mean unrestricted
+	MaxAttempts          *int `db:"max_attempts" json:"maxAttempts,omitempty"`
+	CooldownMinutes      int  `db:"cooldown_minutes" json:"cooldownMinutes"`
+	LockoutAfterFailures *int `db:"lockout_after_failures" json:"lockoutAfterFailures,omitempty"`
+	// ScoringPolicy applies to questions without their own; nil uses each
+	// question type's default
+	ScoringPolicy *ScoringPolicy `db:"scoring_policy" json:"scoringPolicy,omitempty"`
+	AllowNegative bool           `db:"allow_negative" json:"allowNegative"` // Let penalties take a question below zero
+	CreatedAt     time.Time      `db:"created_at" json:"createdAt"`
+	UpdatedAt     time.Time      `db:"updated_at" json:"updatedAt"`
 
 	// Joined fields
 	Questions []*Question `db:"-" json:"questions,omitempty"`
 }
 
+// DrawsFromPool reports whether attempts see only a subset of poolSize questions
+func (t *Test) DrawsFromPool(poolSize int) bool {
+	return t.QuestionCount != nil && *t.QuestionCount < poolSize
+}
+
 type Question struct {
 	ID           uuid.UUID       `db:"id" json:"id"`
 	TestID       uuid.UUID       `db:"test_id" json:"testId"`
@@ -39,7 +85,11 @@ type Question struct {
 	QuestionData json.RawMessage `db:"question_data" json:"questionData"`
 	Points       int             `db:"points" json:"points"`
 	OrderIndex   int             `db:"order_index" json:"orderIndex"`
-	CreatedAt    time.Time       `db:"created_at" json:"createdAt"`
+	Tags         json.RawMessage `db:"tags" json:"tags"`
+	// Overrides of the test's scoring settings
+	ScoringPolicy *ScoringPolicy `db:"scoring_policy" json:"scoringPolicy,omitempty"`
+	AllowNegative *bool          `db:"allow_negative" json:"allowNegative,omitempty"`
+	CreatedAt     time.Time      `db:"created_at" json:"createdAt"`
 }
 
 // Multiple Choice question data structure
@@ -59,9 +109,17 @@ type DragDropData struct {
 
 // Fill in the Blank question data structure
 type FillBlankData struct {
-	Template    string   `json:"template"` // Use {{blank}} for placeholders
-	Blanks      []string `json:"blanks"`   // Correct answers for each blank
-	Explanation string   `json:"explanation,omitempty"`
+	Template string   `json:"template"` // Use {{blank}} for placeholders
+	Blanks   []string `json:"blanks"`   // Correct answers for each blank
+	// Optional, indexed like Blanks
+	Alternatives [][]string `json:"alternatives,omitempty"` // Further accepted answers
+	Patterns     []string   `json:"patterns,omitempty"`     // Regular expression a whole answer may match instead
+	// Answers are compared ignoring case, accents and extra whitespace unless
+	// CaseSensitive is set (accents and whitespace are still normalized).
+	// MaxEdits forgives typos up to that Levenshtein distance.
+	CaseSensitive bool   `json:"caseSensitive,omitempty"`
+	MaxEdits      int    `json:"maxEdits,omitempty"`
+	Explanation   string `json:"explanation,omitempty"`
 }
 
 // Matching question data structure
@@ -79,19 +137,133 @@ type OrderingData struct {
 	Explanation  string   `json:"explanation,omitempty"`
 }
 
+// True/False question data structure; the question text is the statement
+type TrueFalseData struct {
+	CorrectAnswer bool   `json:"correctAnswer"`
+	Explanation   string `json:"explanation,omitempty"`
+}
+
+// Numeric question data structure. Answers within Tolerance of the correct
+// value (inclusive) are accepted.
+type NumericData struct {
+	CorrectValue float64 `json:"correctValue"`
+	Tolerance    float64 `json:"tolerance,omitempty"`
+	Unit         string  `json:"unit,omitempty"` // Shown next to the input, e.g. "days"
+	Explanation  string  `json:"explanation,omitempty"`
+}
+
+// Hotspot question data structure: the learner clicks the regions to find on
+// an image. Coordinates are fractions (0-1) of the image's width and height,
+// so they hold at any display size.
+type HotspotData struct {
+	ImageURL    string          `json:"imageUrl"`
+	Regions     []HotspotRegion `json:"regions"` // Each region is one thing to find
+	Explanation string          `json:"explanation,omitempty"`
+}
+
+type HotspotShape string
+
+const (
+	HotspotRect   HotspotShape = "rect"
+	HotspotCircle HotspotShape = "circle"
+)
+
+type HotspotRegion struct {
+	Label  string       `json:"label,omitempty"`
+	Shape  HotspotShape `json:"shape"`
+	X      float64      `json:"x"` // Left edge of a rect, centre of a circle
+	Y      float64      `json:"y"` // Top edge of a rect, centre of a circle
+	Width  float64      `json:"width,omitempty"`
+	Height float64      `json:"height,omitempty"`
+	Radius float64      `json:"radius,omitempty"` // Same units, so the circle stretches with the image
+}
+
+// HotspotClick is one click of a hotspot answer, in the same coordinates as
+// the regions
+type HotspotClick struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// Short free-text question data structure, graded by keyword rubric: each
+// rubric item is a concept the answer should mention, in any of its terms.
+type ShortTextData struct {
+	Rubric       []RubricItem `json:"rubric"`
+	MaxLength    int          `json:"maxLength,omitempty"`
+	SampleAnswer string       `json:"sampleAnswer,omitempty"`
+	Explanation  string       `json:"explanation,omitempty"`
+}
+
+type RubricItem struct {
+	Concept string   `json:"concept,omitempty"`
+	Terms   []string `json:"terms"`
+}
+
+// Open-ended question data structure, graded by the configured LLM against
+// the rubric's criteria
+type OpenEndedData struct {
+	Rubric      []string `json:"rubric"`
+	ModelAnswer string   `json:"modelAnswer,omitempty"` // Helps the grader calibrate
+	MaxLength   int      `json:"maxLength,omitempty"`
+	Explanation string   `json:"explanation,omitempty"`
+}
+
+// OpenAnswerGradingRequest is what the LLM grader is shown of an answer
+type OpenAnswerGradingRequest struct {
+	QuestionText string
+	Rubric       []string
+	ModelAnswer  string
+	Answer       string
+}
+
+// OpenAnswerGrade is the LLM's judgement of an open-ended answer
+type OpenAnswerGrade struct {
+	Score      int     `json:"score"` // 0-100
+	Rationale  string  `json:"rationale"`
+	Confidence float64 `json:"confidence"` // 0-1
+}
+
 type TestAttempt struct {
-	ID          uuid.UUID  `db:"id" json:"id"`
-	UserID      uuid.UUID  `db:"user_id" json:"userId"`
-	TestID      uuid.UUID  `db:"test_id" json:"testId"`
-	StartedAt   time.Time  `db:"started_at" json:"startedAt"`
-	CompletedAt *time.Time `db:"completed_at" json:"completedAt,omitempty"`
-	Score       *int       `db:"score" json:"score,omitempty"`
-	MaxScore    *int       `db:"max_score" json:"maxScore,omitempty"`
-	Percentage  *float64   `db:"percentage" json:"percentage,omitempty"`
-	Passed      *bool      `db:"passed" json:"passed,omitempty"`
+	ID            uuid.UUID  `db:"id" json:"id"`
+	UserID        uuid.UUID  `db:"user_id" json:"userId"`
+	TestID        uuid.UUID  `db:"test_id" json:"testId"`
+	StartedAt     time.Time  `db:"started_at" json:"startedAt"`
+	CompletedAt   *time.Time `db:"completed_at" json:"completedAt,omitempty"`
+	Score         *int       `db:"score" json:"score,omitempty"`
+	MaxScore      *int       `db:"max_score" json:"maxScore,omitempty"`
+	Percentage    *float64   `db:"percentage" json:"percentage,omitempty"`
+	Passed        *bool      `db:"passed" json:"passed,omitempty"`
+	DeadlineAt    *time.Time `db:"deadline_at" json:"deadlineAt,omitempty"` // nil for untimed tests
+	AutoSubmitted bool       `db:"auto_submitted" json:"autoSubmitted"`     // graded because time ran out
+	// QuestionSet holds the []AttemptQuestion drawn when the attempt started.
+	// Attempts created before question pools existed have an empty set.
+	QuestionSet json.RawMessage `db:"question_set" json:"-"`
 
 	// Joined fields
-	Answers []*UserAnswer `db:"-" json:"answers,omitempty"`
+	Answers          []*UserAnswer `db:"-" json:"answers,omitempty"`
+	Questions        []*Question   `db:"-" json:"questions,omitempty"`
+	RemainingSeconds *int          `db:"-" json:"remainingSeconds,omitempty"`
+}
+
+// Remaining returns the time left before the deadline, or nil for untimed
+// attempts. It never goes below zero.
+func (a *TestAttempt) Remaining(now time.Time) *int {
+	if a.DeadlineAt == nil {
+		return nil
+	}
+	seconds := int(a.DeadlineAt.Sub(now).Seconds())
+	if seconds < 0 {
+		seconds = 0
+	}
+	return &seconds
+}
+
+// AttemptQuestion is one question as it was presented in an attempt.
+// OptionOrder[i] is the original index of the option (multiple choice) or
+// item (ordering) shown at position i; it is empty when nothing was shuffled.
+type AttemptQuestion struct {
+	QuestionID  uuid.UUID `json:"questionId"`
+	OptionOrder []int     `json:"optionOrder,omitempty"`
 }
 
 type UserAnswer struct {
@@ -101,16 +273,21 @@ type UserAnswer struct {
 	AnswerData json.RawMessage `db:"answer_data" json:"answerData"`
 	IsCorrect  bool            `db:"is_correct" json:"isCorrect"`
 	PointsAwarded int          `db:"points_awarded" json:"pointsAwarded"`
+	// TimeSpentSeconds is reported by the client and capped at the attempt's duration
+	TimeSpentSeconds *int      `db:"time_spent_seconds" json:"timeSpentSeconds,omitempty"`
 	CreatedAt  time.Time       `db:"created_at" json:"createdAt"`
 }
 
 type SubmitAnswerRequest struct {
-	QuestionID uuid.UUID       `json:"questionId" validate:"required"`
-	AnswerData json.RawMessage `json:"answerData" validate:"required"`
+	QuestionID       uuid.UUID       `json:"questionId" validate:"required"`
+	AnswerData       json.RawMessage `json:"answerData" validate:"required"`
+	TimeSpentSeconds *int            `json:"timeSpentSeconds,omitempty" validate:"omitempty,min=0"`
 }
 
+// SubmitTestRequest carries the final answers. They are merged with the
+// answers saved during the attempt, replacing saved ones for the same question.
 type SubmitTestRequest struct {
-	Answers []SubmitAnswerRequest `json:"answers" validate:"required,dive"`
+	Answers []SubmitAnswerRequest `json:"answers" validate:"dive"`
 }
 
 type TestResult struct {
@@ -128,30 +305,62 @@ type AnswerResult struct {
 	PointsAwarded int             `json:"pointsAwarded"`
 	MaxPoints     int             `json:"maxPoints"`
 	Explanation   string          `json:"explanation,omitempty"`
+	UnderReview   bool            `json:"underReview,omitempty"` // Awaiting the LLM or a human grader
 }
 
 type CreateTestRequest struct {
-	CourseID         uuid.UUID `json:"courseId" validate:"required"`
-	Title            string    `json:"title" validate:"required,min=1,max=255"`
-	Description      string    `json:"description"`
-	TimeLimitMinutes *int      `json:"timeLimitMinutes" validate:"omitempty,min=1"`
-	PassingScore     int       `json:"passingScore" validate:"required,min=0,max=100"`
+	CourseID             uuid.UUID      `json:"courseId" validate:"required"`
+	Title                string         `json:"title" validate:"required,min=1,max=255"`
+	Description          string         `json:"description"`
+	TimeLimitMinutes     *int           `json:"timeLimitMinutes" validate:"omitempty,min=1"`
+	PassingScore         int            `json:"passingScore" validate:"required,min=0,max=100"`
+	QuestionCount        *int           `json:"questionCount" validate:"omitempty,min=1"`
+	StratifyBy           StratifyBy     `json:"stratifyBy" validate:"omitempty,oneof=none question_type tag"`
+	ShuffleQuestions     bool           `json:"shuffleQuestions"`
+	ShuffleOptions       bool           `json:"shuffleOptions"`
+	MaxAttempts          *int           `json:"maxAttempts" validate:"omitempty,min=1"`
+	CooldownMinutes      int            `json:"cooldownMinutes" validate:"min=0"`
+	LockoutAfterFailures *int           `json:"lockoutAfterFailures" validate:"omitempty,min=1"`
+	ScoringPolicy        *ScoringPolicy `json:"scoringPolicy" validate:"omitempty,oneof=all_or_nothing proportional penalty"`
+	AllowNegative        bool           `json:"allowNegative"`
+}
+
+type UpdateTestRequest struct {
+	Title                string         `json:"title" validate:"required,min=1,max=255"`
+	Description          string         `json:"description"`
+	TimeLimitMinutes     *int           `json:"timeLimitMinutes" validate:"omitempty,min=1"`
+	PassingScore         int            `json:"passingScore" validate:"required,min=0,max=100"`
+	QuestionCount        *int           `json:"questionCount" validate:"omitempty,min=1"`
+	StratifyBy           StratifyBy     `json:"stratifyBy" validate:"omitempty,oneof=none question_type tag"`
+	ShuffleQuestions     bool           `json:"shuffleQuestions"`
+	ShuffleOptions       bool           `json:"shuffleOptions"`
+	MaxAttempts          *int           `json:"maxAttempts" validate:"omitempty,min=1"`
+	CooldownMinutes      int            `json:"cooldownMinutes" validate:"min=0"`
+	LockoutAfterFailures *int           `json:"lockoutAfterFailures" validate:"omitempty,min=1"`
+	ScoringPolicy        *ScoringPolicy `json:"scoringPolicy" validate:"omitempty,oneof=all_or_nothing proportional penalty"`
+	AllowNegative        bool           `json:"allowNegative"`
 }
 
 type CreateQuestionRequest struct {
-	TestID       uuid.UUID       `json:"testId" validate:"required"`
-	QuestionType QuestionType    `json:"questionType" validate:"required"`
-	QuestionText string          `json:"questionText" validate:"required,min=1"`
-	QuestionData json.RawMessage `json:"questionData" validate:"required"`
-	Points       int             `json:"points" validate:"required,min=1"`
-	OrderIndex   int             `json:"orderIndex"`
+	TestID        uuid.UUID       `json:"testId" validate:"required"`
+	QuestionType  QuestionType    `json:"questionType" validate:"required"`
+	QuestionText  string          `json:"questionText" validate:"required,min=1"`
+	QuestionData  json.RawMessage `json:"questionData" validate:"required"`
+	Points        int             `json:"points" validate:"required,min=1"`
+	OrderIndex    int             `json:"orderIndex"`
+	Tags          []string        `json:"tags" validate:"omitempty,dive,min=1,max=50"`
+	ScoringPolicy *ScoringPolicy  `json:"scoringPolicy" validate:"omitempty,oneof=all_or_nothing proportional penalty"`
+	AllowNegative *bool           `json:"allowNegative"`
 }
 
 type UpdateQuestionRequest struct {
-	QuestionType QuestionType    `json:"questionType" validate:"required"`
-	QuestionText string          `json:"questionText" validate:"required,min=1"`
-	QuestionData json.RawMessage `json:"questionData" validate:"required"`
-	Points       int             `json:"points" validate:"required,min=1"`
+	QuestionType  QuestionType    `json:"questionType" validate:"required"`
+	QuestionText  string          `json:"questionText" validate:"required,min=1"`
+	QuestionData  json.RawMessage `json:"questionData" validate:"required"`
+	Points        int             `json:"points" validate:"required,min=1"`
+	Tags          []string        `json:"tags" validate:"omitempty,dive,min=1,max=50"`
+	ScoringPolicy *ScoringPolicy  `json:"scoringPolicy" validate:"omitempty,oneof=all_or_nothing proportional penalty"`
+	AllowNegative *bool           `json:"allowNegative"`
 }
 
 type ReorderQuestionsRequest struct {
@@ -163,6 +372,150 @@ type QuestionOrder struct {
 	OrderIndex int    `json:"orderIndex" validate:"min=0"`
 }
 
+// TestAttemptGrant gives a learner attempts beyond the test's retake policy
+type TestAttemptGrant struct {
+	ID            uuid.UUID  `db:"id" json:"id"`
+	TestID        uuid.UUID  `db:"test_id" json:"testId"`
+	UserID        uuid.UUID  `db:"user_id" json:"userId"`
+	ExtraAttempts int        `db:"extra_attempts" json:"extraAttempts"`
+	Reason        *string    `db:"reason" json:"reason,omitempty"`
+	GrantedBy     *uuid.UUID `db:"granted_by" json:"grantedBy,omitempty"`
+	CreatedAt     time.Time  `db:"created_at" json:"createdAt"`
+}
+
+type GrantAttemptsRequest struct {
+	UserID        uuid.UUID `json:"userId" validate:"required"`
+	ExtraAttempts int       `json:"extraAttempts" validate:"required,min=1,max=100"`
+	Reason        string    `json:"reason" validate:"max=500"`
+}
+
+type ReviewStatus string
+
+const (
+	ReviewStatusGrading    ReviewStatus = "grading" // Waiting for the LLM's grade
+	ReviewStatusPending    ReviewStatus = "pending"
+	ReviewStatusAccepted   ReviewStatus = "accepted" // Confident grade, kept without review
+	ReviewStatusConfirmed  ReviewStatus = "confirmed"
+	ReviewStatusOverridden ReviewStatus = "overridden"
+)
+
+// AnswerReview records an LLM grade of an answer and, once a human has looked
+// at it, their decision
+type AnswerReview struct {
+	ID              uuid.UUID    `db:"id" json:"id"`
+	UserAnswerID    uuid.UUID    `db:"user_answer_id" json:"userAnswerId"`
+	AttemptID       uuid.UUID    `db:"attempt_id" json:"attemptId"`
+	QuestionID      uuid.UUID    `db:"question_id" json:"questionId"`
+	Status          ReviewStatus `db:"status" json:"status"`
+	SuggestedPoints int          `db:"suggested_points" json:"suggestedPoints"`
+	Rationale       string       `db:"rationale" json:"rationale"`
+	Confidence      float64      `db:"confidence" json:"confidence"`
+	FinalPoints     *int         `db:"final_points" json:"finalPoints,omitempty"`
+	ReviewNote      *string      `db:"review_note" json:"reviewNote,omitempty"`
+	ReviewedBy      *uuid.UUID   `db:"reviewed_by" json:"reviewedBy,omitempty"`
+	ReviewedAt      *time.Time   `db:"reviewed_at" json:"reviewedAt,omitempty"`
+	CreatedAt       time.Time    `db:"created_at" json:"createdAt"`
+
+	// Joined fields
+	QuestionText string          `db:"question_text" json:"questionText,omitempty"`
+	MaxPoints    int             `db:"max_points" json:"maxPoints,omitempty"`
+	AnswerData   json.RawMessage `db:"answer_data" json:"answerData,omitempty"`
+	UserEmail    string          `db:"user_email" json:"userEmail,omitempty"`
+}
+
+// AwaitingGrade reports whether the answer's final grade is still open
+func (r *AnswerReview) AwaitingGrade() bool {
+	return r.Status == ReviewStatusGrading || r.Status == ReviewStatusPending
+}
+
+type ResolveReviewRequest struct {
+	PointsAwarded int    `json:"pointsAwarded" validate:"min=0"`
+	Note          string `json:"note" validate:"max=1000"`
+}
+
+// ItemAnalysis summarizes how the questions of a test performed across its
+// completed attempts
+type ItemAnalysis struct {
+	TestID    uuid.UUID        `json:"testId"`
+	Attempts  int              `json:"attempts"`
+	Questions []*QuestionStats `json:"questions"`
+}
+
+// Item analysis flags
+const (
+	FlagTooEasy                = "too_easy"
+	FlagTooHard                = "too_hard"
+	FlagLowDiscrimination      = "low_discrimination"
+	FlagNegativeDiscrimination = "negative_discrimination"
+	FlagUnusedDistractor       = "unused_distractor"
+	FlagMisleadingDistractor   = "misleading_distractor"
+)
+
+// QuestionStats are the classical item statistics of a question. Responses
+// counts the attempts the question was presented in, answered or not.
+// Difficulty is the share of responses that were fully correct (the p-value)
+// and Discrimination the point-biserial correlation between correctness and
+// the score on the rest of the test. Statistics that can't be computed from
+// the responses are nil.
+type QuestionStats struct {
+	QuestionID     uuid.UUID      `json:"questionId"`
+	QuestionText   string         `json:"questionText"`
+	QuestionType   QuestionType   `json:"questionType"`
+	Responses      int            `json:"responses"`
+	Answered       int            `json:"answered"`
+	Difficulty     *float64       `json:"difficulty"`
+	MeanScore      *float64       `json:"meanScore"`
+	Discrimination *float64       `json:"discrimination"`
+	AvgTimeSeconds *float64       `json:"avgTimeSeconds"`
+	Options        []*OptionStats `json:"options,omitempty"`
+	Flags          []string       `json:"flags"`
+}
+
+// OptionStats is how often a multiple choice option was selected, as a share
+// of the answered responses
+type OptionStats struct {
+	Index    int     `json:"index"`
+	Option   string  `json:"option"`
+	Correct  bool    `json:"correct"`
+	Selected int     `json:"selected"`
+	Rate     float64 `json:"rate"`
+}
+
+// InterchangeFormat is a question bank file format other tools read and write
+type InterchangeFormat string
+
+const (
+	FormatQTI  InterchangeFormat = "qti"  // IMS QTI 2.1 content package
+	FormatGIFT InterchangeFormat = "gift" // Moodle GIFT text
+)
+
+// InterchangeIssue is a question that did not map cleanly between a file
+// format and our question types. Skipped questions were left out entirely;
+// the others were carried over with the loss described in Reason.
+type InterchangeIssue struct {
+	Item    string `json:"item"` // Question ID, or the item's identifier or position in the file
+	Title   string `json:"title,omitempty"`
+	Reason  string `json:"reason"`
+	Skipped bool   `json:"skipped"`
+}
+
+// QuestionExport is a test's questions written in an interchange format
+type QuestionExport struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+	Issues      []InterchangeIssue
+}
+
+// ImportReport lists the questions created from an imported file and the
+// items that could not be mapped. A dry run only validates the file.
+type ImportReport struct {
+	Format   InterchangeFormat  `json:"format"`
+	DryRun   bool               `json:"dryRun"`
+	Imported []*Question        `json:"imported"`
+	Issues   []InterchangeIssue `json:"issues"`
+}
+
 type TestRepository interface {
 	Create(test *Test) error
 	GetByID(id uuid.UUID) (*Test, error)
@@ -185,11 +538,43 @@ type TestAttemptRepository interface {
 	GetByID(id uuid.UUID) (*TestAttempt, error)
 	GetByUserAndTest(userID, testID uuid.UUID) ([]*TestAttempt, error)
 	Update(attempt *TestAttempt) error
+	// Complete stores the result of an open attempt and swaps its saved
+	// answers for the graded ones, in one transaction. It reports false when
+	// the attempt had already been completed, e.g. by a concurrent submit.
+	Complete(attempt *TestAttempt, answers []*UserAnswer) (bool, error)
 	GetLatestByUserAndTest(userID, testID uuid.UUID) (*TestAttempt, error)
+	// ListExpired returns open attempts whose deadline passed before the given time
+	ListExpired(before time.Time, limit int) ([]*TestAttempt, error)
+	// ListCompletedByTest returns every completed attempt of a test
+	ListCompletedByTest(testID uuid.UUID) ([]*TestAttempt, error)
+}
+
+type TestAttemptGrantRepository interface {
+	Create(grant *TestAttemptGrant) error
+	// SumExtraAttempts returns the total extra attempts granted to a user for a test
+	SumExtraAttempts(userID, testID uuid.UUID) (int, error)
 }
 
 type UserAnswerRepository interface {
 	Create(answer *UserAnswer) error
 	CreateBatch(answers []*UserAnswer) error
+	// Upsert stores the answer for its question, replacing an earlier one
+	Upsert(answer *UserAnswer) error
 	GetByAttemptID(attemptID uuid.UUID) ([]*UserAnswer, error)
+	// UpdateGrade stores a changed grade of an answer
+	UpdateGrade(answer *UserAnswer) error
+	// GetByTestID returns the answers of every completed attempt of a test
+	GetByTestID(testID uuid.UUID) ([]*UserAnswer, error)
+}
+
+type AnswerReviewRepository interface {
+	Create(review *AnswerReview) error
+	GetByID(id uuid.UUID) (*AnswerReview, error)
+	// ListByStatus returns the reviews of the tenant's courses
+	ListByStatus(tenant Tenant, status ReviewStatus, limit, offset int) ([]*AnswerReview, error)
+	GetByAttemptID(attemptID uuid.UUID) ([]*AnswerReview, error)
+	// Judge stores the LLM's grade of a review that was waiting for it
+	Judge(review *AnswerReview) error
+	// Resolve stores the reviewer's decision
+	Resolve(review *AnswerReview) error
 }
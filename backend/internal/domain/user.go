@@ -11,17 +11,22 @@ type UserRole string
 const (
 	RoleUser  UserRole = "user"
 	RoleAdmin UserRole = "admin"
+	// RoleSuperAdmin administers every organization
+	RoleSuperAdmin UserRole = "super_admin"
 )
 
 type User struct {
-	ID           uuid.UUID `db:"id" json:"id"`
-	Email        string    `db:"email" json:"email"`
-	PasswordHash string    `db:"password_hash" json:"-"`
-	FirstName    string    `db:"first_name" json:"firstName"`
-	LastName     string    `db:"last_name" json:"lastName"`
-	Role         UserRole  `db:"role" json:"role"`
-	CreatedAt    time.Time `db:"created_at" json:"createdAt"`
-	UpdatedAt    time.Time `db:"updated_at" json:"updatedAt"`
+	ID             uuid.UUID `db:"id" json:"id"`
+	Email          string    `db:"email" json:"email"`
+	PasswordHash   string    `db:"password_hash" json:"-"`
+	FirstName      string    `db:"first_name" json:"firstName"`
+	LastName       string    `db:"last_name" json:"lastName"`
+	Role           UserRole  `db:"role" json:"role"`
+	OrganizationID uuid.UUID `db:"organization_id" json:"organizationId"`
+	CreatedAt      time.Time `db:"created_at" json:"createdAt"`
+	UpdatedAt      time.Time `db:"updated_at" json:"updatedAt"`
+	// Permissions of the user's role, loaded when tokens are issued
+	Permissions []Permission `db:"-" json:"permissions"`
 }
 
 type CreateUserRequest struct {
@@ -29,6 +34,9 @@ type CreateUserRequest struct {
 	Password  string `json:"password" validate:"required,min=8"`
 	FirstName string `json:"firstName" validate:"required,min=1,max=100"`
 	LastName  string `json:"lastName" validate:"required,min=1,max=100"`
+	// Organization is the slug of the organization to join; empty joins
+	// the default organization
+	Organization string `json:"organization,omitempty" validate:"omitempty,max=100"`
 }
 
 type LoginRequest struct {
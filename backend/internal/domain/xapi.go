@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type XAPIStatus string
+
+const (
+	XAPIStatusPending XAPIStatus = "pending"
+	XAPIStatusSent    XAPIStatus = "sent"
+	XAPIStatusFailed  XAPIStatus = "failed" // Rejected by the LRS or out of tries; not retried
+)
+
+// XAPIOutboxEntry is an xAPI statement waiting to be sent to the LRS. The
+// entry's ID is the statement's ID.
+type XAPIOutboxEntry struct {
+	ID            uuid.UUID       `db:"id" json:"id"`
+	Statement     json.RawMessage `db:"statement" json:"statement"`
+	Status        XAPIStatus      `db:"status" json:"status"`
+	Attempts      int             `db:"attempts" json:"attempts"`
+	NextAttemptAt time.Time       `db:"next_attempt_at" json:"nextAttemptAt"`
+	LastError     *string         `db:"last_error" json:"lastError,omitempty"`
+	SentAt        *time.Time      `db:"sent_at" json:"sentAt,omitempty"`
+	CreatedAt     time.Time       `db:"created_at" json:"createdAt"`
+}
+
+// SlideViewRequest records that the learner looked at a slide of one of the
+// course's presentation lessons
+type SlideViewRequest struct {
+	LessonID   uuid.UUID `json:"lessonId" validate:"required"`
+	SlideIndex int       `json:"slideIndex" validate:"min=0"`
+}
+
+type XAPIOutboxRepository interface {
+	Create(entry *XAPIOutboxEntry) error
+	// ClaimDue returns up to limit pending entries that are due and pushes
+	// their next attempt out by leaseFor, so other instances skip them while
+	// they are being sent
+	ClaimDue(limit int, leaseFor time.Duration) ([]*XAPIOutboxEntry, error)
+	MarkSent(ids []uuid.UUID) error
+	Retry(id uuid.UUID, nextAttemptAt time.Time, errMsg string) error
+	Fail(id uuid.UUID, errMsg string) error
+	DeleteSentBefore(t time.Time) (int64, error)
+}
@@ -3,12 +3,17 @@ package postgres
 import (
 	"database/sql"
 	"errors"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/secusense/backend/internal/domain"
 )
 
+const aiJobColumns = `id, course_id, job_type, status, input_data, output_data, error, created_at, updated_at, completed_at,
+	attempts, max_attempts, run_at, locked_by, locked_until`
+
 type AIGenerationJobRepository struct {
 	db *sqlx.DB
 }
@@ -19,13 +24,21 @@ func NewAIGenerationJobRepository(db *sqlx.DB) *AIGenerationJobRepository {
 
 func (r *AIGenerationJobRepository) Create(job *domain.AIGenerationJob) error {
 	query := `
-		INSERT INTO ai_generation_jobs (id, course_id, job_type, status, input_data, output_data, error, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), NOW())
-		RETURNING created_at, updated_at`
+		INSERT INTO ai_generation_jobs (id, course_id, job_type, status, input_data, output_data, error, max_attempts, run_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, COALESCE($9, NOW()), NOW(), NOW())
+		RETURNING created_at, updated_at, run_at`
 
 	if job.ID == uuid.Nil {
 		job.ID = uuid.New()
 	}
+	if job.MaxAttempts <= 0 {
+		job.MaxAttempts = 1
+	}
+
+	var runAt interface{}
+	if !job.RunAt.IsZero() {
+		runAt = job.RunAt
+	}
 
 	// Handle nil OutputData - use nil for SQL NULL instead of empty json.RawMessage
 	var outputData interface{}
@@ -35,15 +48,16 @@ func (r *AIGenerationJobRepository) Create(job *domain.AIGenerationJob) error {
 
 	return r.db.QueryRow(
 		query,
-		job.ID, job.CourseID, job.JobType, job.Status, job.InputData, outputData, job.Error,
-	).Scan(&job.CreatedAt, &job.UpdatedAt)
+		job.ID, job.CourseID, job.JobType, job.Status, job.InputData, outputData, job.Error, job.MaxAttempts, runAt,
+	).Scan(&job.CreatedAt, &job.UpdatedAt, &job.RunAt)
 }
 
 func (r *AIGenerationJobRepository) GetByID(id uuid.UUID) (*domain.AIGenerationJob, error) {
 	var job domain.AIGenerationJob
 	query := `SELECT id, course_id, job_type, status, input_data,
 			  COALESCE(output_data, '{}') as output_data,
-			  error, created_at, updated_at, completed_at
+			  error, created_at, updated_at, completed_at,
+			  attempts, max_attempts, run_at, locked_by, locked_until
 			  FROM ai_generation_jobs WHERE id = $1`
 
 	err := r.db.Get(&job, query, id)
@@ -58,7 +72,7 @@ func (r *AIGenerationJobRepository) GetByID(id uuid.UUID) (*domain.AIGenerationJ
 
 func (r *AIGenerationJobRepository) GetByCourseID(courseID uuid.UUID) ([]*domain.AIGenerationJob, error) {
 	var jobs []*domain.AIGenerationJob
-	query := `SELECT id, course_id, job_type, status, input_data, output_data, error, created_at, updated_at, completed_at
+	query := `SELECT ` + aiJobColumns + `
 			  FROM ai_generation_jobs WHERE course_id = $1 ORDER BY created_at DESC`
 
 	err := r.db.Select(&jobs, query, courseID)
@@ -83,7 +97,7 @@ func (r *AIGenerationJobRepository) Update(job *domain.AIGenerationJob) error {
 
 func (r *AIGenerationJobRepository) ListPending(limit int) ([]*domain.AIGenerationJob, error) {
 	var jobs []*domain.AIGenerationJob
-	query := `SELECT id, course_id, job_type, status, input_data, output_data, error, created_at, updated_at, completed_at
+	query := `SELECT ` + aiJobColumns + `
 			  FROM ai_generation_jobs WHERE status = 'pending' ORDER BY created_at ASC LIMIT $1`
 
 	err := r.db.Select(&jobs, query, limit)
@@ -92,3 +106,135 @@ func (r *AIGenerationJobRepository) ListPending(limit int) ([]*domain.AIGenerati
 	}
 	return jobs, nil
 }
+
+// Lease atomically claims the oldest runnable job of one of the given types.
+// SKIP LOCKED lets several workers (or instances) poll concurrently without
+// blocking on each other. Returns nil when there is nothing to do.
+func (r *AIGenerationJobRepository) Lease(workerID string, jobTypes []domain.JobType, leaseFor time.Duration) (*domain.AIGenerationJob, error) {
+	types := make([]string, len(jobTypes))
+	for i, t := range jobTypes {
+		types[i] = string(t)
+	}
+
+	var job domain.AIGenerationJob
+	query := `
+		UPDATE ai_generation_jobs
+		SET status = 'processing', attempts = attempts + 1, locked_by = $1,
+			locked_until = NOW() + make_interval(secs => $2), updated_at = NOW()
+		WHERE id = (
+			SELECT id FROM ai_generation_jobs
+			WHERE status = 'pending' AND run_at <= NOW() AND job_type = ANY($3)
+			ORDER BY run_at ASC, created_at ASC
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + aiJobColumns
+
+	err := r.db.Get(&job, query, workerID, leaseFor.Seconds(), pq.Array(types))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// ExtendLease pushes the lease of a running job forward. It fails if the job
+// is no longer held by the worker, e.g. because it was recovered meanwhile.
+func (r *AIGenerationJobRepository) ExtendLease(id uuid.UUID, workerID string, leaseFor time.Duration) error {
+	query := `
+		UPDATE ai_generation_jobs
+		SET locked_until = NOW() + make_interval(secs => $1)
+		WHERE id = $2 AND locked_by = $3 AND status = 'processing'`
+
+	result, err := r.db.Exec(query, leaseFor.Seconds(), id, workerID)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return domain.ErrLeaseLost
+	}
+	return nil
+}
+
+// leaseHeld turns the missing row of a guarded update into ErrLeaseLost
+func leaseHeld(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.ErrLeaseLost
+	}
+	return err
+}
+
+func (r *AIGenerationJobRepository) Complete(job *domain.AIGenerationJob, workerID string) error {
+	var outputData interface{}
+	if len(job.OutputData) > 0 {
+		outputData = job.OutputData
+	}
+
+	query := `
+		UPDATE ai_generation_jobs
+		SET status = 'completed', course_id = $1, output_data = $2, error = NULL,
+			locked_by = NULL, locked_until = NULL, completed_at = NOW(), updated_at = NOW()
+		WHERE id = $3 AND locked_by = $4 AND status = 'processing'
+		RETURNING status, updated_at, completed_at`
+
+	return leaseHeld(r.db.QueryRow(query, job.CourseID, outputData, job.ID, workerID).
+		Scan(&job.Status, &job.UpdatedAt, &job.CompletedAt))
+}
+
+// Retry puts a failed job back into the queue to run again at runAt.
+func (r *AIGenerationJobRepository) Retry(job *domain.AIGenerationJob, workerID string, runAt time.Time, errMsg string) error {
+	query := `
+		UPDATE ai_generation_jobs
+		SET status = 'pending', error = $1, run_at = $2,
+			locked_by = NULL, locked_until = NULL, updated_at = NOW()
+		WHERE id = $3 AND locked_by = $4 AND status = 'processing'
+		RETURNING status, run_at, updated_at`
+
+	return leaseHeld(r.db.QueryRow(query, errMsg, runAt, job.ID, workerID).Scan(&job.Status, &job.RunAt, &job.UpdatedAt))
+}
+
+// Fail marks a job as permanently failed.
+func (r *AIGenerationJobRepository) Fail(job *domain.AIGenerationJob, workerID string, errMsg string) error {
+	query := `
+		UPDATE ai_generation_jobs
+		SET status = 'failed', error = $1, locked_by = NULL, locked_until = NULL,
+			completed_at = NOW(), updated_at = NOW()
+		WHERE id = $2 AND locked_by = $3 AND status = 'processing'
+		RETURNING status, updated_at, completed_at`
+
+	return leaseHeld(r.db.QueryRow(query, errMsg, job.ID, workerID).Scan(&job.Status, &job.UpdatedAt, &job.CompletedAt))
+}
+
+// Release hands an interrupted job back to the queue without counting the
+// interrupted run as an attempt.
+func (r *AIGenerationJobRepository) Release(id uuid.UUID, workerID string) error {
+	query := `
+		UPDATE ai_generation_jobs
+		SET status = 'pending', attempts = GREATEST(attempts - 1, 0),
+			locked_by = NULL, locked_until = NULL, updated_at = NOW()
+		WHERE id = $1 AND locked_by = $2 AND status = 'processing'`
+
+	_, err := r.db.Exec(query, id, workerID)
+	return err
+}
+
+// RecoverExpired returns jobs whose worker died (lease expired, or jobs
+// started before leases existed) to the queue.
+func (r *AIGenerationJobRepository) RecoverExpired() (int64, error) {
+	query := `
+		UPDATE ai_generation_jobs
+		SET status = 'pending', locked_by = NULL, locked_until = NULL, updated_at = NOW()
+		WHERE status = 'processing' AND (locked_until IS NULL OR locked_until < NOW())`
+
+	result, err := r.db.Exec(query)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
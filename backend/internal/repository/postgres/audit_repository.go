@@ -0,0 +1,67 @@
+package postgres
+
+import (
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/secusense/backend/internal/domain"
+)
+
+type AuditRepository struct {
+	db *sqlx.DB
+}
+
+func NewAuditRepository(db *sqlx.DB) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+func (r *AuditRepository) Create(entry *domain.AuditEntry) error {
+	return insertAuditEntry(r.db, entry)
+}
+
+// insertAuditEntry adds an entry to the audit log, on its own or within a
+// transaction
+func insertAuditEntry(q sqlx.Queryer, entry *domain.AuditEntry) error {
+	query := `
+		INSERT INTO audit_log (id, organization_id, actor_id, action, entity_type, entity_id, details, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())
+		RETURNING created_at`
+
+	if entry.ID == uuid.Nil {
+		entry.ID = uuid.New()
+	}
+	details := entry.Details
+	if details == nil {
+		details = []byte("{}")
+	}
+
+	return q.QueryRowx(
+		query,
+		entry.ID, entry.OrganizationID, entry.ActorID, entry.Action, entry.EntityType, entry.EntityID, details,
+	).Scan(&entry.CreatedAt)
+}
+
+// List returns the audit log of the tenant, newest first
+func (r *AuditRepository) List(tenant domain.Tenant, limit, offset int) ([]*domain.AuditEntry, error) {
+	var entries []*domain.AuditEntry
+	query := `
+		SELECT a.id, a.organization_id, a.actor_id, a.action, a.entity_type, a.entity_id, a.details, a.created_at,
+		       u.email as actor_email
+		FROM audit_log a
+		LEFT JOIN users u ON a.actor_id = u.id
+		WHERE ($1 OR a.organization_id = $2)
+		ORDER BY a.created_at DESC
+		LIMIT $3 OFFSET $4`
+
+	err := r.db.Select(&entries, query, tenant.CrossTenant, tenant.OrganizationID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (r *AuditRepository) Count(tenant domain.Tenant) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM audit_log WHERE ($1 OR organization_id = $2)`
+	err := r.db.Get(&count, query, tenant.CrossTenant, tenant.OrganizationID)
+	return count, err
+}
@@ -13,6 +13,20 @@ import (
 	"github.com/secusense/backend/internal/domain"
 )
 
+// certificateColumns selects a certificate with its learner, course, score
+// and the branding of its organization. The course's language is that of
+// the workflow it was generated by.
+const certificateColumns = `c.id, c.certificate_number, c.user_id, c.course_id, c.test_attempt_id, c.pdf_url,
+		       c.verification_hash, c.issued_at, c.expires_at, c.organization_id,
+		       c.previous_certificate_id, c.renewal_opened_at, c.signature,
+		       c.status_list_index, c.revoked_at, c.revoked_by, c.revocation_reason,
+		       u.first_name as user_first_name, u.last_name as user_last_name, u.email as user_email,
+		       co.title as course_title,
+		       COALESCE((SELECT ws.language FROM course_workflow_sessions ws
+		                 WHERE ws.course_id = c.course_id ORDER BY ws.created_at DESC LIMIT 1), 'en') as course_language,
+		       ta.score as score, ta.max_score as max_score,
+		       o.name as organization_name, o.certificate_issuer, o.brand_color, o.certificate_footer`
+
 type CertificateRepository struct {
 	db *sqlx.DB
 }
@@ -22,34 +36,35 @@ func NewCertificateRepository(db *sqlx.DB) *CertificateRepository {
 }
 
 func (r *CertificateRepository) Create(cert *domain.Certificate) error {
+	// A certificate belongs to the organization of its course
 	query := `
-		INSERT INTO certificates (id, certificate_number, user_id, course_id, test_attempt_id, pdf_url, verification_hash, issued_at, expires_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), $8)
-		RETURNING issued_at`
+		INSERT INTO certificates (id, certificate_number, user_id, course_id, test_attempt_id, pdf_url, verification_hash, organization_id, issued_at, expires_at, previous_certificate_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, (SELECT organization_id FROM courses WHERE id = $4), $8, $9, $10)
+		RETURNING organization_id, issued_at, status_list_index`
 
 	if cert.ID == uuid.Nil {
 		cert.ID = uuid.New()
 	}
+	if cert.IssuedAt.IsZero() {
+		cert.IssuedAt = time.Now()
+	}
 
 	return r.db.QueryRow(
 		query,
 		cert.ID, cert.CertificateNumber, cert.UserID, cert.CourseID, cert.TestAttemptID,
-		cert.PDFURL, cert.VerificationHash, cert.ExpiresAt,
-	).Scan(&cert.IssuedAt)
+		cert.PDFURL, cert.VerificationHash, cert.IssuedAt, cert.ExpiresAt, cert.PreviousCertificateID,
+	).Scan(&cert.OrganizationID, &cert.IssuedAt, &cert.StatusListIndex)
 }
 
 func (r *CertificateRepository) GetByID(id uuid.UUID) (*domain.Certificate, error) {
 	var cert domain.Certificate
 	query := `
-		SELECT c.id, c.certificate_number, c.user_id, c.course_id, c.test_attempt_id, c.pdf_url,
-		       c.verification_hash, c.issued_at, c.expires_at,
-		       u.first_name as user_first_name, u.last_name as user_last_name, u.email as user_email,
-		       co.title as course_title,
-		       ta.score as score, ta.max_score as max_score
+		SELECT ` + certificateColumns + `
 		FROM certificates c
 		JOIN users u ON c.user_id = u.id
 		JOIN courses co ON c.course_id = co.id
 		JOIN test_attempts ta ON c.test_attempt_id = ta.id
+		JOIN organizations o ON c.organization_id = o.id
 		WHERE c.id = $1`
 
 	err := r.db.Get(&cert, query, id)
@@ -65,15 +80,12 @@ func (r *CertificateRepository) GetByID(id uuid.UUID) (*domain.Certificate, erro
 func (r *CertificateRepository) GetByVerificationHash(hash string) (*domain.Certificate, error) {
 	var cert domain.Certificate
 	query := `
-		SELECT c.id, c.certificate_number, c.user_id, c.course_id, c.test_attempt_id, c.pdf_url,
-		       c.verification_hash, c.issued_at, c.expires_at,
-		       u.first_name as user_first_name, u.last_name as user_last_name, u.email as user_email,
-		       co.title as course_title,
-		       ta.score as score, ta.max_score as max_score
+		SELECT ` + certificateColumns + `
 		FROM certificates c
 		JOIN users u ON c.user_id = u.id
 		JOIN courses co ON c.course_id = co.id
 		JOIN test_attempts ta ON c.test_attempt_id = ta.id
+		JOIN organizations o ON c.organization_id = o.id
 		WHERE c.verification_hash = $1`
 
 	err := r.db.Get(&cert, query, hash)
@@ -89,15 +101,12 @@ func (r *CertificateRepository) GetByVerificationHash(hash string) (*domain.Cert
 func (r *CertificateRepository) GetByUserID(userID uuid.UUID) ([]*domain.Certificate, error) {
 	var certs []*domain.Certificate
 	query := `
-		SELECT c.id, c.certificate_number, c.user_id, c.course_id, c.test_attempt_id, c.pdf_url,
-		       c.verification_hash, c.issued_at, c.expires_at,
-		       u.first_name as user_first_name, u.last_name as user_last_name, u.email as user_email,
-		       co.title as course_title,
-		       ta.score as score, ta.max_score as max_score
+		SELECT ` + certificateColumns + `
 		FROM certificates c
 		JOIN users u ON c.user_id = u.id
 		JOIN courses co ON c.course_id = co.id
 		JOIN test_attempts ta ON c.test_attempt_id = ta.id
+		JOIN organizations o ON c.organization_id = o.id
 		WHERE c.user_id = $1
 		ORDER BY c.issued_at DESC`
 
@@ -111,15 +120,12 @@ func (r *CertificateRepository) GetByUserID(userID uuid.UUID) ([]*domain.Certifi
 func (r *CertificateRepository) GetByUserAndCourse(userID, courseID uuid.UUID) (*domain.Certificate, error) {
 	var cert domain.Certificate
 	query := `
-		SELECT c.id, c.certificate_number, c.user_id, c.course_id, c.test_attempt_id, c.pdf_url,
-		       c.verification_hash, c.issued_at, c.expires_at,
-		       u.first_name as user_first_name, u.last_name as user_last_name, u.email as user_email,
-		       co.title as course_title,
-		       ta.score as score, ta.max_score as max_score
+		SELECT ` + certificateColumns + `
 		FROM certificates c
 		JOIN users u ON c.user_id = u.id
 		JOIN courses co ON c.course_id = co.id
 		JOIN test_attempts ta ON c.test_attempt_id = ta.id
+		JOIN organizations o ON c.organization_id = o.id
 		WHERE c.user_id = $1 AND c.course_id = $2
 		ORDER BY c.issued_at DESC LIMIT 1`
 
@@ -133,16 +139,162 @@ func (r *CertificateRepository) GetByUserAndCourse(userID, courseID uuid.UUID) (
 	return &cert, nil
 }
 
+// List returns the certificates issued in the tenant, newest first
+func (r *CertificateRepository) List(tenant domain.Tenant, limit, offset int) ([]*domain.Certificate, error) {
+	var certs []*domain.Certificate
+	query := `
+		SELECT ` + certificateColumns + `
+		FROM certificates c
+		JOIN users u ON c.user_id = u.id
+		JOIN courses co ON c.course_id = co.id
+		JOIN test_attempts ta ON c.test_attempt_id = ta.id
+		JOIN organizations o ON c.organization_id = o.id
+		WHERE ($1 OR c.organization_id = $2)
+		ORDER BY c.issued_at DESC
+		LIMIT $3 OFFSET $4`
+
+	err := r.db.Select(&certs, query, tenant.CrossTenant, tenant.OrganizationID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return certs, nil
+}
+
+func (r *CertificateRepository) Count(tenant domain.Tenant) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM certificates WHERE ($1 OR organization_id = $2)`
+	err := r.db.Get(&count, query, tenant.CrossTenant, tenant.OrganizationID)
+	return count, err
+}
+
 func (r *CertificateRepository) Update(cert *domain.Certificate) error {
 	query := `
 		UPDATE certificates
-		SET pdf_url = $1, expires_at = $2
-		WHERE id = $3`
+		SET pdf_url = $1, expires_at = $2, signature = $3
+		WHERE id = $4`
 
-	_, err := r.db.Exec(query, cert.PDFURL, cert.ExpiresAt, cert.ID)
+	_, err := r.db.Exec(query, cert.PDFURL, cert.ExpiresAt, cert.Signature, cert.ID)
 	return err
 }
 
+// ListDueForRenewal skips certificates that were renewed already, the
+// renewal being the one to watch, and revoked ones
+func (r *CertificateRepository) ListDueForRenewal(now time.Time, limit int) ([]*domain.Certificate, error) {
+	var certs []*domain.Certificate
+	query := `
+		SELECT ` + certificateColumns + `
+		FROM certificates c
+		JOIN users u ON c.user_id = u.id
+		JOIN courses co ON c.course_id = co.id
+		JOIN test_attempts ta ON c.test_attempt_id = ta.id
+		JOIN organizations o ON c.organization_id = o.id
+		WHERE c.expires_at IS NOT NULL AND c.renewal_opened_at IS NULL AND c.revoked_at IS NULL
+		  AND c.expires_at - make_interval(days => co.renewal_notice_days) <= $1
+		  AND NOT EXISTS (SELECT 1 FROM certificates n WHERE n.previous_certificate_id = c.id)
+		ORDER BY c.expires_at ASC
+		LIMIT $2`
+
+	if err := r.db.Select(&certs, query, now, limit); err != nil {
+		return nil, err
+	}
+	return certs, nil
+}
+
+func (r *CertificateRepository) MarkRenewalOpened(id uuid.UUID, at time.Time) error {
+	_, err := r.db.Exec(`UPDATE certificates SET renewal_opened_at = $1 WHERE id = $2`, at, id)
+	return err
+}
+
+func (r *CertificateRepository) GetHistory(id uuid.UUID) ([]*domain.Certificate, error) {
+	var certs []*domain.Certificate
+	query := `
+		WITH RECURSIVE chain AS (
+			SELECT id, previous_certificate_id FROM certificates WHERE id = $1
+			UNION
+			SELECT p.id, p.previous_certificate_id
+			FROM certificates p JOIN chain ON p.id = chain.previous_certificate_id
+		)
+		SELECT ` + certificateColumns + `
+		FROM chain
+		JOIN certificates c ON c.id = chain.id
+		JOIN users u ON c.user_id = u.id
+		JOIN courses co ON c.course_id = co.id
+		JOIN test_attempts ta ON c.test_attempt_id = ta.id
+		JOIN organizations o ON c.organization_id = o.id
+		ORDER BY c.issued_at DESC`
+
+	if err := r.db.Select(&certs, query, id); err != nil {
+		return nil, err
+	}
+	return certs, nil
+}
+
+func (r *CertificateRepository) GetByAttemptID(attemptID uuid.UUID) (*domain.Certificate, error) {
+	var cert domain.Certificate
+	query := `
+		SELECT ` + certificateColumns + `
+		FROM certificates c
+		JOIN users u ON c.user_id = u.id
+		JOIN courses co ON c.course_id = co.id
+		JOIN test_attempts ta ON c.test_attempt_id = ta.id
+		JOIN organizations o ON c.organization_id = o.id
+		WHERE c.test_attempt_id = $1`
+
+	err := r.db.Get(&cert, query, attemptID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &cert, nil
+}
+
+func (r *CertificateRepository) Revoke(cert *domain.Certificate) (bool, error) {
+	query := `
+		UPDATE certificates
+		SET revoked_at = $1, revoked_by = $2, revocation_reason = $3
+		WHERE id = $4 AND revoked_at IS NULL`
+
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return false, err
+	}
+
+	result, err := tx.Exec(query, cert.RevokedAt, cert.RevokedBy, cert.RevocationReason, cert.ID)
+	if err != nil {
+		tx.Rollback()
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil || rows == 0 {
+		tx.Rollback()
+		return false, err
+	}
+
+	if err := insertAuditEntry(tx, domain.NewCertificateRevokedEntry(cert)); err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+func (r *CertificateRepository) ListRevokedStatusIndexes() ([]int, error) {
+	var indexes []int
+	query := `SELECT status_list_index FROM certificates WHERE revoked_at IS NOT NULL ORDER BY status_list_index`
+	if err := r.db.Select(&indexes, query); err != nil {
+		return nil, err
+	}
+	return indexes, nil
+}
+
+func (r *CertificateRepository) MaxStatusListIndex() (int, error) {
+	var max int
+	err := r.db.Get(&max, `SELECT COALESCE(MAX(status_list_index), 0) FROM certificates`)
+	return max, err
+}
+
 func (r *CertificateRepository) GenerateCertificateNumber() (string, error) {
 	year := time.Now().Year()
 	bytes := make([]byte, 4)
@@ -151,3 +303,63 @@ func (r *CertificateRepository) GenerateCertificateNumber() (string, error) {
 	}
 	return fmt.Sprintf("SS-%d-%s", year, hex.EncodeToString(bytes)), nil
 }
+
+type CertificateKeyRepository struct {
+	db *sqlx.DB
+}
+
+func NewCertificateKeyRepository(db *sqlx.DB) *CertificateKeyRepository {
+	return &CertificateKeyRepository{db: db}
+}
+
+func (r *CertificateKeyRepository) GetLatest() (*domain.CertificateSigningKey, error) {
+	var key domain.CertificateSigningKey
+	err := r.db.Get(&key, `SELECT kid, private_key, created_at FROM certificate_signing_keys ORDER BY created_at DESC LIMIT 1`)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (r *CertificateKeyRepository) List() ([]*domain.CertificateSigningKey, error) {
+	var keys []*domain.CertificateSigningKey
+	err := r.db.Select(&keys, `SELECT kid, private_key, created_at FROM certificate_signing_keys ORDER BY created_at DESC`)
+	if err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (r *CertificateKeyRepository) CreateFirst(key *domain.CertificateSigningKey) (bool, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	// Instances starting together wait here, so only the first creates a key
+	if _, err := tx.Exec(`LOCK TABLE certificate_signing_keys IN EXCLUSIVE MODE`); err != nil {
+		return false, err
+	}
+	var exists bool
+	if err := tx.Get(&exists, `SELECT EXISTS (SELECT 1 FROM certificate_signing_keys)`); err != nil {
+		return false, err
+	}
+	if exists {
+		return false, nil
+	}
+
+	query := `INSERT INTO certificate_signing_keys (kid, private_key, created_at) VALUES ($1, $2, NOW()) RETURNING created_at`
+	if err := tx.QueryRow(query, key.KID, key.PrivateKey).Scan(&key.CreatedAt); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+func (r *CertificateKeyRepository) UpdatePrivateKey(kid, privateKey string) error {
+	_, err := r.db.Exec(`UPDATE certificate_signing_keys SET private_key = $1 WHERE kid = $2`, privateKey, kid)
+	return err
+}
@@ -0,0 +1,160 @@
+package postgres
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/secusense/backend/internal/domain"
+)
+
+const certificateTemplateColumns = `id, organization_id, course_id, name, primary_color, text_color, logo,
+		       signatures, texts, extra_fields, created_at, updated_at`
+
+type CertificateTemplateRepository struct {
+	db *sqlx.DB
+}
+
+func NewCertificateTemplateRepository(db *sqlx.DB) *CertificateTemplateRepository {
+	return &CertificateTemplateRepository{db: db}
+}
+
+func (r *CertificateTemplateRepository) Create(tmpl *domain.CertificateTemplate) error {
+	signatures, texts, extraFields, err := marshalTemplate(tmpl)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO certificate_templates (id, organization_id, course_id, name, primary_color, text_color, logo,
+		                                   signatures, texts, extra_fields, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NOW(), NOW())
+		RETURNING created_at, updated_at`
+
+	if tmpl.ID == uuid.Nil {
+		tmpl.ID = uuid.New()
+	}
+
+	return r.db.QueryRow(
+		query,
+		tmpl.ID, tmpl.OrganizationID, tmpl.CourseID, tmpl.Name, tmpl.PrimaryColor, tmpl.TextColor, tmpl.Logo,
+		signatures, texts, extraFields,
+	).Scan(&tmpl.CreatedAt, &tmpl.UpdatedAt)
+}
+
+func (r *CertificateTemplateRepository) GetByID(id uuid.UUID) (*domain.CertificateTemplate, error) {
+	query := `SELECT ` + certificateTemplateColumns + ` FROM certificate_templates WHERE id = $1`
+	return r.get(query, id)
+}
+
+func (r *CertificateTemplateRepository) GetByScope(organizationID uuid.UUID, courseID *uuid.UUID) (*domain.CertificateTemplate, error) {
+	query := `
+		SELECT ` + certificateTemplateColumns + `
+		FROM certificate_templates
+		WHERE organization_id = $1 AND course_id IS NOT DISTINCT FROM $2`
+	return r.get(query, organizationID, courseID)
+}
+
+func (r *CertificateTemplateRepository) GetForCourse(organizationID, courseID uuid.UUID) (*domain.CertificateTemplate, error) {
+	query := `
+		SELECT ` + certificateTemplateColumns + `
+		FROM certificate_templates
+		WHERE organization_id = $1 AND (course_id = $2 OR course_id IS NULL)
+		ORDER BY course_id NULLS LAST
+		LIMIT 1`
+	return r.get(query, organizationID, courseID)
+}
+
+// List returns the templates of the tenant, organization templates first
+func (r *CertificateTemplateRepository) List(tenant domain.Tenant) ([]*domain.CertificateTemplate, error) {
+	var templates []*domain.CertificateTemplate
+	query := `
+		SELECT ` + certificateTemplateColumns + `
+		FROM certificate_templates
+		WHERE ($1 OR organization_id = $2)
+		ORDER BY organization_id, course_id NULLS FIRST, name`
+
+	if err := r.db.Select(&templates, query, tenant.CrossTenant, tenant.OrganizationID); err != nil {
+		return nil, err
+	}
+	for _, tmpl := range templates {
+		if err := unmarshalTemplate(tmpl); err != nil {
+			return nil, err
+		}
+	}
+	return templates, nil
+}
+
+func (r *CertificateTemplateRepository) Update(tmpl *domain.CertificateTemplate) error {
+	signatures, texts, extraFields, err := marshalTemplate(tmpl)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE certificate_templates
+		SET name = $1, primary_color = $2, text_color = $3, logo = $4,
+		    signatures = $5, texts = $6, extra_fields = $7, updated_at = NOW()
+		WHERE id = $8
+		RETURNING updated_at`
+
+	return r.db.QueryRow(
+		query,
+		tmpl.Name, tmpl.PrimaryColor, tmpl.TextColor, tmpl.Logo, signatures, texts, extraFields, tmpl.ID,
+	).Scan(&tmpl.UpdatedAt)
+}
+
+func (r *CertificateTemplateRepository) Delete(id uuid.UUID) error {
+	_, err := r.db.Exec(`DELETE FROM certificate_templates WHERE id = $1`, id)
+	return err
+}
+
+func (r *CertificateTemplateRepository) get(query string, args ...interface{}) (*domain.CertificateTemplate, error) {
+	var tmpl domain.CertificateTemplate
+	err := r.db.Get(&tmpl, query, args...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := unmarshalTemplate(&tmpl); err != nil {
+		return nil, err
+	}
+	return &tmpl, nil
+}
+
+func marshalTemplate(tmpl *domain.CertificateTemplate) (signatures, texts, extraFields []byte, err error) {
+	if tmpl.Signatures == nil {
+		tmpl.Signatures = []domain.CertificateSignature{}
+	}
+	if tmpl.Texts == nil {
+		tmpl.Texts = map[string]map[domain.CertificateTextKey]string{}
+	}
+	if tmpl.ExtraFields == nil {
+		tmpl.ExtraFields = []domain.CertificateExtraField{}
+	}
+
+	if signatures, err = json.Marshal(tmpl.Signatures); err != nil {
+		return nil, nil, nil, err
+	}
+	if texts, err = json.Marshal(tmpl.Texts); err != nil {
+		return nil, nil, nil, err
+	}
+	if extraFields, err = json.Marshal(tmpl.ExtraFields); err != nil {
+		return nil, nil, nil, err
+	}
+	return signatures, texts, extraFields, nil
+}
+
+func unmarshalTemplate(tmpl *domain.CertificateTemplate) error {
+	if err := json.Unmarshal(tmpl.SignaturesRaw, &tmpl.Signatures); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(tmpl.TextsRaw, &tmpl.Texts); err != nil {
+		return err
+	}
+	return json.Unmarshal(tmpl.ExtraFieldsRaw, &tmpl.ExtraFields)
+}
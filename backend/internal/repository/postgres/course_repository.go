@@ -19,24 +19,28 @@ func NewCourseRepository(db *sqlx.DB) *CourseRepository {
 
 func (r *CourseRepository) Create(course *domain.Course) error {
 	query := `
-		INSERT INTO courses (id, title, description, video_url, synthesia_video_id, video_status, video_error, thumbnail_url, pass_percentage, is_published, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NOW(), NOW())
+		INSERT INTO courses (id, title, description, video_url, synthesia_video_id, video_status, video_error, thumbnail_url, pass_percentage, is_published, organization_id, certificate_validity_days, renewal_notice_days, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, NOW(), NOW())
 		RETURNING created_at, updated_at`
 
 	if course.ID == uuid.Nil {
 		course.ID = uuid.New()
 	}
+	if course.RenewalNoticeDays <= 0 {
+		course.RenewalNoticeDays = domain.DefaultRenewalNoticeDays
+	}
 
 	return r.db.QueryRow(
 		query,
 		course.ID, course.Title, course.Description, course.VideoURL, course.SynthesiaVideoID,
-		course.VideoStatus, course.VideoError, course.ThumbnailURL, course.PassPercentage, course.IsPublished,
+		course.VideoStatus, course.VideoError, course.ThumbnailURL, course.PassPercentage, course.IsPublished, course.OrganizationID,
+		course.CertificateValidityDays, course.RenewalNoticeDays,
 	).Scan(&course.CreatedAt, &course.UpdatedAt)
 }
 
 func (r *CourseRepository) GetByID(id uuid.UUID) (*domain.Course, error) {
 	var course domain.Course
-	query := `SELECT id, title, description, video_url, synthesia_video_id, video_status, video_error, thumbnail_url, pass_percentage, is_published, created_at, updated_at
+	query := `SELECT id, title, description, video_url, synthesia_video_id, video_status, video_error, thumbnail_url, pass_percentage, is_published, organization_id, certificate_validity_days, renewal_notice_days, created_at, updated_at
 			  FROM courses WHERE id = $1`
 
 	err := r.db.Get(&course, query, id)
@@ -53,14 +57,16 @@ func (r *CourseRepository) Update(course *domain.Course) error {
 	query := `
 		UPDATE courses
 		SET title = $1, description = $2, video_url = $3, synthesia_video_id = $4,
-		    video_status = $5, video_error = $6, thumbnail_url = $7, pass_percentage = $8, is_published = $9, updated_at = NOW()
-		WHERE id = $10
+		    video_status = $5, video_error = $6, thumbnail_url = $7, pass_percentage = $8, is_published = $9,
+		    certificate_validity_days = $10, renewal_notice_days = $11, updated_at = NOW()
+		WHERE id = $12
 		RETURNING updated_at`
 
 	return r.db.QueryRow(
 		query,
 		course.Title, course.Description, course.VideoURL, course.SynthesiaVideoID,
-		course.VideoStatus, course.VideoError, course.ThumbnailURL, course.PassPercentage, course.IsPublished, course.ID,
+		course.VideoStatus, course.VideoError, course.ThumbnailURL, course.PassPercentage, course.IsPublished,
+		course.CertificateValidityDays, course.RenewalNoticeDays, course.ID,
 	).Scan(&course.UpdatedAt)
 }
 
@@ -70,19 +76,20 @@ func (r *CourseRepository) Delete(id uuid.UUID) error {
 	return err
 }
 
-func (r *CourseRepository) List(limit, offset int, publishedOnly bool) ([]*domain.Course, error) {
+// List returns the courses the tenant may see
+func (r *CourseRepository) List(tenant domain.Tenant, limit, offset int, publishedOnly bool) ([]*domain.Course, error) {
 	var courses []*domain.Course
 	var query string
 
 	if publishedOnly {
-		query = `SELECT id, title, description, video_url, synthesia_video_id, video_status, video_error, thumbnail_url, pass_percentage, is_published, created_at, updated_at
-				 FROM courses WHERE is_published = true ORDER BY created_at DESC LIMIT $1 OFFSET $2`
+		query = `SELECT id, title, description, video_url, synthesia_video_id, video_status, video_error, thumbnail_url, pass_percentage, is_published, organization_id, certificate_validity_days, renewal_notice_days, created_at, updated_at
+				 FROM courses WHERE ($1 OR organization_id = $2) AND is_published = true ORDER BY created_at DESC LIMIT $3 OFFSET $4`
 	} else {
-		query = `SELECT id, title, description, video_url, synthesia_video_id, video_status, video_error, thumbnail_url, pass_percentage, is_published, created_at, updated_at
-				 FROM courses ORDER BY created_at DESC LIMIT $1 OFFSET $2`
+		query = `SELECT id, title, description, video_url, synthesia_video_id, video_status, video_error, thumbnail_url, pass_percentage, is_published, organization_id, certificate_validity_days, renewal_notice_days, created_at, updated_at
+				 FROM courses WHERE ($1 OR organization_id = $2) ORDER BY created_at DESC LIMIT $3 OFFSET $4`
 	}
 
-	err := r.db.Select(&courses, query, limit, offset)
+	err := r.db.Select(&courses, query, tenant.CrossTenant, tenant.OrganizationID, limit, offset)
 	if err != nil {
 		return nil, err
 	}
@@ -92,7 +99,7 @@ func (r *CourseRepository) List(limit, offset int, publishedOnly bool) ([]*domai
 // GetByVideoStatus returns courses with a specific video status
 func (r *CourseRepository) GetByVideoStatus(status domain.VideoStatus) ([]*domain.Course, error) {
 	var courses []*domain.Course
-	query := `SELECT id, title, description, video_url, synthesia_video_id, video_status, video_error, thumbnail_url, pass_percentage, is_published, created_at, updated_at
+	query := `SELECT id, title, description, video_url, synthesia_video_id, video_status, video_error, thumbnail_url, pass_percentage, is_published, organization_id, certificate_validity_days, renewal_notice_days, created_at, updated_at
 			  FROM courses WHERE video_status = $1 ORDER BY updated_at ASC`
 
 	err := r.db.Select(&courses, query, status)
@@ -105,7 +112,7 @@ func (r *CourseRepository) GetByVideoStatus(status domain.VideoStatus) ([]*domai
 // GetBySynthesiaVideoID returns a course by its Synthesia video ID
 func (r *CourseRepository) GetBySynthesiaVideoID(videoID string) (*domain.Course, error) {
 	var course domain.Course
-	query := `SELECT id, title, description, video_url, synthesia_video_id, video_status, video_error, thumbnail_url, pass_percentage, is_published, created_at, updated_at
+	query := `SELECT id, title, description, video_url, synthesia_video_id, video_status, video_error, thumbnail_url, pass_percentage, is_published, organization_id, certificate_validity_days, renewal_notice_days, created_at, updated_at
 			  FROM courses WHERE synthesia_video_id = $1`
 
 	err := r.db.Get(&course, query, videoID)
@@ -118,17 +125,17 @@ func (r *CourseRepository) GetBySynthesiaVideoID(videoID string) (*domain.Course
 	return &course, nil
 }
 
-func (r *CourseRepository) Count(publishedOnly bool) (int, error) {
+func (r *CourseRepository) Count(tenant domain.Tenant, publishedOnly bool) (int, error) {
 	var count int
 	var query string
 
 	if publishedOnly {
-		query = `SELECT COUNT(*) FROM courses WHERE is_published = true`
+		query = `SELECT COUNT(*) FROM courses WHERE ($1 OR organization_id = $2) AND is_published = true`
 	} else {
-		query = `SELECT COUNT(*) FROM courses`
+		query = `SELECT COUNT(*) FROM courses WHERE ($1 OR organization_id = $2)`
 	}
 
-	err := r.db.Get(&count, query)
+	err := r.db.Get(&count, query, tenant.CrossTenant, tenant.OrganizationID)
 	return count, err
 }
 
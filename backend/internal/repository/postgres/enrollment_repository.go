@@ -18,10 +18,11 @@ func NewEnrollmentRepository(db *sqlx.DB) *EnrollmentRepository {
 }
 
 func (r *EnrollmentRepository) Create(enrollment *domain.Enrollment) error {
+	// An enrollment belongs to the organization of its course
 	query := `
-		INSERT INTO enrollments (id, user_id, course_id, status, progress_percentage, video_watched, enrolled_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, NOW(), NOW())
-		RETURNING enrolled_at, updated_at`
+		INSERT INTO enrollments (id, user_id, course_id, status, progress_percentage, video_watched, organization_id, assignment_id, due_at, renewal_started_at, enrolled_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, (SELECT organization_id FROM courses WHERE id = $3), $7, $8, $9, NOW(), NOW())
+		RETURNING organization_id, enrolled_at, updated_at`
 
 	if enrollment.ID == uuid.Nil {
 		enrollment.ID = uuid.New()
@@ -30,13 +31,14 @@ func (r *EnrollmentRepository) Create(enrollment *domain.Enrollment) error {
 	return r.db.QueryRow(
 		query,
 		enrollment.ID, enrollment.UserID, enrollment.CourseID, enrollment.Status,
-		enrollment.ProgressPercentage, enrollment.VideoWatched,
-	).Scan(&enrollment.EnrolledAt, &enrollment.UpdatedAt)
+		enrollment.ProgressPercentage, enrollment.VideoWatched, enrollment.AssignmentID, enrollment.DueAt,
+		enrollment.RenewalStartedAt,
+	).Scan(&enrollment.OrganizationID, &enrollment.EnrolledAt, &enrollment.UpdatedAt)
 }
 
 func (r *EnrollmentRepository) GetByID(id uuid.UUID) (*domain.Enrollment, error) {
 	var enrollment domain.Enrollment
-	query := `SELECT id, user_id, course_id, status, progress_percentage, video_watched, enrolled_at, completed_at, updated_at
+	query := `SELECT id, user_id, course_id, status, progress_percentage, video_watched, enrolled_at, completed_at, updated_at, organization_id, assignment_id, due_at, renewal_started_at
 			  FROM enrollments WHERE id = $1`
 
 	err := r.db.Get(&enrollment, query, id)
@@ -51,7 +53,7 @@ func (r *EnrollmentRepository) GetByID(id uuid.UUID) (*domain.Enrollment, error)
 
 func (r *EnrollmentRepository) GetByUserAndCourse(userID, courseID uuid.UUID) (*domain.Enrollment, error) {
 	var enrollment domain.Enrollment
-	query := `SELECT id, user_id, course_id, status, progress_percentage, video_watched, enrolled_at, completed_at, updated_at
+	query := `SELECT id, user_id, course_id, status, progress_percentage, video_watched, enrolled_at, completed_at, updated_at, organization_id, assignment_id, due_at, renewal_started_at
 			  FROM enrollments WHERE user_id = $1 AND course_id = $2`
 
 	err := r.db.Get(&enrollment, query, userID, courseID)
@@ -67,14 +69,15 @@ func (r *EnrollmentRepository) GetByUserAndCourse(userID, courseID uuid.UUID) (*
 func (r *EnrollmentRepository) Update(enrollment *domain.Enrollment) error {
 	query := `
 		UPDATE enrollments
-		SET status = $1, progress_percentage = $2, video_watched = $3, completed_at = $4, updated_at = NOW()
-		WHERE id = $5
+		SET status = $1, progress_percentage = $2, video_watched = $3, completed_at = $4,
+		    assignment_id = $5, due_at = $6, renewal_started_at = $7, updated_at = NOW()
+		WHERE id = $8
 		RETURNING updated_at`
 
 	return r.db.QueryRow(
 		query,
 		enrollment.Status, enrollment.ProgressPercentage, enrollment.VideoWatched,
-		enrollment.CompletedAt, enrollment.ID,
+		enrollment.CompletedAt, enrollment.AssignmentID, enrollment.DueAt, enrollment.RenewalStartedAt, enrollment.ID,
 	).Scan(&enrollment.UpdatedAt)
 }
 
@@ -82,7 +85,7 @@ func (r *EnrollmentRepository) ListByUser(userID uuid.UUID) ([]*domain.Enrollmen
 	var enrollments []*domain.EnrollmentWithCourse
 	query := `
 		SELECT e.id, e.user_id, e.course_id, e.status, e.progress_percentage, e.video_watched,
-		       e.enrolled_at, e.completed_at, e.updated_at,
+		       e.enrolled_at, e.completed_at, e.updated_at, e.organization_id, e.assignment_id, e.due_at, e.renewal_started_at,
 		       c.title as course_title, c.description as course_description, c.thumbnail_url as course_thumbnail_url
 		FROM enrollments e
 		JOIN courses c ON e.course_id = c.id
@@ -98,7 +101,7 @@ func (r *EnrollmentRepository) ListByUser(userID uuid.UUID) ([]*domain.Enrollmen
 
 func (r *EnrollmentRepository) ListByCourse(courseID uuid.UUID) ([]*domain.Enrollment, error) {
 	var enrollments []*domain.Enrollment
-	query := `SELECT id, user_id, course_id, status, progress_percentage, video_watched, enrolled_at, completed_at, updated_at
+	query := `SELECT id, user_id, course_id, status, progress_percentage, video_watched, enrolled_at, completed_at, updated_at, organization_id, assignment_id, due_at, renewal_started_at
 			  FROM enrollments WHERE course_id = $1 ORDER BY enrolled_at DESC`
 
 	err := r.db.Select(&enrollments, query, courseID)
@@ -108,6 +111,36 @@ func (r *EnrollmentRepository) ListByCourse(courseID uuid.UUID) ([]*domain.Enrol
 	return enrollments, nil
 }
 
+// ListByOrganization returns the enrollments of the tenant with their
+// learners, newest first
+func (r *EnrollmentRepository) ListByOrganization(tenant domain.Tenant, limit, offset int) ([]*domain.EnrollmentWithLearner, error) {
+	var enrollments []*domain.EnrollmentWithLearner
+	query := `
+		SELECT e.id, e.user_id, e.course_id, e.status, e.progress_percentage, e.video_watched,
+		       e.enrolled_at, e.completed_at, e.updated_at, e.organization_id, e.assignment_id, e.due_at, e.renewal_started_at,
+		       c.title as course_title, c.description as course_description, c.thumbnail_url as course_thumbnail_url,
+		       u.email as user_email, u.first_name as user_first_name, u.last_name as user_last_name
+		FROM enrollments e
+		JOIN courses c ON e.course_id = c.id
+		JOIN users u ON e.user_id = u.id
+		WHERE ($1 OR e.organization_id = $2)
+		ORDER BY e.enrolled_at DESC
+		LIMIT $3 OFFSET $4`
+
+	err := r.db.Select(&enrollments, query, tenant.CrossTenant, tenant.OrganizationID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return enrollments, nil
+}
+
+func (r *EnrollmentRepository) CountByOrganization(tenant domain.Tenant) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM enrollments WHERE ($1 OR organization_id = $2)`
+	err := r.db.Get(&count, query, tenant.CrossTenant, tenant.OrganizationID)
+	return count, err
+}
+
 func (r *EnrollmentRepository) Delete(id uuid.UUID) error {
 	query := `DELETE FROM enrollments WHERE id = $1`
 	_, err := r.db.Exec(query, id)
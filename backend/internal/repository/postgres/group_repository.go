@@ -0,0 +1,307 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/secusense/backend/internal/domain"
+)
+
+type GroupRepository struct {
+	db *sqlx.DB
+}
+
+func NewGroupRepository(db *sqlx.DB) *GroupRepository {
+	return &GroupRepository{db: db}
+}
+
+func (r *GroupRepository) Create(group *domain.Group) error {
+	query := `
+		INSERT INTO groups (id, organization_id, name, description, kind, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
+		RETURNING created_at, updated_at`
+
+	if group.ID == uuid.Nil {
+		group.ID = uuid.New()
+	}
+
+	return r.db.QueryRow(
+		query,
+		group.ID, group.OrganizationID, group.Name, group.Description, group.Kind,
+	).Scan(&group.CreatedAt, &group.UpdatedAt)
+}
+
+func (r *GroupRepository) GetByID(id uuid.UUID) (*domain.Group, error) {
+	var group domain.Group
+	query := `
+		SELECT g.id, g.organization_id, g.name, g.description, g.kind, g.created_at, g.updated_at,
+		       (SELECT COUNT(*) FROM group_members m WHERE m.group_id = g.id) as member_count
+		FROM groups g WHERE g.id = $1`
+
+	err := r.db.Get(&group, query, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &group, nil
+}
+
+func (r *GroupRepository) GetByName(organizationID uuid.UUID, name string) (*domain.Group, error) {
+	var group domain.Group
+	query := `
+		SELECT g.id, g.organization_id, g.name, g.description, g.kind, g.created_at, g.updated_at,
+		       (SELECT COUNT(*) FROM group_members m WHERE m.group_id = g.id) as member_count
+		FROM groups g WHERE g.organization_id = $1 AND g.name = $2`
+
+	err := r.db.Get(&group, query, organizationID, name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &group, nil
+}
+
+func (r *GroupRepository) List(tenant domain.Tenant) ([]*domain.Group, error) {
+	groups := []*domain.Group{}
+	query := `
+		SELECT g.id, g.organization_id, g.name, g.description, g.kind, g.created_at, g.updated_at,
+		       (SELECT COUNT(*) FROM group_members m WHERE m.group_id = g.id) as member_count
+		FROM groups g
+		WHERE ($1 OR g.organization_id = $2)
+		ORDER BY g.kind ASC, g.name ASC`
+
+	if err := r.db.Select(&groups, query, tenant.CrossTenant, tenant.OrganizationID); err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
+
+func (r *GroupRepository) Update(group *domain.Group) error {
+	query := `
+		UPDATE groups
+		SET name = $1, description = $2, kind = $3, updated_at = NOW()
+		WHERE id = $4
+		RETURNING updated_at`
+
+	return r.db.QueryRow(query, group.Name, group.Description, group.Kind, group.ID).Scan(&group.UpdatedAt)
+}
+
+func (r *GroupRepository) Delete(id uuid.UUID) error {
+	_, err := r.db.Exec(`DELETE FROM groups WHERE id = $1`, id)
+	return err
+}
+
+func (r *GroupRepository) AddMember(groupID, userID uuid.UUID) (bool, error) {
+	result, err := r.db.Exec(`
+		INSERT INTO group_members (group_id, user_id, added_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT DO NOTHING`, groupID, userID)
+	if err != nil {
+		return false, err
+	}
+	added, err := result.RowsAffected()
+	return added > 0, err
+}
+
+func (r *GroupRepository) RemoveMember(groupID, userID uuid.UUID) error {
+	_, err := r.db.Exec(`DELETE FROM group_members WHERE group_id = $1 AND user_id = $2`, groupID, userID)
+	return err
+}
+
+func (r *GroupRepository) ListMembers(groupID uuid.UUID) ([]*domain.GroupMember, error) {
+	members := []*domain.GroupMember{}
+	query := `
+		SELECT m.user_id, u.email, u.first_name, u.last_name, m.added_at
+		FROM group_members m
+		JOIN users u ON m.user_id = u.id
+		WHERE m.group_id = $1
+		ORDER BY u.last_name ASC, u.first_name ASC`
+
+	if err := r.db.Select(&members, query, groupID); err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+func (r *GroupRepository) ListMemberIDs(groupID uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	if err := r.db.Select(&ids, `SELECT user_id FROM group_members WHERE group_id = $1`, groupID); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+type CourseAssignmentRepository struct {
+	db *sqlx.DB
+}
+
+func NewCourseAssignmentRepository(db *sqlx.DB) *CourseAssignmentRepository {
+	return &CourseAssignmentRepository{db: db}
+}
+
+func (r *CourseAssignmentRepository) Create(assignment *domain.CourseAssignment) error {
+	query := `
+		INSERT INTO course_assignments (id, organization_id, course_id, group_id, due_at, assigned_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW(), NOW())
+		RETURNING created_at, updated_at`
+
+	if assignment.ID == uuid.Nil {
+		assignment.ID = uuid.New()
+	}
+
+	return r.db.QueryRow(
+		query,
+		assignment.ID, assignment.OrganizationID, assignment.CourseID, assignment.GroupID,
+		assignment.DueAt, assignment.AssignedBy,
+	).Scan(&assignment.CreatedAt, &assignment.UpdatedAt)
+}
+
+func (r *CourseAssignmentRepository) GetByID(id uuid.UUID) (*domain.CourseAssignment, error) {
+	var assignment domain.CourseAssignment
+	query := `SELECT id, organization_id, course_id, group_id, due_at, assigned_by, created_at, updated_at
+			  FROM course_assignments WHERE id = $1`
+
+	err := r.db.Get(&assignment, query, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &assignment, nil
+}
+
+func (r *CourseAssignmentRepository) GetByCourseAndGroup(courseID, groupID uuid.UUID) (*domain.CourseAssignment, error) {
+	var assignment domain.CourseAssignment
+	query := `SELECT id, organization_id, course_id, group_id, due_at, assigned_by, created_at, updated_at
+			  FROM course_assignments WHERE course_id = $1 AND group_id = $2`
+
+	err := r.db.Get(&assignment, query, courseID, groupID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &assignment, nil
+}
+
+func (r *CourseAssignmentRepository) ListByGroup(groupID uuid.UUID) ([]*domain.CourseAssignment, error) {
+	var assignments []*domain.CourseAssignment
+	query := `SELECT id, organization_id, course_id, group_id, due_at, assigned_by, created_at, updated_at
+			  FROM course_assignments WHERE group_id = $1 ORDER BY created_at ASC`
+
+	if err := r.db.Select(&assignments, query, groupID); err != nil {
+		return nil, err
+	}
+	return assignments, nil
+}
+
+// List returns the assignments of the tenant with their progress, those
+// due soonest first
+func (r *CourseAssignmentRepository) List(tenant domain.Tenant) ([]*domain.AssignmentProgress, error) {
+	assignments := []*domain.AssignmentProgress{}
+	query := `
+		SELECT a.id, a.organization_id, a.course_id, a.group_id, a.due_at, a.assigned_by, a.created_at, a.updated_at,
+		       c.title as course_title, g.name as group_name,
+		       COUNT(e.id) as enrolled,
+		       COUNT(e.id) FILTER (WHERE e.status = 'completed') as completed,
+		       COUNT(e.id) FILTER (WHERE e.status <> 'completed' AND e.due_at < NOW()) as overdue
+		FROM course_assignments a
+		JOIN courses c ON a.course_id = c.id
+		JOIN groups g ON a.group_id = g.id
+		LEFT JOIN enrollments e ON e.assignment_id = a.id
+		WHERE ($1 OR a.organization_id = $2)
+		GROUP BY a.id, c.title, g.name
+		ORDER BY a.due_at ASC NULLS LAST, a.created_at DESC`
+
+	if err := r.db.Select(&assignments, query, tenant.CrossTenant, tenant.OrganizationID); err != nil {
+		return nil, err
+	}
+	return assignments, nil
+}
+
+func (r *CourseAssignmentRepository) UpdateDueAt(assignment *domain.CourseAssignment) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE course_assignments SET due_at = $1, updated_at = NOW() WHERE id = $2 RETURNING updated_at`
+	if err := tx.QueryRow(query, assignment.DueAt, assignment.ID).Scan(&assignment.UpdatedAt); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE enrollments SET due_at = $1, updated_at = NOW()
+		WHERE assignment_id = $2 AND status <> 'completed'`, assignment.DueAt, assignment.ID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *CourseAssignmentRepository) Delete(id uuid.UUID) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`
+		UPDATE enrollments e SET (assignment_id, due_at) = (
+			SELECT a.id, a.due_at
+			FROM course_assignments a
+			JOIN group_members m ON m.group_id = a.group_id
+			WHERE a.course_id = e.course_id AND m.user_id = e.user_id AND a.id <> $1
+			ORDER BY a.due_at ASC NULLS LAST, a.created_at ASC
+			LIMIT 1
+		), updated_at = NOW()
+		WHERE e.assignment_id = $1`, id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM course_assignments WHERE id = $1`, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ListOverdue returns the assigned enrollments past their due date, the
+// longest overdue first
+func (r *CourseAssignmentRepository) ListOverdue(tenant domain.Tenant, limit, offset int) ([]*domain.OverdueEnrollment, error) {
+	overdue := []*domain.OverdueEnrollment{}
+	query := `
+		SELECT e.id as enrollment_id, e.user_id, u.email as user_email, u.first_name as user_first_name,
+		       u.last_name as user_last_name, e.course_id, c.title as course_title, g.name as group_name,
+		       e.progress_percentage, e.due_at
+		FROM enrollments e
+		JOIN users u ON e.user_id = u.id
+		JOIN courses c ON e.course_id = c.id
+		JOIN course_assignments a ON e.assignment_id = a.id
+		JOIN groups g ON a.group_id = g.id
+		WHERE ($1 OR e.organization_id = $2)
+		  AND e.status <> 'completed' AND e.due_at < NOW()
+		ORDER BY e.due_at ASC
+		LIMIT $3 OFFSET $4`
+
+	if err := r.db.Select(&overdue, query, tenant.CrossTenant, tenant.OrganizationID, limit, offset); err != nil {
+		return nil, err
+	}
+	return overdue, nil
+}
+
+func (r *CourseAssignmentRepository) CountOverdue(tenant domain.Tenant) (int, error) {
+	var count int
+	query := `
+		SELECT COUNT(*) FROM enrollments
+		WHERE ($1 OR organization_id = $2)
+		  AND assignment_id IS NOT NULL AND status <> 'completed' AND due_at < NOW()`
+	err := r.db.Get(&count, query, tenant.CrossTenant, tenant.OrganizationID)
+	return count, err
+}
@@ -0,0 +1,279 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/secusense/backend/internal/domain"
+)
+
+const ltiPlatformColumns = `id, name, issuer, client_id, deployment_ids, auth_login_url, auth_token_url, jwks_url, organization_id, created_at, updated_at`
+
+type LTIPlatformRepository struct {
+	db *sqlx.DB
+}
+
+func NewLTIPlatformRepository(db *sqlx.DB) *LTIPlatformRepository {
+	return &LTIPlatformRepository{db: db}
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanLTIPlatform(row rowScanner) (*domain.LTIPlatform, error) {
+	var p domain.LTIPlatform
+	err := row.Scan(&p.ID, &p.Name, &p.Issuer, &p.ClientID, pq.Array(&p.DeploymentIDs),
+		&p.AuthLoginURL, &p.AuthTokenURL, &p.JWKSURL, &p.OrganizationID, &p.CreatedAt, &p.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if p.DeploymentIDs == nil {
+		p.DeploymentIDs = []string{}
+	}
+	return &p, nil
+}
+
+func (r *LTIPlatformRepository) Create(platform *domain.LTIPlatform) error {
+	query := `
+		INSERT INTO lti_platforms (id, name, issuer, client_id, deployment_ids, auth_login_url, auth_token_url, jwks_url, organization_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW(), NOW())
+		RETURNING created_at, updated_at`
+
+	if platform.ID == uuid.Nil {
+		platform.ID = uuid.New()
+	}
+
+	return r.db.QueryRow(
+		query,
+		platform.ID, platform.Name, platform.Issuer, platform.ClientID, pq.Array(platform.DeploymentIDs),
+		platform.AuthLoginURL, platform.AuthTokenURL, platform.JWKSURL, platform.OrganizationID,
+	).Scan(&platform.CreatedAt, &platform.UpdatedAt)
+}
+
+func (r *LTIPlatformRepository) GetByID(id uuid.UUID) (*domain.LTIPlatform, error) {
+	query := `SELECT ` + ltiPlatformColumns + ` FROM lti_platforms WHERE id = $1`
+	p, err := scanLTIPlatform(r.db.QueryRow(query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return p, err
+}
+
+func (r *LTIPlatformRepository) GetByIssuer(issuer, clientID string) (*domain.LTIPlatform, error) {
+	query := `SELECT ` + ltiPlatformColumns + ` FROM lti_platforms
+			  WHERE issuer = $1 AND ($2 = '' OR client_id = $2)
+			  ORDER BY created_at ASC LIMIT 1`
+	p, err := scanLTIPlatform(r.db.QueryRow(query, issuer, clientID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return p, err
+}
+
+func (r *LTIPlatformRepository) List(tenant domain.Tenant) ([]*domain.LTIPlatform, error) {
+	query := `SELECT ` + ltiPlatformColumns + ` FROM lti_platforms WHERE ($1 OR organization_id = $2) ORDER BY name ASC`
+	rows, err := r.db.Query(query, tenant.CrossTenant, tenant.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	platforms := []*domain.LTIPlatform{}
+	for rows.Next() {
+		p, err := scanLTIPlatform(rows)
+		if err != nil {
+			return nil, err
+		}
+		platforms = append(platforms, p)
+	}
+	return platforms, rows.Err()
+}
+
+func (r *LTIPlatformRepository) Update(platform *domain.LTIPlatform) error {
+	query := `
+		UPDATE lti_platforms
+		SET name = $1, issuer = $2, client_id = $3, deployment_ids = $4, auth_login_url = $5,
+			auth_token_url = $6, jwks_url = $7, organization_id = $8, updated_at = NOW()
+		WHERE id = $9
+		RETURNING updated_at`
+
+	return r.db.QueryRow(
+		query,
+		platform.Name, platform.Issuer, platform.ClientID, pq.Array(platform.DeploymentIDs), platform.AuthLoginURL,
+		platform.AuthTokenURL, platform.JWKSURL, platform.OrganizationID, platform.ID,
+	).Scan(&platform.UpdatedAt)
+}
+
+func (r *LTIPlatformRepository) Delete(id uuid.UUID) error {
+	_, err := r.db.Exec(`DELETE FROM lti_platforms WHERE id = $1`, id)
+	return err
+}
+
+type LTIRepository struct {
+	db *sqlx.DB
+}
+
+func NewLTIRepository(db *sqlx.DB) *LTIRepository {
+	return &LTIRepository{db: db}
+}
+
+func (r *LTIRepository) GetLatestKey() (*domain.LTIToolKey, error) {
+	var key domain.LTIToolKey
+	err := r.db.Get(&key, `SELECT kid, private_key, created_at FROM lti_tool_keys ORDER BY created_at DESC LIMIT 1`)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (r *LTIRepository) ListKeys() ([]*domain.LTIToolKey, error) {
+	var keys []*domain.LTIToolKey
+	err := r.db.Select(&keys, `SELECT kid, private_key, created_at FROM lti_tool_keys ORDER BY created_at DESC`)
+	if err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (r *LTIRepository) CreateKey(key *domain.LTIToolKey) error {
+	query := `INSERT INTO lti_tool_keys (kid, private_key, created_at) VALUES ($1, $2, NOW()) RETURNING created_at`
+	return r.db.QueryRow(query, key.KID, key.PrivateKey).Scan(&key.CreatedAt)
+}
+
+func (r *LTIRepository) CreateState(state *domain.LTIState) error {
+	query := `INSERT INTO lti_states (state, nonce, platform_id, expires_at) VALUES ($1, $2, $3, $4)`
+	_, err := r.db.Exec(query, state.State, state.Nonce, state.PlatformID, state.ExpiresAt)
+	return err
+}
+
+func (r *LTIRepository) ConsumeState(state string) (*domain.LTIState, error) {
+	var s domain.LTIState
+	query := `
+		DELETE FROM lti_states WHERE state = $1 AND expires_at > NOW()
+		RETURNING state, nonce, platform_id, expires_at`
+
+	err := r.db.Get(&s, query, state)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *LTIRepository) GetUserLink(platformID uuid.UUID, subject string) (*domain.LTIUserLink, error) {
+	var link domain.LTIUserLink
+	query := `SELECT platform_id, subject, user_id, created_at FROM lti_user_links WHERE platform_id = $1 AND subject = $2`
+	err := r.db.Get(&link, query, platformID, subject)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+func (r *LTIRepository) CreateUserLink(link *domain.LTIUserLink) error {
+	query := `
+		INSERT INTO lti_user_links (platform_id, subject, user_id, created_at)
+		VALUES ($1, $2, $3, NOW())
+		RETURNING created_at`
+	return r.db.QueryRow(query, link.PlatformID, link.Subject, link.UserID).Scan(&link.CreatedAt)
+}
+
+const ltiLaunchColumns = `id, code_hash, code_used, platform_id, user_id, deployment_id, message_type, course_id,
+	deep_link_return_url, deep_link_data, expires_at, created_at`
+
+func (r *LTIRepository) CreateLaunch(launch *domain.LTILaunch) error {
+	query := `
+		INSERT INTO lti_launches (id, code_hash, platform_id, user_id, deployment_id, message_type, course_id,
+			deep_link_return_url, deep_link_data, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NOW())
+		RETURNING created_at`
+
+	if launch.ID == uuid.Nil {
+		launch.ID = uuid.New()
+	}
+
+	return r.db.QueryRow(
+		query,
+		launch.ID, launch.CodeHash, launch.PlatformID, launch.UserID, launch.DeploymentID, launch.MessageType,
+		launch.CourseID, launch.DeepLinkReturnURL, launch.DeepLinkData, launch.ExpiresAt,
+	).Scan(&launch.CreatedAt)
+}
+
+func (r *LTIRepository) GetLaunch(id uuid.UUID) (*domain.LTILaunch, error) {
+	var launch domain.LTILaunch
+	err := r.db.Get(&launch, `SELECT `+ltiLaunchColumns+` FROM lti_launches WHERE id = $1`, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &launch, nil
+}
+
+func (r *LTIRepository) ConsumeLaunchCode(codeHash string) (*domain.LTILaunch, error) {
+	var launch domain.LTILaunch
+	query := `
+		UPDATE lti_launches SET code_used = TRUE
+		WHERE code_hash = $1 AND NOT code_used AND expires_at > NOW()
+		RETURNING ` + ltiLaunchColumns
+
+	err := r.db.Get(&launch, query, codeHash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &launch, nil
+}
+
+func (r *LTIRepository) UpsertGradeLink(link *domain.LTIGradeLink) error {
+	query := `
+		INSERT INTO lti_grade_links (id, platform_id, user_id, course_id, subject, lineitem_url, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW(), NOW())
+		ON CONFLICT (platform_id, user_id, lineitem_url)
+		DO UPDATE SET course_id = EXCLUDED.course_id, subject = EXCLUDED.subject, updated_at = NOW()
+		RETURNING id, created_at, updated_at`
+
+	if link.ID == uuid.Nil {
+		link.ID = uuid.New()
+	}
+
+	return r.db.QueryRow(
+		query,
+		link.ID, link.PlatformID, link.UserID, link.CourseID, link.Subject, link.LineItemURL,
+	).Scan(&link.ID, &link.CreatedAt, &link.UpdatedAt)
+}
+
+func (r *LTIRepository) ListGradeLinks(userID, courseID uuid.UUID) ([]*domain.LTIGradeLink, error) {
+	var links []*domain.LTIGradeLink
+	query := `
+		SELECT id, platform_id, user_id, course_id, subject, lineitem_url, created_at, updated_at
+		FROM lti_grade_links WHERE user_id = $1 AND course_id = $2`
+
+	if err := r.db.Select(&links, query, userID, courseID); err != nil {
+		return nil, err
+	}
+	return links, nil
+}
+
+func (r *LTIRepository) DeleteExpired() error {
+	if _, err := r.db.Exec(`DELETE FROM lti_states WHERE expires_at < NOW()`); err != nil {
+		return err
+	}
+	_, err := r.db.Exec(`DELETE FROM lti_launches WHERE expires_at < NOW()`)
+	return err
+}
@@ -0,0 +1,88 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/secusense/backend/internal/domain"
+)
+
+type OrganizationRepository struct {
+	db *sqlx.DB
+}
+
+func NewOrganizationRepository(db *sqlx.DB) *OrganizationRepository {
+	return &OrganizationRepository{db: db}
+}
+
+func (r *OrganizationRepository) Create(org *domain.Organization) error {
+	query := `
+		INSERT INTO organizations (id, name, slug, certificate_issuer, brand_color, certificate_footer, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW(), NOW())
+		RETURNING created_at, updated_at`
+
+	if org.ID == uuid.Nil {
+		org.ID = uuid.New()
+	}
+
+	return r.db.QueryRow(
+		query,
+		org.ID, org.Name, org.Slug, org.CertificateIssuer, org.BrandColor, org.CertificateFooter,
+	).Scan(&org.CreatedAt, &org.UpdatedAt)
+}
+
+func (r *OrganizationRepository) GetByID(id uuid.UUID) (*domain.Organization, error) {
+	var org domain.Organization
+	query := `SELECT id, name, slug, certificate_issuer, brand_color, certificate_footer, created_at, updated_at
+			  FROM organizations WHERE id = $1`
+
+	err := r.db.Get(&org, query, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &org, nil
+}
+
+func (r *OrganizationRepository) GetBySlug(slug string) (*domain.Organization, error) {
+	var org domain.Organization
+	query := `SELECT id, name, slug, certificate_issuer, brand_color, certificate_footer, created_at, updated_at
+			  FROM organizations WHERE slug = $1`
+
+	err := r.db.Get(&org, query, slug)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &org, nil
+}
+
+func (r *OrganizationRepository) List() ([]*domain.Organization, error) {
+	orgs := []*domain.Organization{}
+	query := `SELECT id, name, slug, certificate_issuer, brand_color, certificate_footer, created_at, updated_at
+			  FROM organizations ORDER BY name ASC`
+
+	if err := r.db.Select(&orgs, query); err != nil {
+		return nil, err
+	}
+	return orgs, nil
+}
+
+func (r *OrganizationRepository) Update(org *domain.Organization) error {
+	query := `
+		UPDATE organizations
+		SET name = $1, certificate_issuer = $2, brand_color = $3, certificate_footer = $4, updated_at = NOW()
+		WHERE id = $5
+		RETURNING updated_at`
+
+	return r.db.QueryRow(
+		query,
+		org.Name, org.CertificateIssuer, org.BrandColor, org.CertificateFooter, org.ID,
+	).Scan(&org.UpdatedAt)
+}
@@ -0,0 +1,125 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/secusense/backend/internal/domain"
+)
+
+type RoleRepository struct {
+	db *sqlx.DB
+}
+
+func NewRoleRepository(db *sqlx.DB) *RoleRepository {
+	return &RoleRepository{db: db}
+}
+
+func (r *RoleRepository) Create(role *domain.Role) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO roles (name, description, built_in, created_at, updated_at)
+		VALUES ($1, $2, $3, NOW(), NOW())
+		RETURNING created_at, updated_at`
+	if err := tx.QueryRow(query, role.Name, role.Description, role.BuiltIn).Scan(&role.CreatedAt, &role.UpdatedAt); err != nil {
+		return err
+	}
+
+	if err := insertPermissions(tx, role); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *RoleRepository) GetByName(name domain.UserRole) (*domain.Role, error) {
+	var role domain.Role
+	query := `SELECT name, description, built_in, created_at, updated_at FROM roles WHERE name = $1`
+
+	err := r.db.Get(&role, query, name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	role.Permissions, err = r.GetPermissions(name)
+	if err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+func (r *RoleRepository) List() ([]*domain.Role, error) {
+	roles := []*domain.Role{}
+	query := `SELECT name, description, built_in, created_at, updated_at FROM roles ORDER BY built_in DESC, name ASC`
+	if err := r.db.Select(&roles, query); err != nil {
+		return nil, err
+	}
+
+	var grants []struct {
+		Role       domain.UserRole   `db:"role"`
+		Permission domain.Permission `db:"permission"`
+	}
+	if err := r.db.Select(&grants, `SELECT role, permission FROM role_permissions ORDER BY permission ASC`); err != nil {
+		return nil, err
+	}
+
+	byName := make(map[domain.UserRole]*domain.Role, len(roles))
+	for _, role := range roles {
+		role.Permissions = []domain.Permission{}
+		byName[role.Name] = role
+	}
+	for _, g := range grants {
+		if role, ok := byName[g.Role]; ok {
+			role.Permissions = append(role.Permissions, g.Permission)
+		}
+	}
+	return roles, nil
+}
+
+// Update saves the description and replaces the permissions of a role
+func (r *RoleRepository) Update(role *domain.Role) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE roles SET description = $1, updated_at = NOW() WHERE name = $2 RETURNING updated_at`
+	if err := tx.QueryRow(query, role.Description, role.Name).Scan(&role.UpdatedAt); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM role_permissions WHERE role = $1`, role.Name); err != nil {
+		return err
+	}
+	if err := insertPermissions(tx, role); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *RoleRepository) GetPermissions(name domain.UserRole) ([]domain.Permission, error) {
+	permissions := []domain.Permission{}
+	query := `SELECT permission FROM role_permissions WHERE role = $1 ORDER BY permission ASC`
+	if err := r.db.Select(&permissions, query, name); err != nil {
+		return nil, err
+	}
+	return permissions, nil
+}
+
+func insertPermissions(tx *sqlx.Tx, role *domain.Role) error {
+	for _, p := range role.Permissions {
+		if _, err := tx.Exec(`INSERT INTO role_permissions (role, permission) VALUES ($1, $2) ON CONFLICT DO NOTHING`, role.Name, p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
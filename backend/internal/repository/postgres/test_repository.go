@@ -3,12 +3,17 @@ package postgres
 import (
 	"database/sql"
 	"errors"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
 	"github.com/secusense/backend/internal/domain"
 )
 
+const testColumns = `id, course_id, title, description, time_limit_minutes, passing_score,
+	question_count, stratify_by, shuffle_questions, shuffle_options,
+	max_attempts, cooldown_minutes, lockout_after_failures, scoring_policy, allow_negative, created_at, updated_at`
+
 type TestRepository struct {
 	db *sqlx.DB
 }
@@ -19,24 +24,30 @@ func NewTestRepository(db *sqlx.DB) *TestRepository {
 
 func (r *TestRepository) Create(test *domain.Test) error {
 	query := `
-		INSERT INTO tests (id, course_id, title, description, time_limit_minutes, passing_score, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, NOW(), NOW())
+		INSERT INTO tests (id, course_id, title, description, time_limit_minutes, passing_score,
+			question_count, stratify_by, shuffle_questions, shuffle_options,
+			max_attempts, cooldown_minutes, lockout_after_failures, scoring_policy, allow_negative, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, NOW(), NOW())
 		RETURNING created_at, updated_at`
 
 	if test.ID == uuid.Nil {
 		test.ID = uuid.New()
 	}
+	if test.StratifyBy == "" {
+		test.StratifyBy = domain.StratifyNone
+	}
 
 	return r.db.QueryRow(
 		query,
 		test.ID, test.CourseID, test.Title, test.Description, test.TimeLimitMinutes, test.PassingScore,
+		test.QuestionCount, test.StratifyBy, test.ShuffleQuestions, test.ShuffleOptions,
+		test.MaxAttempts, test.CooldownMinutes, test.LockoutAfterFailures, test.ScoringPolicy, test.AllowNegative,
 	).Scan(&test.CreatedAt, &test.UpdatedAt)
 }
 
 func (r *TestRepository) GetByID(id uuid.UUID) (*domain.Test, error) {
 	var test domain.Test
-	query := `SELECT id, course_id, title, description, time_limit_minutes, passing_score, created_at, updated_at
-			  FROM tests WHERE id = $1`
+	query := `SELECT ` + testColumns + ` FROM tests WHERE id = $1`
 
 	err := r.db.Get(&test, query, id)
 	if errors.Is(err, sql.ErrNoRows) {
@@ -50,8 +61,7 @@ func (r *TestRepository) GetByID(id uuid.UUID) (*domain.Test, error) {
 
 func (r *TestRepository) GetByCourseID(courseID uuid.UUID) (*domain.Test, error) {
 	var test domain.Test
-	query := `SELECT id, course_id, title, description, time_limit_minutes, passing_score, created_at, updated_at
-			  FROM tests WHERE course_id = $1`
+	query := `SELECT ` + testColumns + ` FROM tests WHERE course_id = $1`
 
 	err := r.db.Get(&test, query, courseID)
 	if errors.Is(err, sql.ErrNoRows) {
@@ -66,13 +76,19 @@ func (r *TestRepository) GetByCourseID(courseID uuid.UUID) (*domain.Test, error)
 func (r *TestRepository) Update(test *domain.Test) error {
 	query := `
 		UPDATE tests
-		SET title = $1, description = $2, time_limit_minutes = $3, passing_score = $4, updated_at = NOW()
-		WHERE id = $5
+		SET title = $1, description = $2, time_limit_minutes = $3, passing_score = $4,
+			question_count = $5, stratify_by = $6, shuffle_questions = $7, shuffle_options = $8,
+			max_attempts = $9, cooldown_minutes = $10, lockout_after_failures = $11,
+			scoring_policy = $12, allow_negative = $13, updated_at = NOW()
+		WHERE id = $14
 		RETURNING updated_at`
 
 	return r.db.QueryRow(
 		query,
-		test.Title, test.Description, test.TimeLimitMinutes, test.PassingScore, test.ID,
+		test.Title, test.Description, test.TimeLimitMinutes, test.PassingScore,
+		test.QuestionCount, test.StratifyBy, test.ShuffleQuestions, test.ShuffleOptions,
+		test.MaxAttempts, test.CooldownMinutes, test.LockoutAfterFailures,
+		test.ScoringPolicy, test.AllowNegative, test.ID,
 	).Scan(&test.UpdatedAt)
 }
 
@@ -82,6 +98,9 @@ func (r *TestRepository) Delete(id uuid.UUID) error {
 	return err
 }
 
+const questionColumns = `id, test_id, question_type, question_text, question_data, points, order_index, tags,
+	scoring_policy, allow_negative, created_at`
+
 type QuestionRepository struct {
 	db *sqlx.DB
 }
@@ -92,8 +111,9 @@ func NewQuestionRepository(db *sqlx.DB) *QuestionRepository {
 
 func (r *QuestionRepository) Create(question *domain.Question) error {
 	query := `
-		INSERT INTO questions (id, test_id, question_type, question_text, question_data, points, order_index, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())
+		INSERT INTO questions (id, test_id, question_type, question_text, question_data, points, order_index, tags,
+			scoring_policy, allow_negative, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, COALESCE($8, '[]'::jsonb), $9, $10, NOW())
 		RETURNING created_at`
 
 	if question.ID == uuid.Nil {
@@ -103,13 +123,14 @@ func (r *QuestionRepository) Create(question *domain.Question) error {
 	return r.db.QueryRow(
 		query,
 		question.ID, question.TestID, question.QuestionType, question.QuestionText,
-		question.QuestionData, question.Points, question.OrderIndex,
+		question.QuestionData, question.Points, question.OrderIndex, question.Tags,
+		question.ScoringPolicy, question.AllowNegative,
 	).Scan(&question.CreatedAt)
 }
 
 func (r *QuestionRepository) GetByID(id uuid.UUID) (*domain.Question, error) {
 	var question domain.Question
-	query := `SELECT id, test_id, question_type, question_text, question_data, points, order_index, created_at
+	query := `SELECT ` + questionColumns + `
 			  FROM questions WHERE id = $1`
 
 	err := r.db.Get(&question, query, id)
@@ -124,7 +145,7 @@ func (r *QuestionRepository) GetByID(id uuid.UUID) (*domain.Question, error) {
 
 func (r *QuestionRepository) GetByTestID(testID uuid.UUID) ([]*domain.Question, error) {
 	var questions []*domain.Question
-	query := `SELECT id, test_id, question_type, question_text, question_data, points, order_index, created_at
+	query := `SELECT ` + questionColumns + `
 			  FROM questions WHERE test_id = $1 ORDER BY order_index ASC`
 
 	err := r.db.Select(&questions, query, testID)
@@ -137,11 +158,12 @@ func (r *QuestionRepository) GetByTestID(testID uuid.UUID) ([]*domain.Question,
 func (r *QuestionRepository) Update(question *domain.Question) error {
 	query := `
 		UPDATE questions
-		SET question_type = $1, question_text = $2, question_data = $3, points = $4, order_index = $5
-		WHERE id = $6`
+		SET question_type = $1, question_text = $2, question_data = $3, points = $4, order_index = $5,
+			tags = COALESCE($6, '[]'::jsonb), scoring_policy = $7, allow_negative = $8
+		WHERE id = $9`
 
 	_, err := r.db.Exec(query, question.QuestionType, question.QuestionText, question.QuestionData,
-		question.Points, question.OrderIndex, question.ID)
+		question.Points, question.OrderIndex, question.Tags, question.ScoringPolicy, question.AllowNegative, question.ID)
 	return err
 }
 
@@ -157,6 +179,9 @@ func (r *QuestionRepository) DeleteByTestID(testID uuid.UUID) error {
 	return err
 }
 
+const attemptColumns = `id, user_id, test_id, started_at, completed_at, score, max_score, percentage, passed,
+	deadline_at, auto_submitted, COALESCE(question_set, '[]') AS question_set`
+
 type TestAttemptRepository struct {
 	db *sqlx.DB
 }
@@ -167,21 +192,20 @@ func NewTestAttemptRepository(db *sqlx.DB) *TestAttemptRepository {
 
 func (r *TestAttemptRepository) Create(attempt *domain.TestAttempt) error {
 	query := `
-		INSERT INTO test_attempts (id, user_id, test_id, started_at)
-		VALUES ($1, $2, $3, NOW())
+		INSERT INTO test_attempts (id, user_id, test_id, question_set, deadline_at, started_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
 		RETURNING started_at`
 
 	if attempt.ID == uuid.Nil {
 		attempt.ID = uuid.New()
 	}
 
-	return r.db.QueryRow(query, attempt.ID, attempt.UserID, attempt.TestID).Scan(&attempt.StartedAt)
+	return r.db.QueryRow(query, attempt.ID, attempt.UserID, attempt.TestID, attempt.QuestionSet, attempt.DeadlineAt).Scan(&attempt.StartedAt)
 }
 
 func (r *TestAttemptRepository) GetByID(id uuid.UUID) (*domain.TestAttempt, error) {
 	var attempt domain.TestAttempt
-	query := `SELECT id, user_id, test_id, started_at, completed_at, score, max_score, percentage, passed
-			  FROM test_attempts WHERE id = $1`
+	query := `SELECT ` + attemptColumns + ` FROM test_attempts WHERE id = $1`
 
 	err := r.db.Get(&attempt, query, id)
 	if errors.Is(err, sql.ErrNoRows) {
@@ -195,7 +219,7 @@ func (r *TestAttemptRepository) GetByID(id uuid.UUID) (*domain.TestAttempt, erro
 
 func (r *TestAttemptRepository) GetByUserAndTest(userID, testID uuid.UUID) ([]*domain.TestAttempt, error) {
 	var attempts []*domain.TestAttempt
-	query := `SELECT id, user_id, test_id, started_at, completed_at, score, max_score, percentage, passed
+	query := `SELECT ` + attemptColumns + `
 			  FROM test_attempts WHERE user_id = $1 AND test_id = $2 ORDER BY started_at DESC`
 
 	err := r.db.Select(&attempts, query, userID, testID)
@@ -207,7 +231,7 @@ func (r *TestAttemptRepository) GetByUserAndTest(userID, testID uuid.UUID) ([]*d
 
 func (r *TestAttemptRepository) GetLatestByUserAndTest(userID, testID uuid.UUID) (*domain.TestAttempt, error) {
 	var attempt domain.TestAttempt
-	query := `SELECT id, user_id, test_id, started_at, completed_at, score, max_score, percentage, passed
+	query := `SELECT ` + attemptColumns + `
 			  FROM test_attempts WHERE user_id = $1 AND test_id = $2 ORDER BY started_at DESC LIMIT 1`
 
 	err := r.db.Get(&attempt, query, userID, testID)
@@ -231,6 +255,119 @@ func (r *TestAttemptRepository) Update(attempt *domain.TestAttempt) error {
 	return err
 }
 
+func (r *TestAttemptRepository) Complete(attempt *domain.TestAttempt, answers []*domain.UserAnswer) (bool, error) {
+	query := `
+		UPDATE test_attempts
+		SET completed_at = $1, score = $2, max_score = $3, percentage = $4, passed = $5, auto_submitted = $6
+		WHERE id = $7 AND completed_at IS NULL`
+
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return false, err
+	}
+
+	result, err := tx.Exec(query, attempt.CompletedAt, attempt.Score, attempt.MaxScore,
+		attempt.Percentage, attempt.Passed, attempt.AutoSubmitted, attempt.ID)
+	if err != nil {
+		tx.Rollback()
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil || rows != 1 {
+		tx.Rollback()
+		return false, err
+	}
+
+	if err := replaceAnswers(tx, attempt.ID, answers); err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+// replaceAnswers swaps all answers of an attempt for the given ones
+func replaceAnswers(tx *sqlx.Tx, attemptID uuid.UUID, answers []*domain.UserAnswer) error {
+	query := `
+		INSERT INTO user_answers (id, attempt_id, question_id, answer_data, is_correct, points_awarded,
+			time_spent_seconds, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())`
+
+	if _, err := tx.Exec(`DELETE FROM user_answers WHERE attempt_id = $1`, attemptID); err != nil {
+		return err
+	}
+
+	for _, answer := range answers {
+		if answer.ID == uuid.Nil {
+			answer.ID = uuid.New()
+		}
+		_, err := tx.Exec(query, answer.ID, attemptID, answer.QuestionID,
+			answer.AnswerData, answer.IsCorrect, answer.PointsAwarded, answer.TimeSpentSeconds)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *TestAttemptRepository) ListExpired(before time.Time, limit int) ([]*domain.TestAttempt, error) {
+	var attempts []*domain.TestAttempt
+	query := `SELECT ` + attemptColumns + `
+			  FROM test_attempts WHERE completed_at IS NULL AND deadline_at < $1
+			  ORDER BY deadline_at ASC LIMIT $2`
+
+	err := r.db.Select(&attempts, query, before, limit)
+	if err != nil {
+		return nil, err
+	}
+	return attempts, nil
+}
+
+func (r *TestAttemptRepository) ListCompletedByTest(testID uuid.UUID) ([]*domain.TestAttempt, error) {
+	var attempts []*domain.TestAttempt
+	query := `SELECT ` + attemptColumns + `
+			  FROM test_attempts WHERE test_id = $1 AND completed_at IS NOT NULL
+			  ORDER BY completed_at ASC`
+
+	err := r.db.Select(&attempts, query, testID)
+	if err != nil {
+		return nil, err
+	}
+	return attempts, nil
+}
+
+type TestAttemptGrantRepository struct {
+	db *sqlx.DB
+}
+
+func NewTestAttemptGrantRepository(db *sqlx.DB) *TestAttemptGrantRepository {
+	return &TestAttemptGrantRepository{db: db}
+}
+
+func (r *TestAttemptGrantRepository) Create(grant *domain.TestAttemptGrant) error {
+	query := `
+		INSERT INTO test_attempt_grants (id, test_id, user_id, extra_attempts, reason, granted_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW())
+		RETURNING created_at`
+
+	if grant.ID == uuid.Nil {
+		grant.ID = uuid.New()
+	}
+
+	return r.db.QueryRow(
+		query,
+		grant.ID, grant.TestID, grant.UserID, grant.ExtraAttempts, grant.Reason, grant.GrantedBy,
+	).Scan(&grant.CreatedAt)
+}
+
+func (r *TestAttemptGrantRepository) SumExtraAttempts(userID, testID uuid.UUID) (int, error) {
+	var total int
+	query := `SELECT COALESCE(SUM(extra_attempts), 0) FROM test_attempt_grants WHERE user_id = $1 AND test_id = $2`
+
+	err := r.db.Get(&total, query, userID, testID)
+	return total, err
+}
+
 type UserAnswerRepository struct {
 	db *sqlx.DB
 }
@@ -239,10 +376,14 @@ func NewUserAnswerRepository(db *sqlx.DB) *UserAnswerRepository {
 	return &UserAnswerRepository{db: db}
 }
 
+const userAnswerColumns = `id, attempt_id, question_id, answer_data, is_correct, points_awarded,
+	time_spent_seconds, created_at`
+
 func (r *UserAnswerRepository) Create(answer *domain.UserAnswer) error {
 	query := `
-		INSERT INTO user_answers (id, attempt_id, question_id, answer_data, is_correct, points_awarded, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, NOW())
+		INSERT INTO user_answers (id, attempt_id, question_id, answer_data, is_correct, points_awarded,
+			time_spent_seconds, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())
 		RETURNING created_at`
 
 	if answer.ID == uuid.Nil {
@@ -252,13 +393,15 @@ func (r *UserAnswerRepository) Create(answer *domain.UserAnswer) error {
 	return r.db.QueryRow(
 		query,
 		answer.ID, answer.AttemptID, answer.QuestionID, answer.AnswerData, answer.IsCorrect, answer.PointsAwarded,
+		answer.TimeSpentSeconds,
 	).Scan(&answer.CreatedAt)
 }
 
 func (r *UserAnswerRepository) CreateBatch(answers []*domain.UserAnswer) error {
 	query := `
-		INSERT INTO user_answers (id, attempt_id, question_id, answer_data, is_correct, points_awarded, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, NOW())`
+		INSERT INTO user_answers (id, attempt_id, question_id, answer_data, is_correct, points_awarded,
+			time_spent_seconds, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())`
 
 	tx, err := r.db.Beginx()
 	if err != nil {
@@ -270,7 +413,7 @@ func (r *UserAnswerRepository) CreateBatch(answers []*domain.UserAnswer) error {
 			answer.ID = uuid.New()
 		}
 		_, err = tx.Exec(query, answer.ID, answer.AttemptID, answer.QuestionID,
-			answer.AnswerData, answer.IsCorrect, answer.PointsAwarded)
+			answer.AnswerData, answer.IsCorrect, answer.PointsAwarded, answer.TimeSpentSeconds)
 		if err != nil {
 			tx.Rollback()
 			return err
@@ -280,10 +423,30 @@ func (r *UserAnswerRepository) CreateBatch(answers []*domain.UserAnswer) error {
 	return tx.Commit()
 }
 
+func (r *UserAnswerRepository) Upsert(answer *domain.UserAnswer) error {
+	query := `
+		INSERT INTO user_answers (id, attempt_id, question_id, answer_data, is_correct, points_awarded,
+			time_spent_seconds, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())
+		ON CONFLICT (attempt_id, question_id) DO UPDATE
+		SET answer_data = EXCLUDED.answer_data, is_correct = EXCLUDED.is_correct, points_awarded = EXCLUDED.points_awarded,
+			time_spent_seconds = COALESCE(EXCLUDED.time_spent_seconds, user_answers.time_spent_seconds)
+		RETURNING id, created_at`
+
+	if answer.ID == uuid.Nil {
+		answer.ID = uuid.New()
+	}
+
+	return r.db.QueryRow(
+		query,
+		answer.ID, answer.AttemptID, answer.QuestionID, answer.AnswerData, answer.IsCorrect, answer.PointsAwarded,
+		answer.TimeSpentSeconds,
+	).Scan(&answer.ID, &answer.CreatedAt)
+}
+
 func (r *UserAnswerRepository) GetByAttemptID(attemptID uuid.UUID) ([]*domain.UserAnswer, error) {
 	var answers []*domain.UserAnswer
-	query := `SELECT id, attempt_id, question_id, answer_data, is_correct, points_awarded, created_at
-			  FROM user_answers WHERE attempt_id = $1`
+	query := `SELECT ` + userAnswerColumns + ` FROM user_answers WHERE attempt_id = $1`
 
 	err := r.db.Select(&answers, query, attemptID)
 	if err != nil {
@@ -291,3 +454,126 @@ func (r *UserAnswerRepository) GetByAttemptID(attemptID uuid.UUID) ([]*domain.Us
 	}
 	return answers, nil
 }
+
+func (r *UserAnswerRepository) UpdateGrade(answer *domain.UserAnswer) error {
+	query := `UPDATE user_answers SET is_correct = $1, points_awarded = $2 WHERE id = $3`
+
+	_, err := r.db.Exec(query, answer.IsCorrect, answer.PointsAwarded, answer.ID)
+	return err
+}
+
+func (r *UserAnswerRepository) GetByTestID(testID uuid.UUID) ([]*domain.UserAnswer, error) {
+	var answers []*domain.UserAnswer
+	query := `SELECT ua.id, ua.attempt_id, ua.question_id, ua.answer_data, ua.is_correct, ua.points_awarded,
+			  ua.time_spent_seconds, ua.created_at
+			  FROM user_answers ua
+			  JOIN test_attempts ta ON ua.attempt_id = ta.id
+			  WHERE ta.test_id = $1 AND ta.completed_at IS NOT NULL`
+
+	err := r.db.Select(&answers, query, testID)
+	if err != nil {
+		return nil, err
+	}
+	return answers, nil
+}
+
+type AnswerReviewRepository struct {
+	db *sqlx.DB
+}
+
+func NewAnswerReviewRepository(db *sqlx.DB) *AnswerReviewRepository {
+	return &AnswerReviewRepository{db: db}
+}
+
+const answerReviewColumns = `ar.id, ar.user_answer_id, ar.attempt_id, ar.question_id, ar.status,
+	ar.suggested_points, ar.rationale, ar.confidence, ar.final_points, ar.review_note,
+	ar.reviewed_by, ar.reviewed_at, ar.created_at,
+	q.question_text, q.points AS max_points, ua.answer_data, u.email AS user_email`
+
+const answerReviewJoins = `
+	FROM answer_reviews ar
+	JOIN questions q ON ar.question_id = q.id
+	JOIN user_answers ua ON ar.user_answer_id = ua.id
+	JOIN test_attempts ta ON ar.attempt_id = ta.id
+	JOIN users u ON ta.user_id = u.id`
+
+func (r *AnswerReviewRepository) Create(review *domain.AnswerReview) error {
+	query := `
+		INSERT INTO answer_reviews (id, user_answer_id, attempt_id, question_id, status,
+			suggested_points, rationale, confidence, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW())
+		RETURNING created_at`
+
+	if review.ID == uuid.Nil {
+		review.ID = uuid.New()
+	}
+
+	return r.db.QueryRow(
+		query,
+		review.ID, review.UserAnswerID, review.AttemptID, review.QuestionID, review.Status,
+		review.SuggestedPoints, review.Rationale, review.Confidence,
+	).Scan(&review.CreatedAt)
+}
+
+func (r *AnswerReviewRepository) GetByID(id uuid.UUID) (*domain.AnswerReview, error) {
+	var review domain.AnswerReview
+	query := `SELECT ` + answerReviewColumns + answerReviewJoins + ` WHERE ar.id = $1`
+
+	err := r.db.Get(&review, query, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+func (r *AnswerReviewRepository) ListByStatus(tenant domain.Tenant, status domain.ReviewStatus, limit, offset int) ([]*domain.AnswerReview, error) {
+	var reviews []*domain.AnswerReview
+	query := `SELECT ` + answerReviewColumns + answerReviewJoins + `
+		JOIN tests t ON ta.test_id = t.id
+		JOIN courses c ON t.course_id = c.id
+		WHERE ($1 OR c.organization_id = $2) AND ar.status = $3
+		ORDER BY ar.created_at
+		LIMIT $4 OFFSET $5`
+
+	err := r.db.Select(&reviews, query, tenant.CrossTenant, tenant.OrganizationID, status, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return reviews, nil
+}
+
+func (r *AnswerReviewRepository) GetByAttemptID(attemptID uuid.UUID) ([]*domain.AnswerReview, error) {
+	var reviews []*domain.AnswerReview
+	query := `SELECT ` + answerReviewColumns + answerReviewJoins + ` WHERE ar.attempt_id = $1`
+
+	err := r.db.Select(&reviews, query, attemptID)
+	if err != nil {
+		return nil, err
+	}
+	return reviews, nil
+}
+
+func (r *AnswerReviewRepository) Judge(review *domain.AnswerReview) error {
+	query := `
+		UPDATE answer_reviews
+		SET status = $1, suggested_points = $2, rationale = $3, confidence = $4
+		WHERE id = $5`
+
+	_, err := r.db.Exec(query, review.Status, review.SuggestedPoints, review.Rationale,
+		review.Confidence, review.ID)
+	return err
+}
+
+func (r *AnswerReviewRepository) Resolve(review *domain.AnswerReview) error {
+	query := `
+		UPDATE answer_reviews
+		SET status = $1, final_points = $2, review_note = $3, reviewed_by = $4, reviewed_at = $5
+		WHERE id = $6`
+
+	_, err := r.db.Exec(query, review.Status, review.FinalPoints, review.ReviewNote,
+		review.ReviewedBy, review.ReviewedAt, review.ID)
+	return err
+}
@@ -19,8 +19,8 @@ func NewUserRepository(db *sqlx.DB) *UserRepository {
 
 func (r *UserRepository) Create(user *domain.User) error {
 	query := `
-		INSERT INTO users (id, email, password_hash, first_name, last_name, role, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, NOW(), NOW())
+		INSERT INTO users (id, email, password_hash, first_name, last_name, role, organization_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), NOW())
 		RETURNING created_at, updated_at`
 
 	if user.ID == uuid.Nil {
@@ -29,13 +29,13 @@ func (r *UserRepository) Create(user *domain.User) error {
 
 	return r.db.QueryRow(
 		query,
-		user.ID, user.Email, user.PasswordHash, user.FirstName, user.LastName, user.Role,
+		user.ID, user.Email, user.PasswordHash, user.FirstName, user.LastName, user.Role, user.OrganizationID,
 	).Scan(&user.CreatedAt, &user.UpdatedAt)
 }
 
 func (r *UserRepository) GetByID(id uuid.UUID) (*domain.User, error) {
 	var user domain.User
-	query := `SELECT id, email, password_hash, first_name, last_name, role, created_at, updated_at
+	query := `SELECT id, email, password_hash, first_name, last_name, role, organization_id, created_at, updated_at
 			  FROM users WHERE id = $1`
 
 	err := r.db.Get(&user, query, id)
@@ -50,7 +50,7 @@ func (r *UserRepository) GetByID(id uuid.UUID) (*domain.User, error) {
 
 func (r *UserRepository) GetByEmail(email string) (*domain.User, error) {
 	var user domain.User
-	query := `SELECT id, email, password_hash, first_name, last_name, role, created_at, updated_at
+	query := `SELECT id, email, password_hash, first_name, last_name, role, organization_id, created_at, updated_at
 			  FROM users WHERE email = $1`
 
 	err := r.db.Get(&user, query, email)
@@ -84,7 +84,7 @@ func (r *UserRepository) Delete(id uuid.UUID) error {
 
 func (r *UserRepository) List(limit, offset int) ([]*domain.User, error) {
 	var users []*domain.User
-	query := `SELECT id, email, password_hash, first_name, last_name, role, created_at, updated_at
+	query := `SELECT id, email, password_hash, first_name, last_name, role, organization_id, created_at, updated_at
 			  FROM users ORDER BY created_at DESC LIMIT $1 OFFSET $2`
 
 	err := r.db.Select(&users, query, limit, offset)
@@ -20,12 +20,12 @@ func NewWorkflowRepository(db *sqlx.DB) *WorkflowRepository {
 
 func (r *WorkflowRepository) CreateSession(session *domain.CourseWorkflowSession) error {
 	query := `
-		INSERT INTO course_workflow_sessions (id, main_topic, target_audience, difficulty_level, language, video_duration_min, current_step, status)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		INSERT INTO course_workflow_sessions (id, main_topic, target_audience, difficulty_level, language, video_duration_min, current_step, status, organization_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 		RETURNING created_at, updated_at`
 	return r.db.QueryRow(query,
 		session.ID, session.MainTopic, session.TargetAudience, session.DifficultyLevel,
-		session.Language, session.VideoDurationMin, session.CurrentStep, session.Status,
+		session.Language, session.VideoDurationMin, session.CurrentStep, session.Status, session.OrganizationID,
 	).Scan(&session.CreatedAt, &session.UpdatedAt)
 }
 
@@ -0,0 +1,101 @@
+package postgres
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/secusense/backend/internal/domain"
+)
+
+const xapiOutboxColumns = `id, statement, status, attempts, next_attempt_at, last_error, sent_at, created_at`
+
+type XAPIOutboxRepository struct {
+	db *sqlx.DB
+}
+
+func NewXAPIOutboxRepository(db *sqlx.DB) *XAPIOutboxRepository {
+	return &XAPIOutboxRepository{db: db}
+}
+
+func (r *XAPIOutboxRepository) Create(entry *domain.XAPIOutboxEntry) error {
+	query := `
+		INSERT INTO xapi_outbox (id, statement, status, next_attempt_at, created_at)
+		VALUES ($1, $2, $3, NOW(), NOW())
+		RETURNING next_attempt_at, created_at`
+
+	if entry.ID == uuid.Nil {
+		entry.ID = uuid.New()
+	}
+	if entry.Status == "" {
+		entry.Status = domain.XAPIStatusPending
+	}
+
+	return r.db.QueryRow(query, entry.ID, entry.Statement, entry.Status).
+		Scan(&entry.NextAttemptAt, &entry.CreatedAt)
+}
+
+// ClaimDue counts an attempt for each claimed entry. SKIP LOCKED lets several
+// instances relay statements without sending the same ones.
+func (r *XAPIOutboxRepository) ClaimDue(limit int, leaseFor time.Duration) ([]*domain.XAPIOutboxEntry, error) {
+	var entries []*domain.XAPIOutboxEntry
+	query := `
+		UPDATE xapi_outbox
+		SET attempts = attempts + 1, next_attempt_at = NOW() + make_interval(secs => $1)
+		WHERE id IN (
+			SELECT id FROM xapi_outbox
+			WHERE status = 'pending' AND next_attempt_at <= NOW()
+			ORDER BY created_at ASC
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + xapiOutboxColumns
+
+	if err := r.db.Select(&entries, query, leaseFor.Seconds(), limit); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (r *XAPIOutboxRepository) MarkSent(ids []uuid.UUID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = id.String()
+	}
+
+	query := `
+		UPDATE xapi_outbox
+		SET status = 'sent', last_error = NULL, sent_at = NOW()
+		WHERE id = ANY($1::uuid[])`
+
+	_, err := r.db.Exec(query, pq.Array(keys))
+	return err
+}
+
+// Retry schedules another attempt for an entry the LRS didn't accept
+func (r *XAPIOutboxRepository) Retry(id uuid.UUID, nextAttemptAt time.Time, errMsg string) error {
+	query := `UPDATE xapi_outbox SET last_error = $1, next_attempt_at = $2 WHERE id = $3 AND status = 'pending'`
+	_, err := r.db.Exec(query, errMsg, nextAttemptAt, id)
+	return err
+}
+
+// Fail gives up on an entry the LRS rejected as invalid
+func (r *XAPIOutboxRepository) Fail(id uuid.UUID, errMsg string) error {
+	query := `UPDATE xapi_outbox SET status = 'failed', last_error = $1 WHERE id = $2`
+	_, err := r.db.Exec(query, errMsg, id)
+	return err
+}
+
+// DeleteSentBefore purges statements the LRS accepted before t. Failed ones
+// are kept for inspection.
+func (r *XAPIOutboxRepository) DeleteSentBefore(t time.Time) (int64, error) {
+	result, err := r.db.Exec(`DELETE FROM xapi_outbox WHERE status = 'sent' AND sent_at < $1`, t)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
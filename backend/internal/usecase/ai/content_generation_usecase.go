@@ -5,18 +5,19 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"time"
 
 	"github.com/google/uuid"
+	"github.com/secusense/backend/infrastructure/queue"
 	"github.com/secusense/backend/internal/domain"
 )
 
 var (
-	ErrJobNotFound = errors.New("job not found")
-	ErrJobFailed   = errors.New("job failed")
+	ErrJobNotFound    = errors.New("job not found")
+	ErrJobFailed      = errors.New("job failed")
+	ErrCourseNotFound = errors.New("course not found")
 )
 
-type OllamaClient interface {
+type LLMClient interface {
 	GenerateCourseContent(ctx context.Context, req *domain.GenerateCourseRequest) (*domain.GeneratedCourseContent, error)
 }
 
@@ -31,8 +32,9 @@ type UseCase struct {
 	contentRepo    domain.CourseContentRepository
 	testRepo       domain.TestRepository
 	questionRepo   domain.QuestionRepository
-	ollamaClient   OllamaClient
+	llmClient      LLMClient
 	synthesiaClient SynthesiaClient
+	jobQueue        *queue.Queue
 }
 
 func NewUseCase(
@@ -41,58 +43,44 @@ func NewUseCase(
 	contentRepo domain.CourseContentRepository,
 	testRepo domain.TestRepository,
 	questionRepo domain.QuestionRepository,
-	ollamaClient OllamaClient,
+	llmClient LLMClient,
 	synthesiaClient SynthesiaClient,
+	jobQueue *queue.Queue,
 ) *UseCase {
-	return &UseCase{
+	uc := &UseCase{
 		jobRepo:         jobRepo,
 		courseRepo:      courseRepo,
 		contentRepo:     contentRepo,
 		testRepo:        testRepo,
 		questionRepo:    questionRepo,
-		ollamaClient:    ollamaClient,
+		llmClient:       llmClient,
 		synthesiaClient: synthesiaClient,
+		jobQueue:        jobQueue,
 	}
-}
-
-func (uc *UseCase) GenerateCourse(ctx context.Context, req *domain.GenerateCourseRequest) (*domain.AIGenerationJob, error) {
-	inputData, _ := json.Marshal(req)
 
-	job := &domain.AIGenerationJob{
-		ID:        uuid.New(),
-		JobType:   domain.JobTypeContent,
-		Status:    domain.JobStatusPending,
-		InputData: inputData,
-	}
+	jobQueue.Register(domain.JobTypeContent, uc.runContentGenerationJob, nil)
 
-	if err := uc.jobRepo.Create(job); err != nil {
-		return nil, err
-	}
+	return uc
+}
 
-	// Process job asynchronously
-	go uc.processContentGeneration(context.Background(), job.ID, req)
+func (uc *UseCase) GenerateCourse(ctx context.Context, tenant domain.Tenant, req *domain.GenerateCourseRequest) (*domain.AIGenerationJob, error) {
+	owner := tenant.Owner(req.OrganizationID)
+	req.OrganizationID = &owner
 
-	return job, nil
+	// Processed by the background job queue
+	return uc.jobQueue.Enqueue(domain.JobTypeContent, req)
 }
 
-func (uc *UseCase) processContentGeneration(ctx context.Context, jobID uuid.UUID, req *domain.GenerateCourseRequest) {
-	job, _ := uc.jobRepo.GetByID(jobID)
-	if job == nil {
-		return
+func (uc *UseCase) runContentGenerationJob(ctx context.Context, job *domain.AIGenerationJob) error {
+	var req domain.GenerateCourseRequest
+	if err := queue.DecodePayload(job, &req); err != nil {
+		return err
 	}
 
-	// Update status to processing
-	job.Status = domain.JobStatusProcessing
-	uc.jobRepo.Update(job)
-
-	// Generate content with Ollama
-	content, err := uc.ollamaClient.GenerateCourseContent(ctx, req)
+	// Generate content with the LLM provider
+	content, err := uc.llmClient.GenerateCourseContent(ctx, &req)
 	if err != nil {
-		errStr := err.Error()
-		job.Status = domain.JobStatusFailed
-		job.Error = &errStr
-		uc.jobRepo.Update(job)
-		return
+		return err
 	}
 
 	// Create course
@@ -102,13 +90,10 @@ func (uc *UseCase) processContentGeneration(ctx context.Context, jobID uuid.UUID
 		Description:    content.Description,
 		PassPercentage: 70,
 		IsPublished:    false,
+		OrganizationID: generationOrganization(&req),
 	}
 	if err := uc.courseRepo.Create(course); err != nil {
-		errStr := err.Error()
-		job.Status = domain.JobStatusFailed
-		job.Error = &errStr
-		uc.jobRepo.Update(job)
-		return
+		return err
 	}
 
 	// Save course content
@@ -121,11 +106,7 @@ func (uc *UseCase) processContentGeneration(ctx context.Context, jobID uuid.UUID
 		GenerationPrompt: req.Topic,
 	}
 	if err := uc.contentRepo.Create(courseContent); err != nil {
-		errStr := err.Error()
-		job.Status = domain.JobStatusFailed
-		job.Error = &errStr
-		uc.jobRepo.Update(job)
-		return
+		return err
 	}
 
 	// Create test
@@ -137,11 +118,7 @@ func (uc *UseCase) processContentGeneration(ctx context.Context, jobID uuid.UUID
 		PassingScore: 70,
 	}
 	if err := uc.testRepo.Create(test); err != nil {
-		errStr := err.Error()
-		job.Status = domain.JobStatusFailed
-		job.Error = &errStr
-		uc.jobRepo.Update(job)
-		return
+		return err
 	}
 
 	// Create questions
@@ -192,14 +169,13 @@ func (uc *UseCase) processContentGeneration(ctx context.Context, jobID uuid.UUID
 		"courseId": course.ID,
 		"testId":   test.ID,
 	})
-	job.Status = domain.JobStatusCompleted
 	job.OutputData = outputData
-	now := time.Now()
-	job.CompletedAt = &now
-	uc.jobRepo.Update(job)
+	return nil
 }
 
-func (uc *UseCase) GetJob(id uuid.UUID) (*domain.AIGenerationJob, error) {
+// GetJob returns a job of the tenant: one generating a course for it, or one
+// working on one of its courses. Other jobs are only shown to super admins.
+func (uc *UseCase) GetJob(tenant domain.Tenant, id uuid.UUID) (*domain.AIGenerationJob, error) {
 	job, err := uc.jobRepo.GetByID(id)
 	if err != nil {
 		return nil, err
@@ -207,9 +183,50 @@ func (uc *UseCase) GetJob(id uuid.UUID) (*domain.AIGenerationJob, error) {
 	if job == nil {
 		return nil, ErrJobNotFound
 	}
+	if tenant.CrossTenant {
+		return job, nil
+	}
+
+	if job.JobType == domain.JobTypeContent {
+		var req domain.GenerateCourseRequest
+		if err := queue.DecodePayload(job, &req); err != nil {
+			return nil, err
+		}
+		if !tenant.Allows(generationOrganization(&req)) {
+			return nil, ErrJobNotFound
+		}
+		return job, nil
+	}
+	if job.CourseID == nil {
+		return nil, ErrJobNotFound
+	}
+	if _, err := uc.getCourse(tenant, *job.CourseID); err != nil {
+		return nil, ErrJobNotFound
+	}
 	return job, nil
 }
 
+// generationOrganization is the organization a generated course belongs to.
+// Jobs queued before organizations existed belong to the default one.
+func generationOrganization(req *domain.GenerateCourseRequest) uuid.UUID {
+	if req.OrganizationID != nil {
+		return *req.OrganizationID
+	}
+	return domain.DefaultOrganizationID
+}
+
+// getCourse returns a course of the tenant
+func (uc *UseCase) getCourse(tenant domain.Tenant, id uuid.UUID) (*domain.Course, error) {
+	course, err := uc.courseRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if course == nil || !tenant.Allows(course.OrganizationID) {
+		return nil, ErrCourseNotFound
+	}
+	return course, nil
+}
+
 func (uc *UseCase) HandleSynthesiaWebhook(payload *domain.SynthesiaWebhookPayload) error {
 	// Find course with this Synthesia video ID
 	course, err := uc.courseRepo.GetBySynthesiaVideoID(payload.VideoID)
@@ -243,14 +260,15 @@ func (uc *UseCase) HandleSynthesiaWebhook(payload *domain.SynthesiaWebhookPayloa
 }
 
 // RefreshVideoStatus checks the status of a video with Synthesia and updates the course
-func (uc *UseCase) RefreshVideoStatus(ctx context.Context, courseID uuid.UUID) (*domain.Course, error) {
-	course, err := uc.courseRepo.GetByID(courseID)
+func (uc *UseCase) RefreshVideoStatus(ctx context.Context, tenant domain.Tenant, courseID uuid.UUID) (*domain.Course, error) {
+	course, err := uc.getCourse(tenant, courseID)
 	if err != nil {
 		return nil, err
 	}
-	if course == nil {
-		return nil, fmt.Errorf("course not found")
-	}
+	return uc.refreshVideoStatus(ctx, course)
+}
+
+func (uc *UseCase) refreshVideoStatus(ctx context.Context, course *domain.Course) (*domain.Course, error) {
 	if course.SynthesiaVideoID == nil || *course.SynthesiaVideoID == "" {
 		return nil, fmt.Errorf("no video generation in progress for this course")
 	}
@@ -317,7 +335,7 @@ func (uc *UseCase) PollPendingVideos(ctx context.Context) error {
 			continue
 		}
 
-		_, err := uc.RefreshVideoStatus(ctx, course.ID)
+		_, err := uc.refreshVideoStatus(ctx, course)
 		if err != nil {
 			println(fmt.Sprintf("Failed to refresh video status for course %s: %v", course.ID, err))
 		}
@@ -326,15 +344,13 @@ func (uc *UseCase) PollPendingVideos(ctx context.Context) error {
 	return nil
 }
 
-// GetFreshVideoURL fetches a fresh signed URL from Synthesia for a course's video
-func (uc *UseCase) GetFreshVideoURL(ctx context.Context, courseID uuid.UUID) (string, error) {
-	course, err := uc.courseRepo.GetByID(courseID)
+// GetFreshVideoURL fetches a fresh signed URL from Synthesia for the video of
+// a course of the tenant
+func (uc *UseCase) GetFreshVideoURL(ctx context.Context, tenant domain.Tenant, courseID uuid.UUID) (string, error) {
+	course, err := uc.getCourse(tenant, courseID)
 	if err != nil {
 		return "", err
 	}
-	if course == nil {
-		return "", fmt.Errorf("course not found")
-	}
 	if course.SynthesiaVideoID == nil || *course.SynthesiaVideoID == "" {
 		return "", fmt.Errorf("no video available for this course")
 	}
@@ -0,0 +1,35 @@
+package audit
+
+import (
+	"github.com/secusense/backend/internal/domain"
+)
+
+type UseCase struct {
+	auditRepo domain.AuditRepository
+}
+
+func NewUseCase(auditRepo domain.AuditRepository) *UseCase {
+	return &UseCase{auditRepo: auditRepo}
+}
+
+// List returns the audit log of the tenant, newest first
+func (uc *UseCase) List(tenant domain.Tenant, page, pageSize int) ([]*domain.AuditEntry, int, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	entries, err := uc.auditRepo.List(tenant, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := uc.auditRepo.Count(tenant)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return entries, total, nil
+}
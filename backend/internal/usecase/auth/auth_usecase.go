@@ -11,25 +11,32 @@ import (
 )
 
 var (
-	ErrUserAlreadyExists = errors.New("user with this email already exists")
-	ErrInvalidCredentials = errors.New("invalid email or password")
-	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	ErrUserAlreadyExists    = errors.New("user with this email already exists")
+	ErrInvalidCredentials   = errors.New("invalid email or password")
+	ErrInvalidRefreshToken  = errors.New("invalid or expired refresh token")
+	ErrOrganizationNotFound = errors.New("organization not found")
 )
 
 type UseCase struct {
 	userRepo         domain.UserRepository
 	refreshTokenRepo domain.RefreshTokenRepository
+	orgRepo          domain.OrganizationRepository
+	roleRepo         domain.RoleRepository
 	jwtManager       *jwt.Manager
 }
 
 func NewUseCase(
 	userRepo domain.UserRepository,
 	refreshTokenRepo domain.RefreshTokenRepository,
+	orgRepo domain.OrganizationRepository,
+	roleRepo domain.RoleRepository,
 	jwtManager *jwt.Manager,
 ) *UseCase {
 	return &UseCase{
 		userRepo:         userRepo,
 		refreshTokenRepo: refreshTokenRepo,
+		orgRepo:          orgRepo,
+		roleRepo:         roleRepo,
 		jwtManager:       jwtManager,
 	}
 }
@@ -44,6 +51,19 @@ func (uc *UseCase) Register(req *domain.CreateUserRequest) (*domain.AuthResponse
 		return nil, ErrUserAlreadyExists
 	}
 
+	// Join the organization named at sign-up, or the default one
+	orgID := domain.DefaultOrganizationID
+	if req.Organization != "" {
+		org, err := uc.orgRepo.GetBySlug(req.Organization)
+		if err != nil {
+			return nil, err
+		}
+		if org == nil {
+			return nil, ErrOrganizationNotFound
+		}
+		orgID = org.ID
+	}
+
 	// Hash password
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 	if err != nil {
@@ -52,12 +72,13 @@ func (uc *UseCase) Register(req *domain.CreateUserRequest) (*domain.AuthResponse
 
 	// Create user
 	user := &domain.User{
-		ID:           uuid.New(),
-		Email:        req.Email,
-		PasswordHash: string(hashedPassword),
-		FirstName:    req.FirstName,
-		LastName:     req.LastName,
-		Role:         domain.RoleUser,
+		ID:             uuid.New(),
+		Email:          req.Email,
+		PasswordHash:   string(hashedPassword),
+		FirstName:      req.FirstName,
+		LastName:       req.LastName,
+		Role:           domain.RoleUser,
+		OrganizationID: orgID,
 	}
 
 	if err := uc.userRepo.Create(user); err != nil {
@@ -124,10 +145,32 @@ func (uc *UseCase) RefreshToken(refreshToken string) (*domain.AuthResponse, erro
 }
 
 func (uc *UseCase) GetCurrentUser(userID uuid.UUID) (*domain.User, error) {
-	return uc.userRepo.GetByID(userID)
+	user, err := uc.userRepo.GetByID(userID)
+	if err != nil || user == nil {
+		return user, err
+	}
+
+	user.Permissions, err = uc.roleRepo.GetPermissions(user.Role)
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// IssueTokens signs a user in who was authenticated elsewhere, such as by an
+// LTI platform
+func (uc *UseCase) IssueTokens(user *domain.User) (*domain.AuthResponse, error) {
+	return uc.generateAuthResponse(user)
 }
 
 func (uc *UseCase) generateAuthResponse(user *domain.User) (*domain.AuthResponse, error) {
+	// The access token carries the role's current permissions
+	permissions, err := uc.roleRepo.GetPermissions(user.Role)
+	if err != nil {
+		return nil, err
+	}
+	user.Permissions = permissions
+
 	tokenPair, err := uc.jwtManager.GenerateTokenPair(user)
 	if err != nil {
 		return nil, err
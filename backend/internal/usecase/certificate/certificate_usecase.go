@@ -1,14 +1,21 @@
 package certificate
 
 import (
-	"crypto/sha256"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"log"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/secusense/backend/infrastructure/storage"
 	"github.com/secusense/backend/internal/domain"
+	"github.com/secusense/backend/pkg/certsig"
+	"github.com/secusense/backend/pkg/openbadge"
 )
 
 var (
@@ -16,40 +23,75 @@ var (
 	ErrTestNotPassed          = errors.New("test must be passed to get certificate")
 	ErrCertificateExists      = errors.New("certificate already exists for this course")
 	ErrAttemptNotFound        = errors.New("test attempt not found")
+	ErrCourseNotFound         = errors.New("course not found")
+	ErrInvalidCredential      = errors.New("not an Open Badges credential")
+	ErrCertificateRevoked     = errors.New("certificate has been revoked")
+	ErrNoSigningKey           = errors.New("no certificate signing key has been created")
 )
 
+// renewalBatchSize bounds how many certificates one renewal run handles
+const renewalBatchSize = 100
+
 type PDFGenerator interface {
-	Generate(cert *domain.Certificate) ([]byte, error)
+	Generate(cert *domain.Certificate, tmpl *domain.CertificateTemplate) ([]byte, error)
 }
 
 type UseCase struct {
-	certRepo    domain.CertificateRepository
-	attemptRepo domain.TestAttemptRepository
-	pdfGen      PDFGenerator
+	certRepo       domain.CertificateRepository
+	keyRepo        domain.CertificateKeyRepository
+	attemptRepo    domain.TestAttemptRepository
+	testRepo       domain.TestRepository
+	enrollmentRepo domain.EnrollmentRepository
+	courseRepo     domain.CourseRepository
+	templateRepo   domain.CertificateTemplateRepository
+	orgRepo        domain.OrganizationRepository
+	pdfGen         PDFGenerator
+	store          storage.Storage
+	linkTTL        time.Duration // How long signed download links are valid
+	statusListURL  string        // Where the revocation list is published
+	keySecret      string        // Seals the signing keys in the database
 }
 
 func NewUseCase(
 	certRepo domain.CertificateRepository,
+	keyRepo domain.CertificateKeyRepository,
 	attemptRepo domain.TestAttemptRepository,
+	testRepo domain.TestRepository,
+	enrollmentRepo domain.EnrollmentRepository,
+	courseRepo domain.CourseRepository,
+	templateRepo domain.CertificateTemplateRepository,
+	orgRepo domain.OrganizationRepository,
 	pdfGen PDFGenerator,
+	store storage.Storage,
+	linkTTL time.Duration,
+	statusListURL string,
+	keySecret string,
 ) *UseCase {
 	return &UseCase{
-		certRepo:    certRepo,
-		attemptRepo: attemptRepo,
-		pdfGen:      pdfGen,
+		certRepo:       certRepo,
+		keyRepo:        keyRepo,
+		attemptRepo:    attemptRepo,
+		testRepo:       testRepo,
+		enrollmentRepo: enrollmentRepo,
+		courseRepo:     courseRepo,
+		templateRepo:   templateRepo,
+		orgRepo:        orgRepo,
+		pdfGen:         pdfGen,
+		store:          store,
+		linkTTL:        linkTTL,
+		statusListURL:  statusListURL,
+		keySecret:      keySecret,
 	}
 }
 
-func (uc *UseCase) Generate(userID, courseID, attemptID uuid.UUID) (*domain.Certificate, error) {
-	// Check if certificate already exists
-	existing, err := uc.certRepo.GetByUserAndCourse(userID, courseID)
-	if err != nil {
-		return nil, err
-	}
-	if existing != nil {
-		return nil, ErrCertificateExists
-	}
-
+// Generate issues the certificate for a passed attempt. A learner holding a
+// certificate gets a new one only to renew it: once its renewal has opened
+// or it has expired, and for an attempt made after it was issued. The new
+// certificate points at the one it renews. A revoked certificate is
+// replaced by passing the test again after the revocation. Only an attempt
+// at the course's own test counts. The PDF is rendered and stored once, at
+// issue.
+func (uc *UseCase) Generate(ctx context.Context, userID, courseID, attemptID uuid.UUID) (*domain.Certificate, error) {
 	// Get the attempt and verify it passed
 	attempt, err := uc.attemptRepo.GetByID(attemptID)
 	if err != nil {
@@ -61,20 +103,54 @@ func (uc *UseCase) Generate(userID, courseID, attemptID uuid.UUID) (*domain.Cert
 	if attempt.UserID != userID {
 		return nil, ErrAttemptNotFound
 	}
+	test, err := uc.testRepo.GetByCourseID(courseID)
+	if err != nil {
+		return nil, err
+	}
+	if test == nil || attempt.TestID != test.ID {
+		return nil, ErrAttemptNotFound
+	}
 	if attempt.Passed == nil || !*attempt.Passed {
 		return nil, ErrTestNotPassed
 	}
 
+	now := time.Now()
+	existing, err := uc.certRepo.GetByUserAndCourse(userID, courseID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil && !renewableWith(existing, attempt, now) {
+		return nil, ErrCertificateExists
+	}
+
+	course, err := uc.courseRepo.GetByID(courseID)
+	if err != nil {
+		return nil, err
+	}
+	if course == nil {
+		return nil, ErrCourseNotFound
+	}
+
+	// Renewing early doesn't cost the learner what is left of the old
+	// certificate
+	validFrom := now
+	if existing != nil && !existing.Revoked() && existing.ExpiresAt != nil && existing.ExpiresAt.After(now) {
+		validFrom = *existing.ExpiresAt
+	}
+
 	// Generate certificate number
 	certNumber, err := uc.certRepo.GenerateCertificateNumber()
 	if err != nil {
 		return nil, err
 	}
 
-	// Generate verification hash
-	hashData := fmt.Sprintf("%s-%s-%s-%s", certNumber, userID, courseID, time.Now().Format(time.RFC3339))
-	hash := sha256.Sum256([]byte(hashData))
-	verificationHash := hex.EncodeToString(hash[:])[:16]
+	// The verification hash only looks the certificate up; it is random so
+	// it can't be guessed from the certificate's details
+	hash := make([]byte, 8)
+	if _, err := rand.Read(hash); err != nil {
+		return nil, err
+	}
+	verificationHash := hex.EncodeToString(hash)
 
 	cert := &domain.Certificate{
 		ID:                uuid.New(),
@@ -83,6 +159,11 @@ func (uc *UseCase) Generate(userID, courseID, attemptID uuid.UUID) (*domain.Cert
 		CourseID:          courseID,
 		TestAttemptID:     attemptID,
 		VerificationHash:  verificationHash,
+		IssuedAt:          now,
+		ExpiresAt:         course.CertificateExpiry(validFrom),
+	}
+	if existing != nil && !existing.Revoked() {
+		cert.PreviousCertificateID = &existing.ID
 	}
 
 	if err := uc.certRepo.Create(cert); err != nil {
@@ -95,20 +176,234 @@ func (uc *UseCase) Generate(userID, courseID, attemptID uuid.UUID) (*domain.Cert
 		return nil, err
 	}
 
+	if err := uc.sign(fullCert); err != nil {
+		return nil, err
+	}
+
+	// A PDF that fails to render or store now is on the first download
+	if _, err := uc.storePDF(ctx, fullCert); err != nil {
+		log.Printf("[Certificates] Failed to store the PDF of certificate %s: %v", fullCert.CertificateNumber, err)
+	}
+
 	return fullCert, nil
 }
 
-func (uc *UseCase) GetByID(id uuid.UUID) (*domain.Certificate, error) {
+// sign signs the certificate with the newest key and stores the credential
+func (uc *UseCase) sign(cert *domain.Certificate) error {
+	key, err := uc.signingKey()
+	if err != nil {
+		return err
+	}
+
+	claims := certsig.NewClaims(
+		cert.CertificateNumber, cert.IssuerName(),
+		fmt.Sprintf("%s %s", cert.UserFirstName, cert.UserLastName), cert.CourseTitle,
+		cert.Score, cert.MaxScore, cert.VerificationHash, cert.IssuedAt, cert.ExpiresAt,
+	)
+	signature, err := key.Sign(claims)
+	if err != nil {
+		return err
+	}
+
+	cert.Signature = &signature
+	return uc.certRepo.Update(cert)
+}
+
+// PrepareSigningKeys seals the signing keys stored before keys were
+// encrypted and creates the first key. It runs at startup, before any
+// certificate is signed.
+func (uc *UseCase) PrepareSigningKeys() error {
+	stored, err := uc.keyRepo.List()
+	if err != nil {
+		return err
+	}
+	for _, k := range stored {
+		if certsig.Sealed(k.PrivateKey) {
+			continue
+		}
+		sealed, err := certsig.SealPEM(k.PrivateKey, uc.keySecret)
+		if err != nil {
+			return err
+		}
+		if err := uc.keyRepo.UpdatePrivateKey(k.KID, sealed); err != nil {
+			return err
+		}
+		log.Printf("[Certificates] Sealed signing key %s", k.KID)
+	}
+	if len(stored) > 0 {
+		return nil
+	}
+
+	key, err := certsig.GenerateKey()
+	if err != nil {
+		return err
+	}
+	privatePEM, err := key.MarshalPEM()
+	if err != nil {
+		return err
+	}
+	sealed, err := certsig.SealPEM(privatePEM, uc.keySecret)
+	if err != nil {
+		return err
+	}
+	created, err := uc.keyRepo.CreateFirst(&domain.CertificateSigningKey{KID: key.KID, PrivateKey: sealed})
+	if err != nil {
+		return err
+	}
+	if created {
+		log.Printf("[Certificates] Generated signing key %s", key.KID)
+	}
+	return nil
+}
+
+// signingKey returns the newest signing key
+func (uc *UseCase) signingKey() (*certsig.Key, error) {
+	stored, err := uc.keyRepo.GetLatest()
+	if err != nil {
+		return nil, err
+	}
+	if stored == nil {
+		return nil, ErrNoSigningKey
+	}
+	return uc.parseKey(stored)
+}
+
+// parseKey opens a stored signing key with the configured secret
+func (uc *UseCase) parseKey(stored *domain.CertificateSigningKey) (*certsig.Key, error) {
+	privatePEM, err := certsig.OpenPEM(stored.PrivateKey, uc.keySecret)
+	if err != nil {
+		return nil, err
+	}
+	return certsig.ParseKey(stored.KID, privatePEM)
+}
+
+// Keys returns the public keys certificates are verified with, including
+// those of retired keys that signed older certificates
+func (uc *UseCase) Keys() (*certsig.JWKS, error) {
+	stored, err := uc.keyRepo.List()
+	if err != nil {
+		return nil, err
+	}
+
+	jwks := &certsig.JWKS{Keys: []certsig.JWK{}}
+	for _, k := range stored {
+		key, err := uc.parseKey(k)
+		if err != nil {
+			log.Printf("[Certificates] ERROR: Failed to parse signing key %s: %v", k.KID, err)
+			continue
+		}
+		jwks.Keys = append(jwks.Keys, key.JWK())
+	}
+	return jwks, nil
+}
+
+// renewableWith reports whether attempt may renew cert, or replace it once
+// it has been revoked
+func renewableWith(cert *domain.Certificate, attempt *domain.TestAttempt, now time.Time) bool {
+	if cert.Revoked() {
+		return attempt.CompletedAt != nil && attempt.CompletedAt.After(*cert.RevokedAt)
+	}
+	if cert.ExpiresAt == nil {
+		return false
+	}
+	if cert.RenewalOpenedAt == nil && now.Before(*cert.ExpiresAt) {
+		return false
+	}
+	return attempt.CompletedAt != nil && attempt.CompletedAt.After(cert.IssuedAt)
+}
+
+func (uc *UseCase) GetByID(tenant domain.Tenant, id uuid.UUID) (*domain.Certificate, error) {
 	cert, err := uc.certRepo.GetByID(id)
 	if err != nil {
 		return nil, err
 	}
-	if cert == nil {
+	if cert == nil || !tenant.Allows(cert.OrganizationID) {
 		return nil, ErrCertificateNotFound
 	}
 	return cert, nil
 }
 
+// List lists the certificates issued in the tenant for administrators
+func (uc *UseCase) List(tenant domain.Tenant, page, pageSize int) ([]*domain.Certificate, int, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	certs, err := uc.certRepo.List(tenant, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := uc.certRepo.Count(tenant)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return certs, total, nil
+}
+
+// GetHistory returns a certificate with the ones it renewed, newest first
+func (uc *UseCase) GetHistory(tenant domain.Tenant, id uuid.UUID) ([]*domain.Certificate, error) {
+	if _, err := uc.GetByID(tenant, id); err != nil {
+		return nil, err
+	}
+	return uc.certRepo.GetHistory(id)
+}
+
+// OpenRenewals enrolls learners again in courses whose certificates are
+// about to expire. The enrollment starts over and is due when the
+// certificate expires; attempts made before don't count against the test's
+// retake policy. It returns how many renewals were opened.
+func (uc *UseCase) OpenRenewals(now time.Time) (int, error) {
+	certs, err := uc.certRepo.ListDueForRenewal(now, renewalBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	opened := 0
+	for _, cert := range certs {
+		if err := uc.openRenewal(cert, now); err != nil {
+			return opened, err
+		}
+		opened++
+	}
+	return opened, nil
+}
+
+func (uc *UseCase) openRenewal(cert *domain.Certificate, now time.Time) error {
+	enrollment, err := uc.enrollmentRepo.GetByUserAndCourse(cert.UserID, cert.CourseID)
+	if err != nil {
+		return err
+	}
+
+	if enrollment == nil {
+		err = uc.enrollmentRepo.Create(&domain.Enrollment{
+			ID:               uuid.New(),
+			UserID:           cert.UserID,
+			CourseID:         cert.CourseID,
+			Status:           domain.EnrollmentStatusActive,
+			DueAt:            cert.ExpiresAt,
+			RenewalStartedAt: &now,
+		})
+	} else {
+		enrollment.Status = domain.EnrollmentStatusActive
+		enrollment.ProgressPercentage = 0
+		enrollment.VideoWatched = false
+		enrollment.CompletedAt = nil
+		enrollment.DueAt = cert.ExpiresAt
+		enrollment.RenewalStartedAt = &now
+		err = uc.enrollmentRepo.Update(enrollment)
+	}
+	if err != nil {
+		return err
+	}
+
+	return uc.certRepo.MarkRenewalOpened(cert.ID, now)
+}
+
 func (uc *UseCase) GetByUserID(userID uuid.UUID) ([]*domain.Certificate, error) {
 	return uc.certRepo.GetByUserID(userID)
 }
@@ -118,31 +413,138 @@ func (uc *UseCase) Verify(hash string) (*domain.CertificateVerification, error)
 	if err != nil {
 		return nil, err
 	}
-	if cert == nil {
-		return &domain.CertificateVerification{
-			Valid: false,
-		}, nil
+	return verification(cert, time.Now()), nil
+}
+
+// VerifyCredential verifies an Open Badges credential document: its proof
+// must check out against one of the certificate signing keys, and the
+// certificate it was issued for must still be valid
+func (uc *UseCase) VerifyCredential(document []byte) (*domain.CertificateVerification, error) {
+	cred, public, err := openbadge.Verify(document)
+	switch err {
+	case nil:
+	case openbadge.ErrInvalidProof:
+		return &domain.CertificateVerification{Status: domain.VerificationNotFound}, nil
+	default:
+		return nil, ErrInvalidCredential
+	}
+
+	trusted, err := uc.isSigningKey(public)
+	if err != nil {
+		return nil, err
+	}
+	if !trusted {
+		return &domain.CertificateVerification{Status: domain.VerificationNotFound}, nil
+	}
+
+	certID, ok := cred.CertificateID()
+	if !ok {
+		return &domain.CertificateVerification{Status: domain.VerificationNotFound}, nil
+	}
+	id, err := uuid.Parse(certID)
+	if err != nil {
+		return &domain.CertificateVerification{Status: domain.VerificationNotFound}, nil
+	}
+	cert, err := uc.certRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	return verification(cert, time.Now()), nil
+}
+
+// isSigningKey reports whether a public key is one of the certificate
+// signing keys, retired ones included
+func (uc *UseCase) isSigningKey(public ed25519.PublicKey) (bool, error) {
+	stored, err := uc.keyRepo.List()
+	if err != nil {
+		return false, err
+	}
+	kid := certsig.Thumbprint(public)
+	for _, k := range stored {
+		if k.KID == kid {
+			return true, nil
+		}
 	}
+	return false, nil
+}
 
-	// Check expiration
-	if cert.ExpiresAt != nil && time.Now().After(*cert.ExpiresAt) {
+// verification reports on a certificate looked up for verification. Only
+// the number and the date are told of a revoked or expired certificate.
+func verification(cert *domain.Certificate, now time.Time) *domain.CertificateVerification {
+	switch {
+	case cert == nil:
+		return &domain.CertificateVerification{Status: domain.VerificationNotFound}
+	case cert.Revoked():
+		return &domain.CertificateVerification{
+			Status:            domain.VerificationRevoked,
+			CertificateNumber: cert.CertificateNumber,
+			RevokedAt:         cert.RevokedAt,
+		}
+	case cert.ExpiresAt != nil && now.After(*cert.ExpiresAt):
 		return &domain.CertificateVerification{
-			Valid: false,
-		}, nil
+			Status:            domain.VerificationExpired,
+			CertificateNumber: cert.CertificateNumber,
+			ExpiresAt:         cert.ExpiresAt,
+		}
 	}
 
 	return &domain.CertificateVerification{
-		Valid:            true,
+		Valid:             true,
+		Status:            domain.VerificationValid,
 		CertificateNumber: cert.CertificateNumber,
-		HolderName:       fmt.Sprintf("%s %s", cert.UserFirstName, cert.UserLastName),
-		CourseTitle:      cert.CourseTitle,
-		IssuedAt:         cert.IssuedAt,
-		Score:            cert.Score,
-		MaxScore:         cert.MaxScore,
-	}, nil
+		HolderName:        fmt.Sprintf("%s %s", cert.UserFirstName, cert.UserLastName),
+		CourseTitle:       cert.CourseTitle,
+		Issuer:            cert.IssuerName(),
+		IssuedAt:          cert.IssuedAt,
+		ExpiresAt:         cert.ExpiresAt,
+		Score:             cert.Score,
+		MaxScore:          cert.MaxScore,
+	}
+}
+
+// GeneratePDF returns the stored PDF of the learner's certificate
+func (uc *UseCase) GeneratePDF(ctx context.Context, id uuid.UUID, userID uuid.UUID) ([]byte, error) {
+	cert, err := uc.downloadable(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if cert.PDFURL != nil {
+		doc, err := uc.store.Get(ctx, storage.CertificateKey(cert.ID.String()))
+		if err == nil {
+			return doc, nil
+		}
+		if err != storage.ErrNotFound {
+			return nil, err
+		}
+	}
+	return uc.storePDF(ctx, cert)
 }
 
-func (uc *UseCase) GeneratePDF(id uuid.UUID, userID uuid.UUID) ([]byte, error) {
+// PDFLink returns a signed, time-limited URL of the learner's certificate
+// PDF, to share it or open it outside the portal
+func (uc *UseCase) PDFLink(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*domain.CertificateLink, error) {
+	cert, err := uc.downloadable(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if cert.PDFURL == nil {
+		if _, err := uc.storePDF(ctx, cert); err != nil {
+			return nil, err
+		}
+	}
+
+	expiresAt := time.Now().Add(uc.linkTTL)
+	url, err := uc.store.SignedURL(ctx, storage.CertificateKey(cert.ID.String()), uc.linkTTL)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.CertificateLink{URL: url, ExpiresAt: expiresAt}, nil
+}
+
+// downloadable returns the learner's certificate if it may be downloaded
+func (uc *UseCase) downloadable(id uuid.UUID, userID uuid.UUID) (*domain.Certificate, error) {
 	cert, err := uc.certRepo.GetByID(id)
 	if err != nil {
 		return nil, err
@@ -154,5 +556,126 @@ func (uc *UseCase) GeneratePDF(id uuid.UUID, userID uuid.UUID) ([]byte, error) {
 		return nil, ErrCertificateNotFound
 	}
 
-	return uc.pdfGen.Generate(cert)
+	if cert.Revoked() {
+		return nil, ErrCertificateRevoked
+	}
+
+	// Certificates issued before signing was introduced are signed now
+	if cert.Signature == nil {
+		if err := uc.sign(cert); err != nil {
+			return nil, err
+		}
+	}
+	return cert, nil
+}
+
+// storePDF renders the certificate with its course's template, stores the
+// PDF and records where it can be downloaded. Certificates issued before
+// PDFs were stored are rendered with the template of their first download.
+func (uc *UseCase) storePDF(ctx context.Context, cert *domain.Certificate) ([]byte, error) {
+	tmpl, err := uc.templateRepo.GetForCourse(cert.OrganizationID, cert.CourseID)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := uc.pdfGen.Generate(cert, tmpl)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.store.Put(ctx, storage.CertificateKey(cert.ID.String()), "application/pdf", doc); err != nil {
+		return nil, err
+	}
+	pdfURL := fmt.Sprintf("/api/v1/certificates/%s/download", cert.ID)
+	cert.PDFURL = &pdfURL
+	if err := uc.certRepo.Update(cert); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// Badge issues the certificate as a signed Open Badges 3.0 credential the
+// learner can import into a digital wallet or professional network
+func (uc *UseCase) Badge(id uuid.UUID, userID uuid.UUID) ([]byte, error) {
+	cert, err := uc.certRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if cert == nil || cert.UserID != userID {
+		return nil, ErrCertificateNotFound
+	}
+	if cert.Revoked() {
+		return nil, ErrCertificateRevoked
+	}
+
+	key, err := uc.signingKey()
+	if err != nil {
+		return nil, err
+	}
+
+	cred, err := openbadge.NewCredential(openbadge.Badge{
+		CertificateID:   cert.ID.String(),
+		IssuerName:      cert.IssuerName(),
+		HolderName:      fmt.Sprintf("%s %s", cert.UserFirstName, cert.UserLastName),
+		HolderEmail:     cert.UserEmail,
+		CourseID:        cert.CourseID.String(),
+		CourseTitle:     cert.CourseTitle,
+		Score:           cert.Score,
+		MaxScore:        cert.MaxScore,
+		IssuedAt:        cert.IssuedAt,
+		ExpiresAt:       cert.ExpiresAt,
+		StatusListURL:   uc.statusListURL,
+		StatusListIndex: cert.StatusListIndex,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return openbadge.Sign(cred, key.Private, time.Now())
+}
+
+// Revoke revokes a certificate issued by mistake or obtained by cheating
+// and records it in the audit log. The certificate is kept, so verifying it
+// reports it as revoked.
+func (uc *UseCase) Revoke(tenant domain.Tenant, actorID, id uuid.UUID, req *domain.RevokeCertificateRequest) (*domain.Certificate, error) {
+	cert, err := uc.GetByID(tenant, id)
+	if err != nil {
+		return nil, err
+	}
+	if cert.Revoked() {
+		return nil, ErrCertificateRevoked
+	}
+
+	now := time.Now()
+	reason := strings.TrimSpace(req.Reason)
+	cert.RevokedAt = &now
+	cert.RevokedBy = &actorID
+	cert.RevocationReason = &reason
+
+	revoked, err := uc.certRepo.Revoke(cert)
+	if err != nil {
+		return nil, err
+	}
+	if !revoked {
+		return nil, ErrCertificateRevoked
+	}
+	log.Printf("[Certificates] Certificate %s revoked by %s", cert.CertificateNumber, actorID)
+	return cert, nil
+}
+
+// RevocationList returns the signed revocation list, with the bit of every
+// revoked certificate set
+func (uc *UseCase) RevocationList() ([]byte, error) {
+	key, err := uc.signingKey()
+	if err != nil {
+		return nil, err
+	}
+	revoked, err := uc.certRepo.ListRevokedStatusIndexes()
+	if err != nil {
+		return nil, err
+	}
+	maxIndex, err := uc.certRepo.MaxStatusListIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	return openbadge.SignStatusList(uc.statusListURL, domain.DefaultCertificateIssuer, revoked, maxIndex+1, key.Private, time.Now())
 }
@@ -0,0 +1,236 @@
+package certificate
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/secusense/backend/internal/domain"
+)
+
+var (
+	ErrTemplateNotFound     = errors.New("certificate template not found")
+	ErrTemplateExists       = errors.New("a certificate template already exists for this organization or course")
+	ErrInvalidTemplateTexts = errors.New("certificate texts must use a supported language and text key")
+)
+
+// ListTemplates lists the certificate templates of the tenant
+func (uc *UseCase) ListTemplates(tenant domain.Tenant) ([]*domain.CertificateTemplate, error) {
+	return uc.templateRepo.List(tenant)
+}
+
+func (uc *UseCase) GetTemplate(tenant domain.Tenant, id uuid.UUID) (*domain.CertificateTemplate, error) {
+	tmpl, err := uc.templateRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if tmpl == nil || !tenant.Allows(tmpl.OrganizationID) {
+		return nil, ErrTemplateNotFound
+	}
+	return tmpl, nil
+}
+
+// CreateTemplate adds the template of an organization, or of one of its
+// courses. Each has one template at most.
+func (uc *UseCase) CreateTemplate(tenant domain.Tenant, req *domain.CreateCertificateTemplateRequest) (*domain.CertificateTemplate, error) {
+	tmpl := &domain.CertificateTemplate{
+		OrganizationID: tenant.Owner(req.OrganizationID),
+		CourseID:       req.CourseID,
+		Name:           req.Name,
+		PrimaryColor:   req.PrimaryColor,
+		TextColor:      req.TextColor,
+		Logo:           req.Logo,
+		Signatures:     req.Signatures,
+		Texts:          req.Texts,
+		ExtraFields:    req.ExtraFields,
+	}
+	if err := uc.checkTemplate(tmpl); err != nil {
+		return nil, err
+	}
+
+	existing, err := uc.templateRepo.GetByScope(tmpl.OrganizationID, tmpl.CourseID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrTemplateExists
+	}
+
+	if err := uc.templateRepo.Create(tmpl); err != nil {
+		return nil, err
+	}
+	return tmpl, nil
+}
+
+func (uc *UseCase) UpdateTemplate(tenant domain.Tenant, id uuid.UUID, req *domain.UpdateCertificateTemplateRequest) (*domain.CertificateTemplate, error) {
+	tmpl, err := uc.GetTemplate(tenant, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		tmpl.Name = *req.Name
+	}
+	if req.PrimaryColor != nil {
+		tmpl.PrimaryColor = emptyToNil(*req.PrimaryColor)
+	}
+	if req.TextColor != nil {
+		tmpl.TextColor = emptyToNil(*req.TextColor)
+	}
+	if req.Logo != nil {
+		tmpl.Logo = emptyToNil(*req.Logo)
+	}
+	if req.Signatures != nil {
+		tmpl.Signatures = req.Signatures
+	}
+	if req.Texts != nil {
+		tmpl.Texts = req.Texts
+	}
+	if req.ExtraFields != nil {
+		tmpl.ExtraFields = req.ExtraFields
+	}
+	if err := uc.checkTemplate(tmpl); err != nil {
+		return nil, err
+	}
+
+	if err := uc.templateRepo.Update(tmpl); err != nil {
+		return nil, err
+	}
+	return tmpl, nil
+}
+
+func (uc *UseCase) DeleteTemplate(tenant domain.Tenant, id uuid.UUID) error {
+	if _, err := uc.GetTemplate(tenant, id); err != nil {
+		return err
+	}
+	return uc.templateRepo.Delete(id)
+}
+
+// PreviewTemplate renders a sample certificate with a saved template
+func (uc *UseCase) PreviewTemplate(tenant domain.Tenant, id uuid.UUID, language string) ([]byte, error) {
+	tmpl, err := uc.GetTemplate(tenant, id)
+	if err != nil {
+		return nil, err
+	}
+	return uc.preview(tmpl, language)
+}
+
+// PreviewDraft renders a sample certificate with a template before it is
+// saved
+func (uc *UseCase) PreviewDraft(tenant domain.Tenant, req *domain.CreateCertificateTemplateRequest, language string) ([]byte, error) {
+	tmpl := &domain.CertificateTemplate{
+		OrganizationID: tenant.Owner(req.OrganizationID),
+		CourseID:       req.CourseID,
+		Name:           req.Name,
+		PrimaryColor:   req.PrimaryColor,
+		TextColor:      req.TextColor,
+		Logo:           req.Logo,
+		Signatures:     req.Signatures,
+		Texts:          req.Texts,
+		ExtraFields:    req.ExtraFields,
+	}
+	if err := uc.checkTemplate(tmpl); err != nil {
+		return nil, err
+	}
+	return uc.preview(tmpl, language)
+}
+
+// preview renders the certificate of a sample learner with the template and
+// its organization's branding
+func (uc *UseCase) preview(tmpl *domain.CertificateTemplate, language string) ([]byte, error) {
+	if !slices.Contains(domain.CertificateLanguages, language) {
+		language = "en"
+	}
+
+	now := time.Now()
+	expiresAt := now.AddDate(1, 0, 0)
+	sample := &domain.Certificate{
+		OrganizationID:    tmpl.OrganizationID,
+		CertificateNumber: "SS-0000-SAMPLE",
+		VerificationHash:  "sample",
+		Score:             9,
+		MaxScore:          10,
+		IssuedAt:          now,
+		ExpiresAt:         &expiresAt,
+		UserFirstName:     "Jane",
+		UserLastName:      "Doe",
+		CourseTitle:       "Security Awareness Essentials",
+		CourseLanguage:    language,
+	}
+
+	if tmpl.CourseID != nil {
+		course, err := uc.courseRepo.GetByID(*tmpl.CourseID)
+		if err != nil {
+			return nil, err
+		}
+		if course != nil {
+			sample.CourseTitle = course.Title
+		}
+	}
+
+	org, err := uc.orgRepo.GetByID(tmpl.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+	if org != nil {
+		sample.OrganizationName = org.Name
+		sample.CertificateIssuer = org.CertificateIssuer
+		sample.BrandColor = org.BrandColor
+		sample.CertificateFooter = org.CertificateFooter
+	}
+
+	return uc.pdfGen.Generate(sample, tmpl)
+}
+
+// checkTemplate validates what the request tags can't: that the course is
+// the organization's, the images and the texts
+func (uc *UseCase) checkTemplate(tmpl *domain.CertificateTemplate) error {
+	if tmpl.CourseID != nil {
+		course, err := uc.courseRepo.GetByID(*tmpl.CourseID)
+		if err != nil {
+			return err
+		}
+		if course == nil || course.OrganizationID != tmpl.OrganizationID {
+			return ErrCourseNotFound
+		}
+	}
+
+	if tmpl.Logo != nil {
+		if _, _, err := domain.DecodeImage(*tmpl.Logo); err != nil {
+			return err
+		}
+	}
+	for _, sig := range tmpl.Signatures {
+		if _, _, err := domain.DecodeImage(sig.Image); err != nil {
+			return err
+		}
+	}
+
+	for language, texts := range tmpl.Texts {
+		if !slices.Contains(domain.CertificateLanguages, language) {
+			return ErrInvalidTemplateTexts
+		}
+		for key, text := range texts {
+			if !slices.Contains(domain.CertificateTextKeys, key) || len(text) > 500 {
+				return ErrInvalidTemplateTexts
+			}
+		}
+	}
+	for _, field := range tmpl.ExtraFields {
+		for language, label := range field.Label {
+			if !slices.Contains(domain.CertificateLanguages, language) || label == "" || len(label) > 100 {
+				return ErrInvalidTemplateTexts
+			}
+		}
+	}
+	return nil
+}
+
+func emptyToNil(s string) *string {
+	if s = strings.TrimSpace(s); s == "" {
+		return nil
+	}
+	return &s
+}
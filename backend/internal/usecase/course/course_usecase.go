@@ -23,13 +23,20 @@ func NewUseCase(courseRepo domain.CourseRepository, courseContentRepo domain.Cou
 	}
 }
 
-func (uc *UseCase) Create(req *domain.CreateCourseRequest) (*domain.Course, error) {
+func (uc *UseCase) Create(tenant domain.Tenant, req *domain.CreateCourseRequest) (*domain.Course, error) {
 	course := &domain.Course{
 		ID:             uuid.New(),
 		Title:          req.Title,
 		Description:    req.Description,
 		PassPercentage: req.PassPercentage,
 		IsPublished:    false,
+		OrganizationID: tenant.Owner(req.OrganizationID),
+
+		CertificateValidityDays: req.CertificateValidityDays,
+		RenewalNoticeDays:       domain.DefaultRenewalNoticeDays,
+	}
+	if req.RenewalNoticeDays != nil {
+		course.RenewalNoticeDays = *req.RenewalNoticeDays
 	}
 
 	if err := uc.courseRepo.Create(course); err != nil {
@@ -39,25 +46,24 @@ func (uc *UseCase) Create(req *domain.CreateCourseRequest) (*domain.Course, erro
 	return course, nil
 }
 
-func (uc *UseCase) GetByID(id uuid.UUID) (*domain.Course, error) {
+// GetByID returns a course of the tenant. Courses of other organizations
+// are reported as not found.
+func (uc *UseCase) GetByID(tenant domain.Tenant, id uuid.UUID) (*domain.Course, error) {
 	course, err := uc.courseRepo.GetByID(id)
 	if err != nil {
 		return nil, err
 	}
-	if course == nil {
+	if course == nil || !tenant.Allows(course.OrganizationID) {
 		return nil, ErrCourseNotFound
 	}
 	return course, nil
 }
 
-func (uc *UseCase) Update(id uuid.UUID, req *domain.UpdateCourseRequest) (*domain.Course, error) {
-	course, err := uc.courseRepo.GetByID(id)
+func (uc *UseCase) Update(tenant domain.Tenant, id uuid.UUID, req *domain.UpdateCourseRequest) (*domain.Course, error) {
+	course, err := uc.GetByID(tenant, id)
 	if err != nil {
 		return nil, err
 	}
-	if course == nil {
-		return nil, ErrCourseNotFound
-	}
 
 	if req.Title != nil {
 		course.Title = *req.Title
@@ -77,6 +83,16 @@ func (uc *UseCase) Update(id uuid.UUID, req *domain.UpdateCourseRequest) (*domai
 	if req.IsPublished != nil {
 		course.IsPublished = *req.IsPublished
 	}
+	if req.CertificateValidityDays != nil {
+		if *req.CertificateValidityDays == 0 {
+			course.CertificateValidityDays = nil
+		} else {
+			course.CertificateValidityDays = req.CertificateValidityDays
+		}
+	}
+	if req.RenewalNoticeDays != nil {
+		course.RenewalNoticeDays = *req.RenewalNoticeDays
+	}
 
 	if err := uc.courseRepo.Update(course); err != nil {
 		return nil, err
@@ -85,19 +101,15 @@ func (uc *UseCase) Update(id uuid.UUID, req *domain.UpdateCourseRequest) (*domai
 	return course, nil
 }
 
-func (uc *UseCase) Delete(id uuid.UUID) error {
-	course, err := uc.courseRepo.GetByID(id)
-	if err != nil {
+func (uc *UseCase) Delete(tenant domain.Tenant, id uuid.UUID) error {
+	if _, err := uc.GetByID(tenant, id); err != nil {
 		return err
 	}
-	if course == nil {
-		return ErrCourseNotFound
-	}
 
 	return uc.courseRepo.Delete(id)
 }
 
-func (uc *UseCase) List(page, pageSize int, publishedOnly bool) ([]*domain.Course, int, error) {
+func (uc *UseCase) List(tenant domain.Tenant, page, pageSize int, publishedOnly bool) ([]*domain.Course, int, error) {
 	if page < 1 {
 		page = 1
 	}
@@ -107,12 +119,12 @@ func (uc *UseCase) List(page, pageSize int, publishedOnly bool) ([]*domain.Cours
 
 	offset := (page - 1) * pageSize
 
-	courses, err := uc.courseRepo.List(pageSize, offset, publishedOnly)
+	courses, err := uc.courseRepo.List(tenant, pageSize, offset, publishedOnly)
 	if err != nil {
 		return nil, 0, err
 	}
 
-	total, err := uc.courseRepo.Count(publishedOnly)
+	total, err := uc.courseRepo.Count(tenant, publishedOnly)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -138,27 +150,21 @@ func (uc *UseCase) SaveContent(content *domain.CourseContent) error {
 	return uc.courseContentRepo.Create(content)
 }
 
-func (uc *UseCase) Publish(id uuid.UUID) error {
-	course, err := uc.courseRepo.GetByID(id)
+func (uc *UseCase) Publish(tenant domain.Tenant, id uuid.UUID) error {
+	course, err := uc.GetByID(tenant, id)
 	if err != nil {
 		return err
 	}
-	if course == nil {
-		return ErrCourseNotFound
-	}
 
 	course.IsPublished = true
 	return uc.courseRepo.Update(course)
 }
 
-func (uc *UseCase) Unpublish(id uuid.UUID) error {
-	course, err := uc.courseRepo.GetByID(id)
+func (uc *UseCase) Unpublish(tenant domain.Tenant, id uuid.UUID) error {
+	course, err := uc.GetByID(tenant, id)
 	if err != nil {
 		return err
 	}
-	if course == nil {
-		return ErrCourseNotFound
-	}
 
 	course.IsPublished = false
 	return uc.courseRepo.Update(course)
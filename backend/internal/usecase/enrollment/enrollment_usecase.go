@@ -2,10 +2,13 @@ package enrollment
 
 import (
 	"errors"
+	"log"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/secusense/backend/infrastructure/xapi"
 	"github.com/secusense/backend/internal/domain"
+	"github.com/secusense/backend/internal/repository/postgres"
 )
 
 var (
@@ -14,27 +17,41 @@ var (
 	ErrAlreadyEnrolled     = errors.New("already enrolled in this course")
 	ErrEnrollmentNotFound  = errors.New("enrollment not found")
 	ErrNotOwner            = errors.New("not the owner of this enrollment")
+	ErrLessonNotFound      = errors.New("lesson not found in this course")
+	ErrSlideNotFound       = errors.New("slide not found in this lesson")
 )
 
 type UseCase struct {
-	enrollmentRepo domain.EnrollmentRepository
-	courseRepo     domain.CourseRepository
+	enrollmentRepo   domain.EnrollmentRepository
+	courseRepo       domain.CourseRepository
+	workflowRepo     *postgres.WorkflowRepository
+	presentationRepo *postgres.PresentationRepository
+	recorder         *xapi.Recorder
 }
 
-func NewUseCase(enrollmentRepo domain.EnrollmentRepository, courseRepo domain.CourseRepository) *UseCase {
+func NewUseCase(
+	enrollmentRepo domain.EnrollmentRepository,
+	courseRepo domain.CourseRepository,
+	workflowRepo *postgres.WorkflowRepository,
+	presentationRepo *postgres.PresentationRepository,
+	recorder *xapi.Recorder,
+) *UseCase {
 	return &UseCase{
-		enrollmentRepo: enrollmentRepo,
-		courseRepo:     courseRepo,
+		enrollmentRepo:   enrollmentRepo,
+		courseRepo:       courseRepo,
+		workflowRepo:     workflowRepo,
+		presentationRepo: presentationRepo,
+		recorder:         recorder,
 	}
 }
 
-func (uc *UseCase) Enroll(userID, courseID uuid.UUID) (*domain.Enrollment, error) {
-	// Check if course exists and is published
+func (uc *UseCase) Enroll(tenant domain.Tenant, userID, courseID uuid.UUID) (*domain.Enrollment, error) {
+	// Check if course exists in the learner's organization and is published
 	course, err := uc.courseRepo.GetByID(courseID)
 	if err != nil {
 		return nil, err
 	}
-	if course == nil {
+	if course == nil || !tenant.Allows(course.OrganizationID) {
 		return nil, ErrCourseNotFound
 	}
 	if !course.IsPublished {
@@ -86,6 +103,28 @@ func (uc *UseCase) ListByUser(userID uuid.UUID) ([]*domain.EnrollmentWithCourse,
 	return uc.enrollmentRepo.ListByUser(userID)
 }
 
+// ListByOrganization lists the enrollments of the tenant for administrators
+func (uc *UseCase) ListByOrganization(tenant domain.Tenant, page, pageSize int) ([]*domain.EnrollmentWithLearner, int, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	enrollments, err := uc.enrollmentRepo.ListByOrganization(tenant, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := uc.enrollmentRepo.CountByOrganization(tenant)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return enrollments, total, nil
+}
+
 func (uc *UseCase) UpdateProgress(id uuid.UUID, userID uuid.UUID, progress int) (*domain.Enrollment, error) {
 	enrollment, err := uc.enrollmentRepo.GetByID(id)
 	if err != nil {
@@ -128,6 +167,10 @@ func (uc *UseCase) CompleteVideo(id uuid.UUID, userID uuid.UUID) (*domain.Enroll
 		return nil, err
 	}
 
+	if course := uc.recordedCourse(enrollment); course != nil {
+		uc.recorder.VideoCompleted(enrollment, course)
+	}
+
 	return enrollment, nil
 }
 
@@ -151,5 +194,90 @@ func (uc *UseCase) MarkCompleted(id uuid.UUID, userID uuid.UUID) (*domain.Enroll
 		return nil, err
 	}
 
+	if course := uc.recordedCourse(enrollment); course != nil {
+		uc.recorder.CourseCompleted(enrollment, course)
+	}
+
 	return enrollment, nil
 }
+
+// Launch records that the learner opened the course of an enrollment
+func (uc *UseCase) Launch(id uuid.UUID, userID uuid.UUID) (*domain.Enrollment, error) {
+	enrollment, err := uc.ownEnrollment(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if course := uc.recordedCourse(enrollment); course != nil {
+		uc.recorder.CourseLaunched(enrollment, course)
+	}
+
+	return enrollment, nil
+}
+
+// ExperienceSlide records that the learner viewed a slide of one of the
+// course's presentation lessons
+func (uc *UseCase) ExperienceSlide(id uuid.UUID, userID uuid.UUID, req *domain.SlideViewRequest) error {
+	enrollment, err := uc.ownEnrollment(id, userID)
+	if err != nil {
+		return err
+	}
+
+	session, err := uc.workflowRepo.GetSessionByCourseID(enrollment.CourseID)
+	if err != nil {
+		return err
+	}
+	var lesson *domain.LessonScript
+	if session != nil {
+		for i := range session.LessonScripts {
+			if session.LessonScripts[i].ID == req.LessonID {
+				lesson = &session.LessonScripts[i]
+				break
+			}
+		}
+	}
+	if lesson == nil {
+		return ErrLessonNotFound
+	}
+
+	presentation, err := uc.presentationRepo.GetByLessonID(lesson.ID)
+	if err != nil {
+		return err
+	}
+	if presentation == nil || req.SlideIndex < 0 || req.SlideIndex >= len(presentation.Slides) {
+		return ErrSlideNotFound
+	}
+
+	if course := uc.recordedCourse(enrollment); course != nil {
+		uc.recorder.SlideExperienced(enrollment, course, lesson, req.SlideIndex, &presentation.Slides[req.SlideIndex])
+	}
+	return nil
+}
+
+func (uc *UseCase) ownEnrollment(id uuid.UUID, userID uuid.UUID) (*domain.Enrollment, error) {
+	enrollment, err := uc.enrollmentRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if enrollment == nil {
+		return nil, ErrEnrollmentNotFound
+	}
+	if enrollment.UserID != userID {
+		return nil, ErrNotOwner
+	}
+	return enrollment, nil
+}
+
+// recordedCourse loads the enrollment's course for a statement, or returns
+// nil when statements aren't recorded or the course can't be loaded
+func (uc *UseCase) recordedCourse(enrollment *domain.Enrollment) *domain.Course {
+	if !uc.recorder.Enabled() {
+		return nil
+	}
+	course, err := uc.courseRepo.GetByID(enrollment.CourseID)
+	if err != nil {
+		log.Printf("[xAPI] ERROR: Failed to load course %s: %v", enrollment.CourseID, err)
+		return nil
+	}
+	return course
+}
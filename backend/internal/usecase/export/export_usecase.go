@@ -0,0 +1,263 @@
+package export
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/secusense/backend/infrastructure/assets"
+	"github.com/secusense/backend/internal/domain"
+	"github.com/secusense/backend/internal/repository/postgres"
+	"github.com/secusense/backend/pkg/scorm"
+)
+
+var (
+	ErrCourseNotFound     = errors.New("course not found")
+	ErrCourseNotPublished = errors.New("only published courses can be exported")
+	ErrUnsupportedVersion = errors.New("unsupported SCORM version")
+	ErrNothingToExport    = errors.New("course has no lessons or questions to export")
+)
+
+type UseCase struct {
+	courseRepo       domain.CourseRepository
+	workflowRepo     *postgres.WorkflowRepository
+	presentationRepo *postgres.PresentationRepository
+	testRepo         domain.TestRepository
+	questionRepo     domain.QuestionRepository
+	assetFetcher     *assets.Fetcher
+}
+
+func NewUseCase(
+	courseRepo domain.CourseRepository,
+	workflowRepo *postgres.WorkflowRepository,
+	presentationRepo *postgres.PresentationRepository,
+	testRepo domain.TestRepository,
+	questionRepo domain.QuestionRepository,
+	assetFetcher *assets.Fetcher,
+) *UseCase {
+	return &UseCase{
+		courseRepo:       courseRepo,
+		workflowRepo:     workflowRepo,
+		presentationRepo: presentationRepo,
+		testRepo:         testRepo,
+		questionRepo:     questionRepo,
+		assetFetcher:     assetFetcher,
+	}
+}
+
+// ExportSCORM packages a published course with its lessons, slide media and
+// test for an LMS. Media that can't be fetched and questions the package
+// can't grade are left out and reported. Only courses of the tenant can be
+// exported.
+func (uc *UseCase) ExportSCORM(ctx context.Context, tenant domain.Tenant, courseID uuid.UUID, version scorm.Version) (*domain.CoursePackage, error) {
+	if version != scorm.Version12 && version != scorm.Version2004 {
+		return nil, ErrUnsupportedVersion
+	}
+
+	course, err := uc.courseRepo.GetByID(courseID)
+	if err != nil {
+		return nil, err
+	}
+	if course == nil || !tenant.Allows(course.OrganizationID) {
+		return nil, ErrCourseNotFound
+	}
+	if !course.IsPublished {
+		return nil, ErrCourseNotPublished
+	}
+
+	result := &domain.CoursePackage{Issues: []domain.InterchangeIssue{}}
+	pkg := &scorm.Package{
+		Identifier:  "secusense-" + course.ID.String(),
+		Title:       course.Title,
+		Description: course.Description,
+	}
+
+	lessons, err := uc.packageLessons(ctx, course, result)
+	if err != nil {
+		return nil, err
+	}
+	pkg.Lessons = lessons
+
+	quiz, err := uc.packageQuiz(courseID, result)
+	if err != nil {
+		return nil, err
+	}
+	pkg.Quiz = quiz
+
+	if len(pkg.Lessons) == 0 && pkg.Quiz == nil {
+		return nil, ErrNothingToExport
+	}
+
+	data, err := scorm.Build(pkg, version)
+	if err != nil {
+		return nil, err
+	}
+	for _, issue := range result.Issues {
+		log.Printf("[SCORM] Course %s, %s (%q): %s", courseID, issue.Item, issue.Title, issue.Reason)
+	}
+
+	result.Data = data
+	result.Filename = fmt.Sprintf("%s-scorm-%s.zip", packageFilename(course.Title), version)
+	return result, nil
+}
+
+// packageLessons collects the lessons of the course's workflow, or the
+// course video for courses created without one
+func (uc *UseCase) packageLessons(ctx context.Context, course *domain.Course, result *domain.CoursePackage) ([]scorm.Lesson, error) {
+	session, err := uc.workflowRepo.GetSessionByCourseID(course.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if session == nil || len(session.LessonScripts) == 0 {
+		if course.VideoURL != nil && *course.VideoURL != "" {
+			return []scorm.Lesson{{Title: course.Title, VideoURL: *course.VideoURL}}, nil
+		}
+		return nil, nil
+	}
+
+	lessons := make([]scorm.Lesson, 0, len(session.LessonScripts))
+	for i, script := range session.LessonScripts {
+		lesson := scorm.Lesson{Title: script.Title}
+		if script.VideoURL != nil {
+			lesson.VideoURL = *script.VideoURL
+		}
+
+		if script.OutputType == domain.OutputTypePresentation && script.PresentationStatus != nil && *script.PresentationStatus == "completed" {
+			presentation, err := uc.presentationRepo.GetByLessonID(script.ID)
+			if err != nil {
+				return nil, err
+			}
+			if presentation != nil {
+				for j, slide := range presentation.Slides {
+					lesson.Slides = append(lesson.Slides, uc.packageSlide(ctx, slide, fmt.Sprintf("lesson %d slide %d", i+1, j+1), result))
+				}
+			}
+		}
+
+		if len(lesson.Slides) == 0 && lesson.VideoURL == "" {
+			result.Issues = append(result.Issues, domain.InterchangeIssue{
+				Item:    fmt.Sprintf("lesson %d", i+1),
+				Title:   script.Title,
+				Reason:  "the lesson has no finished presentation or video",
+				Skipped: true,
+			})
+			continue
+		}
+		lessons = append(lessons, lesson)
+	}
+	return lessons, nil
+}
+
+func (uc *UseCase) packageSlide(ctx context.Context, slide domain.PresentationSlide, item string, result *domain.CoursePackage) scorm.Slide {
+	packaged := scorm.Slide{
+		Title:    slide.Title,
+		Content:  slide.Content,
+		Script:   slide.Script,
+		ImageAlt: slide.ImageAlt,
+	}
+
+	fetch := func(url, kind string) *scorm.Asset {
+		if url == "" {
+			return nil
+		}
+		data, ext, err := uc.assetFetcher.Fetch(ctx, url)
+		if err != nil {
+			log.Printf("[SCORM] Failed to fetch %s %s: %v", kind, url, err)
+			result.Issues = append(result.Issues, domain.InterchangeIssue{
+				Item:   item,
+				Title:  slide.Title,
+				Reason: "the " + kind + " could not be fetched and was left out",
+			})
+			return nil
+		}
+		return &scorm.Asset{Data: data, Ext: ext}
+	}
+	packaged.Audio = fetch(slide.AudioURL, "audio")
+	packaged.Image = fetch(slide.ImageURL, "image")
+	return packaged
+}
+
+// packageQuiz turns the course's test into the package's quiz. Questions the
+// player can't grade without the server are skipped.
+func (uc *UseCase) packageQuiz(courseID uuid.UUID, result *domain.CoursePackage) (*scorm.Quiz, error) {
+	test, err := uc.testRepo.GetByCourseID(courseID)
+	if err != nil {
+		return nil, err
+	}
+	if test == nil {
+		return nil, nil
+	}
+
+	questions, err := uc.questionRepo.GetByTestID(test.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	quiz := &scorm.Quiz{
+		Title:         test.Title,
+		PassingScore:  test.PassingScore,
+		Shuffle:       test.ShuffleQuestions,
+		AllowNegative: test.AllowNegative,
+	}
+	if test.ScoringPolicy != nil {
+		quiz.Policy = *test.ScoringPolicy
+	}
+
+	for i, q := range questions {
+		if !scorm.Supports(q.QuestionType) {
+			result.Issues = append(result.Issues, domain.InterchangeIssue{
+				Item:    fmt.Sprintf("question %d", i+1),
+				Title:   q.QuestionText,
+				Reason:  string(q.QuestionType) + " questions are graded by the server and can't be packaged",
+				Skipped: true,
+			})
+			continue
+		}
+
+		question := scorm.QuizQuestion{
+			Type:          q.QuestionType,
+			Text:          q.QuestionText,
+			Data:          q.QuestionData,
+			Points:        q.Points,
+			AllowNegative: q.AllowNegative,
+		}
+		if q.ScoringPolicy != nil {
+			question.Policy = *q.ScoringPolicy
+		}
+		quiz.Questions = append(quiz.Questions, question)
+	}
+
+	if len(quiz.Questions) == 0 {
+		return nil, nil
+	}
+	// The draw can't be larger than what is left after skipping
+	if test.QuestionCount != nil && *test.QuestionCount < len(quiz.Questions) {
+		quiz.QuestionCount = *test.QuestionCount
+	}
+	return quiz, nil
+}
+
+// packageFilename turns a course title into a safe file name
+func packageFilename(title string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + 'a' - 'A'
+		}
+		return '-'
+	}, title)
+	name = strings.Trim(name, "-")
+	for strings.Contains(name, "--") {
+		name = strings.ReplaceAll(name, "--", "-")
+	}
+	if name == "" {
+		return "course"
+	}
+	return name
+}
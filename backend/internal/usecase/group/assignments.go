@@ -0,0 +1,177 @@
+package group
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/secusense/backend/internal/domain"
+)
+
+func (uc *UseCase) ListAssignments(tenant domain.Tenant) ([]*domain.AssignmentProgress, error) {
+	return uc.assignmentRepo.List(tenant)
+}
+
+// Assign assigns a published course to groups of its organization and
+// enrolls their members. Assigning a course to a group again moves the
+// due date.
+func (uc *UseCase) Assign(tenant domain.Tenant, assignedBy uuid.UUID, req *domain.AssignCourseRequest) ([]*domain.CourseAssignment, error) {
+	course, err := uc.courseRepo.GetByID(req.CourseID)
+	if err != nil {
+		return nil, err
+	}
+	if course == nil || !tenant.Allows(course.OrganizationID) {
+		return nil, ErrCourseNotFound
+	}
+	if !course.IsPublished {
+		return nil, ErrCourseNotPublished
+	}
+
+	// Check every group before assigning to any
+	for _, groupID := range req.GroupIDs {
+		group, err := uc.GetByID(tenant, groupID)
+		if err != nil {
+			return nil, err
+		}
+		if group.OrganizationID != course.OrganizationID {
+			return nil, ErrGroupNotFound
+		}
+	}
+
+	assignments := make([]*domain.CourseAssignment, 0, len(req.GroupIDs))
+	for _, groupID := range req.GroupIDs {
+		assignment, err := uc.assignmentRepo.GetByCourseAndGroup(course.ID, groupID)
+		if err != nil {
+			return nil, err
+		}
+		if assignment != nil {
+			assignment.DueAt = req.DueAt
+			if err := uc.assignmentRepo.UpdateDueAt(assignment); err != nil {
+				return nil, err
+			}
+		} else {
+			assignment = &domain.CourseAssignment{
+				ID:             uuid.New(),
+				OrganizationID: course.OrganizationID,
+				CourseID:       course.ID,
+				GroupID:        groupID,
+				DueAt:          req.DueAt,
+				AssignedBy:     &assignedBy,
+			}
+			if err := uc.assignmentRepo.Create(assignment); err != nil {
+				return nil, err
+			}
+		}
+
+		members, err := uc.groupRepo.ListMemberIDs(groupID)
+		if err != nil {
+			return nil, err
+		}
+		for _, userID := range members {
+			if err := uc.assignUser(userID, assignment); err != nil {
+				return nil, err
+			}
+		}
+		assignments = append(assignments, assignment)
+	}
+
+	return assignments, nil
+}
+
+func (uc *UseCase) UpdateAssignment(tenant domain.Tenant, id uuid.UUID, req *domain.UpdateAssignmentRequest) (*domain.CourseAssignment, error) {
+	assignment, err := uc.getAssignment(tenant, id)
+	if err != nil {
+		return nil, err
+	}
+
+	assignment.DueAt = req.DueAt
+	if err := uc.assignmentRepo.UpdateDueAt(assignment); err != nil {
+		return nil, err
+	}
+	return assignment, nil
+}
+
+// DeleteAssignment withdraws an assignment. The learners stay enrolled,
+// without a due date.
+func (uc *UseCase) DeleteAssignment(tenant domain.Tenant, id uuid.UUID) error {
+	if _, err := uc.getAssignment(tenant, id); err != nil {
+		return err
+	}
+	return uc.assignmentRepo.Delete(id)
+}
+
+// ListOverdue lists the learners who haven't completed an assigned course
+// by its due date
+func (uc *UseCase) ListOverdue(tenant domain.Tenant, page, pageSize int) ([]*domain.OverdueEnrollment, int, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	overdue, err := uc.assignmentRepo.ListOverdue(tenant, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := uc.assignmentRepo.CountOverdue(tenant)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return overdue, total, nil
+}
+
+func (uc *UseCase) getAssignment(tenant domain.Tenant, id uuid.UUID) (*domain.CourseAssignment, error) {
+	assignment, err := uc.assignmentRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if assignment == nil || !tenant.Allows(assignment.OrganizationID) {
+		return nil, ErrAssignmentNotFound
+	}
+	return assignment, nil
+}
+
+// assignUser enrolls a learner for an assignment. A learner already in the
+// course keeps their progress; when several assignments cover the course,
+// the enrollment follows the one due first. Completed courses are left
+// alone.
+func (uc *UseCase) assignUser(userID uuid.UUID, assignment *domain.CourseAssignment) error {
+	enrollment, err := uc.enrollmentRepo.GetByUserAndCourse(userID, assignment.CourseID)
+	if err != nil {
+		return err
+	}
+
+	if enrollment == nil {
+		return uc.enrollmentRepo.Create(&domain.Enrollment{
+			ID:           uuid.New(),
+			UserID:       userID,
+			CourseID:     assignment.CourseID,
+			Status:       domain.EnrollmentStatusActive,
+			AssignmentID: &assignment.ID,
+			DueAt:        assignment.DueAt,
+		})
+	}
+
+	if enrollment.Status == domain.EnrollmentStatusCompleted {
+		return nil
+	}
+	if enrollment.AssignmentID != nil && *enrollment.AssignmentID != assignment.ID && !dueEarlier(assignment.DueAt, enrollment.DueAt) {
+		return nil
+	}
+
+	enrollment.Status = domain.EnrollmentStatusActive
+	enrollment.AssignmentID = &assignment.ID
+	enrollment.DueAt = assignment.DueAt
+	return uc.enrollmentRepo.Update(enrollment)
+}
+
+// dueEarlier reports whether due date a comes before b; no due date comes
+// last
+func dueEarlier(a, b *time.Time) bool {
+	if a == nil {
+		return false
+	}
+	return b == nil || a.Before(*b)
+}
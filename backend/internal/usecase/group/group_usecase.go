@@ -0,0 +1,189 @@
+package group
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/secusense/backend/internal/domain"
+)
+
+var (
+	ErrGroupNotFound      = errors.New("group not found")
+	ErrGroupExists        = errors.New("a group with this name already exists")
+	ErrUserNotFound       = errors.New("user not found in the group's organization")
+	ErrCourseNotFound     = errors.New("course not found")
+	ErrCourseNotPublished = errors.New("course is not published")
+	ErrAssignmentNotFound = errors.New("assignment not found")
+)
+
+type UseCase struct {
+	groupRepo      domain.GroupRepository
+	assignmentRepo domain.CourseAssignmentRepository
+	userRepo       domain.UserRepository
+	courseRepo     domain.CourseRepository
+	enrollmentRepo domain.EnrollmentRepository
+}
+
+func NewUseCase(
+	groupRepo domain.GroupRepository,
+	assignmentRepo domain.CourseAssignmentRepository,
+	userRepo domain.UserRepository,
+	courseRepo domain.CourseRepository,
+	enrollmentRepo domain.EnrollmentRepository,
+) *UseCase {
+	return &UseCase{
+		groupRepo:      groupRepo,
+		assignmentRepo: assignmentRepo,
+		userRepo:       userRepo,
+		courseRepo:     courseRepo,
+		enrollmentRepo: enrollmentRepo,
+	}
+}
+
+func (uc *UseCase) List(tenant domain.Tenant) ([]*domain.Group, error) {
+	return uc.groupRepo.List(tenant)
+}
+
+func (uc *UseCase) GetByID(tenant domain.Tenant, id uuid.UUID) (*domain.Group, error) {
+	group, err := uc.groupRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if group == nil || !tenant.Allows(group.OrganizationID) {
+		return nil, ErrGroupNotFound
+	}
+	return group, nil
+}
+
+func (uc *UseCase) Create(tenant domain.Tenant, req *domain.CreateGroupRequest) (*domain.Group, error) {
+	group := &domain.Group{
+		ID:             uuid.New(),
+		OrganizationID: tenant.Owner(req.OrganizationID),
+		Name:           strings.TrimSpace(req.Name),
+		Description:    req.Description,
+		Kind:           req.Kind,
+	}
+	if group.Kind == "" {
+		group.Kind = domain.GroupKindGroup
+	}
+
+	existing, err := uc.groupRepo.GetByName(group.OrganizationID, group.Name)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrGroupExists
+	}
+
+	if err := uc.groupRepo.Create(group); err != nil {
+		return nil, err
+	}
+	return group, nil
+}
+
+func (uc *UseCase) Update(tenant domain.Tenant, id uuid.UUID, req *domain.UpdateGroupRequest) (*domain.Group, error) {
+	group, err := uc.GetByID(tenant, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		existing, err := uc.groupRepo.GetByName(group.OrganizationID, name)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil && existing.ID != group.ID {
+			return nil, ErrGroupExists
+		}
+		group.Name = name
+	}
+	if req.Description != nil {
+		group.Description = *req.Description
+	}
+	if req.Kind != nil {
+		group.Kind = *req.Kind
+	}
+
+	if err := uc.groupRepo.Update(group); err != nil {
+		return nil, err
+	}
+	return group, nil
+}
+
+// Delete removes a group with its assignments. Enrollments stay, without a
+// due date.
+func (uc *UseCase) Delete(tenant domain.Tenant, id uuid.UUID) error {
+	if _, err := uc.GetByID(tenant, id); err != nil {
+		return err
+	}
+
+	assignments, err := uc.assignmentRepo.ListByGroup(id)
+	if err != nil {
+		return err
+	}
+	for _, a := range assignments {
+		if err := uc.assignmentRepo.Delete(a.ID); err != nil {
+			return err
+		}
+	}
+	return uc.groupRepo.Delete(id)
+}
+
+func (uc *UseCase) ListMembers(tenant domain.Tenant, groupID uuid.UUID) ([]*domain.GroupMember, error) {
+	if _, err := uc.GetByID(tenant, groupID); err != nil {
+		return nil, err
+	}
+	return uc.groupRepo.ListMembers(groupID)
+}
+
+// AddMembers adds users of the group's organization to it and enrolls the
+// new members in the courses assigned to the group
+func (uc *UseCase) AddMembers(tenant domain.Tenant, groupID uuid.UUID, req *domain.AddGroupMembersRequest) ([]*domain.GroupMember, error) {
+	group, err := uc.GetByID(tenant, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check every user before adding any
+	for _, userID := range req.UserIDs {
+		user, err := uc.userRepo.GetByID(userID)
+		if err != nil {
+			return nil, err
+		}
+		if user == nil || user.OrganizationID != group.OrganizationID {
+			return nil, ErrUserNotFound
+		}
+	}
+
+	assignments, err := uc.assignmentRepo.ListByGroup(groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, userID := range req.UserIDs {
+		added, err := uc.groupRepo.AddMember(groupID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if !added {
+			continue
+		}
+		for _, a := range assignments {
+			if err := uc.assignUser(userID, a); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return uc.groupRepo.ListMembers(groupID)
+}
+
+// RemoveMember takes a user out of a group. Their enrollments stay.
+func (uc *UseCase) RemoveMember(tenant domain.Tenant, groupID, userID uuid.UUID) error {
+	if _, err := uc.GetByID(tenant, groupID); err != nil {
+		return err
+	}
+	return uc.groupRepo.RemoveMember(groupID, userID)
+}
@@ -0,0 +1,141 @@
+package lti
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/secusense/backend/infrastructure/ltiplatform"
+	"github.com/secusense/backend/infrastructure/queue"
+	"github.com/secusense/backend/internal/domain"
+)
+
+// scorePayload is the input of an LTI score job
+type scorePayload struct {
+	UserID uuid.UUID `json:"userId"`
+	TestID uuid.UUID `json:"testId"`
+}
+
+// AttemptGraded queues the learner's grade for the platforms the course was
+// launched from. Grades are sent from the job queue so a slow or unreachable
+// LMS neither delays the learner's result nor loses the grade.
+func (uc *UseCase) AttemptGraded(attempt *domain.TestAttempt, test *domain.Test) {
+	links, err := uc.ltiRepo.ListGradeLinks(attempt.UserID, test.CourseID)
+	if err != nil {
+		log.Printf("[LTI] ERROR: Failed to load grade links of user %s: %v", attempt.UserID, err)
+		return
+	}
+	if len(links) == 0 {
+		return
+	}
+
+	if _, err := uc.jobQueue.Enqueue(domain.JobTypeLTIScore, scorePayload{
+		UserID: attempt.UserID,
+		TestID: test.ID,
+	}); err != nil {
+		log.Printf("[LTI] ERROR: Failed to queue score of user %s for test %s: %v", attempt.UserID, test.ID, err)
+	}
+}
+
+// runScoreJob posts the learner's best completed attempt to every line item
+// linked to the course. The score is read when the job runs, so a job that
+// is retried or overtaken by a later attempt still sends the current grade.
+func (uc *UseCase) runScoreJob(ctx context.Context, job *domain.AIGenerationJob) error {
+	var payload scorePayload
+	if err := queue.DecodePayload(job, &payload); err != nil {
+		return err
+	}
+
+	test, err := uc.testRepo.GetByID(payload.TestID)
+	if err != nil {
+		return err
+	}
+	if test == nil {
+		return nil
+	}
+
+	attempts, err := uc.attemptRepo.GetByUserAndTest(payload.UserID, payload.TestID)
+	if err != nil {
+		return err
+	}
+	var best *domain.TestAttempt
+	for _, a := range attempts {
+		if a.CompletedAt == nil || a.Percentage == nil {
+			continue
+		}
+		if best == nil || *a.Percentage > *best.Percentage {
+			best = a
+		}
+	}
+	if best == nil {
+		return nil
+	}
+
+	gradingProgress := ltiplatform.GradingProgressFullyGraded
+	reviews, err := uc.reviewRepo.GetByAttemptID(best.ID)
+	if err != nil {
+		return err
+	}
+	for _, r := range reviews {
+		if r.Status == domain.ReviewStatusPending {
+			gradingProgress = ltiplatform.GradingProgressPendingManual
+			break
+		}
+		if r.Status == domain.ReviewStatusGrading {
+			gradingProgress = ltiplatform.GradingProgressPending
+		}
+	}
+
+	links, err := uc.ltiRepo.ListGradeLinks(payload.UserID, test.CourseID)
+	if err != nil {
+		return err
+	}
+	if len(links) == 0 {
+		return nil
+	}
+
+	key, err := uc.signingKey()
+	if err != nil {
+		return err
+	}
+
+	// Platforms ignore a score that is not newer than the one they hold, so
+	// the timestamp is when the grade is read rather than when the attempt
+	// was submitted; a regrade or resolved review would otherwise be dropped
+	timestamp := time.Now().UTC().Format(time.RFC3339)
+
+	var failed int
+	for _, link := range links {
+		platform, err := uc.platformRepo.GetByID(link.PlatformID)
+		if err != nil {
+			return err
+		}
+		if platform == nil {
+			continue
+		}
+
+		score := &ltiplatform.Score{
+			UserID:           link.Subject,
+			ScoreGiven:       *best.Percentage,
+			ScoreMaximum:     100,
+			Timestamp:        timestamp,
+			ActivityProgress: ltiplatform.ActivityProgressCompleted,
+			GradingProgress:  gradingProgress,
+		}
+		if err := uc.grades.PostScore(ctx, platform, key, link.LineItemURL, score); err != nil {
+			log.Printf("[LTI] ERROR: Failed to send score of user %s to %s: %v", payload.UserID, platform.Issuer, err)
+			failed++
+			continue
+		}
+		log.Printf("[LTI] Sent score %.1f%% of user %s to %s", score.ScoreGiven, payload.UserID, platform.Issuer)
+	}
+
+	// Posting a score twice is harmless, so a retry may resend to the
+	// platforms that already took it
+	if failed > 0 {
+		return fmt.Errorf("%d of %d platforms rejected the score", failed, len(links))
+	}
+	return nil
+}
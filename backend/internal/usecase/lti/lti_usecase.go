@@ -0,0 +1,584 @@
+package lti
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/secusense/backend/config"
+	"github.com/secusense/backend/infrastructure/ltiplatform"
+	"github.com/secusense/backend/infrastructure/queue"
+	"github.com/secusense/backend/internal/domain"
+	"github.com/secusense/backend/internal/usecase/auth"
+	"github.com/secusense/backend/pkg/jwt"
+)
+
+var (
+	ErrPlatformNotFound     = errors.New("LTI platform not found")
+	ErrPlatformExists       = errors.New("a platform with this issuer and client ID is already registered")
+	ErrInvalidLogin         = errors.New("invalid LTI login request")
+	ErrInvalidState         = errors.New("LTI login expired or was already used; launch again from the LMS")
+	ErrInvalidLaunch        = errors.New("invalid LTI launch")
+	ErrDeploymentNotAllowed = errors.New("LTI deployment is not registered")
+	ErrUnsupportedMessage   = errors.New("unsupported LTI message")
+	ErrCourseNotFound       = errors.New("course not found")
+	ErrCourseNotPublished   = errors.New("course is not published")
+	ErrNotInstructor        = errors.New("only instructors can add courses to the LMS")
+	ErrInvalidCode          = errors.New("invalid or expired launch code")
+	ErrLaunchNotFound       = errors.New("LTI launch not found")
+	ErrNotDeepLinking       = errors.New("launch is not a deep linking request")
+)
+
+const (
+	launchPath = "/api/v1/lti/launch"
+
+	// Provisioned users can't sign in with a password; bcrypt never matches it
+	unusablePassword = "!"
+	maxNameLength    = 100
+)
+
+type UseCase struct {
+	platformRepo   domain.LTIPlatformRepository
+	ltiRepo        domain.LTIRepository
+	userRepo       domain.UserRepository
+	courseRepo     domain.CourseRepository
+	enrollmentRepo domain.EnrollmentRepository
+	testRepo       domain.TestRepository
+	attemptRepo    domain.TestAttemptRepository
+	reviewRepo     domain.AnswerReviewRepository
+	authUC         *auth.UseCase
+	keySet         *ltiplatform.KeySet
+	grades         *ltiplatform.GradeClient
+	jobQueue       *queue.Queue
+	cfg            config.LTIConfig
+}
+
+func NewUseCase(
+	platformRepo domain.LTIPlatformRepository,
+	ltiRepo domain.LTIRepository,
+	userRepo domain.UserRepository,
+	courseRepo domain.CourseRepository,
+	enrollmentRepo domain.EnrollmentRepository,
+	testRepo domain.TestRepository,
+	attemptRepo domain.TestAttemptRepository,
+	reviewRepo domain.AnswerReviewRepository,
+	authUC *auth.UseCase,
+	keySet *ltiplatform.KeySet,
+	grades *ltiplatform.GradeClient,
+	jobQueue *queue.Queue,
+	cfg config.LTIConfig,
+) *UseCase {
+	uc := &UseCase{
+		platformRepo:   platformRepo,
+		ltiRepo:        ltiRepo,
+		userRepo:       userRepo,
+		courseRepo:     courseRepo,
+		enrollmentRepo: enrollmentRepo,
+		testRepo:       testRepo,
+		attemptRepo:    attemptRepo,
+		reviewRepo:     reviewRepo,
+		authUC:         authUC,
+		keySet:         keySet,
+		grades:         grades,
+		jobQueue:       jobQueue,
+		cfg:            cfg,
+	}
+
+	jobQueue.Register(domain.JobTypeLTIScore, uc.runScoreJob, nil)
+
+	return uc
+}
+
+// ToolConfiguration returns the URLs an LMS administrator registers the tool
+// with
+func (uc *UseCase) ToolConfiguration() *domain.LTIToolConfiguration {
+	base := strings.TrimSuffix(uc.cfg.ToolURL, "/") + "/api/v1/lti"
+	return &domain.LTIToolConfiguration{
+		LoginURL:       base + "/login",
+		LaunchURL:      base + "/launch",
+		DeepLinkingURL: base + "/launch",
+		JWKSURL:        base + "/jwks",
+	}
+}
+
+// JWKS returns the public keys platforms verify the tool's messages with
+func (uc *UseCase) JWKS() (*ltiplatform.JWKS, error) {
+	if _, err := uc.signingKey(); err != nil {
+		return nil, err
+	}
+	keys, err := uc.ltiRepo.ListKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	jwks := &ltiplatform.JWKS{Keys: []ltiplatform.JWK{}}
+	for _, k := range keys {
+		key, err := ltiplatform.ParseToolKey(k)
+		if err != nil {
+			log.Printf("[LTI] ERROR: Failed to parse tool key %s: %v", k.KID, err)
+			continue
+		}
+		jwks.Keys = append(jwks.Keys, key.JWK())
+	}
+	return jwks, nil
+}
+
+// signingKey returns the newest tool key, creating the first one on demand
+func (uc *UseCase) signingKey() (*ltiplatform.ToolKey, error) {
+	stored, err := uc.ltiRepo.GetLatestKey()
+	if err != nil {
+		return nil, err
+	}
+	if stored == nil {
+		stored, err = ltiplatform.GenerateToolKey()
+		if err != nil {
+			return nil, err
+		}
+		if err := uc.ltiRepo.CreateKey(stored); err != nil {
+			return nil, err
+		}
+		log.Printf("[LTI] Generated tool key %s", stored.KID)
+	}
+	return ltiplatform.ParseToolKey(stored)
+}
+
+// Login answers a platform's OIDC login initiation with the URL of the
+// platform's authorization endpoint, which sends the browser back to Launch
+// with an id_token
+func (uc *UseCase) Login(req *domain.LTILoginRequest) (string, error) {
+	if req.Issuer == "" || req.LoginHint == "" {
+		return "", ErrInvalidLogin
+	}
+
+	if err := uc.ltiRepo.DeleteExpired(); err != nil {
+		log.Printf("[LTI] ERROR: Failed to purge expired launches: %v", err)
+	}
+
+	platform, err := uc.platformRepo.GetByIssuer(req.Issuer, req.ClientID)
+	if err != nil {
+		return "", err
+	}
+	if platform == nil {
+		return "", ErrPlatformNotFound
+	}
+	if req.DeploymentID != "" && !platform.AllowsDeployment(req.DeploymentID) {
+		return "", ErrDeploymentNotAllowed
+	}
+
+	authURL, err := url.Parse(platform.AuthLoginURL)
+	if err != nil {
+		return "", err
+	}
+
+	state := &domain.LTIState{
+		State:      randomToken(),
+		Nonce:      randomToken(),
+		PlatformID: platform.ID,
+		ExpiresAt:  time.Now().Add(uc.cfg.StateTTL),
+	}
+	if err := uc.ltiRepo.CreateState(state); err != nil {
+		return "", err
+	}
+
+	q := authURL.Query()
+	q.Set("scope", "openid")
+	q.Set("response_type", "id_token")
+	q.Set("response_mode", "form_post")
+	q.Set("prompt", "none")
+	q.Set("client_id", platform.ClientID)
+	q.Set("redirect_uri", strings.TrimSuffix(uc.cfg.ToolURL, "/")+launchPath)
+	q.Set("login_hint", req.LoginHint)
+	if req.LTIMessageHint != "" {
+		q.Set("lti_message_hint", req.LTIMessageHint)
+	}
+	q.Set("state", state.State)
+	q.Set("nonce", state.Nonce)
+	authURL.RawQuery = q.Encode()
+
+	return authURL.String(), nil
+}
+
+// Launch validates the id_token a platform posts back after login, signs in
+// the launching user (creating an account on first launch) and returns the
+// frontend URL that picks the launch up
+func (uc *UseCase) Launch(ctx context.Context, idToken, state string) (string, error) {
+	if idToken == "" || state == "" {
+		return "", ErrInvalidLaunch
+	}
+
+	stored, err := uc.ltiRepo.ConsumeState(state)
+	if err != nil {
+		return "", err
+	}
+	if stored == nil {
+		return "", ErrInvalidState
+	}
+
+	platform, err := uc.platformRepo.GetByID(stored.PlatformID)
+	if err != nil {
+		return "", err
+	}
+	if platform == nil {
+		return "", ErrPlatformNotFound
+	}
+
+	claims, err := uc.keySet.ParseIDToken(ctx, platform, idToken)
+	if err != nil {
+		log.Printf("[LTI] Rejected launch from %s: %v", platform.Issuer, err)
+		return "", ErrInvalidLaunch
+	}
+	if claims.Nonce != stored.Nonce {
+		log.Printf("[LTI] Rejected launch from %s: nonce mismatch", platform.Issuer)
+		return "", ErrInvalidLaunch
+	}
+	if !platform.AllowsDeployment(claims.DeploymentID) {
+		return "", ErrDeploymentNotAllowed
+	}
+
+	launch := &domain.LTILaunch{
+		PlatformID:   platform.ID,
+		DeploymentID: claims.DeploymentID,
+		MessageType:  claims.MessageType,
+		ExpiresAt:    time.Now().Add(uc.cfg.LaunchTTL),
+	}
+
+	switch claims.MessageType {
+	case domain.LTIResourceLinkRequest:
+		courseID, ok := courseFromClaims(claims)
+		if !ok {
+			return "", ErrCourseNotFound
+		}
+		if _, err := uc.publishedCourse(platform, courseID); err != nil {
+			return "", err
+		}
+		launch.CourseID = &courseID
+
+	case domain.LTIDeepLinkingRequest:
+		settings := claims.DeepLinking
+		if settings == nil || settings.ReturnURL == "" {
+			return "", ErrInvalidLaunch
+		}
+		if !settings.Accepts(ltiplatform.ContentItemTypeResourceLink) {
+			return "", ErrUnsupportedMessage
+		}
+		if !claims.HasRole(ltiplatform.RoleInstructor, ltiplatform.RoleContentDeveloper, ltiplatform.RoleAdministrator) {
+			return "", ErrNotInstructor
+		}
+		launch.DeepLinkReturnURL = &settings.ReturnURL
+		if settings.Data != "" {
+			launch.DeepLinkData = &settings.Data
+		}
+
+	default:
+		return "", ErrUnsupportedMessage
+	}
+
+	user, err := uc.provisionUser(platform, claims)
+	if err != nil {
+		return "", err
+	}
+	launch.UserID = user.ID
+
+	if launch.CourseID != nil {
+		if err := uc.enroll(user.ID, *launch.CourseID); err != nil {
+			return "", err
+		}
+		uc.linkGrades(platform, user, *launch.CourseID, claims)
+	}
+
+	code := randomToken()
+	launch.CodeHash = jwt.HashToken(code)
+	if err := uc.ltiRepo.CreateLaunch(launch); err != nil {
+		return "", err
+	}
+
+	log.Printf("[LTI] %s launch from %s for user %s", claims.MessageType, platform.Issuer, user.ID)
+
+	return strings.TrimSuffix(uc.cfg.FrontendURL, "/") + "/lti?code=" + url.QueryEscape(code), nil
+}
+
+// Session trades the one-time code of a launch for tokens of the launching
+// user
+func (uc *UseCase) Session(code string) (*domain.LTISession, error) {
+	launch, err := uc.ltiRepo.ConsumeLaunchCode(jwt.HashToken(code))
+	if err != nil {
+		return nil, err
+	}
+	if launch == nil {
+		return nil, ErrInvalidCode
+	}
+
+	user, err := uc.userRepo.GetByID(launch.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrInvalidCode
+	}
+
+	tokens, err := uc.authUC.IssueTokens(user)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.LTISession{
+		AuthResponse: *tokens,
+		LaunchID:     launch.ID,
+		MessageType:  launch.MessageType,
+		CourseID:     launch.CourseID,
+	}, nil
+}
+
+// DeepLink answers a deep linking launch with a link to the chosen course.
+// Courses with a test ask the platform for a gradebook column.
+func (uc *UseCase) DeepLink(userID uuid.UUID, req *domain.LTIDeepLinkRequest) (*domain.LTIDeepLinkResponse, error) {
+	launch, err := uc.ltiRepo.GetLaunch(req.LaunchID)
+	if err != nil {
+		return nil, err
+	}
+	if launch == nil || launch.UserID != userID || time.Now().After(launch.ExpiresAt) {
+		return nil, ErrLaunchNotFound
+	}
+	if launch.MessageType != domain.LTIDeepLinkingRequest || launch.DeepLinkReturnURL == nil {
+		return nil, ErrNotDeepLinking
+	}
+
+	platform, err := uc.platformRepo.GetByID(launch.PlatformID)
+	if err != nil {
+		return nil, err
+	}
+	if platform == nil {
+		return nil, ErrPlatformNotFound
+	}
+
+	course, err := uc.publishedCourse(platform, req.CourseID)
+	if err != nil {
+		return nil, err
+	}
+
+	item := ltiplatform.ContentItem{
+		Type:   ltiplatform.ContentItemTypeResourceLink,
+		Title:  course.Title,
+		Text:   course.Description,
+		URL:    strings.TrimSuffix(uc.cfg.ToolURL, "/") + launchPath,
+		Custom: map[string]string{"course_id": course.ID.String()},
+	}
+	test, err := uc.testRepo.GetByCourseID(course.ID)
+	if err != nil {
+		return nil, err
+	}
+	if test != nil {
+		item.LineItem = &ltiplatform.LineItem{
+			ScoreMaximum: 100,
+			Label:        course.Title,
+			ResourceID:   course.ID.String(),
+		}
+	}
+
+	key, err := uc.signingKey()
+	if err != nil {
+		return nil, err
+	}
+	var data string
+	if launch.DeepLinkData != nil {
+		data = *launch.DeepLinkData
+	}
+	token, err := key.SignDeepLinkingResponse(platform, launch.DeploymentID, data, []ltiplatform.ContentItem{item})
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.LTIDeepLinkResponse{
+		ReturnURL: *launch.DeepLinkReturnURL,
+		JWT:       token,
+	}, nil
+}
+
+// publishedCourse returns a published course of the platform's
+// organization; a platform can't launch courses of other tenants
+func (uc *UseCase) publishedCourse(platform *domain.LTIPlatform, courseID uuid.UUID) (*domain.Course, error) {
+	course, err := uc.courseRepo.GetByID(courseID)
+	if err != nil {
+		return nil, err
+	}
+	if course == nil || course.OrganizationID != platform.OrganizationID {
+		return nil, ErrCourseNotFound
+	}
+	if !course.IsPublished {
+		return nil, ErrCourseNotPublished
+	}
+	return course, nil
+}
+
+// provisionUser returns the account linked to the platform user, creating it
+// on first launch. Accounts are never matched by email: a platform must not
+// be able to sign in as a user it doesn't own.
+func (uc *UseCase) provisionUser(platform *domain.LTIPlatform, claims *ltiplatform.LaunchClaims) (*domain.User, error) {
+	firstName, lastName := names(claims)
+
+	link, err := uc.ltiRepo.GetUserLink(platform.ID, claims.Subject)
+	if err != nil {
+		return nil, err
+	}
+	if link != nil {
+		user, err := uc.userRepo.GetByID(link.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if user == nil {
+			return nil, ErrInvalidLaunch
+		}
+		// The platform is the source of truth for names it sends
+		if (claims.GivenName != "" || claims.Name != "") && (user.FirstName != firstName || user.LastName != lastName) {
+			user.FirstName = firstName
+			user.LastName = lastName
+			if err := uc.userRepo.Update(user); err != nil {
+				return nil, err
+			}
+		}
+		return user, nil
+	}
+
+	email, err := uc.provisionEmail(platform, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &domain.User{
+		ID:             uuid.New(),
+		Email:          email,
+		PasswordHash:   unusablePassword,
+		FirstName:      firstName,
+		LastName:       lastName,
+		Role:           domain.RoleUser,
+		OrganizationID: platform.OrganizationID,
+	}
+	if err := uc.userRepo.Create(user); err != nil {
+		return nil, err
+	}
+	if err := uc.ltiRepo.CreateUserLink(&domain.LTIUserLink{
+		PlatformID: platform.ID,
+		Subject:    claims.Subject,
+		UserID:     user.ID,
+	}); err != nil {
+		return nil, err
+	}
+
+	log.Printf("[LTI] Provisioned user %s for %s subject %s", user.ID, platform.Issuer, claims.Subject)
+	return user, nil
+}
+
+// provisionEmail uses the email the platform sends unless an account already
+// has it, falling back to an address that can't receive mail
+func (uc *UseCase) provisionEmail(platform *domain.LTIPlatform, claims *ltiplatform.LaunchClaims) (string, error) {
+	if email := strings.ToLower(strings.TrimSpace(claims.Email)); email != "" && len(email) <= 255 {
+		existing, err := uc.userRepo.GetByEmail(email)
+		if err != nil {
+			return "", err
+		}
+		if existing == nil {
+			return email, nil
+		}
+	}
+	sum := sha256.Sum256([]byte(platform.ID.String() + "|" + claims.Subject))
+	return "lti-" + hex.EncodeToString(sum[:12]) + "@lti.invalid", nil
+}
+
+// enroll makes sure a learner launched into a course is enrolled in it
+func (uc *UseCase) enroll(userID, courseID uuid.UUID) error {
+	existing, err := uc.enrollmentRepo.GetByUserAndCourse(userID, courseID)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return nil
+	}
+	return uc.enrollmentRepo.Create(&domain.Enrollment{
+		ID:       uuid.New(),
+		UserID:   userID,
+		CourseID: courseID,
+		Status:   domain.EnrollmentStatusActive,
+	})
+}
+
+// linkGrades remembers the launch's line item so test results can be sent
+// back to the platform
+func (uc *UseCase) linkGrades(platform *domain.LTIPlatform, user *domain.User, courseID uuid.UUID, claims *ltiplatform.LaunchClaims) {
+	if claims.AGS == nil || claims.AGS.LineItem == "" || !claims.AGS.HasScope(ltiplatform.ScopeScore) {
+		return
+	}
+	link := &domain.LTIGradeLink{
+		PlatformID:  platform.ID,
+		UserID:      user.ID,
+		CourseID:    courseID,
+		Subject:     claims.Subject,
+		LineItemURL: claims.AGS.LineItem,
+	}
+	if err := uc.ltiRepo.UpsertGradeLink(link); err != nil {
+		log.Printf("[LTI] ERROR: Failed to store grade link for user %s: %v", user.ID, err)
+	}
+}
+
+// courseFromClaims finds the launched course in the custom course_id
+// parameter, falling back to a /courses/<id> target link
+func courseFromClaims(claims *ltiplatform.LaunchClaims) (uuid.UUID, bool) {
+	if id, err := uuid.Parse(claims.CustomValue("course_id")); err == nil {
+		return id, true
+	}
+	target, err := url.Parse(claims.TargetLinkURI)
+	if err != nil {
+		return uuid.Nil, false
+	}
+	parts := strings.Split(strings.Trim(target.Path, "/"), "/")
+	for i := 0; i+1 < len(parts); i++ {
+		if parts[i] != "courses" {
+			continue
+		}
+		if id, err := uuid.Parse(parts[i+1]); err == nil {
+			return id, true
+		}
+	}
+	return uuid.Nil, false
+}
+
+func names(claims *ltiplatform.LaunchClaims) (string, string) {
+	first := strings.TrimSpace(claims.GivenName)
+	last := strings.TrimSpace(claims.FamilyName)
+	if first == "" && last == "" {
+		if parts := strings.Fields(claims.Name); len(parts) > 0 {
+			first = parts[0]
+			last = strings.Join(parts[1:], " ")
+		}
+	}
+	if first == "" {
+		first = "LTI"
+	}
+	if last == "" {
+		last = "Learner"
+	}
+	return truncate(first), truncate(last)
+}
+
+func truncate(s string) string {
+	if utf8.RuneCountInString(s) <= maxNameLength {
+		return s
+	}
+	return string([]rune(s)[:maxNameLength])
+}
+
+func randomToken() string {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,87 @@
+package lti
+
+import (
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/secusense/backend/internal/domain"
+)
+
+func (uc *UseCase) ListPlatforms(tenant domain.Tenant) ([]*domain.LTIPlatform, error) {
+	return uc.platformRepo.List(tenant)
+}
+
+// CreatePlatform registers an LMS that may launch the tool's courses of an
+// organization
+func (uc *UseCase) CreatePlatform(tenant domain.Tenant, req *domain.CreateLTIPlatformRequest) (*domain.LTIPlatform, error) {
+	existing, err := uc.platformRepo.GetByIssuer(req.Issuer, req.ClientID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrPlatformExists
+	}
+
+	platform := &domain.LTIPlatform{ID: uuid.New(), OrganizationID: tenant.Owner(req.OrganizationID)}
+	applyPlatformRequest(platform, req)
+	if err := uc.platformRepo.Create(platform); err != nil {
+		return nil, err
+	}
+	return platform, nil
+}
+
+func (uc *UseCase) UpdatePlatform(tenant domain.Tenant, id uuid.UUID, req *domain.CreateLTIPlatformRequest) (*domain.LTIPlatform, error) {
+	platform, err := uc.platformRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if platform == nil || !tenant.Allows(platform.OrganizationID) {
+		return nil, ErrPlatformNotFound
+	}
+
+	existing, err := uc.platformRepo.GetByIssuer(req.Issuer, req.ClientID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil && existing.ID != id {
+		return nil, ErrPlatformExists
+	}
+
+	if tenant.CrossTenant && req.OrganizationID != nil {
+		platform.OrganizationID = *req.OrganizationID
+	}
+	applyPlatformRequest(platform, req)
+	if err := uc.platformRepo.Update(platform); err != nil {
+		return nil, err
+	}
+	return platform, nil
+}
+
+// DeletePlatform removes a platform with its pending launches and grade
+// links. Provisioned accounts stay.
+func (uc *UseCase) DeletePlatform(tenant domain.Tenant, id uuid.UUID) error {
+	platform, err := uc.platformRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if platform == nil || !tenant.Allows(platform.OrganizationID) {
+		return ErrPlatformNotFound
+	}
+	return uc.platformRepo.Delete(id)
+}
+
+func applyPlatformRequest(platform *domain.LTIPlatform, req *domain.CreateLTIPlatformRequest) {
+	platform.Name = strings.TrimSpace(req.Name)
+	platform.Issuer = req.Issuer
+	platform.ClientID = req.ClientID
+	platform.AuthLoginURL = req.AuthLoginURL
+	platform.AuthTokenURL = req.AuthTokenURL
+	platform.JWKSURL = req.JWKSURL
+
+	platform.DeploymentIDs = []string{}
+	for _, id := range req.DeploymentIDs {
+		if id = strings.TrimSpace(id); id != "" {
+			platform.DeploymentIDs = append(platform.DeploymentIDs, id)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package organization
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/secusense/backend/internal/domain"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrOrganizationNotFound = errors.New("organization not found")
+	ErrSlugTaken            = errors.New("an organization with this slug already exists")
+	ErrInvalidSlug          = errors.New("slug may only contain lowercase letters, digits and single hyphens")
+	ErrUserAlreadyExists    = errors.New("user with this email already exists")
+	ErrRoleNotFound         = errors.New("role not found")
+	ErrRoleNotAssignable    = errors.New("members can't be added as super admins")
+	ErrRoleExceedsCaller    = errors.New("you can only add members with roles whose permissions you hold")
+)
+
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+type UseCase struct {
+	orgRepo  domain.OrganizationRepository
+	userRepo domain.UserRepository
+	roleRepo domain.RoleRepository
+}
+
+func NewUseCase(orgRepo domain.OrganizationRepository, userRepo domain.UserRepository, roleRepo domain.RoleRepository) *UseCase {
+	return &UseCase{
+		orgRepo:  orgRepo,
+		userRepo: userRepo,
+		roleRepo: roleRepo,
+	}
+}
+
+func (uc *UseCase) List() ([]*domain.Organization, error) {
+	return uc.orgRepo.List()
+}
+
+// GetByID returns an organization the tenant belongs to, or any
+// organization for super admins
+func (uc *UseCase) GetByID(tenant domain.Tenant, id uuid.UUID) (*domain.Organization, error) {
+	org, err := uc.orgRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if org == nil || !tenant.Allows(org.ID) {
+		return nil, ErrOrganizationNotFound
+	}
+	return org, nil
+}
+
+func (uc *UseCase) Create(req *domain.CreateOrganizationRequest) (*domain.Organization, error) {
+	slug := strings.ToLower(strings.TrimSpace(req.Slug))
+	if !slugPattern.MatchString(slug) {
+		return nil, ErrInvalidSlug
+	}
+
+	existing, err := uc.orgRepo.GetBySlug(slug)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrSlugTaken
+	}
+
+	org := &domain.Organization{
+		ID:                uuid.New(),
+		Name:              strings.TrimSpace(req.Name),
+		Slug:              slug,
+		CertificateIssuer: req.CertificateIssuer,
+		BrandColor:        req.BrandColor,
+		CertificateFooter: req.CertificateFooter,
+	}
+	if err := uc.orgRepo.Create(org); err != nil {
+		return nil, err
+	}
+	return org, nil
+}
+
+// Update changes an organization's name and certificate branding. An empty
+// branding value resets it to the default.
+func (uc *UseCase) Update(id uuid.UUID, req *domain.UpdateOrganizationRequest) (*domain.Organization, error) {
+	org, err := uc.orgRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if org == nil {
+		return nil, ErrOrganizationNotFound
+	}
+
+	if req.Name != nil {
+		org.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.CertificateIssuer != nil {
+		org.CertificateIssuer = emptyToNil(*req.CertificateIssuer)
+	}
+	if req.BrandColor != nil {
+		org.BrandColor = emptyToNil(*req.BrandColor)
+	}
+	if req.CertificateFooter != nil {
+		org.CertificateFooter = emptyToNil(*req.CertificateFooter)
+	}
+
+	if err := uc.orgRepo.Update(org); err != nil {
+		return nil, err
+	}
+	return org, nil
+}
+
+// CreateMember adds an account with any role but super_admin to an
+// organization. Admins can only add members to their own organization, with
+// a role whose permissions they hold themselves.
+func (uc *UseCase) CreateMember(tenant domain.Tenant, callerPermissions []domain.Permission, orgID uuid.UUID, req *domain.CreateMemberRequest) (*domain.User, error) {
+	if _, err := uc.GetByID(tenant, orgID); err != nil {
+		return nil, err
+	}
+
+	if req.Role == domain.RoleSuperAdmin {
+		return nil, ErrRoleNotAssignable
+	}
+	role, err := uc.roleRepo.GetByName(req.Role)
+	if err != nil {
+		return nil, err
+	}
+	if role == nil {
+		return nil, ErrRoleNotFound
+	}
+	if !domain.HoldsAll(callerPermissions, role.Permissions) {
+		return nil, ErrRoleExceedsCaller
+	}
+
+	existing, err := uc.userRepo.GetByEmail(req.Email)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrUserAlreadyExists
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &domain.User{
+		ID:             uuid.New(),
+		Email:          req.Email,
+		PasswordHash:   string(hashedPassword),
+		FirstName:      req.FirstName,
+		LastName:       req.LastName,
+		Role:           req.Role,
+		OrganizationID: orgID,
+	}
+	if err := uc.userRepo.Create(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func emptyToNil(s string) *string {
+	if s = strings.TrimSpace(s); s == "" {
+		return nil
+	}
+	return &s
+}
@@ -0,0 +1,148 @@
+package role
+
+import (
+	"errors"
+	"regexp"
+
+	"github.com/google/uuid"
+	"github.com/secusense/backend/internal/domain"
+)
+
+var (
+	ErrRoleNotFound      = errors.New("role not found")
+	ErrRoleExists        = errors.New("a role with this name already exists")
+	ErrInvalidRoleName   = errors.New("role names may only contain lowercase letters, digits and underscores")
+	ErrInvalidPermission = errors.New("unknown permission")
+	ErrBuiltInRole       = errors.New("built-in roles can't be changed")
+	ErrUserNotFound      = errors.New("user not found")
+	ErrRoleNotAssignable = errors.New("only super admins can grant or revoke the super_admin role")
+	ErrOwnRole           = errors.New("you can't change your own role")
+	ErrRoleExceedsCaller = errors.New("you can only grant or revoke roles whose permissions you hold")
+)
+
+var namePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+type UseCase struct {
+	roleRepo domain.RoleRepository
+	userRepo domain.UserRepository
+}
+
+func NewUseCase(roleRepo domain.RoleRepository, userRepo domain.UserRepository) *UseCase {
+	return &UseCase{
+		roleRepo: roleRepo,
+		userRepo: userRepo,
+	}
+}
+
+func (uc *UseCase) List() ([]*domain.Role, error) {
+	return uc.roleRepo.List()
+}
+
+// Create defines a custom role
+func (uc *UseCase) Create(req *domain.CreateRoleRequest) (*domain.Role, error) {
+	if !namePattern.MatchString(string(req.Name)) {
+		return nil, ErrInvalidRoleName
+	}
+	if err := validatePermissions(req.Permissions); err != nil {
+		return nil, err
+	}
+
+	existing, err := uc.roleRepo.GetByName(req.Name)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrRoleExists
+	}
+
+	role := &domain.Role{
+		Name:        req.Name,
+		Description: req.Description,
+		Permissions: req.Permissions,
+	}
+	if err := uc.roleRepo.Create(role); err != nil {
+		return nil, err
+	}
+	return role, nil
+}
+
+// Update changes the description of a custom role and, when given,
+// replaces its permissions
+func (uc *UseCase) Update(name domain.UserRole, req *domain.UpdateRoleRequest) (*domain.Role, error) {
+	role, err := uc.roleRepo.GetByName(name)
+	if err != nil {
+		return nil, err
+	}
+	if role == nil {
+		return nil, ErrRoleNotFound
+	}
+	if role.BuiltIn {
+		return nil, ErrBuiltInRole
+	}
+
+	if req.Description != nil {
+		role.Description = *req.Description
+	}
+	if req.Permissions != nil {
+		if err := validatePermissions(req.Permissions); err != nil {
+			return nil, err
+		}
+		role.Permissions = req.Permissions
+	}
+
+	if err := uc.roleRepo.Update(role); err != nil {
+		return nil, err
+	}
+	return role, nil
+}
+
+// AssignRole gives a user of the tenant another role. It applies once the
+// user's access token is refreshed. Callers can't change their own role, nor
+// grant or take away a role with permissions they don't hold themselves.
+func (uc *UseCase) AssignRole(tenant domain.Tenant, callerID uuid.UUID, callerPermissions []domain.Permission, userID uuid.UUID, req *domain.AssignRoleRequest) (*domain.User, error) {
+	if userID == callerID {
+		return nil, ErrOwnRole
+	}
+
+	user, err := uc.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || !tenant.Allows(user.OrganizationID) {
+		return nil, ErrUserNotFound
+	}
+	if !tenant.CrossTenant && (user.Role == domain.RoleSuperAdmin || req.Role == domain.RoleSuperAdmin) {
+		return nil, ErrRoleNotAssignable
+	}
+
+	role, err := uc.roleRepo.GetByName(req.Role)
+	if err != nil {
+		return nil, err
+	}
+	if role == nil {
+		return nil, ErrRoleNotFound
+	}
+	current, err := uc.roleRepo.GetPermissions(user.Role)
+	if err != nil {
+		return nil, err
+	}
+	if !domain.HoldsAll(callerPermissions, role.Permissions) || !domain.HoldsAll(callerPermissions, current) {
+		return nil, ErrRoleExceedsCaller
+	}
+
+	user.Role = role.Name
+	if err := uc.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+	user.Permissions = role.Permissions
+	return user, nil
+}
+
+func validatePermissions(permissions []domain.Permission) error {
+	for _, p := range permissions {
+		if !p.Valid() {
+			return ErrInvalidPermission
+		}
+	}
+	return nil
+}
@@ -0,0 +1,300 @@
+package test
+
+import (
+	"encoding/json"
+	"math"
+
+	"github.com/google/uuid"
+	"github.com/secusense/backend/internal/domain"
+)
+
+// Item analysis thresholds. Questions are only flagged once enough responses
+// are in for the statistics to mean something.
+const (
+	minFlagResponses     = 10
+	tooEasyDifficulty    = 0.9
+	tooHardDifficulty    = 0.2
+	lowDiscrimination    = 0.2
+	unusedDistractorRate = 0.05
+)
+
+// itemTally accumulates the responses to one question
+type itemTally struct {
+	question  *domain.Question
+	correct   []float64 // 1 for fully correct responses, else 0
+	restScore []float64 // share of the rest of the test scored, per response
+	points    int
+	answered  int
+	timeSum   int
+	timed     int
+	selected  []int
+}
+
+// GetItemAnalysis computes difficulty, discrimination, option frequencies and
+// average time for each question of a test from its completed attempts.
+// Questions an attempt presented but the learner left unanswered count as
+// incorrect responses.
+func (uc *UseCase) GetItemAnalysis(tenant domain.Tenant, testID uuid.UUID) (*domain.ItemAnalysis, error) {
+	if _, err := uc.getTest(tenant, testID); err != nil {
+		return nil, err
+	}
+
+	questions, err := uc.questionRepo.GetByTestID(testID)
+	if err != nil {
+		return nil, err
+	}
+	attempts, err := uc.attemptRepo.ListCompletedByTest(testID)
+	if err != nil {
+		return nil, err
+	}
+	answers, err := uc.answerRepo.GetByTestID(testID)
+	if err != nil {
+		return nil, err
+	}
+
+	byAttempt := make(map[uuid.UUID]map[uuid.UUID]*domain.UserAnswer, len(attempts))
+	for _, a := range answers {
+		if byAttempt[a.AttemptID] == nil {
+			byAttempt[a.AttemptID] = make(map[uuid.UUID]*domain.UserAnswer)
+		}
+		byAttempt[a.AttemptID][a.QuestionID] = a
+	}
+
+	tallies := make(map[uuid.UUID]*itemTally, len(questions))
+	for _, q := range questions {
+		tallies[q.ID] = &itemTally{question: q, selected: make([]int, len(multipleChoiceOptions(q)))}
+	}
+
+	for _, attempt := range attempts {
+		set, err := decodeQuestionSet(attempt)
+		if err != nil {
+			return nil, err
+		}
+
+		// Attempts without a question set were shown every question
+		optionOrders := make(map[uuid.UUID][]int)
+		var presented []*itemTally
+		if len(set) > 0 {
+			for _, entry := range set {
+				if t, ok := tallies[entry.QuestionID]; ok {
+					presented = append(presented, t)
+					optionOrders[entry.QuestionID] = entry.OptionOrder
+				}
+			}
+		} else {
+			for _, q := range questions {
+				presented = append(presented, tallies[q.ID])
+			}
+		}
+
+		given := byAttempt[attempt.ID]
+		var total, maxTotal int
+		for _, t := range presented {
+			if a, ok := given[t.question.ID]; ok {
+				total += a.PointsAwarded
+			}
+			maxTotal += t.question.Points
+		}
+
+		for _, t := range presented {
+			a := given[t.question.ID]
+			var points int
+			correct := 0.0
+			if a != nil {
+				points = a.PointsAwarded
+				if a.IsCorrect {
+					correct = 1
+				}
+				t.tallyAnswer(a, optionOrders[t.question.ID])
+			}
+			t.points += points
+
+			rest := 0.0
+			if restMax := maxTotal - t.question.Points; restMax > 0 {
+				rest = float64(total-points) / float64(restMax)
+			}
+			t.correct = append(t.correct, correct)
+			t.restScore = append(t.restScore, rest)
+		}
+	}
+
+	analysis := &domain.ItemAnalysis{
+		TestID:    testID,
+		Attempts:  len(attempts),
+		Questions: make([]*domain.QuestionStats, 0, len(questions)),
+	}
+	for _, q := range questions {
+		analysis.Questions = append(analysis.Questions, tallies[q.ID].stats())
+	}
+	return analysis, nil
+}
+
+func (t *itemTally) tallyAnswer(a *domain.UserAnswer, order []int) {
+	t.answered++
+	if a.TimeSpentSeconds != nil {
+		t.timeSum += *a.TimeSpentSeconds
+		t.timed++
+	}
+
+	if len(t.selected) == 0 {
+		return
+	}
+	var indices []int
+	if err := json.Unmarshal(canonicalAnswer(t.question, a.AnswerData, order), &indices); err != nil {
+		return
+	}
+	seen := make(map[int]bool, len(indices))
+	for _, i := range indices {
+		if i >= 0 && i < len(t.selected) && !seen[i] {
+			seen[i] = true
+			t.selected[i]++
+		}
+	}
+}
+
+func (t *itemTally) stats() *domain.QuestionStats {
+	q := t.question
+	stats := &domain.QuestionStats{
+		QuestionID:   q.ID,
+		QuestionText: q.QuestionText,
+		QuestionType: q.QuestionType,
+		Responses:    len(t.correct),
+		Answered:     t.answered,
+		Flags:        []string{},
+	}
+
+	if n := len(t.correct); n > 0 {
+		var sum float64
+		for _, c := range t.correct {
+			sum += c
+		}
+		difficulty := sum / float64(n)
+		stats.Difficulty = &difficulty
+
+		if q.Points > 0 {
+			mean := float64(t.points) / float64(n*q.Points)
+			stats.MeanScore = &mean
+		}
+		stats.Discrimination = pointBiserial(t.correct, t.restScore)
+	}
+	if t.timed > 0 {
+		avg := float64(t.timeSum) / float64(t.timed)
+		stats.AvgTimeSeconds = &avg
+	}
+
+	options := multipleChoiceOptions(q)
+	correctIndex := multipleChoiceCorrect(q)
+	for i, option := range options {
+		o := &domain.OptionStats{Index: i, Option: option, Correct: correctIndex[i], Selected: t.selected[i]}
+		if t.answered > 0 {
+			o.Rate = float64(o.Selected) / float64(t.answered)
+		}
+		stats.Options = append(stats.Options, o)
+	}
+
+	if stats.Responses >= minFlagResponses {
+		stats.Flags = itemFlags(stats)
+	}
+	return stats
+}
+
+func itemFlags(stats *domain.QuestionStats) []string {
+	flags := []string{}
+	if d := stats.Difficulty; d != nil {
+		if *d > tooEasyDifficulty {
+			flags = append(flags, domain.FlagTooEasy)
+		} else if *d < tooHardDifficulty {
+			flags = append(flags, domain.FlagTooHard)
+		}
+	}
+	if r := stats.Discrimination; r != nil {
+		if *r < 0 {
+			flags = append(flags, domain.FlagNegativeDiscrimination)
+		} else if *r < lowDiscrimination {
+			flags = append(flags, domain.FlagLowDiscrimination)
+		}
+	}
+
+	if stats.Answered == 0 {
+		return flags
+	}
+	// A distractor nobody picks does no work; one picked more often than
+	// every correct option suggests the question or its key is misleading
+	mostPickedCorrect := 0
+	for _, o := range stats.Options {
+		if o.Correct {
+			mostPickedCorrect = max(mostPickedCorrect, o.Selected)
+		}
+	}
+	var unused, misleading bool
+	for _, o := range stats.Options {
+		if o.Correct {
+			continue
+		}
+		if o.Rate < unusedDistractorRate {
+			unused = true
+		}
+		if o.Selected > mostPickedCorrect {
+			misleading = true
+		}
+	}
+	if unused {
+		flags = append(flags, domain.FlagUnusedDistractor)
+	}
+	if misleading {
+		flags = append(flags, domain.FlagMisleadingDistractor)
+	}
+	return flags
+}
+
+// pointBiserial is the correlation between dichotomous correctness and a
+// score. It is nil when either has no variance.
+func pointBiserial(correct, score []float64) *float64 {
+	n := float64(len(correct))
+	if n < 2 {
+		return nil
+	}
+	var meanX, meanY float64
+	for i := range correct {
+		meanX += correct[i]
+		meanY += score[i]
+	}
+	meanX /= n
+	meanY /= n
+
+	var cov, varX, varY float64
+	for i := range correct {
+		dx, dy := correct[i]-meanX, score[i]-meanY
+		cov += dx * dy
+		varX += dx * dx
+		varY += dy * dy
+	}
+	if varX == 0 || varY == 0 {
+		return nil
+	}
+	r := cov / math.Sqrt(varX*varY)
+	return &r
+}
+
+func multipleChoiceOptions(q *domain.Question) []string {
+	if q.QuestionType != domain.QuestionTypeMultipleChoice {
+		return nil
+	}
+	var data domain.MultipleChoiceData
+	if err := json.Unmarshal(q.QuestionData, &data); err != nil {
+		return nil
+	}
+	return data.Options
+}
+
+func multipleChoiceCorrect(q *domain.Question) map[int]bool {
+	correct := make(map[int]bool)
+	var data domain.MultipleChoiceData
+	if err := json.Unmarshal(q.QuestionData, &data); err != nil {
+		return correct
+	}
+	for _, i := range data.CorrectIndices {
+		correct[i] = true
+	}
+	return correct
+}
@@ -0,0 +1,223 @@
+package test
+
+import (
+	"encoding/json"
+	"math/rand"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/secusense/backend/internal/domain"
+)
+
+// assembleQuestionSet draws the questions for a new attempt according to the
+// test's pool settings and picks the option order each one is shown in.
+func assembleQuestionSet(test *domain.Test, pool []*domain.Question) []domain.AttemptQuestion {
+	drawn := pool
+	if test.DrawsFromPool(len(pool)) {
+		drawn = drawQuestions(pool, *test.QuestionCount, test.StratifyBy)
+	}
+
+	// A draw is random anyway; without one, keep the authored order unless
+	// shuffling was asked for
+	if test.ShuffleQuestions || test.DrawsFromPool(len(pool)) {
+		drawn = append([]*domain.Question(nil), drawn...)
+		rand.Shuffle(len(drawn), func(i, j int) { drawn[i], drawn[j] = drawn[j], drawn[i] })
+	}
+
+	set := make([]domain.AttemptQuestion, 0, len(drawn))
+	for _, q := range drawn {
+		entry := domain.AttemptQuestion{QuestionID: q.ID}
+		if test.ShuffleOptions {
+			if n := optionCount(q); n > 1 {
+				entry.OptionOrder = rand.Perm(n)
+			}
+		}
+		set = append(set, entry)
+	}
+	return set
+}
+
+// drawQuestions picks count questions at random. When stratified, every group
+// contributes in proportion to its share of the pool (largest remainder
+// method), so a draw mirrors the pool's mix of types or topics.
+func drawQuestions(pool []*domain.Question, count int, stratifyBy domain.StratifyBy) []*domain.Question {
+	groups := make(map[string][]*domain.Question)
+	var keys []string
+	for _, q := range pool {
+		key := stratumKey(q, stratifyBy)
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], q)
+	}
+
+	type quota struct {
+		key       string
+		take      int
+		remainder float64
+	}
+	quotas := make([]quota, 0, len(keys))
+	assigned := 0
+	for _, key := range keys {
+		exact := float64(count) * float64(len(groups[key])) / float64(len(pool))
+		take := int(exact)
+		quotas = append(quotas, quota{key: key, take: take, remainder: exact - float64(take)})
+		assigned += take
+	}
+	sort.SliceStable(quotas, func(i, j int) bool { return quotas[i].remainder > quotas[j].remainder })
+	for i := 0; assigned < count; i = (i + 1) % len(quotas) {
+		if quotas[i].take < len(groups[quotas[i].key]) {
+			quotas[i].take++
+			assigned++
+		}
+	}
+
+	drawn := make([]*domain.Question, 0, count)
+	for _, qt := range quotas {
+		group := groups[qt.key]
+		for _, idx := range rand.Perm(len(group))[:qt.take] {
+			drawn = append(drawn, group[idx])
+		}
+	}
+	return drawn
+}
+
+func stratumKey(q *domain.Question, stratifyBy domain.StratifyBy) string {
+	switch stratifyBy {
+	case domain.StratifyQuestionType:
+		return string(q.QuestionType)
+	case domain.StratifyTag:
+		var tags []string
+		json.Unmarshal(q.Tags, &tags)
+		if len(tags) > 0 {
+			return tags[0]
+		}
+	}
+	return ""
+}
+
+// optionCount returns how many shuffleable options a question has. Only
+// multiple choice options and ordering items are shuffled; the other types
+// reference their items by value, not by position.
+func optionCount(q *domain.Question) int {
+	switch q.QuestionType {
+	case domain.QuestionTypeMultipleChoice:
+		var data domain.MultipleChoiceData
+		if err := json.Unmarshal(q.QuestionData, &data); err == nil {
+			return len(data.Options)
+		}
+	case domain.QuestionTypeOrdering:
+		var data domain.OrderingData
+		if err := json.Unmarshal(q.QuestionData, &data); err == nil {
+			return len(data.Items)
+		}
+	}
+	return 0
+}
+
+func decodeQuestionSet(attempt *domain.TestAttempt) ([]domain.AttemptQuestion, error) {
+	var set []domain.AttemptQuestion
+	if len(attempt.QuestionSet) == 0 {
+		return set, nil
+	}
+	if err := json.Unmarshal(attempt.QuestionSet, &set); err != nil {
+		return nil, err
+	}
+	return set, nil
+}
+
+// presentQuestions returns copies of the attempt's questions in the order and
+// with the option order the learner is shown. Answer keys are remapped along
+// with the options, so the copies are still self-consistent.
+func presentQuestions(set []domain.AttemptQuestion, questions []*domain.Question) []*domain.Question {
+	byID := make(map[uuid.UUID]*domain.Question, len(questions))
+	for _, q := range questions {
+		byID[q.ID] = q
+	}
+
+	presented := make([]*domain.Question, 0, len(set))
+	for _, entry := range set {
+		q, ok := byID[entry.QuestionID]
+		if !ok {
+			continue
+		}
+		shown := *q
+		if len(entry.OptionOrder) > 0 {
+			shown.QuestionData = permuteQuestionData(q, entry.OptionOrder)
+		}
+		presented = append(presented, &shown)
+	}
+	return presented
+}
+
+func permuteQuestionData(q *domain.Question, order []int) json.RawMessage {
+	// position[orig] is where the original option ended up
+	position := make(map[int]int, len(order))
+	for shown, orig := range order {
+		position[orig] = shown
+	}
+
+	switch q.QuestionType {
+	case domain.QuestionTypeMultipleChoice:
+		var data domain.MultipleChoiceData
+		if err := json.Unmarshal(q.QuestionData, &data); err != nil || len(data.Options) != len(order) {
+			return q.QuestionData
+		}
+		options := make([]string, len(order))
+		for shown, orig := range order {
+			options[shown] = data.Options[orig]
+		}
+		correct := make([]int, len(data.CorrectIndices))
+		for i, orig := range data.CorrectIndices {
+			correct[i] = position[orig]
+		}
+		data.Options, data.CorrectIndices = options, correct
+		permuted, _ := json.Marshal(data)
+		return permuted
+
+	case domain.QuestionTypeOrdering:
+		var data domain.OrderingData
+		if err := json.Unmarshal(q.QuestionData, &data); err != nil || len(data.Items) != len(order) {
+			return q.QuestionData
+		}
+		items := make([]string, len(order))
+		for shown, orig := range order {
+			items[shown] = data.Items[orig]
+		}
+		correct := make([]int, len(data.CorrectOrder))
+		for i, orig := range data.CorrectOrder {
+			correct[i] = position[orig]
+		}
+		data.Items, data.CorrectOrder = items, correct
+		permuted, _ := json.Marshal(data)
+		return permuted
+	}
+	return q.QuestionData
+}
+
+// canonicalAnswer translates option positions in an answer from the order
+// the learner saw back to the stored order, so it can be graded against the
+// question's answer key. Positions outside the shown options are kept as an
+// invalid index and grade as wrong.
+func canonicalAnswer(q *domain.Question, answerData json.RawMessage, order []int) json.RawMessage {
+	if len(order) == 0 {
+		return answerData
+	}
+	if q.QuestionType != domain.QuestionTypeMultipleChoice && q.QuestionType != domain.QuestionTypeOrdering {
+		return answerData
+	}
+
+	var positions []int
+	if err := json.Unmarshal(answerData, &positions); err != nil {
+		return answerData
+	}
+	for i, shown := range positions {
+		if shown >= 0 && shown < len(order) {
+			positions[i] = order[shown]
+		} else {
+			positions[i] = -1
+		}
+	}
+	mapped, _ := json.Marshal(positions)
+	return mapped
+}
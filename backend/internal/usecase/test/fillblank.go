@@ -0,0 +1,116 @@
+package test
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/secusense/backend/internal/domain"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// blankAccepts reports whether answer fills blank i of a fill-in-the-blank
+// question: it matches the blank's answer or one of its alternatives after
+// normalization (within the allowed edits), or matches the blank's pattern.
+func blankAccepts(data *domain.FillBlankData, i int, answer string) bool {
+	given := normalizeAnswer(answer, data.CaseSensitive)
+
+	accepted := []string{data.Blanks[i]}
+	if i < len(data.Alternatives) {
+		accepted = append(accepted, data.Alternatives[i]...)
+	}
+	for _, want := range accepted {
+		want = normalizeAnswer(want, data.CaseSensitive)
+		if want == "" {
+			continue
+		}
+		if given == want || levenshtein(given, want) <= allowedEdits(want, data.MaxEdits) {
+			return true
+		}
+	}
+
+	if i < len(data.Patterns) && data.Patterns[i] != "" {
+		re, err := compileBlankPattern(data.Patterns[i], data.CaseSensitive)
+		if err == nil && re.MatchString(normalizeAnswer(answer, true)) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeAnswer collapses whitespace and strips accents, so "  Café" and
+// "cafe" compare equal; case is folded unless caseSensitive is set.
+func normalizeAnswer(s string, caseSensitive bool) string {
+	s = strings.Join(strings.Fields(s), " ")
+
+	// A transformer chain keeps state, so it is built per call
+	stripAccents := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	if stripped, _, err := transform.String(stripAccents, s); err == nil {
+		s = stripped
+	}
+
+	if !caseSensitive {
+		s = strings.ToLower(s)
+	}
+	return s
+}
+
+// allowedEdits caps the typo tolerance at one edit per four characters of the
+// accepted answer, so short answers like "2FA" are never matched by "3FA".
+func allowedEdits(want string, maxEdits int) int {
+	if limit := utf8.RuneCountInString(want) / 4; maxEdits > limit {
+		return limit
+	}
+	return maxEdits
+}
+
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+// compileBlankPattern anchors a blank's pattern so it has to match the whole
+// answer
+func compileBlankPattern(pattern string, caseSensitive bool) (*regexp.Regexp, error) {
+	flags := "(?i)"
+	if caseSensitive {
+		flags = ""
+	}
+	return regexp.Compile(flags + `^(?:` + pattern + `)$`)
+}
+
+func validateFillBlank(data json.RawMessage) error {
+	var fb domain.FillBlankData
+	if err := json.Unmarshal(data, &fb); err != nil || len(fb.Blanks) == 0 {
+		return ErrInvalidQuestionData
+	}
+	if len(fb.Alternatives) > len(fb.Blanks) || len(fb.Patterns) > len(fb.Blanks) || fb.MaxEdits < 0 {
+		return ErrInvalidQuestionData
+	}
+	for _, pattern := range fb.Patterns {
+		if _, err := compileBlankPattern(pattern, fb.CaseSensitive); err != nil {
+			return ErrInvalidQuestionData
+		}
+	}
+	return nil
+}
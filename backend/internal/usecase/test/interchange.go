@@ -0,0 +1,148 @@
+package test
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/secusense/backend/internal/domain"
+)
+
+// QuestionCodec reads and writes a question bank file format
+type QuestionCodec interface {
+	// Encode writes the questions the format can hold and reports the ones
+	// it had to leave out or could only carry over in part
+	Encode(title string, questions []*domain.Question) ([]byte, []domain.InterchangeIssue, error)
+	// Decode maps the items of a file to questions and reports the items it
+	// could not map. Decoded questions belong to no test yet; a zero Points
+	// means the file did not say.
+	Decode(data []byte) ([]*domain.Question, []domain.InterchangeIssue, error)
+	ContentType() string
+	Extension() string
+}
+
+// RegisterCodec installs the codec for an interchange format, replacing any
+// codec registered before
+func (uc *UseCase) RegisterCodec(format domain.InterchangeFormat, codec QuestionCodec) {
+	uc.codecs[format] = codec
+}
+
+// ExportQuestions writes every question of a test in the given format
+func (uc *UseCase) ExportQuestions(tenant domain.Tenant, testID uuid.UUID, format domain.InterchangeFormat) (*domain.QuestionExport, error) {
+	codec, ok := uc.codecs[format]
+	if !ok {
+		return nil, ErrUnsupportedFormat
+	}
+
+	test, err := uc.getTest(tenant, testID)
+	if err != nil {
+		return nil, err
+	}
+
+	questions, err := uc.questionRepo.GetByTestID(testID)
+	if err != nil {
+		return nil, err
+	}
+
+	data, issues, err := codec.Encode(test.Title, questions)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.QuestionExport{
+		Filename:    exportFilename(test.Title) + codec.Extension(),
+		ContentType: codec.ContentType(),
+		Data:        data,
+		Issues:      issues,
+	}, nil
+}
+
+// ImportQuestions adds the questions of a file to a test, after the ones it
+// already has. Items that can't be mapped, or whose answer key is invalid,
+// are skipped and reported; a dry run reports without creating anything.
+func (uc *UseCase) ImportQuestions(tenant domain.Tenant, testID uuid.UUID, format domain.InterchangeFormat, data []byte, dryRun bool) (*domain.ImportReport, error) {
+	codec, ok := uc.codecs[format]
+	if !ok {
+		return nil, ErrUnsupportedFormat
+	}
+
+	if _, err := uc.getTest(tenant, testID); err != nil {
+		return nil, err
+	}
+
+	decoded, issues, err := codec.Decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImportFile, err)
+	}
+
+	existing, err := uc.questionRepo.GetByTestID(testID)
+	if err != nil {
+		return nil, err
+	}
+	nextIndex := 0
+	for _, q := range existing {
+		nextIndex = max(nextIndex, q.OrderIndex+1)
+	}
+
+	report := &domain.ImportReport{
+		Format:   format,
+		DryRun:   dryRun,
+		Imported: []*domain.Question{},
+		Issues:   issues,
+	}
+	for i, q := range decoded {
+		if err := validateQuestionData(q.QuestionType, q.QuestionData); err != nil || strings.TrimSpace(q.QuestionText) == "" {
+			report.Issues = append(report.Issues, domain.InterchangeIssue{
+				Item:    fmt.Sprintf("mapped question %d", i+1),
+				Title:   q.QuestionText,
+				Reason:  "the mapped " + string(q.QuestionType) + " question has no valid answer key",
+				Skipped: true,
+			})
+			continue
+		}
+
+		q.ID = uuid.New()
+		q.TestID = testID
+		q.OrderIndex = nextIndex
+		if q.Points <= 0 {
+			q.Points = 1
+		}
+		if q.Tags == nil {
+			q.Tags = encodeTags(nil)
+		}
+
+		if !dryRun {
+			if err := uc.questionRepo.Create(q); err != nil {
+				return nil, err
+			}
+		}
+		nextIndex++
+		report.Imported = append(report.Imported, q)
+	}
+	if report.Issues == nil {
+		report.Issues = []domain.InterchangeIssue{}
+	}
+
+	return report, nil
+}
+
+// exportFilename turns a test title into a safe file name
+func exportFilename(title string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + 'a' - 'A'
+		}
+		return '-'
+	}, title)
+	name = strings.Trim(name, "-")
+	for strings.Contains(name, "--") {
+		name = strings.ReplaceAll(name, "--", "-")
+	}
+	if name == "" {
+		return "questions"
+	}
+	return name
+}
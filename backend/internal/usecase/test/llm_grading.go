@@ -0,0 +1,224 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/secusense/backend/infrastructure/queue"
+	"github.com/secusense/backend/internal/domain"
+)
+
+// AnswerGrader judges open-ended answers; the configured LLM provider
+// implements it
+type AnswerGrader interface {
+	GradeOpenAnswer(ctx context.Context, req *domain.OpenAnswerGradingRequest) (*domain.OpenAnswerGrade, error)
+}
+
+type llmScorer struct {
+	grader          AnswerGrader
+	timeout         time.Duration
+	reviewThreshold float64
+}
+
+// NewLLMScorer grades open-ended answers with an LLM. Grades with a
+// confidence below reviewThreshold, and answers the LLM failed to grade, are
+// queued for a human reviewer.
+func NewLLMScorer(grader AnswerGrader, timeout time.Duration, reviewThreshold float64) Scorer {
+	return &llmScorer{grader: grader, timeout: timeout, reviewThreshold: reviewThreshold}
+}
+
+func (s *llmScorer) DefaultPolicy() domain.ScoringPolicy { return domain.ScoringProportional }
+
+func (s *llmScorer) Deferred() bool { return true }
+
+// Score reports the LLM's 0-100 score as credit out of 100
+func (s *llmScorer) Score(question *domain.Question, answerData json.RawMessage) Outcome {
+	var data domain.OpenEndedData
+	if err := json.Unmarshal(question.QuestionData, &data); err != nil {
+		return Outcome{}
+	}
+	outcome := Outcome{Total: 100, Explanation: data.Explanation}
+
+	var answer string
+	if err := json.Unmarshal(answerData, &answer); err != nil || strings.TrimSpace(answer) == "" {
+		return outcome
+	}
+	if data.MaxLength > 0 {
+		if r := []rune(answer); len(r) > data.MaxLength {
+			answer = string(r[:data.MaxLength])
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	result, err := s.grader.GradeOpenAnswer(ctx, &domain.OpenAnswerGradingRequest{
+		QuestionText: question.QuestionText,
+		Rubric:       data.Rubric,
+		ModelAnswer:  data.ModelAnswer,
+		Answer:       answer,
+	})
+	if err != nil {
+		log.Printf("[Grading] LLM failed to grade an answer to question %s: %v", question.ID, err)
+		outcome.Judgement = &Judgement{
+			Rationale:   "Automatic grading failed: " + err.Error(),
+			NeedsReview: true,
+		}
+		return outcome
+	}
+
+	outcome.Correct = min(max(result.Score, 0), 100)
+	outcome.Judgement = &Judgement{
+		Rationale:   result.Rationale,
+		Confidence:  result.Confidence,
+		NeedsReview: result.Confidence < s.reviewThreshold,
+	}
+	return outcome
+}
+
+// gradingJobPayload is the input of a grading job
+type gradingJobPayload struct {
+	AttemptID uuid.UUID `json:"attemptId"`
+}
+
+// deferred reports whether answers to a question are graded by the grading
+// job rather than on submission
+func (uc *UseCase) deferred(question *domain.Question) bool {
+	scorer, ok := uc.scorers[question.QuestionType].(DeferredScorer)
+	return ok && scorer.Deferred()
+}
+
+// queueGrading has the grading job grade an attempt's deferred answers
+func (uc *UseCase) queueGrading(attemptID uuid.UUID) {
+	if _, err := uc.jobQueue.Enqueue(domain.JobTypeGradeAnswers, gradingJobPayload{AttemptID: attemptID}); err != nil {
+		log.Printf("[Grading] ERROR: Failed to queue grading of attempt %s: %v", attemptID, err)
+	}
+}
+
+// runGradingJob grades the answers of a completed attempt that wait for the
+// LLM, then regrades the attempt. Answers graded by an earlier try of the
+// job are kept.
+func (uc *UseCase) runGradingJob(ctx context.Context, job *domain.AIGenerationJob) error {
+	var payload gradingJobPayload
+	if err := queue.DecodePayload(job, &payload); err != nil {
+		return err
+	}
+
+	attempt, err := uc.attemptRepo.GetByID(payload.AttemptID)
+	if err != nil {
+		return err
+	}
+	if attempt == nil || attempt.CompletedAt == nil {
+		return nil
+	}
+	test, err := uc.testRepo.GetByID(attempt.TestID)
+	if err != nil {
+		return err
+	}
+	if test == nil {
+		return nil
+	}
+
+	questions, err := uc.questionRepo.GetByTestID(test.ID)
+	if err != nil {
+		return err
+	}
+	questionMap := make(map[uuid.UUID]*domain.Question, len(questions))
+	for _, q := range questions {
+		questionMap[q.ID] = q
+	}
+	set, err := decodeQuestionSet(attempt)
+	if err != nil {
+		return err
+	}
+	optionOrders := make(map[uuid.UUID][]int, len(set))
+	for _, entry := range set {
+		optionOrders[entry.QuestionID] = entry.OptionOrder
+	}
+
+	answers, err := uc.answerRepo.GetByAttemptID(attempt.ID)
+	if err != nil {
+		return err
+	}
+	reviews, err := uc.reviewRepo.GetByAttemptID(attempt.ID)
+	if err != nil {
+		return err
+	}
+	reviewed := make(map[uuid.UUID]*domain.AnswerReview, len(reviews))
+	for _, r := range reviews {
+		reviewed[r.UserAnswerID] = r
+	}
+
+	for _, answer := range answers {
+		question, ok := questionMap[answer.QuestionID]
+		if !ok {
+			continue
+		}
+		// An answer whose review couldn't be recorded on submission is
+		// still graded
+		review, ok := reviewed[answer.ID]
+		save := uc.reviewRepo.Judge
+		if !ok {
+			if !uc.deferred(question) {
+				continue
+			}
+			review = awaitingReview(answer)
+			save = uc.reviewRepo.Create
+		} else if review.Status != domain.ReviewStatusGrading {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		result := uc.gradeAnswer(test, question, canonicalAnswer(question, answer.AnswerData, optionOrders[answer.QuestionID]))
+		answer.PointsAwarded = result.points
+		answer.IsCorrect = result.isCorrect
+		if err := uc.answerRepo.UpdateGrade(answer); err != nil {
+			return err
+		}
+
+		review.SuggestedPoints = result.points
+		review.Status = domain.ReviewStatusAccepted
+		if result.judgement != nil {
+			review.Rationale = result.judgement.Rationale
+			review.Confidence = result.judgement.Confidence
+			if result.judgement.NeedsReview {
+				review.Status = domain.ReviewStatusPending
+			}
+		}
+		if err := save(review); err != nil {
+			return err
+		}
+	}
+
+	return uc.regradeAttempt(attempt.ID, answers, nil)
+}
+
+// failGradingJob hands the answers the grading job couldn't grade to a
+// human reviewer
+func (uc *UseCase) failGradingJob(job *domain.AIGenerationJob, jobErr error) {
+	var payload gradingJobPayload
+	if err := queue.DecodePayload(job, &payload); err != nil {
+		return
+	}
+	reviews, err := uc.reviewRepo.GetByAttemptID(payload.AttemptID)
+	if err != nil {
+		log.Printf("[Grading] ERROR: Failed to load reviews of attempt %s: %v", payload.AttemptID, err)
+		return
+	}
+	for _, review := range reviews {
+		if review.Status != domain.ReviewStatusGrading {
+			continue
+		}
+		review.Status = domain.ReviewStatusPending
+		review.Rationale = "Automatic grading failed: " + jobErr.Error()
+		if err := uc.reviewRepo.Judge(review); err != nil {
+			log.Printf("[Grading] ERROR: Failed to queue answer %s for review: %v", review.UserAnswerID, err)
+		}
+	}
+}
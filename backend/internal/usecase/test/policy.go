@@ -0,0 +1,82 @@
+package test
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/secusense/backend/internal/domain"
+)
+
+// CooldownError is returned when a user tries to start an attempt before the
+// test's cooldown since their previous attempt has passed.
+type CooldownError struct {
+	RetryAfter time.Duration
+}
+
+func (e *CooldownError) Error() string {
+	return fmt.Sprintf("next attempt possible in %d minute(s)", int(math.Ceil(e.RetryAfter.Minutes())))
+}
+
+// RetryAfterSeconds rounds the wait up, for use in a Retry-After header
+func (e *CooldownError) RetryAfterSeconds() int {
+	return int(math.Ceil(e.RetryAfter.Seconds()))
+}
+
+// checkAttemptPolicy enforces the test's retake policy for a user about to
+// start an attempt. Extra attempts granted by an administrator raise both the
+// attempt limit and the failure lockout threshold. When since is set, as for
+// a learner renewing a certificate, earlier attempts don't count. It runs
+// once the user's open attempt, if any, has been resumed or graded, so every
+// attempt it counts has been taken.
+func (uc *UseCase) checkAttemptPolicy(test *domain.Test, userID uuid.UUID, since *time.Time, now time.Time) error {
+	if test.MaxAttempts == nil && test.LockoutAfterFailures == nil && test.CooldownMinutes <= 0 {
+		return nil
+	}
+
+	attempts, err := uc.attemptRepo.GetByUserAndTest(userID, test.ID)
+	if err != nil {
+		return err
+	}
+	extra, err := uc.grantRepo.SumExtraAttempts(userID, test.ID)
+	if err != nil {
+		return err
+	}
+
+	if since != nil {
+		current := attempts[:0]
+		for _, a := range attempts {
+			if !a.StartedAt.Before(*since) {
+				current = append(current, a)
+			}
+		}
+		attempts = current
+	}
+
+	failures := 0
+	var lastCompleted *time.Time
+	for _, a := range attempts {
+		if a.Passed != nil && !*a.Passed {
+			failures++
+		}
+		if a.CompletedAt != nil && (lastCompleted == nil || a.CompletedAt.After(*lastCompleted)) {
+			lastCompleted = a.CompletedAt
+		}
+	}
+
+	if test.LockoutAfterFailures != nil && failures >= *test.LockoutAfterFailures+extra {
+		return ErrLockedOut
+	}
+	if test.MaxAttempts != nil && len(attempts) >= *test.MaxAttempts+extra {
+		return ErrMaxAttemptsReached
+	}
+	if test.CooldownMinutes > 0 && lastCompleted != nil {
+		readyAt := lastCompleted.Add(time.Duration(test.CooldownMinutes) * time.Minute)
+		if now.Before(readyAt) {
+			return &CooldownError{RetryAfter: readyAt.Sub(now)}
+		}
+	}
+
+	return nil
+}
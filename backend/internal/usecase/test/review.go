@@ -0,0 +1,225 @@
+package test
+
+import (
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/secusense/backend/internal/domain"
+)
+
+// awaitingReview marks an answer that is left to the grading job
+func awaitingReview(answer *domain.UserAnswer) *domain.AnswerReview {
+	return &domain.AnswerReview{
+		ID:           uuid.New(),
+		UserAnswerID: answer.ID,
+		AttemptID:    answer.AttemptID,
+		QuestionID:   answer.QuestionID,
+		Status:       domain.ReviewStatusGrading,
+	}
+}
+
+func newReview(answer *domain.UserAnswer, result grade) *domain.AnswerReview {
+	status := domain.ReviewStatusAccepted
+	if result.judgement.NeedsReview {
+		status = domain.ReviewStatusPending
+	}
+	return &domain.AnswerReview{
+		ID:              uuid.New(),
+		UserAnswerID:    answer.ID,
+		AttemptID:       answer.AttemptID,
+		QuestionID:      answer.QuestionID,
+		Status:          status,
+		SuggestedPoints: result.points,
+		Rationale:       result.judgement.Rationale,
+		Confidence:      result.judgement.Confidence,
+	}
+}
+
+// recordReviews stores the judged grades of a finished attempt. The attempt
+// is already graded, so a failure here only loses the audit trail and is
+// logged rather than returned.
+func (uc *UseCase) recordReviews(reviews []*domain.AnswerReview) {
+	for _, review := range reviews {
+		if err := uc.reviewRepo.Create(review); err != nil {
+			log.Printf("[Grading] Failed to record review of answer %s: %v", review.UserAnswerID, err)
+		}
+	}
+}
+
+// pendingReviews returns the answers of an attempt that await the LLM or a
+// reviewer
+func (uc *UseCase) pendingReviews(attemptID uuid.UUID) (map[uuid.UUID]bool, error) {
+	reviews, err := uc.reviewRepo.GetByAttemptID(attemptID)
+	if err != nil {
+		return nil, err
+	}
+	pending := make(map[uuid.UUID]bool)
+	for _, review := range reviews {
+		if review.AwaitingGrade() {
+			pending[review.UserAnswerID] = true
+		}
+	}
+	return pending, nil
+}
+
+// ListReviews returns the tenant's LLM grades with the given status, oldest
+// first
+func (uc *UseCase) ListReviews(tenant domain.Tenant, status domain.ReviewStatus, limit, offset int) ([]*domain.AnswerReview, error) {
+	if status == "" {
+		status = domain.ReviewStatusPending
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return uc.reviewRepo.ListByStatus(tenant, status, limit, offset)
+}
+
+// ResolveReview settles the grade of a reviewed answer. The attempt's score
+// and pass are recomputed; if the attempt no longer passes, the certificate
+// issued for it is revoked.
+func (uc *UseCase) ResolveReview(tenant domain.Tenant, reviewID, adminID uuid.UUID, req *domain.ResolveReviewRequest) (*domain.AnswerReview, error) {
+	review, err := uc.reviewRepo.GetByID(reviewID)
+	if err != nil {
+		return nil, err
+	}
+	if review == nil {
+		return nil, ErrReviewNotFound
+	}
+	if review.Status != domain.ReviewStatusPending {
+		return nil, ErrReviewNotPending
+	}
+	attempt, err := uc.attemptRepo.GetByID(review.AttemptID)
+	if err != nil {
+		return nil, err
+	}
+	if attempt == nil {
+		return nil, ErrReviewNotFound
+	}
+	if _, err := uc.getTest(tenant, attempt.TestID); err != nil {
+		if err == ErrTestNotFound {
+			return nil, ErrReviewNotFound
+		}
+		return nil, err
+	}
+	if req.PointsAwarded > review.MaxPoints {
+		return nil, ErrInvalidPoints
+	}
+
+	answers, err := uc.answerRepo.GetByAttemptID(review.AttemptID)
+	if err != nil {
+		return nil, err
+	}
+	var answer *domain.UserAnswer
+	for _, a := range answers {
+		if a.ID == review.UserAnswerID {
+			answer = a
+		}
+	}
+	if answer == nil {
+		return nil, ErrReviewNotFound
+	}
+
+	answer.PointsAwarded = req.PointsAwarded
+	answer.IsCorrect = req.PointsAwarded == review.MaxPoints
+	if err := uc.answerRepo.UpdateGrade(answer); err != nil {
+		return nil, err
+	}
+	if err := uc.regradeAttempt(review.AttemptID, answers, &adminID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	review.Status = domain.ReviewStatusConfirmed
+	if req.PointsAwarded != review.SuggestedPoints {
+		review.Status = domain.ReviewStatusOverridden
+	}
+	review.FinalPoints = &req.PointsAwarded
+	review.ReviewedBy = &adminID
+	review.ReviewedAt = &now
+	if req.Note != "" {
+		review.ReviewNote = &req.Note
+	}
+	if err := uc.reviewRepo.Resolve(review); err != nil {
+		return nil, err
+	}
+
+	return review, nil
+}
+
+// regradeAttempt recomputes a completed attempt's score from its answers.
+// The reviewer, if any, is recorded as revoking the certificate of an
+// attempt that no longer passes.
+func (uc *UseCase) regradeAttempt(attemptID uuid.UUID, answers []*domain.UserAnswer, reviewerID *uuid.UUID) error {
+	attempt, err := uc.attemptRepo.GetByID(attemptID)
+	if err != nil {
+		return err
+	}
+	if attempt == nil || attempt.CompletedAt == nil {
+		return ErrAttemptNotFound
+	}
+	test, err := uc.testRepo.GetByID(attempt.TestID)
+	if err != nil {
+		return err
+	}
+	if test == nil {
+		return ErrTestNotFound
+	}
+
+	var totalScore int
+	for _, a := range answers {
+		totalScore += a.PointsAwarded
+	}
+	if totalScore < 0 {
+		totalScore = 0
+	}
+
+	var percentage float64
+	if attempt.MaxScore != nil && *attempt.MaxScore > 0 {
+		percentage = float64(totalScore) / float64(*attempt.MaxScore) * 100
+	}
+	passed := percentage >= float64(test.PassingScore)
+	wasPassed := attempt.Passed != nil && *attempt.Passed
+
+	attempt.Score = &totalScore
+	attempt.Percentage = &percentage
+	attempt.Passed = &passed
+	if err := uc.attemptRepo.Update(attempt); err != nil {
+		return err
+	}
+
+	// The LRS keeps the first verdict; a regrade that changes it is reported
+	// as a new one
+	if passed != wasPassed && uc.recorder.Enabled() {
+		uc.recorder.TestFinished(attempt, uc.registration(attempt.UserID, test.CourseID), test)
+	}
+	uc.notifyGraded(attempt, test)
+
+	if !passed {
+		return uc.revokeCertificate(attemptID, reviewerID)
+	}
+	return nil
+}
+
+// revokeCertificate revokes the certificate issued for an attempt that
+// failed on regrading, if any
+func (uc *UseCase) revokeCertificate(attemptID uuid.UUID, reviewerID *uuid.UUID) error {
+	cert, err := uc.certRepo.GetByAttemptID(attemptID)
+	if err != nil {
+		return err
+	}
+	if cert == nil || cert.Revoked() {
+		return nil
+	}
+
+	now := time.Now()
+	reason := "The test was failed after its answers were regraded"
+	cert.RevokedAt = &now
+	cert.RevokedBy = reviewerID
+	cert.RevocationReason = &reason
+	_, err = uc.certRepo.Revoke(cert)
+	return err
+}
@@ -0,0 +1,453 @@
+package test
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/secusense/backend/internal/domain"
+)
+
+// Outcome is a scorer's verdict on one answer, in credit units: the question
+// is split into Total gradable parts (options, blanks, pairs, positions), of
+// which Correct were answered right and Wrong were answered wrong. Parts left
+// unanswered are neither.
+type Outcome struct {
+	Correct     int
+	Wrong       int
+	Total       int
+	Explanation string
+	// Judgement is set by scorers that grade by opinion rather than by
+	// counting, like the LLM grader; such grades are recorded for review
+	Judgement *Judgement
+}
+
+type Judgement struct {
+	Rationale   string
+	Confidence  float64
+	NeedsReview bool
+}
+
+// grade is a scored answer, in points
+type grade struct {
+	isCorrect   bool
+	points      int
+	explanation string
+	judgement   *Judgement
+}
+
+// Scorer grades the answers to one question type. Turning an outcome into
+// points is left to the scoring policy, so a scorer only needs to count.
+type Scorer interface {
+	Score(question *domain.Question, answerData json.RawMessage) Outcome
+	// DefaultPolicy is used when neither the question nor its test sets one
+	DefaultPolicy() domain.ScoringPolicy
+}
+
+// DeferredScorer is a scorer too slow to run while the learner waits, like
+// the LLM grader. Its answers count for nothing when the attempt is
+// submitted and are graded by a queued job, which then regrades the attempt.
+type DeferredScorer interface {
+	Scorer
+	Deferred() bool
+}
+
+// RegisterScorer installs the scorer for a question type, replacing any
+// scorer registered before. Questions of a type without scorer earn nothing.
+func (uc *UseCase) RegisterScorer(qType domain.QuestionType, scorer Scorer) {
+	uc.scorers[qType] = scorer
+}
+
+func defaultScorers() map[domain.QuestionType]Scorer {
+	return map[domain.QuestionType]Scorer{
+		domain.QuestionTypeMultipleChoice: multipleChoiceScorer{},
+		domain.QuestionTypeDragDrop:       dragDropScorer{},
+		domain.QuestionTypeFillBlank:      fillBlankScorer{},
+		domain.QuestionTypeMatching:       matchingScorer{},
+		domain.QuestionTypeOrdering:       orderingScorer{},
+		domain.QuestionTypeTrueFalse:      trueFalseScorer{},
+		domain.QuestionTypeNumeric:        numericScorer{},
+		domain.QuestionTypeHotspot:        hotspotScorer{},
+		domain.QuestionTypeShortText:      shortTextScorer{},
+	}
+}
+
+func (uc *UseCase) gradeAnswer(test *domain.Test, question *domain.Question, answerData json.RawMessage) grade {
+	scorer, ok := uc.scorers[question.QuestionType]
+	if !ok {
+		return grade{}
+	}
+
+	outcome := scorer.Score(question, answerData)
+	policy := resolvePolicy(test, question, scorer)
+
+	allowNegative := test.AllowNegative
+	if question.AllowNegative != nil {
+		allowNegative = *question.AllowNegative
+	}
+
+	return grade{
+		isCorrect:   outcome.Total > 0 && outcome.Correct == outcome.Total && outcome.Wrong == 0,
+		points:      applyPolicy(policy, outcome, question.Points, allowNegative),
+		explanation: outcome.Explanation,
+		judgement:   outcome.Judgement,
+	}
+}
+
+// resolvePolicy picks the question's own policy, then the test's, then the
+// scorer's default
+func resolvePolicy(test *domain.Test, question *domain.Question, scorer Scorer) domain.ScoringPolicy {
+	if question.ScoringPolicy != nil && *question.ScoringPolicy != "" {
+		return *question.ScoringPolicy
+	}
+	if test.ScoringPolicy != nil && *test.ScoringPolicy != "" {
+		return *test.ScoringPolicy
+	}
+	return scorer.DefaultPolicy()
+}
+
+// applyPolicy converts an outcome into points:
+//   - all_or_nothing: full points only if every part is right and none wrong
+//   - proportional: share of right parts, out of the parts expected or the
+//     parts answered if that is more (so selecting every option doesn't pay)
+//   - penalty: every wrong part cancels a right one, which can go negative
+//
+// Fractions are rounded to the nearest point. Unless negative scores are
+// allowed, a question is clamped to a minimum of zero.
+func applyPolicy(policy domain.ScoringPolicy, outcome Outcome, points int, allowNegative bool) int {
+	if outcome.Total == 0 {
+		return 0
+	}
+
+	var fraction float64
+	switch policy {
+	case domain.ScoringAllOrNothing:
+		if outcome.Correct == outcome.Total && outcome.Wrong == 0 {
+			fraction = 1
+		}
+	case domain.ScoringPenalty:
+		fraction = float64(outcome.Correct-outcome.Wrong) / float64(outcome.Total)
+	default:
+		denominator := outcome.Total
+		if answered := outcome.Correct + outcome.Wrong; answered > denominator {
+			denominator = answered
+		}
+		fraction = float64(outcome.Correct) / float64(denominator)
+	}
+
+	awarded := int(math.Round(float64(points) * fraction))
+	if !allowNegative && awarded < 0 {
+		return 0
+	}
+	return awarded
+}
+
+type multipleChoiceScorer struct{}
+
+func (multipleChoiceScorer) DefaultPolicy() domain.ScoringPolicy { return domain.ScoringAllOrNothing }
+
+func (multipleChoiceScorer) Score(question *domain.Question, answerData json.RawMessage) Outcome {
+	var data domain.MultipleChoiceData
+	if err := json.Unmarshal(question.QuestionData, &data); err != nil {
+		return Outcome{}
+	}
+	outcome := Outcome{Total: len(data.CorrectIndices), Explanation: data.Explanation}
+
+	var selected []int
+	if err := json.Unmarshal(answerData, &selected); err != nil {
+		return outcome
+	}
+
+	correctSet := make(map[int]bool)
+	for _, idx := range data.CorrectIndices {
+		correctSet[idx] = true
+	}
+
+	seen := make(map[int]bool)
+	for _, idx := range selected {
+		if seen[idx] {
+			continue
+		}
+		seen[idx] = true
+		if correctSet[idx] {
+			outcome.Correct++
+		} else {
+			outcome.Wrong++
+		}
+	}
+	return outcome
+}
+
+type dragDropScorer struct{}
+
+func (dragDropScorer) DefaultPolicy() domain.ScoringPolicy { return domain.ScoringProportional }
+
+func (dragDropScorer) Score(question *domain.Question, answerData json.RawMessage) Outcome {
+	var data domain.DragDropData
+	if err := json.Unmarshal(question.QuestionData, &data); err != nil {
+		return Outcome{}
+	}
+	outcome := Outcome{Total: len(data.CorrectMapping), Explanation: data.Explanation}
+
+	var mapping map[string]string
+	if err := json.Unmarshal(answerData, &mapping); err != nil {
+		return outcome
+	}
+	countPairs(&outcome, data.CorrectMapping, mapping)
+	return outcome
+}
+
+type fillBlankScorer struct{}
+
+func (fillBlankScorer) DefaultPolicy() domain.ScoringPolicy { return domain.ScoringProportional }
+
+func (fillBlankScorer) Score(question *domain.Question, answerData json.RawMessage) Outcome {
+	var data domain.FillBlankData
+	if err := json.Unmarshal(question.QuestionData, &data); err != nil {
+		return Outcome{}
+	}
+	outcome := Outcome{Total: len(data.Blanks), Explanation: data.Explanation}
+
+	var answers []string
+	if err := json.Unmarshal(answerData, &answers); err != nil {
+		return outcome
+	}
+	if len(answers) != len(data.Blanks) {
+		return outcome
+	}
+
+	for i, answer := range answers {
+		switch {
+		case strings.TrimSpace(answer) == "":
+		case blankAccepts(&data, i, answer):
+			outcome.Correct++
+		default:
+			outcome.Wrong++
+		}
+	}
+	return outcome
+}
+
+type matchingScorer struct{}
+
+func (matchingScorer) DefaultPolicy() domain.ScoringPolicy { return domain.ScoringProportional }
+
+func (matchingScorer) Score(question *domain.Question, answerData json.RawMessage) Outcome {
+	var data domain.MatchingData
+	if err := json.Unmarshal(question.QuestionData, &data); err != nil {
+		return Outcome{}
+	}
+	outcome := Outcome{Total: len(data.CorrectPairs), Explanation: data.Explanation}
+
+	var pairs map[string]string
+	if err := json.Unmarshal(answerData, &pairs); err != nil {
+		return outcome
+	}
+	countPairs(&outcome, data.CorrectPairs, pairs)
+	return outcome
+}
+
+type orderingScorer struct{}
+
+func (orderingScorer) DefaultPolicy() domain.ScoringPolicy { return domain.ScoringProportional }
+
+func (orderingScorer) Score(question *domain.Question, answerData json.RawMessage) Outcome {
+	var data domain.OrderingData
+	if err := json.Unmarshal(question.QuestionData, &data); err != nil {
+		return Outcome{}
+	}
+	outcome := Outcome{Total: len(data.CorrectOrder), Explanation: data.Explanation}
+
+	var order []int
+	if err := json.Unmarshal(answerData, &order); err != nil {
+		return outcome
+	}
+	if len(order) != len(data.CorrectOrder) {
+		return outcome
+	}
+
+	for i, pos := range order {
+		if pos == data.CorrectOrder[i] {
+			outcome.Correct++
+		} else {
+			outcome.Wrong++
+		}
+	}
+	return outcome
+}
+
+type trueFalseScorer struct{}
+
+func (trueFalseScorer) DefaultPolicy() domain.ScoringPolicy { return domain.ScoringAllOrNothing }
+
+func (trueFalseScorer) Score(question *domain.Question, answerData json.RawMessage) Outcome {
+	var data domain.TrueFalseData
+	if err := json.Unmarshal(question.QuestionData, &data); err != nil {
+		return Outcome{}
+	}
+	outcome := Outcome{Total: 1, Explanation: data.Explanation}
+
+	var answer *bool
+	if err := json.Unmarshal(answerData, &answer); err != nil || answer == nil {
+		return outcome
+	}
+	if *answer == data.CorrectAnswer {
+		outcome.Correct++
+	} else {
+		outcome.Wrong++
+	}
+	return outcome
+}
+
+type numericScorer struct{}
+
+func (numericScorer) DefaultPolicy() domain.ScoringPolicy { return domain.ScoringAllOrNothing }
+
+func (numericScorer) Score(question *domain.Question, answerData json.RawMessage) Outcome {
+	var data domain.NumericData
+	if err := json.Unmarshal(question.QuestionData, &data); err != nil {
+		return Outcome{}
+	}
+	outcome := Outcome{Total: 1, Explanation: data.Explanation}
+
+	var answer *float64
+	if err := json.Unmarshal(answerData, &answer); err != nil || answer == nil {
+		return outcome
+	}
+	// The epsilon absorbs float error at the edge of the range, e.g. 0.1+0.2
+	if math.Abs(*answer-data.CorrectValue) <= data.Tolerance+1e-9 {
+		outcome.Correct++
+	} else {
+		outcome.Wrong++
+	}
+	return outcome
+}
+
+type hotspotScorer struct{}
+
+func (hotspotScorer) DefaultPolicy() domain.ScoringPolicy { return domain.ScoringProportional }
+
+// Score counts every region clicked as found and every click outside all
+// regions as wrong; clicking a region again changes nothing.
+func (hotspotScorer) Score(question *domain.Question, answerData json.RawMessage) Outcome {
+	var data domain.HotspotData
+	if err := json.Unmarshal(question.QuestionData, &data); err != nil {
+		return Outcome{}
+	}
+	outcome := Outcome{Total: len(data.Regions), Explanation: data.Explanation}
+
+	var clicks []domain.HotspotClick
+	if err := json.Unmarshal(answerData, &clicks); err != nil {
+		return outcome
+	}
+
+	found := make(map[int]bool)
+	for _, click := range clicks {
+		hit := -1
+		for i, region := range data.Regions {
+			if regionContains(region, click) {
+				hit = i
+				break
+			}
+		}
+		switch {
+		case hit < 0:
+			outcome.Wrong++
+		case !found[hit]:
+			found[hit] = true
+			outcome.Correct++
+		}
+	}
+	return outcome
+}
+
+func regionContains(region domain.HotspotRegion, click domain.HotspotClick) bool {
+	switch region.Shape {
+	case domain.HotspotCircle:
+		dx, dy := click.X-region.X, click.Y-region.Y
+		return dx*dx+dy*dy <= region.Radius*region.Radius
+	case domain.HotspotRect:
+		return click.X >= region.X && click.X <= region.X+region.Width &&
+			click.Y >= region.Y && click.Y <= region.Y+region.Height
+	}
+	return false
+}
+
+type shortTextScorer struct{}
+
+func (shortTextScorer) DefaultPolicy() domain.ScoringPolicy { return domain.ScoringProportional }
+
+// Score credits each rubric item the answer mentions by one of its terms, as a
+// whole word or phrase. Text past MaxLength is ignored, so an answer can't
+// collect credit by listing every term it can think of.
+func (shortTextScorer) Score(question *domain.Question, answerData json.RawMessage) Outcome {
+	var data domain.ShortTextData
+	if err := json.Unmarshal(question.QuestionData, &data); err != nil {
+		return Outcome{}
+	}
+	outcome := Outcome{Total: len(data.Rubric), Explanation: data.Explanation}
+
+	var answer string
+	if err := json.Unmarshal(answerData, &answer); err != nil {
+		return outcome
+	}
+	if data.MaxLength > 0 {
+		if r := []rune(answer); len(r) > data.MaxLength {
+			answer = string(r[:data.MaxLength])
+		}
+	}
+	text := normalizeAnswer(answer, false)
+	if text == "" {
+		return outcome
+	}
+
+	for _, item := range data.Rubric {
+		for _, term := range item.Terms {
+			if term = normalizeAnswer(term, false); term != "" && containsWord(text, term) {
+				outcome.Correct++
+				break
+			}
+		}
+	}
+	return outcome
+}
+
+// containsWord reports whether term occurs in text not as part of a longer
+// word, so "rat" doesn't match inside "separate"
+func containsWord(text, term string) bool {
+	for offset := 0; offset < len(text); {
+		i := strings.Index(text[offset:], term)
+		if i < 0 {
+			return false
+		}
+		start, end := offset+i, offset+i+len(term)
+		before, _ := utf8.DecodeLastRuneInString(text[:start])
+		after, _ := utf8.DecodeRuneInString(text[end:])
+		if (start == 0 || !isWordRune(before)) && (end == len(text) || !isWordRune(after)) {
+			return true
+		}
+		offset = start + 1
+	}
+	return false
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// countPairs compares a key→value answer with the expected pairs; keys left
+// unassigned count as unanswered
+func countPairs(outcome *Outcome, expected, given map[string]string) {
+	for key, want := range expected {
+		got, ok := given[key]
+		switch {
+		case !ok || got == "":
+		case got == want:
+			outcome.Correct++
+		default:
+			outcome.Wrong++
+		}
+	}
+}
@@ -3,27 +3,55 @@ package test
 import (
 	"encoding/json"
 	"errors"
+	"log"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/secusense/backend/infrastructure/queue"
+	"github.com/secusense/backend/infrastructure/xapi"
 	"github.com/secusense/backend/internal/domain"
 )
 
 var (
-	ErrTestNotFound       = errors.New("test not found")
-	ErrAttemptNotFound    = errors.New("attempt not found")
-	ErrAttemptCompleted   = errors.New("attempt already completed")
-	ErrVideoNotWatched    = errors.New("video must be watched before taking the test")
-	ErrNotEnrolled        = errors.New("not enrolled in this course")
+	ErrTestNotFound         = errors.New("test not found")
+	ErrAttemptNotFound      = errors.New("attempt not found")
+	ErrAttemptCompleted     = errors.New("attempt already completed")
+	ErrVideoNotWatched      = errors.New("video must be watched before taking the test")
+	ErrNotEnrolled          = errors.New("not enrolled in this course")
+	ErrMaxAttemptsReached   = errors.New("maximum number of attempts reached")
+	ErrLockedOut            = errors.New("too many failed attempts; ask an administrator for another attempt")
+	ErrNoOpenAttempt        = errors.New("no open attempt")
+	ErrQuestionNotInAttempt = errors.New("question is not part of this attempt")
+	ErrInvalidQuestionData  = errors.New("invalid question data")
+	ErrTimeLimitExceeded    = errors.New("time limit exceeded; the attempt was graded with the answers saved before the deadline")
+	ErrReviewNotFound       = errors.New("review not found")
+	ErrReviewNotPending     = errors.New("review is not awaiting a reviewer")
+	ErrCourseNotFound       = errors.New("course not found")
+	ErrQuestionNotFound     = errors.New("question not found")
+	ErrInvalidPoints        = errors.New("points exceed the question's maximum")
+	ErrUnsupportedFormat    = errors.New("unsupported question bank format")
+	ErrInvalidImportFile    = errors.New("invalid import file")
 )
 
+// Expired attempts are auto-submitted in batches of this size
+const sweepBatchSize = 100
+
 type UseCase struct {
 	testRepo       domain.TestRepository
 	questionRepo   domain.QuestionRepository
 	attemptRepo    domain.TestAttemptRepository
 	answerRepo     domain.UserAnswerRepository
+	grantRepo      domain.TestAttemptGrantRepository
+	reviewRepo     domain.AnswerReviewRepository
 	enrollmentRepo domain.EnrollmentRepository
 	courseRepo     domain.CourseRepository
+	certRepo       domain.CertificateRepository
+	recorder       *xapi.Recorder
+	jobQueue       *queue.Queue
+	gracePeriod    time.Duration
+	scorers        map[domain.QuestionType]Scorer
+	codecs         map[domain.InterchangeFormat]QuestionCodec
+	listeners      []AttemptListener
 }
 
 func NewUseCase(
@@ -31,20 +59,73 @@ func NewUseCase(
 	questionRepo domain.QuestionRepository,
 	attemptRepo domain.TestAttemptRepository,
 	answerRepo domain.UserAnswerRepository,
+	grantRepo domain.TestAttemptGrantRepository,
+	reviewRepo domain.AnswerReviewRepository,
 	enrollmentRepo domain.EnrollmentRepository,
 	courseRepo domain.CourseRepository,
+	certRepo domain.CertificateRepository,
+	recorder *xapi.Recorder,
+	jobQueue *queue.Queue,
+	gracePeriod time.Duration,
 ) *UseCase {
-	return &UseCase{
+	uc := &UseCase{
 		testRepo:       testRepo,
 		questionRepo:   questionRepo,
 		attemptRepo:    attemptRepo,
 		answerRepo:     answerRepo,
+		grantRepo:      grantRepo,
+		reviewRepo:     reviewRepo,
 		enrollmentRepo: enrollmentRepo,
 		courseRepo:     courseRepo,
+		certRepo:       certRepo,
+		recorder:       recorder,
+		jobQueue:       jobQueue,
+		gracePeriod:    gracePeriod,
+		scorers:        defaultScorers(),
+		codecs:         make(map[domain.InterchangeFormat]QuestionCodec),
+	}
+	jobQueue.Register(domain.JobTypeGradeAnswers, uc.runGradingJob, uc.failGradingJob)
+	return uc
+}
+
+// allowsCourse reports whether a course exists and belongs to the tenant
+func (uc *UseCase) allowsCourse(tenant domain.Tenant, courseID uuid.UUID) (bool, error) {
+	course, err := uc.courseRepo.GetByID(courseID)
+	if err != nil {
+		return false, err
+	}
+	return course != nil && tenant.Allows(course.OrganizationID), nil
+}
+
+// getTest loads a test the tenant may see. Tests belong to the
+// organization of their course.
+func (uc *UseCase) getTest(tenant domain.Tenant, testID uuid.UUID) (*domain.Test, error) {
+	test, err := uc.testRepo.GetByID(testID)
+	if err != nil {
+		return nil, err
 	}
+	if test == nil {
+		return nil, ErrTestNotFound
+	}
+	allowed, err := uc.allowsCourse(tenant, test.CourseID)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, ErrTestNotFound
+	}
+	return test, nil
 }
 
-func (uc *UseCase) GetByCourseID(courseID uuid.UUID) (*domain.Test, error) {
+// getCourseTest loads the test of a course the tenant may see
+func (uc *UseCase) getCourseTest(tenant domain.Tenant, courseID uuid.UUID) (*domain.Test, error) {
+	allowed, err := uc.allowsCourse(tenant, courseID)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, ErrTestNotFound
+	}
 	test, err := uc.testRepo.GetByCourseID(courseID)
 	if err != nil {
 		return nil, err
@@ -52,25 +133,73 @@ func (uc *UseCase) GetByCourseID(courseID uuid.UUID) (*domain.Test, error) {
 	if test == nil {
 		return nil, ErrTestNotFound
 	}
+	return test, nil
+}
+
+// getQuestion loads a question of a test the tenant may see
+func (uc *UseCase) getQuestion(tenant domain.Tenant, questionID uuid.UUID) (*domain.Question, error) {
+	question, err := uc.questionRepo.GetByID(questionID)
+	if err != nil {
+		return nil, err
+	}
+	if question == nil {
+		return nil, ErrQuestionNotFound
+	}
+	if _, err := uc.getTest(tenant, question.TestID); err != nil {
+		if err == ErrTestNotFound {
+			return nil, ErrQuestionNotFound
+		}
+		return nil, err
+	}
+	return question, nil
+}
+
+func (uc *UseCase) GetByCourseID(tenant domain.Tenant, courseID uuid.UUID) (*domain.Test, error) {
+	test, err := uc.getCourseTest(tenant, courseID)
+	if err != nil {
+		return nil, err
+	}
 
 	// Load questions
 	questions, err := uc.questionRepo.GetByTestID(test.ID)
 	if err != nil {
 		return nil, err
 	}
-	test.Questions = questions
+
+	// Learners get their draw when they start an attempt; handing out the
+	// whole pool here would defeat it
+	if !test.DrawsFromPool(len(questions)) {
+		test.Questions = questions
+	}
 
 	return test, nil
 }
 
-func (uc *UseCase) CreateTest(req *domain.CreateTestRequest) (*domain.Test, error) {
+func (uc *UseCase) CreateTest(tenant domain.Tenant, req *domain.CreateTestRequest) (*domain.Test, error) {
+	allowed, err := uc.allowsCourse(tenant, req.CourseID)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, ErrCourseNotFound
+	}
+
 	test := &domain.Test{
-		ID:               uuid.New(),
-		CourseID:         req.CourseID,
-		Title:            req.Title,
-		Description:      req.Description,
-		TimeLimitMinutes: req.TimeLimitMinutes,
-		PassingScore:     req.PassingScore,
+		ID:                   uuid.New(),
+		CourseID:             req.CourseID,
+		Title:                req.Title,
+		Description:          req.Description,
+		TimeLimitMinutes:     req.TimeLimitMinutes,
+		PassingScore:         req.PassingScore,
+		QuestionCount:        req.QuestionCount,
+		StratifyBy:           req.StratifyBy,
+		ShuffleQuestions:     req.ShuffleQuestions,
+		ShuffleOptions:       req.ShuffleOptions,
+		MaxAttempts:          req.MaxAttempts,
+		CooldownMinutes:      req.CooldownMinutes,
+		LockoutAfterFailures: req.LockoutAfterFailures,
+		ScoringPolicy:        req.ScoringPolicy,
+		AllowNegative:        req.AllowNegative,
 	}
 
 	if err := uc.testRepo.Create(test); err != nil {
@@ -80,15 +209,82 @@ func (uc *UseCase) CreateTest(req *domain.CreateTestRequest) (*domain.Test, erro
 	return test, nil
 }
 
-func (uc *UseCase) CreateQuestion(req *domain.CreateQuestionRequest) (*domain.Question, error) {
+// UpdateTest updates a test's settings, including how attempts draw from its
+// question pool. Attempts already started keep the questions they were given.
+func (uc *UseCase) UpdateTest(tenant domain.Tenant, testID uuid.UUID, req *domain.UpdateTestRequest) (*domain.Test, error) {
+	test, err := uc.getTest(tenant, testID)
+	if err != nil {
+		return nil, err
+	}
+
+	test.Title = req.Title
+	test.Description = req.Description
+	test.TimeLimitMinutes = req.TimeLimitMinutes
+	test.PassingScore = req.PassingScore
+	test.QuestionCount = req.QuestionCount
+	test.StratifyBy = req.StratifyBy
+	if test.StratifyBy == "" {
+		test.StratifyBy = domain.StratifyNone
+	}
+	test.ShuffleQuestions = req.ShuffleQuestions
+	test.ShuffleOptions = req.ShuffleOptions
+	test.MaxAttempts = req.MaxAttempts
+	test.CooldownMinutes = req.CooldownMinutes
+	test.LockoutAfterFailures = req.LockoutAfterFailures
+	test.ScoringPolicy = req.ScoringPolicy
+	test.AllowNegative = req.AllowNegative
+
+	if err := uc.testRepo.Update(test); err != nil {
+		return nil, err
+	}
+
+	return test, nil
+}
+
+// GrantAttempts gives a user extra attempts beyond the test's retake policy,
+// which also lifts a failure lockout
+func (uc *UseCase) GrantAttempts(tenant domain.Tenant, testID, adminID uuid.UUID, req *domain.GrantAttemptsRequest) (*domain.TestAttemptGrant, error) {
+	if _, err := uc.getTest(tenant, testID); err != nil {
+		return nil, err
+	}
+
+	grant := &domain.TestAttemptGrant{
+		ID:            uuid.New(),
+		TestID:        testID,
+		UserID:        req.UserID,
+		ExtraAttempts: req.ExtraAttempts,
+		GrantedBy:     &adminID,
+	}
+	if req.Reason != "" {
+		grant.Reason = &req.Reason
+	}
+
+	if err := uc.grantRepo.Create(grant); err != nil {
+		return nil, err
+	}
+
+	return grant, nil
+}
+
+func (uc *UseCase) CreateQuestion(tenant domain.Tenant, req *domain.CreateQuestionRequest) (*domain.Question, error) {
+	if _, err := uc.getTest(tenant, req.TestID); err != nil {
+		return nil, err
+	}
+	if err := validateQuestionData(req.QuestionType, req.QuestionData); err != nil {
+		return nil, err
+	}
+
 	question := &domain.Question{
-		ID:           uuid.New(),
-		TestID:       req.TestID,
-		QuestionType: req.QuestionType,
-		QuestionText: req.QuestionText,
-		QuestionData: req.QuestionData,
-		Points:       req.Points,
-		OrderIndex:   req.OrderIndex,
+		ID:            uuid.New(),
+		TestID:        req.TestID,
+		QuestionType:  req.QuestionType,
+		QuestionText:  req.QuestionText,
+		QuestionData:  req.QuestionData,
+		Points:        req.Points,
+		OrderIndex:    req.OrderIndex,
+		Tags:          encodeTags(req.Tags),
+		ScoringPolicy: req.ScoringPolicy,
+		AllowNegative: req.AllowNegative,
 	}
 
 	if err := uc.questionRepo.Create(question); err != nil {
@@ -127,20 +323,182 @@ func (uc *UseCase) StartAttempt(userID, testID uuid.UUID) (*domain.TestAttempt,
 		}
 	}
 
+	// Starting again while an attempt is open hands that attempt back, so a
+	// reload or a second tab doesn't use up the attempt limit. An open
+	// attempt past its time limit is graded first and counts as taken.
+	open, err := uc.attemptRepo.GetLatestByUserAndTest(userID, testID)
+	if err != nil {
+		return nil, err
+	}
+	if open != nil && open.CompletedAt == nil {
+		if !uc.pastGrace(open, time.Now()) {
+			return uc.ResumeAttempt(userID, testID)
+		}
+		if _, err := uc.finishWithSavedAnswers(open, test); err != nil && err != ErrAttemptCompleted {
+			return nil, err
+		}
+	}
+
+	if err := uc.checkAttemptPolicy(test, userID, enrollment.RenewalStartedAt, time.Now()); err != nil {
+		return nil, err
+	}
+
+	questions, err := uc.questionRepo.GetByTestID(testID)
+	if err != nil {
+		return nil, err
+	}
+
+	set := assembleQuestionSet(test, questions)
+	questionSet, err := json.Marshal(set)
+	if err != nil {
+		return nil, err
+	}
+
 	attempt := &domain.TestAttempt{
-		ID:     uuid.New(),
-		UserID: userID,
-		TestID: testID,
+		ID:          uuid.New(),
+		UserID:      userID,
+		TestID:      testID,
+		QuestionSet: questionSet,
+	}
+
+	if test.TimeLimitMinutes != nil {
+		deadline := time.Now().Add(time.Duration(*test.TimeLimitMinutes) * time.Minute)
+		attempt.DeadlineAt = &deadline
 	}
 
 	if err := uc.attemptRepo.Create(attempt); err != nil {
 		return nil, err
 	}
 
+	attempt.Questions = presentQuestions(set, questions)
+	attempt.RemainingSeconds = attempt.Remaining(time.Now())
+
+	return attempt, nil
+}
+
+// ResumeAttempt returns the user's open attempt at a test with the questions
+// as they were first presented and the answers saved so far.
+func (uc *UseCase) ResumeAttempt(userID, testID uuid.UUID) (*domain.TestAttempt, error) {
+	attempt, err := uc.attemptRepo.GetLatestByUserAndTest(userID, testID)
+	if err != nil {
+		return nil, err
+	}
+	if attempt == nil || attempt.CompletedAt != nil {
+		return nil, ErrNoOpenAttempt
+	}
+	if uc.pastGrace(attempt, time.Now()) {
+		return nil, uc.expireAttempt(attempt)
+	}
+
+	questions, err := uc.questionRepo.GetByTestID(testID)
+	if err != nil {
+		return nil, err
+	}
+
+	set, err := decodeQuestionSet(attempt)
+	if err != nil {
+		return nil, err
+	}
+	if len(set) == 0 {
+		// Started before question sets were stored: it shows the whole test
+		for _, q := range questions {
+			set = append(set, domain.AttemptQuestion{QuestionID: q.ID})
+		}
+	}
+
+	answers, err := uc.answerRepo.GetByAttemptID(attempt.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	attempt.Questions = presentQuestions(set, questions)
+	attempt.Answers = answers
+	attempt.RemainingSeconds = attempt.Remaining(time.Now())
+
 	return attempt, nil
 }
 
+// SaveAnswer stores one answer of an open attempt, replacing an earlier
+// answer to the same question. Saved answers are graded on submission.
+func (uc *UseCase) SaveAnswer(attemptID, userID uuid.UUID, req *domain.SubmitAnswerRequest) (*domain.UserAnswer, error) {
+	attempt, err := uc.getOpenAttempt(attemptID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if uc.pastGrace(attempt, time.Now()) {
+		return nil, uc.expireAttempt(attempt)
+	}
+
+	set, err := decodeQuestionSet(attempt)
+	if err != nil {
+		return nil, err
+	}
+	if len(set) > 0 {
+		inSet := false
+		for _, entry := range set {
+			if entry.QuestionID == req.QuestionID {
+				inSet = true
+				break
+			}
+		}
+		if !inSet {
+			return nil, ErrQuestionNotInAttempt
+		}
+	} else {
+		question, err := uc.questionRepo.GetByID(req.QuestionID)
+		if err != nil {
+			return nil, err
+		}
+		if question == nil || question.TestID != attempt.TestID {
+			return nil, ErrQuestionNotInAttempt
+		}
+	}
+
+	answer := &domain.UserAnswer{
+		ID:               uuid.New(),
+		AttemptID:        attemptID,
+		QuestionID:       req.QuestionID,
+		AnswerData:       req.AnswerData,
+		TimeSpentSeconds: capTimeSpent(attempt, req.TimeSpentSeconds, time.Now()),
+	}
+
+	if err := uc.answerRepo.Upsert(answer); err != nil {
+		return nil, err
+	}
+
+	return answer, nil
+}
+
 func (uc *UseCase) SubmitAttempt(attemptID uuid.UUID, userID uuid.UUID, submission *domain.SubmitTestRequest) (*domain.TestResult, error) {
+	attempt, err := uc.getOpenAttempt(attemptID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Past the grace window the submitted answers no longer count: the
+	// attempt is graded like the sweeper would have done
+	if uc.pastGrace(attempt, time.Now()) {
+		return nil, uc.expireAttempt(attempt)
+	}
+
+	test, err := uc.testRepo.GetByID(attempt.TestID)
+	if err != nil {
+		return nil, err
+	}
+	if test == nil {
+		return nil, ErrTestNotFound
+	}
+
+	saved, err := uc.savedAnswers(attemptID)
+	if err != nil {
+		return nil, err
+	}
+
+	return uc.finishAttempt(attempt, test, mergeAnswers(saved, submission.Answers))
+}
+
+// getOpenAttempt loads an attempt of the user that has not been completed
+func (uc *UseCase) getOpenAttempt(attemptID, userID uuid.UUID) (*domain.TestAttempt, error) {
 	attempt, err := uc.attemptRepo.GetByID(attemptID)
 	if err != nil {
 		return nil, err
@@ -154,18 +512,146 @@ func (uc *UseCase) SubmitAttempt(attemptID uuid.UUID, userID uuid.UUID, submissi
 	if attempt.CompletedAt != nil {
 		return nil, ErrAttemptCompleted
 	}
+	return attempt, nil
+}
 
-	// Get test and questions
+// expireAttempt grades an attempt that ran out of time with its saved
+// answers and returns ErrTimeLimitExceeded, or the error that prevented it
+func (uc *UseCase) expireAttempt(attempt *domain.TestAttempt) error {
 	test, err := uc.testRepo.GetByID(attempt.TestID)
+	if err != nil {
+		return err
+	}
+	if test == nil {
+		return ErrTestNotFound
+	}
+	if _, err := uc.finishWithSavedAnswers(attempt, test); err != nil {
+		return err
+	}
+	return ErrTimeLimitExceeded
+}
+
+// SweepExpiredAttempts grades every open attempt whose deadline and grace
+// window have passed, using whatever answers were saved. It returns how many
+// attempts were finished.
+func (uc *UseCase) SweepExpiredAttempts() (int, error) {
+	swept := 0
+	for {
+		attempts, err := uc.attemptRepo.ListExpired(time.Now().Add(-uc.gracePeriod), sweepBatchSize)
+		if err != nil {
+			return swept, err
+		}
+
+		finished := 0
+		for _, attempt := range attempts {
+			test, err := uc.testRepo.GetByID(attempt.TestID)
+			if err != nil {
+				return swept, err
+			}
+			if test == nil {
+				continue
+			}
+			if _, err := uc.finishWithSavedAnswers(attempt, test); err != nil {
+				if errors.Is(err, ErrAttemptCompleted) {
+					// Submitted in the meantime
+					continue
+				}
+				log.Printf("[Tests] ERROR: Failed to auto-submit attempt %s: %v", attempt.ID, err)
+				continue
+			}
+			finished++
+		}
+		swept += finished
+
+		// A full batch means there may be more; stop if nothing could be
+		// finished so a failing attempt can't keep us looping
+		if len(attempts) < sweepBatchSize || finished == 0 {
+			return swept, nil
+		}
+	}
+}
+
+// pastGrace reports whether a submission at now is too late to be accepted
+func (uc *UseCase) pastGrace(attempt *domain.TestAttempt, now time.Time) bool {
+	return attempt.DeadlineAt != nil && now.After(attempt.DeadlineAt.Add(uc.gracePeriod))
+}
+
+// capTimeSpent bounds the client-reported time on a question by how long
+// the attempt has been open
+func capTimeSpent(attempt *domain.TestAttempt, seconds *int, now time.Time) *int {
+	if seconds == nil {
+		return nil
+	}
+	capped := min(*seconds, int(now.Sub(attempt.StartedAt).Seconds()))
+	capped = max(capped, 0)
+	return &capped
+}
+
+func (uc *UseCase) finishWithSavedAnswers(attempt *domain.TestAttempt, test *domain.Test) (*domain.TestResult, error) {
+	answers, err := uc.savedAnswers(attempt.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	attempt.AutoSubmitted = true
+	return uc.finishAttempt(attempt, test, answers)
+}
+
+func (uc *UseCase) savedAnswers(attemptID uuid.UUID) ([]domain.SubmitAnswerRequest, error) {
+	saved, err := uc.answerRepo.GetByAttemptID(attemptID)
 	if err != nil {
 		return nil, err
 	}
 
+	answers := make([]domain.SubmitAnswerRequest, 0, len(saved))
+	for _, a := range saved {
+		answers = append(answers, domain.SubmitAnswerRequest{
+			QuestionID:       a.QuestionID,
+			AnswerData:       a.AnswerData,
+			TimeSpentSeconds: a.TimeSpentSeconds,
+		})
+	}
+	return answers, nil
+}
+
+// mergeAnswers combines saved answers with a final payload. The payload wins
+// for questions answered in both, keeping the saved time spent if it reports
+// none; each question appears once.
+func mergeAnswers(saved, final []domain.SubmitAnswerRequest) []domain.SubmitAnswerRequest {
+	index := make(map[uuid.UUID]int, len(saved)+len(final))
+	merged := make([]domain.SubmitAnswerRequest, 0, len(saved)+len(final))
+	for _, answers := range [][]domain.SubmitAnswerRequest{saved, final} {
+		for _, a := range answers {
+			if i, ok := index[a.QuestionID]; ok {
+				if a.TimeSpentSeconds == nil {
+					a.TimeSpentSeconds = merged[i].TimeSpentSeconds
+				}
+				merged[i] = a
+				continue
+			}
+			index[a.QuestionID] = len(merged)
+			merged = append(merged, a)
+		}
+	}
+	return merged
+}
+
+// finishAttempt grades the answers, completes the attempt and stores the
+// graded answers in place of any saved ones. Answers to deferred questions
+// are left to the grading job.
+func (uc *UseCase) finishAttempt(attempt *domain.TestAttempt, test *domain.Test, submitted []domain.SubmitAnswerRequest) (*domain.TestResult, error) {
+	attemptID := attempt.ID
+
 	questions, err := uc.questionRepo.GetByTestID(test.ID)
 	if err != nil {
 		return nil, err
 	}
 
+	set, err := decodeQuestionSet(attempt)
+	if err != nil {
+		return nil, err
+	}
+
 	// Build question map
 	questionMap := make(map[uuid.UUID]*domain.Question)
 	for _, q := range questions {
@@ -176,39 +662,75 @@ func (uc *UseCase) SubmitAttempt(attemptID uuid.UUID, userID uuid.UUID, submissi
 	var totalScore, maxScore int
 	var answers []*domain.UserAnswer
 	var answerResults []*domain.AnswerResult
+	var reviews []*domain.AnswerReview
+	var gradeLater bool
+
+	// Attempts with a question set are scored out of every question they were
+	// shown, answered or not, and only those questions count
+	optionOrders := make(map[uuid.UUID][]int, len(set))
+	if len(set) > 0 {
+		drawnMap := make(map[uuid.UUID]*domain.Question, len(set))
+		for _, entry := range set {
+			if q, ok := questionMap[entry.QuestionID]; ok {
+				drawnMap[entry.QuestionID] = q
+				optionOrders[entry.QuestionID] = entry.OptionOrder
+				maxScore += q.Points
+			}
+		}
+		questionMap = drawnMap
+	}
 
-	for _, sub := range submission.Answers {
+	for _, sub := range submitted {
 		question, exists := questionMap[sub.QuestionID]
 		if !exists {
 			continue
 		}
 
-		isCorrect, points, explanation := uc.gradeAnswer(question, sub.AnswerData)
-		maxScore += question.Points
-		totalScore += points
+		// Stored answers stay as the learner gave them; only grading needs
+		// the original option order
+		deferred := uc.deferred(question)
+		var result grade
+		if !deferred {
+			graded := canonicalAnswer(question, sub.AnswerData, optionOrders[sub.QuestionID])
+			result = uc.gradeAnswer(test, question, graded)
+		}
+		if len(set) == 0 {
+			maxScore += question.Points
+		}
+		totalScore += result.points
 
 		answer := &domain.UserAnswer{
-			ID:            uuid.New(),
-			AttemptID:     attemptID,
-			QuestionID:    sub.QuestionID,
-			AnswerData:    sub.AnswerData,
-			IsCorrect:     isCorrect,
-			PointsAwarded: points,
+			ID:               uuid.New(),
+			AttemptID:        attemptID,
+			QuestionID:       sub.QuestionID,
+			AnswerData:       sub.AnswerData,
+			IsCorrect:        result.isCorrect,
+			PointsAwarded:    result.points,
+			TimeSpentSeconds: capTimeSpent(attempt, sub.TimeSpentSeconds, time.Now()),
 		}
 		answers = append(answers, answer)
+		switch {
+		case deferred:
+			reviews = append(reviews, awaitingReview(answer))
+			gradeLater = true
+		case result.judgement != nil:
+			reviews = append(reviews, newReview(answer, result))
+		}
 
 		answerResults = append(answerResults, &domain.AnswerResult{
 			QuestionID:    sub.QuestionID,
-			IsCorrect:     isCorrect,
-			PointsAwarded: points,
+			IsCorrect:     result.isCorrect,
+			PointsAwarded: result.points,
 			MaxPoints:     question.Points,
-			Explanation:   explanation,
+			Explanation:   result.explanation,
+			UnderReview:   deferred || (result.judgement != nil && result.judgement.NeedsReview),
 		})
 	}
 
-	// Save answers
-	if err := uc.answerRepo.CreateBatch(answers); err != nil {
-		return nil, err
+	// Negative marking may cost points on a question, but never the whole
+	// attempt
+	if totalScore < 0 {
+		totalScore = 0
 	}
 
 	// Calculate percentage
@@ -226,9 +748,21 @@ func (uc *UseCase) SubmitAttempt(attemptID uuid.UUID, userID uuid.UUID, submissi
 	attempt.Percentage = &percentage
 	attempt.Passed = &passed
 
-	if err := uc.attemptRepo.Update(attempt); err != nil {
+	// Completing claims the attempt, so a concurrent submit or sweep can't
+	// grade it twice; its answers are stored in the same transaction
+	completed, err := uc.attemptRepo.Complete(attempt, answers)
+	if err != nil {
 		return nil, err
 	}
+	if !completed {
+		return nil, ErrAttemptCompleted
+	}
+	uc.recordReviews(reviews)
+	uc.recordStatements(attempt, test, questionMap, answers)
+	uc.notifyGraded(attempt, test)
+	if gradeLater {
+		uc.queueGrading(attemptID)
+	}
 
 	return &domain.TestResult{
 		AttemptID:  attemptID,
@@ -240,163 +774,49 @@ func (uc *UseCase) SubmitAttempt(attemptID uuid.UUID, userID uuid.UUID, submissi
 	}, nil
 }
 
-func (uc *UseCase) gradeAnswer(question *domain.Question, answerData json.RawMessage) (bool, int, string) {
-	switch question.QuestionType {
-	case domain.QuestionTypeMultipleChoice:
-		return uc.gradeMultipleChoice(question, answerData)
-	case domain.QuestionTypeDragDrop:
-		return uc.gradeDragDrop(question, answerData)
-	case domain.QuestionTypeFillBlank:
-		return uc.gradeFillBlank(question, answerData)
-	case domain.QuestionTypeMatching:
-		return uc.gradeMatching(question, answerData)
-	case domain.QuestionTypeOrdering:
-		return uc.gradeOrdering(question, answerData)
-	default:
-		return false, 0, ""
-	}
-}
-
-func (uc *UseCase) gradeMultipleChoice(question *domain.Question, answerData json.RawMessage) (bool, int, string) {
-	var data domain.MultipleChoiceData
-	if err := json.Unmarshal(question.QuestionData, &data); err != nil {
-		return false, 0, ""
+// recordStatements reports a finished attempt's answers and result as xAPI
+// statements
+func (uc *UseCase) recordStatements(attempt *domain.TestAttempt, test *domain.Test, questions map[uuid.UUID]*domain.Question, answers []*domain.UserAnswer) {
+	if !uc.recorder.Enabled() {
+		return
 	}
-
-	var selected []int
-	if err := json.Unmarshal(answerData, &selected); err != nil {
-		return false, 0, data.Explanation
-	}
-
-	if len(selected) != len(data.CorrectIndices) {
-		return false, 0, data.Explanation
-	}
-
-	correctSet := make(map[int]bool)
-	for _, idx := range data.CorrectIndices {
-		correctSet[idx] = true
-	}
-
-	for _, idx := range selected {
-		if !correctSet[idx] {
-			return false, 0, data.Explanation
+	registration := uc.registration(attempt.UserID, test.CourseID)
+	for _, a := range answers {
+		if q, ok := questions[a.QuestionID]; ok {
+			uc.recorder.QuestionAnswered(attempt, registration, test, q, a)
 		}
 	}
-
-	return true, question.Points, data.Explanation
+	uc.recorder.TestFinished(attempt, registration, test)
 }
 
-func (uc *UseCase) gradeDragDrop(question *domain.Question, answerData json.RawMessage) (bool, int, string) {
-	var data domain.DragDropData
-	if err := json.Unmarshal(question.QuestionData, &data); err != nil {
-		return false, 0, ""
-	}
-
-	var mapping map[string]string
-	if err := json.Unmarshal(answerData, &mapping); err != nil {
-		return false, 0, data.Explanation
-	}
-
-	correct := 0
-	for item, zone := range data.CorrectMapping {
-		if mapping[item] == zone {
-			correct++
-		}
-	}
-
-	if correct == len(data.CorrectMapping) {
-		return true, question.Points, data.Explanation
-	}
-
-	// Partial credit
-	partialPoints := (question.Points * correct) / len(data.CorrectMapping)
-	return false, partialPoints, data.Explanation
+// AttemptListener is told whenever an attempt's grade is set or changes
+type AttemptListener interface {
+	AttemptGraded(attempt *domain.TestAttempt, test *domain.Test)
 }
 
-func (uc *UseCase) gradeFillBlank(question *domain.Question, answerData json.RawMessage) (bool, int, string) {
-	var data domain.FillBlankData
-	if err := json.Unmarshal(question.QuestionData, &data); err != nil {
-		return false, 0, ""
-	}
-
-	var answers []string
-	if err := json.Unmarshal(answerData, &answers); err != nil {
-		return false, 0, data.Explanation
-	}
-
-	if len(answers) != len(data.Blanks) {
-		return false, 0, data.Explanation
-	}
-
-	correct := 0
-	for i, answer := range answers {
-		if answer == data.Blanks[i] {
-			correct++
-		}
-	}
-
-	if correct == len(data.Blanks) {
-		return true, question.Points, data.Explanation
-	}
-
-	partialPoints := (question.Points * correct) / len(data.Blanks)
-	return false, partialPoints, data.Explanation
+// RegisterListener adds a listener for graded attempts
+func (uc *UseCase) RegisterListener(listener AttemptListener) {
+	uc.listeners = append(uc.listeners, listener)
 }
 
-func (uc *UseCase) gradeMatching(question *domain.Question, answerData json.RawMessage) (bool, int, string) {
-	var data domain.MatchingData
-	if err := json.Unmarshal(question.QuestionData, &data); err != nil {
-		return false, 0, ""
+func (uc *UseCase) notifyGraded(attempt *domain.TestAttempt, test *domain.Test) {
+	for _, l := range uc.listeners {
+		l.AttemptGraded(attempt, test)
 	}
-
-	var pairs map[string]string
-	if err := json.Unmarshal(answerData, &pairs); err != nil {
-		return false, 0, data.Explanation
-	}
-
-	correct := 0
-	for left, right := range data.CorrectPairs {
-		if pairs[left] == right {
-			correct++
-		}
-	}
-
-	if correct == len(data.CorrectPairs) {
-		return true, question.Points, data.Explanation
-	}
-
-	partialPoints := (question.Points * correct) / len(data.CorrectPairs)
-	return false, partialPoints, data.Explanation
 }
 
-func (uc *UseCase) gradeOrdering(question *domain.Question, answerData json.RawMessage) (bool, int, string) {
-	var data domain.OrderingData
-	if err := json.Unmarshal(question.QuestionData, &data); err != nil {
-		return false, 0, ""
-	}
-
-	var order []int
-	if err := json.Unmarshal(answerData, &order); err != nil {
-		return false, 0, data.Explanation
-	}
-
-	if len(order) != len(data.CorrectOrder) {
-		return false, 0, data.Explanation
-	}
-
-	correct := 0
-	for i, pos := range order {
-		if pos == data.CorrectOrder[i] {
-			correct++
-		}
+// registration is the learner's enrollment in the course, which ties
+// statements about the test to the rest of the learner's course activity
+func (uc *UseCase) registration(userID, courseID uuid.UUID) *uuid.UUID {
+	enrollment, err := uc.enrollmentRepo.GetByUserAndCourse(userID, courseID)
+	if err != nil {
+		log.Printf("[xAPI] ERROR: Failed to load enrollment of user %s in course %s: %v", userID, courseID, err)
+		return nil
 	}
-
-	if correct == len(data.CorrectOrder) {
-		return true, question.Points, data.Explanation
+	if enrollment == nil {
+		return nil
 	}
-
-	partialPoints := (question.Points * correct) / len(data.CorrectOrder)
-	return false, partialPoints, data.Explanation
+	return &enrollment.ID
 }
 
 func (uc *UseCase) GetAttemptResults(attemptID uuid.UUID, userID uuid.UUID) (*domain.TestResult, error) {
@@ -427,6 +847,11 @@ func (uc *UseCase) GetAttemptResults(attemptID uuid.UUID, userID uuid.UUID) (*do
 		questionMap[q.ID] = q
 	}
 
+	underReview, err := uc.pendingReviews(attemptID)
+	if err != nil {
+		return nil, err
+	}
+
 	var answerResults []*domain.AnswerResult
 	for _, a := range answers {
 		q := questionMap[a.QuestionID]
@@ -437,6 +862,7 @@ func (uc *UseCase) GetAttemptResults(attemptID uuid.UUID, userID uuid.UUID) (*do
 			PointsAwarded: a.PointsAwarded,
 			MaxPoints:     q.Points,
 			Explanation:   explanation,
+			UnderReview:   underReview[a.ID],
 		})
 	}
 
@@ -472,25 +898,48 @@ func (uc *UseCase) getExplanation(question *domain.Question) string {
 		var data domain.OrderingData
 		json.Unmarshal(question.QuestionData, &data)
 		return data.Explanation
+	case domain.QuestionTypeTrueFalse:
+		var data domain.TrueFalseData
+		json.Unmarshal(question.QuestionData, &data)
+		return data.Explanation
+	case domain.QuestionTypeNumeric:
+		var data domain.NumericData
+		json.Unmarshal(question.QuestionData, &data)
+		return data.Explanation
+	case domain.QuestionTypeHotspot:
+		var data domain.HotspotData
+		json.Unmarshal(question.QuestionData, &data)
+		return data.Explanation
+	case domain.QuestionTypeShortText:
+		var data domain.ShortTextData
+		json.Unmarshal(question.QuestionData, &data)
+		return data.Explanation
+	case domain.QuestionTypeOpenEnded:
+		var data domain.OpenEndedData
+		json.Unmarshal(question.QuestionData, &data)
+		return data.Explanation
 	default:
 		return ""
 	}
 }
 
 // UpdateQuestion updates an existing question
-func (uc *UseCase) UpdateQuestion(questionID uuid.UUID, req *domain.UpdateQuestionRequest) (*domain.Question, error) {
-	question, err := uc.questionRepo.GetByID(questionID)
+func (uc *UseCase) UpdateQuestion(tenant domain.Tenant, questionID uuid.UUID, req *domain.UpdateQuestionRequest) (*domain.Question, error) {
+	question, err := uc.getQuestion(tenant, questionID)
 	if err != nil {
 		return nil, err
 	}
-	if question == nil {
-		return nil, errors.New("question not found")
+	if err := validateQuestionData(req.QuestionType, req.QuestionData); err != nil {
+		return nil, err
 	}
 
 	question.QuestionType = req.QuestionType
 	question.QuestionText = req.QuestionText
 	question.QuestionData = req.QuestionData
 	question.Points = req.Points
+	question.Tags = encodeTags(req.Tags)
+	question.ScoringPolicy = req.ScoringPolicy
+	question.AllowNegative = req.AllowNegative
 
 	if err := uc.questionRepo.Update(question); err != nil {
 		return nil, err
@@ -500,14 +949,10 @@ func (uc *UseCase) UpdateQuestion(questionID uuid.UUID, req *domain.UpdateQuesti
 }
 
 // DeleteQuestion deletes a question
-func (uc *UseCase) DeleteQuestion(questionID uuid.UUID) error {
-	question, err := uc.questionRepo.GetByID(questionID)
-	if err != nil {
+func (uc *UseCase) DeleteQuestion(tenant domain.Tenant, questionID uuid.UUID) error {
+	if _, err := uc.getQuestion(tenant, questionID); err != nil {
 		return err
 	}
-	if question == nil {
-		return errors.New("question not found")
-	}
 
 	return uc.questionRepo.Delete(questionID)
 }
@@ -518,14 +963,11 @@ func (uc *UseCase) GetQuestionsByTestID(testID uuid.UUID) ([]*domain.Question, e
 }
 
 // GetTestByCourseIDWithQuestions retrieves a test with all questions for editing
-func (uc *UseCase) GetTestByCourseIDWithQuestions(courseID uuid.UUID) (*domain.Test, error) {
-	test, err := uc.testRepo.GetByCourseID(courseID)
+func (uc *UseCase) GetTestByCourseIDWithQuestions(tenant domain.Tenant, courseID uuid.UUID) (*domain.Test, error) {
+	test, err := uc.getCourseTest(tenant, courseID)
 	if err != nil {
 		return nil, err
 	}
-	if test == nil {
-		return nil, ErrTestNotFound
-	}
 
 	questions, err := uc.questionRepo.GetByTestID(test.ID)
 	if err != nil {
@@ -535,3 +977,11 @@ func (uc *UseCase) GetTestByCourseIDWithQuestions(courseID uuid.UUID) (*domain.T
 
 	return test, nil
 }
+
+func encodeTags(tags []string) json.RawMessage {
+	if tags == nil {
+		tags = []string{}
+	}
+	encoded, _ := json.Marshal(tags)
+	return encoded
+}
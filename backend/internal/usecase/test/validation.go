@@ -0,0 +1,68 @@
+package test
+
+import (
+	"encoding/json"
+
+	"github.com/secusense/backend/internal/domain"
+)
+
+// validateQuestionData rejects answer keys that cannot be graded. The older
+// question types are not checked.
+func validateQuestionData(qType domain.QuestionType, data json.RawMessage) error {
+	switch qType {
+	case domain.QuestionTypeFillBlank:
+		return validateFillBlank(data)
+
+	case domain.QuestionTypeTrueFalse:
+		var tf domain.TrueFalseData
+		if err := json.Unmarshal(data, &tf); err != nil {
+			return ErrInvalidQuestionData
+		}
+
+	case domain.QuestionTypeNumeric:
+		var n domain.NumericData
+		if err := json.Unmarshal(data, &n); err != nil || n.Tolerance < 0 {
+			return ErrInvalidQuestionData
+		}
+
+	case domain.QuestionTypeHotspot:
+		var h domain.HotspotData
+		if err := json.Unmarshal(data, &h); err != nil || h.ImageURL == "" || len(h.Regions) == 0 {
+			return ErrInvalidQuestionData
+		}
+		for _, region := range h.Regions {
+			if !validRegion(region) {
+				return ErrInvalidQuestionData
+			}
+		}
+
+	case domain.QuestionTypeShortText:
+		var st domain.ShortTextData
+		if err := json.Unmarshal(data, &st); err != nil || len(st.Rubric) == 0 || st.MaxLength < 0 {
+			return ErrInvalidQuestionData
+		}
+		for _, item := range st.Rubric {
+			if len(item.Terms) == 0 {
+				return ErrInvalidQuestionData
+			}
+		}
+
+	case domain.QuestionTypeOpenEnded:
+		var oe domain.OpenEndedData
+		if err := json.Unmarshal(data, &oe); err != nil || len(oe.Rubric) == 0 || oe.MaxLength < 0 {
+			return ErrInvalidQuestionData
+		}
+	}
+	return nil
+}
+
+func validRegion(region domain.HotspotRegion) bool {
+	inImage := func(v float64) bool { return v >= 0 && v <= 1 }
+	switch region.Shape {
+	case domain.HotspotRect:
+		return inImage(region.X) && inImage(region.Y) && region.Width > 0 && region.Height > 0
+	case domain.HotspotCircle:
+		return inImage(region.X) && inImage(region.Y) && region.Radius > 0
+	}
+	return false
+}
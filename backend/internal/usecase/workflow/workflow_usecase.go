@@ -6,9 +6,13 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"time"
 
 	"github.com/google/uuid"
-	"github.com/secusense/backend/infrastructure/ollama"
+	"github.com/secusense/backend/infrastructure/assets"
+	"github.com/secusense/backend/infrastructure/eventbus"
+	"github.com/secusense/backend/infrastructure/llm"
+	"github.com/secusense/backend/infrastructure/queue"
 	"github.com/secusense/backend/infrastructure/synthesia"
 	"github.com/secusense/backend/infrastructure/tts"
 	"github.com/secusense/backend/infrastructure/unsplash"
@@ -31,10 +35,25 @@ type UseCase struct {
 	courseRepo       *postgres.CourseRepository
 	testRepo         domain.TestRepository
 	questionRepo     domain.QuestionRepository
-	ollamaClient     *ollama.Client
+	llmProvider      llm.LLMProvider
 	synthesiaClient  *synthesia.Client
 	ttsClient        *tts.Client
 	unsplashClient   *unsplash.Client
+	assetFetcher     *assets.Fetcher
+	jobQueue         *queue.Queue
+	events           eventbus.Bus
+}
+
+// sessionJobPayload is the input of the session-level workflow jobs
+type sessionJobPayload struct {
+	SessionID uuid.UUID `json:"sessionId"`
+}
+
+// presentationJobPayload is the input of a presentation generation job
+type presentationJobPayload struct {
+	PresentationID uuid.UUID `json:"presentationId"`
+	LessonID       uuid.UUID `json:"lessonId"`
+	Language       string    `json:"language"`
 }
 
 func NewUseCase(
@@ -43,26 +62,129 @@ func NewUseCase(
 	courseRepo *postgres.CourseRepository,
 	testRepo domain.TestRepository,
 	questionRepo domain.QuestionRepository,
-	ollamaClient *ollama.Client,
+	llmProvider llm.LLMProvider,
 	synthesiaClient *synthesia.Client,
 	ttsClient *tts.Client,
 	unsplashClient *unsplash.Client,
+	assetFetcher *assets.Fetcher,
+	jobQueue *queue.Queue,
+	events eventbus.Bus,
 ) *UseCase {
-	return &UseCase{
+	uc := &UseCase{
 		workflowRepo:     workflowRepo,
 		presentationRepo: presentationRepo,
 		courseRepo:       courseRepo,
 		testRepo:         testRepo,
 		questionRepo:     questionRepo,
-		ollamaClient:     ollamaClient,
+		llmProvider:      llmProvider,
 		synthesiaClient:  synthesiaClient,
 		ttsClient:        ttsClient,
 		unsplashClient:   unsplashClient,
+		assetFetcher:     assetFetcher,
+		jobQueue:         jobQueue,
+		events:           events,
+	}
+
+	jobQueue.Register(domain.JobTypeWorkflowResearch, uc.runResearchJob, uc.failSessionJob)
+	jobQueue.Register(domain.JobTypeWorkflowRefinement, uc.runRefinementJob, uc.failSessionJob)
+	jobQueue.Register(domain.JobTypeWorkflowScripts, uc.runScriptGenerationJob, uc.failSessionJob)
+	jobQueue.Register(domain.JobTypeWorkflowVideos, uc.runVideoGenerationJob, uc.failSessionJob)
+	jobQueue.Register(domain.JobTypeWorkflowPresentation, uc.runPresentationJob, uc.failPresentationJob)
+	jobQueue.Register(domain.JobTypeWorkflowQuestions, uc.runQuestionGenerationJob, uc.failSessionJob)
+
+	return uc
+}
+
+// enqueueSessionJob queues a session step. If the job can't be stored the
+// session is marked failed so the step can be retried from the UI.
+func (uc *UseCase) enqueueSessionJob(session *domain.CourseWorkflowSession, jobType domain.JobType) error {
+	if _, err := uc.jobQueue.Enqueue(jobType, sessionJobPayload{SessionID: session.ID}); err != nil {
+		session.Status = domain.JobStatusFailed
+		uc.saveSession(session)
+		return fmt.Errorf("failed to queue %s: %w", jobType, err)
 	}
+	return nil
+}
+
+// loadJobSession resolves the session a queued job refers to
+func (uc *UseCase) loadJobSession(job *domain.AIGenerationJob) (*domain.CourseWorkflowSession, error) {
+	var payload sessionJobPayload
+	if err := queue.DecodePayload(job, &payload); err != nil {
+		return nil, err
+	}
+
+	session, err := uc.workflowRepo.GetSessionByID(payload.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session == nil {
+		return nil, ErrSessionNotFound
+	}
+	return session, nil
+}
+
+// failSessionJob marks the session failed once its job has run out of retries
+func (uc *UseCase) failSessionJob(job *domain.AIGenerationJob, err error) {
+	session, loadErr := uc.loadJobSession(job)
+	if loadErr != nil {
+		log.Printf("[Workflow] ERROR: Failed to load session for failed job %s: %v", job.ID, loadErr)
+		return
+	}
+
+	log.Printf("[Workflow] Step %s failed for session %s: %v", session.CurrentStep, session.ID, err)
+	session.Status = domain.JobStatusFailed
+	uc.saveSession(session)
+
+	uc.publish(domain.WorkflowEvent{
+		Type:      domain.WorkflowEventFailed,
+		SessionID: session.ID,
+		Step:      session.CurrentStep,
+		Status:    session.Status,
+		Message:   err.Error(),
+	})
+}
+
+// saveSession persists the session's step/status and announces it to
+// anyone watching the session's event stream
+func (uc *UseCase) saveSession(session *domain.CourseWorkflowSession) error {
+	if err := uc.workflowRepo.UpdateSession(session); err != nil {
+		return err
+	}
+
+	uc.publish(domain.WorkflowEvent{
+		Type:      domain.WorkflowEventStepChanged,
+		SessionID: session.ID,
+		Step:      session.CurrentStep,
+		Status:    session.Status,
+	})
+	return nil
+}
+
+func (uc *UseCase) publish(event domain.WorkflowEvent) {
+	if event.At.IsZero() {
+		event.At = time.Now()
+	}
+	uc.events.Publish(event)
+}
+
+// SubscribeEvents streams progress events of a session until cancel is
+// called. The subscription starts before the session is loaded, so no event
+// published in between is lost; one already reflected in the returned
+// session may arrive again.
+func (uc *UseCase) SubscribeEvents(tenant domain.Tenant, sessionID uuid.UUID) (*domain.CourseWorkflowSession, <-chan domain.WorkflowEvent, func(), error) {
+	events, cancel := uc.events.Subscribe(sessionID)
+
+	session, err := uc.GetSession(tenant, sessionID)
+	if err != nil {
+		cancel()
+		return nil, nil, nil, ErrSessionNotFound
+	}
+
+	return session, events, cancel, nil
 }
 
 // StartResearch begins a new workflow by generating topic suggestions
-func (uc *UseCase) StartResearch(ctx context.Context, req *domain.StartResearchRequest) (*domain.CourseWorkflowSession, error) {
+func (uc *UseCase) StartResearch(ctx context.Context, tenant domain.Tenant, req *domain.StartResearchRequest) (*domain.CourseWorkflowSession, error) {
 	// Default language to English if not specified
 	language := req.Language
 	if language == "" {
@@ -79,6 +201,7 @@ func (uc *UseCase) StartResearch(ctx context.Context, req *domain.StartResearchR
 		VideoDurationMin: req.VideoDurationMin,
 		CurrentStep:      domain.StepResearch,
 		Status:           domain.JobStatusProcessing,
+		OrganizationID:   tenant.Owner(req.OrganizationID),
 	}
 
 	if session.VideoDurationMin == 0 {
@@ -89,26 +212,34 @@ func (uc *UseCase) StartResearch(ctx context.Context, req *domain.StartResearchR
 		return nil, fmt.Errorf("failed to create session: %w", err)
 	}
 
-	// Generate suggestions async
-	go uc.generateSuggestionsAsync(session.ID, req.Topic, req.TargetAudience, req.DifficultyLevel, language)
+	// Generate suggestions in the background
+	if err := uc.enqueueSessionJob(session, domain.JobTypeWorkflowResearch); err != nil {
+		return nil, err
+	}
 
 	return session, nil
 }
 
-func (uc *UseCase) generateSuggestionsAsync(sessionID uuid.UUID, topic, audience, difficulty, language string) {
-	ctx := context.Background()
-
-	session, err := uc.workflowRepo.GetSessionByID(sessionID)
-	if err != nil || session == nil {
-		return
+func (uc *UseCase) runResearchJob(ctx context.Context, job *domain.AIGenerationJob) error {
+	session, err := uc.loadJobSession(job)
+	if err != nil {
+		return err
+	}
+	// A previous attempt already got past this step
+	if session.CurrentStep != domain.StepResearch {
+		return nil
+	}
+	// Suggestions were saved by an attempt that failed right afterwards
+	if len(session.Suggestions) > 0 {
+		session.CurrentStep = domain.StepSelection
+		session.Status = domain.JobStatusCompleted
+		return uc.saveSession(session)
 	}
 
 	// Call Research Agency
-	suggestions, err := uc.ollamaClient.ResearchTopicSuggestions(ctx, topic, audience, difficulty, language, 6)
+	suggestions, err := uc.llmProvider.ResearchTopicSuggestions(ctx, session.MainTopic, session.TargetAudience, session.DifficultyLevel, session.Language, 6)
 	if err != nil {
-		session.Status = domain.JobStatusFailed
-		uc.workflowRepo.UpdateSession(session)
-		return
+		return fmt.Errorf("failed to research topic suggestions: %w", err)
 	}
 
 	// Save suggestions
@@ -116,7 +247,7 @@ func (uc *UseCase) generateSuggestionsAsync(sessionID uuid.UUID, topic, audience
 	for i, s := range suggestions {
 		domainSuggestions = append(domainSuggestions, domain.TopicSuggestion{
 			ID:          uuid.New(),
-			SessionID:   sessionID,
+			SessionID:   session.ID,
 			Title:       s.Title,
 			Description: s.Description,
 			IsCustom:    false,
@@ -126,33 +257,31 @@ func (uc *UseCase) generateSuggestionsAsync(sessionID uuid.UUID, topic, audience
 	}
 
 	if err := uc.workflowRepo.CreateSuggestionsBatch(domainSuggestions); err != nil {
-		session.Status = domain.JobStatusFailed
-		uc.workflowRepo.UpdateSession(session)
-		return
+		return fmt.Errorf("failed to save suggestions: %w", err)
 	}
 
 	// Update session to selection step
 	session.CurrentStep = domain.StepSelection
 	session.Status = domain.JobStatusCompleted
-	uc.workflowRepo.UpdateSession(session)
+	return uc.saveSession(session)
 }
 
 // GetSession retrieves a workflow session with all its data
-func (uc *UseCase) GetSession(sessionID uuid.UUID) (*domain.CourseWorkflowSession, error) {
+func (uc *UseCase) GetSession(tenant domain.Tenant, sessionID uuid.UUID) (*domain.CourseWorkflowSession, error) {
 	session, err := uc.workflowRepo.GetSessionByID(sessionID)
 	if err != nil {
 		return nil, err
 	}
-	if session == nil {
+	if session == nil || !tenant.Allows(session.OrganizationID) {
 		return nil, ErrSessionNotFound
 	}
 	return session, nil
 }
 
 // UpdateSuggestionStatus updates the approval status of a suggestion
-func (uc *UseCase) UpdateSuggestionStatus(sessionID, suggestionID uuid.UUID, status domain.SuggestionStatus) error {
-	session, err := uc.workflowRepo.GetSessionByID(sessionID)
-	if err != nil || session == nil {
+func (uc *UseCase) UpdateSuggestionStatus(tenant domain.Tenant, sessionID, suggestionID uuid.UUID, status domain.SuggestionStatus) error {
+	session, err := uc.GetSession(tenant, sessionID)
+	if err != nil {
 		return ErrSessionNotFound
 	}
 
@@ -173,9 +302,9 @@ func (uc *UseCase) UpdateSuggestionStatus(sessionID, suggestionID uuid.UUID, sta
 }
 
 // AddCustomTopic adds a user-defined topic to the session
-func (uc *UseCase) AddCustomTopic(sessionID uuid.UUID, req *domain.AddCustomTopicRequest) (*domain.TopicSuggestion, error) {
-	session, err := uc.workflowRepo.GetSessionByID(sessionID)
-	if err != nil || session == nil {
+func (uc *UseCase) AddCustomTopic(tenant domain.Tenant, sessionID uuid.UUID, req *domain.AddCustomTopicRequest) (*domain.TopicSuggestion, error) {
+	session, err := uc.GetSession(tenant, sessionID)
+	if err != nil {
 		return nil, ErrSessionNotFound
 	}
 
@@ -201,9 +330,9 @@ func (uc *UseCase) AddCustomTopic(sessionID uuid.UUID, req *domain.AddCustomTopi
 }
 
 // GenerateMoreSuggestions generates additional topic suggestions
-func (uc *UseCase) GenerateMoreSuggestions(ctx context.Context, sessionID uuid.UUID) error {
-	session, err := uc.workflowRepo.GetSessionByID(sessionID)
-	if err != nil || session == nil {
+func (uc *UseCase) GenerateMoreSuggestions(ctx context.Context, tenant domain.Tenant, sessionID uuid.UUID) error {
+	session, err := uc.GetSession(tenant, sessionID)
+	if err != nil {
 		return ErrSessionNotFound
 	}
 
@@ -212,7 +341,7 @@ func (uc *UseCase) GenerateMoreSuggestions(ctx context.Context, sessionID uuid.U
 	}
 
 	// Generate more suggestions
-	suggestions, err := uc.ollamaClient.ResearchTopicSuggestions(
+	suggestions, err := uc.llmProvider.ResearchTopicSuggestions(
 		ctx,
 		session.MainTopic,
 		session.TargetAudience,
@@ -243,9 +372,9 @@ func (uc *UseCase) GenerateMoreSuggestions(ctx context.Context, sessionID uuid.U
 }
 
 // ProceedToRefinement moves to the refinement step and processes approved topics
-func (uc *UseCase) ProceedToRefinement(ctx context.Context, sessionID uuid.UUID) (*domain.CourseWorkflowSession, error) {
-	session, err := uc.workflowRepo.GetSessionByID(sessionID)
-	if err != nil || session == nil {
+func (uc *UseCase) ProceedToRefinement(ctx context.Context, tenant domain.Tenant, sessionID uuid.UUID) (*domain.CourseWorkflowSession, error) {
+	session, err := uc.GetSession(tenant, sessionID)
+	if err != nil {
 		return nil, ErrSessionNotFound
 	}
 
@@ -269,34 +398,54 @@ func (uc *UseCase) ProceedToRefinement(ctx context.Context, sessionID uuid.UUID)
 	// Update status to processing
 	session.CurrentStep = domain.StepRefinement
 	session.Status = domain.JobStatusProcessing
-	if err := uc.workflowRepo.UpdateSession(session); err != nil {
+	if err := uc.saveSession(session); err != nil {
 		return nil, err
 	}
 
-	// Process refinement async
-	go uc.processRefinementAsync(session, approved)
+	// Process refinement in the background
+	if err := uc.enqueueSessionJob(session, domain.JobTypeWorkflowRefinement); err != nil {
+		return nil, err
+	}
 
 	return session, nil
 }
 
-func (uc *UseCase) processRefinementAsync(session *domain.CourseWorkflowSession, approved []domain.TopicSuggestion) {
-	ctx := context.Background()
+func (uc *UseCase) runRefinementJob(ctx context.Context, job *domain.AIGenerationJob) error {
+	session, err := uc.loadJobSession(job)
+	if err != nil {
+		return err
+	}
+	if session.CurrentStep != domain.StepRefinement {
+		return nil
+	}
+	// Refined topics were saved by an attempt that failed right afterwards
+	if len(session.RefinedTopics) > 0 {
+		session.CurrentStep = domain.StepScriptGen
+		session.Status = domain.JobStatusCompleted
+		return uc.saveSession(session)
+	}
 
-	// Convert to Ollama format
-	var suggestions []ollama.TopicSuggestionResult
+	approved, err := uc.workflowRepo.GetApprovedSuggestions(session.ID)
+	if err != nil {
+		return err
+	}
+	if len(approved) == 0 {
+		return ErrNoApprovedTopics
+	}
+
+	// Convert to LLM format
+	var suggestions []llm.TopicSuggestionResult
 	for _, a := range approved {
-		suggestions = append(suggestions, ollama.TopicSuggestionResult{
+		suggestions = append(suggestions, llm.TopicSuggestionResult{
 			Title:       a.Title,
 			Description: a.Description,
 		})
 	}
 
 	// Call Refinement Agency
-	refined, err := uc.ollamaClient.RefineTopics(ctx, session.MainTopic, suggestions, session.TargetAudience, session.DifficultyLevel, session.Language)
+	refined, err := uc.llmProvider.RefineTopics(ctx, session.MainTopic, suggestions, session.TargetAudience, session.DifficultyLevel, session.Language)
 	if err != nil {
-		session.Status = domain.JobStatusFailed
-		uc.workflowRepo.UpdateSession(session)
-		return
+		return fmt.Errorf("failed to refine topics: %w", err)
 	}
 
 	// Save refined topics
@@ -329,21 +478,19 @@ func (uc *UseCase) processRefinementAsync(session *domain.CourseWorkflowSession,
 	}
 
 	if err := uc.workflowRepo.CreateRefinedTopicsBatch(domainTopics); err != nil {
-		session.Status = domain.JobStatusFailed
-		uc.workflowRepo.UpdateSession(session)
-		return
+		return fmt.Errorf("failed to save refined topics: %w", err)
 	}
 
 	// Move to script generation step
 	session.CurrentStep = domain.StepScriptGen
 	session.Status = domain.JobStatusCompleted
-	uc.workflowRepo.UpdateSession(session)
+	return uc.saveSession(session)
 }
 
 // ProceedToScriptGeneration generates scripts for all refined topics
-func (uc *UseCase) ProceedToScriptGeneration(ctx context.Context, sessionID uuid.UUID) (*domain.CourseWorkflowSession, error) {
-	session, err := uc.workflowRepo.GetSessionByID(sessionID)
-	if err != nil || session == nil {
+func (uc *UseCase) ProceedToScriptGeneration(ctx context.Context, tenant domain.Tenant, sessionID uuid.UUID) (*domain.CourseWorkflowSession, error) {
+	session, err := uc.GetSession(tenant, sessionID)
+	if err != nil {
 		return nil, ErrSessionNotFound
 	}
 
@@ -362,26 +509,40 @@ func (uc *UseCase) ProceedToScriptGeneration(ctx context.Context, sessionID uuid
 
 	// Update status
 	session.Status = domain.JobStatusProcessing
-	if err := uc.workflowRepo.UpdateSession(session); err != nil {
+	if err := uc.saveSession(session); err != nil {
 		return nil, err
 	}
 
-	// Process scripts async
-	go uc.processScriptGenerationAsync(session)
+	// Process scripts in the background
+	if err := uc.enqueueSessionJob(session, domain.JobTypeWorkflowScripts); err != nil {
+		return nil, err
+	}
 
 	return session, nil
 }
 
-func (uc *UseCase) processScriptGenerationAsync(session *domain.CourseWorkflowSession) {
-	ctx := context.Background()
+func (uc *UseCase) runScriptGenerationJob(ctx context.Context, job *domain.AIGenerationJob) error {
+	session, err := uc.loadJobSession(job)
+	if err != nil {
+		return err
+	}
+	if session.CurrentStep != domain.StepScriptGen {
+		return nil
+	}
+	// Scripts were saved by an attempt that failed right afterwards
+	if len(session.LessonScripts) > 0 {
+		session.CurrentStep = domain.StepVideoGen
+		session.Status = domain.JobStatusCompleted
+		return uc.saveSession(session)
+	}
 
-	// Convert refined topics to Ollama format
-	var topics []ollama.RefinedTopicResult
+	// Convert refined topics to LLM format
+	var topics []llm.RefinedTopicResult
 	for _, t := range session.RefinedTopics {
 		var goals []string
 		json.Unmarshal(t.LearningGoals, &goals)
 
-		topics = append(topics, ollama.RefinedTopicResult{
+		topics = append(topics, llm.RefinedTopicResult{
 			Title:            t.Title,
 			Description:      t.Description,
 			LearningGoals:    goals,
@@ -389,56 +550,55 @@ func (uc *UseCase) processScriptGenerationAsync(session *domain.CourseWorkflowSe
 		})
 	}
 
-	// Call Script Agency
-	scripts, err := uc.ollamaClient.GenerateLessonScripts(ctx, session.MainTopic, topics, session.TargetAudience, session.DifficultyLevel, session.Language)
-	if err != nil {
-		session.Status = domain.JobStatusFailed
-		uc.workflowRepo.UpdateSession(session)
-		return
-	}
-
-	// Save scripts
+	// Call Script Agency one topic at a time so progress can be reported per lesson
 	var domainScripts []domain.LessonScript
-	for i, s := range scripts {
-		// Find matching topic
-		var topicID uuid.UUID
-		for _, t := range session.RefinedTopics {
-			if t.Title == s.TopicTitle {
-				topicID = t.ID
-				break
-			}
+	for i, topic := range topics {
+		scripts, err := uc.llmProvider.GenerateLessonScripts(ctx, session.MainTopic, []llm.RefinedTopicResult{topic}, session.TargetAudience, session.DifficultyLevel, session.Language)
+		if err != nil {
+			return fmt.Errorf("failed to generate lesson scripts: %w", err)
 		}
-		if topicID == uuid.Nil && i < len(session.RefinedTopics) {
-			topicID = session.RefinedTopics[i].ID
+		if len(scripts) == 0 {
+			return fmt.Errorf("no script generated for %s", topic.Title)
 		}
+		s := scripts[0]
 
-		domainScripts = append(domainScripts, domain.LessonScript{
+		script := domain.LessonScript{
 			ID:          uuid.New(),
 			SessionID:   session.ID,
-			TopicID:     topicID,
+			TopicID:     session.RefinedTopics[i].ID,
 			Title:       s.Title,
 			Script:      s.Script,
 			DurationMin: s.DurationMin,
 			SortOrder:   i,
+		}
+		domainScripts = append(domainScripts, script)
+
+		uc.publish(domain.WorkflowEvent{
+			Type:      domain.WorkflowEventScriptGenerated,
+			SessionID: session.ID,
+			Step:      domain.StepScriptGen,
+			LessonID:  &script.ID,
+			Title:     script.Title,
+			Current:   i + 1,
+			Total:     len(topics),
 		})
 	}
 
+	// Save scripts
 	if err := uc.workflowRepo.CreateLessonScriptsBatch(domainScripts); err != nil {
-		session.Status = domain.JobStatusFailed
-		uc.workflowRepo.UpdateSession(session)
-		return
+		return fmt.Errorf("failed to save lesson scripts: %w", err)
 	}
 
 	// Move to video generation step
 	session.CurrentStep = domain.StepVideoGen
 	session.Status = domain.JobStatusCompleted
-	uc.workflowRepo.UpdateSession(session)
+	return uc.saveSession(session)
 }
 
 // ProceedToVideoGeneration starts video generation for all scripts
-func (uc *UseCase) ProceedToVideoGeneration(ctx context.Context, sessionID uuid.UUID) (*domain.CourseWorkflowSession, error) {
-	session, err := uc.workflowRepo.GetSessionByID(sessionID)
-	if err != nil || session == nil {
+func (uc *UseCase) ProceedToVideoGeneration(ctx context.Context, tenant domain.Tenant, sessionID uuid.UUID) (*domain.CourseWorkflowSession, error) {
+	session, err := uc.GetSession(tenant, sessionID)
+	if err != nil {
 		return nil, ErrSessionNotFound
 	}
 
@@ -461,18 +621,26 @@ func (uc *UseCase) ProceedToVideoGeneration(ctx context.Context, sessionID uuid.
 
 	// Update status
 	session.Status = domain.JobStatusProcessing
-	if err := uc.workflowRepo.UpdateSession(session); err != nil {
+	if err := uc.saveSession(session); err != nil {
 		return nil, err
 	}
 
-	// Generate videos async
-	go uc.processVideoGenerationAsync(session)
+	// Generate videos in the background
+	if err := uc.enqueueSessionJob(session, domain.JobTypeWorkflowVideos); err != nil {
+		return nil, err
+	}
 
 	return session, nil
 }
 
-func (uc *UseCase) processVideoGenerationAsync(session *domain.CourseWorkflowSession) {
-	ctx := context.Background()
+func (uc *UseCase) runVideoGenerationJob(ctx context.Context, job *domain.AIGenerationJob) error {
+	session, err := uc.loadJobSession(job)
+	if err != nil {
+		return err
+	}
+	if session.CurrentStep != domain.StepVideoGen {
+		return nil
+	}
 
 	for _, script := range session.LessonScripts {
 		// Skip lessons that are set to presentation output type
@@ -480,6 +648,10 @@ func (uc *UseCase) processVideoGenerationAsync(session *domain.CourseWorkflowSes
 			log.Printf("[VideoGen] Skipping lesson %s - output type is presentation", script.ID)
 			continue
 		}
+		// Skip lessons a previous attempt already submitted
+		if script.VideoID != nil && *script.VideoID != "" {
+			continue
+		}
 
 		// Generate video
 		log.Printf("[VideoGen] Generating video for lesson %s: %s", script.ID, script.Title)
@@ -488,6 +660,14 @@ func (uc *UseCase) processVideoGenerationAsync(session *domain.CourseWorkflowSes
 			log.Printf("[VideoGen] ERROR: Failed to generate video for lesson %s: %v", script.ID, err)
 			// Mark this script as failed but continue with others
 			uc.workflowRepo.UpdateLessonScriptVideo(script.ID, "", "", "failed")
+			uc.publish(domain.WorkflowEvent{
+				Type:      domain.WorkflowEventFailed,
+				SessionID: session.ID,
+				Step:      domain.StepVideoGen,
+				LessonID:  &script.ID,
+				Title:     script.Title,
+				Message:   err.Error(),
+			})
 			continue
 		}
 
@@ -499,8 +679,11 @@ func (uc *UseCase) processVideoGenerationAsync(session *domain.CourseWorkflowSes
 	// Move to question generation step
 	session.CurrentStep = domain.StepQuestionGen
 	session.Status = domain.JobStatusCompleted
-	uc.workflowRepo.UpdateSession(session)
+	if err := uc.saveSession(session); err != nil {
+		return err
+	}
 	log.Printf("[VideoGen] Video/presentation generation complete, moving to question generation for session %s", session.ID)
+	return nil
 }
 
 // createCourseFromWorkflow creates a Course from a completed workflow session
@@ -540,6 +723,7 @@ func (uc *UseCase) createCourseFromWorkflow(session *domain.CourseWorkflowSessio
 		VideoStatus:      videoStatus,
 		PassPercentage:   70, // Default pass percentage
 		IsPublished:      false, // Not published by default - admin can review and publish
+		OrganizationID:   session.OrganizationID,
 	}
 
 	if err := uc.courseRepo.Create(course); err != nil {
@@ -550,9 +734,9 @@ func (uc *UseCase) createCourseFromWorkflow(session *domain.CourseWorkflowSessio
 }
 
 // UpdateRefinedTopic updates the content of a refined topic
-func (uc *UseCase) UpdateRefinedTopic(sessionID, topicID uuid.UUID, req *domain.UpdateRefinedTopicRequest) (*domain.RefinedTopic, error) {
-	session, err := uc.workflowRepo.GetSessionByID(sessionID)
-	if err != nil || session == nil {
+func (uc *UseCase) UpdateRefinedTopic(tenant domain.Tenant, sessionID, topicID uuid.UUID, req *domain.UpdateRefinedTopicRequest) (*domain.RefinedTopic, error) {
+	session, err := uc.GetSession(tenant, sessionID)
+	if err != nil {
 		return nil, ErrSessionNotFound
 	}
 
@@ -584,10 +768,10 @@ func (uc *UseCase) UpdateRefinedTopic(sessionID, topicID uuid.UUID, req *domain.
 	return topic, nil
 }
 
-// RegenerateSingleTopic regenerates a single refined topic using Ollama
-func (uc *UseCase) RegenerateSingleTopic(ctx context.Context, sessionID, topicID uuid.UUID) (*domain.RefinedTopic, error) {
-	session, err := uc.workflowRepo.GetSessionByID(sessionID)
-	if err != nil || session == nil {
+// RegenerateSingleTopic regenerates a single refined topic using the LLM provider
+func (uc *UseCase) RegenerateSingleTopic(ctx context.Context, tenant domain.Tenant, sessionID, topicID uuid.UUID) (*domain.RefinedTopic, error) {
+	session, err := uc.GetSession(tenant, sessionID)
+	if err != nil {
 		return nil, ErrSessionNotFound
 	}
 
@@ -611,13 +795,13 @@ func (uc *UseCase) RegenerateSingleTopic(ctx context.Context, sessionID, topicID
 	}
 
 	// Create a single-item slice for refinement
-	singleSuggestion := []ollama.TopicSuggestionResult{{
+	singleSuggestion := []llm.TopicSuggestionResult{{
 		Title:       suggestion.Title,
 		Description: suggestion.Description,
 	}}
 
-	// Regenerate using Ollama
-	refined, err := uc.ollamaClient.RefineTopics(ctx, session.MainTopic, singleSuggestion,
+	// Regenerate using the LLM provider
+	refined, err := uc.llmProvider.RefineTopics(ctx, session.MainTopic, singleSuggestion,
 		session.TargetAudience, session.DifficultyLevel, session.Language)
 	if err != nil {
 		return nil, fmt.Errorf("failed to regenerate topic: %w", err)
@@ -642,9 +826,9 @@ func (uc *UseCase) RegenerateSingleTopic(ctx context.Context, sessionID, topicID
 }
 
 // ReorderRefinedTopics updates the sort order of refined topics
-func (uc *UseCase) ReorderRefinedTopics(sessionID uuid.UUID, orders []domain.TopicOrder) error {
-	session, err := uc.workflowRepo.GetSessionByID(sessionID)
-	if err != nil || session == nil {
+func (uc *UseCase) ReorderRefinedTopics(tenant domain.Tenant, sessionID uuid.UUID, orders []domain.TopicOrder) error {
+	session, err := uc.GetSession(tenant, sessionID)
+	if err != nil {
 		return ErrSessionNotFound
 	}
 
@@ -666,9 +850,9 @@ func (uc *UseCase) ReorderRefinedTopics(sessionID uuid.UUID, orders []domain.Top
 }
 
 // UpdateLessonScript updates the content of a lesson script
-func (uc *UseCase) UpdateLessonScript(sessionID, lessonID uuid.UUID, req *domain.UpdateLessonScriptRequest) (*domain.LessonScript, error) {
-	session, err := uc.workflowRepo.GetSessionByID(sessionID)
-	if err != nil || session == nil {
+func (uc *UseCase) UpdateLessonScript(tenant domain.Tenant, sessionID, lessonID uuid.UUID, req *domain.UpdateLessonScriptRequest) (*domain.LessonScript, error) {
+	session, err := uc.GetSession(tenant, sessionID)
+	if err != nil {
 		return nil, ErrSessionNotFound
 	}
 
@@ -699,9 +883,9 @@ func (uc *UseCase) UpdateLessonScript(sessionID, lessonID uuid.UUID, req *domain
 }
 
 // RegenerateScript regenerates a single lesson script using AI
-func (uc *UseCase) RegenerateScript(ctx context.Context, sessionID, lessonID uuid.UUID) (*domain.LessonScript, error) {
-	session, err := uc.workflowRepo.GetSessionByID(sessionID)
-	if err != nil || session == nil {
+func (uc *UseCase) RegenerateScript(ctx context.Context, tenant domain.Tenant, sessionID, lessonID uuid.UUID) (*domain.LessonScript, error) {
+	session, err := uc.GetSession(tenant, sessionID)
+	if err != nil {
 		return nil, ErrSessionNotFound
 	}
 
@@ -729,15 +913,15 @@ func (uc *UseCase) RegenerateScript(ctx context.Context, sessionID, lessonID uui
 	json.Unmarshal(topic.LearningGoals, &goals)
 
 	// Create a single-item slice for script generation
-	singleTopic := []ollama.RefinedTopicResult{{
+	singleTopic := []llm.RefinedTopicResult{{
 		Title:            topic.Title,
 		Description:      topic.Description,
 		LearningGoals:    goals,
 		EstimatedTimeMin: topic.EstimatedTimeMin,
 	}}
 
-	// Regenerate using Ollama
-	scripts, err := uc.ollamaClient.GenerateLessonScripts(ctx, session.MainTopic, singleTopic,
+	// Regenerate using the LLM provider
+	scripts, err := uc.llmProvider.GenerateLessonScripts(ctx, session.MainTopic, singleTopic,
 		session.TargetAudience, session.DifficultyLevel, session.Language)
 	if err != nil {
 		return nil, fmt.Errorf("failed to regenerate script: %w", err)
@@ -761,9 +945,9 @@ func (uc *UseCase) RegenerateScript(ctx context.Context, sessionID, lessonID uui
 }
 
 // SetLessonOutputType changes the output type for a lesson (video or presentation)
-func (uc *UseCase) SetLessonOutputType(sessionID, lessonID uuid.UUID, outputType domain.OutputType) error {
-	session, err := uc.workflowRepo.GetSessionByID(sessionID)
-	if err != nil || session == nil {
+func (uc *UseCase) SetLessonOutputType(tenant domain.Tenant, sessionID, lessonID uuid.UUID, outputType domain.OutputType) error {
+	session, err := uc.GetSession(tenant, sessionID)
+	if err != nil {
 		return ErrSessionNotFound
 	}
 
@@ -785,9 +969,9 @@ func (uc *UseCase) SetLessonOutputType(sessionID, lessonID uuid.UUID, outputType
 }
 
 // GeneratePresentation creates a presentation for a lesson
-func (uc *UseCase) GeneratePresentation(ctx context.Context, sessionID, lessonID uuid.UUID) (*domain.LessonPresentation, error) {
-	session, err := uc.workflowRepo.GetSessionByID(sessionID)
-	if err != nil || session == nil {
+func (uc *UseCase) GeneratePresentation(ctx context.Context, tenant domain.Tenant, sessionID, lessonID uuid.UUID) (*domain.LessonPresentation, error) {
+	session, err := uc.GetSession(tenant, sessionID)
+	if err != nil {
 		return nil, ErrSessionNotFound
 	}
 
@@ -815,29 +999,81 @@ func (uc *UseCase) GeneratePresentation(ctx context.Context, sessionID, lessonID
 		return nil, fmt.Errorf("failed to create presentation: %w", err)
 	}
 
-	// Generate presentation async
-	go uc.generatePresentationAsync(presentation.ID, lesson, session.Language)
+	// Generate presentation in the background
+	if err := uc.enqueuePresentationJob(presentation.ID, lessonID, session.Language); err != nil {
+		return nil, err
+	}
 
 	return presentation, nil
 }
 
-// generatePresentationAsync handles the async presentation generation
-func (uc *UseCase) generatePresentationAsync(presentationID uuid.UUID, lesson *domain.LessonScript, language string) {
-	ctx := context.Background()
+// enqueuePresentationJob queues slide generation for a presentation record
+func (uc *UseCase) enqueuePresentationJob(presentationID, lessonID uuid.UUID, language string) error {
+	payload := presentationJobPayload{PresentationID: presentationID, LessonID: lessonID, Language: language}
+	if _, err := uc.jobQueue.Enqueue(domain.JobTypeWorkflowPresentation, payload); err != nil {
+		uc.presentationRepo.UpdateStatus(presentationID, "failed")
+		uc.workflowRepo.UpdateLessonScriptPresentationStatus(lessonID, "failed")
+		return fmt.Errorf("failed to queue presentation generation: %w", err)
+	}
+	return nil
+}
 
-	log.Printf("[Presentation] Starting generation for lesson %s (presentation %s)", lesson.ID, presentationID)
+// failPresentationJob marks the presentation failed once its job has run out of retries
+func (uc *UseCase) failPresentationJob(job *domain.AIGenerationJob, err error) {
+	var payload presentationJobPayload
+	if decodeErr := queue.DecodePayload(job, &payload); decodeErr != nil {
+		log.Printf("[Presentation] ERROR: %v", decodeErr)
+		return
+	}
+
+	log.Printf("[Presentation] ERROR: Giving up on presentation %s: %v", payload.PresentationID, err)
+	uc.presentationRepo.UpdateStatus(payload.PresentationID, "failed")
+	uc.workflowRepo.UpdateLessonScriptPresentationStatus(payload.LessonID, "failed")
+
+	if lesson, _ := uc.workflowRepo.GetLessonScriptByID(payload.LessonID); lesson != nil {
+		uc.publish(domain.WorkflowEvent{
+			Type:      domain.WorkflowEventFailed,
+			SessionID: lesson.SessionID,
+			LessonID:  &lesson.ID,
+			Title:     lesson.Title,
+			Message:   err.Error(),
+		})
+	}
+}
+
+// runPresentationJob generates slides, images and narration for a presentation
+func (uc *UseCase) runPresentationJob(ctx context.Context, job *domain.AIGenerationJob) error {
+	var payload presentationJobPayload
+	if err := queue.DecodePayload(job, &payload); err != nil {
+		return err
+	}
+	presentationID, language := payload.PresentationID, payload.Language
+
+	lesson, err := uc.workflowRepo.GetLessonScriptByID(payload.LessonID)
+	if err != nil {
+		return err
+	}
+	if lesson == nil {
+		return ErrLessonNotFound
+	}
 
-	// Generate slides using Ollama
-	log.Printf("[Presentation] Calling Ollama to generate slides for lesson: %s", lesson.Title)
-	slideResults, err := uc.ollamaClient.GeneratePresentationSlides(ctx, lesson.Title, lesson.Script, language)
+	log.Printf("[Presentation] Starting generation for lesson %s (presentation %s)", lesson.ID, presentationID)
+	uc.publish(domain.WorkflowEvent{
+		Type:      domain.WorkflowEventPresentationStarted,
+		SessionID: lesson.SessionID,
+		LessonID:  &lesson.ID,
+		Title:     lesson.Title,
+	})
+
+	// Generate slides using the LLM provider
+	log.Printf("[Presentation] Calling LLM to generate slides for lesson: %s", lesson.Title)
+	slideResults, err := uc.llmProvider.GeneratePresentationSlides(ctx, lesson.Title, lesson.Script, language)
 	if err != nil {
 		log.Printf("[Presentation] ERROR: Failed to generate slides for lesson %s: %v", lesson.ID, err)
-		uc.presentationRepo.UpdateStatus(presentationID, "failed")
-		uc.workflowRepo.UpdateLessonScriptPresentationStatus(lesson.ID, "failed")
-		return
+		return fmt.Errorf("failed to generate slides: %w", err)
 	}
 
-	log.Printf("[Presentation] Ollama generated %d slides for lesson %s", len(slideResults), lesson.ID)
+	log.Printf("[Presentation] LLM generated %d slides for lesson %s", len(slideResults), lesson.ID)
 
 	// Convert to domain slides and generate audio/images for each
 	slides := make([]domain.PresentationSlide, len(slideResults))
@@ -858,6 +1094,12 @@ func (uc *UseCase) generatePresentationAsync(presentationID uuid.UUID, lesson *d
 			} else if photo != nil {
 				slide.ImageURL = photo.GetImageURL()
 				slide.ImageAlt = photo.GetAltText()
+				// Kept in the storage; the hotlink is the fallback
+				if imageURL, err := uc.assetFetcher.SaveImage(ctx, slide.ImageURL); err != nil {
+					log.Printf("[Presentation] WARNING: Failed to store image for slide %d: %v", i, err)
+				} else {
+					slide.ImageURL = imageURL
+				}
 				log.Printf("[Presentation] Stock image fetched: %s", slide.ImageURL)
 			}
 		}
@@ -878,15 +1120,23 @@ func (uc *UseCase) generatePresentationAsync(presentationID uuid.UUID, lesson *d
 		}
 
 		slides[i] = slide
+
+		uc.publish(domain.WorkflowEvent{
+			Type:      domain.WorkflowEventSlideGenerated,
+			SessionID: lesson.SessionID,
+			LessonID:  &lesson.ID,
+			Title:     slide.Title,
+			Current:   i + 1,
+			Total:     len(slideResults),
+			HasAudio:  slide.AudioURL != "",
+		})
 	}
 
 	// Update presentation with slides
 	log.Printf("[Presentation] Saving %d slides to database", len(slides))
 	if err := uc.presentationRepo.UpdateSlides(presentationID, slides); err != nil {
 		log.Printf("[Presentation] ERROR: Failed to update slides for presentation %s: %v", presentationID, err)
-		uc.presentationRepo.UpdateStatus(presentationID, "failed")
-		uc.workflowRepo.UpdateLessonScriptPresentationStatus(lesson.ID, "failed")
-		return
+		return fmt.Errorf("failed to save slides: %w", err)
 	}
 
 	// Mark as completed
@@ -894,13 +1144,19 @@ func (uc *UseCase) generatePresentationAsync(presentationID uuid.UUID, lesson *d
 	uc.presentationRepo.UpdateStatus(presentationID, "completed")
 	uc.workflowRepo.UpdateLessonScriptPresentationStatus(lesson.ID, "completed")
 	log.Printf("[Presentation] Successfully completed presentation generation for lesson %s", lesson.ID)
+	uc.publish(domain.WorkflowEvent{
+		Type:      domain.WorkflowEventPresentationCompleted,
+		SessionID: lesson.SessionID,
+		LessonID:  &lesson.ID,
+		Title:     lesson.Title,
+		Total:     len(slides),
+	})
+	return nil
 }
 
 // GetPresentation retrieves a presentation by lesson ID
-func (uc *UseCase) GetPresentation(sessionID, lessonID uuid.UUID) (*domain.LessonPresentation, error) {
-	// Verify session exists
-	session, err := uc.workflowRepo.GetSessionByID(sessionID)
-	if err != nil || session == nil {
+func (uc *UseCase) GetPresentation(tenant domain.Tenant, sessionID, lessonID uuid.UUID) (*domain.LessonPresentation, error) {
+	if _, err := uc.GetSession(tenant, sessionID); err != nil {
 		return nil, ErrSessionNotFound
 	}
 
@@ -917,10 +1173,9 @@ func (uc *UseCase) GetPresentation(sessionID, lessonID uuid.UUID) (*domain.Lesso
 }
 
 // RegenerateAudio regenerates audio files for an existing presentation
-func (uc *UseCase) RegenerateAudio(ctx context.Context, sessionID, lessonID uuid.UUID) (*domain.LessonPresentation, error) {
-	// Verify session exists
-	session, err := uc.workflowRepo.GetSessionByID(sessionID)
-	if err != nil || session == nil {
+func (uc *UseCase) RegenerateAudio(ctx context.Context, tenant domain.Tenant, sessionID, lessonID uuid.UUID) (*domain.LessonPresentation, error) {
+	session, err := uc.GetSession(tenant, sessionID)
+	if err != nil {
 		return nil, ErrSessionNotFound
 	}
 
@@ -983,10 +1238,10 @@ type CourseLessonWithPresentation struct {
 }
 
 // GetCourseLessons retrieves all lessons for a course with their presentations
-func (uc *UseCase) GetCourseLessons(courseID uuid.UUID) ([]CourseLessonWithPresentation, error) {
+func (uc *UseCase) GetCourseLessons(tenant domain.Tenant, courseID uuid.UUID) ([]CourseLessonWithPresentation, error) {
 	// Find workflow session by course ID
 	session, err := uc.workflowRepo.GetSessionByCourseID(courseID)
-	if err != nil || session == nil {
+	if err != nil || session == nil || !tenant.Allows(session.OrganizationID) {
 		return nil, errors.New("no lessons found for this course")
 	}
 
@@ -1019,9 +1274,9 @@ func (uc *UseCase) GetCourseLessons(courseID uuid.UUID) ([]CourseLessonWithPrese
 }
 
 // ProceedToQuestionGeneration generates quiz questions for the course
-func (uc *UseCase) ProceedToQuestionGeneration(ctx context.Context, sessionID uuid.UUID) (*domain.CourseWorkflowSession, error) {
-	session, err := uc.workflowRepo.GetSessionByID(sessionID)
-	if err != nil || session == nil {
+func (uc *UseCase) ProceedToQuestionGeneration(ctx context.Context, tenant domain.Tenant, sessionID uuid.UUID) (*domain.CourseWorkflowSession, error) {
+	session, err := uc.GetSession(tenant, sessionID)
+	if err != nil {
 		return nil, ErrSessionNotFound
 	}
 
@@ -1040,18 +1295,32 @@ func (uc *UseCase) ProceedToQuestionGeneration(ctx context.Context, sessionID uu
 
 	// Update status
 	session.Status = domain.JobStatusProcessing
-	if err := uc.workflowRepo.UpdateSession(session); err != nil {
+	if err := uc.saveSession(session); err != nil {
 		return nil, err
 	}
 
-	// Process question generation async
-	go uc.processQuestionGenerationAsync(session)
+	// Process question generation in the background
+	if err := uc.enqueueSessionJob(session, domain.JobTypeWorkflowQuestions); err != nil {
+		return nil, err
+	}
 
 	return session, nil
 }
 
-func (uc *UseCase) processQuestionGenerationAsync(session *domain.CourseWorkflowSession) {
-	ctx := context.Background()
+func (uc *UseCase) runQuestionGenerationJob(ctx context.Context, job *domain.AIGenerationJob) error {
+	session, err := uc.loadJobSession(job)
+	if err != nil {
+		return err
+	}
+	if session.CurrentStep != domain.StepQuestionGen {
+		return nil
+	}
+	// The course was created by an attempt that failed right afterwards
+	if session.CourseID != nil {
+		session.CurrentStep = domain.StepCompleted
+		session.Status = domain.JobStatusCompleted
+		return uc.saveSession(session)
+	}
 
 	log.Printf("[QuestionGen] Starting question generation for session %s", session.ID)
 
@@ -1061,13 +1330,11 @@ func (uc *UseCase) processQuestionGenerationAsync(session *domain.CourseWorkflow
 		scripts = append(scripts, lesson.Script)
 	}
 
-	// Generate questions using Ollama
-	questions, err := uc.ollamaClient.GenerateQuizQuestions(ctx, session.MainTopic, scripts, session.Language, 10)
+	// Generate questions using the LLM provider
+	questions, err := uc.llmProvider.GenerateQuizQuestions(ctx, session.MainTopic, scripts, session.Language, 10)
 	if err != nil {
 		log.Printf("[QuestionGen] ERROR: Failed to generate questions: %v", err)
-		session.Status = domain.JobStatusFailed
-		uc.workflowRepo.UpdateSession(session)
-		return
+		return fmt.Errorf("failed to generate questions: %w", err)
 	}
 
 	log.Printf("[QuestionGen] Generated %d questions for session %s", len(questions), session.ID)
@@ -1076,13 +1343,17 @@ func (uc *UseCase) processQuestionGenerationAsync(session *domain.CourseWorkflow
 	course, err := uc.createCourseFromWorkflow(session)
 	if err != nil {
 		log.Printf("[QuestionGen] ERROR: Failed to create course from workflow: %v", err)
-		session.Status = domain.JobStatusFailed
-		uc.workflowRepo.UpdateSession(session)
-		return
+		return fmt.Errorf("failed to create course: %w", err)
 	}
 
 	log.Printf("[QuestionGen] Course created: %s (%s)", course.Title, course.ID)
 	session.CourseID = &course.ID
+	job.CourseID = &course.ID
+
+	// Remember the course right away so a retry doesn't create it twice
+	if err := uc.workflowRepo.UpdateSession(session); err != nil {
+		log.Printf("[QuestionGen] WARNING: Failed to link course to session %s: %v", session.ID, err)
+	}
 
 	// Create test for the course
 	if uc.testRepo != nil && uc.questionRepo != nil {
@@ -1124,8 +1395,11 @@ func (uc *UseCase) processQuestionGenerationAsync(session *domain.CourseWorkflow
 	// Mark workflow as completed
 	session.CurrentStep = domain.StepCompleted
 	session.Status = domain.JobStatusCompleted
-	uc.workflowRepo.UpdateSession(session)
+	if err := uc.saveSession(session); err != nil {
+		return err
+	}
 	log.Printf("[QuestionGen] Workflow completed for session %s", session.ID)
+	return nil
 }
 
 // GeneratedQuestionsPreview returns preview of questions that would be generated
@@ -1134,9 +1408,9 @@ type GeneratedQuestionsPreview struct {
 }
 
 // PreviewQuestions generates questions for preview without saving them
-func (uc *UseCase) PreviewQuestions(ctx context.Context, sessionID uuid.UUID) (*GeneratedQuestionsPreview, error) {
-	session, err := uc.workflowRepo.GetSessionByID(sessionID)
-	if err != nil || session == nil {
+func (uc *UseCase) PreviewQuestions(ctx context.Context, tenant domain.Tenant, sessionID uuid.UUID) (*GeneratedQuestionsPreview, error) {
+	session, err := uc.GetSession(tenant, sessionID)
+	if err != nil {
 		return nil, ErrSessionNotFound
 	}
 
@@ -1146,8 +1420,8 @@ func (uc *UseCase) PreviewQuestions(ctx context.Context, sessionID uuid.UUID) (*
 		scripts = append(scripts, lesson.Script)
 	}
 
-	// Generate questions using Ollama
-	questions, err := uc.ollamaClient.GenerateQuizQuestions(ctx, session.MainTopic, scripts, session.Language, 10)
+	// Generate questions using the LLM provider
+	questions, err := uc.llmProvider.GenerateQuizQuestions(ctx, session.MainTopic, scripts, session.Language, 10)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate questions: %w", err)
 	}
@@ -1159,12 +1433,20 @@ func (uc *UseCase) PreviewQuestions(ctx context.Context, sessionID uuid.UUID) (*
 
 // ======== Course-level lesson management (for completed courses) ========
 
-// UpdateLessonScriptForCourse updates a lesson script for a completed course
-func (uc *UseCase) UpdateLessonScriptForCourse(ctx context.Context, courseID, lessonID uuid.UUID, req *domain.UpdateLessonScriptRequest) (*domain.LessonScript, error) {
-	// Find workflow session by course ID
+// getCourseSession finds the workflow session that created a course
+func (uc *UseCase) getCourseSession(tenant domain.Tenant, courseID uuid.UUID) (*domain.CourseWorkflowSession, error) {
 	session, err := uc.workflowRepo.GetSessionByCourseID(courseID)
-	if err != nil || session == nil {
-		return nil, errors.New("no workflow session found for this course")
+	if err != nil || session == nil || !tenant.Allows(session.OrganizationID) {
+		return nil, ErrSessionNotFound
+	}
+	return session, nil
+}
+
+// UpdateLessonScriptForCourse updates a lesson script for a completed course
+func (uc *UseCase) UpdateLessonScriptForCourse(ctx context.Context, tenant domain.Tenant, courseID, lessonID uuid.UUID, req *domain.UpdateLessonScriptRequest) (*domain.LessonScript, error) {
+	session, err := uc.getCourseSession(tenant, courseID)
+	if err != nil {
+		return nil, err
 	}
 
 	// Find and update the lesson
@@ -1190,11 +1472,10 @@ func (uc *UseCase) UpdateLessonScriptForCourse(ctx context.Context, courseID, le
 }
 
 // RegenerateLessonScriptForCourse regenerates a lesson script using AI for a completed course
-func (uc *UseCase) RegenerateLessonScriptForCourse(ctx context.Context, courseID, lessonID uuid.UUID) (*domain.LessonScript, error) {
-	// Find workflow session by course ID
-	session, err := uc.workflowRepo.GetSessionByCourseID(courseID)
-	if err != nil || session == nil {
-		return nil, errors.New("no workflow session found for this course")
+func (uc *UseCase) RegenerateLessonScriptForCourse(ctx context.Context, tenant domain.Tenant, courseID, lessonID uuid.UUID) (*domain.LessonScript, error) {
+	session, err := uc.getCourseSession(tenant, courseID)
+	if err != nil {
+		return nil, err
 	}
 
 	// Get the lesson script
@@ -1217,15 +1498,15 @@ func (uc *UseCase) RegenerateLessonScriptForCourse(ctx context.Context, courseID
 	json.Unmarshal(topic.LearningGoals, &goals)
 
 	// Create a single-item slice for script generation
-	singleTopic := []ollama.RefinedTopicResult{{
+	singleTopic := []llm.RefinedTopicResult{{
 		Title:            topic.Title,
 		Description:      topic.Description,
 		LearningGoals:    goals,
 		EstimatedTimeMin: topic.EstimatedTimeMin,
 	}}
 
-	// Regenerate using Ollama
-	scripts, err := uc.ollamaClient.GenerateLessonScripts(ctx, session.MainTopic, singleTopic,
+	// Regenerate using the LLM provider
+	scripts, err := uc.llmProvider.GenerateLessonScripts(ctx, session.MainTopic, singleTopic,
 		session.TargetAudience, session.DifficultyLevel, session.Language)
 	if err != nil {
 		return nil, fmt.Errorf("failed to regenerate script: %w", err)
@@ -1249,11 +1530,10 @@ func (uc *UseCase) RegenerateLessonScriptForCourse(ctx context.Context, courseID
 }
 
 // RegeneratePresentationForCourse regenerates a presentation for a lesson in a completed course
-func (uc *UseCase) RegeneratePresentationForCourse(ctx context.Context, courseID, lessonID uuid.UUID) (*domain.LessonPresentation, error) {
-	// Find workflow session by course ID
-	session, err := uc.workflowRepo.GetSessionByCourseID(courseID)
-	if err != nil || session == nil {
-		return nil, errors.New("no workflow session found for this course")
+func (uc *UseCase) RegeneratePresentationForCourse(ctx context.Context, tenant domain.Tenant, courseID, lessonID uuid.UUID) (*domain.LessonPresentation, error) {
+	session, err := uc.getCourseSession(tenant, courseID)
+	if err != nil {
+		return nil, err
 	}
 
 	// Get the lesson script
@@ -1289,8 +1569,10 @@ func (uc *UseCase) RegeneratePresentationForCourse(ctx context.Context, courseID
 		uc.presentationRepo.UpdateStatus(presentation.ID, "processing")
 	}
 
-	// Generate presentation async
-	go uc.generatePresentationAsync(presentation.ID, lesson, session.Language)
+	// Generate presentation in the background
+	if err := uc.enqueuePresentationJob(presentation.ID, lessonID, session.Language); err != nil {
+		return nil, err
+	}
 
 	return presentation, nil
 }
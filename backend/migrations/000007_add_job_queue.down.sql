@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_ai_jobs_lease;
+DROP INDEX IF EXISTS idx_ai_jobs_runnable;
+
+ALTER TABLE ai_generation_jobs
+DROP COLUMN IF EXISTS locked_until,
+DROP COLUMN IF EXISTS locked_by,
+DROP COLUMN IF EXISTS run_at,
+DROP COLUMN IF EXISTS max_attempts,
+DROP COLUMN IF EXISTS attempts;
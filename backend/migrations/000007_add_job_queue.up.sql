@@ -0,0 +1,13 @@
+-- Turn ai_generation_jobs into a durable work queue
+ALTER TABLE ai_generation_jobs
+ADD COLUMN IF NOT EXISTS attempts INT NOT NULL DEFAULT 0,
+ADD COLUMN IF NOT EXISTS max_attempts INT NOT NULL DEFAULT 3,
+ADD COLUMN IF NOT EXISTS run_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+ADD COLUMN IF NOT EXISTS locked_by VARCHAR(100),
+ADD COLUMN IF NOT EXISTS locked_until TIMESTAMP WITH TIME ZONE;
+
+-- Workers pick the oldest runnable job, so index pending jobs by run_at
+CREATE INDEX IF NOT EXISTS idx_ai_jobs_runnable ON ai_generation_jobs(run_at) WHERE status = 'pending';
+
+-- Recovery scans for expired leases
+CREATE INDEX IF NOT EXISTS idx_ai_jobs_lease ON ai_generation_jobs(locked_until) WHERE status = 'processing';
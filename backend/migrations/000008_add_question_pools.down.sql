@@ -0,0 +1,11 @@
+ALTER TABLE test_attempts
+DROP COLUMN IF EXISTS question_set;
+
+ALTER TABLE questions
+DROP COLUMN IF EXISTS tags;
+
+ALTER TABLE tests
+DROP COLUMN IF EXISTS shuffle_options,
+DROP COLUMN IF EXISTS shuffle_questions,
+DROP COLUMN IF EXISTS stratify_by,
+DROP COLUMN IF EXISTS question_count;
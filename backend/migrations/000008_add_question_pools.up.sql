@@ -0,0 +1,15 @@
+-- Question pools: a test may draw a random subset of its questions per attempt
+ALTER TABLE tests
+ADD COLUMN IF NOT EXISTS question_count INTEGER,
+ADD COLUMN IF NOT EXISTS stratify_by VARCHAR(20) NOT NULL DEFAULT 'none',
+ADD COLUMN IF NOT EXISTS shuffle_questions BOOLEAN NOT NULL DEFAULT FALSE,
+ADD COLUMN IF NOT EXISTS shuffle_options BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Tags are free-form labels used to stratify draws
+ALTER TABLE questions
+ADD COLUMN IF NOT EXISTS tags JSONB NOT NULL DEFAULT '[]';
+
+-- The questions and option order an attempt was shown, so grading matches
+-- what the learner saw
+ALTER TABLE test_attempts
+ADD COLUMN IF NOT EXISTS question_set JSONB;
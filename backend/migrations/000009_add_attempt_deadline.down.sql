@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_test_attempts_open_deadline;
+
+ALTER TABLE test_attempts
+DROP COLUMN IF EXISTS auto_submitted,
+DROP COLUMN IF EXISTS deadline_at;
@@ -0,0 +1,7 @@
+-- Server-side time limits: the deadline is fixed when the attempt starts
+ALTER TABLE test_attempts
+ADD COLUMN IF NOT EXISTS deadline_at TIMESTAMP WITH TIME ZONE,
+ADD COLUMN IF NOT EXISTS auto_submitted BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- The sweeper looks for open attempts past their deadline
+CREATE INDEX IF NOT EXISTS idx_test_attempts_open_deadline ON test_attempts(deadline_at) WHERE completed_at IS NULL;
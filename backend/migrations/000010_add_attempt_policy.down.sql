@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS test_attempt_grants;
+
+ALTER TABLE tests
+DROP COLUMN IF EXISTS lockout_after_failures,
+DROP COLUMN IF EXISTS cooldown_minutes,
+DROP COLUMN IF EXISTS max_attempts;
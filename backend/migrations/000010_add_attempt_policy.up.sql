@@ -0,0 +1,18 @@
+-- Retake policy per test; NULL / 0 means unrestricted
+ALTER TABLE tests
+ADD COLUMN IF NOT EXISTS max_attempts INTEGER,
+ADD COLUMN IF NOT EXISTS cooldown_minutes INTEGER NOT NULL DEFAULT 0,
+ADD COLUMN IF NOT EXISTS lockout_after_failures INTEGER;
+
+-- Extra attempts granted to a learner by an administrator
+CREATE TABLE IF NOT EXISTS test_attempt_grants (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    test_id UUID NOT NULL REFERENCES tests(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    extra_attempts INTEGER NOT NULL,
+    reason TEXT,
+    granted_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_test_attempt_grants_user_test ON test_attempt_grants(user_id, test_id);
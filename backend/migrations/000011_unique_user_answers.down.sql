@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_user_answers_attempt_question;
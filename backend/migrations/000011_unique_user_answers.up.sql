@@ -0,0 +1,9 @@
+-- Answers are saved one at a time while an attempt is open, so each question
+-- may only have one answer per attempt. Keep the newest of any duplicates.
+DELETE FROM user_answers a
+USING user_answers b
+WHERE a.attempt_id = b.attempt_id
+  AND a.question_id = b.question_id
+  AND (COALESCE(a.created_at, 'epoch'), a.id) < (COALESCE(b.created_at, 'epoch'), b.id);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_answers_attempt_question ON user_answers(attempt_id, question_id);
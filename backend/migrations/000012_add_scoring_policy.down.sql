@@ -0,0 +1,7 @@
+ALTER TABLE questions
+DROP COLUMN IF EXISTS allow_negative,
+DROP COLUMN IF EXISTS scoring_policy;
+
+ALTER TABLE tests
+DROP COLUMN IF EXISTS allow_negative,
+DROP COLUMN IF EXISTS scoring_policy;
@@ -0,0 +1,9 @@
+-- Scoring policy per test, optionally overridden per question. NULL inherits:
+-- question -> test -> default of the question type.
+ALTER TABLE tests
+ADD COLUMN IF NOT EXISTS scoring_policy VARCHAR(20),
+ADD COLUMN IF NOT EXISTS allow_negative BOOLEAN NOT NULL DEFAULT FALSE;
+
+ALTER TABLE questions
+ADD COLUMN IF NOT EXISTS scoring_policy VARCHAR(20),
+ADD COLUMN IF NOT EXISTS allow_negative BOOLEAN;
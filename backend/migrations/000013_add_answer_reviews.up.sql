@@ -0,0 +1,20 @@
+-- Grades given by the LLM to open-ended answers. Low-confidence grades wait
+-- in the review queue (status 'pending') until an administrator resolves them.
+CREATE TABLE IF NOT EXISTS answer_reviews (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_answer_id UUID NOT NULL REFERENCES user_answers(id) ON DELETE CASCADE,
+    attempt_id UUID NOT NULL REFERENCES test_attempts(id) ON DELETE CASCADE,
+    question_id UUID NOT NULL REFERENCES questions(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    suggested_points INTEGER NOT NULL,
+    rationale TEXT NOT NULL DEFAULT '',
+    confidence REAL NOT NULL,
+    final_points INTEGER,
+    review_note TEXT,
+    reviewed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    reviewed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_answer_reviews_status ON answer_reviews(status, created_at);
+CREATE INDEX IF NOT EXISTS idx_answer_reviews_attempt ON answer_reviews(attempt_id);
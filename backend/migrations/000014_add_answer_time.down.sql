@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_test_attempts_test_completed;
+
+ALTER TABLE user_answers
+DROP COLUMN IF EXISTS time_spent_seconds;
@@ -0,0 +1,6 @@
+-- Time the learner spent on a question, as reported by the client. Used by
+-- item analysis; NULL for answers saved before it was tracked.
+ALTER TABLE user_answers
+ADD COLUMN IF NOT EXISTS time_spent_seconds INTEGER;
+
+CREATE INDEX IF NOT EXISTS idx_test_attempts_test_completed ON test_attempts(test_id) WHERE completed_at IS NOT NULL;
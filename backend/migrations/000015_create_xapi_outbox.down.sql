@@ -0,0 +1 @@
+DROP TABLE IF EXISTS xapi_outbox;
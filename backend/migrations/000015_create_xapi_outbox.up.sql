@@ -0,0 +1,16 @@
+-- xAPI statements waiting to be sent to the Learning Record Store. The row ID
+-- is the statement ID, so a statement resent after a lost response is
+-- recognised by the LRS instead of being stored twice.
+CREATE TABLE IF NOT EXISTS xapi_outbox (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    statement JSONB NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    last_error TEXT,
+    sent_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_xapi_outbox_due ON xapi_outbox(next_attempt_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_xapi_outbox_sent ON xapi_outbox(sent_at) WHERE status = 'sent';
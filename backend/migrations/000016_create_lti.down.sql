@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS lti_grade_links;
+DROP TABLE IF EXISTS lti_launches;
+DROP TABLE IF EXISTS lti_user_links;
+DROP TABLE IF EXISTS lti_states;
+DROP TABLE IF EXISTS lti_tool_keys;
+DROP TABLE IF EXISTS lti_platforms;
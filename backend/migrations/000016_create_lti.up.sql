@@ -0,0 +1,75 @@
+-- LTI 1.3 platforms (LMSs) registered by an administrator. A platform is
+-- identified by its issuer and the client ID it gave SecuSense.
+CREATE TABLE IF NOT EXISTS lti_platforms (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(255) NOT NULL,
+    issuer TEXT NOT NULL,
+    client_id TEXT NOT NULL,
+    deployment_ids TEXT[] NOT NULL DEFAULT '{}',
+    auth_login_url TEXT NOT NULL,
+    auth_token_url TEXT NOT NULL,
+    jwks_url TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (issuer, client_id)
+);
+
+-- The tool's signing keys, published as its JWKS. The newest key signs.
+CREATE TABLE IF NOT EXISTS lti_tool_keys (
+    kid VARCHAR(64) PRIMARY KEY,
+    private_key TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- OIDC logins waiting for the platform to come back with an id_token
+CREATE TABLE IF NOT EXISTS lti_states (
+    state VARCHAR(64) PRIMARY KEY,
+    nonce VARCHAR(64) NOT NULL,
+    platform_id UUID NOT NULL REFERENCES lti_platforms(id) ON DELETE CASCADE,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_lti_states_expires ON lti_states(expires_at);
+
+-- SecuSense accounts provisioned for platform users
+CREATE TABLE IF NOT EXISTS lti_user_links (
+    platform_id UUID NOT NULL REFERENCES lti_platforms(id) ON DELETE CASCADE,
+    subject TEXT NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (platform_id, subject)
+);
+
+-- Validated launches. The browser exchanges the one-time code for a session;
+-- deep linking launches also keep what the platform needs in the response.
+CREATE TABLE IF NOT EXISTS lti_launches (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    code_hash VARCHAR(64) NOT NULL UNIQUE,
+    code_used BOOLEAN NOT NULL DEFAULT FALSE,
+    platform_id UUID NOT NULL REFERENCES lti_platforms(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    deployment_id TEXT NOT NULL,
+    message_type VARCHAR(50) NOT NULL,
+    course_id UUID REFERENCES courses(id) ON DELETE CASCADE,
+    deep_link_return_url TEXT,
+    deep_link_data TEXT,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_lti_launches_expires ON lti_launches(expires_at);
+
+-- Where a learner's grade for a course goes (Assignment and Grade Services)
+CREATE TABLE IF NOT EXISTS lti_grade_links (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    platform_id UUID NOT NULL REFERENCES lti_platforms(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    course_id UUID NOT NULL REFERENCES courses(id) ON DELETE CASCADE,
+    subject TEXT NOT NULL,
+    lineitem_url TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (platform_id, user_id, lineitem_url)
+);
+
+CREATE INDEX IF NOT EXISTS idx_lti_grade_links_user_course ON lti_grade_links(user_id, course_id);
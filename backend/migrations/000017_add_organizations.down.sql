@@ -0,0 +1,10 @@
+UPDATE users SET role = 'admin' WHERE role = 'super_admin';
+
+ALTER TABLE lti_platforms DROP COLUMN IF EXISTS organization_id;
+ALTER TABLE course_workflow_sessions DROP COLUMN IF EXISTS organization_id;
+ALTER TABLE certificates DROP COLUMN IF EXISTS organization_id;
+ALTER TABLE enrollments DROP COLUMN IF EXISTS organization_id;
+ALTER TABLE courses DROP COLUMN IF EXISTS organization_id;
+ALTER TABLE users DROP COLUMN IF EXISTS organization_id;
+
+DROP TABLE IF EXISTS organizations;
@@ -0,0 +1,54 @@
+-- Organizations are the client companies (tenants) SecuSense serves. Users,
+-- courses, enrollments and certificates belong to exactly one; everything
+-- that existed before moves to the default organization.
+CREATE TABLE IF NOT EXISTS organizations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(255) NOT NULL,
+    slug VARCHAR(100) NOT NULL UNIQUE,
+    -- Certificate branding; NULL falls back to the SecuSense defaults
+    certificate_issuer VARCHAR(255),
+    brand_color VARCHAR(7),
+    certificate_footer VARCHAR(500),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+INSERT INTO organizations (id, name, slug)
+VALUES ('00000000-0000-0000-0000-000000000001', 'SecuSense', 'default')
+ON CONFLICT (id) DO NOTHING;
+
+-- The defaults only backfill existing rows; new rows must name their
+-- organization
+ALTER TABLE users
+ADD COLUMN IF NOT EXISTS organization_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES organizations(id);
+ALTER TABLE users ALTER COLUMN organization_id DROP DEFAULT;
+
+ALTER TABLE courses
+ADD COLUMN IF NOT EXISTS organization_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES organizations(id);
+ALTER TABLE courses ALTER COLUMN organization_id DROP DEFAULT;
+
+ALTER TABLE enrollments
+ADD COLUMN IF NOT EXISTS organization_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES organizations(id);
+ALTER TABLE enrollments ALTER COLUMN organization_id DROP DEFAULT;
+
+ALTER TABLE certificates
+ADD COLUMN IF NOT EXISTS organization_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES organizations(id);
+ALTER TABLE certificates ALTER COLUMN organization_id DROP DEFAULT;
+
+ALTER TABLE course_workflow_sessions
+ADD COLUMN IF NOT EXISTS organization_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES organizations(id);
+ALTER TABLE course_workflow_sessions ALTER COLUMN organization_id DROP DEFAULT;
+
+ALTER TABLE lti_platforms
+ADD COLUMN IF NOT EXISTS organization_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES organizations(id);
+ALTER TABLE lti_platforms ALTER COLUMN organization_id DROP DEFAULT;
+
+CREATE INDEX IF NOT EXISTS idx_users_organization ON users(organization_id);
+CREATE INDEX IF NOT EXISTS idx_courses_organization ON courses(organization_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_enrollments_organization ON enrollments(organization_id, enrolled_at);
+CREATE INDEX IF NOT EXISTS idx_certificates_organization ON certificates(organization_id, issued_at);
+CREATE INDEX IF NOT EXISTS idx_course_workflow_sessions_organization ON course_workflow_sessions(organization_id);
+CREATE INDEX IF NOT EXISTS idx_lti_platforms_organization ON lti_platforms(organization_id);
+
+-- The seeded administrator manages every organization
+UPDATE users SET role = 'super_admin' WHERE email = 'admin@secusense.local' AND role = 'admin';
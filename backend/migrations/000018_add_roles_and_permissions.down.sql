@@ -0,0 +1,6 @@
+ALTER TABLE users DROP CONSTRAINT IF EXISTS fk_users_role;
+UPDATE users SET role = 'user' WHERE role NOT IN ('user', 'admin', 'super_admin');
+ALTER TABLE users ALTER COLUMN role TYPE VARCHAR(20);
+
+DROP TABLE IF EXISTS role_permissions;
+DROP TABLE IF EXISTS roles;
@@ -0,0 +1,57 @@
+-- Roles grant permissions on the admin API. Access tokens carry the
+-- permissions of the user's role, so changes apply once a token is refreshed.
+CREATE TABLE IF NOT EXISTS roles (
+    name VARCHAR(50) PRIMARY KEY,
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    -- Built-in roles can't be edited so no one locks themselves out
+    built_in BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS role_permissions (
+    role VARCHAR(50) NOT NULL REFERENCES roles(name) ON DELETE CASCADE,
+    permission VARCHAR(50) NOT NULL,
+    PRIMARY KEY (role, permission)
+);
+
+INSERT INTO roles (name, description, built_in) VALUES
+    ('user', 'Learner without admin access', TRUE),
+    ('admin', 'Manages everything in their organization', TRUE),
+    ('super_admin', 'Manages every organization', TRUE),
+    ('content_author', 'Writes courses and tests and runs the AI workflows', FALSE),
+    ('reviewer', 'Grades answers awaiting review', FALSE),
+    ('manager', 'Manages learners and follows their progress', FALSE),
+    ('auditor', 'Reads reports', FALSE)
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('admin', 'course:write'),
+    ('admin', 'test:write'),
+    ('admin', 'review:grade'),
+    ('admin', 'workflow:run'),
+    ('admin', 'reports:read'),
+    ('admin', 'users:manage'),
+    ('admin', 'lti:manage'),
+    ('super_admin', 'course:write'),
+    ('super_admin', 'test:write'),
+    ('super_admin', 'review:grade'),
+    ('super_admin', 'workflow:run'),
+    ('super_admin', 'reports:read'),
+    ('super_admin', 'users:manage'),
+    ('super_admin', 'lti:manage'),
+    ('super_admin', 'organizations:manage'),
+    ('super_admin', 'roles:manage'),
+    ('content_author', 'course:write'),
+    ('content_author', 'test:write'),
+    ('content_author', 'workflow:run'),
+    ('reviewer', 'review:grade'),
+    ('reviewer', 'reports:read'),
+    ('manager', 'reports:read'),
+    ('manager', 'users:manage'),
+    ('auditor', 'reports:read')
+ON CONFLICT DO NOTHING;
+
+ALTER TABLE users ALTER COLUMN role TYPE VARCHAR(50);
+ALTER TABLE users DROP CONSTRAINT IF EXISTS fk_users_role;
+ALTER TABLE users ADD CONSTRAINT fk_users_role FOREIGN KEY (role) REFERENCES roles(name) ON UPDATE CASCADE;
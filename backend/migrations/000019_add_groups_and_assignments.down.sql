@@ -0,0 +1,10 @@
+DELETE FROM role_permissions WHERE permission = 'assignments:manage';
+
+DROP INDEX IF EXISTS idx_enrollments_due;
+DROP INDEX IF EXISTS idx_enrollments_assignment;
+ALTER TABLE enrollments DROP COLUMN IF EXISTS due_at;
+ALTER TABLE enrollments DROP COLUMN IF EXISTS assignment_id;
+
+DROP TABLE IF EXISTS course_assignments;
+DROP TABLE IF EXISTS group_members;
+DROP TABLE IF EXISTS groups;
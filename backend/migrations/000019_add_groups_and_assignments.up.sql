@@ -0,0 +1,52 @@
+-- Groups and departments collect the learners of an organization so courses
+-- can be assigned to all of them at once, with a due date
+CREATE TABLE IF NOT EXISTS groups (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    kind VARCHAR(20) NOT NULL DEFAULT 'group' CHECK (kind IN ('group', 'department')),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (organization_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS group_members (
+    group_id UUID NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    added_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (group_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_group_members_user ON group_members(user_id);
+
+-- An assignment enrolls every member of a group in a course, including
+-- members who join later
+CREATE TABLE IF NOT EXISTS course_assignments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    course_id UUID NOT NULL REFERENCES courses(id) ON DELETE CASCADE,
+    group_id UUID NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+    due_at TIMESTAMP WITH TIME ZONE,
+    assigned_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (course_id, group_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_course_assignments_group ON course_assignments(group_id);
+CREATE INDEX IF NOT EXISTS idx_course_assignments_organization ON course_assignments(organization_id);
+
+-- The assignment an enrollment is due for; self-service enrollments have none
+ALTER TABLE enrollments
+ADD COLUMN IF NOT EXISTS assignment_id UUID REFERENCES course_assignments(id) ON DELETE SET NULL,
+ADD COLUMN IF NOT EXISTS due_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_enrollments_assignment ON enrollments(assignment_id);
+CREATE INDEX IF NOT EXISTS idx_enrollments_due ON enrollments(due_at) WHERE due_at IS NOT NULL AND status <> 'completed';
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('admin', 'assignments:manage'),
+    ('super_admin', 'assignments:manage'),
+    ('manager', 'assignments:manage')
+ON CONFLICT DO NOTHING;
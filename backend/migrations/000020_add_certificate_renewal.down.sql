@@ -0,0 +1,9 @@
+ALTER TABLE enrollments DROP COLUMN IF EXISTS renewal_started_at;
+
+DROP INDEX IF EXISTS idx_certificates_expiry;
+DROP INDEX IF EXISTS idx_certificates_previous;
+ALTER TABLE certificates DROP COLUMN IF EXISTS renewal_opened_at;
+ALTER TABLE certificates DROP COLUMN IF EXISTS previous_certificate_id;
+
+ALTER TABLE courses DROP COLUMN IF EXISTS renewal_notice_days;
+ALTER TABLE courses DROP COLUMN IF EXISTS certificate_validity_days;
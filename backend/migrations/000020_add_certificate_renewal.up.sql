@@ -0,0 +1,19 @@
+-- Courses can make their certificates expire, e.g. yearly security
+-- awareness training. Learners are enrolled again renewal_notice_days before
+-- their certificate expires.
+ALTER TABLE courses
+ADD COLUMN IF NOT EXISTS certificate_validity_days INTEGER CHECK (certificate_validity_days > 0),
+ADD COLUMN IF NOT EXISTS renewal_notice_days INTEGER NOT NULL DEFAULT 30 CHECK (renewal_notice_days > 0);
+
+-- A renewed certificate points at the one it replaces; renewal_opened_at is
+-- set once the learner has been enrolled again to renew it
+ALTER TABLE certificates
+ADD COLUMN IF NOT EXISTS previous_certificate_id UUID REFERENCES certificates(id) ON DELETE SET NULL,
+ADD COLUMN IF NOT EXISTS renewal_opened_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_certificates_previous ON certificates(previous_certificate_id);
+CREATE INDEX IF NOT EXISTS idx_certificates_expiry ON certificates(expires_at) WHERE expires_at IS NOT NULL AND renewal_opened_at IS NULL;
+
+-- Attempts started before a renewal don't count against the retake policy
+ALTER TABLE enrollments
+ADD COLUMN IF NOT EXISTS renewal_started_at TIMESTAMP WITH TIME ZONE;
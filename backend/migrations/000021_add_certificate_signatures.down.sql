@@ -0,0 +1,3 @@
+ALTER TABLE certificates DROP COLUMN IF EXISTS signature;
+
+DROP TABLE IF EXISTS certificate_signing_keys;
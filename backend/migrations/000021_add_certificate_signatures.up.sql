@@ -0,0 +1,12 @@
+-- Certificates are signed with Ed25519 keys so they can be verified offline
+-- against the published public keys. The newest key signs.
+CREATE TABLE IF NOT EXISTS certificate_signing_keys (
+    kid VARCHAR(64) PRIMARY KEY,
+    private_key TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- The signed credential (compact JWS) embedded in the certificate PDF and
+-- its QR code; certificates issued before signing are signed on download
+ALTER TABLE certificates
+ADD COLUMN IF NOT EXISTS signature TEXT;
@@ -0,0 +1,11 @@
+DELETE FROM role_permissions WHERE permission = 'certificates:revoke';
+
+DROP TABLE IF EXISTS audit_log;
+
+DROP INDEX IF EXISTS idx_certificates_revoked;
+DROP INDEX IF EXISTS idx_certificates_status_list_index;
+ALTER TABLE certificates DROP COLUMN IF EXISTS status_list_index;
+DROP SEQUENCE IF EXISTS certificate_status_list_index_seq;
+ALTER TABLE certificates DROP COLUMN IF EXISTS revocation_reason;
+ALTER TABLE certificates DROP COLUMN IF EXISTS revoked_by;
+ALTER TABLE certificates DROP COLUMN IF EXISTS revoked_at;
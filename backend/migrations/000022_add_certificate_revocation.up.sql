@@ -0,0 +1,39 @@
+-- Admins revoke certificates issued by mistake or obtained by cheating. A
+-- revoked certificate is kept so verification can tell it apart from one
+-- that doesn't exist.
+ALTER TABLE certificates
+ADD COLUMN IF NOT EXISTS revoked_at TIMESTAMP WITH TIME ZONE,
+ADD COLUMN IF NOT EXISTS revoked_by UUID REFERENCES users(id) ON DELETE SET NULL,
+ADD COLUMN IF NOT EXISTS revocation_reason TEXT;
+
+-- Every certificate has a bit in the public revocation list; existing
+-- certificates are numbered as the column is added
+CREATE SEQUENCE IF NOT EXISTS certificate_status_list_index_seq MINVALUE 0 START 0;
+
+ALTER TABLE certificates
+ADD COLUMN IF NOT EXISTS status_list_index INTEGER NOT NULL DEFAULT nextval('certificate_status_list_index_seq');
+
+ALTER SEQUENCE certificate_status_list_index_seq OWNED BY certificates.status_list_index;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_certificates_status_list_index ON certificates(status_list_index);
+CREATE INDEX IF NOT EXISTS idx_certificates_revoked ON certificates(revoked_at) WHERE revoked_at IS NOT NULL;
+
+-- Audit trail of administrative actions, such as revoking a certificate
+CREATE TABLE IF NOT EXISTS audit_log (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    actor_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    action VARCHAR(100) NOT NULL,
+    entity_type VARCHAR(50) NOT NULL,
+    entity_id UUID NOT NULL,
+    details JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_organization ON audit_log(organization_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_log_entity ON audit_log(entity_type, entity_id);
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('admin', 'certificates:revoke'),
+    ('super_admin', 'certificates:revoke')
+ON CONFLICT DO NOTHING;
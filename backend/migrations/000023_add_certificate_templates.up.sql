@@ -0,0 +1,20 @@
+-- Certificate templates of an organization (course_id NULL) or of one of
+-- its courses, which takes precedence. Images are stored as data URIs;
+-- texts map a language to the texts it overrides.
+CREATE TABLE IF NOT EXISTS certificate_templates (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    course_id UUID REFERENCES courses(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    primary_color VARCHAR(7),
+    text_color VARCHAR(7),
+    logo TEXT,
+    signatures JSONB NOT NULL DEFAULT '[]',
+    texts JSONB NOT NULL DEFAULT '{}',
+    extra_fields JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_certificate_templates_organization ON certificate_templates(organization_id) WHERE course_id IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_certificate_templates_course ON certificate_templates(course_id) WHERE course_id IS NOT NULL;
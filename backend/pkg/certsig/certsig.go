@@ -0,0 +1,286 @@
+// Package certsig signs certificates as compact JWS credentials with Ed25519
+// keys and verifies them against the published public keys, so a
+// certificate can be checked without reaching the SecuSense API.
+//
+// A credential travels in the QR code of the certificate PDF, as the
+// credential query parameter of the verification URL, and in the PDF's
+// keywords after KeywordPrefix.
+package certsig
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// KeywordPrefix marks the credential in the keywords of a certificate PDF
+	KeywordPrefix = "secusense-credential:"
+	// QueryParam carries the credential in the verification URL of the QR code
+	QueryParam = "credential"
+)
+
+var (
+	ErrNoCredential     = errors.New("no certificate credential found")
+	ErrUnknownKey       = errors.New("credential is signed with an unknown key")
+	ErrInvalidSignature = errors.New("credential signature is invalid")
+)
+
+// credentialPattern finds a compact JWS after KeywordPrefix in raw PDF bytes
+var credentialPattern = regexp.MustCompile(regexp.QuoteMeta(KeywordPrefix) + `([A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+)`)
+
+// Claims are what a credential attests. The registered claims carry the
+// certificate number (jti), the issuer's name (iss) and the issue and expiry
+// dates.
+type Claims struct {
+	Holder           string `json:"holder"`
+	Course           string `json:"course"`
+	Score            int    `json:"score,omitempty"`
+	MaxScore         int    `json:"maxScore,omitempty"`
+	VerificationHash string `json:"vh"` // Looks the certificate up online
+	jwt.RegisteredClaims
+}
+
+// NewClaims describes a certificate. expiresAt is nil for certificates that
+// don't expire.
+func NewClaims(number, issuer, holder, course string, score, maxScore int, verificationHash string, issuedAt time.Time, expiresAt *time.Time) *Claims {
+	claims := &Claims{
+		Holder:           holder,
+		Course:           course,
+		Score:            score,
+		MaxScore:         maxScore,
+		VerificationHash: verificationHash,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:       number,
+			Issuer:   issuer,
+			IssuedAt: jwt.NewNumericDate(issuedAt),
+		},
+	}
+	if expiresAt != nil {
+		claims.ExpiresAt = jwt.NewNumericDate(*expiresAt)
+	}
+	return claims
+}
+
+// Expired reports whether the certificate had expired at now
+func (c *Claims) Expired(now time.Time) bool {
+	return c.ExpiresAt != nil && now.After(c.ExpiresAt.Time)
+}
+
+// JWK is an Ed25519 public key in JSON Web Key form (RFC 8037)
+type JWK struct {
+	Kty string `json:"kty"`
+	Crv string `json:"crv"`
+	Kid string `json:"kid"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	X   string `json:"x"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// PublicKey decodes the key
+func (j JWK) PublicKey() (ed25519.PublicKey, error) {
+	if j.Kty != "OKP" || j.Crv != "Ed25519" {
+		return nil, fmt.Errorf("key %s is not an Ed25519 key", j.Kid)
+	}
+	x, err := base64.RawURLEncoding.DecodeString(j.X)
+	if err != nil || len(x) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("key %s has a malformed public key", j.Kid)
+	}
+	return ed25519.PublicKey(x), nil
+}
+
+// Key is a key certificates are signed with
+type Key struct {
+	KID     string
+	Private ed25519.PrivateKey
+}
+
+// GenerateKey creates a new signing key. Its ID is the RFC 7638 thumbprint
+// of the public key.
+func GenerateKey() (*Key, error) {
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return &Key{KID: Thumbprint(private.Public().(ed25519.PublicKey)), Private: private}, nil
+}
+
+// ParseKey reads a PEM encoded PKCS#8 private key
+func ParseKey(kid, privatePEM string) (*Key, error) {
+	block, _ := pem.Decode([]byte(privatePEM))
+	if block == nil {
+		return nil, errors.New("signing key is not PEM encoded")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	private, ok := parsed.(ed25519.PrivateKey)
+	if !ok {
+		return nil, errors.New("signing key is not an Ed25519 key")
+	}
+	return &Key{KID: kid, Private: private}, nil
+}
+
+// MarshalPEM encodes the private key as PEM encoded PKCS#8
+func (k *Key) MarshalPEM() (string, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(k.Private)
+	if err != nil {
+		return "", err
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})), nil
+}
+
+// sealedPrefix marks a private key encrypted by SealPEM
+const sealedPrefix = "sealed:"
+
+// SealPEM encrypts a PEM encoded private key with AES-256-GCM under a key
+// derived from secret, so the stored key is useless without the secret
+func SealPEM(privatePEM, secret string) (string, error) {
+	gcm, err := sealingCipher(secret)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := gcm.Seal(nonce, nonce, []byte(privatePEM), nil)
+	return sealedPrefix + base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+// OpenPEM decrypts a private key sealed by SealPEM. A key stored before
+// keys were sealed is returned as it is.
+func OpenPEM(stored, secret string) (string, error) {
+	if !Sealed(stored) {
+		return stored, nil
+	}
+	sealed, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(stored, sealedPrefix))
+	if err != nil {
+		return "", errors.New("sealed signing key is malformed")
+	}
+	gcm, err := sealingCipher(secret)
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return "", errors.New("sealed signing key is malformed")
+	}
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	privatePEM, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", errors.New("signing key can't be opened with the configured secret")
+	}
+	return string(privatePEM), nil
+}
+
+// Sealed reports whether a stored private key is encrypted
+func Sealed(stored string) bool {
+	return strings.HasPrefix(stored, sealedPrefix)
+}
+
+func sealingCipher(secret string) (cipher.AEAD, error) {
+	if secret == "" {
+		return nil, errors.New("no secret to seal signing keys with")
+	}
+	key := sha256.Sum256([]byte(secret))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// Sign returns the claims as an EdDSA JWS carrying the key's ID
+func (k *Key) Sign(claims *Claims) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims)
+	token.Header["kid"] = k.KID
+	return token.SignedString(k.Private)
+}
+
+func (k *Key) JWK() JWK {
+	return JWK{
+		Kty: "OKP",
+		Crv: "Ed25519",
+		Kid: k.KID,
+		Use: "sig",
+		Alg: "EdDSA",
+		X:   base64.RawURLEncoding.EncodeToString(k.Private.Public().(ed25519.PublicKey)),
+	}
+}
+
+// Thumbprint returns the RFC 7638 thumbprint of an Ed25519 public key
+func Thumbprint(public ed25519.PublicKey) string {
+	x := base64.RawURLEncoding.EncodeToString(public)
+	// Members in lexicographic order, no whitespace
+	sum := sha256.Sum256([]byte(`{"crv":"Ed25519","kty":"OKP","x":"` + x + `"}`))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// Verify checks a credential's signature against the keys. An expired
+// certificate still verifies; see Claims.Expired.
+func Verify(credential string, keys *JWKS) (*Claims, error) {
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(credential, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		for _, k := range keys.Keys {
+			if k.Kid == kid {
+				return k.PublicKey()
+			}
+		}
+		return nil, ErrUnknownKey
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodEdDSA.Alg()}), jwt.WithoutClaimsValidation())
+	if err != nil {
+		if errors.Is(err, ErrUnknownKey) {
+			return nil, ErrUnknownKey
+		}
+		return nil, ErrInvalidSignature
+	}
+	return claims, nil
+}
+
+// Extract finds the credential in a certificate PDF, a scanned QR payload
+// (the verification URL) or a bare credential
+func Extract(data []byte) (string, error) {
+	if m := credentialPattern.FindSubmatch(data); m != nil {
+		return string(m[1]), nil
+	}
+
+	payload := strings.TrimSpace(string(data))
+	if u, err := url.Parse(payload); err == nil && u.Scheme != "" {
+		if credential := u.Query().Get(QueryParam); credential != "" {
+			return credential, nil
+		}
+		return "", ErrNoCredential
+	}
+	if strings.Count(payload, ".") == 2 && !strings.ContainsAny(payload, " \n") {
+		return payload, nil
+	}
+	return "", ErrNoCredential
+}
+
+// VerificationURL is the URL the QR code of a certificate points at
+func VerificationURL(baseURL, verificationHash, credential string) string {
+	u := fmt.Sprintf("%s/verify/%s", baseURL, verificationHash)
+	if credential != "" {
+		u += "?" + QueryParam + "=" + credential
+	}
+	return u
+}
@@ -0,0 +1,645 @@
+// Package gift reads and writes questions in Moodle's GIFT text format
+package gift
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/secusense/backend/internal/domain"
+)
+
+// Codec maps questions to and from GIFT. Multiple choice, true/false,
+// matching, numeric and single-blank fill-in-the-blank questions have a GIFT
+// equivalent; ordering, drag and drop and hotspot questions do not.
+type Codec struct{}
+
+func NewCodec() *Codec {
+	return &Codec{}
+}
+
+func (c *Codec) ContentType() string { return "text/plain; charset=utf-8" }
+
+func (c *Codec) Extension() string { return ".gift.txt" }
+
+// Encode writes one GIFT question per question, separated by blank lines.
+// The first tag of a question becomes its category.
+func (c *Codec) Encode(title string, questions []*domain.Question) ([]byte, []domain.InterchangeIssue, error) {
+	var b strings.Builder
+	var issues []domain.InterchangeIssue
+	fmt.Fprintf(&b, "// %s\n", strings.ReplaceAll(title, "\n", " "))
+
+	category := ""
+	for _, q := range questions {
+		text, issue := encodeQuestion(q)
+		if issue != nil {
+			issue.Item = q.ID.String()
+			issue.Title = q.QuestionText
+			issues = append(issues, *issue)
+		}
+		if text == "" {
+			continue
+		}
+
+		if tag := firstTag(q.Tags); tag != category && tag != "" {
+			fmt.Fprintf(&b, "\n$CATEGORY: %s\n", tag)
+			category = tag
+		}
+		b.WriteString("\n")
+		b.WriteString(text)
+		b.WriteString("\n")
+	}
+
+	// The report travels with the file, for whoever imports it elsewhere
+	for _, issue := range issues {
+		if issue.Skipped {
+			fmt.Fprintf(&b, "\n// Not exported: %s (%s)", oneLine(issue.Title), issue.Reason)
+		}
+	}
+	if len(issues) > 0 {
+		b.WriteString("\n")
+	}
+	return []byte(b.String()), issues, nil
+}
+
+// encodeQuestion returns the GIFT text of a question, or an empty text and
+// the reason if it has none. A non-skipping issue describes what was lost.
+func encodeQuestion(q *domain.Question) (string, *domain.InterchangeIssue) {
+	skip := func(reason string) (string, *domain.InterchangeIssue) {
+		return "", &domain.InterchangeIssue{Reason: reason, Skipped: true}
+	}
+	invalid := func() (string, *domain.InterchangeIssue) {
+		return skip("the question data could not be read")
+	}
+	text := escape(q.QuestionText)
+
+	switch q.QuestionType {
+	case domain.QuestionTypeMultipleChoice:
+		var data domain.MultipleChoiceData
+		if err := json.Unmarshal(q.QuestionData, &data); err != nil || len(data.Options) == 0 {
+			return invalid()
+		}
+		correct := make(map[int]bool, len(data.CorrectIndices))
+		for _, i := range data.CorrectIndices {
+			correct[i] = true
+		}
+		var answers []string
+		for i, option := range data.Options {
+			switch {
+			case len(correct) == 1 && correct[i]:
+				answers = append(answers, "="+escape(option))
+			case len(correct) == 1:
+				answers = append(answers, "~"+escape(option))
+			case correct[i]:
+				answers = append(answers, "~%"+weight(100/float64(len(correct)))+"%"+escape(option))
+			default:
+				answers = append(answers, "~%-100%"+escape(option))
+			}
+		}
+		return block(text, answers, data.Explanation), nil
+
+	case domain.QuestionTypeTrueFalse:
+		var data domain.TrueFalseData
+		if err := json.Unmarshal(q.QuestionData, &data); err != nil {
+			return invalid()
+		}
+		answer := "FALSE"
+		if data.CorrectAnswer {
+			answer = "TRUE"
+		}
+		return block(text, []string{answer}, data.Explanation), nil
+
+	case domain.QuestionTypeMatching:
+		var data domain.MatchingData
+		if err := json.Unmarshal(q.QuestionData, &data); err != nil || len(data.CorrectPairs) == 0 {
+			return invalid()
+		}
+		var answers []string
+		paired := make(map[string]bool)
+		for _, left := range data.LeftItems {
+			right, ok := data.CorrectPairs[left]
+			if !ok {
+				continue
+			}
+			paired[right] = true
+			answers = append(answers, "="+escape(left)+" -> "+escape(right))
+		}
+		// Unpaired right items are distractors
+		for _, right := range data.RightItems {
+			if !paired[right] {
+				answers = append(answers, "= -> "+escape(right))
+			}
+		}
+		return block(text, answers, data.Explanation), nil
+
+	case domain.QuestionTypeFillBlank:
+		var data domain.FillBlankData
+		if err := json.Unmarshal(q.QuestionData, &data); err != nil {
+			return invalid()
+		}
+		if len(data.Blanks) != 1 || strings.Count(data.Template, "{{blank}}") != 1 {
+			return skip("GIFT allows only one blank per question")
+		}
+		answers := []string{"=" + escape(data.Blanks[0])}
+		if len(data.Alternatives) > 0 {
+			for _, alt := range data.Alternatives[0] {
+				answers = append(answers, "="+escape(alt))
+			}
+		}
+		before, after, _ := strings.Cut(data.Template, "{{blank}}")
+		// The question text goes on its own line above the sentence
+		gift := text + `\n` + escape(before) + answerBlock(answers, data.Explanation) + escape(after)
+
+		var lost []string
+		if len(data.Patterns) > 0 {
+			lost = append(lost, "answer patterns")
+		}
+		if data.CaseSensitive {
+			lost = append(lost, "case sensitivity")
+		}
+		if data.MaxEdits > 0 {
+			lost = append(lost, "typo tolerance")
+		}
+		if len(lost) > 0 {
+			return gift, &domain.InterchangeIssue{Reason: "exported without its " + strings.Join(lost, ", ")}
+		}
+		return gift, nil
+
+	case domain.QuestionTypeNumeric:
+		var data domain.NumericData
+		if err := json.Unmarshal(q.QuestionData, &data); err != nil {
+			return invalid()
+		}
+		answer := "#" + formatNumber(data.CorrectValue)
+		if data.Tolerance > 0 {
+			answer += ":" + formatNumber(data.Tolerance)
+		}
+		gift := block(text, []string{answer}, data.Explanation)
+		if data.Unit != "" {
+			return gift, &domain.InterchangeIssue{Reason: "exported without its unit " + data.Unit}
+		}
+		return gift, nil
+	}
+
+	return skip("GIFT has no " + strings.ReplaceAll(string(q.QuestionType), "_", " ") + " questions")
+}
+
+func block(text string, answers []string, explanation string) string {
+	return text + " " + answerBlock(answers, explanation)
+}
+
+func answerBlock(answers []string, explanation string) string {
+	var b strings.Builder
+	b.WriteString("{")
+	if len(answers) == 1 {
+		b.WriteString(answers[0])
+	} else {
+		for _, a := range answers {
+			b.WriteString("\n\t" + a)
+		}
+		b.WriteString("\n")
+	}
+	if explanation != "" {
+		b.WriteString("####" + escape(explanation))
+		if len(answers) > 1 {
+			b.WriteString("\n")
+		}
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+// Decode reads the questions of a GIFT file. Essay and description items,
+// and questions whose answers can't be read, are reported and skipped.
+func (c *Codec) Decode(data []byte) ([]*domain.Question, []domain.InterchangeIssue, error) {
+	if !utf8.Valid(data) {
+		return nil, nil, errors.New("GIFT files must be UTF-8 text")
+	}
+
+	var questions []*domain.Question
+	var issues []domain.InterchangeIssue
+	category := ""
+	n := 0
+	for _, chunk := range splitQuestions(string(data)) {
+		if strings.HasPrefix(chunk, "$CATEGORY:") {
+			category = strings.TrimSpace(strings.TrimPrefix(chunk, "$CATEGORY:"))
+			continue
+		}
+		n++
+
+		q, err := decodeQuestion(chunk)
+		if err != nil {
+			issues = append(issues, domain.InterchangeIssue{
+				Item:    fmt.Sprintf("question %d", n),
+				Title:   oneLine(chunk),
+				Reason:  err.Error(),
+				Skipped: true,
+			})
+			continue
+		}
+		if category != "" {
+			q.Tags, _ = json.Marshal([]string{category})
+		}
+		questions = append(questions, q)
+	}
+
+	if len(questions) == 0 && len(issues) == 0 {
+		return nil, nil, errors.New("no GIFT questions found")
+	}
+	return questions, issues, nil
+}
+
+// splitQuestions returns the questions and category lines of a file, without
+// comments. Questions are separated by blank lines.
+func splitQuestions(text string) []string {
+	var chunks []string
+	var current []string
+	flush := func() {
+		if chunk := strings.TrimSpace(strings.Join(current, "\n")); chunk != "" {
+			chunks = append(chunks, chunk)
+		}
+		current = nil
+	}
+
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, "//"):
+			continue
+		case trimmed == "":
+			flush()
+		case strings.HasPrefix(trimmed, "$CATEGORY:"):
+			flush()
+			chunks = append(chunks, trimmed)
+		default:
+			current = append(current, line)
+		}
+	}
+	flush()
+	return chunks
+}
+
+func decodeQuestion(chunk string) (*domain.Question, error) {
+	var title string
+	if strings.HasPrefix(chunk, "::") {
+		end := indexUnescaped(chunk[2:], "::")
+		if end < 0 {
+			return nil, errors.New("unterminated question title")
+		}
+		title = unescape(strings.TrimSpace(chunk[2 : 2+end]))
+		chunk = strings.TrimSpace(chunk[4+end:])
+	}
+	chunk = stripTextFormat(chunk)
+
+	open := indexUnescaped(chunk, "{")
+	if open < 0 {
+		return nil, errors.New("description items have no answers to import")
+	}
+	closing := indexUnescaped(chunk[open:], "}")
+	if closing < 0 {
+		return nil, errors.New("unterminated answer block")
+	}
+	closing += open
+	before, inside, after := chunk[:open], strings.TrimSpace(chunk[open+1:closing]), chunk[closing+1:]
+
+	text := strings.TrimSpace(unescape(before + after))
+	if strings.TrimSpace(after) != "" {
+		// "Missing word" questions have the answers inside the sentence
+		text = strings.TrimSpace(unescape(before)) + " _____ " + strings.TrimSpace(unescape(after))
+	}
+	if text == "" {
+		text = title
+	}
+
+	inside, explanation := cutUnescaped(inside, "####")
+	explanation = strings.TrimSpace(unescape(explanation))
+
+	switch {
+	case inside == "":
+		return nil, errors.New("essay questions have no rubric to grade against")
+
+	case strings.HasPrefix(inside, "#"):
+		data, err := decodeNumeric(inside[1:])
+		if err != nil {
+			return nil, err
+		}
+		data.Explanation = explanation
+		return newQuestion(domain.QuestionTypeNumeric, text, data)
+
+	case isTrueFalse(inside):
+		answer, _ := cutUnescaped(inside, "#")
+		answer = strings.ToUpper(strings.TrimSpace(answer))
+		return newQuestion(domain.QuestionTypeTrueFalse, text, domain.TrueFalseData{
+			CorrectAnswer: answer == "T" || answer == "TRUE",
+			Explanation:   explanation,
+		})
+	}
+
+	answers, err := parseAnswers(inside)
+	if err != nil {
+		return nil, err
+	}
+
+	allCorrect, allPairs := true, true
+	for _, a := range answers {
+		allCorrect = allCorrect && a.kind == '='
+		allPairs = allPairs && a.kind == '=' && indexUnescaped(a.raw, "->") >= 0
+	}
+
+	switch {
+	case allPairs:
+		data := domain.MatchingData{CorrectPairs: make(map[string]string), Explanation: explanation}
+		for _, a := range answers {
+			i := indexUnescaped(a.raw, "->")
+			left, right := strings.TrimSpace(unescape(a.raw[:i])), strings.TrimSpace(unescape(a.raw[i+2:]))
+			if right == "" {
+				return nil, errors.New("matching pair without a right-hand item")
+			}
+			data.RightItems = appendUnique(data.RightItems, right)
+			if left == "" {
+				continue
+			}
+			if _, dup := data.CorrectPairs[left]; dup {
+				return nil, errors.New("matching question lists an item twice")
+			}
+			data.LeftItems = append(data.LeftItems, left)
+			data.CorrectPairs[left] = right
+		}
+		if len(data.LeftItems) == 0 {
+			return nil, errors.New("matching question has no pairs")
+		}
+		return newQuestion(domain.QuestionTypeMatching, text, data)
+
+	case allCorrect:
+		// Short answer: every listed answer is accepted
+		data := domain.FillBlankData{Explanation: explanation}
+		for _, a := range answers {
+			if a.weight != nil && *a.weight <= 0 {
+				continue
+			}
+			if len(data.Blanks) == 0 {
+				data.Blanks = []string{a.text}
+				continue
+			}
+			if len(data.Alternatives) == 0 {
+				data.Alternatives = [][]string{{}}
+			}
+			data.Alternatives[0] = append(data.Alternatives[0], a.text)
+		}
+		if len(data.Blanks) == 0 {
+			return nil, errors.New("short answer question has no accepted answer")
+		}
+		questionText, template := fillBlankTemplate(before, after)
+		if questionText == "" {
+			questionText = title
+		}
+		if questionText == "" {
+			questionText = "Fill in the blank"
+		}
+		data.Template = template
+		return newQuestion(domain.QuestionTypeFillBlank, questionText, data)
+	}
+
+	data := domain.MultipleChoiceData{Explanation: explanation}
+	for i, a := range answers {
+		if a.kind == '=' || (a.weight != nil && *a.weight > 0) {
+			data.CorrectIndices = append(data.CorrectIndices, i)
+		}
+		data.Options = append(data.Options, a.text)
+	}
+	if len(data.CorrectIndices) == 0 {
+		return nil, errors.New("multiple choice question has no correct option")
+	}
+	return newQuestion(domain.QuestionTypeMultipleChoice, text, data)
+}
+
+// fillBlankTemplate splits the text around a short answer block into the
+// question and the sentence with the blank: the last line before the block
+// starts the sentence.
+func fillBlankTemplate(before, after string) (string, string) {
+	head, tail := "", before
+	for i := 0; i < len(before); i++ {
+		switch {
+		case before[i] == '\n':
+			head, tail = before[:i], before[i+1:]
+		case before[i] == '\\' && i+1 < len(before):
+			if before[i+1] == 'n' {
+				head, tail = before[:i], before[i+2:]
+			}
+			i++
+		}
+	}
+	tail = strings.TrimLeft(unescape(tail), " \t")
+	after = strings.TrimRight(unescape(after), " \t\n")
+	return strings.TrimSpace(unescape(head)), tail + "{{blank}}" + after
+}
+
+type answer struct {
+	kind   byte // '=' or '~'
+	weight *float64
+	raw    string // Escaped, without kind and weight
+	text   string
+}
+
+func parseAnswers(inside string) ([]answer, error) {
+	var answers []answer
+	start := -1
+	flush := func(end int) error {
+		if start < 0 {
+			if strings.TrimSpace(inside[:end]) != "" {
+				return errors.New("answers must start with = or ~")
+			}
+			return nil
+		}
+		a := answer{kind: inside[start], raw: strings.TrimSpace(inside[start+1 : end])}
+		if strings.HasPrefix(a.raw, "%") {
+			closing := strings.Index(a.raw[1:], "%")
+			if closing < 0 {
+				return errors.New("unterminated answer weight")
+			}
+			w, err := strconv.ParseFloat(a.raw[1:1+closing], 64)
+			if err != nil {
+				return errors.New("invalid answer weight")
+			}
+			a.weight = &w
+			a.raw = strings.TrimSpace(a.raw[2+closing:])
+		}
+		// Per-answer feedback is dropped; only the general feedback is kept
+		a.raw, _ = cutUnescaped(a.raw, "#")
+		a.raw = strings.TrimSpace(a.raw)
+		a.text = strings.TrimSpace(unescape(a.raw))
+		answers = append(answers, a)
+		return nil
+	}
+
+	for i := 0; i < len(inside); i++ {
+		switch inside[i] {
+		case '\\':
+			i++
+		case '=', '~':
+			if err := flush(i); err != nil {
+				return nil, err
+			}
+			start = i
+		}
+	}
+	if err := flush(len(inside)); err != nil {
+		return nil, err
+	}
+	if len(answers) == 0 {
+		return nil, errors.New("no answers")
+	}
+	return answers, nil
+}
+
+// decodeNumeric reads "value", "value:tolerance" or "min..max". Of several
+// weighted answers, the first full-credit one is used.
+func decodeNumeric(s string) (domain.NumericData, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "=") {
+		answers, err := parseAnswers(s)
+		if err != nil {
+			return domain.NumericData{}, err
+		}
+		for _, a := range answers {
+			if a.kind == '=' && (a.weight == nil || *a.weight >= 100) {
+				return decodeNumeric(a.raw)
+			}
+		}
+		return domain.NumericData{}, errors.New("numeric question has no full-credit answer")
+	}
+	s, _ = cutUnescaped(s, "#")
+	s = strings.TrimSpace(s)
+
+	if lo, hi, ok := strings.Cut(s, ".."); ok {
+		min, err1 := strconv.ParseFloat(strings.TrimSpace(lo), 64)
+		max, err2 := strconv.ParseFloat(strings.TrimSpace(hi), 64)
+		if err1 != nil || err2 != nil || max < min {
+			return domain.NumericData{}, errors.New("invalid numeric range")
+		}
+		return domain.NumericData{CorrectValue: (min + max) / 2, Tolerance: (max - min) / 2}, nil
+	}
+
+	value, tolerance, _ := strings.Cut(s, ":")
+	var data domain.NumericData
+	var err error
+	if data.CorrectValue, err = strconv.ParseFloat(strings.TrimSpace(value), 64); err != nil {
+		return data, errors.New("invalid numeric answer")
+	}
+	if tolerance != "" {
+		if data.Tolerance, err = strconv.ParseFloat(strings.TrimSpace(tolerance), 64); err != nil || data.Tolerance < 0 {
+			return data, errors.New("invalid numeric tolerance")
+		}
+	}
+	return data, nil
+}
+
+func isTrueFalse(inside string) bool {
+	answer, _ := cutUnescaped(inside, "#")
+	switch strings.ToUpper(strings.TrimSpace(answer)) {
+	case "T", "TRUE", "F", "FALSE":
+		return true
+	}
+	return false
+}
+
+func newQuestion(qType domain.QuestionType, text string, data any) (*domain.Question, error) {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.Question{QuestionType: qType, QuestionText: text, QuestionData: encoded}, nil
+}
+
+// stripTextFormat drops a leading [html], [moodle], [plain] or [markdown]
+// marker
+func stripTextFormat(s string) string {
+	for _, marker := range []string{"[html]", "[moodle]", "[plain]", "[markdown]"} {
+		if strings.HasPrefix(s, marker) {
+			return strings.TrimSpace(s[len(marker):])
+		}
+	}
+	return s
+}
+
+// indexUnescaped finds sep outside backslash escapes
+func indexUnescaped(s, sep string) int {
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' {
+			i++
+			continue
+		}
+		if strings.HasPrefix(s[i:], sep) {
+			return i
+		}
+	}
+	return -1
+}
+
+func cutUnescaped(s, sep string) (string, string) {
+	if i := indexUnescaped(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):]
+	}
+	return s, ""
+}
+
+var escaper = strings.NewReplacer(`\`, `\\`, `~`, `\~`, `=`, `\=`, `#`, `\#`, `{`, `\{`, `}`, `\}`, `:`, `\:`, "\n", `\n`)
+
+func escape(s string) string {
+	return escaper.Replace(s)
+}
+
+func unescape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+			if s[i] == 'n' {
+				b.WriteByte('\n')
+			} else {
+				b.WriteByte(s[i])
+			}
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// weight formats an answer weight the way Moodle writes them, e.g. 33.33333
+func weight(w float64) string {
+	s := strconv.FormatFloat(w, 'f', 5, 64)
+	return strings.TrimRight(strings.TrimRight(s, "0"), ".")
+}
+
+func formatNumber(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+func firstTag(raw json.RawMessage) string {
+	var tags []string
+	if err := json.Unmarshal(raw, &tags); err != nil || len(tags) == 0 {
+		return ""
+	}
+	return strings.TrimSpace(strings.ReplaceAll(tags[0], "\n", " "))
+}
+
+func appendUnique(items []string, item string) []string {
+	for _, existing := range items {
+		if existing == item {
+			return items
+		}
+	}
+	return append(items, item)
+}
+
+func oneLine(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if r := []rune(s); len(r) > 80 {
+		return string(r[:77]) + "..."
+	}
+	return s
+}
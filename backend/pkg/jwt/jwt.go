@@ -20,6 +20,10 @@ type Claims struct {
 	UserID uuid.UUID       `json:"userId"`
 	Email  string          `json:"email"`
 	Role   domain.UserRole `json:"role"`
+	// OrganizationID is the tenant the user belongs to
+	OrganizationID uuid.UUID `json:"orgId"`
+	// Permissions of the user's role when the token was issued
+	Permissions []domain.Permission `json:"perms,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -63,9 +67,11 @@ func (m *Manager) GenerateTokenPair(user *domain.User) (*TokenPair, error) {
 
 func (m *Manager) generateAccessToken(user *domain.User) (string, error) {
 	claims := &Claims{
-		UserID: user.ID,
-		Email:  user.Email,
-		Role:   user.Role,
+		UserID:         user.ID,
+		Email:          user.Email,
+		Role:           user.Role,
+		OrganizationID: user.OrganizationID,
+		Permissions:    user.Permissions,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(m.accessExpiresIn)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -0,0 +1,163 @@
+// Package openbadge issues certificates as Open Badges 3.0 credentials: W3C
+// Verifiable Credentials in JSON-LD that digital wallets and professional
+// networks can import. Credentials are secured with a Data Integrity proof
+// using the eddsa-jcs-2022 cryptosuite, so the same Ed25519 keys that sign
+// the certificate PDFs sign the badges, and a credential verifies without
+// an RDF canonicalization library.
+package openbadge
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidCredential = errors.New("not an Open Badges credential")
+	ErrInvalidProof      = errors.New("credential proof is invalid")
+)
+
+// Context is the JSON-LD context of an Open Badges 3.0 credential
+var Context = []string{
+	"https://www.w3.org/ns/credentials/v2",
+	"https://purl.imsglobal.org/spec/ob/v3p0/context-3.0.3.json",
+}
+
+// MediaType is the media type credentials are served with
+const MediaType = "application/vc+ld+json"
+
+type Credential struct {
+	Context           []string           `json:"@context"`
+	ID                string             `json:"id"`
+	Type              []string           `json:"type"`
+	Name              string             `json:"name"`
+	Issuer            Profile            `json:"issuer"`
+	ValidFrom         string             `json:"validFrom"`
+	ValidUntil        string             `json:"validUntil,omitempty"`
+	CredentialSubject AchievementSubject `json:"credentialSubject"`
+	CredentialStatus  *StatusEntry       `json:"credentialStatus,omitempty"`
+	Proof             *Proof             `json:"proof,omitempty"`
+}
+
+// Profile describes the issuer. Its ID is the did:key of the key the
+// credential is signed with.
+type Profile struct {
+	ID   string   `json:"id"`
+	Type []string `json:"type"`
+	Name string   `json:"name"`
+}
+
+type AchievementSubject struct {
+	Type        []string         `json:"type"`
+	Identifier  []IdentityObject `json:"identifier"`
+	Achievement Achievement      `json:"achievement"`
+	Result      []Result         `json:"result,omitempty"`
+}
+
+// IdentityObject identifies the learner, by name and by a salted hash of
+// their email address
+type IdentityObject struct {
+	Type         string `json:"type"`
+	IdentityHash string `json:"identityHash"`
+	IdentityType string `json:"identityType"`
+	Hashed       bool   `json:"hashed"`
+	Salt         string `json:"salt,omitempty"`
+}
+
+type Achievement struct {
+	ID              string   `json:"id"`
+	Type            []string `json:"type"`
+	AchievementType string   `json:"achievementType"`
+	Name            string   `json:"name"`
+	Description     string   `json:"description"`
+	Criteria        Criteria `json:"criteria"`
+}
+
+type Criteria struct {
+	Narrative string `json:"narrative"`
+}
+
+type Result struct {
+	Type  []string `json:"type"`
+	Value string   `json:"value"`
+}
+
+// Badge holds what a credential attests
+type Badge struct {
+	CertificateID string
+	IssuerName    string
+	HolderName    string
+	HolderEmail   string
+	CourseID      string
+	CourseTitle   string
+	Score         int
+	MaxScore      int
+	IssuedAt      time.Time
+	ExpiresAt     *time.Time
+	// The revocation list the certificate is in, and its bit there; no
+	// credentialStatus is added without a list
+	StatusListURL   string
+	StatusListIndex int
+}
+
+// NewCredential describes a certificate as an unsigned credential; Sign
+// sets the issuer's ID and adds the proof
+func NewCredential(b Badge) (*Credential, error) {
+	salt := make([]byte, 8)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	saltHex := hex.EncodeToString(salt)
+	emailHash := sha256.Sum256([]byte(strings.ToLower(b.HolderEmail) + saltHex))
+
+	cred := &Credential{
+		Context:   Context,
+		ID:        "urn:uuid:" + b.CertificateID,
+		Type:      []string{"VerifiableCredential", "OpenBadgeCredential"},
+		Name:      b.CourseTitle,
+		Issuer:    Profile{Type: []string{"Profile"}, Name: b.IssuerName},
+		ValidFrom: formatTime(b.IssuedAt),
+		CredentialSubject: AchievementSubject{
+			Type: []string{"AchievementSubject"},
+			Identifier: []IdentityObject{
+				{Type: "IdentityObject", IdentityHash: b.HolderName, IdentityType: "name"},
+				{Type: "IdentityObject", IdentityHash: "sha256$" + hex.EncodeToString(emailHash[:]), IdentityType: "emailAddress", Hashed: true, Salt: saltHex},
+			},
+			Achievement: Achievement{
+				ID:              "urn:uuid:" + b.CourseID,
+				Type:            []string{"Achievement"},
+				AchievementType: "Certificate",
+				Name:            b.CourseTitle,
+				Description:     "Completed the security awareness course " + b.CourseTitle + " and passed its final test.",
+				Criteria:        Criteria{Narrative: "Complete every lesson of the course and pass its final test."},
+			},
+		},
+	}
+	if b.ExpiresAt != nil {
+		cred.ValidUntil = formatTime(*b.ExpiresAt)
+	}
+	if b.StatusListURL != "" {
+		cred.CredentialStatus = newStatusEntry(b.StatusListURL, b.StatusListIndex)
+	}
+	if b.MaxScore > 0 {
+		cred.CredentialSubject.Result = []Result{{
+			Type:  []string{"Result"},
+			Value: fmt.Sprintf("%d/%d", b.Score, b.MaxScore),
+		}}
+	}
+	return cred, nil
+}
+
+// CertificateID returns the ID of the certificate a credential was issued
+// for
+func (c *Credential) CertificateID() (string, bool) {
+	return strings.CutPrefix(c.ID, "urn:uuid:")
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,97 @@
+package openbadge
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"math/big"
+	"strings"
+)
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// ed25519Multicodec prefixes an Ed25519 public key in a Multikey
+var ed25519Multicodec = []byte{0xed, 0x01}
+
+var errMalformedKey = errors.New("malformed did:key verification method")
+
+// DIDKey returns the did:key identifier of an Ed25519 public key
+func DIDKey(public ed25519.PublicKey) string {
+	return "did:key:" + multikey(public)
+}
+
+// VerificationMethod returns the did:key verification method of an Ed25519
+// public key, the DID followed by its key fragment
+func VerificationMethod(public ed25519.PublicKey) string {
+	mk := multikey(public)
+	return "did:key:" + mk + "#" + mk
+}
+
+// publicKeyOf resolves a did:key verification method to its Ed25519 public
+// key and the DID it belongs to
+func publicKeyOf(verificationMethod string) (ed25519.PublicKey, string, error) {
+	did, fragment, _ := strings.Cut(verificationMethod, "#")
+	mk, ok := strings.CutPrefix(did, "did:key:")
+	if !ok || (fragment != "" && fragment != mk) || !strings.HasPrefix(mk, "z") {
+		return nil, "", errMalformedKey
+	}
+
+	decoded, err := base58Decode(mk[1:])
+	if err != nil {
+		return nil, "", err
+	}
+	if len(decoded) != len(ed25519Multicodec)+ed25519.PublicKeySize ||
+		decoded[0] != ed25519Multicodec[0] || decoded[1] != ed25519Multicodec[1] {
+		return nil, "", errMalformedKey
+	}
+	return ed25519.PublicKey(decoded[len(ed25519Multicodec):]), did, nil
+}
+
+func multikey(public ed25519.PublicKey) string {
+	return "z" + base58Encode(append(append([]byte{}, ed25519Multicodec...), public...))
+}
+
+// base58Encode encodes with the Bitcoin alphabet, as multibase base58btc
+// does
+func base58Encode(data []byte) string {
+	n := new(big.Int).SetBytes(data)
+	radix := big.NewInt(58)
+	mod := new(big.Int)
+
+	var out []byte
+	for n.Sign() > 0 {
+		n.DivMod(n, radix, mod)
+		out = append(out, base58Alphabet[mod.Int64()])
+	}
+	// Leading zero bytes are kept as leading ones
+	for _, b := range data {
+		if b != 0 {
+			break
+		}
+		out = append(out, base58Alphabet[0])
+	}
+
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return string(out)
+}
+
+func base58Decode(s string) ([]byte, error) {
+	n := new(big.Int)
+	radix := big.NewInt(58)
+	for _, c := range s {
+		i := strings.IndexRune(base58Alphabet, c)
+		if i < 0 {
+			return nil, errors.New("invalid base58 character")
+		}
+		n.Mul(n, radix)
+		n.Add(n, big.NewInt(int64(i)))
+	}
+
+	decoded := n.Bytes()
+	zeros := 0
+	for zeros < len(s) && s[zeros] == base58Alphabet[0] {
+		zeros++
+	}
+	return append(make([]byte, zeros), decoded...), nil
+}
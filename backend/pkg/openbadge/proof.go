@@ -0,0 +1,167 @@
+package openbadge
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/json"
+	"time"
+)
+
+const (
+	proofType      = "DataIntegrityProof"
+	cryptosuite    = "eddsa-jcs-2022"
+	assertionProof = "assertionMethod"
+)
+
+// Proof is a Data Integrity proof
+type Proof struct {
+	Type               string `json:"type"`
+	Cryptosuite        string `json:"cryptosuite"`
+	Created            string `json:"created"`
+	VerificationMethod string `json:"verificationMethod"`
+	ProofPurpose       string `json:"proofPurpose"`
+	ProofValue         string `json:"proofValue,omitempty"`
+}
+
+// Sign issues the credential under the key's did:key and returns the
+// signed credential document
+func Sign(cred *Credential, key ed25519.PrivateKey, created time.Time) ([]byte, error) {
+	cred.Issuer.ID = DIDKey(key.Public().(ed25519.PublicKey))
+	cred.Proof = nil
+
+	proof, err := prove(cred, key, created)
+	if err != nil {
+		return nil, err
+	}
+	cred.Proof = proof
+	return encode(cred)
+}
+
+// prove computes the proof of a document, which has no proof yet
+func prove(document interface{}, key ed25519.PrivateKey, created time.Time) (*Proof, error) {
+	unsecured, err := json.Marshal(document)
+	if err != nil {
+		return nil, err
+	}
+	proof := &Proof{
+		Type:               proofType,
+		Cryptosuite:        cryptosuite,
+		Created:            formatTime(created),
+		VerificationMethod: VerificationMethod(key.Public().(ed25519.PublicKey)),
+		ProofPurpose:       assertionProof,
+	}
+	proofConfig, err := json.Marshal(proof)
+	if err != nil {
+		return nil, err
+	}
+
+	hash, err := hashData(unsecured, proofConfig)
+	if err != nil {
+		return nil, err
+	}
+	proof.ProofValue = "z" + base58Encode(ed25519.Sign(key, hash))
+	return proof, nil
+}
+
+func encode(document interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(document); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Verify checks the proof of a credential document and returns the
+// credential with the public key it was signed with. It doesn't check that
+// the key belongs to a trusted issuer; that is up to the caller.
+func Verify(data []byte) (*Credential, ed25519.PublicKey, error) {
+	var document map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&document); err != nil {
+		return nil, nil, ErrInvalidCredential
+	}
+	var cred Credential
+	if err := json.Unmarshal(data, &cred); err != nil || cred.Proof == nil {
+		return nil, nil, ErrInvalidCredential
+	}
+
+	proof := *cred.Proof
+	if proof.Type != proofType || proof.Cryptosuite != cryptosuite || proof.ProofPurpose != assertionProof {
+		return nil, nil, ErrInvalidProof
+	}
+	public, did, err := publicKeyOf(proof.VerificationMethod)
+	if err != nil || cred.Issuer.ID != did {
+		return nil, nil, ErrInvalidProof
+	}
+	if len(proof.ProofValue) < 2 || proof.ProofValue[0] != 'z' {
+		return nil, nil, ErrInvalidProof
+	}
+	signature, err := base58Decode(proof.ProofValue[1:])
+	if err != nil {
+		return nil, nil, ErrInvalidProof
+	}
+
+	// The proof configuration is the proof without its value; the
+	// document is everything but the proof
+	rawProof, _ := document["proof"].(map[string]interface{})
+	delete(rawProof, "proofValue")
+	delete(rawProof, "@context")
+	delete(document, "proof")
+
+	unsecured, err := json.Marshal(document)
+	if err != nil {
+		return nil, nil, ErrInvalidCredential
+	}
+	proofConfig, err := json.Marshal(rawProof)
+	if err != nil {
+		return nil, nil, ErrInvalidCredential
+	}
+	hash, err := hashData(unsecured, proofConfig)
+	if err != nil {
+		return nil, nil, ErrInvalidCredential
+	}
+
+	if !ed25519.Verify(public, hash, signature) {
+		return nil, nil, ErrInvalidProof
+	}
+	return &cred, public, nil
+}
+
+// hashData hashes the proof configuration, carrying the document's
+// context, and the document as eddsa-jcs-2022 does: the SHA-256 of each
+// canonical form, the proof configuration's first
+func hashData(document, proofConfig []byte) ([]byte, error) {
+	canonicalDocument, err := Canonicalize(document)
+	if err != nil {
+		return nil, err
+	}
+
+	var config map[string]interface{}
+	if err := json.Unmarshal(proofConfig, &config); err != nil {
+		return nil, err
+	}
+	var withContext struct {
+		Context interface{} `json:"@context"`
+	}
+	if err := json.Unmarshal(document, &withContext); err != nil {
+		return nil, err
+	}
+	config["@context"] = withContext.Context
+	configJSON, err := json.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+	canonicalConfig, err := Canonicalize(configJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	configHash := sha256.Sum256(canonicalConfig)
+	documentHash := sha256.Sum256(canonicalDocument)
+	return append(configHash[:], documentHash[:]...), nil
+}
@@ -0,0 +1,112 @@
+package openbadge
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/ed25519"
+	"encoding/base64"
+	"strconv"
+	"time"
+)
+
+// MinStatusListSize is the minimum number of bits of a status list, so that
+// a list doesn't tell how many credentials were issued
+const MinStatusListSize = 131072
+
+// StatusEntry points a credential at its bit in a revocation list. The
+// Bitstring Status List is the W3C successor of StatusList2021.
+type StatusEntry struct {
+	ID                   string `json:"id"`
+	Type                 string `json:"type"`
+	StatusPurpose        string `json:"statusPurpose"`
+	StatusListIndex      string `json:"statusListIndex"`
+	StatusListCredential string `json:"statusListCredential"`
+}
+
+func newStatusEntry(listURL string, index int) *StatusEntry {
+	return &StatusEntry{
+		ID:                   listURL + "#" + strconv.Itoa(index),
+		Type:                 "BitstringStatusListEntry",
+		StatusPurpose:        "revocation",
+		StatusListIndex:      strconv.Itoa(index),
+		StatusListCredential: listURL,
+	}
+}
+
+// StatusListCredential publishes the revocation bits of all credentials
+type StatusListCredential struct {
+	Context           []string   `json:"@context"`
+	ID                string     `json:"id"`
+	Type              []string   `json:"type"`
+	Issuer            Profile    `json:"issuer"`
+	ValidFrom         string     `json:"validFrom"`
+	CredentialSubject StatusList `json:"credentialSubject"`
+	Proof             *Proof     `json:"proof,omitempty"`
+}
+
+type StatusList struct {
+	ID            string `json:"id"`
+	Type          string `json:"type"`
+	StatusPurpose string `json:"statusPurpose"`
+	EncodedList   string `json:"encodedList"`
+}
+
+// SignStatusList issues the revocation list published at listURL, with the
+// bits of the revoked indexes set, and returns the signed document. size is
+// the number of bits and is raised to MinStatusListSize.
+func SignStatusList(listURL, issuerName string, revoked []int, size int, key ed25519.PrivateKey, now time.Time) ([]byte, error) {
+	encoded, err := encodeStatusList(revoked, size)
+	if err != nil {
+		return nil, err
+	}
+
+	list := &StatusListCredential{
+		Context: []string{Context[0]},
+		ID:      listURL,
+		Type:    []string{"VerifiableCredential", "BitstringStatusListCredential"},
+		Issuer: Profile{
+			ID:   DIDKey(key.Public().(ed25519.PublicKey)),
+			Type: []string{"Profile"},
+			Name: issuerName,
+		},
+		ValidFrom: formatTime(now),
+		CredentialSubject: StatusList{
+			ID:            listURL + "#list",
+			Type:          "BitstringStatusList",
+			StatusPurpose: "revocation",
+			EncodedList:   encoded,
+		},
+	}
+
+	proof, err := prove(list, key, now)
+	if err != nil {
+		return nil, err
+	}
+	list.Proof = proof
+	return encode(list)
+}
+
+// encodeStatusList sets the bits of the indexes, the first bit being the
+// most significant of the first byte, and encodes the list as GZIP
+// compressed multibase base64url
+func encodeStatusList(indexes []int, size int) (string, error) {
+	if size < MinStatusListSize {
+		size = MinStatusListSize
+	}
+	bits := make([]byte, (size+7)/8)
+	for _, i := range indexes {
+		if i >= 0 && i/8 < len(bits) {
+			bits[i/8] |= 0x80 >> (i % 8)
+		}
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(bits); err != nil {
+		return "", err
+	}
+	if err := zw.Close(); err != nil {
+		return "", err
+	}
+	return "u" + base64.RawURLEncoding.EncodeToString(buf.Bytes()), nil
+}
@@ -2,18 +2,24 @@ package pdf
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/johnfercher/maroto/v2"
 	"github.com/johnfercher/maroto/v2/pkg/components/code"
 	"github.com/johnfercher/maroto/v2/pkg/components/col"
+	"github.com/johnfercher/maroto/v2/pkg/components/image"
 	"github.com/johnfercher/maroto/v2/pkg/components/text"
 	"github.com/johnfercher/maroto/v2/pkg/config"
 	"github.com/johnfercher/maroto/v2/pkg/consts/align"
+	"github.com/johnfercher/maroto/v2/pkg/consts/extension"
 	"github.com/johnfercher/maroto/v2/pkg/consts/fontstyle"
 	"github.com/johnfercher/maroto/v2/pkg/consts/orientation"
 	"github.com/johnfercher/maroto/v2/pkg/consts/pagesize"
+	"github.com/johnfercher/maroto/v2/pkg/core"
 	"github.com/johnfercher/maroto/v2/pkg/props"
 	"github.com/secusense/backend/internal/domain"
+	"github.com/secusense/backend/pkg/certsig"
 )
 
 type CertificateGenerator struct {
@@ -26,110 +32,181 @@ func NewCertificateGenerator(verificationBaseURL string) *CertificateGenerator {
 	}
 }
 
-func (g *CertificateGenerator) Generate(cert *domain.Certificate) ([]byte, error) {
-	cfg := config.NewBuilder().
+// Generate renders the certificate in its course's language, customized by
+// the template if there is one. A signed certificate carries its credential
+// in the QR code and in the PDF keywords, where the offline verifier finds
+// it.
+func (g *CertificateGenerator) Generate(cert *domain.Certificate, tmpl *domain.CertificateTemplate) ([]byte, error) {
+	builder := config.NewBuilder().
 		WithOrientation(orientation.Horizontal).
 		WithPageSize(pagesize.A4).
 		WithLeftMargin(20).
-		WithTopMargin(20).
+		WithTopMargin(15).
 		WithRightMargin(20).
-		Build()
+		WithBottomMargin(15)
+
+	credential := ""
+	if cert.Signature != nil {
+		credential = *cert.Signature
+		builder = builder.WithKeywords(certsig.KeywordPrefix+credential, false)
+	}
+	cfg := builder.Build()
 
 	m := maroto.New(cfg)
 
+	language := cert.CourseLanguage
+	if _, ok := builtinTexts[language]; !ok {
+		language = "en"
+	}
+	dateFormat := dateFormats[language]
+	placeholders := strings.NewReplacer(
+		"{score}", strconv.Itoa(cert.Score),
+		"{maxScore}", strconv.Itoa(cert.MaxScore),
+		"{number}", cert.CertificateNumber,
+	)
+	textOf := func(key domain.CertificateTextKey) string {
+		s := tmpl.Text(language, key)
+		if s == "" {
+			s = builtinTexts[language][key]
+		}
+		return placeholders.Replace(s)
+	}
+
+	brand := brandColor(cert.BrandColor)
+	var textColor *props.Color
+	if tmpl != nil {
+		if tmpl.PrimaryColor != nil {
+			brand = brandColor(tmpl.PrimaryColor)
+		}
+		if tmpl.TextColor != nil {
+			textColor = brandColor(tmpl.TextColor)
+		}
+	}
+	grey := &props.Color{Red: 100, Green: 100, Blue: 100}
+
+	// Logo
+	if tmpl != nil && tmpl.Logo != nil {
+		if logo := imageOf(*tmpl.Logo, 100); logo != nil {
+			m.AddRow(16, col.New(4), col.New(4).Add(logo), col.New(4))
+		}
+	}
+
 	// Header
-	m.AddRow(20,
+	m.AddRow(16,
 		col.New(12).Add(
-			text.New("CERTIFICATE OF COMPLETION", props.Text{
-				Top:   5,
+			text.New(textOf(domain.CertificateTextTitle), props.Text{
+				Top:   3,
 				Style: fontstyle.Bold,
 				Size:  28,
 				Align: align.Center,
-				Color: &props.Color{Red: 0, Green: 51, Blue: 102},
+				Color: brand,
 			}),
 		),
 	)
 
-	m.AddRow(10,
+	m.AddRow(8,
 		col.New(12).Add(
-			text.New("SecuSense Training Portal", props.Text{
-				Top:   2,
+			text.New(cert.IssuerName(), props.Text{
+				Top:   1,
 				Style: fontstyle.Normal,
 				Size:  14,
 				Align: align.Center,
-				Color: &props.Color{Red: 100, Green: 100, Blue: 100},
+				Color: grey,
 			}),
 		),
 	)
 
 	// Divider
-	m.AddRow(15)
+	m.AddRow(6)
 
 	// This is to certify
-	m.AddRow(10,
+	m.AddRow(8,
 		col.New(12).Add(
-			text.New("This is to certify that", props.Text{
-				Top:   2,
+			text.New(textOf(domain.CertificateTextCertify), props.Text{
+				Top:   1,
 				Size:  12,
 				Align: align.Center,
+				Color: textColor,
 			}),
 		),
 	)
 
 	// Name
 	holderName := fmt.Sprintf("%s %s", cert.UserFirstName, cert.UserLastName)
-	m.AddRow(15,
+	m.AddRow(14,
 		col.New(12).Add(
 			text.New(holderName, props.Text{
 				Top:   2,
 				Style: fontstyle.Bold,
 				Size:  24,
 				Align: align.Center,
-				Color: &props.Color{Red: 0, Green: 51, Blue: 102},
+				Color: brand,
 			}),
 		),
 	)
 
 	// Has successfully completed
-	m.AddRow(10,
+	m.AddRow(8,
 		col.New(12).Add(
-			text.New("has successfully completed the course", props.Text{
-				Top:   2,
+			text.New(textOf(domain.CertificateTextCompleted), props.Text{
+				Top:   1,
 				Size:  12,
 				Align: align.Center,
+				Color: textColor,
 			}),
 		),
 	)
 
 	// Course title
-	m.AddRow(15,
+	m.AddRow(12,
 		col.New(12).Add(
 			text.New(cert.CourseTitle, props.Text{
 				Top:   2,
 				Style: fontstyle.BoldItalic,
 				Size:  18,
 				Align: align.Center,
+				Color: textColor,
 			}),
 		),
 	)
 
 	// Score
-	scoreText := fmt.Sprintf("with a score of %d out of %d", cert.Score, cert.MaxScore)
-	m.AddRow(10,
+	m.AddRow(8,
 		col.New(12).Add(
-			text.New(scoreText, props.Text{
-				Top:   2,
+			text.New(textOf(domain.CertificateTextScore), props.Text{
+				Top:   1,
 				Size:  12,
 				Align: align.Center,
+				Color: textColor,
 			}),
 		),
 	)
 
+	// Extra fields, such as CPE credit hours
+	if tmpl != nil {
+		for _, field := range tmpl.ExtraFields {
+			m.AddRow(6,
+				col.New(12).Add(
+					text.New(fmt.Sprintf("%s: %s", field.LabelIn(language), field.Value), props.Text{
+						Top:   1,
+						Size:  11,
+						Align: align.Center,
+						Color: textColor,
+					}),
+				),
+			)
+		}
+	}
+
 	// Date
-	m.AddRow(15,
+	dateText := strings.ReplaceAll(textOf(domain.CertificateTextIssued), "{date}", cert.IssuedAt.Format(dateFormat))
+	if cert.ExpiresAt != nil {
+		dateText += strings.ReplaceAll(textOf(domain.CertificateTextValidUntil), "{date}", cert.ExpiresAt.Format(dateFormat))
+	}
+	m.AddRow(10,
 		col.New(12).Add(
-			text.New(fmt.Sprintf("Issued on %s", cert.IssuedAt.Format("January 2, 2006")), props.Text{
-				Top:   5,
+			text.New(dateText, props.Text{
+				Top:   3,
 				Size:  11,
 				Align: align.Center,
 				Color: &props.Color{Red: 80, Green: 80, Blue: 80},
@@ -138,52 +215,79 @@ func (g *CertificateGenerator) Generate(cert *domain.Certificate) ([]byte, error
 	)
 
 	// Certificate number
-	m.AddRow(10,
+	m.AddRow(6,
 		col.New(12).Add(
-			text.New(fmt.Sprintf("Certificate Number: %s", cert.CertificateNumber), props.Text{
-				Top:   2,
+			text.New(textOf(domain.CertificateTextNumber), props.Text{
+				Top:   1,
 				Size:  10,
 				Align: align.Center,
-				Color: &props.Color{Red: 100, Green: 100, Blue: 100},
+				Color: grey,
 			}),
 		),
 	)
 
-	// Verification QR code and link
-	verificationURL := fmt.Sprintf("%s/verify/%s", g.verificationBaseURL, cert.VerificationHash)
-	m.AddRow(35,
-		col.New(4),
+	// Signatures beside the verification QR code, the first on the left
+	var signatures []domain.CertificateSignature
+	if tmpl != nil {
+		signatures = tmpl.Signatures
+	}
+	signatureAt := func(i int) *domain.CertificateSignature {
+		if i < len(signatures) {
+			return &signatures[i]
+		}
+		return nil
+	}
+	left, right := signatureAt(0), signatureAt(1)
+
+	verificationURL := certsig.VerificationURL(g.verificationBaseURL, cert.VerificationHash, "")
+	m.AddRow(28,
+		signatureImageCol(left),
 		col.New(4).Add(
-			code.NewQr(verificationURL, props.Rect{
+			code.NewQr(certsig.VerificationURL(g.verificationBaseURL, cert.VerificationHash, credential), props.Rect{
 				Center:  true,
 				Percent: 100,
 			}),
 		),
-		col.New(4),
+		signatureImageCol(right),
 	)
 
-	m.AddRow(8,
-		col.New(12).Add(
-			text.New("Scan to verify or visit:", props.Text{
+	m.AddRow(10,
+		signatureCaptionCol(left),
+		col.New(4).Add(
+			text.New(textOf(domain.CertificateTextVerify), props.Text{
 				Top:   1,
 				Size:  8,
 				Align: align.Center,
 				Color: &props.Color{Red: 120, Green: 120, Blue: 120},
 			}),
-		),
-	)
-
-	m.AddRow(6,
-		col.New(12).Add(
 			text.New(verificationURL, props.Text{
-				Top:   0,
+				Top:   5,
 				Size:  8,
 				Align: align.Center,
 				Color: &props.Color{Red: 0, Green: 102, Blue: 204},
 			}),
 		),
+		signatureCaptionCol(right),
 	)
 
+	// Template or organization footer
+	footer := textOf(domain.CertificateTextFooter)
+	if footer == "" && cert.CertificateFooter != nil {
+		footer = *cert.CertificateFooter
+	}
+	if footer != "" {
+		m.AddRow(8,
+			col.New(12).Add(
+				text.New(footer, props.Text{
+					Top:   2,
+					Size:  8,
+					Align: align.Center,
+					Color: grey,
+				}),
+			),
+		)
+	}
+
 	// Generate PDF
 	doc, err := m.Generate()
 	if err != nil {
@@ -192,3 +296,66 @@ func (g *CertificateGenerator) Generate(cert *domain.Certificate) ([]byte, error
 
 	return doc.GetBytes(), nil
 }
+
+// signatureImageCol is the column of a signature's image, empty without a
+// signature
+func signatureImageCol(sig *domain.CertificateSignature) core.Col {
+	c := col.New(4)
+	if sig != nil {
+		if img := imageOf(sig.Image, 70); img != nil {
+			c.Add(img)
+		}
+	}
+	return c
+}
+
+// signatureCaptionCol is the column of a signer's name and title
+func signatureCaptionCol(sig *domain.CertificateSignature) core.Col {
+	c := col.New(4)
+	if sig == nil {
+		return c
+	}
+	c.Add(text.New(sig.Name, props.Text{
+		Top:   1,
+		Style: fontstyle.Bold,
+		Size:  10,
+		Align: align.Center,
+	}))
+	if sig.Title != "" {
+		c.Add(text.New(sig.Title, props.Text{
+			Top:   5,
+			Size:  8,
+			Align: align.Center,
+			Color: &props.Color{Red: 100, Green: 100, Blue: 100},
+		}))
+	}
+	return c
+}
+
+// imageOf renders a data URI image scaled to a percentage of its cell, or
+// returns nil if the image doesn't decode
+func imageOf(dataURI string, percent float64) core.Component {
+	data, format, err := domain.DecodeImage(dataURI)
+	if err != nil {
+		return nil
+	}
+	ext := extension.Png
+	if format == "jpg" {
+		ext = extension.Jpg
+	}
+	return image.NewFromBytes(data, ext, props.Rect{
+		Center:  true,
+		Percent: percent,
+	})
+}
+
+// brandColor parses an organization's #rrggbb brand color, falling back to
+// the SecuSense blue
+func brandColor(hex *string) *props.Color {
+	if hex != nil && len(*hex) == 7 && (*hex)[0] == '#' {
+		if rgb, err := strconv.ParseUint((*hex)[1:], 16, 32); err == nil {
+			return &props.Color{Red: int(rgb >> 16), Green: int(rgb >> 8 & 0xff), Blue: int(rgb & 0xff)}
+		}
+	}
+	return &props.Color{Red: 0, Green: 51, Blue: 102}
+}
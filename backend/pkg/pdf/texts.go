@@ -0,0 +1,78 @@
+package pdf
+
+import "github.com/secusense/backend/internal/domain"
+
+// builtinTexts are the texts of certificates in each course language;
+// templates override them
+var builtinTexts = map[string]map[domain.CertificateTextKey]string{
+	"en": {
+		domain.CertificateTextTitle:      "CERTIFICATE OF COMPLETION",
+		domain.CertificateTextCertify:    "This is to certify that",
+		domain.CertificateTextCompleted:  "has successfully completed the course",
+		domain.CertificateTextScore:      "with a score of {score} out of {maxScore}",
+		domain.CertificateTextIssued:     "Issued on {date}",
+		domain.CertificateTextValidUntil: ", valid until {date}",
+		domain.CertificateTextNumber:     "Certificate Number: {number}",
+		domain.CertificateTextVerify:     "Scan to verify or visit:",
+	},
+	"de": {
+		domain.CertificateTextTitle:      "ABSCHLUSSZERTIFIKAT",
+		domain.CertificateTextCertify:    "Hiermit wird bestätigt, dass",
+		domain.CertificateTextCompleted:  "den folgenden Kurs erfolgreich abgeschlossen hat",
+		domain.CertificateTextScore:      "mit {score} von {maxScore} Punkten",
+		domain.CertificateTextIssued:     "Ausgestellt am {date}",
+		domain.CertificateTextValidUntil: ", gültig bis {date}",
+		domain.CertificateTextNumber:     "Zertifikatsnummer: {number}",
+		domain.CertificateTextVerify:     "Zum Prüfen scannen oder aufrufen:",
+	},
+	"fr": {
+		domain.CertificateTextTitle:      "CERTIFICAT DE RÉUSSITE",
+		domain.CertificateTextCertify:    "Nous certifions que",
+		domain.CertificateTextCompleted:  "a suivi avec succès le cours",
+		domain.CertificateTextScore:      "avec un score de {score} sur {maxScore}",
+		domain.CertificateTextIssued:     "Délivré le {date}",
+		domain.CertificateTextValidUntil: ", valable jusqu'au {date}",
+		domain.CertificateTextNumber:     "Numéro de certificat : {number}",
+		domain.CertificateTextVerify:     "Scannez pour vérifier ou consultez :",
+	},
+	"es": {
+		domain.CertificateTextTitle:      "CERTIFICADO DE FINALIZACIÓN",
+		domain.CertificateTextCertify:    "Se certifica que",
+		domain.CertificateTextCompleted:  "ha completado con éxito el curso",
+		domain.CertificateTextScore:      "con una puntuación de {score} sobre {maxScore}",
+		domain.CertificateTextIssued:     "Emitido el {date}",
+		domain.CertificateTextValidUntil: ", válido hasta el {date}",
+		domain.CertificateTextNumber:     "Número de certificado: {number}",
+		domain.CertificateTextVerify:     "Escanee para verificar o visite:",
+	},
+	"it": {
+		domain.CertificateTextTitle:      "ATTESTATO DI COMPLETAMENTO",
+		domain.CertificateTextCertify:    "Si certifica che",
+		domain.CertificateTextCompleted:  "ha completato con successo il corso",
+		domain.CertificateTextScore:      "con un punteggio di {score} su {maxScore}",
+		domain.CertificateTextIssued:     "Rilasciato il {date}",
+		domain.CertificateTextValidUntil: ", valido fino al {date}",
+		domain.CertificateTextNumber:     "Numero del certificato: {number}",
+		domain.CertificateTextVerify:     "Scansiona per verificare o visita:",
+	},
+	"pt": {
+		domain.CertificateTextTitle:      "CERTIFICADO DE CONCLUSÃO",
+		domain.CertificateTextCertify:    "Certificamos que",
+		domain.CertificateTextCompleted:  "concluiu com sucesso o curso",
+		domain.CertificateTextScore:      "com uma pontuação de {score} de {maxScore}",
+		domain.CertificateTextIssued:     "Emitido em {date}",
+		domain.CertificateTextValidUntil: ", válido até {date}",
+		domain.CertificateTextNumber:     "Número do certificado: {number}",
+		domain.CertificateTextVerify:     "Digitalize para verificar ou visite:",
+	},
+}
+
+// dateFormats are the date layouts of each language
+var dateFormats = map[string]string{
+	"en": "January 2, 2006",
+	"de": "02.01.2006",
+	"fr": "02/01/2006",
+	"es": "02/01/2006",
+	"it": "02/01/2006",
+	"pt": "02/01/2006",
+}
@@ -0,0 +1,549 @@
+package qti
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/secusense/backend/internal/domain"
+)
+
+// Import limits, so a crafted package can't exhaust memory
+const (
+	maxItemSize    = 2 << 20
+	maxPackageSize = 32 << 20
+	maxItems       = 1000
+)
+
+// Decode reads a content package, or a single assessment item XML file
+func (c *Codec) Decode(data []byte) ([]*domain.Question, []domain.InterchangeIssue, error) {
+	type source struct {
+		name string
+		data []byte
+	}
+	var sources []source
+
+	if bytes.HasPrefix(data, []byte("PK")) {
+		files, err := packageItems(data)
+		if err != nil {
+			return nil, nil, err
+		}
+		total := 0
+		for _, f := range files {
+			content, err := readFile(f)
+			if err != nil {
+				return nil, nil, fmt.Errorf("%s: %v", f.Name, err)
+			}
+			if total += len(content); total > maxPackageSize {
+				return nil, nil, errors.New("the package is too large")
+			}
+			sources = append(sources, source{name: f.Name, data: content})
+		}
+	} else {
+		sources = append(sources, source{name: "item", data: data})
+	}
+
+	var questions []*domain.Question
+	var issues []domain.InterchangeIssue
+	for _, src := range sources {
+		root, err := parseTree(bytes.NewReader(src.data))
+		if err != nil || root.name != "assessmentItem" {
+			if len(sources) == 1 {
+				return nil, nil, errors.New("not a QTI 2.1 assessment item or content package")
+			}
+			issues = append(issues, domain.InterchangeIssue{Item: src.name, Reason: "not a QTI 2.1 assessment item", Skipped: true})
+			continue
+		}
+
+		q, err := decodeItem(root)
+		if err != nil {
+			item := root.attrs["identifier"]
+			if item == "" {
+				item = src.name
+			}
+			issues = append(issues, domain.InterchangeIssue{
+				Item:    item,
+				Title:   root.attrs["title"],
+				Reason:  err.Error(),
+				Skipped: true,
+			})
+			continue
+		}
+		questions = append(questions, q)
+	}
+	return questions, issues, nil
+}
+
+// packageItems returns the item files of a content package: the resources
+// its manifest lists as QTI 2.1 items, or every XML file if it has none
+func packageItems(data []byte) ([]*zip.File, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, errors.New("unreadable zip archive")
+	}
+
+	byName := make(map[string]*zip.File, len(archive.File))
+	var manifest *zip.File
+	for _, f := range archive.File {
+		byName[f.Name] = f
+		if f.Name == "imsmanifest.xml" {
+			manifest = f
+		}
+	}
+
+	var items []*zip.File
+	if manifest != nil {
+		content, err := readFile(manifest)
+		if err != nil {
+			return nil, err
+		}
+		root, err := parseTree(bytes.NewReader(content))
+		if err != nil {
+			return nil, errors.New("unreadable imsmanifest.xml")
+		}
+		for _, resource := range root.find("resource") {
+			if !strings.HasPrefix(resource.attrs["type"], itemType) {
+				continue
+			}
+			if f, ok := byName[path.Clean(resource.attrs["href"])]; ok {
+				items = append(items, f)
+			}
+		}
+	} else {
+		for _, f := range archive.File {
+			if strings.HasSuffix(strings.ToLower(f.Name), ".xml") {
+				items = append(items, f)
+			}
+		}
+	}
+
+	if len(items) == 0 {
+		return nil, errors.New("the package contains no QTI 2.1 items")
+	}
+	if len(items) > maxItems {
+		return nil, fmt.Errorf("the package has more than %d items", maxItems)
+	}
+	return items, nil
+}
+
+func readFile(f *zip.File) ([]byte, error) {
+	r, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	content, err := io.ReadAll(io.LimitReader(r, maxItemSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(content) > maxItemSize {
+		return nil, errors.New("file too large")
+	}
+	return content, nil
+}
+
+// response is a response declaration: the correct values and, for text
+// entries, the mapped values
+type response struct {
+	correct []string
+	mapping []mapEntry
+}
+
+type mapEntry struct {
+	key           string
+	value         float64
+	caseSensitive bool
+}
+
+// interactions this package maps; others make the item unmappable
+var supportedInteractions = map[string]bool{
+	"choiceInteraction": true, "orderInteraction": true, "matchInteraction": true,
+	"gapMatchInteraction": true, "textEntryInteraction": true,
+}
+
+func decodeItem(item *node) (*domain.Question, error) {
+	responses := make(map[string]*response)
+	for _, decl := range item.childrenNamed("responseDeclaration") {
+		r := &response{}
+		if correct := decl.child("correctResponse"); correct != nil {
+			for _, v := range correct.childrenNamed("value") {
+				r.correct = append(r.correct, strings.TrimSpace(v.textContent(nil)))
+			}
+		}
+		if mapping := decl.child("mapping"); mapping != nil {
+			for _, e := range mapping.childrenNamed("mapEntry") {
+				value, _ := strconv.ParseFloat(e.attrs["mappedValue"], 64)
+				r.mapping = append(r.mapping, mapEntry{key: e.attrs["mapKey"], value: value, caseSensitive: e.attrs["caseSensitive"] == "true"})
+			}
+		}
+		responses[decl.attrs["identifier"]] = r
+	}
+
+	body := item.child("itemBody")
+	if body == nil {
+		return nil, errors.New("item has no body")
+	}
+
+	var interactions []*node
+	var walk func(*node)
+	walk = func(n *node) {
+		for _, c := range n.children {
+			if strings.HasSuffix(c.name, "Interaction") {
+				interactions = append(interactions, c)
+				continue
+			}
+			walk(c)
+		}
+	}
+	walk(body)
+
+	if len(interactions) == 0 {
+		return nil, errors.New("item has no interaction")
+	}
+	kind := interactions[0].name
+	for _, in := range interactions {
+		if !supportedInteractions[in.name] {
+			return nil, fmt.Errorf("%s is not supported", in.name)
+		}
+		if in.name != kind || (kind != "textEntryInteraction" && len(interactions) > 1) {
+			return nil, errors.New("items with several interactions can't be mapped")
+		}
+	}
+
+	explanation := ""
+	if feedback := item.child("modalFeedback"); feedback != nil {
+		explanation = feedback.textContent(nil)
+	}
+
+	var q *domain.Question
+	var err error
+	if kind == "textEntryInteraction" {
+		q, err = decodeTextEntry(body, responses, explanation)
+	} else {
+		interaction := interactions[0]
+		// The question is the text around the interaction and its prompt
+		text := body.textContent(func(n *node) bool { return n == interaction })
+		if prompt := interaction.child("prompt"); prompt != nil {
+			text = cleanText(text + "\n" + prompt.textContent(nil))
+		}
+		if text == "" {
+			text = item.attrs["title"]
+		}
+
+		r := responses[interaction.attrs["responseIdentifier"]]
+		if r == nil {
+			return nil, errors.New("interaction has no response declaration")
+		}
+		switch kind {
+		case "choiceInteraction":
+			q, err = decodeChoice(interaction, r, text, explanation)
+		case "orderInteraction":
+			q, err = decodeOrder(interaction, r, text, explanation)
+		case "matchInteraction":
+			q, err = decodeMatch(interaction, r, text, explanation)
+		case "gapMatchInteraction":
+			q, err = decodeGapMatch(interaction, r, text, explanation)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if outcome := scoreDeclaration(item); outcome != nil {
+		if max, err := strconv.ParseFloat(outcome.attrs["normalMaximum"], 64); err == nil && max >= 1 {
+			q.Points = int(math.Round(max))
+		}
+	}
+	return q, nil
+}
+
+func scoreDeclaration(item *node) *node {
+	for _, o := range item.childrenNamed("outcomeDeclaration") {
+		if o.attrs["identifier"] == "SCORE" {
+			return o
+		}
+	}
+	return nil
+}
+
+// correctValues returns the correct values of a response, falling back to
+// the positively mapped keys
+func correctValues(r *response) []string {
+	if len(r.correct) > 0 {
+		return r.correct
+	}
+	var values []string
+	for _, e := range r.mapping {
+		if e.value > 0 {
+			values = append(values, e.key)
+		}
+	}
+	return values
+}
+
+func decodeChoice(interaction *node, r *response, text, explanation string) (*domain.Question, error) {
+	choices := interaction.childrenNamed("simpleChoice")
+	if len(choices) == 0 {
+		return nil, errors.New("choice interaction has no choices")
+	}
+	correct := make(map[string]bool)
+	for _, v := range correctValues(r) {
+		correct[v] = true
+	}
+	if len(correct) == 0 {
+		return nil, errors.New("choice interaction has no correct response")
+	}
+
+	// A True/False pair is our true/false question
+	if len(choices) == 2 && len(correct) == 1 {
+		first, second := strings.ToLower(choices[0].textContent(nil)), strings.ToLower(choices[1].textContent(nil))
+		if (first == "true" && second == "false") || (first == "false" && second == "true") {
+			answer := strings.ToLower(choices[0].textContent(nil)) == "true"
+			if !correct[choices[0].attrs["identifier"]] {
+				answer = !answer
+			}
+			return newQuestion(domain.QuestionTypeTrueFalse, text, domain.TrueFalseData{CorrectAnswer: answer, Explanation: explanation})
+		}
+	}
+
+	data := domain.MultipleChoiceData{Explanation: explanation}
+	for i, choice := range choices {
+		data.Options = append(data.Options, choice.textContent(nil))
+		if correct[choice.attrs["identifier"]] {
+			data.CorrectIndices = append(data.CorrectIndices, i)
+		}
+	}
+	if len(data.CorrectIndices) == 0 {
+		return nil, errors.New("the correct response names no choice")
+	}
+	return newQuestion(domain.QuestionTypeMultipleChoice, text, data)
+}
+
+func decodeOrder(interaction *node, r *response, text, explanation string) (*domain.Question, error) {
+	choices := interaction.childrenNamed("simpleChoice")
+	index := make(map[string]int, len(choices))
+	data := domain.OrderingData{Explanation: explanation}
+	for i, choice := range choices {
+		index[choice.attrs["identifier"]] = i
+		data.Items = append(data.Items, choice.textContent(nil))
+	}
+	for _, v := range r.correct {
+		i, ok := index[v]
+		if !ok {
+			return nil, errors.New("the correct order names an unknown choice")
+		}
+		data.CorrectOrder = append(data.CorrectOrder, i)
+	}
+	if len(data.Items) == 0 || len(data.CorrectOrder) != len(data.Items) {
+		return nil, errors.New("the correct order must list every choice")
+	}
+	return newQuestion(domain.QuestionTypeOrdering, text, data)
+}
+
+func decodeMatch(interaction *node, r *response, text, explanation string) (*domain.Question, error) {
+	sets := interaction.childrenNamed("simpleMatchSet")
+	if len(sets) != 2 {
+		return nil, errors.New("match interaction needs two sets")
+	}
+	left, right := associableChoices(sets[0]), associableChoices(sets[1])
+	data := domain.MatchingData{CorrectPairs: make(map[string]string), Explanation: explanation}
+	for _, c := range sets[0].childrenNamed("simpleAssociableChoice") {
+		data.LeftItems = append(data.LeftItems, c.textContent(nil))
+	}
+	for _, c := range sets[1].childrenNamed("simpleAssociableChoice") {
+		data.RightItems = append(data.RightItems, c.textContent(nil))
+	}
+	if len(left) != len(data.LeftItems) {
+		return nil, errors.New("match interaction lists an item twice")
+	}
+
+	for _, pair := range correctValues(r) {
+		fields := strings.Fields(pair)
+		if len(fields) != 2 {
+			return nil, errors.New("invalid correct pair")
+		}
+		l, lok := left[fields[0]]
+		rt, rok := right[fields[1]]
+		if !lok || !rok {
+			// Pairs may be given either way round
+			l, lok = left[fields[1]]
+			rt, rok = right[fields[0]]
+		}
+		if !lok || !rok {
+			return nil, errors.New("a correct pair names an unknown choice")
+		}
+		if _, dup := data.CorrectPairs[l]; dup {
+			return nil, errors.New("an item has several correct matches")
+		}
+		data.CorrectPairs[l] = rt
+	}
+	if len(data.CorrectPairs) == 0 {
+		return nil, errors.New("match interaction has no correct pairs")
+	}
+	return newQuestion(domain.QuestionTypeMatching, text, data)
+}
+
+func associableChoices(set *node) map[string]string {
+	choices := make(map[string]string)
+	seen := make(map[string]bool)
+	for _, c := range set.childrenNamed("simpleAssociableChoice") {
+		text := c.textContent(nil)
+		if seen[text] {
+			continue
+		}
+		seen[text] = true
+		choices[c.attrs["identifier"]] = text
+	}
+	return choices
+}
+
+// decodeGapMatch maps the gap texts to drag and drop items and the gaps to
+// drop zones, labelled with the text that leads up to each gap
+func decodeGapMatch(interaction *node, r *response, text, explanation string) (*domain.Question, error) {
+	if len(interaction.childrenNamed("gapImg")) > 0 {
+		return nil, errors.New("image gaps are not supported")
+	}
+	items := make(map[string]string)
+	data := domain.DragDropData{CorrectMapping: make(map[string]string), Explanation: explanation}
+	for _, g := range interaction.childrenNamed("gapText") {
+		label := g.textContent(nil)
+		items[g.attrs["identifier"]] = label
+		data.Items = append(data.Items, label)
+	}
+
+	zones := make(map[string]string)
+	var label strings.Builder
+	var walk func(*node)
+	walk = func(n *node) {
+		for _, c := range n.children {
+			switch {
+			case c.name == "":
+				label.WriteString(c.text)
+			case c.name == "gap":
+				zone := cleanText(label.String())
+				if zone == "" {
+					zone = fmt.Sprintf("Gap %d", len(zones)+1)
+				}
+				zones[c.attrs["identifier"]] = zone
+				data.DropZones = append(data.DropZones, zone)
+				label.Reset()
+			case c.name != "prompt" && c.name != "gapText":
+				walk(c)
+			}
+		}
+	}
+	walk(interaction)
+
+	for _, pair := range correctValues(r) {
+		fields := strings.Fields(pair)
+		if len(fields) != 2 {
+			return nil, errors.New("invalid correct pair")
+		}
+		item, iok := items[fields[0]]
+		zone, zok := zones[fields[1]]
+		if !iok || !zok {
+			return nil, errors.New("a correct pair names an unknown gap or text")
+		}
+		data.CorrectMapping[item] = zone
+	}
+	if len(data.Items) == 0 || len(data.DropZones) == 0 || len(data.CorrectMapping) == 0 {
+		return nil, errors.New("gap match interaction has no texts, gaps or correct pairs")
+	}
+	return newQuestion(domain.QuestionTypeDragDrop, text, data)
+}
+
+// decodeTextEntry maps text entries to the blanks of a fill-in-the-blank
+// question. The blocks holding them form the template; the rest of the body
+// is the question.
+func decodeTextEntry(body *node, responses map[string]*response, explanation string) (*domain.Question, error) {
+	hasEntry := func(n *node) bool { return len(n.find("textEntryInteraction")) > 0 || n.name == "textEntryInteraction" }
+
+	data := domain.FillBlankData{Explanation: explanation}
+	var template strings.Builder
+	var write func(*node) error
+	write = func(n *node) error {
+		if n.name == "" {
+			template.WriteString(n.text)
+			return nil
+		}
+		if n.name != "textEntryInteraction" {
+			for _, c := range n.children {
+				if err := write(c); err != nil {
+					return err
+				}
+			}
+			if blockElements[n.name] {
+				template.WriteString("\n")
+			}
+			return nil
+		}
+
+		r := responses[n.attrs["responseIdentifier"]]
+		if r == nil {
+			return errors.New("text entry has no response declaration")
+		}
+		accepted := correctValues(r)
+		if len(accepted) == 0 {
+			return errors.New("text entry has no correct response")
+		}
+		alternatives := []string{}
+		for _, e := range r.mapping {
+			if e.value > 0 && e.key != accepted[0] {
+				alternatives = append(alternatives, e.key)
+			}
+			data.CaseSensitive = data.CaseSensitive || e.caseSensitive
+		}
+		alternatives = append(alternatives, accepted[1:]...)
+		data.Blanks = append(data.Blanks, accepted[0])
+		data.Alternatives = append(data.Alternatives, alternatives)
+		template.WriteString("{{blank}}")
+		return nil
+	}
+
+	var question []string
+	for _, c := range body.children {
+		if c.name != "" && hasEntry(c) {
+			if err := write(c); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if c.name == "" {
+			question = append(question, c.text)
+		} else {
+			question = append(question, (&node{children: []*node{c}}).textContent(nil))
+		}
+	}
+
+	data.Template = cleanText(template.String())
+	text := cleanText(strings.Join(question, "\n"))
+	if text == "" {
+		text = "Fill in the blank"
+	}
+
+	// Drop the alternatives if no blank has any
+	hasAlternatives := false
+	for _, alts := range data.Alternatives {
+		hasAlternatives = hasAlternatives || len(alts) > 0
+	}
+	if !hasAlternatives {
+		data.Alternatives = nil
+	}
+	return newQuestion(domain.QuestionTypeFillBlank, text, data)
+}
+
+func newQuestion(qType domain.QuestionType, text string, data any) (*domain.Question, error) {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.Question{QuestionType: qType, QuestionText: text, QuestionData: encoded}, nil
+}
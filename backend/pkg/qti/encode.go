@@ -0,0 +1,359 @@
+package qti
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/secusense/backend/internal/domain"
+)
+
+// Encode writes a content package with one assessment item per question and
+// a manifest listing them. Questions that have no QTI interaction are left
+// out and listed in a comment in the manifest.
+func (c *Codec) Encode(title string, questions []*domain.Question) ([]byte, []domain.InterchangeIssue, error) {
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	var issues []domain.InterchangeIssue
+	var hrefs []string
+
+	for _, q := range questions {
+		item, issue := encodeItem(q)
+		if issue != nil {
+			issue.Item = q.ID.String()
+			issue.Title = q.QuestionText
+			issues = append(issues, *issue)
+		}
+		if item == "" {
+			continue
+		}
+
+		href := "items/" + itemIdentifier(q) + ".xml"
+		w, err := archive.Create(href)
+		if err != nil {
+			return nil, nil, err
+		}
+		if _, err := w.Write([]byte(item)); err != nil {
+			return nil, nil, err
+		}
+		hrefs = append(hrefs, href)
+	}
+
+	w, err := archive.Create("imsmanifest.xml")
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, err := w.Write([]byte(manifest(title, hrefs, issues))); err != nil {
+		return nil, nil, err
+	}
+	if err := archive.Close(); err != nil {
+		return nil, nil, err
+	}
+	return buf.Bytes(), issues, nil
+}
+
+func manifest(title string, hrefs []string, issues []domain.InterchangeIssue) string {
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	fmt.Fprintf(&b, "<!-- %s -->\n", comment(title))
+	for _, issue := range issues {
+		if issue.Skipped {
+			fmt.Fprintf(&b, "<!-- Not exported: %s -->\n", comment(issue.Title+" ("+issue.Reason+")"))
+		}
+	}
+	b.WriteString(`<manifest xmlns="http://www.imsglobal.org/xsd/imscp_v1p1" identifier="MANIFEST-1">` + "\n")
+	fmt.Fprintf(&b, "  <metadata><schema>QTIv2.1 Package</schema><schemaversion>1.0.0</schemaversion></metadata>\n")
+	fmt.Fprintf(&b, "  <organizations/>\n  <resources>\n")
+	for _, href := range hrefs {
+		id := strings.TrimSuffix(strings.TrimPrefix(href, "items/"), ".xml")
+		fmt.Fprintf(&b, "    <resource identifier=\"RES-%s\" type=%q href=%q>\n", id, itemType, href)
+		fmt.Fprintf(&b, "      <file href=%q/>\n    </resource>\n", href)
+	}
+	b.WriteString("  </resources>\n</manifest>\n")
+	return b.String()
+}
+
+// comment makes text safe to put in an XML comment, where "--" may not appear
+func comment(text string) string {
+	return strings.ReplaceAll(oneLine(text), "--", "- -")
+}
+
+func itemIdentifier(q *domain.Question) string {
+	return "Q-" + q.ID.String()
+}
+
+// itemWriter builds the XML of one assessment item
+type itemWriter struct {
+	declarations strings.Builder
+	body         strings.Builder
+	processing   string
+}
+
+func (w *itemWriter) correct(identifier, cardinality, baseType string, values []string) {
+	fmt.Fprintf(&w.declarations, "  <responseDeclaration identifier=%q cardinality=%q baseType=%q>\n", identifier, cardinality, baseType)
+	w.declarations.WriteString("    <correctResponse>\n")
+	for _, v := range values {
+		fmt.Fprintf(&w.declarations, "      <value>%s</value>\n", esc(v))
+	}
+	w.declarations.WriteString("    </correctResponse>\n  </responseDeclaration>\n")
+}
+
+func (w *itemWriter) prompt(text string) {
+	fmt.Fprintf(&w.body, "      <prompt>%s</prompt>\n", esc(text))
+}
+
+func (w *itemWriter) choice(tag, identifier, text string, extra string) {
+	fmt.Fprintf(&w.body, "      <%s identifier=%q%s>%s</%s>\n", tag, identifier, extra, esc(text), tag)
+}
+
+func (w *itemWriter) item(q *domain.Question, explanation string) string {
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	fmt.Fprintf(&b, `<assessmentItem xmlns=%q xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:schemaLocation="%s %s"`,
+		qtiNamespace, qtiNamespace, qtiSchema)
+	fmt.Fprintf(&b, " identifier=%q title=\"%s\" adaptive=\"false\" timeDependent=\"false\">\n", itemIdentifier(q), esc(oneLine(q.QuestionText)))
+	b.WriteString(w.declarations.String())
+	fmt.Fprintf(&b, "  <outcomeDeclaration identifier=\"SCORE\" cardinality=\"single\" baseType=\"float\" normalMaximum=\"%d\">\n", q.Points)
+	b.WriteString("    <defaultValue><value>0</value></defaultValue>\n  </outcomeDeclaration>\n")
+	if explanation != "" {
+		b.WriteString("  <outcomeDeclaration identifier=\"FEEDBACK\" cardinality=\"single\" baseType=\"identifier\"/>\n")
+	}
+	b.WriteString("  <itemBody>\n")
+	b.WriteString(w.body.String())
+	b.WriteString("  </itemBody>\n")
+	b.WriteString("  <responseProcessing>\n")
+	b.WriteString(w.processing)
+	if explanation != "" {
+		fmt.Fprintf(&b, "    <setOutcomeValue identifier=\"FEEDBACK\"><baseValue baseType=\"identifier\">%s</baseValue></setOutcomeValue>\n", explanationID)
+	}
+	b.WriteString("  </responseProcessing>\n")
+	if explanation != "" {
+		fmt.Fprintf(&b, "  <modalFeedback outcomeIdentifier=\"FEEDBACK\" identifier=%q showHide=\"show\">%s</modalFeedback>\n", explanationID, esc(explanation))
+	}
+	b.WriteString("</assessmentItem>\n")
+	return b.String()
+}
+
+// matchCorrect awards the question's points when the response is exactly
+// the correct one, like the match_correct template
+func matchCorrect(points int) string {
+	return fmt.Sprintf(`    <responseCondition>
+      <responseIf>
+        <match><variable identifier="RESPONSE"/><correct identifier="RESPONSE"/></match>
+        <setOutcomeValue identifier="SCORE"><baseValue baseType="float">%d</baseValue></setOutcomeValue>
+      </responseIf>
+      <responseElse>
+        <setOutcomeValue identifier="SCORE"><baseValue baseType="float">0</baseValue></setOutcomeValue>
+      </responseElse>
+    </responseCondition>
+`, points)
+}
+
+// encodeItem returns the assessment item of a question, or an empty item and
+// the reason if it has none. A non-skipping issue describes what was lost.
+func encodeItem(q *domain.Question) (string, *domain.InterchangeIssue) {
+	skip := func(reason string) (string, *domain.InterchangeIssue) {
+		return "", &domain.InterchangeIssue{Reason: reason, Skipped: true}
+	}
+	invalid := func() (string, *domain.InterchangeIssue) {
+		return skip("the question data could not be read")
+	}
+	w := &itemWriter{processing: matchCorrect(q.Points)}
+
+	switch q.QuestionType {
+	case domain.QuestionTypeMultipleChoice:
+		var data domain.MultipleChoiceData
+		if err := json.Unmarshal(q.QuestionData, &data); err != nil || len(data.Options) == 0 {
+			return invalid()
+		}
+		cardinality, maxChoices := "single", 1
+		if len(data.CorrectIndices) > 1 {
+			cardinality, maxChoices = "multiple", 0
+		}
+		var values []string
+		for _, i := range data.CorrectIndices {
+			values = append(values, choiceID("C", i))
+		}
+		w.correct("RESPONSE", cardinality, "identifier", values)
+		fmt.Fprintf(&w.body, "    <choiceInteraction responseIdentifier=\"RESPONSE\" shuffle=\"false\" maxChoices=\"%d\">\n", maxChoices)
+		w.prompt(q.QuestionText)
+		for i, option := range data.Options {
+			w.choice("simpleChoice", choiceID("C", i), option, "")
+		}
+		w.body.WriteString("    </choiceInteraction>\n")
+		return w.item(q, data.Explanation), nil
+
+	case domain.QuestionTypeTrueFalse:
+		var data domain.TrueFalseData
+		if err := json.Unmarshal(q.QuestionData, &data); err != nil {
+			return invalid()
+		}
+		correct := "FALSE"
+		if data.CorrectAnswer {
+			correct = "TRUE"
+		}
+		w.correct("RESPONSE", "single", "identifier", []string{correct})
+		w.body.WriteString("    <choiceInteraction responseIdentifier=\"RESPONSE\" shuffle=\"false\" maxChoices=\"1\">\n")
+		w.prompt(q.QuestionText)
+		w.choice("simpleChoice", "TRUE", "True", "")
+		w.choice("simpleChoice", "FALSE", "False", "")
+		w.body.WriteString("    </choiceInteraction>\n")
+		return w.item(q, data.Explanation), nil
+
+	case domain.QuestionTypeOrdering:
+		var data domain.OrderingData
+		if err := json.Unmarshal(q.QuestionData, &data); err != nil || len(data.Items) == 0 {
+			return invalid()
+		}
+		var values []string
+		for _, i := range data.CorrectOrder {
+			values = append(values, choiceID("C", i))
+		}
+		w.correct("RESPONSE", "ordered", "identifier", values)
+		w.body.WriteString("    <orderInteraction responseIdentifier=\"RESPONSE\" shuffle=\"true\">\n")
+		w.prompt(q.QuestionText)
+		for i, item := range data.Items {
+			w.choice("simpleChoice", choiceID("C", i), item, "")
+		}
+		w.body.WriteString("    </orderInteraction>\n")
+		return w.item(q, data.Explanation), nil
+
+	case domain.QuestionTypeMatching:
+		var data domain.MatchingData
+		if err := json.Unmarshal(q.QuestionData, &data); err != nil || len(data.CorrectPairs) == 0 {
+			return invalid()
+		}
+		rightIDs := make(map[string]string, len(data.RightItems))
+		for i, right := range data.RightItems {
+			rightIDs[right] = choiceID("R", i)
+		}
+		var values []string
+		for i, left := range data.LeftItems {
+			if right, ok := data.CorrectPairs[left]; ok {
+				values = append(values, choiceID("L", i)+" "+rightIDs[right])
+			}
+		}
+		w.correct("RESPONSE", "multiple", "directedPair", values)
+		fmt.Fprintf(&w.body, "    <matchInteraction responseIdentifier=\"RESPONSE\" shuffle=\"true\" maxAssociations=\"%d\">\n", len(data.LeftItems))
+		w.prompt(q.QuestionText)
+		w.body.WriteString("      <simpleMatchSet>\n")
+		for i, left := range data.LeftItems {
+			w.choice("simpleAssociableChoice", choiceID("L", i), left, ` matchMax="1"`)
+		}
+		w.body.WriteString("      </simpleMatchSet>\n      <simpleMatchSet>\n")
+		for i, right := range data.RightItems {
+			w.choice("simpleAssociableChoice", choiceID("R", i), right, fmt.Sprintf(` matchMax="%d"`, len(data.LeftItems)))
+		}
+		w.body.WriteString("      </simpleMatchSet>\n    </matchInteraction>\n")
+		return w.item(q, data.Explanation), nil
+
+	case domain.QuestionTypeDragDrop:
+		var data domain.DragDropData
+		if err := json.Unmarshal(q.QuestionData, &data); err != nil || len(data.Items) == 0 || len(data.DropZones) == 0 {
+			return invalid()
+		}
+		zoneIDs := make(map[string]string, len(data.DropZones))
+		for i, zone := range data.DropZones {
+			zoneIDs[zone] = choiceID("G", i)
+		}
+		var values []string
+		for i, item := range data.Items {
+			if zone, ok := data.CorrectMapping[item]; ok {
+				values = append(values, choiceID("I", i)+" "+zoneIDs[zone])
+			}
+		}
+		w.correct("RESPONSE", "multiple", "directedPair", values)
+		w.body.WriteString("    <gapMatchInteraction responseIdentifier=\"RESPONSE\" shuffle=\"true\">\n")
+		w.prompt(q.QuestionText)
+		for i, item := range data.Items {
+			w.choice("gapText", choiceID("I", i), item, ` matchMax="1"`)
+		}
+		// Each drop zone is a labelled gap
+		for i, zone := range data.DropZones {
+			fmt.Fprintf(&w.body, "      <p>%s <gap identifier=%q/></p>\n", esc(zone), choiceID("G", i))
+		}
+		w.body.WriteString("    </gapMatchInteraction>\n")
+		return w.item(q, data.Explanation), nil
+
+	case domain.QuestionTypeFillBlank:
+		return encodeFillBlank(q, w)
+	}
+
+	return skip("QTI export has no interaction for " + strings.ReplaceAll(string(q.QuestionType), "_", " ") + " questions")
+}
+
+// encodeFillBlank writes a text entry per blank. Each blank is worth an equal
+// share of the points; its answer and alternatives are mapped to that share.
+func encodeFillBlank(q *domain.Question, w *itemWriter) (string, *domain.InterchangeIssue) {
+	var data domain.FillBlankData
+	if err := json.Unmarshal(q.QuestionData, &data); err != nil || len(data.Blanks) == 0 {
+		return "", &domain.InterchangeIssue{Reason: "the question data could not be read", Skipped: true}
+	}
+	parts := strings.Split(data.Template, "{{blank}}")
+	if len(parts)-1 != len(data.Blanks) {
+		return "", &domain.InterchangeIssue{Reason: "the template's blanks don't match its answers", Skipped: true}
+	}
+
+	share := strconv.FormatFloat(float64(q.Points)/float64(len(data.Blanks)), 'f', -1, 64)
+	var sum strings.Builder
+	for i, blank := range data.Blanks {
+		id := fmt.Sprintf("RESPONSE_%d", i+1)
+		fmt.Fprintf(&w.declarations, "  <responseDeclaration identifier=%q cardinality=\"single\" baseType=\"string\">\n", id)
+		fmt.Fprintf(&w.declarations, "    <correctResponse><value>%s</value></correctResponse>\n", esc(blank))
+		w.declarations.WriteString("    <mapping defaultValue=\"0\">\n")
+		accepted := []string{blank}
+		if i < len(data.Alternatives) {
+			accepted = append(accepted, data.Alternatives[i]...)
+		}
+		for _, a := range accepted {
+			fmt.Fprintf(&w.declarations, "      <mapEntry mapKey=\"%s\" mappedValue=%q caseSensitive=\"%t\"/>\n", esc(a), share, data.CaseSensitive)
+		}
+		w.declarations.WriteString("    </mapping>\n  </responseDeclaration>\n")
+		fmt.Fprintf(&sum, "<mapResponse identifier=%q/>", id)
+	}
+	w.processing = fmt.Sprintf("    <setOutcomeValue identifier=\"SCORE\"><sum>%s</sum></setOutcomeValue>\n", sum.String())
+
+	fmt.Fprintf(&w.body, "    <p>%s</p>\n    <p>", esc(q.QuestionText))
+	for i, part := range parts {
+		w.body.WriteString(esc(part))
+		if i < len(data.Blanks) {
+			fmt.Fprintf(&w.body, "<textEntryInteraction responseIdentifier=\"RESPONSE_%d\" expectedLength=\"%d\"/>", i+1, expectedLength)
+		}
+	}
+	w.body.WriteString("</p>\n")
+
+	item := w.item(q, data.Explanation)
+	var lost []string
+	if len(data.Patterns) > 0 {
+		lost = append(lost, "answer patterns")
+	}
+	if data.MaxEdits > 0 {
+		lost = append(lost, "typo tolerance")
+	}
+	if len(lost) > 0 {
+		return item, &domain.InterchangeIssue{Reason: "exported without its " + strings.Join(lost, ", ")}
+	}
+	return item, nil
+}
+
+func choiceID(prefix string, i int) string {
+	return prefix + strconv.Itoa(i+1)
+}
+
+func esc(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+func oneLine(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if r := []rune(s); len(r) > 80 {
+		return string(r[:77]) + "..."
+	}
+	return s
+}
@@ -0,0 +1,25 @@
+// Package qti reads and writes questions as IMS QTI 2.1 assessment items
+package qti
+
+const (
+	qtiNamespace   = "http://www.imsglobal.org/xsd/imsqti_v2p1"
+	qtiSchema      = "http://www.imsglobal.org/xsd/qti/qtiv2p1/imsqti_v2p1.xsd"
+	itemType       = "imsqti_item_xmlv2p1"
+	explanationID  = "EXPLANATION"
+	expectedLength = 15
+)
+
+// Codec maps questions to and from QTI 2.1. Multiple choice and true/false
+// questions become choice interactions, ordering questions order
+// interactions, matching questions match interactions, drag and drop
+// questions gap match interactions, and fill-in-the-blank questions text
+// entry interactions.
+type Codec struct{}
+
+func NewCodec() *Codec {
+	return &Codec{}
+}
+
+func (c *Codec) ContentType() string { return "application/zip" }
+
+func (c *Codec) Extension() string { return ".qti.zip" }
@@ -0,0 +1,137 @@
+package qti
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+)
+
+// node is an element or, with an empty name, a run of text. QTI item bodies
+// mix text and markup, so the order of children matters and encoding/xml's
+// struct mapping can't be used.
+type node struct {
+	name     string
+	attrs    map[string]string
+	children []*node
+	text     string
+}
+
+func parseTree(r io.Reader) (*node, error) {
+	decoder := xml.NewDecoder(r)
+	var stack []*node
+	var root *node
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			n := &node{name: t.Name.Local, attrs: make(map[string]string, len(t.Attr))}
+			for _, a := range t.Attr {
+				n.attrs[a.Name.Local] = a.Value
+			}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, n)
+			} else if root == nil {
+				root = n
+			}
+			stack = append(stack, n)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, &node{text: string(t)})
+			}
+		}
+	}
+	if root == nil {
+		return nil, errors.New("empty XML document")
+	}
+	return root, nil
+}
+
+func (n *node) child(name string) *node {
+	for _, c := range n.children {
+		if c.name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+func (n *node) childrenNamed(name string) []*node {
+	var found []*node
+	for _, c := range n.children {
+		if c.name == name {
+			found = append(found, c)
+		}
+	}
+	return found
+}
+
+// find returns the descendants with the given name, in document order
+func (n *node) find(name string) []*node {
+	var found []*node
+	for _, c := range n.children {
+		if c.name == name {
+			found = append(found, c)
+		}
+		found = append(found, c.find(name)...)
+	}
+	return found
+}
+
+// blockElements start a new line of text
+var blockElements = map[string]bool{
+	"p": true, "div": true, "br": true, "li": true, "ul": true, "ol": true, "table": true, "tr": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true, "blockquote": true, "pre": true,
+	"prompt": true,
+}
+
+// textContent flattens the markup below n to plain text, one line per block.
+// Elements for which skip returns true are left out.
+func (n *node) textContent(skip func(*node) bool) string {
+	var b strings.Builder
+	var walk func(*node)
+	walk = func(n *node) {
+		if n.name == "" {
+			b.WriteString(n.text)
+			return
+		}
+		if skip != nil && skip(n) {
+			return
+		}
+		if blockElements[n.name] {
+			b.WriteString("\n")
+		}
+		for _, c := range n.children {
+			walk(c)
+		}
+		if blockElements[n.name] {
+			b.WriteString("\n")
+		}
+	}
+	for _, c := range n.children {
+		walk(c)
+	}
+	return cleanText(b.String())
+}
+
+// cleanText collapses whitespace within lines and drops empty lines
+func cleanText(s string) string {
+	var lines []string
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.Join(strings.Fields(line), " "); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
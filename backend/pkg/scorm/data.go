@@ -0,0 +1,135 @@
+package scorm
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+)
+
+// courseData is what the player reads from course.js
+type courseData struct {
+	Title       string       `json:"title"`
+	Description string       `json:"description"`
+	Version     Version      `json:"version"`
+	Lessons     []lessonData `json:"lessons"`
+	Quiz        *quizData    `json:"quiz,omitempty"`
+}
+
+type lessonData struct {
+	Title    string      `json:"title"`
+	VideoURL string      `json:"videoUrl,omitempty"`
+	Slides   []slideData `json:"slides"`
+}
+
+type slideData struct {
+	Title    string `json:"title"`
+	Content  string `json:"content"`
+	Script   string `json:"script,omitempty"`
+	Image    string `json:"image,omitempty"`
+	ImageAlt string `json:"imageAlt,omitempty"`
+	Audio    string `json:"audio,omitempty"`
+}
+
+type quizData struct {
+	Title         string         `json:"title"`
+	PassingScore  int            `json:"passingScore"`
+	QuestionCount int            `json:"questionCount,omitempty"`
+	Shuffle       bool           `json:"shuffle"`
+	Policy        string         `json:"policy,omitempty"`
+	AllowNegative bool           `json:"allowNegative"`
+	Questions     []questionData `json:"questions"`
+}
+
+type questionData struct {
+	Type          string          `json:"type"`
+	Text          string          `json:"text"`
+	Data          json.RawMessage `json:"data"`
+	Points        int             `json:"points"`
+	Policy        string          `json:"policy,omitempty"`
+	AllowNegative *bool           `json:"allowNegative,omitempty"`
+}
+
+// newCourseData maps the package to the player's data and adds its assets to
+// files
+func newCourseData(pkg *Package, version Version, files map[string][]byte) *courseData {
+	data := &courseData{
+		Title:       pkg.Title,
+		Description: pkg.Description,
+		Version:     version,
+		Lessons:     make([]lessonData, 0, len(pkg.Lessons)),
+	}
+
+	addAsset := func(asset *Asset, name string) string {
+		if asset == nil || len(asset.Data) == 0 {
+			return ""
+		}
+		path := "assets/" + name + asset.Ext
+		files[path] = asset.Data
+		return path
+	}
+
+	for i, lesson := range pkg.Lessons {
+		l := lessonData{
+			Title:    lesson.Title,
+			VideoURL: webURL(lesson.VideoURL),
+			Slides:   make([]slideData, 0, len(lesson.Slides)),
+		}
+		for j, slide := range lesson.Slides {
+			name := fmt.Sprintf("lesson-%d-slide-%d", i+1, j+1)
+			l.Slides = append(l.Slides, slideData{
+				Title:    slide.Title,
+				Content:  sanitizeHTML(slide.Content),
+				Script:   slide.Script,
+				Image:    addAsset(slide.Image, name+"-image"),
+				ImageAlt: slide.ImageAlt,
+				Audio:    addAsset(slide.Audio, name+"-audio"),
+			})
+		}
+		data.Lessons = append(data.Lessons, l)
+	}
+
+	if quiz := pkg.Quiz; quiz != nil && len(quiz.Questions) > 0 {
+		q := &quizData{
+			Title:         quiz.Title,
+			PassingScore:  quiz.PassingScore,
+			QuestionCount: quiz.QuestionCount,
+			Shuffle:       quiz.Shuffle,
+			Policy:        string(quiz.Policy),
+			AllowNegative: quiz.AllowNegative,
+			Questions:     make([]questionData, 0, len(quiz.Questions)),
+		}
+		for _, question := range quiz.Questions {
+			q.Questions = append(q.Questions, questionData{
+				Type:          string(question.Type),
+				Text:          question.Text,
+				Data:          question.Data,
+				Points:        question.Points,
+				Policy:        string(question.Policy),
+				AllowNegative: question.AllowNegative,
+			})
+		}
+		data.Quiz = q
+	}
+
+	return data
+}
+
+// webURL drops anything but http(s) links, which the player opens in a new
+// window
+func webURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return ""
+	}
+	return u.String()
+}
+
+func sortedNames(files map[string][]byte) []string {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,120 @@
+package scorm
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+var manifestFuncs = template.FuncMap{
+	"xml": func(s string) string {
+		var b strings.Builder
+		xml.EscapeText(&b, []byte(s))
+		return b.String()
+	},
+}
+
+var manifest12 = template.Must(template.New("manifest12").Funcs(manifestFuncs).Parse(`<?xml version="1.0" encoding="UTF-8"?>
+<manifest identifier="{{xml .Identifier}}" version="1.0"
+  xmlns="http://www.imsproject.org/xsd/imscp_rootv1p1p2"
+  xmlns:adlcp="http://www.adlnet.org/xsd/adlcp_rootv1p2"
+  xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance"
+  xsi:schemaLocation="http://www.imsproject.org/xsd/imscp_rootv1p1p2 imscp_rootv1p1p2.xsd http://www.imsglobal.org/xsd/imsmd_rootv1p2p1 imsmd_rootv1p2p1.xsd http://www.adlnet.org/xsd/adlcp_rootv1p2 adlcp_rootv1p2.xsd">
+  <metadata>
+    <schema>ADL SCORM</schema>
+    <schemaversion>1.2</schemaversion>
+  </metadata>
+  <organizations default="ORG-1">
+    <organization identifier="ORG-1">
+      <title>{{xml .Title}}</title>
+      <item identifier="ITEM-1" identifierref="RES-1" isvisible="true">
+        <title>{{xml .Title}}</title>
+{{- if .MasteryScore}}
+        <adlcp:masteryscore>{{.MasteryScore}}</adlcp:masteryscore>
+{{- end}}
+      </item>
+    </organization>
+  </organizations>
+  <resources>
+    <resource identifier="RES-1" type="webcontent" adlcp:scormtype="sco" href="index.html">
+{{- range .Files}}
+      <file href="{{xml .}}"/>
+{{- end}}
+    </resource>
+  </resources>
+</manifest>
+`))
+
+var manifest2004 = template.Must(template.New("manifest2004").Funcs(manifestFuncs).Parse(`<?xml version="1.0" encoding="UTF-8"?>
+<manifest identifier="{{xml .Identifier}}" version="1"
+  xmlns="http://www.imsglobal.org/xsd/imscp_v1p1"
+  xmlns:adlcp="http://www.adlnet.org/xsd/adlcp_v1p3"
+  xmlns:adlseq="http://www.adlnet.org/xsd/adlseq_v1p3"
+  xmlns:adlnav="http://www.adlnet.org/xsd/adlnav_v1p3"
+  xmlns:imsss="http://www.imsglobal.org/xsd/imsss"
+  xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance"
+  xsi:schemaLocation="http://www.imsglobal.org/xsd/imscp_v1p1 imscp_v1p1.xsd http://www.adlnet.org/xsd/adlcp_v1p3 adlcp_v1p3.xsd http://www.adlnet.org/xsd/adlseq_v1p3 adlseq_v1p3.xsd http://www.adlnet.org/xsd/adlnav_v1p3 adlnav_v1p3.xsd http://www.imsglobal.org/xsd/imsss imsss_v1p0.xsd">
+  <metadata>
+    <schema>ADL SCORM</schema>
+    <schemaversion>2004 4th Edition</schemaversion>
+  </metadata>
+  <organizations default="ORG-1">
+    <organization identifier="ORG-1">
+      <title>{{xml .Title}}</title>
+      <item identifier="ITEM-1" identifierref="RES-1" isvisible="true">
+        <title>{{xml .Title}}</title>
+{{- if .MasteryScore}}
+        <imsss:sequencing>
+          <imsss:objectives>
+            <imsss:primaryObjective objectiveID="PRIMARYOBJ" satisfiedByMeasure="true">
+              <imsss:minNormalizedMeasure>{{.MinNormalizedMeasure}}</imsss:minNormalizedMeasure>
+            </imsss:primaryObjective>
+          </imsss:objectives>
+        </imsss:sequencing>
+{{- end}}
+      </item>
+    </organization>
+  </organizations>
+  <resources>
+    <resource identifier="RES-1" type="webcontent" adlcp:scormType="sco" href="index.html">
+{{- range .Files}}
+      <file href="{{xml .}}"/>
+{{- end}}
+    </resource>
+  </resources>
+</manifest>
+`))
+
+type manifestData struct {
+	Identifier   string
+	Title        string
+	MasteryScore int // Passing percentage; 0 when there is no quiz
+	Files        []string
+}
+
+func (m manifestData) MinNormalizedMeasure() string {
+	return fmt.Sprintf("%.2f", float64(m.MasteryScore)/100)
+}
+
+func writeManifest(pkg *Package, version Version, files []string) ([]byte, error) {
+	data := manifestData{
+		Identifier: pkg.Identifier,
+		Title:      pkg.Title,
+		Files:      files,
+	}
+	if pkg.Quiz != nil && len(pkg.Quiz.Questions) > 0 {
+		data.MasteryScore = pkg.Quiz.PassingScore
+	}
+
+	tmpl := manifest12
+	if version == Version2004 {
+		tmpl = manifest2004
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,558 @@
+/*
+ * Course player. Shows the lessons slide by slide, then the quiz, and keeps
+ * the LMS informed through Scorm: the content counts as completed once every
+ * slide has been viewed and the quiz, if there is one, has been submitted;
+ * the quiz decides passed or failed.
+ *
+ * The quiz is graded here the way the SecuSense server grades it, so a
+ * result in the LMS matches the same answers given in the web app.
+ */
+(function () {
+  'use strict';
+
+  var course = window.SECUSENSE_COURSE;
+  var nav = document.getElementById('nav');
+  var main = document.getElementById('main');
+
+  // Persisted in suspend data: v holds one '0'/'1' per slide of each lesson,
+  // q the best quiz result as {p: percentage, ok: passed}
+  var state = { v: [], q: null };
+  var position = { lesson: 0, slide: 0, quiz: false };
+
+  // ---- Helpers ----
+
+  function h(tag, props, children) {
+    var node = document.createElement(tag);
+    props = props || {};
+    for (var key in props) {
+      if (!props.hasOwnProperty(key) || props[key] === undefined || props[key] === null) {
+        continue;
+      }
+      if (key === 'text') {
+        node.textContent = props[key];
+      } else if (key === 'html') {
+        node.innerHTML = props[key]; // Sanitized when the package was built
+      } else if (key.indexOf('on') === 0) {
+        node.addEventListener(key.substring(2), props[key]);
+      } else {
+        node.setAttribute(key, props[key]);
+      }
+    }
+    (children || []).forEach(function (child) {
+      if (child) {
+        node.appendChild(typeof child === 'string' ? document.createTextNode(child) : child);
+      }
+    });
+    return node;
+  }
+
+  function clear(node) {
+    while (node.firstChild) {
+      node.removeChild(node.firstChild);
+    }
+  }
+
+  function shuffle(list) {
+    var copy = list.slice();
+    for (var i = copy.length - 1; i > 0; i--) {
+      var j = Math.floor(Math.random() * (i + 1));
+      var tmp = copy[i];
+      copy[i] = copy[j];
+      copy[j] = tmp;
+    }
+    return copy;
+  }
+
+  // A video lesson without slides is a single page with a link to the video
+  function pageCount(lesson) {
+    return Math.max(1, lesson.slides.length);
+  }
+
+  // ---- Progress ----
+
+  function restore() {
+    var saved = Scorm.loadState();
+    course.lessons.forEach(function (lesson, i) {
+      var viewed = saved && saved.v && typeof saved.v[i] === 'string' ? saved.v[i] : '';
+      var bits = '';
+      for (var j = 0; j < pageCount(lesson); j++) {
+        bits += viewed.charAt(j) === '1' ? '1' : '0';
+      }
+      state.v.push(bits);
+    });
+    if (saved && saved.q) {
+      state.q = saved.q;
+    }
+    if (saved && saved.l) {
+      var parts = String(saved.l).split('.');
+      if (parts[0] === 'quiz' && course.quiz) {
+        position.quiz = true;
+      } else if (course.lessons[+parts[0]]) {
+        position.lesson = +parts[0];
+        position.slide = Math.min(+parts[1] || 0, pageCount(course.lessons[position.lesson]) - 1);
+      }
+    }
+  }
+
+  function bookmark() {
+    return position.quiz ? 'quiz' : position.lesson + '.' + position.slide;
+  }
+
+  function save() {
+    Scorm.saveState({ v: state.v, q: state.q, l: bookmark() }, bookmark());
+  }
+
+  function viewedShare() {
+    var total = 0;
+    var viewed = 0;
+    state.v.forEach(function (bits) {
+      total += bits.length;
+      viewed += bits.split('1').length - 1;
+    });
+    return total ? viewed / total : 1;
+  }
+
+  function lessonDone(i) {
+    return state.v[i].indexOf('0') === -1;
+  }
+
+  function markViewed(i, j) {
+    var bits = state.v[i];
+    if (bits.charAt(j) !== '1') {
+      state.v[i] = bits.substring(0, j) + '1' + bits.substring(j + 1);
+    }
+    Scorm.setProgress(viewedShare());
+    checkCompletion();
+  }
+
+  function checkCompletion() {
+    if (viewedShare() === 1 && (!course.quiz || state.q)) {
+      Scorm.complete();
+    }
+  }
+
+  // ---- Navigation ----
+
+  function renderNav() {
+    clear(nav);
+    nav.appendChild(h('h1', { text: course.title }));
+    var list = h('ol', { class: 'toc' });
+    course.lessons.forEach(function (lesson, i) {
+      var active = !position.quiz && position.lesson === i;
+      list.appendChild(h('li', { class: (active ? 'active ' : '') + (lessonDone(i) ? 'done' : '') }, [
+        h('button', { type: 'button', text: lesson.title, onclick: function () { showLesson(i, 0); } })
+      ]));
+    });
+    if (course.quiz) {
+      var result = state.q ? ' (' + Math.round(state.q.p) + '%)' : '';
+      list.appendChild(h('li', { class: (position.quiz ? 'active ' : '') + (state.q && state.q.ok ? 'done' : '') }, [
+        h('button', { type: 'button', text: course.quiz.title + result, onclick: showQuiz })
+      ]));
+    }
+    nav.appendChild(list);
+  }
+
+  function showLesson(i, j) {
+    var lesson = course.lessons[i];
+    position = { lesson: i, slide: j, quiz: false };
+    markViewed(i, j);
+    save();
+    renderNav();
+
+    clear(main);
+    main.appendChild(h('p', { class: 'crumb', text: 'Lesson ' + (i + 1) + ' of ' + course.lessons.length }));
+    main.appendChild(h('h2', { text: lesson.title }));
+
+    var slide = lesson.slides[j];
+    if (!slide) {
+      main.appendChild(h('div', { class: 'slide' }, [
+        lesson.videoUrl
+          ? h('p', {}, [h('a', { href: lesson.videoUrl, target: '_blank', rel: 'noopener noreferrer', text: 'Watch the lesson video' })])
+          : h('p', { text: 'This lesson has no content yet.' })
+      ]));
+    } else {
+      main.appendChild(h('article', { class: 'slide' }, [
+        slide.title ? h('h3', { text: slide.title }) : null,
+        slide.image ? h('img', { src: slide.image, alt: slide.imageAlt || '' }) : null,
+        h('div', { class: 'slide-content', html: slide.content }),
+        slide.audio ? h('audio', { controls: 'controls', src: slide.audio, preload: 'none' }) : null,
+        slide.script ? h('details', { class: 'transcript' }, [h('summary', { text: 'Transcript' }), h('p', { text: slide.script })]) : null
+      ]));
+    }
+
+    var pages = pageCount(lesson);
+    var next = null;
+    if (j + 1 < pages) {
+      next = h('button', { type: 'button', class: 'primary', text: 'Next', onclick: function () { showLesson(i, j + 1); } });
+    } else if (i + 1 < course.lessons.length) {
+      next = h('button', { type: 'button', class: 'primary', text: 'Next lesson', onclick: function () { showLesson(i + 1, 0); } });
+    } else if (course.quiz) {
+      next = h('button', { type: 'button', class: 'primary', text: 'Go to the quiz', onclick: showQuiz });
+    }
+    main.appendChild(h('div', { class: 'pager' }, [
+      h('button', { type: 'button', text: 'Previous', disabled: j === 0 && i === 0 ? 'disabled' : null, onclick: function () {
+        if (j > 0) {
+          showLesson(i, j - 1);
+        } else if (i > 0) {
+          showLesson(i - 1, pageCount(course.lessons[i - 1]) - 1);
+        }
+      } }),
+      h('span', { class: 'count', text: (j + 1) + ' / ' + pages }),
+      next
+    ]));
+    main.focus();
+  }
+
+  // ---- Quiz ----
+
+  var defaultPolicies = {
+    multiple_choice: 'all_or_nothing',
+    true_false: 'all_or_nothing',
+    numeric: 'all_or_nothing'
+  };
+
+  var accents = (function () {
+    try {
+      return new RegExp('\\p{Mn}', 'gu');
+    } catch (e) {
+      return /[\u0300-\u036f]/g;
+    }
+  })();
+
+  function normalizeAnswer(s, caseSensitive) {
+    s = String(s).trim().split(/\s+/).join(' ');
+    if (s.normalize) {
+      s = s.normalize('NFD').replace(accents, '').normalize('NFC');
+    }
+    return caseSensitive ? s : s.toLowerCase();
+  }
+
+  function levenshtein(a, b) {
+    var ra = Array.from(a);
+    var rb = Array.from(b);
+    var prev = [];
+    var curr = [];
+    for (var j = 0; j <= rb.length; j++) {
+      prev[j] = j;
+    }
+    for (var i = 1; i <= ra.length; i++) {
+      curr[0] = i;
+      for (j = 1; j <= rb.length; j++) {
+        var cost = ra[i - 1] === rb[j - 1] ? 0 : 1;
+        curr[j] = Math.min(prev[j] + 1, curr[j - 1] + 1, prev[j - 1] + cost);
+      }
+      var tmp = prev;
+      prev = curr;
+      curr = tmp;
+    }
+    return prev[rb.length];
+  }
+
+  function blankAccepts(data, i, answer) {
+    var given = normalizeAnswer(answer, data.caseSensitive);
+    var accepted = [data.blanks[i]].concat((data.alternatives && data.alternatives[i]) || []);
+    for (var k = 0; k < accepted.length; k++) {
+      var want = normalizeAnswer(accepted[k], data.caseSensitive);
+      if (!want) {
+        continue;
+      }
+      var allowed = Math.min(data.maxEdits || 0, Math.floor(Array.from(want).length / 4));
+      if (given === want || levenshtein(given, want) <= allowed) {
+        return true;
+      }
+    }
+    var pattern = data.patterns && data.patterns[i];
+    if (pattern) {
+      try {
+        return new RegExp('^(?:' + pattern + ')$', data.caseSensitive ? '' : 'i').test(normalizeAnswer(answer, true));
+      } catch (e) {
+        return false;
+      }
+    }
+    return false;
+  }
+
+  function countPairs(outcome, expected, given) {
+    for (var key in expected) {
+      if (!expected.hasOwnProperty(key)) {
+        continue;
+      }
+      outcome.total++;
+      var got = given[key];
+      if (!got) {
+        continue;
+      }
+      if (got === expected[key]) {
+        outcome.correct++;
+      } else {
+        outcome.wrong++;
+      }
+    }
+  }
+
+  // outcomeOf counts the right and wrong parts of an answer
+  function outcomeOf(question, answer) {
+    var data = question.data;
+    var outcome = { correct: 0, wrong: 0, total: 0 };
+    switch (question.type) {
+      case 'multiple_choice':
+        outcome.total = data.correctIndices.length;
+        var seen = {};
+        (answer || []).forEach(function (index) {
+          if (seen[index]) {
+            return;
+          }
+          seen[index] = true;
+          data.correctIndices.indexOf(index) !== -1 ? outcome.correct++ : outcome.wrong++;
+        });
+        break;
+      case 'true_false':
+        outcome.total = 1;
+        if (answer !== null) {
+          answer === data.correctAnswer ? outcome.correct++ : outcome.wrong++;
+        }
+        break;
+      case 'numeric':
+        outcome.total = 1;
+        if (answer !== null) {
+          Math.abs(answer - data.correctValue) <= (data.tolerance || 0) + 1e-9 ? outcome.correct++ : outcome.wrong++;
+        }
+        break;
+      case 'fill_blank':
+        outcome.total = data.blanks.length;
+        answer.forEach(function (text, i) {
+          if (!text.trim()) {
+            return;
+          }
+          blankAccepts(data, i, text) ? outcome.correct++ : outcome.wrong++;
+        });
+        break;
+      case 'ordering':
+        outcome.total = data.correctOrder.length;
+        answer.forEach(function (index, i) {
+          index === data.correctOrder[i] ? outcome.correct++ : outcome.wrong++;
+        });
+        break;
+      case 'matching':
+        countPairs(outcome, data.correctPairs, answer);
+        break;
+      case 'drag_drop':
+        countPairs(outcome, data.correctMapping, answer);
+        break;
+    }
+    return outcome;
+  }
+
+  function applyPolicy(policy, outcome, points, allowNegative) {
+    if (outcome.total === 0) {
+      return 0;
+    }
+    var fraction = 0;
+    if (policy === 'all_or_nothing') {
+      fraction = outcome.correct === outcome.total && outcome.wrong === 0 ? 1 : 0;
+    } else if (policy === 'penalty') {
+      fraction = (outcome.correct - outcome.wrong) / outcome.total;
+    } else {
+      fraction = outcome.correct / Math.max(outcome.total, outcome.correct + outcome.wrong);
+    }
+    var awarded = Math.round(points * fraction);
+    return !allowNegative && awarded < 0 ? 0 : awarded;
+  }
+
+  function grade(question, answer) {
+    var quiz = course.quiz;
+    var outcome = outcomeOf(question, answer);
+    var policy = question.policy || quiz.policy || defaultPolicies[question.type] || 'proportional';
+    var allowNegative = question.allowNegative !== undefined ? question.allowNegative : quiz.allowNegative;
+    return {
+      points: applyPolicy(policy, outcome, question.points, allowNegative),
+      correct: outcome.total > 0 && outcome.correct === outcome.total && outcome.wrong === 0
+    };
+  }
+
+  // Each input builder renders a question and returns a function that reads
+  // the answer in the shape the server's scorers take
+  var inputs = {
+    multiple_choice: function (question, box, name) {
+      var multiple = question.data.correctIndices.length > 1;
+      var choices = question.data.options.map(function (option, i) {
+        var input = h('input', { type: multiple ? 'checkbox' : 'radio', name: name, value: i });
+        box.appendChild(h('label', { class: 'choice' }, [input, ' ', option]));
+        return input;
+      });
+      return function () {
+        return choices.filter(function (c) { return c.checked; }).map(function (c) { return +c.value; });
+      };
+    },
+    true_false: function (question, box, name) {
+      var yes = h('input', { type: 'radio', name: name });
+      var no = h('input', { type: 'radio', name: name });
+      box.appendChild(h('label', { class: 'choice' }, [yes, ' True']));
+      box.appendChild(h('label', { class: 'choice' }, [no, ' False']));
+      return function () {
+        return yes.checked ? true : no.checked ? false : null;
+      };
+    },
+    numeric: function (question, box) {
+      var input = h('input', { type: 'number', step: 'any' });
+      box.appendChild(h('label', { class: 'numeric' }, [input, question.data.unit ? ' ' + question.data.unit : null]));
+      return function () {
+        var value = parseFloat(input.value);
+        return isNaN(value) ? null : value;
+      };
+    },
+    fill_blank: function (question, box) {
+      var fields = [];
+      var parts = question.data.template.split('{{blank}}');
+      var line = h('p', { class: 'blanks' });
+      parts.forEach(function (part, i) {
+        line.appendChild(document.createTextNode(part));
+        if (i < parts.length - 1) {
+          var input = h('input', { type: 'text', 'aria-label': 'Blank ' + (i + 1) });
+          fields.push(input);
+          line.appendChild(input);
+        }
+      });
+      box.appendChild(line);
+      return function () {
+        return question.data.blanks.map(function (_, i) { return fields[i] ? fields[i].value : ''; });
+      };
+    },
+    ordering: function (question, box) {
+      var order = shuffle(question.data.items.map(function (_, i) { return i; }));
+      var list = h('ol', { class: 'ordering' });
+      function render() {
+        clear(list);
+        order.forEach(function (index, pos) {
+          list.appendChild(h('li', {}, [
+            h('span', { text: question.data.items[index] }),
+            h('button', { type: 'button', 'aria-label': 'Move up', text: '↑', disabled: pos === 0 ? 'disabled' : null, onclick: function () { move(pos, -1); } }),
+            h('button', { type: 'button', 'aria-label': 'Move down', text: '↓', disabled: pos === order.length - 1 ? 'disabled' : null, onclick: function () { move(pos, 1); } })
+          ]));
+        });
+      }
+      function move(pos, step) {
+        var tmp = order[pos];
+        order[pos] = order[pos + step];
+        order[pos + step] = tmp;
+        render();
+      }
+      render();
+      box.appendChild(list);
+      return function () {
+        return order.slice();
+      };
+    },
+    matching: function (question, box) {
+      return pairInputs(box, question.data.leftItems, question.data.rightItems);
+    },
+    drag_drop: function (question, box) {
+      return pairInputs(box, question.data.items, question.data.dropZones);
+    }
+  };
+
+  function pairInputs(box, keys, values) {
+    var selects = {};
+    var table = h('div', { class: 'pairs' });
+    keys.forEach(function (key) {
+      var select = h('select', {}, [h('option', { value: '', text: 'Choose…' })].concat(values.map(function (value) {
+        return h('option', { value: value, text: value });
+      })));
+      selects[key] = select;
+      table.appendChild(h('label', {}, [h('span', { text: key }), select]));
+    });
+    box.appendChild(table);
+    return function () {
+      var answer = {};
+      for (var key in selects) {
+        if (selects.hasOwnProperty(key) && selects[key].value) {
+          answer[key] = selects[key].value;
+        }
+      }
+      return answer;
+    };
+  }
+
+  function showQuiz() {
+    var quiz = course.quiz;
+    position = { lesson: position.lesson, slide: position.slide, quiz: true };
+    save();
+    renderNav();
+
+    clear(main);
+    main.appendChild(h('h2', { text: quiz.title }));
+    if (state.q) {
+      main.appendChild(h('p', { class: state.q.ok ? 'passed' : 'failed', text: 'Your best result: ' + Math.round(state.q.p) + '% (' + (state.q.ok ? 'passed' : 'not passed') + ')' }));
+    }
+    main.appendChild(h('p', { text: 'You need ' + quiz.passingScore + '% to pass.' }));
+
+    var questions = quiz.shuffle || quiz.questionCount ? shuffle(quiz.questions) : quiz.questions.slice();
+    if (quiz.questionCount && quiz.questionCount < questions.length) {
+      questions = questions.slice(0, quiz.questionCount);
+    }
+
+    var form = h('form', { class: 'quiz' });
+    var readers = questions.map(function (question, i) {
+      var box = h('fieldset', { class: 'question' }, [h('legend', { text: (i + 1) + '. ' + question.text })]);
+      form.appendChild(box);
+      return inputs[question.type](question, box, 'q' + i);
+    });
+    form.appendChild(h('button', { type: 'submit', class: 'primary', text: 'Submit answers' }));
+    form.addEventListener('submit', function (event) {
+      event.preventDefault();
+      submitQuiz(questions, readers, form);
+    });
+    main.appendChild(form);
+    main.focus();
+  }
+
+  function submitQuiz(questions, readers, form) {
+    var quiz = course.quiz;
+    var earned = 0;
+    var max = 0;
+    var boxes = form.querySelectorAll('fieldset');
+    questions.forEach(function (question, i) {
+      var result = grade(question, readers[i]());
+      earned += result.points;
+      max += question.points;
+      boxes[i].className += result.correct ? ' correct' : ' incorrect';
+      if (question.data.explanation) {
+        boxes[i].appendChild(h('p', { class: 'explanation', text: question.data.explanation }));
+      }
+      boxes[i].disabled = true;
+    });
+
+    var percentage = max > 0 ? earned / max * 100 : 0;
+    var passed = percentage >= quiz.passingScore;
+    if (!state.q || percentage > state.q.p) {
+      state.q = { p: percentage, ok: passed };
+    }
+    checkCompletion();
+    Scorm.setScore(earned, max, percentage, passed);
+    save();
+    renderNav();
+
+    form.removeChild(form.lastChild);
+    var summary = h('div', { class: 'summary ' + (passed ? 'passed' : 'failed') }, [
+      h('p', { text: 'Score: ' + earned + ' / ' + max + ' (' + Math.round(percentage) + '%)' }),
+      h('p', { text: passed ? 'You passed.' : 'You did not reach the passing score.' }),
+      h('button', { type: 'button', text: 'Try again', onclick: showQuiz })
+    ]);
+    form.appendChild(summary);
+    summary.scrollIntoView();
+  }
+
+  // ---- Start ----
+
+  Scorm.init(course.version);
+  restore();
+  document.title = course.title;
+  window.addEventListener('beforeunload', function () { save(); Scorm.finish(); });
+  window.addEventListener('pagehide', function () { save(); Scorm.finish(); });
+
+  if (position.quiz || course.lessons.length === 0) {
+    showQuiz();
+  } else {
+    showLesson(position.lesson, position.slide);
+  }
+})();
@@ -0,0 +1,207 @@
+/*
+ * SCORM runtime adapter. Finds the LMS's API object (API_1484_11 for SCORM
+ * 2004, API for SCORM 1.2) in a parent or opener window and maps the
+ * player's progress onto the version's data model. Without an LMS every call
+ * is a no-op, so the package can also be opened straight from disk.
+ */
+var Scorm = (function () {
+  'use strict';
+
+  var api = null;
+  var is2004 = false;
+  var startedAt = new Date();
+  var terminated = false;
+  var status = { completed: false, success: null };
+
+  function searchWindow(win, name) {
+    for (var depth = 0; win && depth < 16; depth++) {
+      try {
+        if (win[name]) {
+          return win[name];
+        }
+      } catch (e) {
+        return null; // Cross-origin frame
+      }
+      if (!win.parent || win.parent === win) {
+        break;
+      }
+      win = win.parent;
+    }
+    return null;
+  }
+
+  function findAPI(name) {
+    return searchWindow(window, name) || (window.opener ? searchWindow(window.opener, name) : null);
+  }
+
+  function get(element) {
+    if (!api || terminated) {
+      return '';
+    }
+    return String(is2004 ? api.GetValue(element) : api.LMSGetValue(element));
+  }
+
+  function set(element, value) {
+    if (!api || terminated) {
+      return false;
+    }
+    var ok = is2004 ? api.SetValue(element, String(value)) : api.LMSSetValue(element, String(value));
+    return String(ok) === 'true';
+  }
+
+  function commit() {
+    if (api && !terminated) {
+      is2004 ? api.Commit('') : api.LMSCommit('');
+    }
+  }
+
+  function pad(n, width) {
+    var s = String(n);
+    while (s.length < width) {
+      s = '0' + s;
+    }
+    return s;
+  }
+
+  function sessionTime() {
+    var seconds = Math.max(0, Math.round((new Date() - startedAt) / 1000));
+    var h = Math.floor(seconds / 3600);
+    var m = Math.floor((seconds % 3600) / 60);
+    var s = seconds % 60;
+    if (is2004) {
+      return 'PT' + h + 'H' + m + 'M' + s + 'S';
+    }
+    return pad(Math.min(h, 9999), 4) + ':' + pad(m, 2) + ':' + pad(s, 2) + '.00';
+  }
+
+  // writeStatus sets the status elements; SCORM 1.2 has a single
+  // lesson_status, where passed and failed take precedence over completed
+  function writeStatus() {
+    if (is2004) {
+      set('cmi.completion_status', status.completed ? 'completed' : 'incomplete');
+      if (status.success !== null) {
+        set('cmi.success_status', status.success ? 'passed' : 'failed');
+      }
+      return;
+    }
+    if (status.success !== null) {
+      set('cmi.core.lesson_status', status.success ? 'passed' : 'failed');
+    } else {
+      set('cmi.core.lesson_status', status.completed ? 'completed' : 'incomplete');
+    }
+  }
+
+  return {
+    // init connects to the LMS, preferring the API of the version the
+    // package was built for. It returns whether an LMS was found.
+    init: function (version) {
+      var names = version === '2004' ? ['API_1484_11', 'API'] : ['API', 'API_1484_11'];
+      for (var i = 0; i < names.length && !api; i++) {
+        api = findAPI(names[i]);
+        is2004 = names[i] === 'API_1484_11';
+      }
+      if (!api) {
+        return false;
+      }
+      var ok = is2004 ? api.Initialize('') : api.LMSInitialize('');
+      if (String(ok) !== 'true') {
+        api = null;
+        return false;
+      }
+
+      var current = get(is2004 ? 'cmi.completion_status' : 'cmi.core.lesson_status');
+      status.completed = current === 'completed' || current === 'passed' || current === 'failed';
+      if (!is2004 && (current === 'passed' || current === 'failed')) {
+        status.success = current === 'passed';
+      }
+      if (is2004) {
+        var success = get('cmi.success_status');
+        if (success === 'passed' || success === 'failed') {
+          status.success = success === 'passed';
+        }
+      }
+      if (current === 'not attempted' || current === 'unknown' || current === '') {
+        writeStatus();
+        commit();
+      }
+      return true;
+    },
+
+    connected: function () {
+      return api !== null;
+    },
+
+    // loadState returns what saveState stored in an earlier session
+    loadState: function () {
+      var data = get('cmi.suspend_data');
+      if (!data) {
+        return null;
+      }
+      try {
+        return JSON.parse(data);
+      } catch (e) {
+        return null;
+      }
+    },
+
+    // saveState stores the player's state and bookmark. SCORM 1.2 LMSs only
+    // have to keep 4096 characters of suspend data, so the state is kept
+    // small.
+    saveState: function (state, location) {
+      set('cmi.suspend_data', JSON.stringify(state));
+      set(is2004 ? 'cmi.location' : 'cmi.core.lesson_location', location);
+      commit();
+    },
+
+    // setProgress reports the share of the content viewed (SCORM 2004 only)
+    setProgress: function (fraction) {
+      if (is2004) {
+        set('cmi.progress_measure', Math.min(1, Math.max(0, fraction)).toFixed(2));
+      }
+    },
+
+    complete: function () {
+      if (!status.completed) {
+        status.completed = true;
+        writeStatus();
+        commit();
+      }
+    },
+
+    // setScore reports a quiz result. SCORM 1.2 scores are percentages;
+    // SCORM 2004 gets the points and the scaled score.
+    setScore: function (points, maxPoints, percentage, passed) {
+      if (is2004) {
+        set('cmi.score.min', 0);
+        set('cmi.score.max', maxPoints);
+        set('cmi.score.raw', points);
+        set('cmi.score.scaled', Math.min(1, Math.max(-1, percentage / 100)).toFixed(4));
+      } else {
+        set('cmi.core.score.min', 0);
+        set('cmi.core.score.max', 100);
+        set('cmi.core.score.raw', Math.max(0, Math.round(percentage)));
+      }
+      status.success = passed;
+      writeStatus();
+      commit();
+    },
+
+    finish: function () {
+      if (!api || terminated) {
+        return;
+      }
+      terminated = true;
+      if (is2004) {
+        set('cmi.session_time', sessionTime());
+        set('cmi.exit', status.completed ? 'normal' : 'suspend');
+        api.Commit('');
+        api.Terminate('');
+      } else {
+        set('cmi.core.session_time', sessionTime());
+        set('cmi.core.exit', status.completed ? '' : 'suspend');
+        api.LMSCommit('');
+        api.LMSFinish('');
+      }
+    }
+  };
+})();
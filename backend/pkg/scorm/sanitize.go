@@ -0,0 +1,103 @@
+package scorm
+
+import (
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// allowedElements is the formatting markup slides may use. Other elements
+// are unwrapped to their text.
+var allowedElements = map[atom.Atom]bool{
+	atom.P: true, atom.Br: true, atom.Hr: true, atom.Div: true, atom.Span: true,
+	atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true, atom.H5: true, atom.H6: true,
+	atom.Ul: true, atom.Ol: true, atom.Li: true, atom.Dl: true, atom.Dt: true, atom.Dd: true,
+	atom.Strong: true, atom.B: true, atom.Em: true, atom.I: true, atom.U: true, atom.S: true,
+	atom.Mark: true, atom.Small: true, atom.Sub: true, atom.Sup: true,
+	atom.Code: true, atom.Pre: true, atom.Kbd: true, atom.Blockquote: true, atom.A: true,
+	atom.Table: true, atom.Thead: true, atom.Tbody: true, atom.Tr: true, atom.Th: true, atom.Td: true,
+}
+
+// droppedElements are removed together with their content
+var droppedElements = map[atom.Atom]bool{
+	atom.Script: true, atom.Style: true, atom.Iframe: true, atom.Object: true, atom.Embed: true,
+	atom.Form: true, atom.Input: true, atom.Button: true, atom.Select: true, atom.Textarea: true,
+	atom.Template: true, atom.Noscript: true, atom.Svg: true, atom.Math: true,
+}
+
+var voidElements = map[atom.Atom]bool{atom.Br: true, atom.Hr: true}
+
+// sanitizeHTML keeps the formatting of generated slide content and drops
+// scripts, styles, event handlers and anything else that would run in the
+// LMS's window. The player inserts slide content as HTML, and unlike the
+// web app it has no framework sanitizer in front of it.
+func sanitizeHTML(content string) string {
+	context := &html.Node{Type: html.ElementNode, Data: "div", DataAtom: atom.Div}
+	nodes, err := html.ParseFragment(strings.NewReader(content), context)
+	if err != nil {
+		return html.EscapeString(content)
+	}
+
+	var b strings.Builder
+	for _, n := range nodes {
+		writeClean(&b, n)
+	}
+	return b.String()
+}
+
+func writeClean(b *strings.Builder, n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		b.WriteString(html.EscapeString(n.Data))
+		return
+	case html.ElementNode:
+	default:
+		return
+	}
+	if droppedElements[n.DataAtom] {
+		return
+	}
+
+	allowed := allowedElements[n.DataAtom]
+	if allowed {
+		b.WriteString("<" + n.Data)
+		for _, attr := range n.Attr {
+			if value, ok := cleanAttr(n.DataAtom, attr); ok {
+				b.WriteString(" " + attr.Key + `="` + html.EscapeString(value) + `"`)
+			}
+		}
+		if n.DataAtom == atom.A {
+			b.WriteString(` target="_blank" rel="noopener noreferrer"`)
+		}
+		b.WriteString(">")
+		if voidElements[n.DataAtom] {
+			return
+		}
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		writeClean(b, c)
+	}
+	if allowed {
+		b.WriteString("</" + n.Data + ">")
+	}
+}
+
+// cleanAttr keeps links to web pages and table cell spans
+func cleanAttr(element atom.Atom, attr html.Attribute) (string, bool) {
+	if attr.Namespace != "" {
+		return "", false
+	}
+	switch {
+	case element == atom.A && attr.Key == "href":
+		u, err := url.Parse(strings.TrimSpace(attr.Val))
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "mailto") {
+			return "", false
+		}
+		return u.String(), true
+	case (element == atom.Td || element == atom.Th) && (attr.Key == "colspan" || attr.Key == "rowspan"):
+		return attr.Val, true
+	}
+	return "", false
+}
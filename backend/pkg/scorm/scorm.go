@@ -0,0 +1,164 @@
+// Package scorm builds SCORM 1.2 and SCORM 2004 (4th edition) content
+// packages for a course. A package holds a single SCO: a self-contained
+// HTML player that shows the lessons and the final quiz, grades the quiz in
+// the browser and reports completion and score to the LMS through the SCORM
+// runtime API.
+package scorm
+
+import (
+	"archive/zip"
+	"bytes"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/secusense/backend/internal/domain"
+)
+
+type Version string
+
+const (
+	Version12   Version = "1.2"
+	Version2004 Version = "2004"
+)
+
+//go:embed runtime
+var runtimeFiles embed.FS
+
+// runtimeFileNames are copied from the runtime directory to the package root
+var runtimeFileNames = []string{"index.html", "scorm.js", "player.js", "player.css"}
+
+const courseDataFile = "course.js"
+
+// Package is the content of a course package
+type Package struct {
+	Identifier  string
+	Title       string
+	Description string
+	Lessons     []Lesson
+	Quiz        *Quiz // nil when the course has no test
+}
+
+type Lesson struct {
+	Title    string
+	VideoURL string // Video lessons link to their host; the video isn't packaged
+	Slides   []Slide
+}
+
+type Slide struct {
+	Title    string
+	Content  string // HTML, sanitized when the package is built
+	Script   string // Narration, shown as a transcript
+	Image    *Asset
+	ImageAlt string
+	Audio    *Asset
+}
+
+// Asset is a media file copied into the package
+type Asset struct {
+	Data []byte
+	Ext  string // Including the dot, e.g. ".mp3"
+}
+
+type Quiz struct {
+	Title         string
+	PassingScore  int // Percentage
+	QuestionCount int // Questions drawn per attempt; 0 means all
+	Shuffle       bool
+	// Policy applies to questions without their own; empty uses each
+	// question type's default
+	Policy        domain.ScoringPolicy
+	AllowNegative bool
+	Questions     []QuizQuestion
+}
+
+type QuizQuestion struct {
+	Type          domain.QuestionType
+	Text          string
+	Data          json.RawMessage
+	Points        int
+	Policy        domain.ScoringPolicy // Empty uses the quiz's policy
+	AllowNegative *bool
+}
+
+// supportedTypes are the question types the player can show and grade
+// without the server
+var supportedTypes = map[domain.QuestionType]bool{
+	domain.QuestionTypeMultipleChoice: true,
+	domain.QuestionTypeTrueFalse:      true,
+	domain.QuestionTypeFillBlank:      true,
+	domain.QuestionTypeNumeric:        true,
+	domain.QuestionTypeOrdering:       true,
+	domain.QuestionTypeMatching:       true,
+	domain.QuestionTypeDragDrop:       true,
+}
+
+// Supports reports whether questions of a type can be put in a package.
+// Hotspot images, rubric-graded text and LLM-graded answers need the server.
+func Supports(t domain.QuestionType) bool {
+	return supportedTypes[t]
+}
+
+// Build writes the package as a zip with imsmanifest.xml at its root
+func Build(pkg *Package, version Version) ([]byte, error) {
+	if version != Version12 && version != Version2004 {
+		return nil, fmt.Errorf("unsupported SCORM version %q", version)
+	}
+	if pkg.Quiz != nil {
+		for _, q := range pkg.Quiz.Questions {
+			if !Supports(q.Type) {
+				return nil, fmt.Errorf("question type %q can't be graded in a package", q.Type)
+			}
+		}
+	}
+	if len(pkg.Lessons) == 0 && (pkg.Quiz == nil || len(pkg.Quiz.Questions) == 0) {
+		return nil, errors.New("package has no lessons and no quiz")
+	}
+
+	files := make(map[string][]byte)
+	for _, name := range runtimeFileNames {
+		data, err := runtimeFiles.ReadFile("runtime/" + name)
+		if err != nil {
+			return nil, err
+		}
+		files[name] = data
+	}
+
+	data := newCourseData(pkg, version, files)
+	script, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	files[courseDataFile] = append(append([]byte("window.SECUSENSE_COURSE = "), script...), ";\n"...)
+
+	names := sortedNames(files)
+	manifest, err := writeManifest(pkg, version, names)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	if err := writeFile(zw, "imsmanifest.xml", manifest); err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		if err := writeFile(zw, name, files[name]); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeFile(zw *zip.Writer, name string, data []byte) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
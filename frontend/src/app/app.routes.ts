@@ -12,6 +12,10 @@ export const routes: Routes = [
     path: 'auth',
     loadChildren: () => import('./features/auth/auth.routes').then(m => m.AUTH_ROUTES)
   },
+  {
+    path: 'lti',
+    loadChildren: () => import('./features/lti/lti.routes').then(m => m.LTI_ROUTES)
+  },
   {
     path: 'courses',
     loadChildren: () => import('./features/courses/courses.routes').then(m => m.COURSES_ROUTES)
@@ -4,12 +4,27 @@ import { Router } from '@angular/router';
 import { Observable, tap, catchError, of, firstValueFrom } from 'rxjs';
 import { environment } from '@env/environment';
 
+export type Permission =
+  | 'course:write'
+  | 'test:write'
+  | 'review:grade'
+  | 'workflow:run'
+  | 'reports:read'
+  | 'users:manage'
+  | 'assignments:manage'
+  | 'certificates:revoke'
+  | 'lti:manage'
+  | 'organizations:manage'
+  | 'roles:manage';
+
 export interface User {
   id: string;
   email: string;
   firstName: string;
   lastName: string;
-  role: 'user' | 'admin';
+  role: string;
+  organizationId: string;
+  permissions: Permission[];
   createdAt: string;
 }
 
@@ -29,6 +44,7 @@ export interface RegisterRequest {
   password: string;
   firstName: string;
   lastName: string;
+  organization?: string;
 }
 
 @Injectable({
@@ -43,7 +59,9 @@ export class AuthService {
 
   readonly user = this.userSignal.asReadonly();
   readonly isAuthenticated = computed(() => this.userSignal() !== null);
-  readonly isAdmin = computed(() => this.userSignal()?.role === 'admin');
+  // Any permission opens the admin area; each page needs its own
+  readonly isAdmin = computed(() => (this.userSignal()?.permissions?.length ?? 0) > 0);
+  readonly isSuperAdmin = computed(() => this.userSignal()?.role === 'super_admin');
   readonly loading = this.loadingSignal.asReadonly();
 
   constructor(
@@ -78,6 +96,10 @@ export class AuthService {
       this.loadingSignal.set(false);
     }
   }
+  hasPermission(permission: Permission): boolean {
+    return this.userSignal()?.permissions?.includes(permission) ?? false;
+  }
+
 
   login(credentials: LoginRequest): Observable<AuthResponse> {
     return this.http.post<AuthResponse>(`${this.API_URL}/auth/login`, credentials).pipe(
@@ -115,6 +137,12 @@ export class AuthService {
     );
   }
 
+  // Signs in with tokens issued elsewhere, such as by an LTI launch
+  startSession(response: AuthResponse): void {
+    this.setTokens(response.accessToken, response.refreshToken);
+    this.userSignal.set(response.user);
+  }
+
   logout(): void {
     this.clearTokens();
     this.userSignal.set(null);
@@ -13,6 +13,11 @@ export interface Certificate {
   verificationHash: string;
   issuedAt: string;
   expiresAt?: string;
+  previousCertificateId?: string;
+  signature?: string;
+  revokedAt?: string;
+  revokedBy?: string;
+  revocationReason?: string;
   userFirstName?: string;
   userLastName?: string;
   userEmail?: string;
@@ -21,16 +26,27 @@ export interface Certificate {
   maxScore?: number;
 }
 
+export type VerificationStatus = 'valid' | 'expired' | 'revoked' | 'not_found';
+
 export interface CertificateVerification {
   valid: boolean;
+  status: VerificationStatus;
   certificateNumber?: string;
   holderName?: string;
   courseTitle?: string;
+  issuer?: string;
   issuedAt?: string;
+  expiresAt?: string;
+  revokedAt?: string;
   score?: number;
   maxScore?: number;
 }
 
+export interface CertificateLink {
+  url: string;
+  expiresAt: string;
+}
+
 export interface GenerateCertificateRequest {
   courseId: string;
   attemptId: string;
@@ -62,6 +78,16 @@ export class CertificateService {
     });
   }
 
+  getDownloadLink(id: string): Observable<CertificateLink> {
+    return this.http.get<CertificateLink>(`${this.API_URL}/certificates/${id}/link`);
+  }
+
+  downloadBadge(id: string): Observable<Blob> {
+    return this.http.get(`${this.API_URL}/certificates/${id}/badge`, {
+      responseType: 'blob'
+    });
+  }
+
   verifyCertificate(hash: string): Observable<CertificateVerification> {
     return this.http.get<CertificateVerification>(`${this.API_URL}/certificates/verify/${hash}`);
   }
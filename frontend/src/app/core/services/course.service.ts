@@ -5,6 +5,8 @@ import { environment } from '@env/environment';
 
 export type VideoStatus = 'pending' | 'processing' | 'completed' | 'failed';
 
+export type ScormVersion = '1.2' | '2004';
+
 export interface Course {
   id: string;
   title: string;
@@ -16,6 +18,8 @@ export interface Course {
   thumbnailUrl?: string;
   passPercentage: number;
   isPublished: boolean;
+  certificateValidityDays?: number;
+  renewalNoticeDays: number;
   createdAt: string;
   updatedAt: string;
 }
@@ -123,6 +127,13 @@ export class CourseService {
     return this.http.post<{ published: boolean }>(`${this.API_URL}/admin/courses/${id}/unpublish`, {});
   }
 
+  exportScorm(id: string, version: ScormVersion = '1.2'): Observable<Blob> {
+    return this.http.get(`${this.API_URL}/admin/courses/${id}/scorm`, {
+      params: { version },
+      responseType: 'blob'
+    });
+  }
+
   refreshVideoStatus(id: string): Observable<Course> {
     return this.http.post<Course>(`${this.API_URL}/admin/courses/${id}/refresh-video`, {});
   }
@@ -13,6 +13,8 @@ export interface Enrollment {
   enrolledAt: string;
   completedAt?: string;
   updatedAt: string;
+  assignmentId?: string;
+  dueAt?: string;
 }
 
 export interface EnrollmentWithCourse extends Enrollment {
@@ -50,4 +52,15 @@ export class EnrollmentService {
   completeVideo(enrollmentId: string): Observable<Enrollment> {
     return this.http.post<Enrollment>(`${this.API_URL}/enrollments/${enrollmentId}/complete-video`, {});
   }
+
+  launch(enrollmentId: string): Observable<Enrollment> {
+    return this.http.post<Enrollment>(`${this.API_URL}/enrollments/${enrollmentId}/launch`, {});
+  }
+
+  recordSlideView(enrollmentId: string, lessonId: string, slideIndex: number): Observable<void> {
+    return this.http.post<void>(`${this.API_URL}/enrollments/${enrollmentId}/slide-views`, {
+      lessonId,
+      slideIndex
+    });
+  }
 }
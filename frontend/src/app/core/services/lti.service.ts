@@ -0,0 +1,36 @@
+import { Injectable } from '@angular/core';
+import { HttpClient } from '@angular/common/http';
+import { Observable } from 'rxjs';
+import { environment } from '@env/environment';
+import { AuthResponse } from './auth.service';
+
+export type LtiMessageType = 'LtiResourceLinkRequest' | 'LtiDeepLinkingRequest';
+
+export interface LtiSession extends AuthResponse {
+  launchId: string;
+  messageType: LtiMessageType;
+  courseId?: string;
+}
+
+export interface LtiDeepLinkResponse {
+  returnUrl: string;
+  jwt: string;
+}
+
+@Injectable({
+  providedIn: 'root'
+})
+export class LtiService {
+  private readonly API_URL = environment.apiUrl;
+
+  constructor(private http: HttpClient) {}
+
+  // Trades the one-time code of an LTI launch for a session
+  createSession(code: string): Observable<LtiSession> {
+    return this.http.post<LtiSession>(`${this.API_URL}/lti/session`, { code });
+  }
+
+  createDeepLink(launchId: string, courseId: string): Observable<LtiDeepLinkResponse> {
+    return this.http.post<LtiDeepLinkResponse>(`${this.API_URL}/lti/deep-link`, { launchId, courseId });
+  }
+}
@@ -3,7 +3,8 @@ import { HttpClient } from '@angular/common/http';
 import { Observable } from 'rxjs';
 import { environment } from '@env/environment';
 
-export type QuestionType = 'multiple_choice' | 'drag_drop' | 'fill_blank' | 'matching' | 'ordering';
+export type QuestionType = 'multiple_choice' | 'drag_drop' | 'fill_blank' | 'matching' | 'ordering' |
+  'true_false' | 'numeric' | 'hotspot' | 'short_text' | 'open_ended';
 
 export interface Question {
   id: string;
@@ -44,6 +45,22 @@ export interface UpdateQuestionRequest {
   points: number;
 }
 
+export type InterchangeFormat = 'qti' | 'gift';
+
+export interface InterchangeIssue {
+  item: string;
+  title?: string;
+  reason: string;
+  skipped: boolean;
+}
+
+export interface ImportReport {
+  format: InterchangeFormat;
+  dryRun: boolean;
+  imported: Question[];
+  issues: InterchangeIssue[];
+}
+
 @Injectable({
   providedIn: 'root'
 })
@@ -67,4 +84,17 @@ export class QuestionService {
   deleteQuestion(questionId: string): Observable<any> {
     return this.http.delete(`${this.API_URL}/admin/questions/${questionId}`);
   }
+
+  exportQuestions(testId: string, format: InterchangeFormat): Observable<Blob> {
+    return this.http.get(`${this.API_URL}/admin/tests/${testId}/questions/export`, {
+      params: { format },
+      responseType: 'blob'
+    });
+  }
+
+  importQuestions(testId: string, format: InterchangeFormat, file: File, dryRun = false): Observable<ImportReport> {
+    return this.http.post<ImportReport>(`${this.API_URL}/admin/tests/${testId}/questions/import`, file, {
+      params: { format, dryRun }
+    });
+  }
 }
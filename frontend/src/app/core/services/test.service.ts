@@ -3,7 +3,8 @@ import { HttpClient } from '@angular/common/http';
 import { Observable } from 'rxjs';
 import { environment } from '@env/environment';
 
-export type QuestionType = 'multiple_choice' | 'drag_drop' | 'fill_blank' | 'matching' | 'ordering';
+export type QuestionType = 'multiple_choice' | 'drag_drop' | 'fill_blank' | 'matching' | 'ordering' |
+  'true_false' | 'numeric' | 'hotspot' | 'short_text' | 'open_ended';
 
 export interface Question {
   id: string;
@@ -13,6 +14,7 @@ export interface Question {
   questionData: any;
   points: number;
   orderIndex: number;
+  tags?: string[];
 }
 
 export interface Test {
@@ -22,6 +24,7 @@ export interface Test {
   description: string;
   timeLimitMinutes?: number;
   passingScore: number;
+  questionCount?: number;
   questions?: Question[];
 }
 
@@ -35,11 +38,17 @@ export interface TestAttempt {
   maxScore?: number;
   percentage?: number;
   passed?: boolean;
+  deadlineAt?: string;
+  remainingSeconds?: number;
+  autoSubmitted?: boolean;
+  questions?: Question[];
+  answers?: { questionId: string; answerData: any; timeSpentSeconds?: number }[];
 }
 
 export interface SubmitAnswer {
   questionId: string;
   answerData: any;
+  timeSpentSeconds?: number;
 }
 
 export interface AnswerResult {
@@ -48,6 +57,7 @@ export interface AnswerResult {
   pointsAwarded: number;
   maxPoints: number;
   explanation?: string;
+  underReview?: boolean;
 }
 
 export interface TestResult {
@@ -75,6 +85,14 @@ export class TestService {
     return this.http.post<TestAttempt>(`${this.API_URL}/tests/${testId}/attempts`, {});
   }
 
+  getCurrentAttempt(testId: string): Observable<TestAttempt> {
+    return this.http.get<TestAttempt>(`${this.API_URL}/tests/${testId}/attempts/current`);
+  }
+
+  saveAnswer(attemptId: string, answer: SubmitAnswer): Observable<unknown> {
+    return this.http.put(`${this.API_URL}/attempts/${attemptId}/answers`, answer);
+  }
+
   submitAttempt(attemptId: string, answers: SubmitAnswer[]): Observable<TestResult> {
     return this.http.post<TestResult>(`${this.API_URL}/attempts/${attemptId}/submit`, { answers });
   }
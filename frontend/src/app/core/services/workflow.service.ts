@@ -127,7 +127,8 @@ export interface UpdateLessonScriptRequest {
 }
 
 export interface GeneratedQuestion {
-  questionType: 'multiple_choice' | 'drag_drop' | 'fill_blank' | 'matching' | 'ordering';
+  questionType: 'multiple_choice' | 'drag_drop' | 'fill_blank' | 'matching' | 'ordering' |
+    'true_false' | 'numeric' | 'hotspot' | 'short_text' | 'open_ended';
   questionText: string;
   questionData: any;
   points: number;
@@ -160,11 +160,21 @@ interface QuestionTypeOption {
             } @else if (dialogQuestion.questionType === 'drag_drop') {
               Format: {{'{'}}items: [...], dropZones: [...], correctMapping: {{'{}'}}, explanation: "..."{{'}'}}
             } @else if (dialogQuestion.questionType === 'fill_blank') {
-              Format: {{'{'}}template: "Use {{'{{blank}}'}} here", blanks: [...], explanation: "..."{{'}'}}
+              Format: {{'{'}}template: "Use {{'{{blank}}'}} here", blanks: [...], alternatives: [[...]], patterns: [...], maxEdits: 1, explanation: "..."{{'}'}}
             } @else if (dialogQuestion.questionType === 'matching') {
               Format: {{'{'}}leftItems: [...], rightItems: [...], correctPairs: {{'{}'}}, explanation: "..."{{'}'}}
             } @else if (dialogQuestion.questionType === 'ordering') {
               Format: {{'{'}}items: [...], correctOrder: [0,1,2], explanation: "..."{{'}'}}
+            } @else if (dialogQuestion.questionType === 'true_false') {
+              Format: {{'{'}}correctAnswer: true, explanation: "..."{{'}'}}
+            } @else if (dialogQuestion.questionType === 'numeric') {
+              Format: {{'{'}}correctValue: 90, tolerance: 0, unit: "days", explanation: "..."{{'}'}}
+            } @else if (dialogQuestion.questionType === 'hotspot') {
+              Format: {{'{'}}imageUrl: "...", regions: [{{'{'}}shape: "rect", x: 0.1, y: 0.2, width: 0.3, height: 0.05{{'}'}}], explanation: "..."{{'}'}} (coordinates are fractions of the image size)
+            } @else if (dialogQuestion.questionType === 'short_text') {
+              Format: {{'{'}}rubric: [{{'{'}}concept: "...", terms: [...]{{'}'}}], maxLength: 300, sampleAnswer: "...", explanation: "..."{{'}'}}
+            } @else if (dialogQuestion.questionType === 'open_ended') {
+              Format: {{'{'}}rubric: ["criterion", ...], modelAnswer: "...", maxLength: 1000, explanation: "..."{{'}'}} (graded by the AI; unsure grades go to the review queue)
             }
           </small>
         </div>
@@ -208,7 +218,12 @@ export class QuestionEditorComponent implements OnInit {
     { label: 'Drag & Drop', value: 'drag_drop' },
     { label: 'Fill in the Blank', value: 'fill_blank' },
     { label: 'Matching', value: 'matching' },
-    { label: 'Ordering', value: 'ordering' }
+    { label: 'Ordering', value: 'ordering' },
+    { label: 'True / False', value: 'true_false' },
+    { label: 'Numeric', value: 'numeric' },
+    { label: 'Image Hotspot', value: 'hotspot' },
+    { label: 'Short Text', value: 'short_text' },
+    { label: 'Open-Ended (AI graded)', value: 'open_ended' }
   ];
 
   constructor(
@@ -249,7 +264,12 @@ export class QuestionEditorComponent implements OnInit {
       'drag_drop': 'Drag & Drop',
       'fill_blank': 'Fill Blank',
       'matching': 'Matching',
-      'ordering': 'Ordering'
+      'ordering': 'Ordering',
+      'true_false': 'True / False',
+      'numeric': 'Numeric',
+      'hotspot': 'Hotspot',
+      'short_text': 'Short Text',
+      'open_ended': 'Open-Ended'
     };
     return labels[type] || type;
   }
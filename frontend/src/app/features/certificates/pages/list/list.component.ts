@@ -74,6 +74,18 @@ import { CertificateService, Certificate } from '@core/services/certificate.serv
                     icon="pi pi-download"
                     (onClick)="downloadCertificate(cert)"
                   ></p-button>
+                  <p-button
+                    label="Open Badge"
+                    icon="pi pi-id-card"
+                    [outlined]="true"
+                    (onClick)="downloadBadge(cert)"
+                  ></p-button>
+                  <p-button
+                    label="Copy PDF Link"
+                    icon="pi pi-link"
+                    [outlined]="true"
+                    (onClick)="copyDownloadLink(cert)"
+                  ></p-button>
                   <p-button
                     label="Share"
                     icon="pi pi-share-alt"
@@ -263,6 +275,26 @@ export class CertificateListComponent implements OnInit {
     });
   }
 
+  downloadBadge(cert: Certificate): void {
+    this.certificateService.downloadBadge(cert.id).subscribe({
+      next: (blob) => {
+        const url = window.URL.createObjectURL(blob);
+        const a = document.createElement('a');
+        a.href = url;
+        a.download = `certificate-${cert.certificateNumber}.json`;
+        a.click();
+        window.URL.revokeObjectURL(url);
+      }
+    });
+  }
+
+  // The link is signed and expires, so it is fetched when copied
+  copyDownloadLink(cert: Certificate): void {
+    this.certificateService.getDownloadLink(cert.id).subscribe({
+      next: (link) => navigator.clipboard.writeText(link.url)
+    });
+  }
+
   shareCertificate(cert: Certificate): void {
     const url = this.getVerifyUrl(cert);
     if (navigator.share) {
@@ -53,8 +53,20 @@ import { CertificateService, CertificateVerification } from '@core/services/cert
             <div class="status-icon">
               <i class="pi pi-times-circle"></i>
             </div>
-            <h1>Certificate Not Found</h1>
-            <p class="status-message">This certificate could not be verified. It may be invalid or expired.</p>
+            @switch (verification()?.status) {
+              @case ('revoked') {
+                <h1>Certificate Revoked</h1>
+                <p class="status-message">Certificate {{ verification()?.certificateNumber }} was revoked by its issuer on {{ verification()?.revokedAt | date:'mediumDate' }}.</p>
+              }
+              @case ('expired') {
+                <h1>Certificate Expired</h1>
+                <p class="status-message">Certificate {{ verification()?.certificateNumber }} expired on {{ verification()?.expiresAt | date:'mediumDate' }}.</p>
+              }
+              @default {
+                <h1>Certificate Not Found</h1>
+                <p class="status-message">This certificate could not be verified. It may be invalid or expired.</p>
+              }
+            }
           </div>
         }
 
@@ -165,7 +177,7 @@ export class VerifyComponent implements OnInit {
         this.loading.set(false);
       },
       error: () => {
-        this.verification.set({ valid: false });
+        this.verification.set({ valid: false, status: 'not_found' });
         this.loading.set(false);
       }
     });
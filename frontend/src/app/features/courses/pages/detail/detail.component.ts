@@ -185,7 +185,8 @@ import { PresentationPlayerComponent } from '@shared/components/presentation-pla
         @if (currentLesson()?.presentation) {
           <app-presentation-player
             [slides]="currentLesson()!.presentation!.slides"
-            [autoPlay]="true">
+            [autoPlay]="true"
+            (slideViewed)="onSlideViewed($event)">
           </app-presentation-player>
         }
       </p-dialog>
@@ -498,6 +499,14 @@ export class CourseDetailComponent implements OnInit {
     this.showPresentationDialog = true;
   }
 
+  onSlideViewed(slideIndex: number): void {
+    const enrollment = this.enrollment();
+    const lesson = this.currentLesson();
+    if (enrollment && lesson) {
+      this.enrollmentService.recordSlideView(enrollment.id, lesson.id, slideIndex).subscribe();
+    }
+  }
+
   onPresentationClose(): void {
     this.currentLesson.set(null);
   }
@@ -517,6 +526,7 @@ export class CourseDetailComponent implements OnInit {
         const enrollment = enrollments.find(e => e.courseId === this.id);
         if (enrollment) {
           this.enrollment.set(enrollment);
+          this.enrollmentService.launch(enrollment.id).subscribe();
         }
       }
     });
@@ -528,6 +538,7 @@ export class CourseDetailComponent implements OnInit {
       next: (enrollment) => {
         this.enrollment.set(enrollment);
         this.enrolling.set(false);
+        this.enrollmentService.launch(enrollment.id).subscribe();
         this.messageService.add({
           severity: 'success',
           summary: 'Enrolled!',
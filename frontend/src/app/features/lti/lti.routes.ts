@@ -0,0 +1,8 @@
+import { Routes } from '@angular/router';
+
+export const LTI_ROUTES: Routes = [
+  {
+    path: '',
+    loadComponent: () => import('./pages/launch/launch.component').then(m => m.LtiLaunchComponent)
+  }
+];
@@ -0,0 +1,180 @@
+import { Component, OnInit, Input, signal } from '@angular/core';
+import { CommonModule } from '@angular/common';
+import { Router } from '@angular/router';
+import { CardModule } from 'primeng/card';
+import { ButtonModule } from 'primeng/button';
+import { AuthService } from '@core/services/auth.service';
+import { Course, CourseService } from '@core/services/course.service';
+import { LtiService, LtiSession } from '@core/services/lti.service';
+
+@Component({
+  selector: 'app-lti-launch',
+  standalone: true,
+  imports: [CommonModule, CardModule, ButtonModule],
+  template: `
+    <div class="launch-container">
+      @if (error()) {
+        <div class="status-card">
+          <i class="pi pi-exclamation-triangle status-icon"></i>
+          <h1>Launch Failed</h1>
+          <p>{{ error() }}</p>
+          <p class="hint">Go back to your learning platform and open the course again.</p>
+        </div>
+      } @else if (session()?.messageType === 'LtiDeepLinkingRequest') {
+        <div class="picker">
+          <h1>Add a Course</h1>
+          <p>Pick the course to add to your learning platform.</p>
+
+          @for (course of courses(); track course.id) {
+            <p-card styleClass="course-option">
+              <div class="course-row">
+                <div>
+                  <h3>{{ course.title }}</h3>
+                  <p class="description">{{ course.description }}</p>
+                </div>
+                <p-button
+                  label="Add"
+                  icon="pi pi-plus"
+                  [loading]="submitting() === course.id"
+                  [disabled]="submitting() !== null"
+                  (onClick)="addCourse(course)"
+                ></p-button>
+              </div>
+            </p-card>
+          } @empty {
+            <p class="hint">There are no published courses yet.</p>
+          }
+        </div>
+      } @else {
+        <div class="status-card">
+          <i class="pi pi-spin pi-spinner" style="font-size: 2rem"></i>
+          <p>Signing you in...</p>
+        </div>
+      }
+    </div>
+  `,
+  styles: [`
+    .launch-container {
+      max-width: 720px;
+      margin: 0 auto;
+      padding: 2rem;
+      min-height: 100vh;
+    }
+
+    .status-card {
+      text-align: center;
+      padding-top: 4rem;
+    }
+
+    .status-icon {
+      font-size: 2.5rem;
+      color: var(--orange-500);
+    }
+
+    .hint {
+      color: var(--text-color-secondary);
+    }
+
+    .picker h1 {
+      margin-bottom: 0.25rem;
+    }
+
+    :host ::ng-deep .course-option {
+      margin-bottom: 1rem;
+    }
+
+    .course-row {
+      display: flex;
+      align-items: center;
+      justify-content: space-between;
+      gap: 1rem;
+    }
+
+    .course-row h3 {
+      margin: 0 0 0.25rem;
+    }
+
+    .description {
+      margin: 0;
+      color: var(--text-color-secondary);
+      display: -webkit-box;
+      -webkit-line-clamp: 2;
+      -webkit-box-orient: vertical;
+      overflow: hidden;
+    }
+  `]
+})
+export class LtiLaunchComponent implements OnInit {
+  @Input() code!: string;
+
+  session = signal<LtiSession | null>(null);
+  courses = signal<Course[]>([]);
+  submitting = signal<string | null>(null);
+  error = signal<string | null>(null);
+
+  constructor(
+    private ltiService: LtiService,
+    private authService: AuthService,
+    private courseService: CourseService,
+    private router: Router
+  ) {}
+
+  ngOnInit(): void {
+    if (!this.code) {
+      this.error.set('The launch link is incomplete.');
+      return;
+    }
+
+    this.ltiService.createSession(this.code).subscribe({
+      next: (session) => {
+        this.authService.startSession(session);
+        if (session.messageType === 'LtiDeepLinkingRequest') {
+          this.session.set(session);
+          this.loadCourses();
+        } else {
+          this.router.navigate(['/courses', session.courseId], { replaceUrl: true });
+        }
+      },
+      error: (err) => {
+        this.error.set(err.error?.error || 'The launch could not be completed.');
+      }
+    });
+  }
+
+  loadCourses(): void {
+    this.courseService.getCourses(1, 100).subscribe({
+      next: (response) => this.courses.set(response.data),
+      error: () => this.error.set('Failed to load courses.')
+    });
+  }
+
+  addCourse(course: Course): void {
+    const session = this.session();
+    if (!session) return;
+
+    this.submitting.set(course.id);
+    this.ltiService.createDeepLink(session.launchId, course.id).subscribe({
+      next: (response) => this.returnToPlatform(response.returnUrl, response.jwt),
+      error: (err) => {
+        this.submitting.set(null);
+        this.error.set(err.error?.error || 'The course could not be added.');
+      }
+    });
+  }
+
+  // The platform expects the response as a form post of the signed JWT
+  private returnToPlatform(returnUrl: string, jwt: string): void {
+    const form = document.createElement('form');
+    form.method = 'POST';
+    form.action = returnUrl;
+
+    const field = document.createElement('input');
+    field.type = 'hidden';
+    field.name = 'JWT';
+    field.value = jwt;
+
+    form.appendChild(field);
+    document.body.appendChild(form);
+    form.submit();
+  }
+}
@@ -50,7 +50,7 @@ import { TestService, Test, Question, SubmitAnswer } from '@core/services/test.s
           <div class="test-info">
             <div class="info-item">
               <i class="pi pi-question-circle"></i>
-              <span>{{ test()?.questions?.length }} questions</span>
+              <span>{{ test()?.questions?.length || test()?.questionCount }} questions</span>
             </div>
             <div class="info-item">
               <i class="pi pi-check-circle"></i>
@@ -199,6 +199,86 @@ import { TestService, Test, Question, SubmitAnswer } from '@core/services/test.s
                   </div>
                 </div>
               }
+
+              @case ('true_false') {
+                <div class="options-list">
+                  @for (choice of [true, false]; track choice) {
+                    <div
+                      class="option-item"
+                      [class.selected]="trueFalseAnswer() === choice"
+                      (click)="trueFalseAnswer.set(choice)"
+                    >
+                      <span class="option-text">{{ choice ? 'True' : 'False' }}</span>
+                      @if (trueFalseAnswer() === choice) {
+                        <i class="pi pi-check"></i>
+                      }
+                    </div>
+                  }
+                </div>
+              }
+
+              @case ('numeric') {
+                <div class="blank-input">
+                  <label>Your answer</label>
+                  <div class="numeric-input">
+                    <input
+                      pInputText
+                      type="number"
+                      [value]="numericAnswer() ?? ''"
+                      (input)="updateNumeric($event)"
+                      placeholder="Enter a number"
+                    />
+                    @if (question.questionData.unit) {
+                      <span>{{ question.questionData.unit }}</span>
+                    }
+                  </div>
+                </div>
+              }
+
+              @case ('hotspot') {
+                <div class="hotspot-section">
+                  <p class="hotspot-hint">Click each spot on the image you think applies. Click a marker to remove it.</p>
+                  <div class="hotspot-image" (click)="addHotspotClick($event)">
+                    <img [src]="question.questionData.imageUrl" alt="" draggable="false" />
+                    @for (click of hotspotClicks(); track $index) {
+                      <span
+                        class="hotspot-marker"
+                        [style.left.%]="click.x * 100"
+                        [style.top.%]="click.y * 100"
+                        (click)="removeHotspotClick($index, $event)"
+                      ></span>
+                    }
+                  </div>
+                </div>
+              }
+
+              @case ('short_text') {
+                <div class="blank-input">
+                  <label>Your answer</label>
+                  <textarea
+                    pInputText
+                    rows="4"
+                    [value]="shortTextAnswer()"
+                    [attr.maxlength]="question.questionData.maxLength || null"
+                    (input)="updateShortText($event)"
+                    placeholder="Write a short answer"
+                  ></textarea>
+                </div>
+              }
+
+              @case ('open_ended') {
+                <div class="blank-input">
+                  <label>Your answer</label>
+                  <textarea
+                    pInputText
+                    rows="8"
+                    [value]="shortTextAnswer()"
+                    [attr.maxlength]="question.questionData.maxLength || null"
+                    (input)="updateShortText($event)"
+                    placeholder="Describe what you would do and why"
+                  ></textarea>
+                </div>
+              }
             }
           </div>
         }
@@ -364,6 +444,39 @@ import { TestService, Test, Question, SubmitAnswer } from '@core/services/test.s
       color: var(--text-secondary);
     }
 
+    .numeric-input {
+      display: flex;
+      align-items: center;
+      gap: 0.5rem;
+    }
+
+    .hotspot-hint {
+      color: var(--text-secondary);
+      margin-bottom: 0.75rem;
+    }
+
+    .hotspot-image {
+      position: relative;
+      display: inline-block;
+      cursor: crosshair;
+    }
+
+    .hotspot-image img {
+      display: block;
+      max-width: 100%;
+      user-select: none;
+    }
+
+    .hotspot-marker {
+      position: absolute;
+      width: 1.25rem;
+      height: 1.25rem;
+      margin: -0.625rem 0 0 -0.625rem;
+      border: 3px solid var(--danger-color);
+      border-radius: 50%;
+      cursor: pointer;
+    }
+
     .matching-section .match-item {
       display: flex;
       align-items: center;
@@ -513,9 +626,17 @@ export class QuizContainerComponent implements OnInit {
   matchingAnswers = signal<Record<string, string>>({});
   orderingItems = signal<string[]>([]);
   dragDropAnswers = signal<Record<string, string>>({});
+  trueFalseAnswer = signal<boolean | null>(null);
+  numericAnswer = signal<number | null>(null);
+  hotspotClicks = signal<{ x: number; y: number }[]>([]);
+  shortTextAnswer = signal('');
   currentDragItem = signal<string | null>(null);
   currentDragIndex = signal<number>(-1);
 
+  // Seconds spent on each question so far, reported with its answer
+  private timeSpent = new Map<string, number>();
+  private questionShownAt = Date.now();
+
   currentQuestion = computed(() => {
     const t = this.test();
     if (!t?.questions) return null;
@@ -543,6 +664,7 @@ export class QuizContainerComponent implements OnInit {
       next: (test) => {
         this.test.set(test);
         this.loading.set(false);
+        this.resumeAttempt(test);
       },
       error: () => {
         this.loading.set(false);
@@ -550,6 +672,24 @@ export class QuizContainerComponent implements OnInit {
     });
   }
 
+  // Picks up an attempt left open, e.g. after the browser was closed
+  resumeAttempt(test: Test): void {
+    this.testService.getCurrentAttempt(test.id).subscribe({
+      next: (attempt) => {
+        this.attemptId.set(attempt.id);
+        if (attempt.questions?.length) {
+          this.test.set({ ...test, questions: attempt.questions });
+        }
+        this.answers.set(new Map((attempt.answers || []).map(a => [a.questionId, a.answerData])));
+        this.timeSpent = new Map((attempt.answers || []).map(a => [a.questionId, a.timeSpentSeconds || 0]));
+        this.questionShownAt = Date.now();
+        this.loadSavedAnswer();
+      },
+      // No open attempt: the learner starts a new one
+      error: () => {}
+    });
+  }
+
   startTest(): void {
     const t = this.test();
     if (!t) return;
@@ -558,7 +698,12 @@ export class QuizContainerComponent implements OnInit {
     this.testService.startAttempt(t.id).subscribe({
       next: (attempt) => {
         this.attemptId.set(attempt.id);
+        // The attempt carries the questions drawn (and shuffled) for this learner
+        if (attempt.questions?.length) {
+          this.test.set({ ...t, questions: attempt.questions });
+        }
         this.starting.set(false);
+        this.questionShownAt = Date.now();
         this.initializeQuestionState();
       },
       error: (err) => {
@@ -593,6 +738,19 @@ export class QuizContainerComponent implements OnInit {
       case 'drag_drop':
         this.dragDropAnswers.set({});
         break;
+      case 'true_false':
+        this.trueFalseAnswer.set(null);
+        break;
+      case 'numeric':
+        this.numericAnswer.set(null);
+        break;
+      case 'hotspot':
+        this.hotspotClicks.set([]);
+        break;
+      case 'short_text':
+      case 'open_ended':
+        this.shortTextAnswer.set('');
+        break;
     }
   }
 
@@ -600,6 +758,12 @@ export class QuizContainerComponent implements OnInit {
     const question = this.currentQuestion();
     if (!question) return;
 
+    const now = Date.now();
+    const seconds = (this.timeSpent.get(question.id) || 0) + (now - this.questionShownAt) / 1000;
+    this.timeSpent.set(question.id, seconds);
+    this.questionShownAt = now;
+    const timeSpentSeconds = Math.round(seconds);
+
     let answerData: any;
     switch (question.questionType) {
       case 'multiple_choice':
@@ -621,11 +785,32 @@ export class QuizContainerComponent implements OnInit {
         });
         answerData = mapping;
         break;
+      case 'true_false':
+        answerData = this.trueFalseAnswer();
+        break;
+      case 'numeric':
+        answerData = this.numericAnswer();
+        break;
+      case 'hotspot':
+        answerData = this.hotspotClicks();
+        break;
+      case 'short_text':
+      case 'open_ended':
+        answerData = this.shortTextAnswer();
+        break;
     }
 
     const currentAnswers = new Map(this.answers());
     currentAnswers.set(question.id, answerData);
     this.answers.set(currentAnswers);
+
+    // Save as we go so a crash or reload doesn't lose the answer
+    const attempt = this.attemptId();
+    if (attempt && answerData != null) {
+      this.testService.saveAnswer(attempt, { questionId: question.id, answerData, timeSpentSeconds }).subscribe({
+        error: () => {}
+      });
+    }
   }
 
   loadSavedAnswer(): void {
@@ -633,7 +818,8 @@ export class QuizContainerComponent implements OnInit {
     if (!question) return;
 
     const savedAnswer = this.answers().get(question.id);
-    if (!savedAnswer) {
+    // false and 0 are answers too
+    if (savedAnswer === undefined || savedAnswer === null) {
       this.initializeQuestionState();
       return;
     }
@@ -658,6 +844,19 @@ export class QuizContainerComponent implements OnInit {
         });
         this.dragDropAnswers.set(zoneMapping);
         break;
+      case 'true_false':
+        this.trueFalseAnswer.set(savedAnswer);
+        break;
+      case 'numeric':
+        this.numericAnswer.set(savedAnswer);
+        break;
+      case 'hotspot':
+        this.hotspotClicks.set(savedAnswer);
+        break;
+      case 'short_text':
+      case 'open_ended':
+        this.shortTextAnswer.set(savedAnswer);
+        break;
     }
   }
 
@@ -682,7 +881,8 @@ export class QuizContainerComponent implements OnInit {
 
     const submitAnswers: SubmitAnswer[] = t.questions.map(q => ({
       questionId: q.id,
-      answerData: this.answers().get(q.id) || null
+      answerData: this.answers().get(q.id) ?? null,
+      timeSpentSeconds: this.timeSpent.has(q.id) ? Math.round(this.timeSpent.get(q.id)!) : undefined
     }));
 
     this.submitting.set(true);
@@ -694,6 +894,13 @@ export class QuizContainerComponent implements OnInit {
       },
       error: (err) => {
         this.submitting.set(false);
+        // Too late: the server already graded the attempt with the saved answers
+        if (err.status === 409) {
+          this.router.navigate(['/quiz/results', attempt], {
+            state: { courseId: this.courseId }
+          });
+          return;
+        }
         this.messageService.add({
           severity: 'error',
           summary: 'Submission Failed',
@@ -729,6 +936,29 @@ export class QuizContainerComponent implements OnInit {
     this.fillBlankAnswers.set(answers);
   }
 
+  updateNumeric(event: Event): void {
+    const value = (event.target as HTMLInputElement).value;
+    this.numericAnswer.set(value === '' ? null : Number(value));
+  }
+
+  updateShortText(event: Event): void {
+    this.shortTextAnswer.set((event.target as HTMLTextAreaElement).value);
+  }
+
+  // Hotspot helpers: clicks are stored as fractions of the image size, the
+  // same coordinates the regions are defined in
+  addHotspotClick(event: MouseEvent): void {
+    const rect = (event.currentTarget as HTMLElement).getBoundingClientRect();
+    const x = (event.clientX - rect.left) / rect.width;
+    const y = (event.clientY - rect.top) / rect.height;
+    this.hotspotClicks.update(clicks => [...clicks, { x, y }]);
+  }
+
+  removeHotspotClick(index: number, event: MouseEvent): void {
+    event.stopPropagation();
+    this.hotspotClicks.update(clicks => clicks.filter((_, i) => i !== index));
+  }
+
   // Matching helpers
   updateMatching(leftItem: string, event: Event): void {
     const value = (event.target as HTMLSelectElement).value;
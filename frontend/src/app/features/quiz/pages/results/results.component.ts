@@ -94,7 +94,9 @@ import { CertificateService } from '@core/services/certificate.service';
                 <div class="answer-header">
                   <span class="question-num">Question {{ i + 1 }}</span>
                   <span class="answer-status">
-                    @if (answer.isCorrect) {
+                    @if (answer.underReview) {
+                      <i class="pi pi-clock"></i> Awaiting review
+                    } @else if (answer.isCorrect) {
                       <i class="pi pi-check"></i> Correct
                     } @else {
                       <i class="pi pi-times"></i> Incorrect
@@ -1,4 +1,4 @@
-import { Component, input, signal, effect, ElementRef, ViewChild, AfterViewInit, OnDestroy } from '@angular/core';
+import { Component, input, output, signal, effect, ElementRef, ViewChild, AfterViewInit, OnDestroy } from '@angular/core';
 import { CommonModule } from '@angular/common';
 import { ButtonModule } from 'primeng/button';
 import { ProgressBarModule } from 'primeng/progressbar';
@@ -359,6 +359,8 @@ export interface PresentationSlide {
 export class PresentationPlayerComponent implements AfterViewInit, OnDestroy {
   slides = input.required<PresentationSlide[]>();
   autoPlay = input<boolean>(true);
+  // Emits the index of every slide the viewer lands on
+  slideViewed = output<number>();
 
   @ViewChild('audioPlayer') audioPlayer!: ElementRef<HTMLAudioElement>;
 
@@ -380,6 +382,7 @@ export class PresentationPlayerComponent implements AfterViewInit, OnDestroy {
       if (slides && slides.length > 0 && index >= 0 && index < slides.length) {
         this.currentSlide.set(slides[index]);
         this.progressPercent.set(((index + 1) / slides.length) * 100);
+        this.slideViewed.emit(index);
       }
     }, { allowSignalWrites: true });
   }